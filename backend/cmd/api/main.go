@@ -7,7 +7,10 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"os/signal"
 	"runtime"
+	"syscall"
 	"time"
 
 	"github.com/gin-contrib/cors"
@@ -15,27 +18,65 @@ import (
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/ramadhan22/dropship-erp/backend/internal/cache"
 	"github.com/ramadhan22/dropship-erp/backend/internal/config"
+	"github.com/ramadhan22/dropship-erp/backend/internal/export"
 	"github.com/ramadhan22/dropship-erp/backend/internal/handlers"
 	"github.com/ramadhan22/dropship-erp/backend/internal/logutil"
+	"github.com/ramadhan22/dropship-erp/backend/internal/metrics"
 	"github.com/ramadhan22/dropship-erp/backend/internal/middleware"
 	"github.com/ramadhan22/dropship-erp/backend/internal/migrations"
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
 	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
 	"github.com/ramadhan22/dropship-erp/backend/internal/service"
+	"github.com/ramadhan22/dropship-erp/backend/internal/storage"
+	"github.com/ramadhan22/dropship-erp/backend/internal/tracing"
 )
 
 func main() {
-	log.SetFlags(log.LstdFlags | log.Lshortfile)
 	// 1) Load configuration (from config.yaml and environment)
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		logutil.Fatalf("Fatal error loading config: %v", err)
 	}
-	w, err := logutil.NewDailyFileWriter(cfg.Logging.Dir)
+	// Everything is logged as JSON lines to one file per day. Lines that
+	// libraries write through the standard log package are logged as WARN.
+	w, err := logutil.NewDailyFileWriterWithRetention(cfg.Logging.Dir, logutil.Retention{
+		CompressAfterDays: cfg.Logging.CompressAfterDays,
+		MaxAgeDays:        cfg.Logging.RetentionDays,
+	})
 	if err != nil {
 		logutil.Fatalf("open log file: %v", err)
 	}
 	defer w.Close()
-	log.SetOutput(w)
+	logutil.SetOutput(w)
+	log.SetFlags(log.Lshortfile)
+	log.SetOutput(logutil.StdlibWriter{Level: logutil.WARN})
+	if err := logutil.ApplyLevels(cfg.Logging.Level, cfg.Logging.Levels); err != nil {
+		logutil.Fatalf("logging levels: %v", err)
+	}
+	logger := logutil.NewLogger("api", logutil.INFO)
+	tracingLog := logutil.NewLogger("tracing", logutil.INFO)
+	bootCtx := context.Background()
+
+	// Tracing: spans always carry IDs for the logs; they are exported only
+	// when a collector is configured
+	tracingCfg := tracing.Config{
+		ServiceName:   cfg.Tracing.ServiceName,
+		SampleRatio:   cfg.Tracing.SampleRatio,
+		FlushInterval: parseDuration(cfg.Tracing.FlushInterval, 5*time.Second),
+		OnExportError: func(spans int, err error) {
+			tracingLog.Warn(context.Background(), "ExportSpans", "Span export failed", map[string]interface{}{
+				"spans": spans,
+				"error": err.Error(),
+			})
+		},
+	}
+	if cfg.Tracing.Enabled {
+		tracingCfg.Endpoint = cfg.Tracing.Endpoint
+		logger.Info(bootCtx, "Startup", "Exporting traces", map[string]interface{}{
+			"endpoint": cfg.Tracing.Endpoint,
+		})
+	}
+	shutdownTracing := tracing.Configure(tracingCfg)
 
 	// 2) Initialize repositories (Postgres DB connection with optimized pool settings)
 	repo, err := repository.NewPostgresRepository(cfg.Database.URL)
@@ -51,7 +92,7 @@ func main() {
 	}
 	if err := migrations.Run(repo.DB.DB); err != nil {
 		if errors.Is(err, migrate.ErrNoChange) {
-			log.Printf("DB migrations: %v", err)
+			logger.Info(bootCtx, "Startup", "DB migrations: no change")
 		} else {
 			logutil.Fatalf("DB migrations failed: %v", err)
 		}
@@ -60,32 +101,58 @@ func main() {
 	// 3) Initialize cache
 	var cacheInstance cache.Cache
 	if cfg.Cache.Enabled {
-		log.Printf("Initializing Redis cache...")
-		redisCache, err := cache.NewRedisCache(cache.CacheConfig{
-			RedisURL:     cfg.Cache.RedisURL,
-			Password:     cfg.Cache.Password,
-			DB:           cfg.Cache.DB,
-			MaxRetries:   cfg.Cache.MaxRetries,
-			DialTimeout:  parseDuration(cfg.Cache.DialTimeout, 5*time.Second),
-			ReadTimeout:  parseDuration(cfg.Cache.ReadTimeout, 3*time.Second),
-			WriteTimeout: parseDuration(cfg.Cache.WriteTimeout, 3*time.Second),
-			DefaultTTL:   parseDuration(cfg.Cache.DefaultTTL, 5*time.Minute),
-		})
-		if err != nil {
-			log.Printf("Failed to initialize Redis cache, falling back to no-op cache: %v", err)
-			cacheInstance = cache.NewNoopCache()
-		} else {
-			cacheInstance = redisCache
-			log.Printf("Redis cache initialized successfully")
+		cacheCfg := cache.CacheConfig{
+			RedisURL:         cfg.Cache.RedisURL,
+			Password:         cfg.Cache.Password,
+			DB:               cfg.Cache.DB,
+			MaxRetries:       cfg.Cache.MaxRetries,
+			DialTimeout:      parseDuration(cfg.Cache.DialTimeout, 5*time.Second),
+			ReadTimeout:      parseDuration(cfg.Cache.ReadTimeout, 3*time.Second),
+			WriteTimeout:     parseDuration(cfg.Cache.WriteTimeout, 3*time.Second),
+			DefaultTTL:       parseDuration(cfg.Cache.DefaultTTL, 5*time.Minute),
+			MemoryMaxEntries: cfg.Cache.MemoryMaxEntries,
+			MemoryTTL:        parseDuration(cfg.Cache.MemoryTTL, 30*time.Second),
+		}
+		switch cfg.Cache.Backend {
+		case "memory":
+			logger.Info(bootCtx, "Startup", "Initializing in-memory LRU cache", map[string]interface{}{
+				"max_entries": cfg.Cache.MemoryMaxEntries,
+			})
+			cacheInstance = cache.NewMemoryCache(cacheCfg)
+		default:
+			logger.Info(bootCtx, "Startup", "Initializing Redis cache")
+			redisCache, err := cache.NewRedisCache(cacheCfg)
+			if err != nil {
+				logger.Warn(bootCtx, "Startup", "Failed to initialize Redis cache, falling back to in-memory cache", map[string]interface{}{
+					"error": err,
+				})
+				cacheInstance = cache.NewMemoryCache(cacheCfg)
+			} else if cfg.Cache.Backend == "tiered" {
+				cacheInstance = cache.NewTieredCache(cache.NewMemoryCache(cacheCfg), redisCache, cacheCfg)
+				logger.Info(bootCtx, "Startup", "Tiered memory + Redis cache initialized")
+			} else {
+				cacheInstance = redisCache
+				logger.Info(bootCtx, "Startup", "Redis cache initialized")
+			}
 		}
 	} else {
-		log.Printf("Cache disabled, using no-op cache")
+		logger.Info(bootCtx, "Startup", "Cache disabled, using no-op cache")
 		cacheInstance = cache.NewNoopCache()
 	}
 
+	// The supervisor owns every background component and drains them on
+	// SIGINT/SIGTERM.
+	sup := service.NewSupervisor(context.Background())
+
 	// 4) Initialize services with the appropriate repo interfaces
 	shClient := service.NewShopeeClient(cfg.Shopee)
 	batchSvc := service.NewBatchService(repo.BatchRepo, repo.BatchDetailRepo)
+	attachmentStorage, err := newAttachmentStorage(cfg.Attachments)
+	if err != nil {
+		logutil.Fatalf("attachment storage: %v", err)
+	}
+	attachmentSvc := service.NewAttachmentService(repo.AttachmentRepo, attachmentStorage, int64(cfg.Attachments.MaxSizeMB)<<20)
+	batchSvc.SetArchiver(attachmentSvc)
 	dropshipSvc := service.NewDropshipService(
 		repo.DB,
 		repo.DropshipRepo,
@@ -104,16 +171,16 @@ func main() {
 
 	// Initialize memory optimizer
 	memoryOptimizer := service.NewMemoryOptimizer(1024, 10*time.Second) // 1GB max, check every 10s
-	memoryOptimizer.StartMonitoring(context.Background())
+	memoryOptimizer.StartMonitoring(sup.Context())
 
 	// Initialize enhanced scheduler
 	enhancedScheduler := service.NewEnhancedImportScheduler(
 		batchSvc, dropshipSvc, streamingProcessor, time.Minute, cfg.MaxThreads,
 	)
-	enhancedScheduler.Start()
+	sup.Start("enhanced-import", enhancedScheduler)
 
 	// Keep original scheduler for backward compatibility
-	service.NewDropshipImportScheduler(batchSvc, dropshipSvc, time.Minute).Start(context.Background())
+	sup.Start("dropship-import", service.NewDropshipImportScheduler(batchSvc, dropshipSvc, time.Minute))
 	shopeeSvc := service.NewShopeeService(repo.DB, repo.ShopeeRepo, repo.DropshipRepo, repo.JournalRepo, repo.ShopeeAdjustmentRepo, repo.ChannelRepo, cfg.Shopee)
 	reconSvc := service.NewReconcileService(
 		repo.DB,
@@ -128,19 +195,28 @@ func main() {
 		cfg.MaxThreads,
 		nil, // Use default reconciliation config
 	)
-	service.NewReconcileBatchScheduler(batchSvc, reconSvc, time.Minute).Start(context.Background())
+	reconPolicySvc := service.NewReconcilePolicyService(repo.ReconciliationPolicyRepo, nil)
+	reconSvc.SetPolicySource(reconPolicySvc)
+	reconSvc.SetRetryPolicy(cfg.Reconcile.RetryMaxAttempts, parseDuration(cfg.Reconcile.RetryDelay, 5*time.Minute))
+	sup.Start("reconcile-retry", service.NewReconcileRetryScheduler(reconSvc, parseDuration(cfg.Reconcile.RetryInterval, time.Minute), cfg.Reconcile.RetryBatchSize))
+	sup.Start("reconcile-batch", service.NewReconcileBatchScheduler(batchSvc, reconSvc, time.Minute))
 
 	// Start background scheduler for reconcile batch creation
-	service.NewReconcileBatchCreationScheduler(batchSvc, reconSvc, time.Minute).Start(context.Background())
+	sup.Start("reconcile-batch-creation", service.NewReconcileBatchCreationScheduler(batchSvc, reconSvc, time.Minute))
 
 	// Start background scheduler for Shopee detail fetching
 	shopeeDetailBgSvc := service.NewShopeeDetailBackgroundService(reconSvc, batchSvc, repo.OrderDetailRepo, repo.DropshipRepo, repo.ChannelRepo, shClient)
-	service.NewShopeeDetailBackgroundScheduler(shopeeDetailBgSvc, time.Minute).Start(context.Background())
+	sup.Start("shopee-detail", service.NewShopeeDetailBackgroundScheduler(shopeeDetailBgSvc, time.Minute))
 	metricSvc := service.NewMetricService(
 		repo.DropshipRepo, repo.ShopeeRepo, repo.JournalRepo, repo.MetricRepo,
 	)
 	taxSvc := service.NewTaxService(repo.DB, repo.TaxRepo, repo.JournalRepo, metricSvc)
+	taxSvc.SetStore(repo.TaxRepo)
+	taxSvc.SetCompany(export.CompanyInfo{Name: cfg.Company.Name, Address: cfg.Company.Address, TaxID: cfg.Company.TaxID})
 	expenseSvc := service.NewExpenseService(repo.DB, repository.NewExpenseRepo(repo.DB), repo.JournalRepo)
+	expenseAllocationSvc := service.NewExpenseAllocationService(repo.DB, repo.ExpenseAllocationRepo, repo.JournalRepo)
+	expenseAllocationSvc.SetCache(cacheInstance)
+	expenseSvc.SetAllocator(expenseAllocationSvc)
 	balanceSvc := service.NewBalanceService(repo.JournalRepo)
 	channelSvc := service.NewChannelService(repo.ChannelRepo, shClient)
 	accountSvc := service.NewAccountService(repo.AccountRepo)
@@ -149,6 +225,11 @@ func main() {
 	plSvc := service.NewPLService(repo.MetricRepo, metricSvc)
 	plReportSvc := service.NewProfitLossReportService(repo.JournalRepo)
 	glSvc := service.NewGLService(repo.JournalRepo)
+	statementSvc := service.NewFinancialStatementService(repo.JournalRepo, repo.AssetAccountRepo)
+	entitySvc := service.NewEntityService(repo.DB, repo.EntityRepo, repo.JournalRepo,
+		func(id int64) service.FinancialStatementJournalRepo { return repo.JournalRepo.ForEntity(id) },
+		repo.AssetAccountRepo)
+	entitySvc.SetCache(cacheInstance)
 	pbSvc := service.NewPendingBalanceService(shClient, repo.ChannelRepo)
 	walletSvc := service.NewWalletTransactionService(repo.ChannelRepo, shClient)
 	adsTopupSvc := service.NewAdsTopupService(walletSvc, repo.JournalRepo)
@@ -160,8 +241,25 @@ func main() {
 	shippingDiscrepancySvc := service.NewShippingDiscrepancyService(repo.DB, repo.ShippingDiscrepancyRepo)
 	adsPerformanceSvc := service.NewAdsPerformanceService(repo.DB, cfg.Shopee, repo)
 	adsPerformanceBatchScheduler := service.NewAdsPerformanceBatchScheduler(batchSvc, adsPerformanceSvc, time.Minute)
-	adsPerformanceBatchScheduler.Start(context.Background())
+	sup.Start("ads-performance", adsPerformanceBatchScheduler)
 	
+	// Share the cache so writers invalidate the summaries and reports
+	// that readers cache
+	shopeeSvc.SetCache(cacheInstance)
+	reconSvc.SetCache(cacheInstance)
+	journalSvc.SetCache(cacheInstance)
+	balanceSvc.SetCache(cacheInstance)
+	plReportSvc.SetCache(cacheInstance)
+	statementSvc.SetCache(cacheInstance)
+	expenseSvc.SetCache(cacheInstance)
+	adsSvc.SetCache(cacheInstance)
+	adsTopupSvc.SetCache(cacheInstance)
+	assetSvc.SetCache(cacheInstance)
+	adjustSvc.SetCache(cacheInstance)
+	taxSvc.SetCache(cacheInstance)
+	withdrawalSvc.SetCache(cacheInstance)
+	walletWdSvc.SetCache(cacheInstance)
+
 	// Initialize forecast service (without obsolete shopee repo)
 	forecastSvc := service.NewForecastService(
 		repo.DropshipRepo, repo.JournalRepo,
@@ -173,7 +271,12 @@ func main() {
 	}
 
 	// 5) Setup Gin router and API routes
-	router := gin.Default()
+	router := gin.New()
+	router.Use(gin.RecoveryWithWriter(logutil.StdlibWriter{Level: logutil.ERROR}))
+	router.Use(middleware.RequestLoggingMiddleware())
+	router.Use(middleware.PrometheusMiddleware())
+	router.Use(middleware.TracingMiddleware())
+	router.Use(middleware.CorrelationIDMiddleware())
 
 	// Add performance monitoring middleware
 	if cfg.Performance.EnableMetrics {
@@ -184,22 +287,31 @@ func main() {
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     cfg.Server.CorsOrigins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Skip-Loading"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Skip-Loading", "X-User-ID", "X-Correlation-ID", "traceparent"},
+		ExposeHeaders:    []string{"X-Correlation-ID", middleware.TraceIDHeader},
 		AllowCredentials: true,
 		AllowOriginFunc:  func(origin string) bool { return true },
 	}))
 
+	// Prometheus scrape endpoint. Pool, cache and queue gauges are read
+	// from their sources on every scrape.
+	metrics.WatchDB("main", repo.DB.DB)
+	metrics.WatchCache("app", cacheInstance)
+	metrics.Default.AddCollector(batchSvc.CollectQueueDepth)
+	router.GET("/metrics", gin.WrapH(metrics.Default.Handler()))
+
 	apiGroup := router.Group("/api")
 	{
 		dh := handlers.NewDropshipHandler(dropshipSvc, batchSvc)
 		apiGroup.POST("/dropship/import", dh.HandleImport)
 		apiGroup.GET("/dropship/purchases", dh.HandleList)
-		apiGroup.GET("/dropship/purchases/filtered", middleware.FilterMiddleware(), dh.HandleListFiltered)
+		apiGroup.GET("/dropship/purchases/filtered", middleware.FilterMiddlewareFor(models.ResourceDropshipPurchases), dh.HandleListFiltered)
 		apiGroup.GET("/dropship/purchases/summary", dh.HandleSum)
 		apiGroup.GET("/dropship/purchases/daily", dh.HandleDailyTotals)
 		apiGroup.GET("/dropship/purchases/monthly", dh.HandleMonthlyTotals)
 		apiGroup.GET("/dropship/cancellations/summary", dh.HandleCancelledSummary)
 		apiGroup.GET("/dropship/purchases/:id/details", dh.HandleListDetails)
+		apiGroup.GET("/dropship/purchases/:id/timeline", dh.HandleTimeline)
 		apiGroup.GET("/dropship/top-products", dh.HandleTopProducts)
 
 		// Enhanced bulk import endpoints
@@ -240,13 +352,16 @@ func main() {
 		apiGroup.POST("/shopee/affiliate", shHandler.HandleImportAffiliate)
 		apiGroup.POST("/shopee/settle/:order_sn", shHandler.HandleConfirmSettle)
 		apiGroup.GET("/shopee/affiliate", shHandler.HandleListAffiliate)
+		apiGroup.GET("/shopee/affiliate/filtered", middleware.FilterMiddlewareFor(models.ResourceShopeeAffiliate), shHandler.HandleListAffiliateFiltered)
 		apiGroup.GET("/shopee/affiliate/summary", shHandler.HandleSumAffiliate)
 		apiGroup.GET("/shopee/settled", shHandler.HandleListSettled)
+		apiGroup.GET("/shopee/settled/filtered", middleware.FilterMiddlewareFor(models.ResourceShopeeSettled), shHandler.HandleListSettledFiltered)
 		apiGroup.GET("/shopee/settled/:order_sn", shHandler.HandleGetSettleDetail)
 		apiGroup.GET("/shopee/settled/summary", shHandler.HandleSumSettled)
 		apiGroup.GET("/shopee/returns", shHandler.HandleGetReturnList)
 		apiGroup.GET("/sales", shHandler.HandleListSalesProfit)
 		apiGroup.POST("/reconcile", handlers.NewReconcileHandler(reconSvc).HandleMatchAndJournal)
+		apiGroup.POST("/reconcile/retry", handlers.NewReconcileHandler(reconSvc).HandleRetryFailedReconciliations)
 		apiGroup.POST("/metrics", handlers.NewMetricHandler(metricSvc).HandleCalculateMetrics)
 		apiGroup.GET("/metrics", handlers.NewMetricHandler(metricSvc).HandleGetMetrics)
 		apiGroup.GET("/balancesheet", handlers.NewBalanceHandler(balanceSvc).HandleGetBalanceSheet)
@@ -270,6 +385,12 @@ func main() {
 
 		expHandler := handlers.NewExpenseHandler(expenseSvc)
 		expHandler.RegisterRoutes(apiGroup)
+		recurringExpenseSvc := service.NewRecurringExpenseService(repo.DB, repo.RecurringExpenseRepo, repository.NewExpenseRepo(repo.DB), repo.JournalRepo)
+		recurringExpenseSvc.SetCache(cacheInstance)
+		sup.Start("recurring-expense", service.NewRecurringExpenseScheduler(recurringExpenseSvc, parseDuration(cfg.Expenses.RecurringInterval, 5*time.Minute)))
+		handlers.NewRecurringExpenseHandler(recurringExpenseSvc).RegisterRoutes(apiGroup)
+		handlers.NewExpenseAllocationHandler(expenseAllocationSvc).RegisterRoutes(apiGroup)
+		handlers.NewAttachmentHandler(attachmentSvc).RegisterRoutes(apiGroup)
 
 		adsHandler := handlers.NewAdInvoiceHandler(adsSvc)
 		adsHandler.RegisterRoutes(apiGroup)
@@ -281,7 +402,19 @@ func main() {
 		handlers.NewPLHandler(plSvc).RegisterRoutes(apiGroup)
 		handlers.NewProfitLossReportHandler(plReportSvc).RegisterRoutes(apiGroup)
 		handlers.NewGLHandler(glSvc).RegisterRoutes(apiGroup)
+		handlers.NewFinancialStatementHandler(statementSvc).RegisterRoutes(apiGroup)
+		handlers.NewEntityHandler(entitySvc).RegisterRoutes(apiGroup)
 		handlers.NewReconcileExtraHandler(reconSvc).RegisterRoutes(apiGroup)
+		handlers.NewReconcilePolicyHandler(reconPolicySvc).RegisterRoutes(apiGroup)
+		handlers.NewReconcileFailureHandler(reconSvc).RegisterRoutes(apiGroup)
+		matchSuggestionSvc := service.NewMatchSuggestionService(repo.MatchSuggestionRepo, reconSvc)
+		handlers.NewMatchSuggestionHandler(matchSuggestionSvc).RegisterRoutes(apiGroup)
+		ledgerIntegritySvc := service.NewLedgerIntegrityService(repo.DB, repo.LedgerIntegrityRepo, repo.JournalRepo, reconSvc)
+		ledgerIntegritySvc.SetCache(cacheInstance)
+		handlers.NewLedgerIntegrityHandler(ledgerIntegritySvc).RegisterRoutes(apiGroup)
+		filterViewSvc := service.NewFilterViewService(repo.SavedFilterViewRepo)
+		middleware.SetViewResolver(filterViewSvc)
+		handlers.NewFilterViewHandler(filterViewSvc).RegisterRoutes(apiGroup)
 		handlers.NewPendingBalanceHandler(pbSvc).RegisterRoutes(apiGroup)
 		handlers.NewWalletHandler(walletSvc).RegisterRoutes(apiGroup)
 		handlers.NewAdsTopupHandler(adsTopupSvc).RegisterRoutes(apiGroup)
@@ -296,7 +429,37 @@ func main() {
 		handlers.NewAdsPerformanceHandler(adsPerformanceSvc, adsPerformanceBatchScheduler).RegisterRoutes(apiGroup)
 		handlers.NewConfigHandler(cfg).RegisterRoutes(apiGroup)
 		dashSvc := service.NewDashboardService(repo.DropshipRepo, repo.JournalRepo, plReportSvc)
+		dashSvc.SetCache(cacheInstance)
 		handlers.NewDashboardHandler(dashSvc).RegisterRoutes(apiGroup)
+		reportExportSvc := service.NewReportExportService(
+			export.CompanyInfo{Name: cfg.Company.Name, Address: cfg.Company.Address, TaxID: cfg.Company.TaxID},
+			balanceSvc, plReportSvc, glSvc, shopeeSvc, shippingDiscrepancySvc, reconSvc, dashSvc, statementSvc,
+		)
+		handlers.NewReportExportHandler(reportExportSvc).RegisterRoutes(apiGroup)
+		reportSinks := map[string]service.ReportSink{
+			service.SinkWebhook:   service.NewWebhookSink(parseDuration(cfg.Reports.WebhookTimeout, 30*time.Second)),
+			service.SinkDirectory: service.NewDirectorySink(cfg.Reports.OutputDir),
+		}
+		if smtpCfg := cfg.Reports.SMTP; smtpCfg.Host != "" {
+			reportSinks[service.SinkEmail] = service.NewSMTPSink(smtpCfg.Host, smtpCfg.Port, smtpCfg.Username, smtpCfg.Password, smtpCfg.From)
+		}
+		reportDeliverySvc := service.NewReportDeliveryService(
+			repo.ReportSubscriptionRepo, reportExportSvc, reportSinks,
+			cfg.Reports.MaxAttempts, parseDuration(cfg.Reports.RetryDelay, 5*time.Minute),
+		)
+		sup.Start("report-delivery", service.NewReportDeliveryScheduler(reportDeliverySvc, parseDuration(cfg.Reports.DeliveryInterval, time.Minute)))
+		handlers.NewReportSubscriptionHandler(reportDeliverySvc).RegisterRoutes(apiGroup)
+		if sink, ok := reportSinks[cfg.Tax.ReminderSink]; ok {
+			taxSvc.SetReminders(sink, cfg.Tax.ReminderTarget, cfg.Tax.ReminderDays)
+		}
+		sup.Start("tax", service.NewTaxScheduler(taxSvc, parseDuration(cfg.Tax.Interval, time.Hour)))
+		returnSvc := service.NewReturnService(
+			repo.DB, repo.OrderReturnRepo, repo.DropshipRepo, repo.JournalRepo,
+			service.NewShopeeReturnSource(cfg.Shopee, repo.ChannelRepo),
+		)
+		returnSvc.SetCache(cacheInstance)
+		handlers.NewReturnHandler(returnSvc).RegisterRoutes(apiGroup)
+		handlers.NewLogHandler(cfg.Logging.Dir).RegisterRoutes(apiGroup)
 
 		// Forecast endpoints
 		forecastHandler := handlers.NewForecastHandler(forecastSvc)
@@ -308,12 +471,63 @@ func main() {
 		// Note: /api/performance is already registered above at line 214
 	}
 
-	// 6) Start the HTTP server
+	// 6) Start the HTTP server and drain everything on SIGINT/SIGTERM
 	addr := fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.Server.Port)
-	log.Printf("🚀 Server starting on %s", addr)
-	if err := router.Run(addr); err != nil {
-		logutil.Fatalf("Server failed: %v", err)
+	srv := &http.Server{Addr: addr, Handler: router}
+	sup.OnShutdown("http", srv.Shutdown)
+	sup.SetBatchResumer(batchSvc)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	serverErr := make(chan error, 1)
+	go func() {
+		logger.Info(bootCtx, "Startup", "Server starting", map[string]interface{}{"addr": addr})
+		serverErr <- srv.ListenAndServe()
+	}()
+	select {
+	case err := <-serverErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			logutil.Fatalf("Server failed: %v", err)
+		}
+	case <-ctx.Done():
+		stop()
+	}
+
+	timeout := parseDuration(cfg.Server.ShutdownTimeout, 30*time.Second)
+	logger.Info(bootCtx, "Shutdown", "Draining background jobs", map[string]interface{}{
+		"timeout": timeout.String(),
+	})
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := sup.Shutdown(shutdownCtx); err != nil {
+		logger.Error(bootCtx, "Shutdown", "Shutdown finished with errors", err)
+	}
+	memoryOptimizer.StopMonitoring()
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		logger.Error(bootCtx, "Shutdown", "Failed to flush traces", err)
+	}
+	if err := repo.Close(); err != nil {
+		logger.Error(bootCtx, "Shutdown", "Failed to close database", err)
+	}
+	logger.Info(bootCtx, "Shutdown", "Server stopped")
+}
+
+// newAttachmentStorage opens the storage configured for attachments.
+func newAttachmentStorage(c config.AttachmentsConfig) (storage.Storage, error) {
+	switch c.Storage {
+	case "", "local":
+		return storage.NewLocal(c.Dir)
+	case "s3":
+		return storage.NewS3(storage.S3Config{
+			Endpoint:  c.S3.Endpoint,
+			Region:    c.S3.Region,
+			Bucket:    c.S3.Bucket,
+			AccessKey: c.S3.AccessKey,
+			SecretKey: c.S3.SecretKey,
+			PathStyle: c.S3.PathStyle,
+		})
 	}
+	return nil, fmt.Errorf("unknown attachments.storage %q", c.Storage)
 }
 
 // parseDuration parses a duration string and returns a default value if parsing fails
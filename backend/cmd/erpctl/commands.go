@@ -0,0 +1,319 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/ramadhan22/dropship-erp/backend/internal/migrations"
+	"github.com/ramadhan22/dropship-erp/backend/internal/service"
+)
+
+func runMigrate(e *env, args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	steps := fs.Int("steps", 1, "migrations to roll back with down")
+	if len(args) == 0 {
+		return errors.New("usage: migrate up|down [-steps n]|status")
+	}
+	fs.Parse(args[1:])
+
+	db := e.repo.DB.DB
+	switch args[0] {
+	case "up":
+		if err := migrations.Run(db); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+			return err
+		}
+	case "down":
+		if *steps < 1 {
+			return errors.New("-steps must be at least 1")
+		}
+		if err := migrations.Down(db, *steps); err != nil {
+			return err
+		}
+	case "status":
+	default:
+		return fmt.Errorf("unknown migrate action %q", args[0])
+	}
+	v, dirty, err := migrations.Version(db)
+	if errors.Is(err, migrate.ErrNilVersion) {
+		log.Printf("no migrations applied")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("schema version %d dirty=%t", v, dirty)
+	return nil
+}
+
+// importers maps the import types to the service call that reads the file.
+var importers = map[string]func(ctx context.Context, e *env, f *os.File, channel string) (int, error){
+	"dropship": func(ctx context.Context, e *env, f *os.File, channel string) (int, error) {
+		return e.dropshipService().ImportFromCSV(ctx, f, channel, 0)
+	},
+	"shopee-settled": func(ctx context.Context, e *env, f *os.File, _ string) (int, error) {
+		n, failed, err := e.shopeeService().ImportSettledOrdersXLSX(ctx, f)
+		for _, inv := range failed {
+			log.Printf("not imported: %s", inv)
+		}
+		return n, err
+	},
+	"affiliate": func(ctx context.Context, e *env, f *os.File, _ string) (int, error) {
+		return e.shopeeService().ImportAffiliateCSV(ctx, f)
+	},
+	"withdrawal": func(ctx context.Context, e *env, f *os.File, _ string) (int, error) {
+		svc := service.NewWithdrawalService(e.repo.DB, e.repo.WithdrawalRepo, e.repo.JournalRepo)
+		svc.SetCache(e.cache)
+		return svc.ImportXLSX(ctx, f)
+	},
+	"adjustment": func(ctx context.Context, e *env, f *os.File, _ string) (int, error) {
+		svc := service.NewShopeeAdjustmentService(e.repo.DB, e.repo.ShopeeAdjustmentRepo, e.repo.JournalRepo)
+		svc.SetCache(e.cache)
+		return svc.ImportXLSX(ctx, f)
+	},
+	"ad-invoice": func(ctx context.Context, e *env, f *os.File, _ string) (int, error) {
+		svc := service.NewAdInvoiceService(e.repo.DB, e.repo.AdInvoiceRepo, e.repo.JournalRepo)
+		svc.SetCache(e.cache)
+		if err := svc.ImportInvoicePDF(ctx, f); err != nil {
+			return 0, err
+		}
+		return 1, nil
+	},
+}
+
+func runImport(e *env, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	channel := fs.String("channel", "", "channel of dropship purchases")
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		return fmt.Errorf("usage: import [-channel name] <%s> <file>", strings.Join(sortedKeys(importers), "|"))
+	}
+	imp, ok := importers[fs.Arg(0)]
+	if !ok {
+		return fmt.Errorf("unknown import type %q", fs.Arg(0))
+	}
+	f, err := os.Open(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	n, err := imp(context.Background(), e, f, *channel)
+	if err != nil {
+		return err
+	}
+	log.Printf("imported %d row(s) from %s", n, fs.Arg(1))
+	return nil
+}
+
+func runReconcile(e *env, args []string) error {
+	fs := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	store := fs.String("store", "", "store to reconcile")
+	from := fs.String("from", "", "first order date (YYYY-MM-DD)")
+	to := fs.String("to", "", "last order date (YYYY-MM-DD)")
+	now := fs.Bool("now", false, "process the batches here instead of leaving them to the API worker")
+	fs.Parse(args)
+	if *store == "" {
+		return errors.New("-store is required")
+	}
+
+	ctx := context.Background()
+	svc := e.reconcileService()
+	info, err := svc.CreateReconcileBatches(ctx, *store, "", "", *from, *to)
+	if err != nil {
+		return err
+	}
+	log.Printf("created %d batch(es) for %d transaction(s)", info.BatchCount, info.TotalTransactions)
+	if !*now {
+		return nil
+	}
+	for _, id := range info.BatchIDs {
+		svc.ProcessReconcileBatch(ctx, id)
+		b, err := e.batchService().GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		log.Printf("batch %d %s: %d/%d done %s", id, b.Status, b.DoneData, b.TotalData, b.ErrorMessage)
+	}
+	return nil
+}
+
+func runBatch(e *env, args []string) error {
+	fs := flag.NewFlagSet("batch", flag.ExitOnError)
+	reason := fs.String("reason", "cancelled from erpctl", "message recorded on cancel")
+	if len(args) == 0 {
+		return errors.New("usage: batch retry|cancel <id>")
+	}
+	fs.Parse(args[1:])
+	if fs.NArg() != 1 {
+		return errors.New("usage: batch retry|cancel <id>")
+	}
+	id, err := strconv.ParseInt(fs.Arg(0), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid batch id %q", fs.Arg(0))
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "retry":
+		err = e.batchService().Retry(ctx, id)
+	case "cancel":
+		err = e.batchService().Cancel(ctx, id, *reason)
+	default:
+		return fmt.Errorf("unknown batch action %q", args[0])
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("batch %d: %s done", id, args[0])
+	return nil
+}
+
+func runMetrics(e *env, args []string) error {
+	fs := flag.NewFlagSet("metrics", flag.ExitOnError)
+	period := fs.String("period", time.Now().Format("2006-01"), "month to recalculate (YYYY-MM)")
+	store := fs.String("store", "", "store to recalculate (default all)")
+	if len(args) == 0 || args[0] != "recalc" {
+		return errors.New("usage: metrics recalc [-period YYYY-MM] [-store name]")
+	}
+	fs.Parse(args[1:])
+
+	ctx := context.Background()
+	stores := []string{*store}
+	if *store == "" {
+		list, err := e.repo.ChannelRepo.ListAllStores(ctx)
+		if err != nil {
+			return err
+		}
+		stores = stores[:0]
+		for _, st := range list {
+			stores = append(stores, st.NamaToko)
+		}
+	}
+	svc := service.NewMetricService(e.repo.DropshipRepo, e.repo.ShopeeRepo, e.repo.JournalRepo, e.repo.MetricRepo)
+	failed := 0
+	for _, st := range stores {
+		if err := svc.CalculateAndCacheMetrics(ctx, st, *period); err != nil {
+			log.Printf("metrics %s %s: %v", st, *period, err)
+			failed++
+			continue
+		}
+		log.Printf("metrics %s %s recalculated", st, *period)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d store(s) failed", failed, len(stores))
+	}
+	return nil
+}
+
+func runToken(e *env, args []string) error {
+	fs := flag.NewFlagSet("token", flag.ExitOnError)
+	store := fs.String("store", "", "store whose Shopee token to refresh")
+	if len(args) == 0 || args[0] != "refresh" {
+		return errors.New("usage: token refresh -store name")
+	}
+	fs.Parse(args[1:])
+	if *store == "" {
+		return errors.New("-store is required")
+	}
+	st, err := e.dropshipService().RefreshStoreToken(context.Background(), *store)
+	if err != nil {
+		return err
+	}
+	log.Printf("token for %s refreshed, expires in %ds", st.NamaToko, *st.ExpireIn)
+	return nil
+}
+
+func runIntegrity(e *env, args []string) error {
+	fs := flag.NewFlagSet("integrity", flag.ExitOnError)
+	checks := fs.String("check", "", "comma separated checks to run (default all)")
+	if len(args) == 0 || args[0] != "check" {
+		return errors.New("usage: integrity check [-check names]")
+	}
+	fs.Parse(args[1:])
+
+	var names []string
+	if *checks != "" {
+		names = strings.Split(*checks, ",")
+	}
+	svc := service.NewLedgerIntegrityService(e.repo.DB, e.repo.LedgerIntegrityRepo, e.repo.JournalRepo, nil)
+	svc.SetCache(e.cache)
+	report, err := svc.Check(context.Background(), names...)
+	if err != nil {
+		return err
+	}
+	for _, is := range report.Issues {
+		log.Printf("%s target=%s: %s", is.Check, is.Target, is.Detail)
+	}
+	if len(report.Issues) > 0 {
+		return fmt.Errorf("%d integrity issue(s) found", len(report.Issues))
+	}
+	log.Printf("ledger is consistent")
+	return nil
+}
+
+func (e *env) batchService() *service.BatchService {
+	return service.NewBatchService(e.repo.BatchRepo, e.repo.BatchDetailRepo)
+}
+
+func (e *env) dropshipService() *service.DropshipService {
+	return service.NewDropshipService(
+		e.repo.DB,
+		e.repo.DropshipRepo,
+		e.repo.JournalRepo,
+		e.repo.ChannelRepo,
+		e.repo.OrderDetailRepo,
+		e.batchService(),
+		service.NewShopeeClient(e.cfg.Shopee),
+		e.cache,
+		e.cfg.MaxThreads,
+		e.cfg.Performance.BatchSize,
+	)
+}
+
+func (e *env) shopeeService() *service.ShopeeService {
+	svc := service.NewShopeeService(e.repo.DB, e.repo.ShopeeRepo, e.repo.DropshipRepo, e.repo.JournalRepo,
+		e.repo.ShopeeAdjustmentRepo, e.repo.ChannelRepo, e.cfg.Shopee)
+	svc.SetCache(e.cache)
+	return svc
+}
+
+func (e *env) reconcileService() *service.ReconcileService {
+	svc := service.NewReconcileService(
+		e.repo.DB,
+		e.repo.DropshipRepo, e.repo.ShopeeRepo, e.repo.JournalRepo, e.repo.ReconcileRepo,
+		e.repo.ChannelRepo,
+		e.repo.OrderDetailRepo,
+		e.repo.ShopeeAdjustmentRepo,
+		service.NewShopeeClient(e.cfg.Shopee),
+		e.batchService(),
+		e.repo.FailedReconciliationRepo,
+		e.repo.ShippingDiscrepancyRepo,
+		e.cfg.MaxThreads,
+		nil,
+	)
+	svc.SetCache(e.cache)
+	svc.SetPolicySource(service.NewReconcilePolicyService(e.repo.ReconciliationPolicyRepo, nil))
+	delay, err := time.ParseDuration(e.cfg.Reconcile.RetryDelay)
+	if err != nil {
+		delay = 5 * time.Minute
+	}
+	svc.SetRetryPolicy(e.cfg.Reconcile.RetryMaxAttempts, delay)
+	return svc
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,113 @@
+// File: backend/cmd/erpctl/main.go
+
+// Command erpctl runs admin tasks from a shell or cron using the same
+// services as the API:
+//
+//	erpctl migrate up|down [-steps n]|status
+//	erpctl import [-channel name] <type> <file>
+//	erpctl reconcile -store name [-from date] [-to date] [-now]
+//	erpctl batch retry <id> | batch cancel [-reason text] <id>
+//	erpctl metrics recalc -period YYYY-MM [-store name]
+//	erpctl token refresh -store name
+//	erpctl integrity check [-check names]
+//
+// Flags go before positional arguments. Unlike the API, erpctl does not apply
+// migrations on start; run `erpctl migrate up` first.
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/cache"
+	"github.com/ramadhan22/dropship-erp/backend/internal/config"
+	"github.com/ramadhan22/dropship-erp/backend/internal/logutil"
+	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
+)
+
+// command runs one top-level subcommand with the arguments after its name.
+type command func(e *env, args []string) error
+
+var commands = map[string]command{
+	"migrate":   runMigrate,
+	"import":    runImport,
+	"reconcile": runReconcile,
+	"batch":     runBatch,
+	"metrics":   runMetrics,
+	"token":     runToken,
+	"integrity": runIntegrity,
+}
+
+// env holds the configuration, database and cache shared by the subcommands.
+type env struct {
+	cfg   *config.Config
+	repo  *repository.Repository
+	cache cache.Cache
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		logutil.Fatalf("Fatal error loading config: %v", err)
+	}
+	repo, err := repository.NewPostgresRepository(cfg.Database.URL)
+	if err != nil {
+		logutil.Fatalf("DB connection failed: %v", err)
+	}
+	defer repo.DB.Close()
+	c, err := newCache(cfg.Cache)
+	if err != nil {
+		repo.DB.Close()
+		logutil.Fatalf("Cache connection failed: %v", err)
+	}
+	defer c.Close()
+
+	if err := cmd(&env{cfg: cfg, repo: repo, cache: c}, os.Args[2:]); err != nil {
+		c.Close()
+		repo.DB.Close()
+		logutil.Fatalf("%s: %v", os.Args[1], err)
+	}
+}
+
+// newCache connects to the Redis cache the API uses so that writes made here
+// invalidate the API's cached reports. An in-memory cache would only live as
+// long as the command, so memory-only or disabled caching gets a no-op cache.
+func newCache(cfg config.CacheConfig) (cache.Cache, error) {
+	if !cfg.Enabled || cfg.Backend == "memory" {
+		return cache.NewNoopCache(), nil
+	}
+	return cache.NewRedisCache(cache.CacheConfig{
+		RedisURL:     cfg.RedisURL,
+		Password:     cfg.Password,
+		DB:           cfg.DB,
+		MaxRetries:   cfg.MaxRetries,
+		DialTimeout:  parseDuration(cfg.DialTimeout, 5*time.Second),
+		ReadTimeout:  parseDuration(cfg.ReadTimeout, 3*time.Second),
+		WriteTimeout: parseDuration(cfg.WriteTimeout, 3*time.Second),
+		DefaultTTL:   parseDuration(cfg.DefaultTTL, 5*time.Minute),
+	})
+}
+
+// parseDuration parses s, returning def when it is empty or invalid.
+func parseDuration(s string, def time.Duration) time.Duration {
+	if d, err := time.ParseDuration(s); err == nil {
+		return d
+	}
+	return def
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: erpctl <%s> [flags] [args]\n", strings.Join(sortedKeys(commands), "|"))
+}
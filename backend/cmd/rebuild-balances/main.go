@@ -0,0 +1,66 @@
+// File: backend/cmd/rebuild-balances/main.go
+
+// Command rebuild-balances regenerates the account_balance_daily snapshot
+// table from journal_lines. Run it with -check to only report days where the
+// snapshots and the journal disagree.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/ramadhan22/dropship-erp/backend/internal/config"
+	"github.com/ramadhan22/dropship-erp/backend/internal/logutil"
+	"github.com/ramadhan22/dropship-erp/backend/internal/migrations"
+	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
+)
+
+func main() {
+	check := flag.Bool("check", false, "report drift without rebuilding")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		logutil.Fatalf("Fatal error loading config: %v", err)
+	}
+	repo, err := repository.NewPostgresRepository(cfg.Database.URL)
+	if err != nil {
+		logutil.Fatalf("DB connection failed: %v", err)
+	}
+	defer repo.DB.Close()
+	if err := migrations.Run(repo.DB.DB); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		logutil.Fatalf("DB migrations failed: %v", err)
+	}
+
+	ctx := context.Background()
+	if *check {
+		drift, err := repo.BalanceSnapshotRepo.FindDrift(ctx)
+		if err != nil {
+			logutil.Fatalf("check balance snapshots: %v", err)
+		}
+		for _, d := range drift {
+			log.Printf("drift account=%d shop=%q date=%s snapshot=%s/%s journal=%s/%s",
+				d.AccountID, d.ShopUsername, d.BalanceDate.Format("2006-01-02"),
+				d.SnapshotDebit, d.SnapshotCredit, d.JournalDebit, d.JournalCredit)
+		}
+		log.Printf("%d snapshot day(s) out of sync", len(drift))
+		return
+	}
+
+	tx, err := repo.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		logutil.Fatalf("begin tx: %v", err)
+	}
+	n, err := repository.NewBalanceSnapshotRepo(tx).Rebuild(ctx)
+	if err != nil {
+		tx.Rollback()
+		logutil.Fatalf("%v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		logutil.Fatalf("commit: %v", err)
+	}
+	log.Printf("rebuilt %d balance snapshot rows", n)
+}
@@ -0,0 +1,200 @@
+// Package apperr defines the typed errors returned by repositories, the
+// Shopee client and journal validation, and classifies them into the
+// failure categories stored in failed_reconciliations.error_type together
+// with a retry policy per category.
+package apperr
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/lib/pq"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/lifecycle"
+)
+
+// Category is a failure class. Its string value is what gets stored in
+// failed_reconciliations.error_type, so existing values must not change.
+type Category string
+
+const (
+	Timeout             Category = "timeout_error"
+	Database            Category = "database_error"
+	Network             Category = "network_error"
+	PurchaseNotFound    Category = "purchase_not_found"
+	ShopeeOrderNotFound Category = "shopee_order_not_found"
+	NotFound            Category = "not_found"
+	JournalCreation     Category = "journal_creation_error"
+	JournalBalance      Category = "journal_balance_error"
+	Authentication      Category = "authentication_error"
+	MissingCredentials  Category = "missing_credentials"
+	RateLimited         Category = "rate_limited"
+	ShopeeServer        Category = "shopee_server_error"
+	ShopeeRequest       Category = "shopee_request_error"
+	InvalidTransition   Category = "invalid_transition"
+	Unknown             Category = "unknown_error"
+)
+
+// retryable lists the categories worth retrying: the failure is transient
+// or depends on data that may still arrive (a purchase imported later).
+// Everything else is permanent and needs a person to fix the data first.
+var retryable = map[Category]bool{
+	Timeout:             true,
+	Database:            true,
+	Network:             true,
+	PurchaseNotFound:    true,
+	ShopeeOrderNotFound: true,
+	NotFound:            true,
+	JournalCreation:     true,
+	Authentication:      true,
+	RateLimited:         true,
+	ShopeeServer:        true,
+	Unknown:             true,
+}
+
+// Retryable reports whether failures of this category may succeed on retry.
+func (c Category) Retryable() bool { return retryable[c] }
+
+// Sentinel errors. Wrap them with fmt.Errorf("%w: ...") to add detail.
+var (
+	ErrRateLimited        = errors.New("rate limited")
+	ErrTokenExpired       = errors.New("access token expired or invalid")
+	ErrMissingCredentials = errors.New("missing shop credentials")
+	ErrUnbalancedJournal  = errors.New("unbalanced journal")
+)
+
+// Entities reported by NotFoundError.
+const (
+	EntityPurchase    = "dropship_purchase"
+	EntityShopeeOrder = "shopee_order"
+)
+
+// NotFoundError reports a missing row. It unwraps to sql.ErrNoRows so
+// existing errors.Is(err, sql.ErrNoRows) checks keep working.
+type NotFoundError struct {
+	Entity string
+	Key    string
+}
+
+func (e *NotFoundError) Error() string { return fmt.Sprintf("%s %s not found", e.Entity, e.Key) }
+
+func (e *NotFoundError) Unwrap() error { return sql.ErrNoRows }
+
+// NotFoundOr returns a NotFoundError when err is sql.ErrNoRows and err
+// unchanged otherwise.
+func NotFoundOr(err error, entity, key string) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return &NotFoundError{Entity: entity, Key: key}
+	}
+	return err
+}
+
+// Shopee error codes that mean the token must be refreshed or the caller
+// must slow down.
+var (
+	shopeeAuthCodes      = map[string]bool{"error_auth": true, "invalid_access_token": true, "invalid_acceess_token": true, "error_token": true}
+	shopeeRateLimitCodes = map[string]bool{"error_rate_limit": true, "error_busy": true}
+)
+
+// ShopeeAPIError is a non-OK HTTP status or an error code in a Shopee
+// response body.
+type ShopeeAPIError struct {
+	Op         string
+	HTTPStatus int
+	Code       string
+	Message    string
+}
+
+func (e *ShopeeAPIError) Error() string {
+	msg := fmt.Sprintf("shopee %s: status %d", e.Op, e.HTTPStatus)
+	if e.Code != "" {
+		msg += ": " + e.Code
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// Is matches ErrRateLimited and ErrTokenExpired from the status and code.
+func (e *ShopeeAPIError) Is(target error) bool {
+	switch target {
+	case ErrRateLimited:
+		return e.HTTPStatus == http.StatusTooManyRequests || shopeeRateLimitCodes[e.Code]
+	case ErrTokenExpired:
+		return e.HTTPStatus == http.StatusUnauthorized || shopeeAuthCodes[e.Code]
+	}
+	return false
+}
+
+// Error tags err with an explicit category, for failures whose cause alone
+// does not say which step failed.
+type Error struct {
+	Category Category
+	Err      error
+}
+
+func (e *Error) Error() string { return e.Err.Error() }
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Wrap tags err with category c. A nil err stays nil.
+func Wrap(c Category, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Category: c, Err: err}
+}
+
+// Classify returns the category of err, inspecting the wrap chain with
+// errors.Is and errors.As. The most specific cause wins: a missing purchase
+// reported while creating a journal is PurchaseNotFound.
+func Classify(err error) Category {
+	if err == nil {
+		return ""
+	}
+	var nf *NotFoundError
+	var sh *ShopeeAPIError
+	var pqErr *pq.Error
+	var netErr net.Error
+	var tagged *Error
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return Timeout
+	case errors.As(err, &nf):
+		switch nf.Entity {
+		case EntityPurchase:
+			return PurchaseNotFound
+		case EntityShopeeOrder:
+			return ShopeeOrderNotFound
+		}
+		return NotFound
+	case errors.Is(err, ErrUnbalancedJournal):
+		return JournalBalance
+	case errors.Is(err, lifecycle.ErrInvalidTransition):
+		return InvalidTransition
+	case errors.Is(err, ErrMissingCredentials):
+		return MissingCredentials
+	case errors.Is(err, ErrRateLimited):
+		return RateLimited
+	case errors.Is(err, ErrTokenExpired):
+		return Authentication
+	case errors.As(err, &sh):
+		if sh.HTTPStatus >= 500 {
+			return ShopeeServer
+		}
+		return ShopeeRequest
+	case errors.As(err, &pqErr), errors.Is(err, sql.ErrConnDone), errors.Is(err, sql.ErrTxDone), errors.Is(err, driver.ErrBadConn):
+		return Database
+	case errors.As(err, &netErr):
+		return Network
+	case errors.As(err, &tagged):
+		return tagged.Category
+	}
+	return Unknown
+}
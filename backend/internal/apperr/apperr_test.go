@@ -0,0 +1,47 @@
+package apperr
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestNotFoundUnwrapsToErrNoRows(t *testing.T) {
+	err := fmt.Errorf("fetch: %w", NotFoundOr(sql.ErrNoRows, EntityPurchase, "INV-1"))
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected errors.Is(err, sql.ErrNoRows)")
+	}
+	if got := Classify(err); got != PurchaseNotFound {
+		t.Errorf("Classify = %q, want %q", got, PurchaseNotFound)
+	}
+	other := errors.New("boom")
+	if NotFoundOr(other, EntityPurchase, "INV-1") != other {
+		t.Errorf("non-ErrNoRows errors must pass through")
+	}
+}
+
+func TestShopeeAPIErrorSentinels(t *testing.T) {
+	if !errors.Is(&ShopeeAPIError{HTTPStatus: 429}, ErrRateLimited) {
+		t.Errorf("429 should be ErrRateLimited")
+	}
+	if !errors.Is(&ShopeeAPIError{HTTPStatus: 200, Code: "invalid_access_token"}, ErrTokenExpired) {
+		t.Errorf("invalid_access_token should be ErrTokenExpired")
+	}
+	if errors.Is(&ShopeeAPIError{HTTPStatus: 200, Code: "error_param"}, ErrTokenExpired) {
+		t.Errorf("error_param is not a token error")
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	for _, c := range []Category{Timeout, Database, Network, RateLimited, ShopeeServer, PurchaseNotFound, Unknown} {
+		if !c.Retryable() {
+			t.Errorf("%s should be retryable", c)
+		}
+	}
+	for _, c := range []Category{JournalBalance, InvalidTransition, ShopeeRequest, MissingCredentials, Category("bogus")} {
+		if c.Retryable() {
+			t.Errorf("%s should be permanent", c)
+		}
+	}
+}
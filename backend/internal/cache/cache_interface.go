@@ -4,9 +4,14 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
+// ErrCacheMiss is wrapped by every Cache implementation when a key is absent
+// or expired, so callers can tell a miss apart from a backend failure.
+var ErrCacheMiss = errors.New("cache miss")
+
 // Cache defines the interface for caching operations
 type Cache interface {
 	// Get retrieves a value from cache
@@ -15,9 +20,19 @@ type Cache interface {
 	// Set stores a value in cache with TTL
 	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
 
+	// SetWithTags stores a value and labels it with tags so it can later be
+	// dropped together with every other entry sharing one of those tags
+	SetWithTags(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error
+
 	// Delete removes a value from cache
 	Delete(ctx context.Context, key string) error
 
+	// DeleteByPrefix removes every key starting with prefix
+	DeleteByPrefix(ctx context.Context, prefix string) error
+
+	// InvalidateTags removes every key labelled with any of the tags
+	InvalidateTags(ctx context.Context, tags ...string) error
+
 	// Exists checks if a key exists in cache
 	Exists(ctx context.Context, key string) (bool, error)
 
@@ -37,6 +52,7 @@ type CacheMetrics struct {
 	Misses    int64
 	Sets      int64
 	Deletes   int64
+	Evictions int64
 	Errors    int64
 	LastError string
 	HitRate   float64
@@ -52,4 +68,10 @@ type CacheConfig struct {
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
 	DefaultTTL   time.Duration
+
+	// MemoryMaxEntries bounds the in-process LRU cache.
+	MemoryMaxEntries int
+	// MemoryTTL caps how long the in-process tier of a TieredCache keeps
+	// entries it copied from Redis.
+	MemoryTTL time.Duration
 }
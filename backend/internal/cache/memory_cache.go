@@ -0,0 +1,223 @@
+// File: backend/internal/cache/memory_cache.go
+
+package cache
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// MemoryCache is a bounded in-process LRU cache. It needs no external
+// services, so it can be used on its own when Redis is not available or as
+// the front tier of a TieredCache.
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	defaultTTL time.Duration
+	ll         *list.List
+	items      map[string]*list.Element
+	tags       map[string]map[string]struct{}
+	metrics    atomicMetrics
+	now        func() time.Time
+}
+
+// memoryEntry is the value stored in each list element.
+type memoryEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+	tags      []string
+}
+
+// NewMemoryCache creates an LRU cache holding at most config.MemoryMaxEntries
+// entries. The least recently used entry is evicted when the cache is full.
+func NewMemoryCache(config CacheConfig) *MemoryCache {
+	if config.MemoryMaxEntries <= 0 {
+		config.MemoryMaxEntries = 10000
+	}
+	if config.DefaultTTL == 0 {
+		config.DefaultTTL = 5 * time.Minute
+	}
+	return &MemoryCache{
+		maxEntries: config.MemoryMaxEntries,
+		defaultTTL: config.DefaultTTL,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+		tags:       make(map[string]map[string]struct{}),
+		now:        time.Now,
+	}
+}
+
+// Get retrieves a value from cache
+func (m *MemoryCache) Get(ctx context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	el, ok := m.items[key]
+	if !ok {
+		atomic.AddInt64(&m.metrics.misses, 1)
+		return nil, fmt.Errorf("%w for key %s", ErrCacheMiss, key)
+	}
+	ent := el.Value.(*memoryEntry)
+	if m.now().After(ent.expiresAt) {
+		m.removeElement(el)
+		atomic.AddInt64(&m.metrics.misses, 1)
+		return nil, fmt.Errorf("%w for key %s", ErrCacheMiss, key)
+	}
+	m.ll.MoveToFront(el)
+	atomic.AddInt64(&m.metrics.hits, 1)
+
+	out := make([]byte, len(ent.value))
+	copy(out, ent.value)
+	return out, nil
+}
+
+// Set stores a value in cache with TTL
+func (m *MemoryCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return m.SetWithTags(ctx, key, value, ttl)
+}
+
+// SetWithTags stores a value and indexes it under each tag
+func (m *MemoryCache) SetWithTags(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error {
+	if ttl == 0 {
+		ttl = m.defaultTTL
+	}
+	stored := make([]byte, len(value))
+	copy(stored, value)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if el, ok := m.items[key]; ok {
+		m.removeElement(el)
+	}
+	ent := &memoryEntry{
+		key:       key,
+		value:     stored,
+		expiresAt: m.now().Add(ttl),
+		tags:      tags,
+	}
+	m.items[key] = m.ll.PushFront(ent)
+	for _, t := range tags {
+		keys := m.tags[t]
+		if keys == nil {
+			keys = make(map[string]struct{})
+			m.tags[t] = keys
+		}
+		keys[key] = struct{}{}
+	}
+	for m.ll.Len() > m.maxEntries {
+		m.removeElement(m.ll.Back())
+		atomic.AddInt64(&m.metrics.evictions, 1)
+	}
+	atomic.AddInt64(&m.metrics.sets, 1)
+	return nil
+}
+
+// Delete removes a value from cache
+func (m *MemoryCache) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if el, ok := m.items[key]; ok {
+		m.removeElement(el)
+	}
+	atomic.AddInt64(&m.metrics.deletes, 1)
+	return nil
+}
+
+// DeleteByPrefix removes every key starting with prefix
+func (m *MemoryCache) DeleteByPrefix(ctx context.Context, prefix string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key, el := range m.items {
+		if strings.HasPrefix(key, prefix) {
+			m.removeElement(el)
+			atomic.AddInt64(&m.metrics.deletes, 1)
+		}
+	}
+	return nil
+}
+
+// InvalidateTags removes every key labelled with any of the tags
+func (m *MemoryCache) InvalidateTags(ctx context.Context, tags ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, t := range tags {
+		for key := range m.tags[t] {
+			if el, ok := m.items[key]; ok {
+				m.removeElement(el)
+				atomic.AddInt64(&m.metrics.deletes, 1)
+			}
+		}
+		delete(m.tags, t)
+	}
+	return nil
+}
+
+// Exists checks if a key exists in cache
+func (m *MemoryCache) Exists(ctx context.Context, key string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	el, ok := m.items[key]
+	if !ok {
+		return false, nil
+	}
+	if m.now().After(el.Value.(*memoryEntry).expiresAt) {
+		m.removeElement(el)
+		return false, nil
+	}
+	return true, nil
+}
+
+// Len returns the number of entries currently held, including expired
+// entries that have not been touched since they expired.
+func (m *MemoryCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ll.Len()
+}
+
+// Close drops all entries
+func (m *MemoryCache) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.ll.Init()
+	m.items = make(map[string]*list.Element)
+	m.tags = make(map[string]map[string]struct{})
+	return nil
+}
+
+// Ping always succeeds for the in-process cache
+func (m *MemoryCache) Ping(ctx context.Context) error {
+	return nil
+}
+
+// GetMetrics returns cache statistics
+func (m *MemoryCache) GetMetrics() CacheMetrics {
+	return m.metrics.snapshot()
+}
+
+// removeElement unlinks an entry from the list, the key map and the tag
+// index. The caller must hold m.mu.
+func (m *MemoryCache) removeElement(el *list.Element) {
+	ent := m.ll.Remove(el).(*memoryEntry)
+	delete(m.items, ent.key)
+	for _, t := range ent.tags {
+		if keys, ok := m.tags[t]; ok {
+			delete(keys, ent.key)
+			if len(keys) == 0 {
+				delete(m.tags, t)
+			}
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemoryCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(CacheConfig{MemoryMaxEntries: 2})
+
+	_ = c.Set(ctx, "a", []byte("1"), 0)
+	_ = c.Set(ctx, "b", []byte("2"), 0)
+	if _, err := c.Get(ctx, "a"); err != nil {
+		t.Fatalf("get a: %v", err)
+	}
+	_ = c.Set(ctx, "c", []byte("3"), 0)
+
+	if _, err := c.Get(ctx, "b"); !errors.Is(err, ErrCacheMiss) {
+		t.Fatalf("expected b to be evicted, got %v", err)
+	}
+	if _, err := c.Get(ctx, "a"); err != nil {
+		t.Fatalf("expected a to survive: %v", err)
+	}
+	if m := c.GetMetrics(); m.Evictions != 1 {
+		t.Fatalf("expected 1 eviction, got %d", m.Evictions)
+	}
+}
+
+func TestMemoryCacheExpires(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(CacheConfig{})
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	_ = c.Set(ctx, "k", []byte("v"), time.Minute)
+	now = now.Add(2 * time.Minute)
+
+	if _, err := c.Get(ctx, "k"); !errors.Is(err, ErrCacheMiss) {
+		t.Fatalf("expected expired entry to miss, got %v", err)
+	}
+	if c.Len() != 0 {
+		t.Fatalf("expired entry not removed")
+	}
+}
+
+func TestMemoryCacheInvalidateTagsAndPrefix(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(CacheConfig{})
+
+	_ = c.SetWithTags(ctx, "report:pl:1", []byte("x"), 0, TagJournals, TagReports)
+	_ = c.SetWithTags(ctx, "purchases:1", []byte("y"), 0, TagPurchases)
+	_ = c.SetWithTags(ctx, "purchases:2", []byte("z"), 0, TagPurchases)
+
+	if err := c.InvalidateTags(ctx, TagJournals); err != nil {
+		t.Fatalf("invalidate: %v", err)
+	}
+	if ok, _ := c.Exists(ctx, "report:pl:1"); ok {
+		t.Fatalf("tagged entry should be gone")
+	}
+	if ok, _ := c.Exists(ctx, "purchases:1"); !ok {
+		t.Fatalf("untagged entry should remain")
+	}
+
+	if err := c.DeleteByPrefix(ctx, "purchases:"); err != nil {
+		t.Fatalf("delete by prefix: %v", err)
+	}
+	if c.Len() != 0 {
+		t.Fatalf("expected empty cache, got %d entries", c.Len())
+	}
+	if len(c.tags) != 0 {
+		t.Fatalf("tag index not cleaned up: %v", c.tags)
+	}
+}
+
+func TestTieredCacheBackfillAndInvalidate(t *testing.T) {
+	ctx := context.Background()
+	local := NewMemoryCache(CacheConfig{})
+	remote := NewMemoryCache(CacheConfig{})
+	tc := NewTieredCache(local, remote, CacheConfig{MemoryTTL: time.Minute})
+
+	// Written by another instance: only the remote tier has it.
+	_ = remote.SetWithTags(ctx, "report:bs", []byte("v"), time.Hour, TagReports)
+
+	if v, err := tc.Get(ctx, "report:bs"); err != nil || string(v) != "v" {
+		t.Fatalf("tiered get: %q %v", v, err)
+	}
+	if ok, _ := local.Exists(ctx, "report:bs"); !ok {
+		t.Fatalf("remote hit not copied to local tier")
+	}
+
+	if err := tc.InvalidateTags(ctx, TagReports); err != nil {
+		t.Fatalf("invalidate: %v", err)
+	}
+	if ok, _ := local.Exists(ctx, "report:bs"); ok {
+		t.Fatalf("backfilled local entry survived invalidation")
+	}
+	if ok, _ := remote.Exists(ctx, "report:bs"); ok {
+		t.Fatalf("remote entry survived invalidation")
+	}
+}
@@ -18,7 +18,7 @@ func NewNoopCache() *NoopCache {
 
 // Get always returns cache miss
 func (n *NoopCache) Get(ctx context.Context, key string) ([]byte, error) {
-	return nil, fmt.Errorf("cache miss (noop cache)")
+	return nil, fmt.Errorf("%w (noop cache)", ErrCacheMiss)
 }
 
 // Set does nothing
@@ -26,11 +26,26 @@ func (n *NoopCache) Set(ctx context.Context, key string, value []byte, ttl time.
 	return nil
 }
 
+// SetWithTags does nothing
+func (n *NoopCache) SetWithTags(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error {
+	return nil
+}
+
 // Delete does nothing
 func (n *NoopCache) Delete(ctx context.Context, key string) error {
 	return nil
 }
 
+// DeleteByPrefix does nothing
+func (n *NoopCache) DeleteByPrefix(ctx context.Context, prefix string) error {
+	return nil
+}
+
+// InvalidateTags does nothing
+func (n *NoopCache) InvalidateTags(ctx context.Context, tags ...string) error {
+	return nil
+}
+
 // Exists always returns false
 func (n *NoopCache) Exists(ctx context.Context, key string) (bool, error) {
 	return false, nil
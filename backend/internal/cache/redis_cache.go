@@ -5,6 +5,7 @@ package cache
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync/atomic"
 	"time"
 
@@ -24,10 +25,55 @@ type atomicMetrics struct {
 	misses    int64
 	sets      int64
 	deletes   int64
+	evictions int64
 	errors    int64
 	lastError atomic.Value
 }
 
+// snapshot converts the counters into a CacheMetrics value
+func (a *atomicMetrics) snapshot() CacheMetrics {
+	hits := atomic.LoadInt64(&a.hits)
+	misses := atomic.LoadInt64(&a.misses)
+	total := hits + misses
+
+	var hitRate float64
+	if total > 0 {
+		hitRate = float64(hits) / float64(total)
+	}
+
+	var lastError string
+	if err := a.lastError.Load(); err != nil {
+		lastError = err.(string)
+	}
+
+	return CacheMetrics{
+		Hits:      hits,
+		Misses:    misses,
+		Sets:      atomic.LoadInt64(&a.sets),
+		Deletes:   atomic.LoadInt64(&a.deletes),
+		Evictions: atomic.LoadInt64(&a.evictions),
+		Errors:    atomic.LoadInt64(&a.errors),
+		LastError: lastError,
+		HitRate:   hitRate,
+	}
+}
+
+// recordError counts a backend failure and remembers its message
+func (a *atomicMetrics) recordError(err error) {
+	atomic.AddInt64(&a.errors, 1)
+	a.lastError.Store(err.Error())
+}
+
+const (
+	// tagKeyPrefix namespaces the Redis sets that index keys by tag
+	tagKeyPrefix = "cachetag:"
+	// tagSetTTL bounds how long a tag index survives without new members,
+	// so sets for tags that are never invalidated do not grow forever
+	tagSetTTL = 24 * time.Hour
+	// scanBatchSize is the COUNT hint used when scanning keys by prefix
+	scanBatchSize = 500
+)
+
 // NewRedisCache creates a new Redis cache instance
 func NewRedisCache(config CacheConfig) (*RedisCache, error) {
 	// Set defaults if not provided
@@ -85,7 +131,7 @@ func (r *RedisCache) Get(ctx context.Context, key string) ([]byte, error) {
 	if err != nil {
 		if err == redis.Nil {
 			atomic.AddInt64(&r.metrics.misses, 1)
-			return nil, fmt.Errorf("cache miss for key %s", key)
+			return nil, fmt.Errorf("%w for key %s", ErrCacheMiss, key)
 		}
 		atomic.AddInt64(&r.metrics.errors, 1)
 		r.metrics.lastError.Store(err.Error())
@@ -126,6 +172,89 @@ func (r *RedisCache) Delete(ctx context.Context, key string) error {
 	return nil
 }
 
+// SetWithTags stores a value and adds its key to a Redis set per tag
+func (r *RedisCache) SetWithTags(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error {
+	if ttl == 0 {
+		ttl = r.config.DefaultTTL
+	}
+	tagTTL := tagSetTTL
+	if ttl > tagTTL {
+		tagTTL = ttl
+	}
+
+	pipe := r.client.TxPipeline()
+	pipe.Set(ctx, key, value, ttl)
+	for _, t := range tags {
+		pipe.SAdd(ctx, tagKeyPrefix+t, key)
+		pipe.Expire(ctx, tagKeyPrefix+t, tagTTL)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		r.metrics.recordError(err)
+		return fmt.Errorf("cache set error: %w", err)
+	}
+
+	atomic.AddInt64(&r.metrics.sets, 1)
+	return nil
+}
+
+// DeleteByPrefix removes every key starting with prefix using SCAN, since
+// DEL does not expand patterns
+func (r *RedisCache) DeleteByPrefix(ctx context.Context, prefix string) error {
+	iter := r.client.Scan(ctx, 0, escapeGlob(prefix)+"*", scanBatchSize).Iterator()
+	batch := make([]string, 0, scanBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		n, err := r.client.Del(ctx, batch...).Result()
+		if err != nil {
+			return err
+		}
+		atomic.AddInt64(&r.metrics.deletes, n)
+		batch = batch[:0]
+		return nil
+	}
+	for iter.Next(ctx) {
+		batch = append(batch, iter.Val())
+		if len(batch) >= scanBatchSize {
+			if err := flush(); err != nil {
+				r.metrics.recordError(err)
+				return fmt.Errorf("cache delete by prefix error: %w", err)
+			}
+		}
+	}
+	if err := iter.Err(); err != nil {
+		r.metrics.recordError(err)
+		return fmt.Errorf("cache scan error: %w", err)
+	}
+	if err := flush(); err != nil {
+		r.metrics.recordError(err)
+		return fmt.Errorf("cache delete by prefix error: %w", err)
+	}
+	return nil
+}
+
+// InvalidateTags removes every key recorded in the tag sets and the sets
+// themselves
+func (r *RedisCache) InvalidateTags(ctx context.Context, tags ...string) error {
+	for _, t := range tags {
+		tagKey := tagKeyPrefix + t
+		keys, err := r.client.SMembers(ctx, tagKey).Result()
+		if err != nil {
+			r.metrics.recordError(err)
+			return fmt.Errorf("cache tag lookup error: %w", err)
+		}
+		keys = append(keys, tagKey)
+		n, err := r.client.Del(ctx, keys...).Result()
+		if err != nil {
+			r.metrics.recordError(err)
+			return fmt.Errorf("cache invalidate tag error: %w", err)
+		}
+		atomic.AddInt64(&r.metrics.deletes, n)
+	}
+	return nil
+}
+
 // Exists checks if a key exists in cache
 func (r *RedisCache) Exists(ctx context.Context, key string) (bool, error) {
 	result, err := r.client.Exists(ctx, key).Result()
@@ -156,27 +285,10 @@ func (r *RedisCache) Ping(ctx context.Context) error {
 
 // GetMetrics returns cache statistics
 func (r *RedisCache) GetMetrics() CacheMetrics {
-	hits := atomic.LoadInt64(&r.metrics.hits)
-	misses := atomic.LoadInt64(&r.metrics.misses)
-	total := hits + misses
-
-	var hitRate float64
-	if total > 0 {
-		hitRate = float64(hits) / float64(total)
-	}
-
-	var lastError string
-	if err := r.metrics.lastError.Load(); err != nil {
-		lastError = err.(string)
-	}
+	return r.metrics.snapshot()
+}
 
-	return CacheMetrics{
-		Hits:      hits,
-		Misses:    misses,
-		Sets:      atomic.LoadInt64(&r.metrics.sets),
-		Deletes:   atomic.LoadInt64(&r.metrics.deletes),
-		Errors:    atomic.LoadInt64(&r.metrics.errors),
-		LastError: lastError,
-		HitRate:   hitRate,
-	}
+// escapeGlob escapes the characters Redis treats specially in MATCH patterns
+func escapeGlob(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`).Replace(s)
 }
@@ -0,0 +1,22 @@
+package cache
+
+// Tags shared by services that cache derived data. Writers invalidate the
+// tags describing what they changed; readers label cached summaries and
+// reports with the tags of the data they were computed from.
+const (
+	// TagPurchases covers anything derived from dropship_purchases.
+	TagPurchases = "purchases"
+	// TagSettlements covers anything derived from shopee_settled and
+	// related Shopee income data.
+	TagSettlements = "settlements"
+	// TagJournals covers anything derived from journal entries and lines,
+	// such as account balances.
+	TagJournals = "journals"
+	// TagReports covers rendered reports (dashboard, balance sheet, P&L).
+	TagReports = "reports"
+)
+
+// StoreTag returns the tag used for data scoped to a single store.
+func StoreTag(store string) string {
+	return "store:" + store
+}
@@ -0,0 +1,131 @@
+// File: backend/internal/cache/tiered_cache.go
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// backfillTag labels local entries copied from the remote tier. Their
+// original tags are only known to the remote tier, so every tag
+// invalidation also drops them.
+const backfillTag = "__tiered_backfill__"
+
+// TieredCache puts a MemoryCache in front of another Cache (normally Redis).
+// Reads are served from memory when possible; writes and invalidations go to
+// both tiers. Entries copied from the remote tier are kept locally for at
+// most MemoryTTL so other instances' invalidations are picked up quickly.
+type TieredCache struct {
+	local    *MemoryCache
+	remote   Cache
+	localTTL time.Duration
+}
+
+// NewTieredCache combines a local and a remote cache.
+func NewTieredCache(local *MemoryCache, remote Cache, config CacheConfig) *TieredCache {
+	localTTL := config.MemoryTTL
+	if localTTL <= 0 {
+		localTTL = 30 * time.Second
+	}
+	return &TieredCache{local: local, remote: remote, localTTL: localTTL}
+}
+
+// Get checks memory first and falls back to the remote tier
+func (t *TieredCache) Get(ctx context.Context, key string) ([]byte, error) {
+	if v, err := t.local.Get(ctx, key); err == nil {
+		return v, nil
+	}
+	v, err := t.remote.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	_ = t.local.SetWithTags(ctx, key, v, t.localTTL, backfillTag)
+	return v, nil
+}
+
+// Set stores a value in both tiers
+func (t *TieredCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return t.SetWithTags(ctx, key, value, ttl)
+}
+
+// SetWithTags stores a value with tags in both tiers
+func (t *TieredCache) SetWithTags(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error {
+	if err := t.remote.SetWithTags(ctx, key, value, ttl, tags...); err != nil {
+		return err
+	}
+	return t.local.SetWithTags(ctx, key, value, t.capLocalTTL(ttl), tags...)
+}
+
+// Delete removes a value from both tiers
+func (t *TieredCache) Delete(ctx context.Context, key string) error {
+	_ = t.local.Delete(ctx, key)
+	return t.remote.Delete(ctx, key)
+}
+
+// DeleteByPrefix removes matching keys from both tiers
+func (t *TieredCache) DeleteByPrefix(ctx context.Context, prefix string) error {
+	_ = t.local.DeleteByPrefix(ctx, prefix)
+	return t.remote.DeleteByPrefix(ctx, prefix)
+}
+
+// InvalidateTags removes tagged keys from both tiers
+func (t *TieredCache) InvalidateTags(ctx context.Context, tags ...string) error {
+	localTags := append(append([]string{}, tags...), backfillTag)
+	_ = t.local.InvalidateTags(ctx, localTags...)
+	return t.remote.InvalidateTags(ctx, tags...)
+}
+
+// Exists checks memory first and falls back to the remote tier
+func (t *TieredCache) Exists(ctx context.Context, key string) (bool, error) {
+	if ok, _ := t.local.Exists(ctx, key); ok {
+		return true, nil
+	}
+	return t.remote.Exists(ctx, key)
+}
+
+// Close closes both tiers
+func (t *TieredCache) Close() error {
+	return errors.Join(t.local.Close(), t.remote.Close())
+}
+
+// Ping checks the remote tier
+func (t *TieredCache) Ping(ctx context.Context) error {
+	return t.remote.Ping(ctx)
+}
+
+// GetMetrics reports hits from either tier; a miss is only counted when
+// neither tier had the key
+func (t *TieredCache) GetMetrics() CacheMetrics {
+	l := t.local.GetMetrics()
+	r := t.remote.GetMetrics()
+
+	hits := l.Hits + r.Hits
+	misses := r.Misses
+	var hitRate float64
+	if hits+misses > 0 {
+		hitRate = float64(hits) / float64(hits+misses)
+	}
+	lastError := r.LastError
+	if lastError == "" {
+		lastError = l.LastError
+	}
+	return CacheMetrics{
+		Hits:      hits,
+		Misses:    misses,
+		Sets:      r.Sets,
+		Deletes:   r.Deletes,
+		Evictions: l.Evictions + r.Evictions,
+		Errors:    l.Errors + r.Errors,
+		LastError: lastError,
+		HitRate:   hitRate,
+	}
+}
+
+func (t *TieredCache) capLocalTTL(ttl time.Duration) time.Duration {
+	if ttl <= 0 || ttl > t.localTTL {
+		return t.localTTL
+	}
+	return ttl
+}
@@ -23,6 +23,13 @@ type Config struct {
 	JWT         JWTConfig
 	Shopee      ShopeeAPIConfig `mapstructure:"shopee_api"`
 	Logging     LoggingConfig
+	Company     CompanyConfig
+	Reports     ReportsConfig
+	Expenses    ExpensesConfig
+	Attachments AttachmentsConfig
+	Tax         TaxConfig
+	Reconcile   ReconcileConfig
+	Tracing     TracingConfig
 	MaxThreads  int `mapstructure:"max_threads"`
 }
 
@@ -31,6 +38,9 @@ type ServerConfig struct {
 	Host        string
 	Port        string
 	CorsOrigins []string `mapstructure:"cors_origins"`
+	// ShutdownTimeout bounds how long SIGTERM waits for requests and
+	// background jobs to finish, e.g. "30s".
+	ShutdownTimeout string `mapstructure:"shutdown_timeout"`
 }
 
 // DatabaseConfig contains DB connection info.
@@ -46,8 +56,10 @@ type JWTConfig struct {
 	Secret string
 }
 
-// CacheConfig contains Redis cache settings.
+// CacheConfig contains cache settings. Backend selects "redis", "memory"
+// (in-process LRU) or "tiered" (memory in front of Redis).
 type CacheConfig struct {
+	Backend      string
 	RedisURL     string `mapstructure:"redis_url"`
 	Password     string
 	DB           int
@@ -57,6 +69,9 @@ type CacheConfig struct {
 	WriteTimeout string `mapstructure:"write_timeout"`
 	DefaultTTL   string `mapstructure:"default_ttl"`
 	Enabled      bool
+
+	MemoryMaxEntries int    `mapstructure:"memory_max_entries"`
+	MemoryTTL        string `mapstructure:"memory_ttl"`
 }
 
 // PerformanceConfig contains performance-related settings.
@@ -69,9 +84,98 @@ type PerformanceConfig struct {
 	EnableMetrics          bool   `mapstructure:"enable_metrics"`
 }
 
-// LoggingConfig specifies where log files are stored.
+// CompanyConfig is printed in the header of exported reports.
+type CompanyConfig struct {
+	Name    string
+	Address string
+	TaxID   string `mapstructure:"tax_id"`
+}
+
+// ExpensesConfig controls the recurring expense scheduler.
+type ExpensesConfig struct {
+	RecurringInterval string `mapstructure:"recurring_interval"`
+}
+
+// AttachmentsConfig selects where uploaded documents are kept. Storage is
+// "local" (files beneath Dir) or "s3" for an S3-compatible bucket.
+type AttachmentsConfig struct {
+	Storage   string
+	Dir       string
+	MaxSizeMB int `mapstructure:"max_size_mb"`
+	S3        S3Config
+}
+
+// TaxConfig controls the tax scheduler. Reminders for unpaid obligations
+// due within ReminderDays go through ReminderSink ("email", "webhook" or
+// "directory") to ReminderTarget; no reminders are sent when the sink is
+// empty.
+type TaxConfig struct {
+	Interval       string
+	ReminderDays   int    `mapstructure:"reminder_days"`
+	ReminderSink   string `mapstructure:"reminder_sink"`
+	ReminderTarget string `mapstructure:"reminder_target"`
+}
+
+// S3Config locates the attachment bucket. PathStyle addresses it as
+// endpoint/bucket, which MinIO and most self-hosted servers need.
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string `mapstructure:"access_key"`
+	SecretKey string `mapstructure:"secret_key"`
+	PathStyle bool   `mapstructure:"path_style"`
+}
+
+// ReportsConfig controls scheduled report delivery. OutputDir is the root
+// for the "directory" sink; subscription targets are resolved beneath it.
+type ReportsConfig struct {
+	DeliveryInterval string `mapstructure:"delivery_interval"`
+	MaxAttempts      int    `mapstructure:"max_attempts"`
+	RetryDelay       string `mapstructure:"retry_delay"`
+	OutputDir        string `mapstructure:"output_dir"`
+	WebhookTimeout   string `mapstructure:"webhook_timeout"`
+	SMTP             SMTPConfig
+}
+
+// ReconcileConfig controls the background retry of failed reconciliations.
+// RetryDelay is doubled after every failed attempt.
+type ReconcileConfig struct {
+	RetryInterval    string `mapstructure:"retry_interval"`
+	RetryBatchSize   int    `mapstructure:"retry_batch_size"`
+	RetryMaxAttempts int    `mapstructure:"retry_max_attempts"`
+	RetryDelay       string `mapstructure:"retry_delay"`
+}
+
+// SMTPConfig holds the mail server used by the "email" report sink.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// TracingConfig controls OpenTelemetry span export. Endpoint is the base
+// URL of an OTLP/HTTP receiver, e.g. a local collector on port 4318.
+type TracingConfig struct {
+	Enabled       bool
+	Endpoint      string
+	ServiceName   string  `mapstructure:"service_name"`
+	SampleRatio   float64 `mapstructure:"sample_ratio"`
+	FlushInterval string  `mapstructure:"flush_interval"`
+}
+
+// LoggingConfig specifies where log files are stored, how long they are
+// kept and the starting log levels. Level applies to every component;
+// Levels overrides it per component, e.g. {"reconcile-service": "debug"}.
+// Both can be changed at runtime through /api/admin/log-levels.
 type LoggingConfig struct {
-	Dir string
+	Dir               string
+	Level             string
+	Levels            map[string]string
+	CompressAfterDays int `mapstructure:"compress_after_days"`
+	RetentionDays     int `mapstructure:"retention_days"`
 }
 
 // ShopeeAPIConfig holds credentials for calling the Shopee Partner API.
@@ -102,8 +206,36 @@ func LoadConfig() (*Config, error) {
 	viper.AutomaticEnv()
 	// Default CORS origin for local development
 	viper.SetDefault("server.cors_origins", []string{"http://localhost:5173"})
+	viper.SetDefault("server.shutdown_timeout", "30s")
 	viper.SetDefault("logging.dir", "logs")
+	viper.SetDefault("logging.level", "info")
+	viper.SetDefault("logging.compress_after_days", 1)
+	viper.SetDefault("logging.retention_days", 30)
 	viper.SetDefault("max_threads", 5)
+	viper.SetDefault("company.name", "Dropship ERP")
+	viper.SetDefault("reports.delivery_interval", "1m")
+	viper.SetDefault("expenses.recurring_interval", "5m")
+	viper.SetDefault("attachments.storage", "local")
+	viper.SetDefault("attachments.dir", "attachments")
+	viper.SetDefault("attachments.max_size_mb", 20)
+	viper.SetDefault("attachments.s3.region", "us-east-1")
+	viper.SetDefault("attachments.s3.path_style", true)
+	viper.SetDefault("tax.interval", "1h")
+	viper.SetDefault("tax.reminder_days", 7)
+	viper.SetDefault("reports.max_attempts", 5)
+	viper.SetDefault("reports.retry_delay", "5m")
+	viper.SetDefault("reports.output_dir", "reports")
+	viper.SetDefault("reports.webhook_timeout", "30s")
+	viper.SetDefault("reports.smtp.port", 587)
+	viper.SetDefault("reconcile.retry_interval", "1m")
+	viper.SetDefault("reconcile.retry_batch_size", 100)
+	viper.SetDefault("reconcile.retry_max_attempts", 5)
+	viper.SetDefault("reconcile.retry_delay", "5m")
+	viper.SetDefault("tracing.enabled", false)
+	viper.SetDefault("tracing.endpoint", "http://localhost:4318")
+	viper.SetDefault("tracing.service_name", "dropship-erp")
+	viper.SetDefault("tracing.sample_ratio", 1.0)
+	viper.SetDefault("tracing.flush_interval", "5s")
 
 	// Cache defaults
 	viper.SetDefault("cache.enabled", false)
@@ -114,6 +246,9 @@ func LoadConfig() (*Config, error) {
 	viper.SetDefault("cache.read_timeout", "3s")
 	viper.SetDefault("cache.write_timeout", "3s")
 	viper.SetDefault("cache.default_ttl", "5m")
+	viper.SetDefault("cache.backend", "redis")
+	viper.SetDefault("cache.memory_max_entries", 10000)
+	viper.SetDefault("cache.memory_ttl", "30s")
 
 	// Database connection pool defaults
 	viper.SetDefault("database.max_open_conns", 25)
@@ -0,0 +1,63 @@
+// File: backend/internal/export/csv.go
+
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+// WriteCSV writes the document header followed by each section as a block of
+// rows separated by a blank line. Amounts are written without grouping so
+// spreadsheet tools read them as numbers.
+func WriteCSV(w io.Writer, doc *Document) error {
+	cw := csv.NewWriter(w)
+	if doc.Company.Name != "" {
+		if err := cw.Write([]string{doc.Company.Name}); err != nil {
+			return err
+		}
+	}
+	if err := cw.Write([]string{doc.Title}); err != nil {
+		return err
+	}
+	for _, l := range doc.headerLines() {
+		if err := cw.Write([]string{l[0], l[1]}); err != nil {
+			return err
+		}
+	}
+	for _, sec := range doc.Sections {
+		if err := cw.Write(nil); err != nil {
+			return err
+		}
+		if sec.Title != "" {
+			if err := cw.Write([]string{sec.Title}); err != nil {
+				return err
+			}
+		}
+		headers := make([]string, len(sec.Columns))
+		for i, col := range sec.Columns {
+			headers[i] = col.Header
+		}
+		if err := cw.Write(headers); err != nil {
+			return err
+		}
+		for _, row := range sec.Rows {
+			rec := make([]string, len(sec.Columns))
+			for i, col := range sec.Columns {
+				if i >= len(row.Cells) {
+					break
+				}
+				rec[i] = formatCell(row.Cells[i], col.Kind, false)
+				if i == 0 && row.Indent > 0 {
+					rec[i] = strings.Repeat("  ", row.Indent) + rec[i]
+				}
+			}
+			if err := cw.Write(rec); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,267 @@
+// File: backend/internal/export/document.go
+
+// Package export renders tabular reports into downloadable files. Services
+// describe a report as a Document; the writers in this package turn it into
+// XLSX, CSV or a printable PDF.
+package export
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
+)
+
+// Format identifies an output file type.
+type Format string
+
+const (
+	FormatXLSX Format = "xlsx"
+	FormatCSV  Format = "csv"
+	FormatPDF  Format = "pdf"
+)
+
+// ParseFormat validates a format name such as "xlsx", "csv" or "pdf".
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(s))); f {
+	case FormatXLSX, FormatCSV, FormatPDF:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unsupported export format %q", s)
+	}
+}
+
+// ContentType returns the MIME type for the format.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatPDF:
+		return "application/pdf"
+	}
+	return "application/octet-stream"
+}
+
+// ColumnKind controls how a column's values are formatted and aligned.
+type ColumnKind int
+
+const (
+	KindText ColumnKind = iota
+	KindMoney
+	KindNumber
+	KindPercent
+	KindDate
+)
+
+// Column describes one column of a section.
+type Column struct {
+	Header string
+	Kind   ColumnKind
+}
+
+// Row is one line of a section. Cells line up with the section's columns and
+// may hold string, numeric, time.Time or pointer-to values; nil renders empty.
+type Row struct {
+	Cells  []interface{}
+	Bold   bool
+	Indent int
+}
+
+// Section is a titled table within a document.
+type Section struct {
+	Title   string
+	Columns []Column
+	Rows    []Row
+}
+
+// CompanyInfo is printed at the top of every export.
+type CompanyInfo struct {
+	Name    string
+	Address string
+	TaxID   string
+}
+
+// Document is a complete report ready to be rendered.
+type Document struct {
+	Company     CompanyInfo
+	Title       string
+	Period      string
+	Store       string
+	GeneratedAt time.Time
+	Sections    []Section
+}
+
+// Filename builds a download name such as "balance-sheet_2025-01.xlsx".
+func (d *Document) Filename(base string, f Format) string {
+	name := base
+	if d.Period != "" {
+		name += "_" + sanitizeFilename(d.Period)
+	}
+	if d.Store != "" {
+		name += "_" + sanitizeFilename(d.Store)
+	}
+	return name + "." + string(f)
+}
+
+// Render writes the document in the requested format.
+func Render(w io.Writer, doc *Document, f Format) error {
+	switch f {
+	case FormatXLSX:
+		return WriteXLSX(w, doc)
+	case FormatCSV:
+		return WriteCSV(w, doc)
+	case FormatPDF:
+		return WritePDF(w, doc)
+	}
+	return fmt.Errorf("unsupported export format %q", f)
+}
+
+// headerLines returns the descriptive lines printed above the tables.
+func (d *Document) headerLines() [][2]string {
+	var lines [][2]string
+	if d.Company.Address != "" {
+		lines = append(lines, [2]string{"Address", d.Company.Address})
+	}
+	if d.Company.TaxID != "" {
+		lines = append(lines, [2]string{"NPWP", d.Company.TaxID})
+	}
+	if d.Period != "" {
+		lines = append(lines, [2]string{"Period", d.Period})
+	}
+	store := d.Store
+	if store == "" {
+		store = "All stores"
+	}
+	lines = append(lines, [2]string{"Store", store})
+	if !d.GeneratedAt.IsZero() {
+		lines = append(lines, [2]string{"Generated", d.GeneratedAt.Format("2006-01-02 15:04")})
+	}
+	return lines
+}
+
+// deref unwraps pointer cell values so writers only deal with plain values.
+func deref(v interface{}) interface{} {
+	switch x := v.(type) {
+	case *string:
+		if x == nil {
+			return nil
+		}
+		return *x
+	case *float64:
+		if x == nil {
+			return nil
+		}
+		return *x
+	case *money.Amount:
+		if x == nil {
+			return nil
+		}
+		return *x
+	case *int:
+		if x == nil {
+			return nil
+		}
+		return *x
+	case *int64:
+		if x == nil {
+			return nil
+		}
+		return *x
+	case *time.Time:
+		if x == nil {
+			return nil
+		}
+		return *x
+	}
+	return v
+}
+
+// toFloat reports the numeric value of a cell, if it has one.
+func toFloat(v interface{}) (float64, bool) {
+	switch x := deref(v).(type) {
+	case float64:
+		return x, true
+	case money.Amount:
+		return x.Float64(), true
+	case float32:
+		return float64(x), true
+	case int:
+		return float64(x), true
+	case int64:
+		return float64(x), true
+	case int32:
+		return float64(x), true
+	}
+	return 0, false
+}
+
+// formatCell renders a cell as display text for the given column kind.
+// Money uses thousands separators when grouped is true (PDF) and plain
+// digits otherwise (CSV) so spreadsheets can parse it.
+func formatCell(v interface{}, kind ColumnKind, grouped bool) string {
+	v = deref(v)
+	if v == nil {
+		return ""
+	}
+	if t, ok := v.(time.Time); ok {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format("2006-01-02")
+	}
+	if f, ok := toFloat(v); ok {
+		switch kind {
+		case KindMoney:
+			if grouped {
+				return groupThousands(f, 2)
+			}
+			return strconv.FormatFloat(f, 'f', 2, 64)
+		case KindPercent:
+			return strconv.FormatFloat(f, 'f', 2, 64) + "%"
+		case KindNumber:
+			if f == math.Trunc(f) {
+				return strconv.FormatFloat(f, 'f', 0, 64)
+			}
+			return strconv.FormatFloat(f, 'f', 2, 64)
+		}
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+// groupThousands formats f with "," thousands separators.
+func groupThousands(f float64, decimals int) string {
+	s := strconv.FormatFloat(math.Abs(f), 'f', decimals, 64)
+	intPart, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, frac = s[:i], s[i:]
+	}
+	var b strings.Builder
+	if f < 0 && s != strconv.FormatFloat(0, 'f', decimals, 64) {
+		b.WriteByte('-')
+	}
+	for i, r := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+	b.WriteString(frac)
+	return b.String()
+}
+
+func sanitizeFilename(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		}
+		return '-'
+	}, s)
+}
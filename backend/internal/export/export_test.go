@@ -0,0 +1,95 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func sampleDoc() *Document {
+	memo := "ongkir (koreksi)"
+	return &Document{
+		Company:     CompanyInfo{Name: "PT Contoh"},
+		Title:       "Balance Sheet",
+		Period:      "2025-01",
+		Store:       "TokoA",
+		GeneratedAt: time.Date(2025, 2, 1, 8, 0, 0, 0, time.UTC),
+		Sections: []Section{{
+			Title:   "Assets",
+			Columns: []Column{{Header: "Account", Kind: KindText}, {Header: "Balance", Kind: KindMoney}},
+			Rows: []Row{
+				{Cells: []interface{}{"Kas", 1234567.5}},
+				{Cells: []interface{}{&memo, nil}, Indent: 1},
+				{Cells: []interface{}{"Total Assets", 1234567.5}, Bold: true},
+			},
+		}},
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, sampleDoc()); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+	r := csv.NewReader(strings.NewReader(buf.String()))
+	r.FieldsPerRecord = -1
+	recs, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("read back csv: %v", err)
+	}
+	last := recs[len(recs)-1]
+	if last[0] != "Total Assets" || last[1] != "1234567.50" {
+		t.Fatalf("unexpected total row %v", last)
+	}
+	if !strings.Contains(buf.String(), "Store,TokoA") {
+		t.Fatalf("store filter missing from header:\n%s", buf.String())
+	}
+}
+
+func TestWriteXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteXLSX(&buf, sampleDoc()); err != nil {
+		t.Fatalf("WriteXLSX: %v", err)
+	}
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatalf("open xlsx: %v", err)
+	}
+	rows, err := f.GetRows(xlsxSheet, excelize.Options{RawCellValue: true})
+	if err != nil {
+		t.Fatalf("rows: %v", err)
+	}
+	last := rows[len(rows)-1]
+	if last[0] != "Total Assets" || last[1] != "1234567.5" {
+		t.Fatalf("unexpected total row %v", last)
+	}
+}
+
+func TestWritePDF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WritePDF(&buf, sampleDoc()); err != nil {
+		t.Fatalf("WritePDF: %v", err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "%PDF-1.4") || !strings.HasSuffix(out, "%%EOF\n") {
+		t.Fatalf("not a PDF document")
+	}
+	for _, want := range []string{"(PT Contoh)", "(1,234,567.50)", `(ongkir \(koreksi\))`, "(Page 1 of 1)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("PDF missing %s", want)
+		}
+	}
+}
+
+func TestGroupThousands(t *testing.T) {
+	cases := map[float64]string{0: "0.00", 999: "999.00", 1000: "1,000.00", -1234567.891: "-1,234,567.89", -0.001: "0.00"}
+	for in, want := range cases {
+		if got := groupThousands(in, 2); got != want {
+			t.Errorf("groupThousands(%v) = %q, want %q", in, got, want)
+		}
+	}
+}
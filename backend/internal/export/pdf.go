@@ -0,0 +1,296 @@
+// File: backend/internal/export/pdf.go
+
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// The PDF writer produces a plain, printable document using the standard
+// Helvetica fonts, so no font files or third-party libraries are needed.
+// Text outside Latin-1 is replaced with "?".
+
+const (
+	pdfMargin     = 36.0
+	pdfRowHeight  = 12.0
+	pdfFontSize   = 8.0
+	pdfCellPad    = 3.0
+	pdfFooterSize = 7.0
+)
+
+// helveticaWidths holds glyph widths (per 1000 em) for ASCII 32..126.
+var helveticaWidths = [...]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+func textWidth(s string, size float64, bold bool) float64 {
+	var w int
+	for _, r := range s {
+		if r >= 32 && r <= 126 {
+			w += helveticaWidths[r-32]
+		} else {
+			w += 556
+		}
+	}
+	width := float64(w) * size / 1000
+	if bold {
+		width *= 1.05
+	}
+	return width
+}
+
+// fitText shortens s with "..." so it fits in width points.
+func fitText(s string, width, size float64, bold bool) string {
+	if textWidth(s, size, bold) <= width {
+		return s
+	}
+	rs := []rune(s)
+	for len(rs) > 0 && textWidth(string(rs)+"...", size, bold) > width {
+		rs = rs[:len(rs)-1]
+	}
+	if len(rs) == 0 {
+		return ""
+	}
+	return string(rs) + "..."
+}
+
+// pdfString encodes s as a WinAnsi PDF literal string.
+func pdfString(s string) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 32 && r < 127:
+			b.WriteRune(r)
+		case r >= 160 && r <= 255:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+// pdfPage accumulates the content stream of one page.
+type pdfPage struct {
+	buf bytes.Buffer
+}
+
+func (p *pdfPage) text(x, y float64, s string, size float64, bold bool) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(&p.buf, "BT /%s %.1f Tf %.2f %.2f Td %s Tj ET\n", font, size, x, y, pdfString(s))
+}
+
+func (p *pdfPage) line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(&p.buf, "%.2f %.2f m %.2f %.2f l S\n", x1, y1, x2, y2)
+}
+
+func (p *pdfPage) fillRect(x, y, w, h float64, gray float64) {
+	fmt.Fprintf(&p.buf, "q %.2f g %.2f %.2f %.2f %.2f re f Q\n", gray, x, y, w, h)
+}
+
+// pdfLayout places the document onto pages.
+type pdfLayout struct {
+	doc           *Document
+	width, height float64
+	pages         []*pdfPage
+	cur           *pdfPage
+	y             float64
+}
+
+func (l *pdfLayout) newPage() {
+	l.cur = &pdfPage{}
+	l.pages = append(l.pages, l.cur)
+	l.y = l.height - pdfMargin
+	if len(l.pages) == 1 {
+		if l.doc.Company.Name != "" {
+			l.y -= 14
+			l.cur.text(pdfMargin, l.y, l.doc.Company.Name, 14, true)
+		}
+		l.y -= 16
+		l.cur.text(pdfMargin, l.y, l.doc.Title, 12, true)
+		l.y -= 4
+		for _, h := range l.doc.headerLines() {
+			l.y -= 11
+			l.cur.text(pdfMargin, l.y, h[0]+":", 9, true)
+			l.cur.text(pdfMargin+70, l.y, h[1], 9, false)
+		}
+		l.y -= 8
+	} else {
+		l.y -= 10
+		running := l.doc.Title
+		if l.doc.Period != "" {
+			running += " - " + l.doc.Period
+		}
+		l.cur.text(pdfMargin, l.y, running, 9, true)
+		l.y -= 8
+	}
+}
+
+func (l *pdfLayout) ensure(space float64) bool {
+	if l.y-space < pdfMargin+pdfRowHeight {
+		l.newPage()
+		return true
+	}
+	return false
+}
+
+func columnWeights(cols []Column) []float64 {
+	weights := make([]float64, len(cols))
+	var total float64
+	for i, c := range cols {
+		switch c.Kind {
+		case KindMoney:
+			weights[i] = 1.2
+		case KindNumber, KindPercent:
+			weights[i] = 0.8
+		case KindDate:
+			weights[i] = 1.0
+		default:
+			weights[i] = 1.5
+			if i == 0 {
+				weights[i] = 2.5
+			}
+		}
+		total += weights[i]
+	}
+	for i := range weights {
+		weights[i] /= total
+	}
+	return weights
+}
+
+func (l *pdfLayout) section(sec Section) {
+	avail := l.width - 2*pdfMargin
+	weights := columnWeights(sec.Columns)
+	widths := make([]float64, len(weights))
+	for i, w := range weights {
+		widths[i] = w * avail
+	}
+
+	header := func() {
+		l.cur.fillRect(pdfMargin, l.y-pdfRowHeight+2, avail, pdfRowHeight, 0.88)
+		l.row(sec.Columns, widths, nil, true, 0, true)
+	}
+
+	l.ensure(3 * pdfRowHeight)
+	if sec.Title != "" {
+		l.y -= 14
+		l.cur.text(pdfMargin, l.y, sec.Title, 10, true)
+		l.y -= 4
+	}
+	header()
+	for _, r := range sec.Rows {
+		if l.ensure(pdfRowHeight) {
+			header()
+		}
+		l.row(sec.Columns, widths, r.Cells, r.Bold, r.Indent, false)
+	}
+	l.y -= 6
+}
+
+func (l *pdfLayout) row(cols []Column, widths []float64, cells []interface{}, bold bool, indent int, isHeader bool) {
+	l.y -= pdfRowHeight
+	x := pdfMargin
+	baseline := l.y + 3
+	for i, col := range cols {
+		var s string
+		if isHeader {
+			s = col.Header
+		} else if i < len(cells) {
+			s = formatCell(cells[i], col.Kind, true)
+		}
+		inner := widths[i] - 2*pdfCellPad
+		pad := pdfCellPad
+		if i == 0 && indent > 0 {
+			pad += float64(indent) * 10
+			inner -= float64(indent) * 10
+		}
+		s = fitText(s, inner, pdfFontSize, bold)
+		tx := x + pad
+		if col.Kind != KindText && col.Kind != KindDate {
+			tx = x + widths[i] - pdfCellPad - textWidth(s, pdfFontSize, bold)
+		}
+		l.cur.text(tx, baseline, s, pdfFontSize, bold)
+		x += widths[i]
+	}
+	if isHeader {
+		l.cur.line(pdfMargin, l.y+1, l.width-pdfMargin, l.y+1)
+	}
+}
+
+// WritePDF renders the document as a printable PDF. Wide tables (more than
+// six columns) are laid out in landscape.
+func WritePDF(w io.Writer, doc *Document) error {
+	l := &pdfLayout{doc: doc, width: 595, height: 842}
+	for _, sec := range doc.Sections {
+		if len(sec.Columns) > 6 {
+			l.width, l.height = 842, 595
+			break
+		}
+	}
+	l.newPage()
+	for _, sec := range doc.Sections {
+		l.section(sec)
+	}
+
+	// Footers need the final page count.
+	for i, p := range l.pages {
+		footer := fmt.Sprintf("Page %d of %d", i+1, len(l.pages))
+		p.text(l.width-pdfMargin-textWidth(footer, pdfFooterSize, false), pdfMargin-12, footer, pdfFooterSize, false)
+	}
+
+	return writePDFObjects(w, l.pages, l.width, l.height)
+}
+
+func writePDFObjects(w io.Writer, pages []*pdfPage, width, height float64) error {
+	var out bytes.Buffer
+	var offsets []int
+	obj := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	// Objects 1-4 are fixed; each page then uses a page and a content object.
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	obj("<< /Type /Catalog /Pages 2 0 R >>")
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, p := range pages {
+		obj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			width, height, 6+2*i))
+		content := p.buf.String()
+		obj(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(content), content))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := w.Write(out.Bytes())
+	return err
+}
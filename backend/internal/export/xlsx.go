@@ -0,0 +1,205 @@
+// File: backend/internal/export/xlsx.go
+
+package export
+
+import (
+	"io"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// xlsxSheet is the single worksheet every export is written to.
+const xlsxSheet = "Report"
+
+// WriteXLSX renders the document onto one worksheet: the company header,
+// then each section with a bold header row. Numeric cells stay numeric and
+// carry a number format so totals can be recalculated in Excel.
+func WriteXLSX(w io.Writer, doc *Document) error {
+	f := excelize.NewFile()
+	defer f.Close()
+	if err := f.SetSheetName("Sheet1", xlsxSheet); err != nil {
+		return err
+	}
+
+	styles, err := newXLSXStyles(f)
+	if err != nil {
+		return err
+	}
+
+	row := 1
+	put := func(col int, v interface{}, style int) error {
+		cell, err := excelize.CoordinatesToCellName(col, row)
+		if err != nil {
+			return err
+		}
+		if err := f.SetCellValue(xlsxSheet, cell, v); err != nil {
+			return err
+		}
+		if style != 0 {
+			return f.SetCellStyle(xlsxSheet, cell, cell, style)
+		}
+		return nil
+	}
+
+	if doc.Company.Name != "" {
+		if err := put(1, doc.Company.Name, styles.title); err != nil {
+			return err
+		}
+		row++
+	}
+	if err := put(1, doc.Title, styles.title); err != nil {
+		return err
+	}
+	row++
+	for _, l := range doc.headerLines() {
+		if err := put(1, l[0], styles.bold); err != nil {
+			return err
+		}
+		if err := put(2, l[1], 0); err != nil {
+			return err
+		}
+		row++
+	}
+
+	maxCols := 2
+	for _, sec := range doc.Sections {
+		row++
+		if len(sec.Columns) > maxCols {
+			maxCols = len(sec.Columns)
+		}
+		if sec.Title != "" {
+			if err := put(1, sec.Title, styles.title); err != nil {
+				return err
+			}
+			row++
+		}
+		for i, col := range sec.Columns {
+			if err := put(i+1, col.Header, styles.header); err != nil {
+				return err
+			}
+		}
+		row++
+		for _, r := range sec.Rows {
+			for i, col := range sec.Columns {
+				if i >= len(r.Cells) {
+					break
+				}
+				v := deref(r.Cells[i])
+				if v == nil {
+					continue
+				}
+				style := styles.forKind(col.Kind, r.Bold)
+				if i == 0 && r.Indent > 0 {
+					style = styles.indented(r.Indent, r.Bold)
+				}
+				if col.Kind == KindDate {
+					v = formatCell(v, KindDate, false)
+				}
+				if err := put(i+1, v, style); err != nil {
+					return err
+				}
+			}
+			row++
+		}
+	}
+
+	last, err := excelize.ColumnNumberToName(maxCols)
+	if err != nil {
+		return err
+	}
+	if err := f.SetColWidth(xlsxSheet, "A", "A", 40); err != nil {
+		return err
+	}
+	if maxCols > 1 {
+		if err := f.SetColWidth(xlsxSheet, "B", last, 18); err != nil {
+			return err
+		}
+	}
+	_, err = f.WriteTo(w)
+	return err
+}
+
+type xlsxStyles struct {
+	f                    *excelize.File
+	title, bold, header  int
+	money, moneyBold     int
+	percent, percentBold int
+	number, numberBold   int
+	indent, indentBold   map[int]int
+}
+
+func newXLSXStyles(f *excelize.File) (*xlsxStyles, error) {
+	s := &xlsxStyles{f: f, indent: map[int]int{}, indentBold: map[int]int{}}
+	pct := `0.00"%"`
+	defs := []struct {
+		dst   *int
+		style *excelize.Style
+	}{
+		{&s.title, &excelize.Style{Font: &excelize.Font{Bold: true, Size: 13}}},
+		{&s.bold, &excelize.Style{Font: &excelize.Font{Bold: true}}},
+		{&s.header, &excelize.Style{
+			Font:   &excelize.Font{Bold: true},
+			Fill:   excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"DDEBF7"}},
+			Border: []excelize.Border{{Type: "bottom", Color: "000000", Style: 1}},
+		}},
+		{&s.money, &excelize.Style{NumFmt: 4}},
+		{&s.moneyBold, &excelize.Style{NumFmt: 4, Font: &excelize.Font{Bold: true}}},
+		{&s.percent, &excelize.Style{CustomNumFmt: &pct}},
+		{&s.percentBold, &excelize.Style{CustomNumFmt: &pct, Font: &excelize.Font{Bold: true}}},
+		{&s.number, &excelize.Style{NumFmt: 3}},
+		{&s.numberBold, &excelize.Style{NumFmt: 3, Font: &excelize.Font{Bold: true}}},
+	}
+	for _, d := range defs {
+		id, err := f.NewStyle(d.style)
+		if err != nil {
+			return nil, err
+		}
+		*d.dst = id
+	}
+	return s, nil
+}
+
+func (s *xlsxStyles) forKind(kind ColumnKind, bold bool) int {
+	switch kind {
+	case KindMoney:
+		if bold {
+			return s.moneyBold
+		}
+		return s.money
+	case KindPercent:
+		if bold {
+			return s.percentBold
+		}
+		return s.percent
+	case KindNumber:
+		if bold {
+			return s.numberBold
+		}
+		return s.number
+	}
+	if bold {
+		return s.bold
+	}
+	return 0
+}
+
+// indented returns a text style with the given indent level, creating it on
+// first use.
+func (s *xlsxStyles) indented(level int, bold bool) int {
+	cache := s.indent
+	if bold {
+		cache = s.indentBold
+	}
+	if id, ok := cache[level]; ok {
+		return id
+	}
+	id, err := s.f.NewStyle(&excelize.Style{
+		Font:      &excelize.Font{Bold: bold},
+		Alignment: &excelize.Alignment{Indent: level},
+	})
+	if err != nil {
+		return 0
+	}
+	cache[level] = id
+	return id
+}
@@ -5,12 +5,13 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 	"github.com/ramadhan22/dropship-erp/backend/internal/service"
 )
 
 type AssetAccountService interface {
 	ListBalances(ctx context.Context) ([]service.AssetAccountBalance, error)
-	AdjustBalance(ctx context.Context, id int64, newBal float64) error
+	AdjustBalance(ctx context.Context, id int64, newBal money.Amount) error
 }
 
 type AssetAccountHandler struct{ svc AssetAccountService }
@@ -41,7 +42,7 @@ func (h *AssetAccountHandler) adjust(c *gin.Context) {
 		return
 	}
 	var req struct {
-		Balance float64 `json:"balance"`
+		Balance money.Amount `json:"balance"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
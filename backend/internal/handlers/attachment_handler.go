@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/service"
+)
+
+// AttachmentService is implemented by service.AttachmentService.
+type AttachmentService interface {
+	Upload(ctx context.Context, fileName, contentType, description string, r io.Reader, links []models.AttachmentLink) (*models.Attachment, error)
+	Link(ctx context.Context, l models.AttachmentLink) error
+	Unlink(ctx context.Context, l models.AttachmentLink) error
+	Get(ctx context.Context, id int64) (*models.Attachment, error)
+	List(ctx context.Context, entityType, entityID string, limit, offset int) ([]models.Attachment, error)
+	Open(ctx context.Context, id int64) (*models.Attachment, io.ReadCloser, error)
+	Delete(ctx context.Context, id int64) error
+}
+
+type AttachmentHandler struct {
+	svc AttachmentService
+}
+
+func NewAttachmentHandler(svc AttachmentService) *AttachmentHandler {
+	return &AttachmentHandler{svc: svc}
+}
+
+func (h *AttachmentHandler) RegisterRoutes(r gin.IRouter) {
+	grp := r.Group("/attachments")
+	grp.GET("/", h.list)
+	grp.POST("/", h.upload)
+	grp.GET("/:id", h.get)
+	grp.GET("/:id/download", h.download)
+	grp.DELETE("/:id", h.delete)
+	grp.POST("/:id/links", h.link)
+	grp.DELETE("/:id/links", h.unlink)
+}
+
+// list handles GET /api/attachments?entity_type=&entity_id=.
+func (h *AttachmentHandler) list(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	size, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if size <= 0 {
+		size = 20
+	}
+	list, err := h.svc.List(c.Request.Context(), c.Query("entity_type"), c.Query("entity_id"), size, (page-1)*size)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// upload handles a multipart POST with a "file" part, an optional
+// "description" and an optional entity_type/entity_id pair to link it to.
+func (h *AttachmentHandler) upload(c *gin.Context) {
+	fh, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+	var links []models.AttachmentLink
+	if et, id := c.PostForm("entity_type"), c.PostForm("entity_id"); et != "" || id != "" {
+		links = append(links, models.AttachmentLink{EntityType: et, EntityID: id})
+	}
+	f, err := fh.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer f.Close()
+	a, err := h.svc.Upload(c.Request.Context(), fh.Filename, fh.Header.Get("Content-Type"), c.PostForm("description"), f, links)
+	if err != nil {
+		c.JSON(attachmentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, a)
+}
+
+func (h *AttachmentHandler) get(c *gin.Context) {
+	id, err := getIDParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	a, err := h.svc.Get(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if a == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return
+	}
+	c.JSON(http.StatusOK, a)
+}
+
+// download streams the stored file. Pass inline=1 to let the browser show
+// it instead of saving it; only images and PDFs are served inline, so an
+// uploaded HTML or SVG file cannot run script on this origin.
+func (h *AttachmentHandler) download(c *gin.Context) {
+	id, err := getIDParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	a, rc, err := h.svc.Open(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(attachmentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	defer rc.Close()
+	disposition := "attachment"
+	if c.Query("inline") == "1" && inlineContentType(a.ContentType) {
+		disposition = "inline"
+	}
+	c.DataFromReader(http.StatusOK, a.SizeBytes, a.ContentType, rc, map[string]string{
+		"Content-Disposition":    fmt.Sprintf("%s; filename=%q", disposition, a.FileName),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// inlineContentType reports whether a file of this type is safe to show in
+// the browser. SVG is an image type that can carry script, so it is
+// downloaded like everything else.
+func inlineContentType(ct string) bool {
+	mt, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return false
+	}
+	return mt == "application/pdf" || (strings.HasPrefix(mt, "image/") && mt != "image/svg+xml")
+}
+
+func (h *AttachmentHandler) delete(c *gin.Context) {
+	id, err := getIDParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	if err := h.svc.Delete(c.Request.Context(), id); err != nil {
+		c.JSON(attachmentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func (h *AttachmentHandler) link(c *gin.Context) {
+	id, err := getIDParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var l models.AttachmentLink
+	if err := c.ShouldBindJSON(&l); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	l.AttachmentID = id
+	if err := h.svc.Link(c.Request.Context(), l); err != nil {
+		c.JSON(attachmentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// unlink handles DELETE /api/attachments/:id/links?entity_type=&entity_id=.
+func (h *AttachmentHandler) unlink(c *gin.Context) {
+	id, err := getIDParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	l := models.AttachmentLink{AttachmentID: id, EntityType: c.Query("entity_type"), EntityID: c.Query("entity_id")}
+	if l.EntityType == "" || l.EntityID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "entity_type and entity_id are required"})
+		return
+	}
+	if err := h.svc.Unlink(c.Request.Context(), l); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func attachmentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidAttachment):
+		return http.StatusBadRequest
+	case errors.Is(err, sql.ErrNoRows):
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+)
+
+// fakeAttachmentService implements just Open; the other methods are not
+// called by download.
+type fakeAttachmentService struct {
+	AttachmentService
+	att *models.Attachment
+}
+
+func (f *fakeAttachmentService) Open(ctx context.Context, id int64) (*models.Attachment, io.ReadCloser, error) {
+	return f.att, io.NopCloser(strings.NewReader("data")), nil
+}
+
+func TestAttachmentDownloadServesOnlySafeTypesInline(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		contentType string
+		want        string
+	}{
+		{"image/png", "inline"},
+		{"application/pdf", "inline"},
+		{"text/html; charset=utf-8", "attachment"},
+		{"image/svg+xml", "attachment"},
+		{"application/octet-stream", "attachment"},
+	}
+	for _, tt := range tests {
+		svc := &fakeAttachmentService{att: &models.Attachment{ID: 1, FileName: "f", ContentType: tt.contentType, SizeBytes: 4}}
+		router := gin.New()
+		NewAttachmentHandler(svc).RegisterRoutes(router)
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest("GET", "/attachments/1/download?inline=1", nil))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d", tt.contentType, rec.Code)
+		}
+		if got := rec.Header().Get("Content-Disposition"); !strings.HasPrefix(got, tt.want+";") {
+			t.Errorf("%s: Content-Disposition = %q, want %s", tt.contentType, got, tt.want)
+		}
+		if got := rec.Header().Get("X-Content-Type-Options"); got != "nosniff" {
+			t.Errorf("%s: X-Content-Type-Options = %q", tt.contentType, got)
+		}
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 	"github.com/ramadhan22/dropship-erp/backend/internal/service"
 )
 
@@ -28,9 +29,9 @@ func (f *fakeBalanceService) GetBalanceSheet(ctx context.Context, shop string, a
 func TestHandleGetBalanceSheet_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	expected := []service.CategoryBalance{
-		{Category: "Assets", Total: 500},
-		{Category: "Liabilities", Total: -200},
-		{Category: "Equity", Total: 300},
+		{Category: "Assets", Total: money.New(500)},
+		{Category: "Liabilities", Total: money.New(-200)},
+		{Category: "Equity", Total: money.New(300)},
 	}
 	svc := &fakeBalanceService{data: expected}
 	h := NewBalanceHandler(svc)
@@ -50,7 +51,7 @@ func TestHandleGetBalanceSheet_Success(t *testing.T) {
 	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
 		t.Fatalf("json unmarshal: %v", err)
 	}
-	if len(got) != 3 || got[0].Total != 500 {
+	if len(got) != 3 || got[0].Total != money.New(500) {
 		t.Errorf("unexpected response: %+v", got)
 	}
 }
@@ -2,11 +2,15 @@ package handlers
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ramadhan22/dropship-erp/backend/internal/middleware"
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
 	"github.com/ramadhan22/dropship-erp/backend/internal/service"
 )
 
@@ -18,7 +22,10 @@ func NewBatchHandler(s *service.BatchService) *BatchHandler { return &BatchHandl
 func (h *BatchHandler) RegisterRoutes(r gin.IRouter) {
 	grp := r.Group("/batches")
 	grp.GET("/", h.list)
+	grp.GET("/filtered", middleware.FilterMiddlewareFor(models.ResourceBatches), h.listFiltered)
 	grp.GET("/:id/details", h.details)
+	grp.POST("/:id/retry", h.retry)
+	grp.POST("/:id/cancel", h.cancel)
 }
 
 func (h *BatchHandler) list(c *gin.Context) {
@@ -48,6 +55,11 @@ func (h *BatchHandler) list(c *gin.Context) {
 	c.JSON(http.StatusOK, list)
 }
 
+func (h *BatchHandler) listFiltered(c *gin.Context) {
+	result, err := h.svc.ListQuery(c.Request.Context(), middleware.GetFilterParams(c))
+	writeQueryResult(c, result, err)
+}
+
 func (h *BatchHandler) details(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
@@ -62,3 +74,41 @@ func (h *BatchHandler) details(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, list)
 }
+
+func (h *BatchHandler) retry(c *gin.Context) {
+	id, err := getIDParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	h.respondBatchAction(c, id, h.svc.Retry(c.Request.Context(), id))
+}
+
+func (h *BatchHandler) cancel(c *gin.Context) {
+	id, err := getIDParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	h.respondBatchAction(c, id, h.svc.Cancel(c.Request.Context(), id, c.Query("reason")))
+}
+
+func (h *BatchHandler) respondBatchAction(c *gin.Context, id int64, err error) {
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrBatchState):
+			status = http.StatusBadRequest
+		case errors.Is(err, sql.ErrNoRows):
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	b, err := h.svc.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, b)
+}
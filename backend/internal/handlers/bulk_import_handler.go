@@ -52,7 +52,6 @@ func (h *BulkImportHandler) HandleBulkImport(c *gin.Context) {
 	}
 
 	// Get optional parameters
-	channel := c.PostForm("channel")
 	useStreaming := c.PostForm("use_streaming") == "true"
 	processConcurrently := c.PostForm("process_concurrently") == "true"
 
@@ -63,7 +62,6 @@ func (h *BulkImportHandler) HandleBulkImport(c *gin.Context) {
 	}
 
 	// Save files and create batch records
-	var filePaths []string
 	var batchIDs []int64
 
 	for _, fileHeader := range files {
@@ -92,8 +90,6 @@ func (h *BulkImportHandler) HandleBulkImport(c *gin.Context) {
 			return
 		}
 
-		filePaths = append(filePaths, filePath)
-
 		// Create batch record
 		processType := "dropship_import"
 		if useStreaming {
@@ -120,17 +116,11 @@ func (h *BulkImportHandler) HandleBulkImport(c *gin.Context) {
 		batchIDs = append(batchIDs, batchID)
 	}
 
-	// Process files
-	if processConcurrently && h.streamingProcessor != nil {
-		// Process files concurrently using streaming processor
-		go func() {
-			ctx := context.Background()
-			if err := h.streamingProcessor.ProcessMultipleFiles(ctx, filePaths, channel); err != nil {
-				// Log error but don't fail the request since it's async
-				fmt.Printf("Error processing files concurrently: %v\n", err)
-			}
-		}()
-	}
+	// The batches are pending, so the import schedulers pick them up; the
+	// enhanced scheduler already works several files at once. They run under
+	// the supervisor, which drains them on shutdown and resumes the ones it
+	// had to abort, so processing the files here as well would import them
+	// twice and outside that control.
 
 	response := gin.H{
 		"queued_files":         len(files),
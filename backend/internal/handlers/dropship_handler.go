@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"io"
 	"net/http"
 	"os"
@@ -12,6 +14,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/ramadhan22/dropship-erp/backend/internal/middleware"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
 	"github.com/ramadhan22/dropship-erp/backend/internal/service"
 )
@@ -21,13 +24,14 @@ type DropshipServiceInterface interface {
 	ImportFromCSV(ctx context.Context, r io.Reader, channel string, batchID int64) (int, error)
 	ListDropshipPurchases(ctx context.Context, channel, store, from, to, orderNo, sortBy, dir string, limit, offset int) ([]models.DropshipPurchase, int, error)
 	ListDropshipPurchasesFiltered(ctx context.Context, params *models.FilterParams) (*models.QueryResult, error)
-	SumDropshipPurchases(ctx context.Context, channel, store, from, to string) (float64, error)
+	SumDropshipPurchases(ctx context.Context, channel, store, from, to string) (money.Amount, error)
 	GetDropshipPurchaseByID(ctx context.Context, kodePesanan string) (*models.DropshipPurchase, error)
 	ListDropshipPurchaseDetails(ctx context.Context, kodePesanan string) ([]models.DropshipPurchaseDetail, error)
 	TopProducts(ctx context.Context, channel, store, from, to string, limit int) ([]models.ProductSales, error)
 	DailyTotals(ctx context.Context, channel, store, from, to string) ([]repository.DailyPurchaseTotal, error)
 	MonthlyTotals(ctx context.Context, channel, store, from, to string) ([]repository.MonthlyPurchaseTotal, error)
 	CancelledSummary(ctx context.Context, channel, store, from, to string) (repository.CancelledSummary, error)
+	GetPurchaseTimeline(ctx context.Context, kodePesanan string) (*models.PurchaseTimeline, error)
 }
 
 type DropshipHandler struct {
@@ -108,12 +112,7 @@ func (h *DropshipHandler) HandleListFiltered(c *gin.Context) {
 	filterParams := middleware.GetFilterParams(c)
 
 	result, err := h.svc.ListDropshipPurchasesFiltered(context.Background(), filterParams)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	c.JSON(http.StatusOK, result)
+	writeQueryResult(c, result, err)
 }
 
 // HandleSum returns the sum of total_transaksi for all data matching filters.
@@ -142,6 +141,20 @@ func (h *DropshipHandler) HandleListDetails(c *gin.Context) {
 	c.JSON(http.StatusOK, details)
 }
 
+// HandleTimeline returns the lifecycle state and status history of a purchase.
+func (h *DropshipHandler) HandleTimeline(c *gin.Context) {
+	tl, err := h.svc.GetPurchaseTimeline(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "purchase not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, tl)
+}
+
 // HandleTopProducts returns aggregated sales by product.
 func (h *DropshipHandler) HandleTopProducts(c *gin.Context) {
 	channel := c.Query("channel")
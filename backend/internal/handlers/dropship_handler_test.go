@@ -5,6 +5,8 @@ package handlers
 import (
 	"bytes"
 	"context"
+	"database/sql"
+	"encoding/json"
 	"errors"
 	"io"
 	"mime/multipart"
@@ -14,7 +16,9 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ramadhan22/dropship-erp/backend/internal/lifecycle"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
 )
 
@@ -44,7 +48,7 @@ func (f *fakeDropshipService) ListDropshipPurchaseDetails(ctx context.Context, k
 	return nil, nil
 }
 
-func (f *fakeDropshipService) SumDropshipPurchases(ctx context.Context, channel, store, from, to string) (float64, error) {
+func (f *fakeDropshipService) SumDropshipPurchases(ctx context.Context, channel, store, from, to string) (money.Amount, error) {
 	return 0, nil
 }
 
@@ -74,6 +78,21 @@ func (f *fakeDropshipService) CancelledSummary(ctx context.Context, channel, sto
 	return repository.CancelledSummary{}, nil
 }
 
+func (f *fakeDropshipService) GetPurchaseTimeline(ctx context.Context, kode string) (*models.PurchaseTimeline, error) {
+	if kode != "PS-1" {
+		return nil, sql.ErrNoRows
+	}
+	return &models.PurchaseTimeline{
+		KodePesanan: kode,
+		Status:      lifecycle.Settled,
+		Next:        lifecycle.Settled.Next(),
+		Events: []models.PurchaseStatusEvent{
+			{KodePesanan: kode, ToStatus: lifecycle.Imported, Cause: lifecycle.CauseImport},
+			{KodePesanan: kode, ToStatus: lifecycle.Settled, Cause: lifecycle.CauseEscrowSettlement},
+		},
+	}, nil
+}
+
 func TestHandleImport_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	svc := &fakeDropshipService{}
@@ -144,3 +163,29 @@ func TestHandleImport_ServiceError(t *testing.T) {
 		t.Fatalf("expected 200, got %d", rec.Code)
 	}
 }
+
+func TestHandleTimeline(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := NewDropshipHandler(&fakeDropshipService{}, nil)
+	router := gin.New()
+	router.GET("/api/dropship/purchases/:id/timeline", h.HandleTimeline)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/api/dropship/purchases/PS-1/timeline", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var tl models.PurchaseTimeline
+	if err := json.Unmarshal(rec.Body.Bytes(), &tl); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if tl.Status != lifecycle.Settled || len(tl.Events) != 2 || tl.Events[1].Cause != lifecycle.CauseEscrowSettlement {
+		t.Errorf("unexpected timeline %+v", tl)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/api/dropship/purchases/missing/timeline", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", rec.Code)
+	}
+}
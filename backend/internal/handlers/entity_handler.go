@@ -0,0 +1,329 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
+	"github.com/ramadhan22/dropship-erp/backend/internal/service"
+)
+
+// EntityService is implemented by service.EntityService.
+type EntityService interface {
+	ListEntities(ctx context.Context) ([]models.Entity, error)
+	GetEntity(ctx context.Context, id int64) (*models.Entity, error)
+	CreateEntity(ctx context.Context, e *models.Entity) error
+	UpdateEntity(ctx context.Context, e *models.Entity) error
+	DeleteEntity(ctx context.Context, id int64) error
+	AssignStore(ctx context.Context, entityID int64, store string) error
+	ListStores(ctx context.Context, entityID int64) ([]string, error)
+	ListAccounts(ctx context.Context, entityID int64) ([]models.Account, error)
+	BalanceSheet(ctx context.Context, entityID int64, asOf time.Time) ([]service.CategoryBalance, error)
+	ProfitLoss(ctx context.Context, entityID int64, typ string, month, year int) (*service.ProfitLoss, error)
+	TrialBalance(ctx context.Context, entityID int64, from, to time.Time) (*service.TrialBalance, error)
+	Consolidated(ctx context.Context, from, to time.Time) (*service.ConsolidatedReport, error)
+	ListIntercompany(ctx context.Context, entityID int64, from, to time.Time) ([]models.IntercompanyTransaction, error)
+	CreateIntercompany(ctx context.Context, t *models.IntercompanyTransaction) error
+	ReverseIntercompany(ctx context.Context, id int64) (*models.IntercompanyTransaction, error)
+}
+
+type EntityHandler struct {
+	svc EntityService
+}
+
+func NewEntityHandler(svc EntityService) *EntityHandler {
+	return &EntityHandler{svc: svc}
+}
+
+func (h *EntityHandler) RegisterRoutes(r gin.IRouter) {
+	grp := r.Group("/entities")
+	grp.GET("/", h.list)
+	grp.POST("/", h.create)
+	grp.GET("/:id", h.get)
+	grp.PUT("/:id", h.update)
+	grp.DELETE("/:id", h.delete)
+	grp.GET("/:id/stores", h.stores)
+	grp.PUT("/:id/stores/:store", h.assignStore)
+	grp.GET("/:id/accounts", h.accounts)
+	grp.GET("/:id/balance-sheet", h.balanceSheet)
+	grp.GET("/:id/profit-loss", h.profitLoss)
+	grp.GET("/:id/trial-balance", h.trialBalance)
+
+	r.GET("/consolidated-report", h.consolidated)
+
+	ic := r.Group("/intercompany-transactions")
+	ic.GET("/", h.listIntercompany)
+	ic.POST("/", h.createIntercompany)
+	ic.POST("/:id/reverse", h.reverseIntercompany)
+}
+
+func (h *EntityHandler) list(c *gin.Context) {
+	list, err := h.svc.ListEntities(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *EntityHandler) create(c *gin.Context) {
+	var e models.Entity
+	if err := c.ShouldBindJSON(&e); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.svc.CreateEntity(c.Request.Context(), &e); err != nil {
+		c.JSON(entityErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, e)
+}
+
+func (h *EntityHandler) get(c *gin.Context) {
+	id, err := getIDParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	e, err := h.svc.GetEntity(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(entityErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, e)
+}
+
+func (h *EntityHandler) update(c *gin.Context) {
+	id, err := getIDParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var e models.Entity
+	if err := c.ShouldBindJSON(&e); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	e.ID = id
+	if err := h.svc.UpdateEntity(c.Request.Context(), &e); err != nil {
+		c.JSON(entityErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, e)
+}
+
+func (h *EntityHandler) delete(c *gin.Context) {
+	id, err := getIDParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	if err := h.svc.DeleteEntity(c.Request.Context(), id); err != nil {
+		c.JSON(entityErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *EntityHandler) stores(c *gin.Context) {
+	id, err := getIDParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	list, err := h.svc.ListStores(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *EntityHandler) assignStore(c *gin.Context) {
+	id, err := getIDParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	if err := h.svc.AssignStore(c.Request.Context(), id, c.Param("store")); err != nil {
+		c.JSON(entityErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+func (h *EntityHandler) accounts(c *gin.Context) {
+	id, err := getIDParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	list, err := h.svc.ListAccounts(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// balanceSheet takes as_of=YYYY-MM-DD, defaulting to today.
+func (h *EntityHandler) balanceSheet(c *gin.Context) {
+	id, err := getIDParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	asOf := time.Now()
+	if s := c.Query("as_of"); s != "" {
+		t, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid as_of, use YYYY-MM-DD"})
+			return
+		}
+		asOf = t
+	}
+	res, err := h.svc.BalanceSheet(c.Request.Context(), id, asOf)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// profitLoss takes type=Monthly|Yearly, month and year like /profitloss.
+func (h *EntityHandler) profitLoss(c *gin.Context) {
+	id, err := getIDParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	month, _ := strconv.Atoi(c.DefaultQuery("month", "0"))
+	year, err := strconv.Atoi(c.Query("year"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid year"})
+		return
+	}
+	res, err := h.svc.ProfitLoss(c.Request.Context(), id, c.DefaultQuery("type", "Monthly"), month, year)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// trialBalance takes period=YYYY-MM or from/to like /trialbalance.
+func (h *EntityHandler) trialBalance(c *gin.Context) {
+	id, err := getIDParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	_, from, to, _, err := parseStatementQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	res, err := h.svc.TrialBalance(c.Request.Context(), id, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// consolidated takes period=YYYY-MM or from/to like /trialbalance.
+func (h *EntityHandler) consolidated(c *gin.Context) {
+	_, from, to, _, err := parseStatementQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	res, err := h.svc.Consolidated(c.Request.Context(), from, to)
+	if err != nil {
+		c.JSON(entityErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// listIntercompany takes period=YYYY-MM or from/to and an optional
+// entity_id.
+func (h *EntityHandler) listIntercompany(c *gin.Context) {
+	_, from, to, _, err := parseStatementQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	entityID, _ := strconv.ParseInt(c.Query("entity_id"), 10, 64)
+	list, err := h.svc.ListIntercompany(c.Request.Context(), entityID, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *EntityHandler) createIntercompany(c *gin.Context) {
+	var req struct {
+		FromEntityID  int64        `json:"from_entity_id" binding:"required"`
+		ToEntityID    int64        `json:"to_entity_id" binding:"required"`
+		TxnDate       string       `json:"txn_date" binding:"required"`
+		Amount        money.Amount `json:"amount"`
+		FromAccountID int64        `json:"from_account_id" binding:"required"`
+		ToAccountID   int64        `json:"to_account_id" binding:"required"`
+		Description   string       `json:"description"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	date, err := time.Parse("2006-01-02", req.TxnDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid txn_date, use YYYY-MM-DD"})
+		return
+	}
+	t := models.IntercompanyTransaction{
+		FromEntityID:  req.FromEntityID,
+		ToEntityID:    req.ToEntityID,
+		TxnDate:       date,
+		Amount:        req.Amount,
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Description:   req.Description,
+	}
+	if err := h.svc.CreateIntercompany(c.Request.Context(), &t); err != nil {
+		c.JSON(entityErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, t)
+}
+
+func (h *EntityHandler) reverseIntercompany(c *gin.Context) {
+	id, err := getIDParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	t, err := h.svc.ReverseIntercompany(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(entityErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, t)
+}
+
+func entityErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidEntity):
+		return http.StatusBadRequest
+	case errors.Is(err, sql.ErrNoRows):
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/service"
+)
+
+// ExpenseAllocationService is implemented by service.ExpenseAllocationService.
+type ExpenseAllocationService interface {
+	CreateRule(ctx context.Context, rule *models.ExpenseAllocationRule) error
+	UpdateRule(ctx context.Context, rule *models.ExpenseAllocationRule) error
+	DeleteRule(ctx context.Context, id int64) error
+	GetRule(ctx context.Context, id int64) (*models.ExpenseAllocationRule, error)
+	ListRules(ctx context.Context) ([]models.ExpenseAllocationRule, error)
+	Shares(ctx context.Context, ruleID int64, from, to time.Time) ([]models.StoreShare, error)
+	RunPeriod(ctx context.Context, ruleID int64, from, to time.Time) ([]models.ExpenseAllocation, error)
+	Reverse(ctx context.Context, id int64) error
+	ListAllocations(ctx context.Context, expenseID string, limit, offset int) ([]models.ExpenseAllocation, error)
+}
+
+type ExpenseAllocationHandler struct {
+	svc ExpenseAllocationService
+}
+
+func NewExpenseAllocationHandler(svc ExpenseAllocationService) *ExpenseAllocationHandler {
+	return &ExpenseAllocationHandler{svc: svc}
+}
+
+func (h *ExpenseAllocationHandler) RegisterRoutes(r gin.IRouter) {
+	rules := r.Group("/expense-allocation-rules")
+	rules.GET("/", h.listRules)
+	rules.POST("/", h.createRule)
+	rules.GET("/:id", h.getRule)
+	rules.PUT("/:id", h.updateRule)
+	rules.DELETE("/:id", h.deleteRule)
+	rules.GET("/:id/shares", h.shares)
+
+	allocs := r.Group("/expense-allocations")
+	allocs.GET("/", h.list)
+	allocs.POST("/run", h.run)
+	allocs.POST("/:id/reverse", h.reverse)
+}
+
+func (h *ExpenseAllocationHandler) listRules(c *gin.Context) {
+	list, err := h.svc.ListRules(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *ExpenseAllocationHandler) createRule(c *gin.Context) {
+	var rule models.ExpenseAllocationRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.svc.CreateRule(c.Request.Context(), &rule); err != nil {
+		c.JSON(expenseAllocationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, rule)
+}
+
+func (h *ExpenseAllocationHandler) getRule(c *gin.Context) {
+	id, err := getIDParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	rule, err := h.svc.GetRule(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if rule == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return
+	}
+	c.JSON(http.StatusOK, rule)
+}
+
+func (h *ExpenseAllocationHandler) updateRule(c *gin.Context) {
+	id, err := getIDParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var rule models.ExpenseAllocationRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	rule.ID = id
+	if err := h.svc.UpdateRule(c.Request.Context(), &rule); err != nil {
+		c.JSON(expenseAllocationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, rule)
+}
+
+func (h *ExpenseAllocationHandler) deleteRule(c *gin.Context) {
+	id, err := getIDParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	if err := h.svc.DeleteRule(c.Request.Context(), id); err != nil {
+		c.JSON(expenseAllocationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// shares handles GET /api/expense-allocation-rules/:id/shares?from=&to=,
+// previewing how the rule splits an expense by that period's figures.
+func (h *ExpenseAllocationHandler) shares(c *gin.Context) {
+	id, err := getIDParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	from, to, ok := allocationPeriod(c, c.Query("from"), c.Query("to"))
+	if !ok {
+		return
+	}
+	shares, err := h.svc.Shares(c.Request.Context(), id, from, to)
+	if err != nil {
+		c.JSON(expenseAllocationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, shares)
+}
+
+func (h *ExpenseAllocationHandler) list(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	size, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if size <= 0 {
+		size = 20
+	}
+	list, err := h.svc.ListAllocations(c.Request.Context(), c.Query("expense_id"), size, (page-1)*size)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// run handles POST /api/expense-allocations/run, allocating every
+// unallocated overhead expense of a period by the given rule.
+func (h *ExpenseAllocationHandler) run(c *gin.Context) {
+	var req struct {
+		RuleID int64  `json:"rule_id" binding:"required"`
+		From   string `json:"from" binding:"required"`
+		To     string `json:"to" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	from, to, ok := allocationPeriod(c, req.From, req.To)
+	if !ok {
+		return
+	}
+	list, err := h.svc.RunPeriod(c.Request.Context(), req.RuleID, from, to)
+	if err != nil {
+		c.JSON(expenseAllocationErrorStatus(err), gin.H{"error": err.Error(), "allocations": list})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *ExpenseAllocationHandler) reverse(c *gin.Context) {
+	id, err := getIDParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	if err := h.svc.Reverse(c.Request.Context(), id); err != nil {
+		c.JSON(expenseAllocationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// allocationPeriod parses a YYYY-MM-DD period, writing a 400 response when
+// either bound is invalid.
+func allocationPeriod(c *gin.Context, fromStr, toStr string) (time.Time, time.Time, bool) {
+	from, err := time.Parse("2006-01-02", fromStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from"})
+		return time.Time{}, time.Time{}, false
+	}
+	to, err := time.Parse("2006-01-02", toStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to"})
+		return time.Time{}, time.Time{}, false
+	}
+	return from, to, true
+}
+
+func expenseAllocationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidAllocation):
+		return http.StatusBadRequest
+	case errors.Is(err, sql.ErrNoRows):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrAllocationRuleInUse):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
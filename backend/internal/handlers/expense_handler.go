@@ -2,11 +2,14 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ramadhan22/dropship-erp/backend/internal/middleware"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 	"github.com/ramadhan22/dropship-erp/backend/internal/service"
 )
 
@@ -18,6 +21,7 @@ func (h *ExpenseHandler) RegisterRoutes(r gin.IRouter) {
 	grp := r.Group("/expenses")
 	grp.POST("/", h.create)
 	grp.GET("/", h.list)
+	grp.GET("/filtered", middleware.FilterMiddlewareFor(models.ResourceExpenses), h.listFiltered)
 	grp.GET("/:id", h.get)
 	grp.PUT("/:id", h.update)
 	grp.DELETE("/:id", h.delete)
@@ -30,7 +34,7 @@ func (h *ExpenseHandler) create(c *gin.Context) {
 		return
 	}
 
-	var total float64
+	var total money.Amount
 	for _, l := range e.Lines {
 		total += l.Amount
 	}
@@ -42,7 +46,7 @@ func (h *ExpenseHandler) create(c *gin.Context) {
 		return
 	}
 	if err := h.svc.CreateExpense(context.Background(), &e); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(expenseErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusCreated, e)
@@ -69,6 +73,11 @@ func (h *ExpenseHandler) list(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"data": ex, "total": total})
 }
 
+func (h *ExpenseHandler) listFiltered(c *gin.Context) {
+	result, err := h.svc.ListExpensesFiltered(c.Request.Context(), middleware.GetFilterParams(c))
+	writeQueryResult(c, result, err)
+}
+
 func (h *ExpenseHandler) get(c *gin.Context) {
 	id := c.Param("id")
 	ex, err := h.svc.GetExpense(context.Background(), id)
@@ -92,7 +101,7 @@ func (h *ExpenseHandler) update(c *gin.Context) {
 	}
 	e.ID = id
 	if err := h.svc.UpdateExpense(context.Background(), &e); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(expenseErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 	c.Status(http.StatusOK)
@@ -106,3 +115,10 @@ func (h *ExpenseHandler) delete(c *gin.Context) {
 	}
 	c.Status(http.StatusOK)
 }
+
+func expenseErrorStatus(err error) int {
+	if errors.Is(err, service.ErrInvalidAllocation) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
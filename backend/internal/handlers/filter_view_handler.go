@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ramadhan22/dropship-erp/backend/internal/middleware"
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/service"
+)
+
+// FilterViewService is implemented by service.FilterViewService.
+type FilterViewService interface {
+	Create(ctx context.Context, v *models.SavedFilterView, user string) error
+	Update(ctx context.Context, v *models.SavedFilterView, user string) error
+	Delete(ctx context.Context, id int64, user string) error
+	Get(ctx context.Context, id int64, user string) (*models.SavedFilterView, error)
+	GetByName(ctx context.Context, resource, name, user string) (*models.SavedFilterView, error)
+	List(ctx context.Context, resource, user string) ([]models.SavedFilterView, error)
+}
+
+// FilterViewHandler exposes saved filter views. The user is taken from the
+// X-User-ID header.
+type FilterViewHandler struct {
+	svc FilterViewService
+}
+
+func NewFilterViewHandler(svc FilterViewService) *FilterViewHandler {
+	return &FilterViewHandler{svc: svc}
+}
+
+func (h *FilterViewHandler) RegisterRoutes(r gin.IRouter) {
+	grp := r.Group("/filter-views")
+	grp.GET("/", h.list)
+	grp.POST("/", h.create)
+	grp.GET("/quick", h.quick)
+	grp.GET("/by-name/:resource/:name", h.getByName)
+	grp.GET("/:id", h.get)
+	grp.PUT("/:id", h.update)
+	grp.DELETE("/:id", h.delete)
+}
+
+func (h *FilterViewHandler) list(c *gin.Context) {
+	list, err := h.svc.List(c.Request.Context(), c.Query("resource"), middleware.UserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *FilterViewHandler) quick(c *gin.Context) {
+	c.JSON(http.StatusOK, middleware.GetQuickFilters())
+}
+
+func (h *FilterViewHandler) create(c *gin.Context) {
+	var v models.SavedFilterView
+	if err := c.ShouldBindJSON(&v); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.svc.Create(c.Request.Context(), &v, middleware.UserID(c)); err != nil {
+		c.JSON(filterViewErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, v)
+}
+
+func (h *FilterViewHandler) get(c *gin.Context) {
+	id, err := getIDParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	v, err := h.svc.Get(c.Request.Context(), id, middleware.UserID(c))
+	if err != nil {
+		c.JSON(filterViewErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, v)
+}
+
+func (h *FilterViewHandler) getByName(c *gin.Context) {
+	v, err := h.svc.GetByName(c.Request.Context(), c.Param("resource"), c.Param("name"), middleware.UserID(c))
+	if err != nil {
+		c.JSON(filterViewErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, v)
+}
+
+func (h *FilterViewHandler) update(c *gin.Context) {
+	id, err := getIDParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var v models.SavedFilterView
+	if err := c.ShouldBindJSON(&v); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	v.ID = id
+	if err := h.svc.Update(c.Request.Context(), &v, middleware.UserID(c)); err != nil {
+		c.JSON(filterViewErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, v)
+}
+
+func (h *FilterViewHandler) delete(c *gin.Context) {
+	id, err := getIDParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	if err := h.svc.Delete(c.Request.Context(), id, middleware.UserID(c)); err != nil {
+		c.JSON(filterViewErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func filterViewErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, models.ErrInvalidFilterView):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrFilterViewForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrFilterViewExists):
+		return http.StatusConflict
+	case errors.Is(err, sql.ErrNoRows):
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
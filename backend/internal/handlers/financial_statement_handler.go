@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ramadhan22/dropship-erp/backend/internal/service"
+)
+
+// FinancialStatementSvc defines the statements served by the handler.
+type FinancialStatementSvc interface {
+	GetTrialBalance(ctx context.Context, store string, from, to time.Time, comparison bool) (*service.TrialBalance, error)
+	GetCashFlow(ctx context.Context, store string, from, to time.Time, comparison bool) (*service.CashFlowStatement, error)
+	GetEquityChanges(ctx context.Context, store string, from, to time.Time, comparison bool) (*service.EquityStatement, error)
+}
+
+type FinancialStatementHandler struct{ svc FinancialStatementSvc }
+
+func NewFinancialStatementHandler(s FinancialStatementSvc) *FinancialStatementHandler {
+	return &FinancialStatementHandler{svc: s}
+}
+
+func (h *FinancialStatementHandler) RegisterRoutes(r gin.IRouter) {
+	r.GET("/trialbalance", h.trialBalance)
+	r.GET("/cashflow", h.cashFlow)
+	r.GET("/equitychanges", h.equityChanges)
+}
+
+func (h *FinancialStatementHandler) trialBalance(c *gin.Context) {
+	store, from, to, comparison, err := parseStatementQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	res, err := h.svc.GetTrialBalance(c.Request.Context(), store, from, to, comparison)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func (h *FinancialStatementHandler) cashFlow(c *gin.Context) {
+	store, from, to, comparison, err := parseStatementQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	res, err := h.svc.GetCashFlow(c.Request.Context(), store, from, to, comparison)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func (h *FinancialStatementHandler) equityChanges(c *gin.Context) {
+	store, from, to, comparison, err := parseStatementQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	res, err := h.svc.GetEquityChanges(c.Request.Context(), store, from, to, comparison)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// parseStatementQuery reads store, comparison and the period, given either
+// as period=YYYY-MM or as from/to dates (YYYY-MM-DD). It defaults to the
+// current month; to covers the whole of its last day.
+func parseStatementQuery(c *gin.Context) (store string, from, to time.Time, comparison bool, err error) {
+	store = firstQuery(c, "store", "shop")
+	comparison = c.Query("comparison") == "true"
+	now := time.Now()
+	from = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if p := c.Query("period"); p != "" {
+		if from, err = time.Parse("2006-01", p); err != nil {
+			return store, from, to, comparison, fmt.Errorf("invalid period, use YYYY-MM")
+		}
+	}
+	to = from.AddDate(0, 1, 0).Add(-time.Second)
+	if s := c.Query("from"); s != "" {
+		if from, err = time.Parse("2006-01-02", s); err != nil {
+			return store, from, to, comparison, fmt.Errorf("invalid from date, use YYYY-MM-DD")
+		}
+	}
+	if s := c.Query("to"); s != "" {
+		t, perr := time.Parse("2006-01-02", s)
+		if perr != nil {
+			return store, from, to, comparison, fmt.Errorf("invalid to date, use YYYY-MM-DD")
+		}
+		to = t.Add(24*time.Hour - time.Second)
+	}
+	if to.Before(from) {
+		return store, from, to, comparison, fmt.Errorf("to must not be before from")
+	}
+	return store, from, to, comparison, nil
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ramadhan22/dropship-erp/backend/internal/service"
+)
+
+type fakeStatementSvc struct {
+	store      string
+	from, to   time.Time
+	comparison bool
+}
+
+func (f *fakeStatementSvc) GetTrialBalance(ctx context.Context, store string, from, to time.Time, comparison bool) (*service.TrialBalance, error) {
+	f.store, f.from, f.to, f.comparison = store, from, to, comparison
+	return &service.TrialBalance{Store: store}, nil
+}
+
+func (f *fakeStatementSvc) GetCashFlow(ctx context.Context, store string, from, to time.Time, comparison bool) (*service.CashFlowStatement, error) {
+	f.store, f.from, f.to, f.comparison = store, from, to, comparison
+	return &service.CashFlowStatement{Store: store}, nil
+}
+
+func (f *fakeStatementSvc) GetEquityChanges(ctx context.Context, store string, from, to time.Time, comparison bool) (*service.EquityStatement, error) {
+	f.store, f.from, f.to, f.comparison = store, from, to, comparison
+	return &service.EquityStatement{Store: store}, nil
+}
+
+func TestFinancialStatementHandler_Period(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &fakeStatementSvc{}
+	r := gin.New()
+	NewFinancialStatementHandler(svc).RegisterRoutes(r)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/trialbalance?store=ShopA&period=2025-02&comparison=true", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	wantTo := time.Date(2025, 2, 28, 23, 59, 59, 0, time.UTC)
+	if svc.store != "ShopA" || !svc.comparison || svc.from.Format("2006-01-02") != "2025-02-01" || !svc.to.Equal(wantTo) {
+		t.Errorf("unexpected arguments %+v", svc)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/cashflow?from=2025-03-10&to=2025-03-01", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for reversed range, got %d", rec.Code)
+	}
+}
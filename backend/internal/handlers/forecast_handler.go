@@ -1,12 +1,12 @@
 package handlers
 
 import (
-	"log"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ramadhan22/dropship-erp/backend/internal/logutil"
 	"github.com/ramadhan22/dropship-erp/backend/internal/service"
 )
 
@@ -24,11 +24,13 @@ func NewForecastHandler(fs *service.ForecastService) *ForecastHandler {
 
 // HandleGenerateForecast handles POST /api/forecast/generate
 func (fh *ForecastHandler) HandleGenerateForecast(c *gin.Context) {
-	log.Printf("Processing forecast generation request")
+	ctx := c.Request.Context()
 
 	var req service.ForecastRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Printf("Invalid forecast request: %v", err)
+		logutil.Warn(ctx, "HandleGenerateForecast", "Invalid forecast request", map[string]interface{}{
+			"error": err,
+		})
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Invalid request format",
 			"details": err.Error(),
@@ -81,14 +83,20 @@ func (fh *ForecastHandler) HandleGenerateForecast(c *gin.Context) {
 		}
 	}
 
-	log.Printf("Generating forecast: shop=%s, period=%s, start=%s, end=%s, forecastTo=%s",
-		req.Shop, req.Period, req.StartDate.Format("2006-01-02"), 
-		req.EndDate.Format("2006-01-02"), req.ForecastTo.Format("2006-01-02"))
+	logutil.Debug(ctx, "HandleGenerateForecast", "Generating forecast", map[string]interface{}{
+		"shop":        req.Shop,
+		"period":      req.Period,
+		"start":       req.StartDate.Format("2006-01-02"),
+		"end":         req.EndDate.Format("2006-01-02"),
+		"forecast_to": req.ForecastTo.Format("2006-01-02"),
+	})
 
 	// Generate forecast
-	forecast, err := fh.forecastService.GenerateForecast(c.Request.Context(), req)
+	forecast, err := fh.forecastService.GenerateForecast(ctx, req)
 	if err != nil {
-		log.Printf("Failed to generate forecast: %v", err)
+		logutil.Error(ctx, "HandleGenerateForecast", "Failed to generate forecast", err, map[string]interface{}{
+			"shop": req.Shop,
+		})
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to generate forecast",
 			"details": err.Error(),
@@ -96,7 +104,6 @@ func (fh *ForecastHandler) HandleGenerateForecast(c *gin.Context) {
 		return
 	}
 
-	log.Printf("Forecast generated successfully for shop %s", req.Shop)
 	c.JSON(http.StatusOK, forecast)
 }
 
@@ -173,7 +180,7 @@ func (fh *ForecastHandler) HandleGetForecastSummary(c *gin.Context) {
 
 	forecast, err := fh.forecastService.GenerateForecast(c.Request.Context(), req)
 	if err != nil {
-		log.Printf("Failed to generate forecast summary: %v", err)
+		logutil.Error(c.Request.Context(), "HandleGetForecastSummary", "Failed to generate forecast summary", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to generate forecast summary",
 			"details": err.Error(),
@@ -11,6 +11,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
 	"github.com/ramadhan22/dropship-erp/backend/internal/service"
 )
@@ -22,7 +23,7 @@ func (m *mockHandlerDropshipRepo) ListDropshipPurchasesByShopAndDate(ctx context
 	return []models.DropshipPurchase{
 		{
 			KodePesanan:    "ORDER1",
-			TotalTransaksi: 1000000,
+			TotalTransaksi: money.New(1000000),
 		},
 	}, nil
 }
@@ -33,7 +34,7 @@ func (m *mockHandlerJournalRepo) GetAccountBalancesAsOf(ctx context.Context, sho
 	return []repository.AccountBalance{
 		{
 			AccountCode: "5000",
-			Balance:     500000,
+			Balance:     money.New(500000),
 		},
 	}, nil
 }
@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ramadhan22/dropship-erp/backend/internal/middleware"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
 	"github.com/ramadhan22/dropship-erp/backend/internal/service"
 )
@@ -17,6 +18,7 @@ func (h *JournalHandler) RegisterRoutes(r gin.IRouter) {
 	grp := r.Group("/journal")
 	grp.POST("/", h.create)
 	grp.GET("/", h.list)
+	grp.GET("/filtered", middleware.FilterMiddlewareFor(models.ResourceJournalEntries), h.listFiltered)
 	grp.GET("/:id", h.get)
 	grp.GET("/:id/lines", h.getLines)
 	grp.GET("/source/:id/lines", h.getLinesBySource)
@@ -35,6 +37,11 @@ func (h *JournalHandler) list(c *gin.Context) {
 	c.JSON(http.StatusOK, list)
 }
 
+func (h *JournalHandler) listFiltered(c *gin.Context) {
+	result, err := h.svc.ListFiltered(c.Request.Context(), middleware.GetFilterParams(c))
+	writeQueryResult(c, result, err)
+}
+
 func (h *JournalHandler) get(c *gin.Context) {
 	id, _ := getIDParam(c)
 	je, err := h.svc.Get(context.Background(), id)
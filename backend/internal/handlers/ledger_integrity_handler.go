@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/service"
+)
+
+// LedgerIntegrityServiceInterface is implemented by service.LedgerIntegrityService.
+type LedgerIntegrityServiceInterface interface {
+	Check(ctx context.Context, checks ...string) (*models.IntegrityReport, error)
+	RepairIssue(ctx context.Context, check, target, performedBy string) (*models.LedgerRepair, error)
+	ListRepairs(ctx context.Context, limit, offset int) ([]models.LedgerRepair, int, error)
+}
+
+// LedgerIntegrityHandler reports ledger inconsistencies and applies repairs.
+type LedgerIntegrityHandler struct {
+	svc LedgerIntegrityServiceInterface
+}
+
+func NewLedgerIntegrityHandler(svc LedgerIntegrityServiceInterface) *LedgerIntegrityHandler {
+	return &LedgerIntegrityHandler{svc: svc}
+}
+
+func (h *LedgerIntegrityHandler) RegisterRoutes(r gin.IRouter) {
+	grp := r.Group("/ledger/integrity")
+	grp.GET("/", h.check)
+	grp.POST("/repair", h.repair)
+	grp.GET("/repairs", h.listRepairs)
+}
+
+func (h *LedgerIntegrityHandler) check(c *gin.Context) {
+	var checks []string
+	if q := c.Query("check"); q != "" {
+		checks = strings.Split(q, ",")
+	}
+	report, err := h.svc.Check(c.Request.Context(), checks...)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrUnknownCheck) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+func (h *LedgerIntegrityHandler) repair(c *gin.Context) {
+	var req struct {
+		Check       string `json:"check" binding:"required"`
+		Target      string `json:"target" binding:"required"`
+		PerformedBy string `json:"performed_by"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	rep, err := h.svc.RepairIssue(c.Request.Context(), req.Check, req.Target, req.PerformedBy)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrUnknownCheck), errors.Is(err, service.ErrNotRepairable):
+			status = http.StatusBadRequest
+		case errors.Is(err, service.ErrIssueResolved):
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, rep)
+}
+
+func (h *LedgerIntegrityHandler) listRepairs(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	size, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if size <= 0 {
+		size = 20
+	}
+	list, total, err := h.svc.ListRepairs(c.Request.Context(), size, (page-1)*size)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list, "total": total})
+}
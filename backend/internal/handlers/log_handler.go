@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ramadhan22/dropship-erp/backend/internal/logutil"
+)
+
+// LogHandler searches the JSON day files and changes log levels at
+// runtime.
+type LogHandler struct {
+	dir string
+}
+
+// NewLogHandler serves the day files kept in dir.
+func NewLogHandler(dir string) *LogHandler {
+	return &LogHandler{dir: dir}
+}
+
+func (h *LogHandler) RegisterRoutes(r gin.IRouter) {
+	grp := r.Group("/admin")
+	grp.GET("/logs", h.search)
+	grp.GET("/log-levels", h.levels)
+	grp.PUT("/log-levels/:component", h.setLevel)
+	grp.DELETE("/log-levels/:component", h.clearLevel)
+}
+
+// search handles GET /api/admin/logs. Without since, the last 24 hours are
+// searched.
+func (h *LogHandler) search(c *gin.Context) {
+	q := logutil.SearchQuery{
+		CorrelationID: c.Query("correlation_id"),
+		TraceID:       c.Query("trace_id"),
+		Shop:          c.Query("shop"),
+		Operation:     c.Query("operation"),
+		Component:     c.Query("component"),
+		Text:          c.Query("q"),
+		Since:         time.Now().Add(-24 * time.Hour),
+		Limit:         100,
+	}
+	if v := c.Query("level"); v != "" {
+		level, err := logutil.ParseLevel(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		q.MinLevel = level
+	}
+	var err error
+	if q.Since, err = parseLogTime(c.Query("since"), q.Since); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid since: " + err.Error()})
+		return
+	}
+	if q.Until, err = parseLogTime(c.Query("until"), time.Time{}); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid until: " + err.Error()})
+		return
+	}
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > 1000 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 1000"})
+			return
+		}
+		q.Limit = n
+	}
+	records, err := logutil.Search(h.dir, q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": records, "count": len(records)})
+}
+
+// parseLogTime accepts RFC 3339 timestamps or YYYY-MM-DD dates.
+func parseLogTime(v string, def time.Time) (time.Time, error) {
+	if v == "" {
+		return def, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", v, time.Local)
+}
+
+func (h *LogHandler) levels(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"overrides":  logutil.ComponentLevels(),
+		"components": logutil.Components(),
+	})
+}
+
+// setLevel handles PUT /api/admin/log-levels/:component with
+// {"level": "debug"}. Use "*" as the component for every component.
+func (h *LogHandler) setLevel(c *gin.Context) {
+	var req struct {
+		Level string `json:"level" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	level, err := logutil.ParseLevel(req.Level)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	logutil.SetComponentLevel(c.Param("component"), level)
+	c.JSON(http.StatusOK, gin.H{"overrides": logutil.ComponentLevels()})
+}
+
+func (h *LogHandler) clearLevel(c *gin.Context) {
+	logutil.ClearComponentLevel(c.Param("component"))
+	c.JSON(http.StatusOK, gin.H{"overrides": logutil.ComponentLevels()})
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/service"
+)
+
+// MatchSuggestionServiceInterface is implemented by service.MatchSuggestionService.
+type MatchSuggestionServiceInterface interface {
+	GenerateSuggestions(ctx context.Context, req service.SuggestionRequest) (*service.SuggestionResult, error)
+	ListSuggestions(ctx context.Context, store, status string, limit, offset int) ([]models.MatchSuggestion, int, error)
+	AcceptSuggestion(ctx context.Context, id int64) (*models.MatchSuggestion, error)
+	RejectSuggestion(ctx context.Context, id int64) (*models.MatchSuggestion, error)
+}
+
+type MatchSuggestionHandler struct {
+	svc MatchSuggestionServiceInterface
+}
+
+func NewMatchSuggestionHandler(svc MatchSuggestionServiceInterface) *MatchSuggestionHandler {
+	return &MatchSuggestionHandler{svc: svc}
+}
+
+func (h *MatchSuggestionHandler) RegisterRoutes(r gin.IRouter) {
+	grp := r.Group("/reconcile/suggestions")
+	grp.GET("/", h.list)
+	grp.POST("/generate", h.generate)
+	grp.POST("/:id/accept", h.accept)
+	grp.POST("/:id/reject", h.reject)
+}
+
+func (h *MatchSuggestionHandler) list(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	size, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if size <= 0 {
+		size = 20
+	}
+	status := c.DefaultQuery("status", models.SuggestionPending)
+	list, total, err := h.svc.ListSuggestions(c.Request.Context(), c.Query("store"), status, size, (page-1)*size)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list, "total": total})
+}
+
+func (h *MatchSuggestionHandler) generate(c *gin.Context) {
+	var req struct {
+		Store         string  `json:"store"`
+		From          string  `json:"from"`
+		To            string  `json:"to"`
+		MinConfidence float64 `json:"min_confidence"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.MinConfidence < 0 || req.MinConfidence > 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "min_confidence must be between 0 and 1"})
+		return
+	}
+	sr := service.SuggestionRequest{Store: req.Store, MinConfidence: req.MinConfidence}
+	if req.From != "" {
+		t, err := time.Parse("2006-01-02", req.From)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date"})
+			return
+		}
+		sr.From = t
+	}
+	if req.To != "" {
+		t, err := time.Parse("2006-01-02", req.To)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date"})
+			return
+		}
+		sr.To = t.Add(24 * time.Hour)
+	}
+	res, err := h.svc.GenerateSuggestions(c.Request.Context(), sr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func (h *MatchSuggestionHandler) accept(c *gin.Context) {
+	h.decide(c, h.svc.AcceptSuggestion)
+}
+
+func (h *MatchSuggestionHandler) reject(c *gin.Context) {
+	h.decide(c, h.svc.RejectSuggestion)
+}
+
+func (h *MatchSuggestionHandler) decide(c *gin.Context, fn func(context.Context, int64) (*models.MatchSuggestion, error)) {
+	id, err := getIDParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	sg, err := fn(c.Request.Context(), id)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrSuggestionDecided):
+			status = http.StatusBadRequest
+		case errors.Is(err, sql.ErrNoRows):
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, sg)
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 	"github.com/ramadhan22/dropship-erp/backend/internal/service"
 )
 
@@ -95,11 +96,11 @@ func TestHandleGetMetrics_Success(t *testing.T) {
 	expected := &models.CachedMetric{
 		ShopUsername:      "ShopX",
 		Period:            "2025-05",
-		SumRevenue:        100,
-		SumCOGS:           50,
-		SumFees:           5,
-		NetProfit:         45,
-		EndingCashBalance: 200,
+		SumRevenue:        money.New(100),
+		SumCOGS:           money.New(50),
+		SumFees:           money.New(5),
+		NetProfit:         money.New(45),
+		EndingCashBalance: money.New(200),
 	}
 	svc := &fakeMetricService{returnCM: expected}
 	h := NewMetricHandler(svc)
@@ -119,7 +120,7 @@ func TestHandleGetMetrics_Success(t *testing.T) {
 		t.Fatalf("json unmarshal: %v", err)
 	}
 	if got.NetProfit != expected.NetProfit {
-		t.Errorf("expected NetProfit %f, got %f", expected.NetProfit, got.NetProfit)
+		t.Errorf("expected NetProfit %s, got %s", expected.NetProfit, got.NetProfit)
 	}
 }
 
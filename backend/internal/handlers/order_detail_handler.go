@@ -6,12 +6,14 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ramadhan22/dropship-erp/backend/internal/middleware"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
 )
 
 // OrderDetailService defines methods used by the handler.
 type OrderDetailService interface {
 	ListOrderDetails(ctx context.Context, store, order string, limit, offset int) ([]models.ShopeeOrderDetailRow, int, error)
+	ListOrderDetailsFiltered(ctx context.Context, params *models.FilterParams) (*models.QueryResult, error)
 	GetOrderDetail(ctx context.Context, sn string) (*models.ShopeeOrderDetailRow, []models.ShopeeOrderItemRow, []models.ShopeeOrderPackageRow, error)
 }
 
@@ -25,6 +27,7 @@ func NewOrderDetailHandler(s OrderDetailService) *OrderDetailHandler {
 func (h *OrderDetailHandler) RegisterRoutes(r gin.IRouter) {
 	grp := r.Group("/order-details")
 	grp.GET("", h.list)
+	grp.GET("/filtered", middleware.FilterMiddlewareFor(models.ResourceOrderDetails), h.listFiltered)
 	grp.GET(":sn", h.get)
 }
 
@@ -49,6 +52,11 @@ func (h *OrderDetailHandler) list(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"data": list, "total": total})
 }
 
+func (h *OrderDetailHandler) listFiltered(c *gin.Context) {
+	result, err := h.svc.ListOrderDetailsFiltered(c.Request.Context(), middleware.GetFilterParams(c))
+	writeQueryResult(c, result, err)
+}
+
 func (h *OrderDetailHandler) get(c *gin.Context) {
 	sn := c.Param("sn")
 	det, items, packs, err := h.svc.GetOrderDetail(context.Background(), sn)
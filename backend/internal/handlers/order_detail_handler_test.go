@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
+)
+
+type fakeOrderDetailService struct {
+	params *models.FilterParams
+}
+
+func (f *fakeOrderDetailService) ListOrderDetails(ctx context.Context, store, order string, limit, offset int) ([]models.ShopeeOrderDetailRow, int, error) {
+	return nil, 0, nil
+}
+
+func (f *fakeOrderDetailService) ListOrderDetailsFiltered(ctx context.Context, params *models.FilterParams) (*models.QueryResult, error) {
+	f.params = params
+	if params.Filters != nil && params.Filters.Conditions[0].Field == "secret" {
+		return nil, fmt.Errorf("failed to build query: %w", repository.ErrInvalidFilter)
+	}
+	res := models.NewQueryResult([]models.ShopeeOrderDetailRow{{OrderSN: "SN1"}}, 1, 1, 20)
+	res.NextCursor = "next"
+	return res, nil
+}
+
+func (f *fakeOrderDetailService) GetOrderDetail(ctx context.Context, sn string) (*models.ShopeeOrderDetailRow, []models.ShopeeOrderItemRow, []models.ShopeeOrderPackageRow, error) {
+	return &models.ShopeeOrderDetailRow{OrderSN: sn}, nil, nil, nil
+}
+
+func TestOrderDetailListFiltered(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &fakeOrderDetailService{}
+	r := gin.New()
+	NewOrderDetailHandler(svc).RegisterRoutes(r)
+
+	q := url.Values{}
+	q.Set("filters", `{"logic":"AND","conditions":[{"field":"store","operator":"eq","value":"ShopA"}]}`)
+	q.Set("cursor", "abc")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/order-details/filtered?"+q.Encode(), nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var body map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if body["next_cursor"] != "next" || body["total"] != float64(1) {
+		t.Errorf("unexpected body %v", body)
+	}
+	if svc.params.Pagination.Cursor != "abc" {
+		t.Errorf("cursor not passed through: %+v", svc.params.Pagination)
+	}
+
+	q.Set("filters", `{"logic":"AND","conditions":[{"field":"secret","operator":"eq","value":"x"}]}`)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/order-details/filtered?"+q.Encode(), nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for disallowed field, got %d", w.Code)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 	"github.com/ramadhan22/dropship-erp/backend/internal/service"
 )
 
@@ -22,7 +23,7 @@ func (f *fakePLReportSvc) GetProfitLoss(ctx context.Context, typ string, month,
 
 func TestProfitLossReportHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
-	svc := &fakePLReportSvc{data: &service.ProfitLoss{TotalPendapatanUsaha: 100}}
+	svc := &fakePLReportSvc{data: &service.ProfitLoss{TotalPendapatanUsaha: money.New(100)}}
 	h := NewProfitLossReportHandler(svc)
 
 	rec := httptest.NewRecorder()
@@ -39,7 +40,7 @@ func TestProfitLossReportHandler(t *testing.T) {
 	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
 		t.Fatalf("unmarshal: %v", err)
 	}
-	if got.TotalPendapatanUsaha != 100 {
+	if got.TotalPendapatanUsaha != money.New(100) {
 		t.Errorf("unexpected body: %+v", got)
 	}
 }
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ramadhan22/dropship-erp/backend/internal/middleware"
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/service"
+)
+
+// ReconcileFailureServiceInterface is implemented by service.ReconcileService.
+type ReconcileFailureServiceInterface interface {
+	ListFailedReconciliations(ctx context.Context, status, shop string, limit, offset int) ([]models.FailedReconciliation, int, error)
+	ListFailedReconciliationsFiltered(ctx context.Context, params *models.FilterParams) (*models.QueryResult, error)
+	GetFailedReconciliation(ctx context.Context, id int64) (*models.FailedReconciliation, []models.FailedReconciliationAttempt, error)
+	RetryFailure(ctx context.Context, id int64) (*models.FailedReconciliation, error)
+}
+
+// ReconcileFailureHandler exposes failed reconciliations, their retry
+// history and the needs-attention queue.
+type ReconcileFailureHandler struct {
+	svc ReconcileFailureServiceInterface
+}
+
+func NewReconcileFailureHandler(svc ReconcileFailureServiceInterface) *ReconcileFailureHandler {
+	return &ReconcileFailureHandler{svc: svc}
+}
+
+func (h *ReconcileFailureHandler) RegisterRoutes(r gin.IRouter) {
+	grp := r.Group("/reconcile/failures")
+	grp.GET("/", h.list)
+	grp.GET("/filtered", middleware.FilterMiddlewareFor(models.ResourceFailedReconciliations), h.listFiltered)
+	grp.GET("/:id", h.get)
+	grp.POST("/:id/retry", h.retry)
+}
+
+func (h *ReconcileFailureHandler) list(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	size, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 || size > 1000 {
+		size = 20
+	}
+	list, total, err := h.svc.ListFailedReconciliations(c.Request.Context(),
+		c.Query("status"), c.Query("shop"), size, (page-1)*size)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list, "total": total})
+}
+
+func (h *ReconcileFailureHandler) listFiltered(c *gin.Context) {
+	result, err := h.svc.ListFailedReconciliationsFiltered(c.Request.Context(), middleware.GetFilterParams(c))
+	writeQueryResult(c, result, err)
+}
+
+func (h *ReconcileFailureHandler) get(c *gin.Context) {
+	id, err := getIDParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	f, attempts, err := h.svc.GetFailedReconciliation(c.Request.Context(), id)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, sql.ErrNoRows) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"failure": f, "attempts": attempts})
+}
+
+func (h *ReconcileFailureHandler) retry(c *gin.Context) {
+	id, err := getIDParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	f, err := h.svc.RetryFailure(c.Request.Context(), id)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			status = http.StatusNotFound
+		case errors.Is(err, service.ErrFailureResolved):
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, f)
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/service"
+)
+
+// ReconcilePolicyServiceInterface is implemented by service.ReconcilePolicyService.
+type ReconcilePolicyServiceInterface interface {
+	Defaults() models.ReconciliationConfig
+	ListPolicies(ctx context.Context) ([]models.ReconciliationPolicy, error)
+	GetPolicy(ctx context.Context, store string) (*models.ReconciliationPolicy, error)
+	SavePolicy(ctx context.Context, p *models.ReconciliationPolicy) error
+	DeletePolicy(ctx context.Context, store string) error
+}
+
+type ReconcilePolicyHandler struct {
+	svc ReconcilePolicyServiceInterface
+}
+
+func NewReconcilePolicyHandler(svc ReconcilePolicyServiceInterface) *ReconcilePolicyHandler {
+	return &ReconcilePolicyHandler{svc: svc}
+}
+
+func (h *ReconcilePolicyHandler) RegisterRoutes(r gin.IRouter) {
+	grp := r.Group("/reconcile/policies")
+	grp.GET("/", h.list)
+	grp.GET("/:store", h.get)
+	grp.PUT("/:store", h.save)
+	grp.DELETE("/:store", h.delete)
+}
+
+func (h *ReconcilePolicyHandler) list(c *gin.Context) {
+	list, err := h.svc.ListPolicies(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"defaults": h.svc.Defaults(), "policies": list})
+}
+
+func (h *ReconcilePolicyHandler) get(c *gin.Context) {
+	p, err := h.svc.GetPolicy(c.Request.Context(), c.Param("store"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
+
+func (h *ReconcilePolicyHandler) save(c *gin.Context) {
+	var p models.ReconciliationPolicy
+	if err := c.ShouldBindJSON(&p); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	p.Store = c.Param("store")
+	if err := h.svc.SavePolicy(c.Request.Context(), &p); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrInvalidPolicy) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
+
+func (h *ReconcilePolicyHandler) delete(c *gin.Context) {
+	if err := h.svc.DeletePolicy(c.Request.Context(), c.Param("store")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusOK)
+}
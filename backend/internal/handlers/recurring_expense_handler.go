@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/service"
+)
+
+// RecurringExpenseService is implemented by service.RecurringExpenseService.
+type RecurringExpenseService interface {
+	CreateTemplate(ctx context.Context, t *models.RecurringExpense) error
+	UpdateTemplate(ctx context.Context, t *models.RecurringExpense) error
+	DeleteTemplate(ctx context.Context, id int64) error
+	GetTemplate(ctx context.Context, id int64) (*models.RecurringExpense, error)
+	ListTemplates(ctx context.Context) ([]models.RecurringExpense, error)
+	ListRuns(ctx context.Context, id int64, limit, offset int) ([]models.RecurringExpenseRun, error)
+	SettleAccruals(ctx context.Context, id int64, date time.Time) (*models.Expense, error)
+}
+
+type RecurringExpenseHandler struct {
+	svc RecurringExpenseService
+}
+
+func NewRecurringExpenseHandler(svc RecurringExpenseService) *RecurringExpenseHandler {
+	return &RecurringExpenseHandler{svc: svc}
+}
+
+func (h *RecurringExpenseHandler) RegisterRoutes(r gin.IRouter) {
+	grp := r.Group("/recurring-expenses")
+	grp.GET("/", h.list)
+	grp.POST("/", h.create)
+	grp.GET("/:id", h.get)
+	grp.PUT("/:id", h.update)
+	grp.DELETE("/:id", h.delete)
+	grp.GET("/:id/runs", h.runs)
+	grp.POST("/:id/settle", h.settle)
+}
+
+func (h *RecurringExpenseHandler) list(c *gin.Context) {
+	list, err := h.svc.ListTemplates(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *RecurringExpenseHandler) create(c *gin.Context) {
+	var t models.RecurringExpense
+	if err := c.ShouldBindJSON(&t); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.svc.CreateTemplate(c.Request.Context(), &t); err != nil {
+		c.JSON(recurringExpenseErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, t)
+}
+
+func (h *RecurringExpenseHandler) get(c *gin.Context) {
+	id, err := getIDParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	t, err := h.svc.GetTemplate(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if t == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return
+	}
+	c.JSON(http.StatusOK, t)
+}
+
+func (h *RecurringExpenseHandler) update(c *gin.Context) {
+	id, err := getIDParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var t models.RecurringExpense
+	if err := c.ShouldBindJSON(&t); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	t.ID = id
+	if err := h.svc.UpdateTemplate(c.Request.Context(), &t); err != nil {
+		c.JSON(recurringExpenseErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, t)
+}
+
+func (h *RecurringExpenseHandler) delete(c *gin.Context) {
+	id, err := getIDParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	if err := h.svc.DeleteTemplate(c.Request.Context(), id); err != nil {
+		c.JSON(recurringExpenseErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func (h *RecurringExpenseHandler) runs(c *gin.Context) {
+	id, err := getIDParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	size, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if size <= 0 {
+		size = 20
+	}
+	list, err := h.svc.ListRuns(c.Request.Context(), id, size, (page-1)*size)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// settle handles POST /api/recurring-expenses/:id/settle. The optional date
+// query parameter (YYYY-MM-DD) dates the payment; it defaults to today.
+func (h *RecurringExpenseHandler) settle(c *gin.Context) {
+	id, err := getIDParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var date time.Time
+	if v := c.Query("date"); v != "" {
+		if date, err = time.Parse("2006-01-02", v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date"})
+			return
+		}
+	}
+	e, err := h.svc.SettleAccruals(c.Request.Context(), id, date)
+	if err != nil {
+		c.JSON(recurringExpenseErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, e)
+}
+
+func recurringExpenseErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidRecurringExpense), errors.Is(err, service.ErrNothingToSettle):
+		return http.StatusBadRequest
+	case errors.Is(err, sql.ErrNoRows):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrRecurringExpenseOpen):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
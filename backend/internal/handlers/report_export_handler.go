@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ramadhan22/dropship-erp/backend/internal/export"
+	"github.com/ramadhan22/dropship-erp/backend/internal/service"
+)
+
+// ReportExporter renders reports to downloadable files.
+type ReportExporter interface {
+	Export(ctx context.Context, req service.ReportRequest, format export.Format) ([]byte, string, error)
+}
+
+type ReportExportHandler struct{ svc ReportExporter }
+
+func NewReportExportHandler(s ReportExporter) *ReportExportHandler {
+	return &ReportExportHandler{svc: s}
+}
+
+func (h *ReportExportHandler) RegisterRoutes(r gin.IRouter) {
+	r.GET("/reports/:report/export", h.handleExport)
+}
+
+// handleExport accepts the same filters as the JSON report endpoints plus
+// format=xlsx|csv|pdf and returns the rendered file as an attachment.
+func (h *ReportExportHandler) handleExport(c *gin.Context) {
+	format, err := export.ParseFormat(c.DefaultQuery("format", "xlsx"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req, err := parseReportRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	data, filename, err := h.svc.Export(c.Request.Context(), req, format)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrInvalidReportRequest) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, format.ContentType(), data)
+}
+
+// parseReportRequest maps query parameters onto a ReportRequest. It accepts
+// period=YYYY-MM (balance sheet), type/month/year (P&L), from/to as
+// YYYY-MM-DD and days (reconciliation).
+func parseReportRequest(c *gin.Context) (service.ReportRequest, error) {
+	req := service.ReportRequest{
+		Report:          c.Param("report"),
+		Store:           firstQuery(c, "store", "shop", "store_name"),
+		Channel:         c.Query("channel"),
+		PeriodType:      c.DefaultQuery("type", "Monthly"),
+		Comparison:      c.Query("comparison") == "true",
+		DiscrepancyType: c.Query("discrepancy_type"),
+	}
+	if p := c.Query("period"); p != "" {
+		start, err := time.Parse("2006-01", p)
+		if err != nil {
+			return req, fmt.Errorf("invalid period")
+		}
+		req.From = start
+		req.To = start.AddDate(0, 1, 0).Add(-time.Second)
+	}
+	if y, _ := strconv.Atoi(c.Query("year")); y > 0 {
+		m, _ := strconv.Atoi(c.DefaultQuery("month", "1"))
+		if m < 1 || m > 12 {
+			m = 1
+		}
+		req.From = time.Date(y, time.Month(m), 1, 0, 0, 0, 0, time.UTC)
+	}
+	if s := c.Query("from"); s != "" {
+		t, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return req, fmt.Errorf("invalid from date, use YYYY-MM-DD")
+		}
+		req.From = t
+	}
+	if s := c.Query("to"); s != "" {
+		t, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return req, fmt.Errorf("invalid to date, use YYYY-MM-DD")
+		}
+		req.To = t.Add(24*time.Hour - time.Second)
+	}
+	if d, _ := strconv.Atoi(c.Query("days")); d > 0 && req.From.IsZero() {
+		req.From = time.Now().AddDate(0, 0, -d)
+	}
+	return req, nil
+}
+
+func firstQuery(c *gin.Context, keys ...string) string {
+	for _, k := range keys {
+		if v := c.Query(k); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ramadhan22/dropship-erp/backend/internal/export"
+	"github.com/ramadhan22/dropship-erp/backend/internal/service"
+)
+
+type fakeReportExporter struct {
+	req    service.ReportRequest
+	format export.Format
+	err    error
+}
+
+func (f *fakeReportExporter) Export(ctx context.Context, req service.ReportRequest, format export.Format) ([]byte, string, error) {
+	f.req, f.format = req, format
+	if f.err != nil {
+		return nil, "", f.err
+	}
+	return []byte("a,b\n"), "balance-sheet.csv", nil
+}
+
+func TestReportExportHandler_CSV(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &fakeReportExporter{}
+	r := gin.New()
+	NewReportExportHandler(svc).RegisterRoutes(r)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/reports/balance-sheet/export?format=csv&shop=ShopA&period=2025-05", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != export.FormatCSV.ContentType() {
+		t.Errorf("unexpected content type %q", ct)
+	}
+	if cd := rec.Header().Get("Content-Disposition"); cd != `attachment; filename="balance-sheet.csv"` {
+		t.Errorf("unexpected content disposition %q", cd)
+	}
+	want := time.Date(2025, 5, 31, 23, 59, 59, 0, time.UTC)
+	if svc.req.Report != service.ReportBalanceSheet || svc.req.Store != "ShopA" || !svc.req.To.Equal(want) {
+		t.Errorf("unexpected request %+v", svc.req)
+	}
+}
+
+func TestReportExportHandler_BadFormat(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	NewReportExportHandler(&fakeReportExporter{}).RegisterRoutes(r)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/reports/balance-sheet/export?format=doc", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+}
+
+func TestReportExportHandler_ErrorStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{fmt.Errorf("%w: unknown report \"nope\"", service.ErrInvalidReportRequest), http.StatusBadRequest},
+		{fmt.Errorf("connection refused"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		r := gin.New()
+		NewReportExportHandler(&fakeReportExporter{err: tt.err}).RegisterRoutes(r)
+
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest("GET", "/reports/nope/export?format=csv", nil))
+		if rec.Code != tt.want {
+			t.Errorf("%v: expected %d, got %d", tt.err, tt.want, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/service"
+)
+
+// ReportSubscriptionService is implemented by service.ReportDeliveryService.
+type ReportSubscriptionService interface {
+	CreateSubscription(ctx context.Context, s *models.ReportSubscription) error
+	UpdateSubscription(ctx context.Context, s *models.ReportSubscription) error
+	DeleteSubscription(ctx context.Context, id int64) error
+	GetSubscription(ctx context.Context, id int64) (*models.ReportSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]models.ReportSubscription, error)
+	ListDeliveries(ctx context.Context, subscriptionID int64, limit, offset int) ([]models.ReportDelivery, error)
+	RunNow(ctx context.Context, id int64) (*models.ReportDelivery, error)
+	RetryDelivery(ctx context.Context, id int64) (*models.ReportDelivery, error)
+}
+
+type ReportSubscriptionHandler struct{ svc ReportSubscriptionService }
+
+func NewReportSubscriptionHandler(svc ReportSubscriptionService) *ReportSubscriptionHandler {
+	return &ReportSubscriptionHandler{svc: svc}
+}
+
+func (h *ReportSubscriptionHandler) RegisterRoutes(r gin.IRouter) {
+	grp := r.Group("/report-subscriptions")
+	grp.GET("/", h.list)
+	grp.POST("/", h.create)
+	grp.GET("/:id", h.get)
+	grp.PUT("/:id", h.update)
+	grp.DELETE("/:id", h.delete)
+	grp.POST("/:id/run", h.run)
+	grp.GET("/:id/deliveries", h.deliveries)
+	r.POST("/report-deliveries/:id/retry", h.retry)
+}
+
+func (h *ReportSubscriptionHandler) list(c *gin.Context) {
+	list, err := h.svc.ListSubscriptions(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *ReportSubscriptionHandler) create(c *gin.Context) {
+	var s models.ReportSubscription
+	if err := c.ShouldBindJSON(&s); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.svc.CreateSubscription(c.Request.Context(), &s); err != nil {
+		c.JSON(subscriptionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, s)
+}
+
+func (h *ReportSubscriptionHandler) get(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	s, err := h.svc.GetSubscription(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if s == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return
+	}
+	c.JSON(http.StatusOK, s)
+}
+
+func (h *ReportSubscriptionHandler) update(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var s models.ReportSubscription
+	if err := c.ShouldBindJSON(&s); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	s.ID = id
+	if err := h.svc.UpdateSubscription(c.Request.Context(), &s); err != nil {
+		c.JSON(subscriptionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, s)
+}
+
+func (h *ReportSubscriptionHandler) delete(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	if err := h.svc.DeleteSubscription(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func (h *ReportSubscriptionHandler) run(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	d, err := h.svc.RunNow(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(subscriptionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, d)
+}
+
+func (h *ReportSubscriptionHandler) deliveries(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	size, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if size <= 0 {
+		size = 20
+	}
+	list, err := h.svc.ListDeliveries(c.Request.Context(), id, size, (page-1)*size)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *ReportSubscriptionHandler) retry(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	d, err := h.svc.RetryDelivery(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(subscriptionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, d)
+}
+
+func subscriptionErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidSubscription):
+		return http.StatusBadRequest
+	case errors.Is(err, sql.ErrNoRows):
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/service"
+)
+
+// ReturnServiceInterface is implemented by service.ReturnService.
+type ReturnServiceInterface interface {
+	SyncReturns(ctx context.Context, store string, from, to time.Time) (*service.ReturnSyncResult, error)
+	ListReturns(ctx context.Context, f models.ReturnFilter, limit, offset int) ([]models.OrderReturn, int, error)
+	GetReturn(ctx context.Context, id int64) (*models.OrderReturn, error)
+	RecordSupplierRefund(ctx context.Context, id int64, in models.SupplierRefundInput) (*models.OrderReturn, error)
+	ReturnLosses(ctx context.Context, store string, from, to *time.Time) ([]models.ReturnLoss, error)
+}
+
+type ReturnHandler struct{ svc ReturnServiceInterface }
+
+func NewReturnHandler(svc ReturnServiceInterface) *ReturnHandler {
+	return &ReturnHandler{svc: svc}
+}
+
+func (h *ReturnHandler) RegisterRoutes(r gin.IRouter) {
+	grp := r.Group("/returns")
+	grp.GET("/", h.list)
+	grp.POST("/sync", h.sync)
+	grp.GET("/losses", h.losses)
+	grp.GET("/:id", h.get)
+	grp.POST("/:id/supplier-refund", h.supplierRefund)
+}
+
+// parseDateRange reads optional from/to query dates (YYYY-MM-DD). To is
+// inclusive of the whole day.
+func parseDateRange(c *gin.Context) (*time.Time, *time.Time, error) {
+	var from, to *time.Time
+	if v := c.Query("from"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return nil, nil, err
+		}
+		from = &t
+	}
+	if v := c.Query("to"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return nil, nil, err
+		}
+		t = t.Add(24*time.Hour - time.Nanosecond)
+		to = &t
+	}
+	return from, to, nil
+}
+
+func (h *ReturnHandler) list(c *gin.Context) {
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date"})
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	size, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if size <= 0 {
+		size = 20
+	}
+	f := models.ReturnFilter{
+		Store:                c.Query("store"),
+		Status:               c.Query("status"),
+		SupplierRefundStatus: c.Query("supplier_refund_status"),
+		OrderSN:              c.Query("order_sn"),
+		From:                 from,
+		To:                   to,
+	}
+	list, total, err := h.svc.ListReturns(c.Request.Context(), f, size, (page-1)*size)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list, "total": total})
+}
+
+func (h *ReturnHandler) sync(c *gin.Context) {
+	var req struct {
+		Store string `json:"store"`
+		From  string `json:"from" binding:"required"`
+		To    string `json:"to" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	from, err1 := time.Parse("2006-01-02", req.From)
+	to, err2 := time.Parse("2006-01-02", req.To)
+	if err1 != nil || err2 != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date"})
+		return
+	}
+	res, err := h.svc.SyncReturns(c.Request.Context(), req.Store, from, to.Add(24*time.Hour))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func (h *ReturnHandler) losses(c *gin.Context) {
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date"})
+		return
+	}
+	list, err := h.svc.ReturnLosses(c.Request.Context(), c.Query("store"), from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *ReturnHandler) get(c *gin.Context) {
+	id, err := getIDParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	ret, err := h.svc.GetReturn(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(returnErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, ret)
+}
+
+func (h *ReturnHandler) supplierRefund(c *gin.Context) {
+	id, err := getIDParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var in models.SupplierRefundInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ret, err := h.svc.RecordSupplierRefund(c.Request.Context(), id, in)
+	if err != nil {
+		c.JSON(returnErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, ret)
+}
+
+func returnErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidReturnRefund):
+		return http.StatusBadRequest
+	case errors.Is(err, sql.ErrNoRows):
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
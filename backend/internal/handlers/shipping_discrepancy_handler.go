@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ramadhan22/dropship-erp/backend/internal/middleware"
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
 	"github.com/ramadhan22/dropship-erp/backend/internal/service"
 )
 
@@ -21,6 +23,7 @@ func NewShippingDiscrepancyHandler(svc *service.ShippingDiscrepancyService) *Shi
 func (h *ShippingDiscrepancyHandler) RegisterRoutes(r gin.IRouter) {
 	grp := r.Group("/shipping-discrepancies")
 	grp.GET("/", h.list)
+	grp.GET("/filtered", middleware.FilterMiddlewareFor(models.ResourceShippingDiscrepancies), h.listFiltered)
 	grp.GET("/stats", h.stats)
 	grp.GET("/invoice/:invoice", h.getByInvoice)
 }
@@ -55,6 +58,12 @@ func (h *ShippingDiscrepancyHandler) list(c *gin.Context) {
 	})
 }
 
+// listFiltered retrieves shipping discrepancies using the filtering framework
+func (h *ShippingDiscrepancyHandler) listFiltered(c *gin.Context) {
+	result, err := h.svc.ListShippingDiscrepanciesFiltered(c.Request.Context(), middleware.GetFilterParams(c))
+	writeQueryResult(c, result, err)
+}
+
 // stats retrieves shipping discrepancy statistics for a date range
 func (h *ShippingDiscrepancyHandler) stats(c *gin.Context) {
 	startDateStr := c.DefaultQuery("start_date", time.Now().AddDate(0, -1, 0).Format("2006-01-02"))
@@ -6,12 +6,14 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ramadhan22/dropship-erp/backend/internal/middleware"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
 )
 
 type ShopeeAdjustmentSvc interface {
 	ImportXLSX(ctx context.Context, r io.Reader) (int, error)
 	List(ctx context.Context, from, to string) ([]models.ShopeeAdjustment, error)
+	ListFiltered(ctx context.Context, params *models.FilterParams) (*models.QueryResult, error)
 	Update(ctx context.Context, a *models.ShopeeAdjustment) error
 	Delete(ctx context.Context, id int64) error
 }
@@ -26,6 +28,7 @@ func (h *ShopeeAdjustmentHandler) RegisterRoutes(r gin.IRouter) {
 	grp := r.Group("/shopee/adjustments")
 	grp.POST("/import", h.importXLSX)
 	grp.GET("/", h.list)
+	grp.GET("/filtered", middleware.FilterMiddlewareFor(models.ResourceShopeeAdjustments), h.listFiltered)
 	grp.PUT("/:id", h.update)
 	grp.DELETE("/:id", h.delete)
 }
@@ -61,6 +64,11 @@ func (h *ShopeeAdjustmentHandler) list(c *gin.Context) {
 	c.JSON(http.StatusOK, list)
 }
 
+func (h *ShopeeAdjustmentHandler) listFiltered(c *gin.Context) {
+	result, err := h.svc.ListFiltered(c.Request.Context(), middleware.GetFilterParams(c))
+	writeQueryResult(c, result, err)
+}
+
 func (h *ShopeeAdjustmentHandler) update(c *gin.Context) {
 	id, _ := getIDParam(c)
 	var a models.ShopeeAdjustment
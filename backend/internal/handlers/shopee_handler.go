@@ -3,12 +3,14 @@ package handlers
 import (
 	"context"
 	"io"
-	"log"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ramadhan22/dropship-erp/backend/internal/logutil"
+	"github.com/ramadhan22/dropship-erp/backend/internal/middleware"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 )
 
 // ShopeeServiceInterface defines only the method the handler needs.
@@ -18,10 +20,12 @@ type ShopeeServiceInterface interface {
 	ListSettled(ctx context.Context, channel, store, from, to, orderNo, sortBy, dir string, limit, offset int) ([]models.ShopeeSettled, int, error)
 	SumShopeeSettled(ctx context.Context, channel, store, from, to string) (*models.ShopeeSummary, error)
 	ListAffiliate(ctx context.Context, noPesanan, from, to string, limit, offset int) ([]models.ShopeeAffiliateSale, int, error)
+	ListSettledFiltered(ctx context.Context, params *models.FilterParams) (*models.QueryResult, error)
+	ListAffiliateFiltered(ctx context.Context, params *models.FilterParams) (*models.QueryResult, error)
 	SumAffiliate(ctx context.Context, noPesanan, from, to string) (*models.ShopeeAffiliateSummary, error)
 	ListSalesProfit(ctx context.Context, channel, store, from, to, orderNo, sortBy, dir string, limit, offset int) ([]models.SalesProfit, int, error)
 	ConfirmSettle(ctx context.Context, orderSN string) error
-	GetSettleDetail(ctx context.Context, orderSN string) (*models.ShopeeSettled, float64, error)
+	GetSettleDetail(ctx context.Context, orderSN string) (*models.ShopeeSettled, money.Amount, error)
 	GetReturnList(ctx context.Context, storeFilter, pageNo, pageSize, createTimeFrom, createTimeTo, updateTimeFrom, updateTimeTo, status, negotiationStatus, sellerProofStatus, sellerCompensationStatus string) ([]models.ShopeeOrderReturn, bool, error)
 }
 
@@ -50,7 +54,11 @@ func (h *ShopeeHandler) HandleImport(c *gin.Context) {
 	total := 0
 	mismatches := []string{}
 	for i, fh := range files {
-		log.Printf("HandleImport processing file %d of %d: %s", i+1, len(files), fh.Filename)
+		logutil.Info(ctx, "HandleImport", "Importing settled orders file", map[string]interface{}{
+			"file":  fh.Filename,
+			"index": i + 1,
+			"files": len(files),
+		})
 		f, err := fh.Open()
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -62,7 +70,12 @@ func (h *ShopeeHandler) HandleImport(c *gin.Context) {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
-		log.Printf("HandleImport finished file %d of %d: %s inserted=%d", i+1, len(files), fh.Filename, count)
+		logutil.Info(ctx, "HandleImport", "Imported settled orders file", map[string]interface{}{
+			"file":     fh.Filename,
+			"index":    i + 1,
+			"files":    len(files),
+			"inserted": count,
+		})
 		total += count
 		mismatches = append(mismatches, mis...)
 	}
@@ -103,7 +116,11 @@ func (h *ShopeeHandler) HandleImportAffiliate(c *gin.Context) {
 	ctx := c.Request.Context()
 	total := 0
 	for i, fh := range files {
-		log.Printf("HandleImportAffiliate processing file %d of %d: %s", i+1, len(files), fh.Filename)
+		logutil.Info(ctx, "HandleImportAffiliate", "Importing affiliate file", map[string]interface{}{
+			"file":  fh.Filename,
+			"index": i + 1,
+			"files": len(files),
+		})
 		f, err := fh.Open()
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -115,7 +132,12 @@ func (h *ShopeeHandler) HandleImportAffiliate(c *gin.Context) {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
-		log.Printf("HandleImportAffiliate finished file %d of %d: %s inserted=%d", i+1, len(files), fh.Filename, count)
+		logutil.Info(ctx, "HandleImportAffiliate", "Imported affiliate file", map[string]interface{}{
+			"file":     fh.Filename,
+			"index":    i + 1,
+			"files":    len(files),
+			"inserted": count,
+		})
 		total += count
 	}
 	c.JSON(http.StatusOK, gin.H{"inserted": total})
@@ -148,6 +170,12 @@ func (h *ShopeeHandler) HandleListSettled(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"data": list, "total": total})
 }
 
+// HandleListSettledFiltered returns settled orders using the filtering framework.
+func (h *ShopeeHandler) HandleListSettledFiltered(c *gin.Context) {
+	result, err := h.svc.ListSettledFiltered(c.Request.Context(), middleware.GetFilterParams(c))
+	writeQueryResult(c, result, err)
+}
+
 // HandleSumSettled returns the total penerimaan for all filtered rows.
 func (h *ShopeeHandler) HandleSumSettled(c *gin.Context) {
 	channel := c.Query("channel")
@@ -186,6 +214,12 @@ func (h *ShopeeHandler) HandleListAffiliate(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"data": list, "total": total})
 }
 
+// HandleListAffiliateFiltered returns affiliate sales using the filtering framework.
+func (h *ShopeeHandler) HandleListAffiliateFiltered(c *gin.Context) {
+	result, err := h.svc.ListAffiliateFiltered(c.Request.Context(), middleware.GetFilterParams(c))
+	writeQueryResult(c, result, err)
+}
+
 // HandleSumAffiliate returns total values for filtered affiliate rows.
 func (h *ShopeeHandler) HandleSumAffiliate(c *gin.Context) {
 	noPesanan := c.Query("no_pesanan")
@@ -13,6 +13,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 )
 
 // fake service
@@ -53,6 +54,14 @@ func (f *fakeShopeeService) ListAffiliate(ctx context.Context, date, month, year
 	return nil, 0, nil
 }
 
+func (f *fakeShopeeService) ListSettledFiltered(ctx context.Context, params *models.FilterParams) (*models.QueryResult, error) {
+	return &models.QueryResult{}, nil
+}
+
+func (f *fakeShopeeService) ListAffiliateFiltered(ctx context.Context, params *models.FilterParams) (*models.QueryResult, error) {
+	return &models.QueryResult{}, nil
+}
+
 func (f *fakeShopeeService) SumAffiliate(ctx context.Context, date, month, year string) (*models.ShopeeAffiliateSummary, error) {
 	return &models.ShopeeAffiliateSummary{}, nil
 }
@@ -61,7 +70,7 @@ func (f *fakeShopeeService) ListSalesProfit(ctx context.Context, channel, store,
 	return nil, 0, nil
 }
 
-func (f *fakeShopeeService) GetSettleDetail(ctx context.Context, orderSN string) (*models.ShopeeSettled, float64, error) {
+func (f *fakeShopeeService) GetSettleDetail(ctx context.Context, orderSN string) (*models.ShopeeSettled, money.Amount, error) {
 	return &models.ShopeeSettled{NoPesanan: orderSN}, 0, nil
 }
 
@@ -2,16 +2,35 @@ package handlers
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ramadhan22/dropship-erp/backend/internal/export"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/service"
 )
 
 // TaxServiceInterface defines needed methods.
 type TaxServiceInterface interface {
 	ComputeTax(ctx context.Context, store, periodType, periodValue string) (*models.TaxPayment, error)
 	PayTax(ctx context.Context, tp *models.TaxPayment) error
+
+	ListRegimes(ctx context.Context, store string) ([]models.TaxRegime, error)
+	CreateRegime(ctx context.Context, g *models.TaxRegime) error
+	UpdateRegime(ctx context.Context, g *models.TaxRegime) error
+	DeleteRegime(ctx context.Context, id int64) error
+	ComputeObligations(ctx context.Context, store, period string) ([]models.TaxPayment, error)
+	RecordObligations(ctx context.Context, store, period string) ([]models.TaxPayment, error)
+	ListObligations(ctx context.Context, store, periodPrefix string, unpaidOnly bool) ([]models.TaxPayment, error)
+	PayObligation(ctx context.Context, id string) (*models.TaxPayment, error)
+	CumulativeRevenue(ctx context.Context, store string, year int) ([]models.TaxMonth, error)
+	AnnualSummary(ctx context.Context, store string, year int) (*models.TaxAnnualSummary, error)
+	ExportAnnualSummary(ctx context.Context, store string, year int, format export.Format) ([]byte, string, error)
+	SendReminders(ctx context.Context) (int, error)
 }
 
 type TaxHandler struct{ svc TaxServiceInterface }
@@ -22,6 +41,19 @@ func (h *TaxHandler) Register(r gin.IRouter) {
 	grp := r.Group("/tax-payment")
 	grp.GET("", h.Get)
 	grp.POST("/pay", h.Pay)
+
+	tax := r.Group("/tax")
+	tax.GET("/regimes", h.listRegimes)
+	tax.POST("/regimes", h.createRegime)
+	tax.PUT("/regimes/:id", h.updateRegime)
+	tax.DELETE("/regimes/:id", h.deleteRegime)
+	tax.GET("/obligations", h.listObligations)
+	tax.GET("/obligations/compute", h.computeObligations)
+	tax.POST("/obligations/record", h.recordObligations)
+	tax.POST("/obligations/:id/pay", h.payObligation)
+	tax.GET("/revenue", h.revenue)
+	tax.GET("/summary", h.summary)
+	tax.POST("/reminders/send", h.sendReminders)
 }
 
 func (h *TaxHandler) Get(c *gin.Context) {
@@ -48,3 +80,172 @@ func (h *TaxHandler) Pay(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, gin.H{"success": true})
 }
+
+func (h *TaxHandler) listRegimes(c *gin.Context) {
+	list, err := h.svc.ListRegimes(c.Request.Context(), c.Query("store"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *TaxHandler) createRegime(c *gin.Context) {
+	var g models.TaxRegime
+	if err := c.ShouldBindJSON(&g); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.svc.CreateRegime(c.Request.Context(), &g); err != nil {
+		c.JSON(taxErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, g)
+}
+
+func (h *TaxHandler) updateRegime(c *gin.Context) {
+	id, err := getIDParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var g models.TaxRegime
+	if err := c.ShouldBindJSON(&g); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	g.ID = id
+	if err := h.svc.UpdateRegime(c.Request.Context(), &g); err != nil {
+		c.JSON(taxErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, g)
+}
+
+func (h *TaxHandler) deleteRegime(c *gin.Context) {
+	id, err := getIDParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	if err := h.svc.DeleteRegime(c.Request.Context(), id); err != nil {
+		c.JSON(taxErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// listObligations returns recorded obligations filtered by store, period
+// prefix (period=2025 or 2025-06) and unpaid=true.
+func (h *TaxHandler) listObligations(c *gin.Context) {
+	unpaid, _ := strconv.ParseBool(c.Query("unpaid"))
+	list, err := h.svc.ListObligations(c.Request.Context(), c.Query("store"), c.Query("period"), unpaid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// computeObligations previews a month's taxes without saving them.
+func (h *TaxHandler) computeObligations(c *gin.Context) {
+	list, err := h.svc.ComputeObligations(c.Request.Context(), c.Query("store"), c.Query("period"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *TaxHandler) recordObligations(c *gin.Context) {
+	var req struct {
+		Store  string `json:"store" binding:"required"`
+		Period string `json:"period" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	list, err := h.svc.RecordObligations(c.Request.Context(), req.Store, req.Period)
+	if err != nil {
+		c.JSON(taxErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *TaxHandler) payObligation(c *gin.Context) {
+	tp, err := h.svc.PayObligation(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(taxErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, tp)
+}
+
+func (h *TaxHandler) revenue(c *gin.Context) {
+	year, err := strconv.Atoi(c.Query("year"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid year"})
+		return
+	}
+	list, err := h.svc.CumulativeRevenue(c.Request.Context(), c.Query("store"), year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// summary returns the annual SPT summary as JSON, or as a file when format
+// is xlsx, csv or pdf.
+func (h *TaxHandler) summary(c *gin.Context) {
+	year, err := strconv.Atoi(c.Query("year"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid year"})
+		return
+	}
+	store := c.Query("store")
+	if f := c.Query("format"); f != "" && f != "json" {
+		format, err := export.ParseFormat(f)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		data, filename, err := h.svc.ExportAnnualSummary(c.Request.Context(), store, year, format)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		c.Data(http.StatusOK, format.ContentType(), data)
+		return
+	}
+	sum, err := h.svc.AnnualSummary(c.Request.Context(), store, year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, sum)
+}
+
+func (h *TaxHandler) sendReminders(c *gin.Context) {
+	n, err := h.svc.SendReminders(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"reminded": n})
+}
+
+func taxErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidTaxRegime):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrTaxAlreadyPaid):
+		return http.StatusConflict
+	case errors.Is(err, sql.ErrNoRows):
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
@@ -5,10 +5,11 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 )
 
 type WithdrawService interface {
-	WithdrawShopeeBalance(ctx context.Context, store string, amount float64) error
+	WithdrawShopeeBalance(ctx context.Context, store string, amount money.Amount) error
 }
 
 type WithdrawHandler struct{ svc WithdrawService }
@@ -19,8 +20,8 @@ func (h *WithdrawHandler) RegisterRoutes(r gin.IRouter) { r.POST("/withdraw", h.
 
 func (h *WithdrawHandler) handleWithdraw(c *gin.Context) {
 	var req struct {
-		Store  string  `json:"store" binding:"required"`
-		Amount float64 `json:"amount" binding:"required"`
+		Store  string       `json:"store" binding:"required"`
+		Amount money.Amount `json:"amount" binding:"required"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -0,0 +1,142 @@
+// File: backend/internal/lifecycle/lifecycle.go
+
+// Package lifecycle defines the states a dropship purchase moves through and
+// the transitions allowed between them. Every status write goes through
+// Check so an order can never jump backwards, e.g. from cancelled to settled.
+package lifecycle
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Status is the lifecycle state of a purchase.
+type Status string
+
+const (
+	// Imported is the initial state after a CSV row is stored.
+	Imported Status = "imported"
+	// PendingSale means the pending sales journal has been posted and the
+	// marketplace payout is outstanding.
+	PendingSale Status = "pending_sale"
+	// Shipped means the marketplace reports the parcel as on its way.
+	Shipped Status = "shipped"
+	// Settled means the escrow or settlement has been received.
+	Settled Status = "settled"
+	// Cancelled means the order was cancelled and its journals reversed.
+	Cancelled Status = "cancelled"
+	// Returned means the whole order came back.
+	Returned Status = "returned"
+	// PartiallyReturned means some items came back.
+	PartiallyReturned Status = "partially_returned"
+)
+
+// Statuses lists every state in lifecycle order.
+var Statuses = []Status{Imported, PendingSale, Shipped, Settled, Cancelled, Returned, PartiallyReturned}
+
+// transitions lists the states reachable from each state. Imported can go
+// straight to a return because reconciliation may find an order that Shopee
+// already reports as returned before its pending sales journal was posted.
+var transitions = map[Status][]Status{
+	Imported:          {PendingSale, Shipped, Settled, Cancelled, Returned, PartiallyReturned},
+	PendingSale:       {Shipped, Settled, Cancelled, Returned, PartiallyReturned},
+	Shipped:           {Settled, Cancelled, Returned, PartiallyReturned},
+	Settled:           {Returned, PartiallyReturned},
+	PartiallyReturned: {Returned},
+	Cancelled:         {},
+	Returned:          {},
+}
+
+// labels are written to status_pesanan_terakhir, the display status shown in
+// the UI. States without a label keep the status text from the import.
+var labels = map[Status]string{
+	Settled:           "Pesanan selesai",
+	Cancelled:         "Pesanan dibatalkan",
+	Returned:          "Pesanan dikembalikan",
+	PartiallyReturned: "Sebagian dikembalikan",
+}
+
+// ErrInvalidTransition is returned when a status change is not allowed.
+var ErrInvalidTransition = errors.New("invalid status transition")
+
+// Valid reports whether s is a known state.
+func (s Status) Valid() bool {
+	_, ok := transitions[s]
+	return ok
+}
+
+// Terminal reports whether no further transitions are possible from s.
+func (s Status) Terminal() bool {
+	return s.Valid() && len(transitions[s]) == 0
+}
+
+// Label returns the default display text for s, or "" when the imported
+// status text should be kept.
+func (s Status) Label() string {
+	return labels[s]
+}
+
+// Next returns the states reachable from s.
+func (s Status) Next() []Status {
+	return append([]Status(nil), transitions[s]...)
+}
+
+// CanTransition reports whether an order may move from one state to another.
+func CanTransition(from, to Status) bool {
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Check returns an error wrapping ErrInvalidTransition when from cannot move
+// to to. Staying in the same state is allowed and treated as a no-op by
+// callers.
+func Check(from, to Status) error {
+	if !to.Valid() {
+		return fmt.Errorf("%w: unknown status %q", ErrInvalidTransition, to)
+	}
+	if from == to || CanTransition(from, to) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, from, to)
+}
+
+// Cause records why a transition happened.
+type Cause string
+
+const (
+	CauseImport           Cause = "import"
+	CausePendingJournal   Cause = "pending_sales_journal"
+	CauseFreeSample       Cause = "free_sample"
+	CauseShopeeShipped    Cause = "shopee_shipped"
+	CauseEscrowSettlement Cause = "escrow_settlement"
+	CauseSettledImport    Cause = "settled_import"
+	CauseCompletionCheck  Cause = "completion_check"
+	CauseManualCancel     Cause = "manual_cancel"
+	CauseShopeeCancelled  Cause = "shopee_cancelled"
+	CauseShopeeReturn     Cause = "shopee_return"
+)
+
+// Change describes a requested transition.
+type Change struct {
+	To    Status
+	Cause Cause
+	// Label overrides the display status; empty uses To.Label().
+	Label string
+	// Note is optional free text stored with the history entry.
+	Note string
+	// At is when the change happened; zero means now.
+	At time.Time
+}
+
+// DisplayLabel returns the display status to store for the change.
+func (c Change) DisplayLabel() string {
+	if c.Label != "" {
+		return c.Label
+	}
+	return c.To.Label()
+}
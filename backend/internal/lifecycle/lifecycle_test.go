@@ -0,0 +1,50 @@
+package lifecycle
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	cases := []struct {
+		from, to Status
+		ok       bool
+	}{
+		{Imported, PendingSale, true},
+		{Imported, Returned, true},
+		{Imported, PartiallyReturned, true},
+		{PendingSale, Settled, true},
+		{Shipped, Returned, true},
+		{Settled, PartiallyReturned, true},
+		{PartiallyReturned, Returned, true},
+		{Settled, Settled, true},
+		{Cancelled, Settled, false},
+		{Settled, PendingSale, false},
+		{Returned, Cancelled, false},
+		{Imported, Status("lost"), false},
+	}
+	for _, c := range cases {
+		err := Check(c.from, c.to)
+		if c.ok && err != nil {
+			t.Errorf("%s -> %s: unexpected error %v", c.from, c.to, err)
+		}
+		if !c.ok && !errors.Is(err, ErrInvalidTransition) {
+			t.Errorf("%s -> %s: expected ErrInvalidTransition, got %v", c.from, c.to, err)
+		}
+	}
+}
+
+func TestTerminalAndLabels(t *testing.T) {
+	if !Cancelled.Terminal() || !Returned.Terminal() || Settled.Terminal() {
+		t.Error("unexpected terminal states")
+	}
+	if got := (Change{To: Cancelled, Label: "Cancelled Shopee"}).DisplayLabel(); got != "Cancelled Shopee" {
+		t.Errorf("label override = %q", got)
+	}
+	if got := (Change{To: Settled}).DisplayLabel(); got != "Pesanan selesai" {
+		t.Errorf("settled label = %q", got)
+	}
+	if got := (Change{To: Shipped}).DisplayLabel(); got != "" {
+		t.Errorf("shipped should keep the imported label, got %q", got)
+	}
+}
@@ -1,28 +1,55 @@
 package logutil
 
 import (
+	"compress/gzip"
 	"io"
+	"log"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// Retention controls what happens to day files once their day is over.
+type Retention struct {
+	// CompressAfterDays gzips day files at least this many days old into
+	// YYYY-MM-DD.log.gz. Zero keeps them uncompressed.
+	CompressAfterDays int
+	// MaxAgeDays deletes day files older than this many days. Zero keeps
+	// them forever.
+	MaxAgeDays int
+}
+
 // DailyFileWriter writes logs to a file named by today's date inside dir.
-// It automatically rotates when the day changes.
+// It automatically rotates when the day changes and then applies its
+// Retention to older files in the background.
 type DailyFileWriter struct {
-	dir  string
-	date string
-	mu   sync.Mutex
-	f    *os.File
+	dir       string
+	retention Retention
+	date      string
+	mu        sync.Mutex
+	f         *os.File
+	cleaning  atomic.Bool
 }
 
 // NewDailyFileWriter creates a writer that stores logs in dir/YYYY-MM-DD.log.
 func NewDailyFileWriter(dir string) (*DailyFileWriter, error) {
-	w := &DailyFileWriter{dir: dir}
+	return NewDailyFileWriterWithRetention(dir, Retention{})
+}
+
+// NewDailyFileWriterWithRetention is like NewDailyFileWriter but also
+// compresses and deletes old day files.
+func NewDailyFileWriterWithRetention(dir string, r Retention) (*DailyFileWriter, error) {
+	w := &DailyFileWriter{dir: dir, retention: r}
 	return w, w.rotate()
 }
 
+// Dir returns the directory holding the day files.
+func (w *DailyFileWriter) Dir() string { return w.dir }
+
 func (w *DailyFileWriter) Write(p []byte) (int, error) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
@@ -50,6 +77,15 @@ func (w *DailyFileWriter) rotate() error {
 	}
 	w.f = f
 	w.date = today
+	if w.retention != (Retention{}) && w.cleaning.CompareAndSwap(false, true) {
+		go func() {
+			defer w.cleaning.Store(false)
+			if err := ApplyRetention(w.dir, w.retention, time.Now()); err != nil {
+				// Written to stderr: logging it here would re-enter Write.
+				log.New(os.Stderr, "", log.LstdFlags).Printf("log retention: %v", err)
+			}
+		}()
+	}
 	return nil
 }
 
@@ -64,3 +100,106 @@ func (w *DailyFileWriter) Close() error {
 }
 
 var _ io.WriteCloser = (*DailyFileWriter)(nil)
+
+// dayFile is a log file named after its day.
+type dayFile struct {
+	path       string
+	day        time.Time
+	compressed bool
+}
+
+// listDayFiles returns the day files in dir, newest first. Other files are
+// ignored.
+func listDayFiles(dir string) ([]dayFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var files []dayFile
+	for _, e := range entries {
+		name := e.Name()
+		compressed := strings.HasSuffix(name, ".log.gz")
+		base := strings.TrimSuffix(strings.TrimSuffix(name, ".gz"), ".log")
+		if e.IsDir() || (!compressed && !strings.HasSuffix(name, ".log")) {
+			continue
+		}
+		day, err := time.ParseInLocation("2006-01-02", base, time.Local)
+		if err != nil {
+			continue
+		}
+		files = append(files, dayFile{path: filepath.Join(dir, name), day: day, compressed: compressed})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].day.Equal(files[j].day) {
+			// Read the live plain file before a gzip of the same day.
+			return !files[i].compressed
+		}
+		return files[i].day.After(files[j].day)
+	})
+	return files, nil
+}
+
+// ApplyRetention compresses and deletes day files in dir according to r,
+// counting age in whole days before now. Today's file is never touched.
+func ApplyRetention(dir string, r Retention, now time.Time) error {
+	files, err := listDayFiles(dir)
+	if err != nil {
+		return err
+	}
+	y, m, d := now.Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+	for _, f := range files {
+		age := int(today.Sub(f.day).Hours() / 24)
+		if age <= 0 {
+			continue
+		}
+		switch {
+		case r.MaxAgeDays > 0 && age > r.MaxAgeDays:
+			if err := os.Remove(f.path); err != nil {
+				return err
+			}
+		case r.CompressAfterDays > 0 && age >= r.CompressAfterDays && !f.compressed:
+			if err := gzipFile(f.path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// gzipFile replaces path with path.gz. An existing .gz for the same day,
+// left by an earlier partial run, is overwritten.
+func gzipFile(path string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	tmp := path + ".gz.tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(out)
+	if _, err := io.Copy(zw, in); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path+".gz"); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
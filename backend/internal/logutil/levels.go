@@ -0,0 +1,104 @@
+package logutil
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// AllComponents is the component name whose level override applies to every
+// component without an override of its own.
+const AllComponents = "*"
+
+var (
+	levelsMu   sync.RWMutex
+	overrides  = map[string]LogLevel{}
+	components = map[string]bool{}
+)
+
+// SetComponentLevel overrides the level of one component, e.g.
+// "reconcile-service", or of every component when name is AllComponents.
+func SetComponentLevel(name string, level LogLevel) {
+	name = strings.TrimSpace(name)
+	levelsMu.Lock()
+	overrides[name] = level
+	levelsMu.Unlock()
+}
+
+// ClearComponentLevel removes an override so the component falls back to
+// the AllComponents override or its own default.
+func ClearComponentLevel(name string) {
+	levelsMu.Lock()
+	delete(overrides, strings.TrimSpace(name))
+	levelsMu.Unlock()
+}
+
+// ComponentLevels returns the current overrides by component.
+func ComponentLevels() map[string]string {
+	levelsMu.RLock()
+	defer levelsMu.RUnlock()
+	out := make(map[string]string, len(overrides))
+	for name, level := range overrides {
+		out[name] = level.String()
+	}
+	return out
+}
+
+// Components lists every component that has logged or created a Logger.
+func Components() []string {
+	levelsMu.RLock()
+	defer levelsMu.RUnlock()
+	out := make([]string, 0, len(components))
+	for name := range components {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func registerComponent(name string) {
+	levelsMu.RLock()
+	known := components[name]
+	levelsMu.RUnlock()
+	if known {
+		return
+	}
+	levelsMu.Lock()
+	components[name] = true
+	levelsMu.Unlock()
+}
+
+// effectiveLevel is the override for component, else the AllComponents
+// override, else def.
+func effectiveLevel(component string, def LogLevel) LogLevel {
+	levelsMu.RLock()
+	defer levelsMu.RUnlock()
+	if level, ok := overrides[component]; ok {
+		return level
+	}
+	if level, ok := overrides[AllComponents]; ok {
+		return level
+	}
+	return def
+}
+
+// ApplyLevels installs the configured levels: def for every component and
+// levels per component. An empty def leaves each logger's own default.
+func ApplyLevels(def string, levels map[string]string) error {
+	if def != "" {
+		level, err := ParseLevel(def)
+		if err != nil {
+			return err
+		}
+		SetComponentLevel(AllComponents, level)
+	}
+	for name, v := range levels {
+		level, err := ParseLevel(v)
+		if err != nil {
+			return fmt.Errorf("logging.levels.%s: %w", name, err)
+		}
+		SetComponentLevel(name, level)
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package logutil
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// captureOutput redirects structured output to a buffer for one test.
+func captureOutput(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	t.Cleanup(func() { SetOutput(os.Stderr) })
+	return &buf
+}
+
+func decodeLines(t *testing.T, buf *bytes.Buffer) []Record {
+	t.Helper()
+	var out []Record
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var r Record
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("line is not JSON: %q", line)
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+func TestLoggerWritesJSONWithContext(t *testing.T) {
+	buf := captureOutput(t)
+	ctx := WithShop(WithCorrelationID(context.Background(), "corr-1"), "toko-a")
+	logger := NewLogger("reconcile-service", INFO)
+
+	logger.Error(ctx, "ReconcileBatch", "escrow fetch failed", errors.New("timeout"), map[string]interface{}{"batch_id": 7})
+
+	recs := decodeLines(t, buf)
+	if len(recs) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(recs))
+	}
+	r := recs[0]
+	if r.Level != "ERROR" || r.Component != "reconcile-service" || r.Operation != "ReconcileBatch" ||
+		r.CorrelationID != "corr-1" || r.Shop != "toko-a" || r.Error != "timeout" {
+		t.Fatalf("unexpected record %+v", r)
+	}
+	if r.Fields["batch_id"] != float64(7) || !strings.HasPrefix(r.Caller, "output_test.go:") {
+		t.Fatalf("unexpected fields %+v caller %q", r.Fields, r.Caller)
+	}
+}
+
+func TestComponentLevelOverrides(t *testing.T) {
+	buf := captureOutput(t)
+	t.Cleanup(func() {
+		ClearComponentLevel("quiet")
+		ClearComponentLevel(AllComponents)
+	})
+	quiet := NewLogger("quiet", DEBUG)
+	other := NewLogger("other", DEBUG)
+	ctx := context.Background()
+
+	SetComponentLevel("quiet", WARN)
+	quiet.Info(ctx, "Op", "dropped")
+	other.Debug(ctx, "Op", "kept")
+	SetComponentLevel(AllComponents, ERROR)
+	other.Warn(ctx, "Op", "dropped by *")
+	quiet.Warn(ctx, "Op", "kept by own override")
+
+	var msgs []string
+	for _, r := range decodeLines(t, buf) {
+		msgs = append(msgs, r.Message)
+	}
+	if strings.Join(msgs, ",") != "kept,kept by own override" {
+		t.Fatalf("unexpected messages %v", msgs)
+	}
+	if ComponentLevels()["quiet"] != "WARN" {
+		t.Fatalf("override not listed: %v", ComponentLevels())
+	}
+}
+
+func TestStdlibWriterBridgesPlainLogLines(t *testing.T) {
+	buf := captureOutput(t)
+	StdlibWriter{Level: WARN}.Write([]byte("server.go:3217: http: TLS handshake error from 10.0.0.1\n" +
+		"0 still failed\n"))
+
+	recs := decodeLines(t, buf)
+	if len(recs) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(recs))
+	}
+	if recs[0].Component != "server" || recs[0].Level != "WARN" || recs[0].Caller != "server.go:3217" ||
+		recs[0].Message != "http: TLS handshake error from 10.0.0.1" {
+		t.Fatalf("unexpected bridged record %+v", recs[0])
+	}
+	// The level is the writer's, not guessed from words like "failed".
+	if recs[1].Component != "dropship-erp" || recs[1].Level != "WARN" {
+		t.Fatalf("unexpected bridged record %+v", recs[1])
+	}
+}
+
+func TestErrorfLogsStack(t *testing.T) {
+	buf := captureOutput(t)
+	Errorf("batch %d failed", 3)
+	recs := decodeLines(t, buf)
+	if len(recs) != 1 || recs[0].Message != "batch 3 failed" || recs[0].Component != "output-test" {
+		t.Fatalf("unexpected record %+v", recs)
+	}
+	if stack, _ := recs[0].Fields["stack"].(string); !strings.Contains(stack, "goroutine") {
+		t.Fatalf("expected stack trace, got %q", stack)
+	}
+}
+
+func writeDay(t *testing.T, dir string, day time.Time, lines ...string) string {
+	t.Helper()
+	path := filepath.Join(dir, day.Format("2006-01-02")+".log")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestApplyRetentionCompressesAndDeletes(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.Local)
+	today := writeDay(t, dir, now, "today")
+	yesterday := writeDay(t, dir, now.AddDate(0, 0, -1), "yesterday")
+	old := writeDay(t, dir, now.AddDate(0, 0, -40), "old")
+	other := filepath.Join(dir, "notes.txt")
+	os.WriteFile(other, []byte("x"), 0644)
+
+	if err := ApplyRetention(dir, Retention{CompressAfterDays: 1, MaxAgeDays: 30}, now); err != nil {
+		t.Fatal(err)
+	}
+
+	for path, want := range map[string]bool{today: true, yesterday: false, yesterday + ".gz": true, old: false, other: true} {
+		if _, err := os.Stat(path); (err == nil) != want {
+			t.Errorf("%s exists=%v, want %v", filepath.Base(path), err == nil, want)
+		}
+	}
+	f, err := os.Open(yesterday + ".gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	b.ReadFrom(zr)
+	if b.String() != "yesterday\n" {
+		t.Fatalf("unexpected gzip content %q", b.String())
+	}
+}
+
+func TestSearchFindsNewestMatchesAcrossFiles(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	line := func(at time.Time, level, corr, shop, op, msg string) string {
+		b, _ := json.Marshal(Record{Time: at, Level: level, Component: "reconcile-service",
+			CorrelationID: corr, Shop: shop, Operation: op, Message: msg})
+		return string(b)
+	}
+	yesterday := now.AddDate(0, 0, -1)
+	writeDay(t, dir, yesterday,
+		line(yesterday, "INFO", "c1", "toko-a", "ReconcileBatch", "y1"),
+		"legacy plain text line",
+	)
+	if err := gzipFile(filepath.Join(dir, yesterday.Format("2006-01-02")+".log")); err != nil {
+		t.Fatal(err)
+	}
+	writeDay(t, dir, now,
+		line(now.Add(-2*time.Minute), "WARN", "c1", "toko-a", "ReconcileBatch", "t1"),
+		line(now.Add(-time.Minute), "INFO", "c2", "toko-b", "Import", "t2"),
+		line(now, "ERROR", "c1", "TOKO-A", "ReconcileBatch", "t3"),
+	)
+
+	recs, err := Search(dir, SearchQuery{CorrelationID: "c1", Since: now.AddDate(0, 0, -2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msgs []string
+	for _, r := range recs {
+		msgs = append(msgs, r.Message)
+	}
+	if strings.Join(msgs, ",") != "t3,t1,y1" {
+		t.Fatalf("unexpected order %v", msgs)
+	}
+
+	recs, _ = Search(dir, SearchQuery{Shop: "toko-a", MinLevel: WARN, Limit: 1, Since: now.AddDate(0, 0, -2)})
+	if len(recs) != 1 || recs[0].Message != "t3" {
+		t.Fatalf("unexpected %+v", recs)
+	}
+	recs, _ = Search(dir, SearchQuery{Operation: "import", Since: now.AddDate(0, 0, -2)})
+	if len(recs) != 1 || recs[0].Message != "t2" {
+		t.Fatalf("unexpected %+v", recs)
+	}
+	recs, _ = Search(dir, SearchQuery{Text: "legacy", Since: yesterday.Add(-24 * time.Hour)})
+	if len(recs) != 1 {
+		t.Fatalf("plain lines should be searchable by text, got %+v", recs)
+	}
+}
+
+func TestSearchSkipsOnlyOversizedLines(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	line := func(msg string) string {
+		b, _ := json.Marshal(Record{Time: now, Level: "INFO", CorrelationID: "c1", Message: msg})
+		return string(b)
+	}
+	writeDay(t, dir, now,
+		line("before"),
+		line(strings.Repeat("x", maxLineSize)),
+		line("after"),
+	)
+
+	recs, err := Search(dir, SearchQuery{CorrelationID: "c1", Since: now.Add(-time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msgs []string
+	for _, r := range recs {
+		msgs = append(msgs, r.Message)
+	}
+	if strings.Join(msgs, ",") != "after,before" {
+		t.Fatalf("unexpected messages %v", msgs)
+	}
+}
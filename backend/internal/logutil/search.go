@@ -0,0 +1,190 @@
+package logutil
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// SearchQuery selects log records. Empty fields match everything.
+type SearchQuery struct {
+	CorrelationID string
+	TraceID       string
+	Shop          string
+	// Operation and Component match case-insensitively on a substring.
+	Operation string
+	Component string
+	// Text matches case-insensitively anywhere in the message or error.
+	Text     string
+	MinLevel LogLevel
+	Since    time.Time
+	Until    time.Time
+	Limit    int
+}
+
+// maxLineSize bounds one log line; longer lines are skipped.
+const maxLineSize = 1 << 20
+
+// Search scans the day files in dir, newest first, and returns up to
+// q.Limit matching records, newest first. Files from before q.Since are not
+// opened. Lines that are not JSON, such as those in day files written
+// before structured output, are matched as a bare message.
+func Search(dir string, q SearchQuery) ([]Record, error) {
+	if q.Limit <= 0 {
+		q.Limit = 100
+	}
+	files, err := listDayFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	var sinceDay time.Time
+	if !q.Since.IsZero() {
+		y, m, d := q.Since.In(time.Local).Date()
+		sinceDay = time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+	}
+	out := []Record{}
+	seen := map[string]bool{}
+	for _, f := range files {
+		if !sinceDay.IsZero() && f.day.Before(sinceDay) {
+			break
+		}
+		// A day that is both plain and gzipped is a compression in
+		// progress; the plain file is complete, so skip the gzip.
+		key := f.day.Format("2006-01-02")
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		matches, err := searchFile(f, q)
+		if err != nil {
+			return nil, err
+		}
+		for i := len(matches) - 1; i >= 0 && len(out) < q.Limit; i-- {
+			out = append(out, matches[i])
+		}
+		if len(out) >= q.Limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+// searchFile returns the last q.Limit matches of one file, oldest first.
+func searchFile(f dayFile, q SearchQuery) ([]Record, error) {
+	file, err := os.Open(f.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// Compressed or deleted by retention since it was listed.
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+	var r io.Reader = file
+	if f.compressed {
+		zr, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		r = zr
+	}
+
+	var matches []Record
+	br := bufio.NewReaderSize(r, 64*1024)
+	var buf []byte
+	for {
+		line, err := readLine(br, buf[:0])
+		if err == io.EOF {
+			break
+		}
+		if err != nil && err != errLineTooLong {
+			return matches, err
+		}
+		buf = line
+		if err == errLineTooLong || len(line) == 0 {
+			continue
+		}
+		var rec Record
+		if line[0] != '{' || json.Unmarshal(line, &rec) != nil {
+			rec = Record{Time: f.day, Message: string(line)}
+		}
+		if !q.matches(rec) {
+			continue
+		}
+		matches = append(matches, rec)
+		if len(matches) > q.Limit {
+			matches = matches[1:]
+		}
+	}
+	return matches, nil
+}
+
+// errLineTooLong reports a line over maxLineSize; it has been consumed and
+// the next call starts on the following line.
+var errLineTooLong = errors.New("log line too long")
+
+// readLine appends the next line of br to buf without its line ending. A
+// line longer than maxLineSize is read to its end and discarded, so one
+// oversized record does not stop the search of the rest of the file.
+func readLine(br *bufio.Reader, buf []byte) ([]byte, error) {
+	tooLong := false
+	for {
+		chunk, isPrefix, err := br.ReadLine()
+		if err != nil {
+			return buf, err
+		}
+		if !tooLong && len(buf)+len(chunk) > maxLineSize {
+			tooLong = true
+			buf = buf[:0]
+		}
+		if !tooLong {
+			buf = append(buf, chunk...)
+		}
+		if !isPrefix {
+			break
+		}
+	}
+	if tooLong {
+		return buf, errLineTooLong
+	}
+	return buf, nil
+}
+
+func (q SearchQuery) matches(r Record) bool {
+	contains := func(s, sub string) bool {
+		return sub == "" || strings.Contains(strings.ToLower(s), strings.ToLower(sub))
+	}
+	if q.CorrelationID != "" && r.CorrelationID != q.CorrelationID {
+		return false
+	}
+	if q.TraceID != "" && r.TraceID != q.TraceID {
+		return false
+	}
+	if q.Shop != "" && !strings.EqualFold(r.Shop, q.Shop) {
+		return false
+	}
+	if !contains(r.Operation, q.Operation) || !contains(r.Component, q.Component) {
+		return false
+	}
+	if q.Text != "" && !contains(r.Message, q.Text) && !contains(r.Error, q.Text) {
+		return false
+	}
+	if q.MinLevel > DEBUG {
+		if level, err := ParseLevel(r.Level); err != nil || level < q.MinLevel {
+			return false
+		}
+	}
+	if !q.Since.IsZero() && r.Time.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && r.Time.After(q.Until) {
+		return false
+	}
+	return true
+}
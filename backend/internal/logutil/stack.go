@@ -1,20 +1,41 @@
 package logutil
 
 import (
-	"log"
+	"fmt"
 	"os"
 	"runtime/debug"
+	"time"
 )
 
+// exit is os.Exit, replaced in tests.
+var exit = os.Exit
+
 // Fatalf logs the message with stack trace and exits with status 1.
 func Fatalf(format string, args ...interface{}) {
-	log.Printf(format, args...)
-	debug.PrintStack()
-	os.Exit(1)
+	logWithStack(FATAL, fmt.Sprintf(format, args...))
+	exit(1)
 }
 
 // Errorf logs the message with stack trace but does not exit.
 func Errorf(format string, args ...interface{}) {
-	log.Printf(format, args...)
-	debug.PrintStack()
+	logWithStack(ERROR, fmt.Sprintf(format, args...))
+}
+
+// logWithStack writes a structured entry for the Printf-style helpers. The
+// component is derived from the calling file, as for bridged log.Printf
+// lines.
+func logWithStack(level LogLevel, message string) {
+	caller := getCaller(3)
+	component := componentFromCaller(caller)
+	if level < effectiveLevel(component, INFO) {
+		return
+	}
+	writeEntry(&LogEntry{
+		Level:     level,
+		Message:   message,
+		Timestamp: time.Now(),
+		Service:   component,
+		Caller:    caller,
+		Fields:    map[string]interface{}{"stack": string(debug.Stack())},
+	})
 }
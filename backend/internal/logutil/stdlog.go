@@ -0,0 +1,63 @@
+package logutil
+
+import (
+	"bytes"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// StdlibWriter turns lines written through the standard log package, which
+// is only used by libraries such as net/http and gin's panic recovery, into
+// structured entries at Level, so they end up in the same JSON files with the
+// same level controls. Install it with
+//
+//	log.SetFlags(log.Lshortfile)
+//	log.SetOutput(logutil.StdlibWriter{Level: logutil.WARN})
+//
+// The component is derived from the calling file, e.g. server.go logs as
+// "server". Application code logs through a Logger with an explicit level.
+type StdlibWriter struct {
+	Level LogLevel
+}
+
+// shortfilePrefix matches the "file.go:123: " prefix added by log.Lshortfile.
+var shortfilePrefix = regexp.MustCompile(`^([\w.-]+\.go:\d+): `)
+
+func (w StdlibWriter) Write(p []byte) (int, error) {
+	for _, line := range bytes.Split(bytes.TrimRight(p, "\n"), []byte("\n")) {
+		msg := string(line)
+		var caller string
+		if m := shortfilePrefix.FindStringSubmatch(msg); m != nil {
+			caller = m[1]
+			msg = msg[len(m[0]):]
+		}
+		component := componentFromCaller(caller)
+		registerComponent(component)
+		if w.Level < effectiveLevel(component, INFO) {
+			continue
+		}
+		writeEntry(&LogEntry{
+			Level:     w.Level,
+			Message:   msg,
+			Timestamp: time.Now(),
+			Service:   component,
+			Caller:    caller,
+		})
+	}
+	return len(p), nil
+}
+
+// componentFromCaller maps "reconcile_service.go:42" to "reconcile-service".
+func componentFromCaller(caller string) string {
+	file := caller
+	if i := strings.LastIndex(file, ":"); i >= 0 {
+		file = file[:i]
+	}
+	file = strings.TrimSuffix(filepath.Base(file), ".go")
+	if file == "" || file == "." || file == "unknown" {
+		return DefaultLogger.service
+	}
+	return strings.ReplaceAll(file, "_", "-")
+}
@@ -2,11 +2,17 @@ package logutil
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"log"
+	"io"
+	"os"
 	"runtime"
+	"runtime/debug"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/tracing"
 )
 
 // LogLevel represents different levels of logging
@@ -38,6 +44,23 @@ func (l LogLevel) String() string {
 	}
 }
 
+// ParseLevel reads a level name such as "debug" or "WARN".
+func ParseLevel(s string) (LogLevel, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "DEBUG":
+		return DEBUG, nil
+	case "INFO":
+		return INFO, nil
+	case "WARN", "WARNING":
+		return WARN, nil
+	case "ERROR":
+		return ERROR, nil
+	case "FATAL":
+		return FATAL, nil
+	}
+	return INFO, fmt.Errorf("unknown log level %q", s)
+}
+
 // LogEntry represents a structured log entry
 type LogEntry struct {
 	Level         LogLevel
@@ -47,12 +70,35 @@ type LogEntry struct {
 	Operation     string
 	CorrelationID string
 	UserID        string
+	Shop          string
+	TraceID       string
+	SpanID        string
 	Duration      *time.Duration
 	Error         error
+	Caller        string
 	Fields        map[string]interface{}
 }
 
-// Logger provides structured logging functionality
+// Record is the JSON form of a LogEntry, one per line in the log files.
+type Record struct {
+	Time          time.Time              `json:"time"`
+	Level         string                 `json:"level"`
+	Component     string                 `json:"component"`
+	Operation     string                 `json:"operation,omitempty"`
+	Message       string                 `json:"message"`
+	CorrelationID string                 `json:"correlation_id,omitempty"`
+	UserID        string                 `json:"user_id,omitempty"`
+	Shop          string                 `json:"shop,omitempty"`
+	TraceID       string                 `json:"trace_id,omitempty"`
+	SpanID        string                 `json:"span_id,omitempty"`
+	DurationMS    *float64               `json:"duration_ms,omitempty"`
+	Error         string                 `json:"error,omitempty"`
+	Caller        string                 `json:"caller,omitempty"`
+	Fields        map[string]interface{} `json:"fields,omitempty"`
+}
+
+// Logger provides structured logging functionality. Its level applies
+// until a runtime override is set for its service with SetComponentLevel.
 type Logger struct {
 	level   LogLevel
 	service string
@@ -60,6 +106,7 @@ type Logger struct {
 
 // NewLogger creates a new structured logger for the given service
 func NewLogger(service string, level LogLevel) *Logger {
+	registerComponent(service)
 	return &Logger{
 		level:   level,
 		service: service,
@@ -69,14 +116,17 @@ func NewLogger(service string, level LogLevel) *Logger {
 // DefaultLogger is the default logger instance
 var DefaultLogger = NewLogger("dropship-erp", INFO)
 
-// getCorrelationID extracts correlation ID from context
-func getCorrelationID(ctx context.Context) string {
-	return GetCorrelationID(ctx)
-}
+var (
+	outputMu sync.Mutex
+	output   io.Writer = os.Stderr
+)
 
-// getUserID extracts user ID from context
-func getUserID(ctx context.Context) string {
-	return GetUserID(ctx)
+// SetOutput sends every structured log line to w, usually a
+// DailyFileWriter.
+func SetOutput(w io.Writer) {
+	outputMu.Lock()
+	output = w
+	outputMu.Unlock()
 }
 
 // getCaller returns the caller information
@@ -93,193 +143,137 @@ func getCaller(skip int) string {
 	return fmt.Sprintf("%s:%d", file, line)
 }
 
-// formatLogEntry formats a log entry for output
-func (l *Logger) formatLogEntry(entry *LogEntry) string {
-	var parts []string
-
-	// Timestamp
-	parts = append(parts, entry.Timestamp.Format("2006-01-02 15:04:05.000"))
-
-	// Level
-	parts = append(parts, fmt.Sprintf("[%s]", entry.Level.String()))
-
-	// Service
-	if entry.Service != "" {
-		parts = append(parts, fmt.Sprintf("[%s]", entry.Service))
+// newEntry fills in the request details carried by ctx.
+func (l *Logger) newEntry(ctx context.Context, level LogLevel, operation, message string, fields []map[string]interface{}) *LogEntry {
+	entry := &LogEntry{
+		Level:         level,
+		Message:       message,
+		Timestamp:     time.Now(),
+		Service:       l.service,
+		Operation:     operation,
+		CorrelationID: GetCorrelationID(ctx),
+		UserID:        GetUserID(ctx),
+		Shop:          GetShop(ctx),
+		TraceID:       tracing.TraceIDFromContext(ctx),
+		SpanID:        tracing.SpanIDFromContext(ctx),
+		Fields:        make(map[string]interface{}),
 	}
-
-	// Operation
-	if entry.Operation != "" {
-		parts = append(parts, fmt.Sprintf("[%s]", entry.Operation))
+	if entry.Operation == "" {
+		entry.Operation = GetOperation(ctx)
 	}
 
-	// Correlation ID
-	if entry.CorrelationID != "" {
-		parts = append(parts, fmt.Sprintf("[%s]", entry.CorrelationID))
+	// Merge fields
+	for _, f := range fields {
+		for k, v := range f {
+			entry.Fields[k] = v
+		}
 	}
+	return entry
+}
 
-	// Duration
+// record converts an entry to its JSON form.
+func (entry *LogEntry) record() Record {
+	r := Record{
+		Time:          entry.Timestamp,
+		Level:         entry.Level.String(),
+		Component:     entry.Service,
+		Operation:     entry.Operation,
+		Message:       entry.Message,
+		CorrelationID: entry.CorrelationID,
+		UserID:        entry.UserID,
+		Shop:          entry.Shop,
+		TraceID:       entry.TraceID,
+		SpanID:        entry.SpanID,
+		Caller:        entry.Caller,
+	}
 	if entry.Duration != nil {
-		parts = append(parts, fmt.Sprintf("[%s]", entry.Duration.String()))
+		ms := float64(*entry.Duration) / float64(time.Millisecond)
+		r.DurationMS = &ms
 	}
-
-	// Message
-	parts = append(parts, entry.Message)
-
-	// Error
 	if entry.Error != nil {
-		parts = append(parts, fmt.Sprintf("error=%v", entry.Error))
+		r.Error = entry.Error.Error()
 	}
-
-	// Additional fields
-	for k, v := range entry.Fields {
-		parts = append(parts, fmt.Sprintf("%s=%v", k, v))
+	if r.Shop == "" {
+		for _, k := range []string{"shop", "store"} {
+			if s, ok := entry.Fields[k].(string); ok && s != "" {
+				r.Shop = s
+				break
+			}
+		}
 	}
-
-	return strings.Join(parts, " ")
+	if len(entry.Fields) > 0 {
+		r.Fields = make(map[string]interface{}, len(entry.Fields))
+		for k, v := range entry.Fields {
+			if err, ok := v.(error); ok {
+				v = err.Error()
+			}
+			r.Fields[k] = v
+		}
+	}
+	return r
 }
 
 // shouldLog determines if a log entry should be logged based on level
 func (l *Logger) shouldLog(level LogLevel) bool {
-	return level >= l.level
+	return level >= effectiveLevel(l.service, l.level)
 }
 
-// log outputs a log entry
+// log outputs a log entry as one JSON line
 func (l *Logger) log(entry *LogEntry) {
 	if !l.shouldLog(entry.Level) {
 		return
 	}
-
 	if entry.Service == "" {
 		entry.Service = l.service
 	}
+	writeEntry(entry)
+}
 
-	formatted := l.formatLogEntry(entry)
-	log.Println(formatted)
+func writeEntry(entry *LogEntry) {
+	b, err := json.Marshal(entry.record())
+	if err != nil {
+		// A field that cannot be encoded must not lose the message.
+		entry.Fields = map[string]interface{}{"fields_error": err.Error()}
+		b, _ = json.Marshal(entry.record())
+	}
+	outputMu.Lock()
+	defer outputMu.Unlock()
+	output.Write(append(b, '\n'))
 }
 
 // Debug logs a debug message
 func (l *Logger) Debug(ctx context.Context, operation, message string, fields ...map[string]interface{}) {
-	entry := &LogEntry{
-		Level:         DEBUG,
-		Message:       message,
-		Timestamp:     time.Now(),
-		Service:       l.service,
-		Operation:     operation,
-		CorrelationID: getCorrelationID(ctx),
-		UserID:        getUserID(ctx),
-		Fields:        make(map[string]interface{}),
-	}
-
-	// Merge fields
-	for _, f := range fields {
-		for k, v := range f {
-			entry.Fields[k] = v
-		}
-	}
-
-	l.log(entry)
+	l.log(l.newEntry(ctx, DEBUG, operation, message, fields))
 }
 
 // Info logs an info message
 func (l *Logger) Info(ctx context.Context, operation, message string, fields ...map[string]interface{}) {
-	entry := &LogEntry{
-		Level:         INFO,
-		Message:       message,
-		Timestamp:     time.Now(),
-		Service:       l.service,
-		Operation:     operation,
-		CorrelationID: getCorrelationID(ctx),
-		UserID:        getUserID(ctx),
-		Fields:        make(map[string]interface{}),
-	}
-
-	// Merge fields
-	for _, f := range fields {
-		for k, v := range f {
-			entry.Fields[k] = v
-		}
-	}
-
-	l.log(entry)
+	l.log(l.newEntry(ctx, INFO, operation, message, fields))
 }
 
 // Warn logs a warning message
 func (l *Logger) Warn(ctx context.Context, operation, message string, fields ...map[string]interface{}) {
-	entry := &LogEntry{
-		Level:         WARN,
-		Message:       message,
-		Timestamp:     time.Now(),
-		Service:       l.service,
-		Operation:     operation,
-		CorrelationID: getCorrelationID(ctx),
-		UserID:        getUserID(ctx),
-		Fields:        make(map[string]interface{}),
-	}
-
-	// Merge fields
-	for _, f := range fields {
-		for k, v := range f {
-			entry.Fields[k] = v
-		}
-	}
-
-	l.log(entry)
+	l.log(l.newEntry(ctx, WARN, operation, message, fields))
 }
 
 // Error logs an error message
 func (l *Logger) Error(ctx context.Context, operation, message string, err error, fields ...map[string]interface{}) {
-	entry := &LogEntry{
-		Level:         ERROR,
-		Message:       message,
-		Timestamp:     time.Now(),
-		Service:       l.service,
-		Operation:     operation,
-		CorrelationID: getCorrelationID(ctx),
-		UserID:        getUserID(ctx),
-		Error:         err,
-		Fields:        make(map[string]interface{}),
-	}
-
-	// Merge fields
-	for _, f := range fields {
-		for k, v := range f {
-			entry.Fields[k] = v
-		}
-	}
-
+	entry := l.newEntry(ctx, ERROR, operation, message, fields)
+	entry.Error = err
 	// Add caller information for errors
-	entry.Fields["caller"] = getCaller(2)
-
+	entry.Caller = getCaller(2)
 	l.log(entry)
 }
 
 // Fatal logs a fatal message and exits
 func (l *Logger) Fatal(ctx context.Context, operation, message string, err error, fields ...map[string]interface{}) {
-	entry := &LogEntry{
-		Level:         FATAL,
-		Message:       message,
-		Timestamp:     time.Now(),
-		Service:       l.service,
-		Operation:     operation,
-		CorrelationID: getCorrelationID(ctx),
-		UserID:        getUserID(ctx),
-		Error:         err,
-		Fields:        make(map[string]interface{}),
-	}
-
-	// Merge fields
-	for _, f := range fields {
-		for k, v := range f {
-			entry.Fields[k] = v
-		}
-	}
-
+	entry := l.newEntry(ctx, FATAL, operation, message, fields)
+	entry.Error = err
 	// Add caller information for fatal errors
-	entry.Fields["caller"] = getCaller(2)
-
+	entry.Caller = getCaller(2)
+	entry.Fields["stack"] = string(debug.Stack())
 	l.log(entry)
-	Fatalf("Fatal error: %s", message)
+	exit(1)
 }
 
 // WithOperation creates a new logger with a specific operation context
@@ -337,54 +331,19 @@ type TimerLogger struct {
 // Finish logs the completion of the operation with duration
 func (tl *TimerLogger) Finish(message string, fields ...map[string]interface{}) {
 	duration := time.Since(tl.startTime)
-	entry := &LogEntry{
-		Level:         INFO,
-		Message:       message,
-		Timestamp:     time.Now(),
-		Service:       tl.logger.service,
-		Operation:     tl.operation,
-		CorrelationID: getCorrelationID(tl.ctx),
-		UserID:        getUserID(tl.ctx),
-		Duration:      &duration,
-		Fields:        make(map[string]interface{}),
-	}
-
-	// Merge fields
-	for _, f := range fields {
-		for k, v := range f {
-			entry.Fields[k] = v
-		}
-	}
-
+	entry := tl.logger.newEntry(tl.ctx, INFO, tl.operation, message, fields)
+	entry.Duration = &duration
 	tl.logger.log(entry)
 }
 
 // FinishWithError logs the completion of the operation with an error
 func (tl *TimerLogger) FinishWithError(message string, err error, fields ...map[string]interface{}) {
 	duration := time.Since(tl.startTime)
-	entry := &LogEntry{
-		Level:         ERROR,
-		Message:       message,
-		Timestamp:     time.Now(),
-		Service:       tl.logger.service,
-		Operation:     tl.operation,
-		CorrelationID: getCorrelationID(tl.ctx),
-		UserID:        getUserID(tl.ctx),
-		Duration:      &duration,
-		Error:         err,
-		Fields:        make(map[string]interface{}),
-	}
-
-	// Merge fields
-	for _, f := range fields {
-		for k, v := range f {
-			entry.Fields[k] = v
-		}
-	}
-
+	entry := tl.logger.newEntry(tl.ctx, ERROR, tl.operation, message, fields)
+	entry.Duration = &duration
+	entry.Error = err
 	// Add caller information for errors
-	entry.Fields["caller"] = getCaller(2)
-
+	entry.Caller = getCaller(2)
 	tl.logger.log(entry)
 }
 
@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"runtime"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/cache"
+)
+
+// Application metrics, registered on Default.
+var (
+	HTTPRequestDuration = NewHistogramVec("http_request_duration_seconds",
+		"HTTP request latency by route template.", nil, "method", "route", "status")
+
+	ShopeeRequests = NewCounterVec("shopee_api_requests_total",
+		"Shopee API calls by endpoint path and HTTP status code; code is \"error\" when no response arrived.", "endpoint", "code")
+	ShopeeRequestDuration = NewHistogramVec("shopee_api_request_duration_seconds",
+		"Shopee API call latency by endpoint path.", nil, "endpoint")
+	ShopeeErrors = NewCounterVec("shopee_api_errors_total",
+		"Shopee API calls that failed in transport or returned a non-2xx status.", "endpoint")
+
+	RateLimiterWaits = NewCounterVec("shopee_rate_limiter_waits_total",
+		"Requests that had to wait for the Shopee rate limiter to refill.")
+	RateLimiterWaitDuration = NewHistogramVec("shopee_rate_limiter_wait_seconds",
+		"Time requests spent waiting for the Shopee rate limiter.", []float64{0.001, 0.01, 0.1, 1, 5, 15, 30, 60})
+
+	DBOpenConnections = NewGaugeVec("db_pool_open_connections",
+		"Open database connections by state.", "db", "state")
+	DBMaxOpenConnections = NewGaugeVec("db_pool_max_open_connections",
+		"Configured maximum number of open database connections; 0 is unlimited.", "db")
+	DBWaitCount = NewCounterVec("db_pool_wait_count_total",
+		"Connections waited for because the pool was exhausted.", "db")
+	DBWaitDuration = NewCounterVec("db_pool_wait_duration_seconds_total",
+		"Total time spent waiting for a database connection.", "db")
+	DBClosed = NewCounterVec("db_pool_closed_total",
+		"Connections closed by the pool, by reason.", "db", "reason")
+
+	CacheOps = NewCounterVec("cache_operations_total",
+		"Cache operations by cache and result (hit, miss, set, delete, eviction, error).", "cache", "result")
+	CacheHitRatio = NewGaugeVec("cache_hit_ratio",
+		"Share of cache reads that were hits.", "cache")
+
+	BatchQueueDepth = NewGaugeVec("batch_queue_depth",
+		"Batches waiting or running, by process type and status.", "process_type", "status")
+	BatchJobDuration = NewHistogramVec("batch_job_duration_seconds",
+		"Duration of finished batch jobs by process type and final status.",
+		[]float64{1, 5, 15, 30, 60, 300, 900, 1800, 3600, 7200, 21600}, "process_type", "status")
+
+	GoGoroutines = NewGaugeVec("go_goroutines", "Number of goroutines.")
+	GoMemAlloc   = NewGaugeVec("go_memstats_alloc_bytes", "Bytes of allocated heap objects.")
+	GoMemSys     = NewGaugeVec("go_memstats_sys_bytes", "Bytes of memory obtained from the OS.")
+	GoGCCount    = NewCounterVec("go_gc_cycles_total", "Completed GC cycles.")
+)
+
+func init() {
+	for _, m := range []Metric{
+		HTTPRequestDuration,
+		ShopeeRequests, ShopeeRequestDuration, ShopeeErrors,
+		RateLimiterWaits, RateLimiterWaitDuration,
+		DBOpenConnections, DBMaxOpenConnections, DBWaitCount, DBWaitDuration, DBClosed,
+		CacheOps, CacheHitRatio,
+		BatchQueueDepth, BatchJobDuration,
+		GoGoroutines, GoMemAlloc, GoMemSys, GoGCCount,
+	} {
+		Default.Register(m)
+	}
+	Default.AddCollector(collectRuntime)
+}
+
+func collectRuntime(context.Context) {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	GoGoroutines.Set(float64(runtime.NumGoroutine()))
+	GoMemAlloc.Set(float64(ms.Alloc))
+	GoMemSys.Set(float64(ms.Sys))
+	GoGCCount.Set(float64(ms.NumGC))
+}
+
+// WatchDB publishes the pool stats of db under the given name on every
+// scrape.
+func WatchDB(name string, db *sql.DB) {
+	Default.AddCollector(func(context.Context) {
+		s := db.Stats()
+		DBOpenConnections.Set(float64(s.InUse), name, "in_use")
+		DBOpenConnections.Set(float64(s.Idle), name, "idle")
+		DBMaxOpenConnections.Set(float64(s.MaxOpenConnections), name)
+		DBWaitCount.Set(float64(s.WaitCount), name)
+		DBWaitDuration.Set(s.WaitDuration.Seconds(), name)
+		DBClosed.Set(float64(s.MaxIdleClosed), name, "max_idle")
+		DBClosed.Set(float64(s.MaxIdleTimeClosed), name, "max_idle_time")
+		DBClosed.Set(float64(s.MaxLifetimeClosed), name, "max_lifetime")
+	})
+}
+
+// CacheStats is implemented by every cache.Cache.
+type CacheStats interface {
+	GetMetrics() cache.CacheMetrics
+}
+
+// WatchCache publishes the counters of c under the given name on every
+// scrape.
+func WatchCache(name string, c CacheStats) {
+	Default.AddCollector(func(context.Context) {
+		m := c.GetMetrics()
+		CacheOps.Set(float64(m.Hits), name, "hit")
+		CacheOps.Set(float64(m.Misses), name, "miss")
+		CacheOps.Set(float64(m.Sets), name, "set")
+		CacheOps.Set(float64(m.Deletes), name, "delete")
+		CacheOps.Set(float64(m.Evictions), name, "eviction")
+		CacheOps.Set(float64(m.Errors), name, "error")
+		ratio := 0.0
+		if reads := m.Hits + m.Misses; reads > 0 {
+			ratio = float64(m.Hits) / float64(reads)
+		}
+		CacheHitRatio.Set(ratio, name)
+	})
+}
@@ -0,0 +1,327 @@
+// Package metrics keeps counters, gauges and histograms in memory and
+// serves them in the Prometheus text exposition format (version 0.0.4).
+//
+// Metrics are registered once on a Registry and updated from anywhere with
+// label values in the order the metric declared them. Values that live
+// elsewhere, such as database pool stats, are copied in by collector funcs
+// that the Registry runs on every scrape.
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the media type of the text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets are latency buckets in seconds, from 5ms to 60s.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// Metric is a named family of series that can write itself out.
+type Metric interface {
+	Name() string
+	write(w io.Writer)
+}
+
+// CollectFunc refreshes metrics whose values are read from another source.
+// It runs at the start of every scrape.
+type CollectFunc func(ctx context.Context)
+
+// Registry holds the metrics served by one endpoint.
+type Registry struct {
+	mu         sync.Mutex
+	metrics    map[string]Metric
+	collectors []CollectFunc
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{metrics: make(map[string]Metric)}
+}
+
+// Default is the registry the application metrics are registered on.
+var Default = NewRegistry()
+
+// Register adds m to the registry. It panics when a metric with the same
+// name is already registered, since that is a programming error.
+func (r *Registry) Register(m Metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.metrics[m.Name()]; ok {
+		panic(fmt.Sprintf("metrics: %s registered twice", m.Name()))
+	}
+	r.metrics[m.Name()] = m
+}
+
+// AddCollector registers fn to run before every scrape.
+func (r *Registry) AddCollector(fn CollectFunc) {
+	r.mu.Lock()
+	r.collectors = append(r.collectors, fn)
+	r.mu.Unlock()
+}
+
+// WriteTo runs the collectors and writes every metric, sorted by name.
+func (r *Registry) WriteTo(ctx context.Context, w io.Writer) {
+	r.mu.Lock()
+	collectors := append([]CollectFunc(nil), r.collectors...)
+	list := make([]Metric, 0, len(r.metrics))
+	for _, m := range r.metrics {
+		list = append(list, m)
+	}
+	r.mu.Unlock()
+
+	for _, fn := range collectors {
+		fn(ctx)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name() < list[j].Name() })
+	for _, m := range list {
+		m.write(w)
+	}
+}
+
+// Handler serves the registry in the text exposition format.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		r.WriteTo(req.Context(), w)
+	})
+}
+
+// desc is the name, help text and label names shared by every metric type.
+type desc struct {
+	name   string
+	help   string
+	labels []string
+}
+
+func (d *desc) Name() string { return d.name }
+
+func (d *desc) header(w io.Writer, typ string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", d.name, escapeHelp(d.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", d.name, typ)
+}
+
+// key joins label values into a map key. It panics on a label count
+// mismatch, like a wrong number of format arguments would.
+func (d *desc) key(values []string) string {
+	if len(values) != len(d.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", d.name, len(d.labels), len(values)))
+	}
+	return strings.Join(values, "\xff")
+}
+
+// labelPairs renders {a="x",b="y"} for the series key, plus any extra pair.
+func (d *desc) labelPairs(key string, extra ...string) string {
+	var parts []string
+	if len(d.labels) > 0 {
+		for i, v := range strings.Split(key, "\xff") {
+			parts = append(parts, d.labels[i]+`="`+escapeLabel(v)+`"`)
+		}
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		parts = append(parts, extra[i]+`="`+escapeLabel(extra[i+1])+`"`)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// valueVec is a set of float series keyed by label values, shared by
+// counters and gauges.
+type valueVec struct {
+	desc
+	typ    string
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+func (v *valueVec) add(delta float64, labels []string) {
+	k := v.key(labels)
+	v.mu.Lock()
+	v.values[k] += delta
+	v.mu.Unlock()
+}
+
+func (v *valueVec) set(val float64, labels []string) {
+	k := v.key(labels)
+	v.mu.Lock()
+	v.values[k] = val
+	v.mu.Unlock()
+}
+
+func (v *valueVec) get(labels []string) float64 {
+	k := v.key(labels)
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.values[k]
+}
+
+func (v *valueVec) write(w io.Writer) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.header(w, v.typ)
+	for _, k := range sortedKeys(v.values) {
+		fmt.Fprintf(w, "%s%s %s\n", v.name, v.labelPairs(k), formatFloat(v.values[k]))
+	}
+}
+
+// CounterVec is a family of monotonically increasing counters.
+type CounterVec struct{ valueVec }
+
+// NewCounterVec returns a counter family with the given label names.
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{valueVec{desc: desc{name, help, labels}, typ: "counter", values: map[string]float64{}}}
+}
+
+// Inc adds one to the series for labels.
+func (c *CounterVec) Inc(labels ...string) { c.add(1, labels) }
+
+// Add adds delta, which must not be negative, to the series for labels.
+func (c *CounterVec) Add(delta float64, labels ...string) {
+	if delta < 0 {
+		return
+	}
+	c.add(delta, labels)
+}
+
+// Set copies a running total kept elsewhere, such as sql.DBStats.WaitCount.
+func (c *CounterVec) Set(val float64, labels ...string) { c.set(val, labels) }
+
+// Value returns the current value of the series for labels.
+func (c *CounterVec) Value(labels ...string) float64 { return c.get(labels) }
+
+// GaugeVec is a family of values that go up and down.
+type GaugeVec struct{ valueVec }
+
+// NewGaugeVec returns a gauge family with the given label names.
+func NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	return &GaugeVec{valueVec{desc: desc{name, help, labels}, typ: "gauge", values: map[string]float64{}}}
+}
+
+// Set sets the series for labels.
+func (g *GaugeVec) Set(val float64, labels ...string) { g.set(val, labels) }
+
+// Add adds delta to the series for labels.
+func (g *GaugeVec) Add(delta float64, labels ...string) { g.add(delta, labels) }
+
+// Value returns the current value of the series for labels.
+func (g *GaugeVec) Value(labels ...string) float64 { return g.get(labels) }
+
+// Reset drops every series, so a collector can republish only the label
+// combinations that still exist.
+func (g *GaugeVec) Reset() {
+	g.mu.Lock()
+	g.values = map[string]float64{}
+	g.mu.Unlock()
+}
+
+// HistogramVec is a family of histograms with shared bucket bounds.
+type HistogramVec struct {
+	desc
+	buckets []float64
+	mu      sync.Mutex
+	series  map[string]*histogram
+}
+
+type histogram struct {
+	counts []uint64 // per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+// NewHistogramVec returns a histogram family. Buckets are upper bounds in
+// increasing order; nil means DefaultBuckets.
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	return &HistogramVec{desc: desc{name, help, labels}, buckets: buckets, series: map[string]*histogram{}}
+}
+
+// Observe records v in the series for labels.
+func (h *HistogramVec) Observe(v float64, labels ...string) {
+	k := h.key(labels)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.series[k]
+	if !ok {
+		s = &histogram{counts: make([]uint64, len(h.buckets))}
+		h.series[k] = s
+	}
+	if i := sort.SearchFloat64s(h.buckets, v); i < len(h.buckets) {
+		s.counts[i]++
+	}
+	s.count++
+	s.sum += v
+}
+
+// Count returns how many observations the series for labels holds.
+func (h *HistogramVec) Count(labels ...string) uint64 {
+	k := h.key(labels)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if s, ok := h.series[k]; ok {
+		return s.count
+	}
+	return 0
+}
+
+func (h *HistogramVec) write(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.header(w, "histogram")
+	keys := make([]string, 0, len(h.series))
+	for k := range h.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		s := h.series[k]
+		var cum uint64
+		for i, b := range h.buckets {
+			cum += s.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.labelPairs(k, "le", formatFloat(b)), cum)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.labelPairs(k, "le", "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, h.labelPairs(k), formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, h.labelPairs(k), s.count)
+	}
+}
+
+func sortedKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
@@ -0,0 +1,109 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/cache"
+)
+
+func scrape(t *testing.T, r *Registry) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, ContentType, rec.Header().Get("Content-Type"))
+	return rec.Body.String()
+}
+
+func TestCounterAndGaugeExposition(t *testing.T) {
+	r := NewRegistry()
+	c := NewCounterVec("jobs_total", "Jobs run.", "type")
+	g := NewGaugeVec("queue_depth", "Queued jobs.")
+	r.Register(c)
+	r.Register(g)
+
+	c.Inc("import")
+	c.Add(2, "import")
+	c.Add(-5, "import") // ignored: counters never go down
+	c.Inc(`we"ird`)
+	g.Set(7)
+
+	out := scrape(t, r)
+	assert.Contains(t, out, "# HELP jobs_total Jobs run.\n# TYPE jobs_total counter\n")
+	assert.Contains(t, out, `jobs_total{type="import"} 3`+"\n")
+	assert.Contains(t, out, `jobs_total{type="we\"ird"} 1`+"\n")
+	assert.Contains(t, out, "# TYPE queue_depth gauge\nqueue_depth 7\n")
+	// Families are sorted by name.
+	assert.Less(t, strings.Index(out, "jobs_total"), strings.Index(out, "queue_depth"))
+}
+
+func TestHistogramExposition(t *testing.T) {
+	r := NewRegistry()
+	h := NewHistogramVec("latency_seconds", "Latency.", []float64{0.1, 1}, "route")
+	r.Register(h)
+
+	h.Observe(0.05, "/a")
+	h.Observe(0.1, "/a")
+	h.Observe(0.5, "/a")
+	h.Observe(3, "/a")
+
+	out := scrape(t, r)
+	assert.Contains(t, out, "# TYPE latency_seconds histogram\n")
+	assert.Contains(t, out, `latency_seconds_bucket{route="/a",le="0.1"} 2`+"\n")
+	assert.Contains(t, out, `latency_seconds_bucket{route="/a",le="1"} 3`+"\n")
+	assert.Contains(t, out, `latency_seconds_bucket{route="/a",le="+Inf"} 4`+"\n")
+	assert.Contains(t, out, `latency_seconds_sum{route="/a"} 3.65`+"\n")
+	assert.Contains(t, out, `latency_seconds_count{route="/a"} 4`+"\n")
+	assert.Equal(t, uint64(4), h.Count("/a"))
+}
+
+func TestCollectorsRunOnScrape(t *testing.T) {
+	r := NewRegistry()
+	g := NewGaugeVec("depth", "Depth.", "status")
+	r.Register(g)
+	calls := 0
+	r.AddCollector(func(context.Context) {
+		calls++
+		g.Reset()
+		if calls == 1 {
+			g.Set(1, "pending")
+		}
+		g.Set(float64(calls), "processing")
+	})
+
+	first := scrape(t, r)
+	assert.Contains(t, first, `depth{status="pending"} 1`)
+	second := scrape(t, r)
+	assert.NotContains(t, second, `status="pending"`)
+	assert.Contains(t, second, `depth{status="processing"} 2`)
+}
+
+func TestRegisterTwicePanics(t *testing.T) {
+	r := NewRegistry()
+	r.Register(NewGaugeVec("x", "X."))
+	assert.Panics(t, func() { r.Register(NewGaugeVec("x", "X.")) })
+}
+
+func TestWrongLabelCountPanics(t *testing.T) {
+	c := NewCounterVec("y_total", "Y.", "a", "b")
+	assert.Panics(t, func() { c.Inc("only-one") })
+}
+
+type stubCache struct{ m cache.CacheMetrics }
+
+func (s stubCache) GetMetrics() cache.CacheMetrics { return s.m }
+
+func TestWatchCachePublishesHitRatio(t *testing.T) {
+	WatchCache("test", stubCache{cache.CacheMetrics{Hits: 3, Misses: 1, Sets: 4}})
+	out := scrape(t, Default)
+	assert.Contains(t, out, `cache_hit_ratio{cache="test"} 0.75`)
+	assert.Contains(t, out, `cache_operations_total{cache="test",result="hit"} 3`)
+	assert.Contains(t, out, "go_goroutines ")
+}
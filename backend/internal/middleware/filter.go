@@ -1,10 +1,14 @@
 package middleware
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
@@ -12,8 +16,37 @@ import (
 
 const FilterParamsKey = "filter_params"
 
+// UserIDHeader identifies the user a request is made for.
+const UserIDHeader = "X-User-ID"
+
+// ViewResolver loads a saved filter view by ID or name for a user. It is
+// implemented by service.FilterViewService.
+type ViewResolver interface {
+	ResolveView(ctx context.Context, resource, ref, user string) (*models.SavedFilterView, error)
+}
+
+var viewResolver ViewResolver
+
+// SetViewResolver enables the view parameter of FilterMiddlewareFor.
+func SetViewResolver(r ViewResolver) {
+	viewResolver = r
+}
+
+// UserID returns the user a request is made for, from the X-User-ID header.
+func UserID(c *gin.Context) string {
+	return c.GetHeader(UserIDHeader)
+}
+
 // FilterMiddleware parses filter, sort, and pagination parameters from the request
 func FilterMiddleware() gin.HandlerFunc {
+	return FilterMiddlewareFor("")
+}
+
+// FilterMiddlewareFor parses filter, sort and pagination parameters for a
+// list of resource. A view parameter (a saved view's ID or name) applies
+// that view: its filters are combined with any sent in the request, and
+// its sort and page size are used unless the request sets them.
+func FilterMiddlewareFor(resource string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		params := &models.FilterParams{}
 
@@ -21,6 +54,7 @@ func FilterMiddleware() gin.HandlerFunc {
 		page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 		pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
 		params.Pagination = models.NewPaginationParams(page, pageSize)
+		params.Pagination.Cursor = c.Query("cursor")
 
 		// Parse filters
 		if filtersJSON := c.Query("filters"); filtersJSON != "" {
@@ -64,12 +98,22 @@ func FilterMiddleware() gin.HandlerFunc {
 			}
 		}
 
+		if ref := c.Query("view"); ref != "" {
+			if !applyView(c, resource, ref, params) {
+				c.Abort()
+				return
+			}
+		}
+
 		// Validate parameters
 		if err := params.Validate(); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid filter parameters: " + err.Error()})
 			c.Abort()
 			return
 		}
+		if params.Filters != nil {
+			params.Filters.ResolveRelativeDates(time.Now())
+		}
 
 		// Store in context
 		c.Set(FilterParamsKey, params)
@@ -77,6 +121,47 @@ func FilterMiddleware() gin.HandlerFunc {
 	}
 }
 
+// applyView merges the saved view ref into params. It writes the error
+// response and returns false when the view cannot be used.
+func applyView(c *gin.Context, resource, ref string, params *models.FilterParams) bool {
+	if viewResolver == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "saved filter views are not available"})
+		return false
+	}
+	view, err := viewResolver.ResolveView(c.Request.Context(), resource, ref, UserID(c))
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "filter view not found"})
+		return false
+	case errors.Is(err, models.ErrInvalidFilterView):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+
+	switch {
+	case view.Filters == nil:
+	case params.Filters == nil:
+		params.Filters = view.Filters
+	default:
+		params.Filters = &models.FilterGroup{
+			Logic:  "AND",
+			Groups: []models.FilterGroup{*view.Filters, *params.Filters},
+		}
+	}
+	if len(params.Sort) == 0 {
+		params.Sort = view.Sort
+	}
+	if c.Query("page_size") == "" && view.PageSize > 0 {
+		cursor := params.Pagination.Cursor
+		params.Pagination = models.NewPaginationParams(params.Pagination.Page, view.PageSize)
+		params.Pagination.Cursor = cursor
+	}
+	return true
+}
+
 // GetFilterParams retrieves filter parameters from the Gin context
 func GetFilterParams(c *gin.Context) *models.FilterParams {
 	if params, exists := c.Get(FilterParamsKey); exists {
@@ -203,7 +288,7 @@ func GetQuickFilters() []QuickFilter {
 					{
 						Field:    "created_at",
 						Operator: models.FilterOpGreaterEq,
-						Value:    "now() - interval '7 days'",
+						Value:    "@today-7d",
 					},
 				},
 			},
@@ -220,7 +305,7 @@ func GetQuickFilters() []QuickFilter {
 					{
 						Field:    "created_at",
 						Operator: models.FilterOpGreaterEq,
-						Value:    "date_trunc('month', current_date)",
+						Value:    "@month_start",
 					},
 				},
 			},
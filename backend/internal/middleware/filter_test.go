@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+)
+
+type fakeViewResolver struct{ user string }
+
+func (f *fakeViewResolver) ResolveView(ctx context.Context, resource, ref, user string) (*models.SavedFilterView, error) {
+	f.user = user
+	if ref != "Unsettled" {
+		return nil, sql.ErrNoRows
+	}
+	return &models.SavedFilterView{
+		Name:     ref,
+		Resource: resource,
+		Filters: &models.FilterGroup{Logic: "AND", Conditions: []models.FilterCondition{
+			{Field: "waktu_pesanan_terbuat", Operator: models.FilterOpLessThan, Value: "@today-14d"},
+		}},
+		Sort:     models.SortList{{Field: "waktu_pesanan_terbuat", Direction: "asc"}},
+		PageSize: 50,
+	}, nil
+}
+
+func serveFiltered(t *testing.T, query url.Values) (*httptest.ResponseRecorder, *models.FilterParams) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	var got *models.FilterParams
+	r := gin.New()
+	r.GET("/list", FilterMiddlewareFor(models.ResourceDropshipPurchases), func(c *gin.Context) {
+		got = GetFilterParams(c)
+		c.Status(http.StatusOK)
+	})
+	req := httptest.NewRequest(http.MethodGet, "/list?"+query.Encode(), nil)
+	req.Header.Set(UserIDHeader, "ana")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w, got
+}
+
+func TestFilterMiddlewareAppliesSavedView(t *testing.T) {
+	resolver := &fakeViewResolver{}
+	SetViewResolver(resolver)
+	defer SetViewResolver(nil)
+
+	q := url.Values{}
+	q.Set("view", "Unsettled")
+	q.Set("filters", `{"logic":"AND","conditions":[{"field":"nama_toko","operator":"eq","value":"ShopA"}]}`)
+	w, params := serveFiltered(t, q)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if resolver.user != "ana" {
+		t.Errorf("view resolved for %q", resolver.user)
+	}
+	if len(params.Filters.Groups) != 2 || params.Filters.Logic != "AND" {
+		t.Fatalf("view and request filters should be combined, got %+v", params.Filters)
+	}
+	want := time.Now().AddDate(0, 0, -14).Format("2006-01-02")
+	if v := params.Filters.Groups[0].Conditions[0].Value; v != want {
+		t.Errorf("relative date resolved to %v, want %s", v, want)
+	}
+	if params.Sort[0].Direction != "asc" || params.Pagination.PageSize != 50 {
+		t.Errorf("view sort and page size not applied: %+v %+v", params.Sort, params.Pagination)
+	}
+
+	q = url.Values{}
+	q.Set("view", "Missing")
+	if w, _ := serveFiltered(t, q); w.Code != http.StatusNotFound {
+		t.Errorf("unknown view: expected 404, got %d", w.Code)
+	}
+}
+
+func TestResolveRelativeDate(t *testing.T) {
+	now := time.Date(2025, 3, 19, 15, 4, 0, 0, time.UTC) // a Wednesday
+	cases := map[string]string{
+		"@today":          "2025-03-19",
+		"@today-14d":      "2025-03-05",
+		"@week_start":     "2025-03-17",
+		"@month_start":    "2025-03-01",
+		"@month_start-1m": "2025-02-01",
+		"@year_start+1y":  "2026-01-01",
+	}
+	for token, want := range cases {
+		got, ok := models.ResolveRelativeDate(token, now)
+		if !ok || got != want {
+			t.Errorf("%s: got %q %v, want %s", token, got, ok, want)
+		}
+	}
+	if _, ok := models.ResolveRelativeDate("2025-01-01", now); ok {
+		t.Error("plain dates are not tokens")
+	}
+}
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"context"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ramadhan22/dropship-erp/backend/internal/logutil"
@@ -45,37 +46,43 @@ func CorrelationIDMiddleware() gin.HandlerFunc {
 	}
 }
 
-// RequestLoggingMiddleware logs requests with structured logging
+// RequestLoggingMiddleware logs one structured entry per request once it
+// completes, at WARN for 4xx and ERROR for 5xx responses. It replaces gin's
+// plain-text access log.
 func RequestLoggingMiddleware() gin.HandlerFunc {
-	logger := logutil.NewLogger("http-middleware", logutil.INFO)
+	logger := logutil.NewLogger("http", logutil.INFO)
 
 	return func(c *gin.Context) {
-		// Record start time
-		startTime := c.Request.Context().Value("start_time")
-		if startTime == nil {
-			c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), "start_time", gin.H{"start": gin.H{"time": gin.H{"Now": func() gin.H { return gin.H{} }}}}))
-		}
-
-		// Log request start
-		logger.Info(c.Request.Context(), "RequestStart", "HTTP request started", map[string]interface{}{
-			"method":      c.Request.Method,
-			"path":        c.Request.URL.Path,
-			"query":       c.Request.URL.RawQuery,
-			"user_agent":  c.Request.UserAgent(),
-			"remote_addr": c.ClientIP(),
-		})
+		start := time.Now()
 
 		// Process request
 		c.Next()
 
-		// Log request completion
-		logger.Info(c.Request.Context(), "RequestComplete", "HTTP request completed", map[string]interface{}{
+		// Runs after the correlation and tracing middleware have put
+		// their IDs on the request context.
+		ctx := c.Request.Context()
+		status := c.Writer.Status()
+		fields := map[string]interface{}{
 			"method":      c.Request.Method,
 			"path":        c.Request.URL.Path,
-			"status":      c.Writer.Status(),
+			"route":       c.FullPath(),
+			"query":       c.Request.URL.RawQuery,
+			"status":      status,
 			"size":        c.Writer.Size(),
+			"duration_ms": time.Since(start).Milliseconds(),
 			"user_agent":  c.Request.UserAgent(),
 			"remote_addr": c.ClientIP(),
-		})
+		}
+		if len(c.Errors) > 0 {
+			fields["errors"] = c.Errors.String()
+		}
+		switch {
+		case status >= 500:
+			logger.Error(ctx, "Request", "HTTP request failed", nil, fields)
+		case status >= 400:
+			logger.Warn(ctx, "Request", "HTTP request rejected", fields)
+		default:
+			logger.Info(ctx, "Request", "HTTP request completed", fields)
+		}
 	}
 }
@@ -4,10 +4,13 @@ package middleware
 
 import (
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/metrics"
 )
 
 // MetricsData holds detailed metrics for monitoring
@@ -72,13 +75,30 @@ func MetricsMiddleware() gin.HandlerFunc {
 	}
 }
 
+// PrometheusMiddleware records every request in the
+// http_request_duration_seconds histogram, labelled by the route template
+// (e.g. /api/journal/:id) rather than the raw path so IDs don't multiply
+// the series.
+func PrometheusMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.HTTPRequestDuration.Observe(time.Since(start).Seconds(),
+			c.Request.Method, route, strconv.Itoa(c.Writer.Status()))
+	}
+}
+
 // GetAppMetrics returns current application metrics
-func GetAppMetrics() MetricsData {
+func GetAppMetrics() *MetricsData {
 	appMetrics.RLock()
 	defer appMetrics.RUnlock()
 
 	// Create a copy to avoid concurrent access issues
-	metrics := MetricsData{
+	metrics := &MetricsData{
 		StartTime:        appMetrics.StartTime,
 		TotalRequests:    appMetrics.TotalRequests,
 		RequestsByMethod: make(map[string]int64),
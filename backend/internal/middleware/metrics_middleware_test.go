@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ramadhan22/dropship-erp/backend/internal/metrics"
+)
+
+func TestPrometheusMiddlewareLabelsByRouteTemplate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(PrometheusMiddleware())
+	r.GET("/api/journal/:id", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	before := metrics.HTTPRequestDuration.Count("GET", "/api/journal/:id", "204")
+	for _, path := range []string{"/api/journal/1", "/api/journal/2", "/nowhere"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	if got := metrics.HTTPRequestDuration.Count("GET", "/api/journal/:id", "204") - before; got != 2 {
+		t.Fatalf("expected 2 observations for the route template, got %d", got)
+	}
+	if metrics.HTTPRequestDuration.Count("GET", "unmatched", "404") == 0 {
+		t.Fatalf("expected unmatched request to be recorded")
+	}
+}
@@ -4,13 +4,16 @@ package middleware
 
 import (
 	"fmt"
-	"log"
 	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/logutil"
 )
 
+var perfLog = logutil.NewLogger("performance-middleware", logutil.INFO)
+
 // PerformanceMetrics holds performance monitoring data
 type PerformanceMetrics struct {
 	RequestCount       int64
@@ -40,8 +43,12 @@ func PerformanceMiddleware() gin.HandlerFunc {
 		// Check for slow queries
 		if responseTime > globalMetrics.SlowQueryThreshold {
 			atomic.AddInt64(&globalMetrics.SlowQueryCount, 1)
-			log.Printf("SLOW QUERY: %s %s took %v (threshold: %v)",
-				params.Method, params.Path, responseTime, globalMetrics.SlowQueryThreshold)
+			perfLog.Warn(params.Request.Context(), "SlowRequest", "Slow request", map[string]interface{}{
+				"method":       params.Method,
+				"path":         params.Path,
+				"duration_ms":  responseTime.Milliseconds(),
+				"threshold_ms": globalMetrics.SlowQueryThreshold.Milliseconds(),
+			})
 		}
 
 		// Count errors
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ramadhan22/dropship-erp/backend/internal/tracing"
+)
+
+// TraceIDHeader carries the trace ID of a request back to the caller so a
+// slow response can be looked up in the tracing backend.
+const TraceIDHeader = "X-Trace-ID"
+
+// TracingMiddleware starts a server span for each request, named after the
+// route template. A W3C traceparent header continues the caller's trace.
+func TracingMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		if sc, ok := tracing.ParseTraceparent(c.GetHeader("traceparent")); ok {
+			ctx = tracing.ContextWithRemote(ctx, sc)
+		}
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		ctx, span := tracing.Start(ctx, c.Request.Method+" "+route, tracing.KindServer)
+		defer span.End()
+		span.SetAttr("http.method", c.Request.Method)
+		span.SetAttr("http.route", route)
+		span.SetAttr("http.target", c.Request.URL.RequestURI())
+		span.SetAttr("http.client_ip", c.ClientIP())
+
+		c.Header(TraceIDHeader, span.SpanContext().TraceID.String())
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttr("http.status_code", status)
+		if status >= http.StatusInternalServerError {
+			span.RecordError(fmt.Errorf("HTTP %d", status))
+		}
+		if len(c.Errors) > 0 {
+			span.SetAttr("gin.errors", c.Errors.String())
+		}
+	}
+}
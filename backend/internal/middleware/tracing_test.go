@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ramadhan22/dropship-erp/backend/internal/logutil"
+	"github.com/ramadhan22/dropship-erp/backend/internal/tracing"
+)
+
+func TestTracingMiddlewareContinuesCallerTrace(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(TracingMiddleware(), CorrelationIDMiddleware())
+	var traceID, correlationID string
+	r.GET("/api/reconcile/batches/:id", func(c *gin.Context) {
+		traceID = tracing.TraceIDFromContext(c.Request.Context())
+		correlationID = logutil.GetCorrelationID(c.Request.Context())
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/reconcile/batches/7", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	req.Header.Set("X-Correlation-ID", "abc")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if traceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("handler saw trace %q", traceID)
+	}
+	if got := rec.Header().Get(TraceIDHeader); got != traceID {
+		t.Fatalf("response trace header %q", got)
+	}
+	if correlationID != "abc" || rec.Header().Get("X-Correlation-ID") != "abc" {
+		t.Fatalf("correlation ID not propagated: %q", correlationID)
+	}
+}
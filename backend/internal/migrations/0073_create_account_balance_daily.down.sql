@@ -0,0 +1,8 @@
+DROP TRIGGER IF EXISTS trg_journal_entries_balance_update ON journal_entries;
+DROP TRIGGER IF EXISTS trg_journal_entries_balance_delete ON journal_entries;
+DROP TRIGGER IF EXISTS trg_journal_lines_balance_sync ON journal_lines;
+DROP FUNCTION IF EXISTS rebuild_account_balance_daily();
+DROP FUNCTION IF EXISTS journal_entries_balance_sync();
+DROP FUNCTION IF EXISTS journal_lines_balance_sync();
+DROP FUNCTION IF EXISTS apply_account_balance_delta(INT, VARCHAR, DATE, NUMERIC, NUMERIC);
+DROP TABLE IF EXISTS account_balance_daily;
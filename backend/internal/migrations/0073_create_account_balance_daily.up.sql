@@ -0,0 +1,129 @@
+-- Daily per-account, per-store movements. Balance queries sum these rows
+-- instead of scanning every journal line. The triggers below keep the table
+-- in step with journal_lines inside the same transaction as the write.
+CREATE TABLE IF NOT EXISTS account_balance_daily (
+  account_id    INT NOT NULL,
+  shop_username VARCHAR(100) NOT NULL,
+  balance_date  DATE NOT NULL,
+  debit_total   NUMERIC(16,2) NOT NULL DEFAULT 0,
+  credit_total  NUMERIC(16,2) NOT NULL DEFAULT 0,
+  PRIMARY KEY (account_id, shop_username, balance_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_balance_daily_shop_date
+  ON account_balance_daily (shop_username, balance_date);
+
+CREATE OR REPLACE FUNCTION apply_account_balance_delta(
+  p_account_id INT, p_shop VARCHAR, p_date DATE, p_debit NUMERIC, p_credit NUMERIC
+) RETURNS VOID AS $$
+BEGIN
+  IF p_debit = 0 AND p_credit = 0 THEN
+    RETURN;
+  END IF;
+  INSERT INTO account_balance_daily AS d
+    (account_id, shop_username, balance_date, debit_total, credit_total)
+  VALUES (p_account_id, p_shop, p_date, p_debit, p_credit)
+  ON CONFLICT (account_id, shop_username, balance_date) DO UPDATE
+    SET debit_total  = d.debit_total + EXCLUDED.debit_total,
+        credit_total = d.credit_total + EXCLUDED.credit_total;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Keeps account_balance_daily in step with single line inserts, updates and
+-- deletes. Lines removed by the ON DELETE CASCADE from journal_entries are
+-- skipped here because journal_entries_balance_delete already subtracted them.
+CREATE OR REPLACE FUNCTION journal_lines_balance_sync() RETURNS TRIGGER AS $$
+DECLARE
+  v_date DATE;
+  v_shop VARCHAR;
+BEGIN
+  IF TG_OP IN ('UPDATE', 'DELETE') THEN
+    SELECT entry_date, shop_username INTO v_date, v_shop
+      FROM journal_entries WHERE journal_id = OLD.journal_id;
+    IF FOUND THEN
+      PERFORM apply_account_balance_delta(OLD.account_id, v_shop, v_date,
+        CASE WHEN OLD.is_debit THEN -OLD.amount ELSE 0 END,
+        CASE WHEN OLD.is_debit THEN 0 ELSE -OLD.amount END);
+    END IF;
+  END IF;
+  IF TG_OP IN ('INSERT', 'UPDATE') THEN
+    SELECT entry_date, shop_username INTO v_date, v_shop
+      FROM journal_entries WHERE journal_id = NEW.journal_id;
+    IF FOUND THEN
+      PERFORM apply_account_balance_delta(NEW.account_id, v_shop, v_date,
+        CASE WHEN NEW.is_debit THEN NEW.amount ELSE 0 END,
+        CASE WHEN NEW.is_debit THEN 0 ELSE NEW.amount END);
+    END IF;
+  END IF;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_journal_lines_balance_sync ON journal_lines;
+CREATE TRIGGER trg_journal_lines_balance_sync
+  AFTER INSERT OR UPDATE OR DELETE ON journal_lines
+  FOR EACH ROW EXECUTE FUNCTION journal_lines_balance_sync();
+
+-- Subtracts every line of an entry before it is deleted, and moves the
+-- amounts when an entry's date or shop changes.
+CREATE OR REPLACE FUNCTION journal_entries_balance_sync() RETURNS TRIGGER AS $$
+DECLARE
+  l RECORD;
+BEGIN
+  IF TG_OP = 'UPDATE' AND NEW.entry_date = OLD.entry_date
+     AND NEW.shop_username = OLD.shop_username THEN
+    RETURN NEW;
+  END IF;
+  FOR l IN
+    SELECT account_id,
+           SUM(CASE WHEN is_debit THEN amount ELSE 0 END) AS debit,
+           SUM(CASE WHEN is_debit THEN 0 ELSE amount END) AS credit
+      FROM journal_lines WHERE journal_id = OLD.journal_id
+     GROUP BY account_id
+  LOOP
+    PERFORM apply_account_balance_delta(l.account_id, OLD.shop_username,
+      OLD.entry_date, -l.debit, -l.credit);
+    IF TG_OP = 'UPDATE' THEN
+      PERFORM apply_account_balance_delta(l.account_id, NEW.shop_username,
+        NEW.entry_date, l.debit, l.credit);
+    END IF;
+  END LOOP;
+  IF TG_OP = 'DELETE' THEN
+    RETURN OLD;
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_journal_entries_balance_delete ON journal_entries;
+CREATE TRIGGER trg_journal_entries_balance_delete
+  BEFORE DELETE ON journal_entries
+  FOR EACH ROW EXECUTE FUNCTION journal_entries_balance_sync();
+
+DROP TRIGGER IF EXISTS trg_journal_entries_balance_update ON journal_entries;
+CREATE TRIGGER trg_journal_entries_balance_update
+  AFTER UPDATE OF entry_date, shop_username ON journal_entries
+  FOR EACH ROW EXECUTE FUNCTION journal_entries_balance_sync();
+
+-- Regenerates the table from journal_lines. Used by the rebuild-balances
+-- command after bulk fixes made with the triggers disabled.
+CREATE OR REPLACE FUNCTION rebuild_account_balance_daily() RETURNS BIGINT AS $$
+DECLARE
+  n BIGINT;
+BEGIN
+  LOCK TABLE journal_lines IN SHARE MODE;
+  DELETE FROM account_balance_daily;
+  INSERT INTO account_balance_daily
+    (account_id, shop_username, balance_date, debit_total, credit_total)
+  SELECT jl.account_id, je.shop_username, je.entry_date,
+         SUM(CASE WHEN jl.is_debit THEN jl.amount ELSE 0 END),
+         SUM(CASE WHEN jl.is_debit THEN 0 ELSE jl.amount END)
+    FROM journal_lines jl
+    JOIN journal_entries je ON je.journal_id = jl.journal_id
+   GROUP BY jl.account_id, je.shop_username, je.entry_date;
+  GET DIAGNOSTICS n = ROW_COUNT;
+  RETURN n;
+END;
+$$ LANGUAGE plpgsql;
+
+SELECT rebuild_account_balance_daily();
@@ -0,0 +1,43 @@
+CREATE TABLE IF NOT EXISTS report_subscriptions (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    report VARCHAR(64) NOT NULL,
+    format VARCHAR(8) NOT NULL DEFAULT 'xlsx',
+    store VARCHAR(100) NOT NULL DEFAULT '',
+    channel VARCHAR(100) NOT NULL DEFAULT '',
+    period VARCHAR(32) NOT NULL DEFAULT 'previous_month', -- relative to the run time
+    comparison BOOLEAN NOT NULL DEFAULT FALSE,
+    schedule VARCHAR(100) NOT NULL,                       -- cron expression
+    sink_type VARCHAR(16) NOT NULL,                       -- 'email', 'webhook' or 'directory'
+    sink_target TEXT NOT NULL,                            -- address list, URL or sub-directory
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    next_run_at TIMESTAMPTZ,
+    last_run_at TIMESTAMPTZ,
+    claimed_until TIMESTAMPTZ,                            -- held by a run until then
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_subscriptions_due
+    ON report_subscriptions(next_run_at) WHERE enabled;
+
+CREATE TABLE IF NOT EXISTS report_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id BIGINT NOT NULL REFERENCES report_subscriptions(id) ON DELETE CASCADE,
+    scheduled_for TIMESTAMPTZ NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',        -- 'pending', 'sent', 'retrying', 'failed'
+    attempts INT NOT NULL DEFAULT 0,
+    file_name TEXT,
+    last_error TEXT,
+    next_retry_at TIMESTAMPTZ,
+    delivered_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_deliveries_subscription
+    ON report_deliveries(subscription_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_report_deliveries_retry
+    ON report_deliveries(next_retry_at) WHERE status = 'retrying';
+CREATE INDEX IF NOT EXISTS idx_report_deliveries_pending
+    ON report_deliveries(updated_at) WHERE status = 'pending';
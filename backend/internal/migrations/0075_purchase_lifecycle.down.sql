@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS purchase_status_history;
+DROP INDEX IF EXISTS idx_dropship_purchases_lifecycle;
+ALTER TABLE dropship_purchases DROP COLUMN IF EXISTS lifecycle_status;
@@ -0,0 +1,38 @@
+ALTER TABLE dropship_purchases
+    ADD COLUMN IF NOT EXISTS lifecycle_status VARCHAR(32) NOT NULL DEFAULT 'imported';
+
+-- Derive the lifecycle state of existing purchases from their display status
+-- and the journals already posted for them.
+UPDATE dropship_purchases dp SET lifecycle_status = CASE
+        WHEN dp.status_pesanan_terakhir = 'Pesanan selesai' THEN 'settled'
+        WHEN dp.status_pesanan_terakhir IN ('Pesanan dibatalkan', 'Cancelled Shopee') THEN 'cancelled'
+        WHEN dp.status_pesanan_terakhir = 'Pesanan dikembalikan' THEN 'returned'
+        WHEN dp.status_pesanan_terakhir = 'Sebagian dikembalikan' THEN 'partially_returned'
+        WHEN EXISTS (
+            SELECT 1 FROM journal_entries je
+            WHERE je.source_type = 'pending_sales' AND je.source_id = dp.kode_invoice_channel
+        ) THEN 'pending_sale'
+        ELSE 'imported'
+    END;
+
+CREATE INDEX IF NOT EXISTS idx_dropship_purchases_lifecycle
+    ON dropship_purchases(lifecycle_status);
+
+CREATE TABLE IF NOT EXISTS purchase_status_history (
+    id BIGSERIAL PRIMARY KEY,
+    kode_pesanan TEXT NOT NULL REFERENCES dropship_purchases(kode_pesanan) ON DELETE CASCADE,
+    from_status VARCHAR(32),                     -- NULL for the initial state
+    to_status VARCHAR(32) NOT NULL,
+    label TEXT NOT NULL DEFAULT '',              -- status_pesanan_terakhir after the change
+    cause VARCHAR(64) NOT NULL,
+    note TEXT,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(), -- when the event happened
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_purchase_status_history_order
+    ON purchase_status_history(kode_pesanan, changed_at, id);
+
+INSERT INTO purchase_status_history (kode_pesanan, from_status, to_status, label, cause, changed_at)
+SELECT kode_pesanan, NULL, lifecycle_status, status_pesanan_terakhir, 'backfill', COALESCE(waktu_pesanan_terbuat, NOW())
+FROM dropship_purchases;
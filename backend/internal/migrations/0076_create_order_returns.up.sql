@@ -0,0 +1,47 @@
+CREATE TABLE IF NOT EXISTS order_returns (
+    id BIGSERIAL PRIMARY KEY,
+    return_sn VARCHAR(64) NOT NULL UNIQUE,
+    order_sn VARCHAR(64) NOT NULL,
+    store VARCHAR(100) NOT NULL,
+    kode_pesanan TEXT REFERENCES dropship_purchases(kode_pesanan) ON DELETE SET NULL,
+    status VARCHAR(64) NOT NULL DEFAULT '',
+    negotiation_status VARCHAR(64) NOT NULL DEFAULT '',
+    seller_proof_status VARCHAR(64) NOT NULL DEFAULT '',
+    seller_compensation_status VARCHAR(64) NOT NULL DEFAULT '',
+    reason TEXT NOT NULL DEFAULT '',
+    refund_amount NUMERIC(18,2) NOT NULL DEFAULT 0,     -- refunded to the buyer by Shopee
+    currency VARCHAR(8) NOT NULL DEFAULT 'IDR',
+    buyer_username VARCHAR(255) NOT NULL DEFAULT '',
+    image_urls TEXT[] NOT NULL DEFAULT '{}',             -- buyer evidence
+    seller_proof_urls TEXT[] NOT NULL DEFAULT '{}',
+    return_created_at TIMESTAMPTZ NOT NULL,
+    return_updated_at TIMESTAMPTZ NOT NULL,
+    due_date TIMESTAMPTZ,
+    supplier_refund_status VARCHAR(16) NOT NULL DEFAULT 'pending', -- 'pending', 'refunded', 'rejected'
+    supplier_refund_amount NUMERIC(18,2) NOT NULL DEFAULT 0,
+    supplier_refunded_at TIMESTAMPTZ,
+    supplier_refund_note TEXT,
+    supplier_journal_id BIGINT REFERENCES journal_entries(journal_id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_returns_order ON order_returns(order_sn);
+CREATE INDEX IF NOT EXISTS idx_order_returns_store_created ON order_returns(store, return_created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_order_returns_supplier_status ON order_returns(supplier_refund_status);
+
+CREATE TABLE IF NOT EXISTS order_return_items (
+    id BIGSERIAL PRIMARY KEY,
+    return_id BIGINT NOT NULL REFERENCES order_returns(id) ON DELETE CASCADE,
+    item_id BIGINT NOT NULL DEFAULT 0,
+    model_id BIGINT NOT NULL DEFAULT 0,
+    item_name TEXT NOT NULL DEFAULT '',
+    item_sku VARCHAR(255) NOT NULL DEFAULT '',
+    model_name TEXT NOT NULL DEFAULT '',
+    model_sku VARCHAR(255) NOT NULL DEFAULT '',
+    qty INT NOT NULL DEFAULT 0,
+    item_price NUMERIC(18,2) NOT NULL DEFAULT 0,
+    is_main_item BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_return_items_return ON order_return_items(return_id);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS reconciliation_policies;
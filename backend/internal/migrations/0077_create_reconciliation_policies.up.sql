@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS reconciliation_policies (
+    store VARCHAR(100) PRIMARY KEY,                         -- nama_toko
+    max_allowed_failures INT NOT NULL DEFAULT 100,          -- halt a run after this many failures, 0 = no limit
+    failure_threshold_percent NUMERIC(5,2) NOT NULL DEFAULT 5,
+    critical_error_types TEXT[] NOT NULL DEFAULT '{}',      -- failure categories that halt a run
+    retry_failed_transactions BOOLEAN NOT NULL DEFAULT FALSE,
+    generate_detailed_report BOOLEAN NOT NULL DEFAULT TRUE,
+    amount_tolerance NUMERIC(15,2) NOT NULL DEFAULT 0,      -- escrow difference absorbed as selisih
+    shipping_discrepancy_threshold NUMERIC(15,2) NOT NULL DEFAULT 0.01, -- larger differences are recorded
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,14 @@
+DROP INDEX IF EXISTS idx_failed_reconciliations_due;
+ALTER TABLE failed_reconciliations
+    ADD COLUMN retried BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN retried_at TIMESTAMP;
+UPDATE failed_reconciliations SET retried = TRUE, retried_at = last_attempt_at WHERE attempts > 0;
+CREATE INDEX idx_failed_reconciliations_retried ON failed_reconciliations(retried);
+DROP TABLE IF EXISTS failed_reconciliation_attempts;
+ALTER TABLE failed_reconciliations
+    DROP COLUMN status,
+    DROP COLUMN attempts,
+    DROP COLUMN max_attempts,
+    DROP COLUMN next_retry_at,
+    DROP COLUMN last_attempt_at,
+    DROP COLUMN resolved_at;
@@ -0,0 +1,49 @@
+-- Failed reconciliations are retried in the background with exponential
+-- backoff. Each row carries its own attempt budget; rows that run out of
+-- attempts or fail permanently move to the needs_attention queue.
+ALTER TABLE failed_reconciliations
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, resolved, needs_attention
+    ADD COLUMN attempts INT NOT NULL DEFAULT 0,
+    ADD COLUMN max_attempts INT NOT NULL DEFAULT 5,
+    ADD COLUMN next_retry_at TIMESTAMP,
+    ADD COLUMN last_attempt_at TIMESTAMP,
+    ADD COLUMN resolved_at TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS failed_reconciliation_attempts (
+    id BIGSERIAL PRIMARY KEY,
+    failed_reconciliation_id BIGINT NOT NULL REFERENCES failed_reconciliations(id) ON DELETE CASCADE,
+    attempt INT NOT NULL,
+    succeeded BOOLEAN NOT NULL,
+    error_type VARCHAR(100),
+    error_message TEXT,
+    attempted_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_failed_reconciliation_attempts_failed_id ON failed_reconciliation_attempts(failed_reconciliation_id);
+
+-- The old manual retry marked a row retried whatever the outcome and
+-- re-recorded a failed retry as a new row for the same purchase and order.
+-- A retried row only succeeded when no such later failure exists; one whose
+-- retry failed goes to needs_attention, its failure carried on by the newer
+-- row.
+UPDATE failed_reconciliations f
+   SET status = CASE WHEN EXISTS (
+                    SELECT 1 FROM failed_reconciliations n
+                     WHERE n.id <> f.id
+                       AND n.purchase_id = f.purchase_id
+                       AND n.shop = f.shop
+                       AND n.order_id IS NOT DISTINCT FROM f.order_id
+                       AND n.failed_at >= COALESCE(f.retried_at, f.failed_at))
+                    THEN 'needs_attention' ELSE 'resolved' END,
+       attempts = 1,
+       last_attempt_at = COALESCE(retried_at, failed_at)
+ WHERE retried;
+UPDATE failed_reconciliations SET resolved_at = last_attempt_at WHERE retried AND status = 'resolved';
+INSERT INTO failed_reconciliation_attempts (failed_reconciliation_id, attempt, succeeded, attempted_at)
+SELECT id, 1, status = 'resolved', last_attempt_at FROM failed_reconciliations WHERE retried;
+UPDATE failed_reconciliations SET next_retry_at = failed_at WHERE NOT retried;
+
+DROP INDEX IF EXISTS idx_failed_reconciliations_retried;
+ALTER TABLE failed_reconciliations DROP COLUMN retried, DROP COLUMN retried_at;
+
+CREATE INDEX IF NOT EXISTS idx_failed_reconciliations_due ON failed_reconciliations(status, next_retry_at);
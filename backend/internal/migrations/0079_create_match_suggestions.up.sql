@@ -0,0 +1,21 @@
+-- Proposed pairings of dropship purchases and Shopee settlements whose
+-- invoices do not match exactly. Rejected pairs are kept so they are not
+-- proposed again.
+CREATE TABLE IF NOT EXISTS match_suggestions (
+    id BIGSERIAL PRIMARY KEY,
+    store VARCHAR(100) NOT NULL,                    -- nama_toko of the purchase
+    kode_pesanan VARCHAR(100) NOT NULL,             -- dropship_purchases
+    kode_invoice_channel VARCHAR(100) NOT NULL,
+    no_pesanan VARCHAR(100) NOT NULL,               -- shopee_settled
+    confidence NUMERIC(5,4) NOT NULL,
+    invoice_score NUMERIC(5,4) NOT NULL,
+    store_score NUMERIC(5,4) NOT NULL,
+    date_score NUMERIC(5,4) NOT NULL,
+    amount_score NUMERIC(5,4) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',  -- pending, accepted, rejected
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    decided_at TIMESTAMPTZ,
+    UNIQUE (kode_pesanan, no_pesanan)
+);
+
+CREATE INDEX IF NOT EXISTS idx_match_suggestions_store_status ON match_suggestions(store, status);
@@ -0,0 +1,2 @@
+DELETE FROM accounts WHERE account_code='2.1.9';
+DROP TABLE IF EXISTS ledger_repairs;
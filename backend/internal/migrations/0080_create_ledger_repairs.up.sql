@@ -0,0 +1,24 @@
+-- Suspense account that absorbs the difference when an unbalanced journal
+-- is repaired, so the gap stays visible until someone books it properly.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM accounts WHERE account_code='2.1.9') THEN
+        INSERT INTO accounts (account_id, account_code, account_name, account_type, parent_id)
+        VALUES (21009, '2.1.9', 'Suspense Selisih Jurnal', 'Liability',
+            (SELECT account_id FROM accounts WHERE account_code='2.1'));
+    END IF;
+END $$;
+
+-- Audit trail of repairs made by the ledger integrity checker.
+CREATE TABLE IF NOT EXISTS ledger_repairs (
+    id BIGSERIAL PRIMARY KEY,
+    check_name VARCHAR(50) NOT NULL,
+    target VARCHAR(100) NOT NULL,       -- journal id or invoice the issue was reported for
+    action VARCHAR(50) NOT NULL,
+    detail TEXT NOT NULL DEFAULT '',
+    journal_id BIGINT,                  -- journal posted or changed by the repair
+    performed_by VARCHAR(100) NOT NULL,
+    performed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_repairs_target ON ledger_repairs(check_name, target);
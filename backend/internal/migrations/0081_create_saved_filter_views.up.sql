@@ -0,0 +1,18 @@
+-- Filter views saved by operators for the /filtered list endpoints. A view
+-- belongs to the user who created it; shared views are visible to everyone.
+CREATE TABLE IF NOT EXISTS saved_filter_views (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    resource VARCHAR(50) NOT NULL,      -- list the view applies to, e.g. shopee_settled
+    owner VARCHAR(100) NOT NULL,        -- X-User-ID of the creator
+    shared BOOLEAN NOT NULL DEFAULT FALSE,
+    description TEXT NOT NULL DEFAULT '',
+    filters JSONB,                      -- models.FilterGroup
+    sort JSONB NOT NULL DEFAULT '[]',   -- []models.SortCondition
+    page_size INT NOT NULL DEFAULT 0,   -- 0 keeps the request's page size
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (owner, resource, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_saved_filter_views_resource ON saved_filter_views(resource, shared);
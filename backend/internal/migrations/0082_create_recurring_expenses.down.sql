@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS recurring_expense_recognitions;
+DROP TABLE IF EXISTS recurring_expense_runs;
+DROP TABLE IF EXISTS recurring_expense_lines;
+DROP TABLE IF EXISTS recurring_expenses;
+ALTER TABLE expenses DROP COLUMN IF EXISTS store;
@@ -0,0 +1,66 @@
+-- Expenses can be booked against a store so they show up in its P&L.
+ALTER TABLE expenses ADD COLUMN IF NOT EXISTS store VARCHAR(100) NOT NULL DEFAULT '';
+
+-- Templates for expenses that repeat on a schedule, such as rent, SaaS
+-- subscriptions and salaries. The scheduler materialises each due run into
+-- an expense (cash, prepaid) or an accrual journal (accrued).
+CREATE TABLE IF NOT EXISTS recurring_expenses (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    schedule VARCHAR(100) NOT NULL,          -- cron expression, e.g. "0 0 1 * *"
+    asset_account_id BIGINT NOT NULL,        -- account the payment is made from
+    store VARCHAR(100) NOT NULL DEFAULT '',
+    mode VARCHAR(20) NOT NULL DEFAULT 'cash', -- cash, prepaid or accrued
+    deferral_account_id BIGINT,              -- prepaid asset or accrued liability
+    spread_months INT NOT NULL DEFAULT 0,    -- months a prepaid payment is spread over
+    start_date TIMESTAMPTZ NOT NULL,
+    end_date TIMESTAMPTZ,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    next_run_at TIMESTAMPTZ,
+    last_run_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_recurring_expenses_due
+    ON recurring_expenses(next_run_at) WHERE enabled;
+
+CREATE TABLE IF NOT EXISTS recurring_expense_lines (
+    id BIGSERIAL PRIMARY KEY,
+    recurring_expense_id BIGINT NOT NULL REFERENCES recurring_expenses(id) ON DELETE CASCADE,
+    account_id BIGINT NOT NULL,
+    amount NUMERIC NOT NULL
+);
+
+-- One row per materialised occurrence. The unique key stops a restarted
+-- scheduler from posting the same period twice.
+CREATE TABLE IF NOT EXISTS recurring_expense_runs (
+    id BIGSERIAL PRIMARY KEY,
+    recurring_expense_id BIGINT NOT NULL REFERENCES recurring_expenses(id) ON DELETE CASCADE,
+    scheduled_for TIMESTAMPTZ NOT NULL,
+    mode VARCHAR(20) NOT NULL,
+    amount NUMERIC NOT NULL,
+    expense_id UUID,                         -- cash and prepaid runs
+    journal_id BIGINT,                       -- accrued runs
+    settled_expense_id UUID,                 -- accrued runs once paid
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (recurring_expense_id, scheduled_for)
+);
+
+-- Monthly slices of prepaid runs, moved from the prepaid account to the
+-- expense accounts as they fall due.
+CREATE TABLE IF NOT EXISTS recurring_expense_recognitions (
+    id BIGSERIAL PRIMARY KEY,
+    run_id BIGINT NOT NULL REFERENCES recurring_expense_runs(id) ON DELETE CASCADE,
+    due_date TIMESTAMPTZ NOT NULL,
+    account_id BIGINT NOT NULL,              -- expense account debited
+    deferral_account_id BIGINT NOT NULL,     -- prepaid account credited
+    store VARCHAR(100) NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    amount NUMERIC NOT NULL,
+    journal_id BIGINT
+);
+
+CREATE INDEX IF NOT EXISTS idx_recurring_expense_recognitions_due
+    ON recurring_expense_recognitions(due_date) WHERE journal_id IS NULL;
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS expense_allocation_lines;
+DROP TABLE IF EXISTS expense_allocations;
+ALTER TABLE expenses DROP COLUMN IF EXISTS allocation_rule_id;
+DROP TABLE IF EXISTS expense_allocation_rule_stores;
+DROP TABLE IF EXISTS expense_allocation_rules;
+DELETE FROM accounts WHERE account_code='2.1.8';
@@ -0,0 +1,60 @@
+-- Clearing account between the company-level overhead pool and the stores
+-- it is allocated to. Its balance nets to zero across all stores.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM accounts WHERE account_code='2.1.8') THEN
+        INSERT INTO accounts (account_id, account_code, account_name, account_type, parent_id)
+        VALUES (21008, '2.1.8', 'Alokasi Beban Antar Toko', 'Liability',
+            (SELECT account_id FROM accounts WHERE account_code='2.1'));
+    END IF;
+END $$;
+
+-- Rules that split an overhead expense across stores.
+CREATE TABLE IF NOT EXISTS expense_allocation_rules (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    method VARCHAR(20) NOT NULL,            -- fixed, revenue or orders
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Stores of a rule. Fixed rules split by weight; revenue and order rules
+-- only consider the listed stores, or every store when none are listed.
+CREATE TABLE IF NOT EXISTS expense_allocation_rule_stores (
+    rule_id BIGINT NOT NULL REFERENCES expense_allocation_rules(id) ON DELETE RESTRICT,
+    store VARCHAR(100) NOT NULL,
+    weight NUMERIC NOT NULL DEFAULT 0,
+    PRIMARY KEY (rule_id, store)
+);
+
+ALTER TABLE expenses ADD COLUMN IF NOT EXISTS allocation_rule_id BIGINT;
+
+-- One allocation per expense while it is active. Reversed allocations are
+-- kept for the audit trail, so a rule that has allocations cannot be
+-- deleted.
+CREATE TABLE IF NOT EXISTS expense_allocations (
+    id BIGSERIAL PRIMARY KEY,
+    expense_id UUID NOT NULL,
+    rule_id BIGINT NOT NULL REFERENCES expense_allocation_rules(id) ON DELETE RESTRICT,
+    method VARCHAR(20) NOT NULL,
+    basis_from DATE NOT NULL,               -- period the revenue/order shares came from
+    basis_to DATE NOT NULL,
+    total NUMERIC NOT NULL,
+    pool_journal_id BIGINT,
+    reversed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_expense_allocations_active
+    ON expense_allocations(expense_id) WHERE reversed_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS expense_allocation_lines (
+    id BIGSERIAL PRIMARY KEY,
+    allocation_id BIGINT NOT NULL REFERENCES expense_allocations(id) ON DELETE CASCADE,
+    store VARCHAR(100) NOT NULL,
+    account_id BIGINT NOT NULL,
+    share DOUBLE PRECISION NOT NULL,
+    amount NUMERIC NOT NULL,
+    journal_id BIGINT
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS attachment_links;
+DROP TABLE IF EXISTS attachments;
@@ -0,0 +1,25 @@
+-- Uploaded documents. The file itself lives in the configured storage under
+-- storage_key; the row keeps what is needed to serve and verify it.
+CREATE TABLE IF NOT EXISTS attachments (
+    id BIGSERIAL PRIMARY KEY,
+    storage_key TEXT NOT NULL UNIQUE,
+    file_name TEXT NOT NULL,
+    content_type TEXT NOT NULL DEFAULT 'application/octet-stream',
+    size_bytes BIGINT NOT NULL,
+    sha256 CHAR(64) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Records an attachment belongs to: expenses, journal entries, ad invoices,
+-- tax payments, withdrawals, Shopee adjustments and import batches.
+CREATE TABLE IF NOT EXISTS attachment_links (
+    attachment_id BIGINT NOT NULL REFERENCES attachments(id) ON DELETE CASCADE,
+    entity_type VARCHAR(32) NOT NULL,
+    entity_id TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (attachment_id, entity_type, entity_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_attachment_links_entity
+    ON attachment_links(entity_type, entity_id);
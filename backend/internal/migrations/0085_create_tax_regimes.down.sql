@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_tax_payments_due;
+DROP INDEX IF EXISTS idx_tax_payments_obligation;
+ALTER TABLE tax_payments
+    DROP COLUMN IF EXISTS reminded_at,
+    DROP COLUMN IF EXISTS due_date,
+    DROP COLUMN IF EXISTS cumulative_revenue,
+    DROP COLUMN IF EXISTS exempt_amount,
+    DROP COLUMN IF EXISTS tax_base,
+    DROP COLUMN IF EXISTS regime,
+    DROP COLUMN IF EXISTS tax_type;
+DROP TABLE IF EXISTS tax_regimes;
+DELETE FROM accounts WHERE account_code IN ('5.4.2', '4.4');
@@ -0,0 +1,75 @@
+-- Accounts for taxes beyond PPh Final UMKM (5.4.1).
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM accounts WHERE account_code='5.4.2') THEN
+        INSERT INTO accounts (account_id, account_code, account_name, account_type, parent_id)
+        VALUES (54002, '5.4.2', 'PPh Pasal 25/29', 'Expense',
+            (SELECT account_id FROM accounts WHERE account_code='5.4'));
+    END IF;
+    -- PPN collected in marketplace prices is not the store's revenue; paying
+    -- it is booked against this contra revenue account.
+    IF NOT EXISTS (SELECT 1 FROM accounts WHERE account_code='4.4') THEN
+        INSERT INTO accounts (account_id, account_code, account_name, account_type, parent_id)
+        VALUES (4004, '4.4', 'PPN Keluaran', 'Revenue',
+            (SELECT account_id FROM accounts WHERE account_code='4'));
+    END IF;
+END $$;
+
+-- Tax regime of a store over a span of time. Months without a regime fall
+-- back to PPh Final UMKM without the individual threshold.
+CREATE TABLE IF NOT EXISTS tax_regimes (
+    id BIGSERIAL PRIMARY KEY,
+    store TEXT NOT NULL,
+    regime VARCHAR(20) NOT NULL,            -- pph_final_umkm or pph_normal
+    taxpayer_type VARCHAR(20) NOT NULL,     -- individual or corporate
+    pkp BOOLEAN NOT NULL DEFAULT FALSE,     -- registered for PPN
+    rate NUMERIC,                           -- overrides the regime's default rate
+    ppn_rate NUMERIC NOT NULL DEFAULT 0.11,
+    effective_from DATE NOT NULL,
+    effective_to DATE,
+    notes TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_tax_regimes_store ON tax_regimes(store, effective_from);
+
+-- Obligations are one row per store, period and tax. Unpaid rows are
+-- recomputed until they are paid.
+ALTER TABLE tax_payments
+    ADD COLUMN IF NOT EXISTS tax_type VARCHAR(20) NOT NULL DEFAULT 'pph_final',
+    ADD COLUMN IF NOT EXISTS regime VARCHAR(20) NOT NULL DEFAULT 'pph_final_umkm',
+    ADD COLUMN IF NOT EXISTS tax_base NUMERIC NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS exempt_amount NUMERIC NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS cumulative_revenue NUMERIC NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS due_date DATE,
+    ADD COLUMN IF NOT EXISTS reminded_at TIMESTAMPTZ;
+
+UPDATE tax_payments SET tax_base = revenue WHERE tax_base = 0;
+UPDATE tax_payments SET paid_at = NULL WHERE NOT is_paid;
+
+-- Paying without a recorded obligation used to insert a new paid row each
+-- time. Their journals point at the rows, so later paid duplicates are kept
+-- but moved to their own period value, e.g. 2025-01#dup2, leaving the
+-- oldest payment as the obligation.
+UPDATE tax_payments t
+   SET period_value = t.period_value || '#dup' || d.n
+  FROM (SELECT id, ROW_NUMBER() OVER (
+                PARTITION BY store, period_type, period_value, tax_type
+                ORDER BY paid_at NULLS LAST, ctid) AS n
+          FROM tax_payments
+         WHERE is_paid) d
+ WHERE t.id = d.id AND d.n > 1;
+
+-- Drop unpaid duplicates, keeping the paid row or else the first one, so
+-- concurrent recording cannot create an obligation twice.
+DELETE FROM tax_payments t
+ USING tax_payments k
+ WHERE t.store = k.store AND t.period_type = k.period_type
+   AND t.period_value = k.period_value AND t.tax_type = k.tax_type
+   AND NOT t.is_paid AND (k.is_paid OR k.ctid < t.ctid);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tax_payments_obligation
+    ON tax_payments(store, period_type, period_value, tax_type);
+
+CREATE INDEX IF NOT EXISTS idx_tax_payments_due ON tax_payments(due_date) WHERE NOT is_paid;
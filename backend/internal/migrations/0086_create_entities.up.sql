@@ -0,0 +1,249 @@
+-- Legal entities keeping their own books. Stores belong to one entity;
+-- journals, balances and tax payments carry the entity they were booked in.
+CREATE TABLE IF NOT EXISTS entities (
+    id BIGSERIAL PRIMARY KEY,
+    code VARCHAR(20) NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    tax_id TEXT NOT NULL DEFAULT '',
+    address TEXT NOT NULL DEFAULT '',
+    -- journals without a store with an entity are booked here
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_entities_default ON entities(is_default) WHERE is_default;
+
+INSERT INTO entities (code, name, is_default)
+SELECT 'MAIN', 'Main Entity', TRUE
+WHERE NOT EXISTS (SELECT 1 FROM entities);
+
+ALTER TABLE stores ADD COLUMN IF NOT EXISTS entity_id BIGINT REFERENCES entities(id);
+UPDATE stores SET entity_id = (SELECT id FROM entities WHERE is_default) WHERE entity_id IS NULL;
+
+-- Accounts without an entity form the shared chart every entity uses;
+-- entity accounts can only be posted to by that entity's journals.
+ALTER TABLE accounts
+    ADD COLUMN IF NOT EXISTS entity_id BIGINT REFERENCES entities(id) ON DELETE CASCADE,
+    ADD COLUMN IF NOT EXISTS intercompany BOOLEAN NOT NULL DEFAULT FALSE;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM accounts WHERE account_code='1.1.15') THEN
+        INSERT INTO accounts (account_id, account_code, account_name, account_type, parent_id, intercompany)
+        VALUES (11015, '1.1.15', 'Piutang Antar Perusahaan', 'Asset',
+            (SELECT account_id FROM accounts WHERE account_code='1.1'), TRUE);
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM accounts WHERE account_code='2.1.7') THEN
+        INSERT INTO accounts (account_id, account_code, account_name, account_type, parent_id, intercompany)
+        VALUES (21007, '2.1.7', 'Utang Antar Perusahaan', 'Liability',
+            (SELECT account_id FROM accounts WHERE account_code='2.1'), TRUE);
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM accounts WHERE account_code='4.5') THEN
+        INSERT INTO accounts (account_id, account_code, account_name, account_type, parent_id, intercompany)
+        VALUES (4005, '4.5', 'Pendapatan Antar Perusahaan', 'Revenue',
+            (SELECT account_id FROM accounts WHERE account_code='4'), TRUE);
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM accounts WHERE account_code='5.6') THEN
+        INSERT INTO accounts (account_id, account_code, account_name, account_type, parent_id, intercompany)
+        VALUES (5006, '5.6', 'Beban Antar Perusahaan', 'Expense',
+            (SELECT account_id FROM accounts WHERE account_code='5'), TRUE);
+    END IF;
+END $$;
+
+ALTER TABLE journal_entries ADD COLUMN IF NOT EXISTS entity_id BIGINT REFERENCES entities(id);
+ALTER TABLE tax_payments ADD COLUMN IF NOT EXISTS entity_id BIGINT REFERENCES entities(id);
+
+-- entity_for_store resolves the entity of a store, falling back to the
+-- default entity.
+CREATE OR REPLACE FUNCTION entity_for_store(p_store TEXT) RETURNS BIGINT AS $$
+    SELECT COALESCE(
+        (SELECT entity_id FROM stores WHERE nama_toko = p_store AND entity_id IS NOT NULL LIMIT 1),
+        (SELECT id FROM entities WHERE is_default));
+$$ LANGUAGE sql STABLE;
+
+UPDATE journal_entries SET entity_id = entity_for_store(COALESCE(NULLIF(store, ''), shop_username))
+ WHERE entity_id IS NULL;
+UPDATE tax_payments SET entity_id = entity_for_store(store) WHERE entity_id IS NULL;
+ALTER TABLE journal_entries ALTER COLUMN entity_id SET NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_journal_entries_entity ON journal_entries(entity_id, entry_date);
+
+-- Journals and tax payments posted without an entity take their store's.
+-- Moving a store later leaves what was already booked where it was.
+CREATE OR REPLACE FUNCTION journal_entries_set_entity() RETURNS TRIGGER AS $$
+BEGIN
+  IF NEW.entity_id IS NULL THEN
+    NEW.entity_id := entity_for_store(COALESCE(NULLIF(NEW.store, ''), NEW.shop_username));
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_journal_entries_set_entity ON journal_entries;
+CREATE TRIGGER trg_journal_entries_set_entity
+  BEFORE INSERT ON journal_entries
+  FOR EACH ROW EXECUTE FUNCTION journal_entries_set_entity();
+
+CREATE OR REPLACE FUNCTION tax_payments_set_entity() RETURNS TRIGGER AS $$
+BEGIN
+  IF NEW.entity_id IS NULL THEN
+    NEW.entity_id := entity_for_store(NEW.store);
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_tax_payments_set_entity ON tax_payments;
+CREATE TRIGGER trg_tax_payments_set_entity
+  BEFORE INSERT ON tax_payments
+  FOR EACH ROW EXECUTE FUNCTION tax_payments_set_entity();
+
+-- Rejects lines posting to another entity's accounts.
+CREATE OR REPLACE FUNCTION journal_lines_entity_check() RETURNS TRIGGER AS $$
+DECLARE
+  v_account BIGINT;
+  v_journal BIGINT;
+BEGIN
+  SELECT entity_id INTO v_account FROM accounts WHERE account_id = NEW.account_id;
+  IF v_account IS NULL THEN
+    RETURN NEW;
+  END IF;
+  SELECT entity_id INTO v_journal FROM journal_entries WHERE journal_id = NEW.journal_id;
+  IF v_journal IS DISTINCT FROM v_account THEN
+    RAISE EXCEPTION 'account % belongs to entity %, journal % is booked in entity %',
+      NEW.account_id, v_account, NEW.journal_id, v_journal;
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_journal_lines_entity_check ON journal_lines;
+CREATE TRIGGER trg_journal_lines_entity_check
+  BEFORE INSERT OR UPDATE OF account_id ON journal_lines
+  FOR EACH ROW EXECUTE FUNCTION journal_lines_entity_check();
+
+-- Money moved or charged between entities. Each side is posted as its own
+-- journal in its own entity.
+CREATE TABLE IF NOT EXISTS intercompany_transactions (
+    id BIGSERIAL PRIMARY KEY,
+    from_entity_id BIGINT NOT NULL REFERENCES entities(id),
+    to_entity_id BIGINT NOT NULL REFERENCES entities(id),
+    txn_date DATE NOT NULL,
+    amount NUMERIC(16,2) NOT NULL CHECK (amount > 0),
+    from_account_id INT NOT NULL REFERENCES accounts(account_id), -- credited by the sender
+    to_account_id INT NOT NULL REFERENCES accounts(account_id),   -- debited by the receiver
+    description TEXT NOT NULL DEFAULT '',
+    reversed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (from_entity_id <> to_entity_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_intercompany_transactions_date ON intercompany_transactions(txn_date);
+
+-- Balances get the entity dimension so entity reports read the snapshots
+-- like store reports do.
+ALTER TABLE account_balance_daily ADD COLUMN IF NOT EXISTS entity_id BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE account_balance_daily DROP CONSTRAINT IF EXISTS account_balance_daily_pkey;
+ALTER TABLE account_balance_daily ADD PRIMARY KEY (account_id, shop_username, entity_id, balance_date);
+CREATE INDEX IF NOT EXISTS idx_account_balance_daily_entity_date
+  ON account_balance_daily (entity_id, balance_date);
+
+DROP FUNCTION IF EXISTS apply_account_balance_delta(INT, VARCHAR, DATE, NUMERIC, NUMERIC);
+CREATE OR REPLACE FUNCTION apply_account_balance_delta(
+  p_account_id INT, p_shop VARCHAR, p_entity BIGINT, p_date DATE, p_debit NUMERIC, p_credit NUMERIC
+) RETURNS VOID AS $$
+BEGIN
+  IF p_debit = 0 AND p_credit = 0 THEN
+    RETURN;
+  END IF;
+  INSERT INTO account_balance_daily AS d
+    (account_id, shop_username, entity_id, balance_date, debit_total, credit_total)
+  VALUES (p_account_id, p_shop, p_entity, p_date, p_debit, p_credit)
+  ON CONFLICT (account_id, shop_username, entity_id, balance_date) DO UPDATE
+    SET debit_total  = d.debit_total + EXCLUDED.debit_total,
+        credit_total = d.credit_total + EXCLUDED.credit_total;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION journal_lines_balance_sync() RETURNS TRIGGER AS $$
+DECLARE
+  v_date DATE;
+  v_shop VARCHAR;
+  v_entity BIGINT;
+BEGIN
+  IF TG_OP IN ('UPDATE', 'DELETE') THEN
+    SELECT entry_date, shop_username, entity_id INTO v_date, v_shop, v_entity
+      FROM journal_entries WHERE journal_id = OLD.journal_id;
+    IF FOUND THEN
+      PERFORM apply_account_balance_delta(OLD.account_id, v_shop, v_entity, v_date,
+        CASE WHEN OLD.is_debit THEN -OLD.amount ELSE 0 END,
+        CASE WHEN OLD.is_debit THEN 0 ELSE -OLD.amount END);
+    END IF;
+  END IF;
+  IF TG_OP IN ('INSERT', 'UPDATE') THEN
+    SELECT entry_date, shop_username, entity_id INTO v_date, v_shop, v_entity
+      FROM journal_entries WHERE journal_id = NEW.journal_id;
+    IF FOUND THEN
+      PERFORM apply_account_balance_delta(NEW.account_id, v_shop, v_entity, v_date,
+        CASE WHEN NEW.is_debit THEN NEW.amount ELSE 0 END,
+        CASE WHEN NEW.is_debit THEN 0 ELSE NEW.amount END);
+    END IF;
+  END IF;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION journal_entries_balance_sync() RETURNS TRIGGER AS $$
+DECLARE
+  l RECORD;
+BEGIN
+  IF TG_OP = 'UPDATE' AND NEW.entry_date = OLD.entry_date
+     AND NEW.shop_username = OLD.shop_username AND NEW.entity_id = OLD.entity_id THEN
+    RETURN NEW;
+  END IF;
+  FOR l IN
+    SELECT account_id,
+           SUM(CASE WHEN is_debit THEN amount ELSE 0 END) AS debit,
+           SUM(CASE WHEN is_debit THEN 0 ELSE amount END) AS credit
+      FROM journal_lines WHERE journal_id = OLD.journal_id
+     GROUP BY account_id
+  LOOP
+    PERFORM apply_account_balance_delta(l.account_id, OLD.shop_username,
+      OLD.entity_id, OLD.entry_date, -l.debit, -l.credit);
+    IF TG_OP = 'UPDATE' THEN
+      PERFORM apply_account_balance_delta(l.account_id, NEW.shop_username,
+        NEW.entity_id, NEW.entry_date, l.debit, l.credit);
+    END IF;
+  END LOOP;
+  IF TG_OP = 'DELETE' THEN
+    RETURN OLD;
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_journal_entries_balance_update ON journal_entries;
+CREATE TRIGGER trg_journal_entries_balance_update
+  AFTER UPDATE OF entry_date, shop_username, entity_id ON journal_entries
+  FOR EACH ROW EXECUTE FUNCTION journal_entries_balance_sync();
+
+CREATE OR REPLACE FUNCTION rebuild_account_balance_daily() RETURNS BIGINT AS $$
+DECLARE
+  n BIGINT;
+BEGIN
+  LOCK TABLE journal_lines IN SHARE MODE;
+  DELETE FROM account_balance_daily;
+  INSERT INTO account_balance_daily
+    (account_id, shop_username, entity_id, balance_date, debit_total, credit_total)
+  SELECT jl.account_id, je.shop_username, je.entity_id, je.entry_date,
+         SUM(CASE WHEN jl.is_debit THEN jl.amount ELSE 0 END),
+         SUM(CASE WHEN jl.is_debit THEN 0 ELSE jl.amount END)
+    FROM journal_lines jl
+    JOIN journal_entries je ON je.journal_id = jl.journal_id
+   GROUP BY jl.account_id, je.shop_username, je.entity_id, je.entry_date;
+  GET DIAGNOSTICS n = ROW_COUNT;
+  RETURN n;
+END;
+$$ LANGUAGE plpgsql;
+
+SELECT rebuild_account_balance_daily();
@@ -0,0 +1,2 @@
+ALTER TABLE batch_history
+    DROP COLUMN IF EXISTS claimed_by;
@@ -0,0 +1,3 @@
+-- The API instance processing a batch, so a shutdown only resets its own.
+ALTER TABLE batch_history
+    ADD COLUMN claimed_by TEXT;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_expense_allocations_active_journal;
+DELETE FROM expense_allocations WHERE source_journal_id IS NOT NULL;
+ALTER TABLE expense_allocations DROP CONSTRAINT IF EXISTS expense_allocations_one_source;
+ALTER TABLE expense_allocations DROP COLUMN IF EXISTS source_journal_id;
+ALTER TABLE expense_allocations ALTER COLUMN expense_id SET NOT NULL;
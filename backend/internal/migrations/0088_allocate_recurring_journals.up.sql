@@ -0,0 +1,9 @@
+-- Recurring amortisation and accrual journals carry expense without an
+-- expense row, so an allocation can split a journal instead.
+ALTER TABLE expense_allocations ALTER COLUMN expense_id DROP NOT NULL;
+ALTER TABLE expense_allocations ADD COLUMN IF NOT EXISTS source_journal_id BIGINT;
+ALTER TABLE expense_allocations ADD CONSTRAINT expense_allocations_one_source
+    CHECK ((expense_id IS NULL) <> (source_journal_id IS NULL));
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_expense_allocations_active_journal
+    ON expense_allocations(source_journal_id) WHERE reversed_at IS NULL;
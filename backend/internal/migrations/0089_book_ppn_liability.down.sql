@@ -0,0 +1,5 @@
+ALTER TABLE tax_payments
+    DROP COLUMN IF EXISTS output_booked,
+    DROP COLUMN IF EXISTS input_tax,
+    DROP COLUMN IF EXISTS output_tax;
+DELETE FROM accounts WHERE account_code IN ('2.1.10', '1.1.16');
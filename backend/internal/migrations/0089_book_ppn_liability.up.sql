@@ -0,0 +1,22 @@
+-- Output VAT is owed from the month of the sale until it is paid, and input
+-- VAT paid on purchases is held until it is set off against it.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM accounts WHERE account_code='2.1.10') THEN
+        INSERT INTO accounts (account_id, account_code, account_name, account_type, parent_id)
+        VALUES (21010, '2.1.10', 'Utang PPN', 'Liability',
+            (SELECT account_id FROM accounts WHERE account_code='2.1'));
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM accounts WHERE account_code='1.1.16') THEN
+        INSERT INTO accounts (account_id, account_code, account_name, account_type, parent_id)
+        VALUES (11016, '1.1.16', 'PPN Masukan', 'Asset',
+            (SELECT account_id FROM accounts WHERE account_code='1.1'));
+    END IF;
+END $$;
+
+-- For PPN rows: the output VAT of the month, the input VAT set off against
+-- it, and how much of the output VAT has been booked to Utang PPN so far.
+ALTER TABLE tax_payments
+    ADD COLUMN IF NOT EXISTS output_tax NUMERIC NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS input_tax NUMERIC NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS output_booked NUMERIC NOT NULL DEFAULT 0;
@@ -14,19 +14,42 @@ import (
 //go:embed *.sql
 var migrationFS embed.FS
 
-// Run applies all pending migrations.
-func Run(db *sql.DB) error {
+func newMigrate(db *sql.DB) (*migrate.Migrate, error) {
 	driver, err := postgres.WithInstance(db, &postgres.Config{})
 	if err != nil {
-		return err
+		return nil, err
 	}
 	source, err := httpfs.New(http.FS(migrationFS), ".")
 	if err != nil {
-		return err
+		return nil, err
 	}
-	m, err := migrate.NewWithInstance("httpfs", source, "postgres", driver)
+	return migrate.NewWithInstance("httpfs", source, "postgres", driver)
+}
+
+// Run applies all pending migrations.
+func Run(db *sql.DB) error {
+	m, err := newMigrate(db)
 	if err != nil {
 		return err
 	}
 	return m.Up()
 }
+
+// Down rolls back the last steps migrations.
+func Down(db *sql.DB, steps int) error {
+	m, err := newMigrate(db)
+	if err != nil {
+		return err
+	}
+	return m.Steps(-steps)
+}
+
+// Version reports the current schema version and whether the last migration
+// failed part way. It returns migrate.ErrNilVersion on an empty database.
+func Version(db *sql.DB) (uint, bool, error) {
+	m, err := newMigrate(db)
+	if err != nil {
+		return 0, false, err
+	}
+	return m.Version()
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Record types an attachment can be linked to.
+const (
+	AttachToExpense          = "expense"
+	AttachToJournalEntry     = "journal_entry"
+	AttachToAdInvoice        = "ad_invoice"
+	AttachToTaxPayment       = "tax_payment"
+	AttachToWithdrawal       = "withdrawal"
+	AttachToShopeeAdjustment = "shopee_adjustment"
+	// AttachToBatch links an import file to the batch_history row that
+	// processed it.
+	AttachToBatch = "batch"
+)
+
+// Attachment is an uploaded document such as a receipt or an adjustment
+// proof. The content lives in the configured storage under StorageKey.
+type Attachment struct {
+	ID          int64            `db:"id" json:"id"`
+	StorageKey  string           `db:"storage_key" json:"-"`
+	FileName    string           `db:"file_name" json:"file_name"`
+	ContentType string           `db:"content_type" json:"content_type"`
+	SizeBytes   int64            `db:"size_bytes" json:"size_bytes"`
+	SHA256      string           `db:"sha256" json:"sha256"`
+	Description string           `db:"description" json:"description"`
+	CreatedAt   time.Time        `db:"created_at" json:"created_at"`
+	Links       []AttachmentLink `json:"links"`
+}
+
+// AttachmentLink ties an attachment to a record.
+type AttachmentLink struct {
+	AttachmentID int64  `db:"attachment_id" json:"-"`
+	EntityType   string `db:"entity_type" json:"entity_type" binding:"required"`
+	EntityID     string `db:"entity_id" json:"entity_id" binding:"required"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
+)
+
+// Entity is a legal entity with its own books. Stores belong to one entity
+// and their journals are booked in it; journals without such a store go to
+// the default entity.
+type Entity struct {
+	ID        int64     `db:"id" json:"id"`
+	Code      string    `db:"code" json:"code"`
+	Name      string    `db:"name" json:"name"`
+	TaxID     string    `db:"tax_id" json:"tax_id"`
+	Address   string    `db:"address" json:"address"`
+	IsDefault bool      `db:"is_default" json:"is_default"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// IntercompanyTransaction moves or charges Amount from one entity to
+// another. The sender debits its intercompany receivable and credits
+// FromAccountID; the receiver debits ToAccountID and credits its
+// intercompany payable.
+type IntercompanyTransaction struct {
+	ID            int64        `db:"id" json:"id"`
+	FromEntityID  int64        `db:"from_entity_id" json:"from_entity_id"`
+	ToEntityID    int64        `db:"to_entity_id" json:"to_entity_id"`
+	TxnDate       time.Time    `db:"txn_date" json:"txn_date"`
+	Amount        money.Amount `db:"amount" json:"amount"`
+	FromAccountID int64        `db:"from_account_id" json:"from_account_id"`
+	ToAccountID   int64        `db:"to_account_id" json:"to_account_id"`
+	Description   string       `db:"description" json:"description"`
+	ReversedAt    *time.Time   `db:"reversed_at" json:"reversed_at"`
+	CreatedAt     time.Time    `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"time"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
+)
+
+// Expense allocation methods.
+const (
+	// AllocateFixed splits by the weights listed on the rule.
+	AllocateFixed = "fixed"
+	// AllocateRevenue splits by each store's revenue in the basis period.
+	AllocateRevenue = "revenue"
+	// AllocateOrders splits by each store's non-cancelled orders in the
+	// basis period.
+	AllocateOrders = "orders"
+)
+
+// ExpenseAllocationRule describes how an overhead expense is split across
+// stores.
+type ExpenseAllocationRule struct {
+	ID          int64                        `db:"id" json:"id"`
+	Name        string                       `db:"name" json:"name"`
+	Method      string                       `db:"method" json:"method"`
+	Description string                       `db:"description" json:"description"`
+	CreatedAt   time.Time                    `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time                    `db:"updated_at" json:"updated_at"`
+	Stores      []ExpenseAllocationRuleStore `json:"stores"`
+}
+
+// ExpenseAllocationRuleStore is one store of a rule. Weight is only used by
+// fixed rules.
+type ExpenseAllocationRuleStore struct {
+	RuleID int64   `db:"rule_id" json:"-"`
+	Store  string  `db:"store" json:"store"`
+	Weight float64 `db:"weight" json:"weight"`
+}
+
+// StoreShare is the fraction of an expense a store carries, with the basis
+// it was computed from (weight, revenue or order count).
+type StoreShare struct {
+	Store string  `json:"store"`
+	Basis float64 `json:"basis"`
+	Share float64 `json:"share"`
+}
+
+// ExpenseAllocation records how one expense was split across stores. It
+// splits either an expense (ExpenseID) or, for recurring amortisation and
+// accrual journals that have no expense, the journal (SourceJournalID).
+type ExpenseAllocation struct {
+	ID              int64                   `db:"id" json:"id"`
+	ExpenseID       string                  `db:"expense_id" json:"expense_id"`
+	SourceJournalID *int64                  `db:"source_journal_id" json:"source_journal_id"`
+	RuleID          int64                   `db:"rule_id" json:"rule_id"`
+	Method          string                  `db:"method" json:"method"`
+	BasisFrom       time.Time               `db:"basis_from" json:"basis_from"`
+	BasisTo         time.Time               `db:"basis_to" json:"basis_to"`
+	Total           money.Amount            `db:"total" json:"total"`
+	PoolJournalID   *int64                  `db:"pool_journal_id" json:"pool_journal_id"`
+	ReversedAt      *time.Time              `db:"reversed_at" json:"reversed_at"`
+	CreatedAt       time.Time               `db:"created_at" json:"created_at"`
+	Lines           []ExpenseAllocationLine `json:"lines"`
+}
+
+// ExpenseAllocationLine is the part of one expense account charged to one
+// store, and the store journal that carries it.
+type ExpenseAllocationLine struct {
+	ID           int64        `db:"id" json:"id"`
+	AllocationID int64        `db:"allocation_id" json:"allocation_id"`
+	Store        string       `db:"store" json:"store"`
+	AccountID    int64        `db:"account_id" json:"account_id"`
+	Share        float64      `db:"share" json:"share"`
+	Amount       money.Amount `db:"amount" json:"amount"`
+	JournalID    *int64       `db:"journal_id" json:"journal_id"`
+}
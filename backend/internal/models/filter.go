@@ -53,6 +53,9 @@ type PaginationParams struct {
 	PageSize int `json:"page_size"`
 	Offset   int `json:"offset"`
 	Limit    int `json:"limit"`
+	// Cursor, when set, selects the page after the row it was taken from
+	// (keyset pagination) and Offset is ignored.
+	Cursor string `json:"cursor,omitempty"`
 }
 
 // FilterParams represents the complete filter, sort, and pagination parameters
@@ -69,6 +72,9 @@ type QueryResult struct {
 	Page       int         `json:"page"`
 	PageSize   int         `json:"page_size"`
 	TotalPages int         `json:"total_pages"`
+	// NextCursor continues the listing after the last row of Data. It is
+	// empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // NewPaginationParams creates pagination parameters with defaults
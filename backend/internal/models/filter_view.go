@@ -0,0 +1,167 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Resources served by the /filtered list endpoints. Saved filter views are
+// stored against one of them.
+const (
+	ResourceDropshipPurchases     = "dropship_purchases"
+	ResourceShopeeSettled         = "shopee_settled"
+	ResourceShopeeAffiliate       = "shopee_affiliate"
+	ResourceJournalEntries        = "journal_entries"
+	ResourceExpenses              = "expenses"
+	ResourceShopeeAdjustments     = "shopee_adjustments"
+	ResourceOrderDetails          = "order_details"
+	ResourceShippingDiscrepancies = "shipping_discrepancies"
+	ResourceFailedReconciliations = "failed_reconciliations"
+	ResourceBatches               = "batches"
+)
+
+// FilterResources lists every resource a saved view may target.
+var FilterResources = map[string]bool{
+	ResourceDropshipPurchases:     true,
+	ResourceShopeeSettled:         true,
+	ResourceShopeeAffiliate:       true,
+	ResourceJournalEntries:        true,
+	ResourceExpenses:              true,
+	ResourceShopeeAdjustments:     true,
+	ResourceOrderDetails:          true,
+	ResourceShippingDiscrepancies: true,
+	ResourceFailedReconciliations: true,
+	ResourceBatches:               true,
+}
+
+// ErrInvalidFilterView is returned when a saved view fails validation or
+// does not apply to the endpoint it is used on.
+var ErrInvalidFilterView = errors.New("invalid filter view")
+
+// SavedFilterView is a named set of filters and sort for one resource,
+// stored in saved_filter_views.
+type SavedFilterView struct {
+	ID          int64        `db:"id" json:"id"`
+	Name        string       `db:"name" json:"name"`
+	Resource    string       `db:"resource" json:"resource"`
+	Owner       string       `db:"owner" json:"owner"`
+	Shared      bool         `db:"shared" json:"shared"`
+	Description string       `db:"description" json:"description"`
+	Filters     *FilterGroup `db:"filters" json:"filters"`
+	Sort        SortList     `db:"sort" json:"sort"`
+	PageSize    int          `db:"page_size" json:"page_size"`
+	CreatedAt   time.Time    `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time    `db:"updated_at" json:"updated_at"`
+}
+
+// Validate checks the view's filters and sort.
+func (v *SavedFilterView) Validate() error {
+	params := FilterParams{Filters: v.Filters, Sort: v.Sort}
+	return params.Validate()
+}
+
+// SortList is a list of sort conditions stored as a JSON column.
+type SortList []SortCondition
+
+// Value encodes the list as JSON.
+func (s SortList) Value() (driver.Value, error) {
+	if s == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal([]SortCondition(s))
+	return string(b), err
+}
+
+// Scan decodes a JSON column.
+func (s *SortList) Scan(src interface{}) error {
+	return scanJSON(src, s)
+}
+
+// Value encodes the group as JSON.
+func (g FilterGroup) Value() (driver.Value, error) {
+	b, err := json.Marshal(g)
+	return string(b), err
+}
+
+// Scan decodes a JSON column.
+func (g *FilterGroup) Scan(src interface{}) error {
+	return scanJSON(src, g)
+}
+
+func scanJSON(src interface{}, dst interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(v, dst)
+	case string:
+		return json.Unmarshal([]byte(v), dst)
+	default:
+		return fmt.Errorf("cannot scan %T as JSON", src)
+	}
+}
+
+// relativeDate matches tokens such as "@today", "@today-14d" or
+// "@month_start-1m" that saved views use instead of fixed dates.
+var relativeDate = regexp.MustCompile(`^@(today|week_start|month_start|year_start)(?:([+-]\d+)([dwmy]))?$`)
+
+// ResolveRelativeDate returns the date a relative token stands for, as
+// YYYY-MM-DD, and false when value is not a token. Weeks start on Monday.
+func ResolveRelativeDate(value string, now time.Time) (string, bool) {
+	m := relativeDate.FindStringSubmatch(value)
+	if m == nil {
+		return "", false
+	}
+	y, mo, d := now.Date()
+	day := time.Date(y, mo, d, 0, 0, 0, 0, now.Location())
+	switch m[1] {
+	case "week_start":
+		day = day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case "month_start":
+		day = time.Date(y, mo, 1, 0, 0, 0, 0, now.Location())
+	case "year_start":
+		day = time.Date(y, time.January, 1, 0, 0, 0, 0, now.Location())
+	}
+	if m[2] != "" {
+		n, _ := strconv.Atoi(m[2])
+		switch m[3] {
+		case "d":
+			day = day.AddDate(0, 0, n)
+		case "w":
+			day = day.AddDate(0, 0, 7*n)
+		case "m":
+			day = day.AddDate(0, n, 0)
+		case "y":
+			day = day.AddDate(n, 0, 0)
+		}
+	}
+	return day.Format("2006-01-02"), true
+}
+
+// ResolveRelativeDates replaces relative date tokens in every condition of
+// the group with the dates they stand for at now.
+func (fg *FilterGroup) ResolveRelativeDates(now time.Time) {
+	resolve := func(v interface{}) interface{} {
+		if s, ok := v.(string); ok {
+			if d, ok := ResolveRelativeDate(s, now); ok {
+				return d
+			}
+		}
+		return v
+	}
+	for i := range fg.Conditions {
+		c := &fg.Conditions[i]
+		c.Value = resolve(c.Value)
+		for j := range c.Values {
+			c.Values[j] = resolve(c.Values[j])
+		}
+	}
+	for i := range fg.Groups {
+		fg.Groups[i].ResolveRelativeDates(now)
+	}
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
+)
+
+// Ledger integrity checks. The value is stored in ledger_repairs.check_name.
+const (
+	CheckUnbalancedJournal     = "unbalanced_journal"
+	CheckDuplicateSource       = "duplicate_source_journal"
+	CheckSettledWithoutJournal = "settled_without_journal"
+	CheckMissingAccount        = "missing_account"
+	CheckStatusMismatch        = "status_journal_mismatch"
+)
+
+// IntegrityIssue is one problem found by the ledger integrity checker. Target
+// identifies it for RepairIssue: a journal id, or the invoice of a purchase.
+type IntegrityIssue struct {
+	Check           string        `db:"check_name" json:"check"`
+	Target          string        `db:"target" json:"target"`
+	JournalID       *int64        `db:"journal_id" json:"journal_id,omitempty"`
+	SourceType      *string       `db:"source_type" json:"source_type,omitempty"`
+	SourceID        *string       `db:"source_id" json:"source_id,omitempty"`
+	KodePesanan     *string       `db:"kode_pesanan" json:"kode_pesanan,omitempty"`
+	LifecycleStatus *string       `db:"lifecycle_status" json:"lifecycle_status,omitempty"`
+	AccountID       *int64        `db:"account_id" json:"account_id,omitempty"`
+	Debit           *money.Amount `db:"debit" json:"debit,omitempty"`
+	Credit          *money.Amount `db:"credit" json:"credit,omitempty"`
+	Exact           bool          `db:"exact" json:"exact,omitempty"` // duplicate source id matches exactly, not only after normalising
+	Detail          string        `db:"detail" json:"detail"`
+	Repairable      bool          `db:"-" json:"repairable"`
+}
+
+// IntegrityReport is the result of a full integrity scan.
+type IntegrityReport struct {
+	CheckedAt time.Time        `json:"checked_at"`
+	Counts    map[string]int   `json:"counts"`
+	Issues    []IntegrityIssue `json:"issues"`
+}
+
+// LedgerRepair is an audited repair made by the integrity checker.
+type LedgerRepair struct {
+	ID          int64     `db:"id" json:"id"`
+	Check       string    `db:"check_name" json:"check"`
+	Target      string    `db:"target" json:"target"`
+	Action      string    `db:"action" json:"action"`
+	Detail      string    `db:"detail" json:"detail"`
+	JournalID   *int64    `db:"journal_id" json:"journal_id"`
+	PerformedBy string    `db:"performed_by" json:"performed_by"`
+	PerformedAt time.Time `db:"performed_at" json:"performed_at"`
+}
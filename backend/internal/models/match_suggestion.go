@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
+)
+
+// Statuses of a MatchSuggestion.
+const (
+	SuggestionPending  = "pending"
+	SuggestionAccepted = "accepted"
+	SuggestionRejected = "rejected"
+)
+
+// MatchSuggestion proposes pairing a dropship purchase with a Shopee
+// settlement whose invoice does not match exactly. Scores are between 0 and
+// 1; Confidence is their weighted sum.
+type MatchSuggestion struct {
+	ID                 int64      `db:"id" json:"id"`
+	Store              string     `db:"store" json:"store"`
+	KodePesanan        string     `db:"kode_pesanan" json:"kode_pesanan"`
+	KodeInvoiceChannel string     `db:"kode_invoice_channel" json:"kode_invoice_channel"`
+	NoPesanan          string     `db:"no_pesanan" json:"no_pesanan"`
+	Confidence         float64    `db:"confidence" json:"confidence"`
+	InvoiceScore       float64    `db:"invoice_score" json:"invoice_score"`
+	StoreScore         float64    `db:"store_score" json:"store_score"`
+	DateScore          float64    `db:"date_score" json:"date_score"`
+	AmountScore        float64    `db:"amount_score" json:"amount_score"`
+	Status             string     `db:"status" json:"status"`
+	CreatedAt          time.Time  `db:"created_at" json:"created_at"`
+	DecidedAt          *time.Time `db:"decided_at" json:"decided_at"`
+}
+
+// MatchPurchase is a dropship purchase without a settlement, with the
+// channel price of its items.
+type MatchPurchase struct {
+	KodePesanan         string       `db:"kode_pesanan" json:"kode_pesanan"`
+	KodeInvoiceChannel  string       `db:"kode_invoice_channel" json:"kode_invoice_channel"`
+	NamaToko            string       `db:"nama_toko" json:"nama_toko"`
+	WaktuPesananTerbuat time.Time    `db:"waktu_pesanan_terbuat" json:"waktu_pesanan_terbuat"`
+	ChannelTotal        money.Amount `db:"channel_total" json:"channel_total"`
+}
+
+// MatchOrder is a Shopee settlement without a purchase, with its product
+// price after discounts.
+type MatchOrder struct {
+	NoPesanan          string       `db:"no_pesanan" json:"no_pesanan"`
+	NamaToko           string       `db:"nama_toko" json:"nama_toko"`
+	WaktuPesananDibuat time.Time    `db:"waktu_pesanan_dibuat" json:"waktu_pesanan_dibuat"`
+	ProductTotal       money.Amount `db:"product_total" json:"product_total"`
+}
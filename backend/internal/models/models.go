@@ -6,17 +6,21 @@ import (
 	"time"
 
 	"github.com/lib/pq"
+	"github.com/ramadhan22/dropship-erp/backend/internal/lifecycle"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 )
 
 // Account represents the D6 table: accounts
 type Account struct {
-	AccountID   int64     `db:"account_id" json:"account_id"`
-	AccountCode string    `db:"account_code" json:"account_code"`
-	AccountName string    `db:"account_name" json:"account_name"`
-	AccountType string    `db:"account_type" json:"account_type"`
-	ParentID    *int64    `db:"parent_id" json:"parent_id"` // NULLABLE
-	CreatedAt   time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+	AccountID    int64     `db:"account_id" json:"account_id"`
+	AccountCode  string    `db:"account_code" json:"account_code"`
+	AccountName  string    `db:"account_name" json:"account_name"`
+	AccountType  string    `db:"account_type" json:"account_type"`
+	ParentID     *int64    `db:"parent_id" json:"parent_id"` // NULLABLE
+	EntityID     *int64    `db:"entity_id" json:"entity_id"` // NULL: shared by every entity
+	Intercompany bool      `db:"intercompany" json:"intercompany"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at" json:"updated_at"`
 }
 
 // DropshipPurchase represents the header table: dropship_purchases
@@ -25,94 +29,97 @@ type DropshipPurchase struct {
 	KodeTransaksi         string    `db:"kode_transaksi" json:"kode_transaksi"`
 	WaktuPesananTerbuat   time.Time `db:"waktu_pesanan_terbuat" json:"waktu_pesanan_terbuat"`
 	StatusPesananTerakhir string    `db:"status_pesanan_terakhir" json:"status_pesanan_terakhir"`
-	BiayaLainnya          float64   `db:"biaya_lainnya" json:"biaya_lainnya"`
-	BiayaMitraJakmall     float64   `db:"biaya_mitra_jakmall" json:"biaya_mitra_jakmall"`
-	TotalTransaksi        float64   `db:"total_transaksi" json:"total_transaksi"`
-	DibuatOleh            string    `db:"dibuat_oleh" json:"dibuat_oleh"`
-	JenisChannel          string    `db:"jenis_channel" json:"jenis_channel"`
-	NamaToko              string    `db:"nama_toko" json:"nama_toko"`
-	KodeInvoiceChannel    string    `db:"kode_invoice_channel" json:"kode_invoice_channel"`
-	GudangPengiriman      string    `db:"gudang_pengiriman" json:"gudang_pengiriman"`
-	JenisEkspedisi        string    `db:"jenis_ekspedisi" json:"jenis_ekspedisi"`
-	Cashless              string    `db:"cashless" json:"cashless"`
-	NomorResi             string    `db:"nomor_resi" json:"nomor_resi"`
-	WaktuPengiriman       time.Time `db:"waktu_pengiriman" json:"waktu_pengiriman"`
-	Provinsi              string    `db:"provinsi" json:"provinsi"`
-	Kota                  string    `db:"kota" json:"kota"`
+	// LifecycleStatus is the typed state behind StatusPesananTerakhir; it only
+	// changes through DropshipRepo.TransitionPurchaseStatus.
+	LifecycleStatus    lifecycle.Status `db:"lifecycle_status" json:"lifecycle_status"`
+	BiayaLainnya       money.Amount     `db:"biaya_lainnya" json:"biaya_lainnya"`
+	BiayaMitraJakmall  money.Amount     `db:"biaya_mitra_jakmall" json:"biaya_mitra_jakmall"`
+	TotalTransaksi     money.Amount     `db:"total_transaksi" json:"total_transaksi"`
+	DibuatOleh         string           `db:"dibuat_oleh" json:"dibuat_oleh"`
+	JenisChannel       string           `db:"jenis_channel" json:"jenis_channel"`
+	NamaToko           string           `db:"nama_toko" json:"nama_toko"`
+	KodeInvoiceChannel string           `db:"kode_invoice_channel" json:"kode_invoice_channel"`
+	GudangPengiriman   string           `db:"gudang_pengiriman" json:"gudang_pengiriman"`
+	JenisEkspedisi     string           `db:"jenis_ekspedisi" json:"jenis_ekspedisi"`
+	Cashless           string           `db:"cashless" json:"cashless"`
+	NomorResi          string           `db:"nomor_resi" json:"nomor_resi"`
+	WaktuPengiriman    time.Time        `db:"waktu_pengiriman" json:"waktu_pengiriman"`
+	Provinsi           string           `db:"provinsi" json:"provinsi"`
+	Kota               string           `db:"kota" json:"kota"`
 }
 
 // DropshipPurchaseDetail represents the detail table: dropship_purchase_details
 type DropshipPurchaseDetail struct {
-	ID                      int64   `db:"id" json:"id"`
-	KodePesanan             string  `db:"kode_pesanan" json:"kode_pesanan"`
-	SKU                     string  `db:"sku" json:"sku"`
-	NamaProduk              string  `db:"nama_produk" json:"nama_produk"`
-	HargaProduk             float64 `db:"harga_produk" json:"harga_produk"`
-	Qty                     int     `db:"qty" json:"qty"`
-	TotalHargaProduk        float64 `db:"total_harga_produk" json:"total_harga_produk"`
-	HargaProdukChannel      float64 `db:"harga_produk_channel" json:"harga_produk_channel"`
-	TotalHargaProdukChannel float64 `db:"total_harga_produk_channel" json:"total_harga_produk_channel"`
-	PotensiKeuntungan       float64 `db:"potensi_keuntungan" json:"potensi_keuntungan"`
+	ID                      int64        `db:"id" json:"id"`
+	KodePesanan             string       `db:"kode_pesanan" json:"kode_pesanan"`
+	SKU                     string       `db:"sku" json:"sku"`
+	NamaProduk              string       `db:"nama_produk" json:"nama_produk"`
+	HargaProduk             money.Amount `db:"harga_produk" json:"harga_produk"`
+	Qty                     int          `db:"qty" json:"qty"`
+	TotalHargaProduk        money.Amount `db:"total_harga_produk" json:"total_harga_produk"`
+	HargaProdukChannel      money.Amount `db:"harga_produk_channel" json:"harga_produk_channel"`
+	TotalHargaProdukChannel money.Amount `db:"total_harga_produk_channel" json:"total_harga_produk_channel"`
+	PotensiKeuntungan       money.Amount `db:"potensi_keuntungan" json:"potensi_keuntungan"`
 }
 
 // ShopeeSettledOrder represents the D1 table: shopee_settled_orders
 type ShopeeSettledOrder struct {
-	ID              int64     `db:"id" json:"id"`
-	OrderID         string    `db:"order_id" json:"order_id"`
-	NetIncome       float64   `db:"net_income" json:"net_income"`
-	ServiceFee      float64   `db:"service_fee" json:"service_fee"`
-	CampaignFee     float64   `db:"campaign_fee" json:"campaign_fee"`
-	CreditCardFee   float64   `db:"credit_card_fee" json:"credit_card_fee"`
-	ShippingSubsidy float64   `db:"shipping_subsidy" json:"shipping_subsidy"`
-	TaxImportFee    float64   `db:"tax_and_import_fee" json:"tax_and_import_fee"`
-	SettledDate     time.Time `db:"settled_date" json:"settled_date"`
-	SellerUsername  string    `db:"seller_username" json:"seller_username"`
-	CreatedAt       time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt       time.Time `db:"updated_at" json:"updated_at"`
+	ID              int64        `db:"id" json:"id"`
+	OrderID         string       `db:"order_id" json:"order_id"`
+	NetIncome       money.Amount `db:"net_income" json:"net_income"`
+	ServiceFee      money.Amount `db:"service_fee" json:"service_fee"`
+	CampaignFee     money.Amount `db:"campaign_fee" json:"campaign_fee"`
+	CreditCardFee   money.Amount `db:"credit_card_fee" json:"credit_card_fee"`
+	ShippingSubsidy money.Amount `db:"shipping_subsidy" json:"shipping_subsidy"`
+	TaxImportFee    money.Amount `db:"tax_and_import_fee" json:"tax_and_import_fee"`
+	SettledDate     time.Time    `db:"settled_date" json:"settled_date"`
+	SellerUsername  string       `db:"seller_username" json:"seller_username"`
+	CreatedAt       time.Time    `db:"created_at" json:"created_at"`
+	UpdatedAt       time.Time    `db:"updated_at" json:"updated_at"`
 }
 
 // ShopeeSettled represents rows of the shopee_settled table.
 type ShopeeSettled struct {
-	NamaToko                                         string    `db:"nama_toko" json:"nama_toko"`
-	NoPesanan                                        string    `db:"no_pesanan" json:"no_pesanan"`
-	NoPengajuan                                      string    `db:"no_pengajuan" json:"no_pengajuan"`
-	UsernamePembeli                                  string    `db:"username_pembeli" json:"username_pembeli"`
-	WaktuPesananDibuat                               time.Time `db:"waktu_pesanan_dibuat" json:"waktu_pesanan_dibuat"`
-	MetodePembayaranPembeli                          string    `db:"metode_pembayaran_pembeli" json:"metode_pembayaran_pembeli"`
-	TanggalDanaDilepaskan                            time.Time `db:"tanggal_dana_dilepaskan" json:"tanggal_dana_dilepaskan"`
-	HargaAsliProduk                                  float64   `db:"harga_asli_produk" json:"harga_asli_produk"`
-	TotalDiskonProduk                                float64   `db:"total_diskon_produk" json:"total_diskon_produk"`
-	JumlahPengembalianDanaKePembeli                  float64   `db:"jumlah_pengembalian_dana_ke_pembeli" json:"jumlah_pengembalian_dana_ke_pembeli"`
-	KomisiShopee                                     float64   `db:"diskon_produk_dari_shopee" json:"diskon_produk_dari_shopee"`
-	BiayaAdminShopee                                 float64   `db:"diskon_voucher_ditanggung_penjual" json:"diskon_voucher_ditanggung_penjual"`
-	BiayaLayanan                                     float64   `db:"cashback_koin_ditanggung_penjual" json:"cashback_koin_ditanggung_penjual"`
-	BiayaLayananEkstra                               float64   `db:"ongkir_dibayar_pembeli" json:"ongkir_dibayar_pembeli"`
-	BiayaPenyediaPembayaran                          float64   `db:"diskon_ongkir_ditanggung_jasa_kirim" json:"diskon_ongkir_ditanggung_jasa_kirim"`
-	Asuransi                                         float64   `db:"gratis_ongkir_dari_shopee" json:"gratis_ongkir_dari_shopee"`
-	TotalBiayaTransaksi                              float64   `db:"ongkir_yang_diteruskan_oleh_shopee_ke_jasa_kirim" json:"ongkir_yang_diteruskan_oleh_shopee_ke_jasa_kirim"`
-	BiayaPengiriman                                  float64   `db:"ongkos_kirim_pengembalian_barang" json:"ongkos_kirim_pengembalian_barang"`
-	TotalDiskonPengiriman                            float64   `db:"pengembalian_biaya_kirim" json:"pengembalian_biaya_kirim"`
-	PromoGratisOngkirShopee                          float64   `db:"biaya_komisi_ams" json:"biaya_komisi_ams"`
-	PromoGratisOngkirPenjual                         float64   `db:"biaya_administrasi" json:"biaya_administrasi"`
-	PromoDiskonShopee                                float64   `db:"biaya_layanan_termasuk_ppn_11" json:"biaya_layanan_termasuk_ppn_11"`
-	PromoDiskonPenjual                               float64   `db:"premi" json:"premi"`
-	CashbackShopee                                   float64   `db:"biaya_program" json:"biaya_program"`
-	CashbackPenjual                                  float64   `db:"biaya_kartu_kredit" json:"biaya_kartu_kredit"`
-	BiayaTransaksi                                   float64   `db:"biaya_transaksi" json:"biaya_transaksi"`
-	KoinShopee                                       float64   `db:"biaya_kampanye" json:"biaya_kampanye"`
-	PotonganLainnya                                  float64   `db:"bea_masuk_ppn_pph" json:"bea_masuk_ppn_pph"`
-	TotalPenerimaan                                  float64   `db:"total_penghasilan" json:"total_penghasilan"`
-	Kompensasi                                       float64   `db:"kompensasi" json:"kompensasi"`
-	PromoGratisOngkirDariPenjual                     float64   `db:"promo_gratis_ongkir_dari_penjual" json:"promo_gratis_ongkir_dari_penjual"`
-	JasaKirim                                        string    `db:"jasa_kirim" json:"jasa_kirim"`
-	NamaKurir                                        string    `db:"nama_kurir" json:"nama_kurir"`
-	PengembalianDanaKePembeli                        float64   `db:"pengembalian_dana_ke_pembeli" json:"pengembalian_dana_ke_pembeli"`
-	ProRataKoinYangDitukarkanUntukPengembalianBarang float64   `db:"pro_rata_koin_yang_ditukarkan_untuk_pengembalian_barang" json:"pro_rata_koin_yang_ditukarkan_untuk_pengembalian_barang"`
-	ProRataVoucherShopeeUntukPengembalianBarang      float64   `db:"pro_rata_voucher_shopee_untuk_pengembalian_barang" json:"pro_rata_voucher_shopee_untuk_pengembalian_barang"`
-	ProRatedBankPaymentChannelPromotionForReturns    float64   `db:"pro_rated_bank_payment_channel_promotion_for_returns" json:"pro_rated_bank_payment_channel_promotion_for_returns"`
-	ProRatedShopeePaymentChannelPromotionForReturns  float64   `db:"pro_rated_shopee_payment_channel_promotion_for_returns" json:"pro_rated_shopee_payment_channel_promotion_for_returns"`
-	IsDataMismatch                                   bool      `db:"is_data_mismatch" json:"is_data_mismatch"`
-	IsSettledConfirmed                               bool      `db:"is_settled_confirmed" json:"is_settled_confirmed"`
+	NamaToko                                         string       `db:"nama_toko" json:"nama_toko"`
+	NoPesanan                                        string       `db:"no_pesanan" json:"no_pesanan"`
+	NoPengajuan                                      string       `db:"no_pengajuan" json:"no_pengajuan"`
+	UsernamePembeli                                  string       `db:"username_pembeli" json:"username_pembeli"`
+	WaktuPesananDibuat                               time.Time    `db:"waktu_pesanan_dibuat" json:"waktu_pesanan_dibuat"`
+	MetodePembayaranPembeli                          string       `db:"metode_pembayaran_pembeli" json:"metode_pembayaran_pembeli"`
+	TanggalDanaDilepaskan                            time.Time    `db:"tanggal_dana_dilepaskan" json:"tanggal_dana_dilepaskan"`
+	HargaAsliProduk                                  money.Amount `db:"harga_asli_produk" json:"harga_asli_produk"`
+	TotalDiskonProduk                                money.Amount `db:"total_diskon_produk" json:"total_diskon_produk"`
+	JumlahPengembalianDanaKePembeli                  money.Amount `db:"jumlah_pengembalian_dana_ke_pembeli" json:"jumlah_pengembalian_dana_ke_pembeli"`
+	KomisiShopee                                     money.Amount `db:"diskon_produk_dari_shopee" json:"diskon_produk_dari_shopee"`
+	BiayaAdminShopee                                 money.Amount `db:"diskon_voucher_ditanggung_penjual" json:"diskon_voucher_ditanggung_penjual"`
+	BiayaLayanan                                     money.Amount `db:"cashback_koin_ditanggung_penjual" json:"cashback_koin_ditanggung_penjual"`
+	BiayaLayananEkstra                               money.Amount `db:"ongkir_dibayar_pembeli" json:"ongkir_dibayar_pembeli"`
+	BiayaPenyediaPembayaran                          money.Amount `db:"diskon_ongkir_ditanggung_jasa_kirim" json:"diskon_ongkir_ditanggung_jasa_kirim"`
+	Asuransi                                         money.Amount `db:"gratis_ongkir_dari_shopee" json:"gratis_ongkir_dari_shopee"`
+	TotalBiayaTransaksi                              money.Amount `db:"ongkir_yang_diteruskan_oleh_shopee_ke_jasa_kirim" json:"ongkir_yang_diteruskan_oleh_shopee_ke_jasa_kirim"`
+	BiayaPengiriman                                  money.Amount `db:"ongkos_kirim_pengembalian_barang" json:"ongkos_kirim_pengembalian_barang"`
+	TotalDiskonPengiriman                            money.Amount `db:"pengembalian_biaya_kirim" json:"pengembalian_biaya_kirim"`
+	PromoGratisOngkirShopee                          money.Amount `db:"biaya_komisi_ams" json:"biaya_komisi_ams"`
+	PromoGratisOngkirPenjual                         money.Amount `db:"biaya_administrasi" json:"biaya_administrasi"`
+	PromoDiskonShopee                                money.Amount `db:"biaya_layanan_termasuk_ppn_11" json:"biaya_layanan_termasuk_ppn_11"`
+	PromoDiskonPenjual                               money.Amount `db:"premi" json:"premi"`
+	CashbackShopee                                   money.Amount `db:"biaya_program" json:"biaya_program"`
+	CashbackPenjual                                  money.Amount `db:"biaya_kartu_kredit" json:"biaya_kartu_kredit"`
+	BiayaTransaksi                                   money.Amount `db:"biaya_transaksi" json:"biaya_transaksi"`
+	KoinShopee                                       money.Amount `db:"biaya_kampanye" json:"biaya_kampanye"`
+	PotonganLainnya                                  money.Amount `db:"bea_masuk_ppn_pph" json:"bea_masuk_ppn_pph"`
+	TotalPenerimaan                                  money.Amount `db:"total_penghasilan" json:"total_penghasilan"`
+	Kompensasi                                       money.Amount `db:"kompensasi" json:"kompensasi"`
+	PromoGratisOngkirDariPenjual                     money.Amount `db:"promo_gratis_ongkir_dari_penjual" json:"promo_gratis_ongkir_dari_penjual"`
+	JasaKirim                                        string       `db:"jasa_kirim" json:"jasa_kirim"`
+	NamaKurir                                        string       `db:"nama_kurir" json:"nama_kurir"`
+	PengembalianDanaKePembeli                        money.Amount `db:"pengembalian_dana_ke_pembeli" json:"pengembalian_dana_ke_pembeli"`
+	ProRataKoinYangDitukarkanUntukPengembalianBarang money.Amount `db:"pro_rata_koin_yang_ditukarkan_untuk_pengembalian_barang" json:"pro_rata_koin_yang_ditukarkan_untuk_pengembalian_barang"`
+	ProRataVoucherShopeeUntukPengembalianBarang      money.Amount `db:"pro_rata_voucher_shopee_untuk_pengembalian_barang" json:"pro_rata_voucher_shopee_untuk_pengembalian_barang"`
+	ProRatedBankPaymentChannelPromotionForReturns    money.Amount `db:"pro_rated_bank_payment_channel_promotion_for_returns" json:"pro_rated_bank_payment_channel_promotion_for_returns"`
+	ProRatedShopeePaymentChannelPromotionForReturns  money.Amount `db:"pro_rated_shopee_payment_channel_promotion_for_returns" json:"pro_rated_shopee_payment_channel_promotion_for_returns"`
+	IsDataMismatch                                   bool         `db:"is_data_mismatch" json:"is_data_mismatch"`
+	IsSettledConfirmed                               bool         `db:"is_settled_confirmed" json:"is_settled_confirmed"`
 }
 
 // JournalEntry represents the D7 header table: journal_entries
@@ -124,17 +131,18 @@ type JournalEntry struct {
 	SourceID     string    `db:"source_id" json:"source_id"`
 	ShopUsername string    `db:"shop_username" json:"shop_username"`
 	Store        string    `db:"store" json:"store"`
+	EntityID     *int64    `db:"entity_id" json:"entity_id"` // nil: the store's entity
 	CreatedAt    time.Time `db:"created_at" json:"created_at"`
 }
 
 // JournalLine represents the D7 detail table: journal_lines
 type JournalLine struct {
-	LineID    int64   `db:"line_id" json:"line_id"`
-	JournalID int64   `db:"journal_id" json:"journal_id"` // FK → journal_entries(journal_id)
-	AccountID int64   `db:"account_id" json:"account_id"` // FK → accounts(account_id)
-	IsDebit   bool    `db:"is_debit" json:"is_debit"`
-	Amount    float64 `db:"amount" json:"amount"`
-	Memo      *string `db:"memo" json:"memo"` // NULLABLE
+	LineID    int64        `db:"line_id" json:"line_id"`
+	JournalID int64        `db:"journal_id" json:"journal_id"` // FK → journal_entries(journal_id)
+	AccountID int64        `db:"account_id" json:"account_id"` // FK → accounts(account_id)
+	IsDebit   bool         `db:"is_debit" json:"is_debit"`
+	Amount    money.Amount `db:"amount" json:"amount"`
+	Memo      *string      `db:"memo" json:"memo"` // NULLABLE
 }
 
 // ReconciledTransaction represents the D3 table: reconciled_transactions
@@ -159,33 +167,55 @@ type ReconcileCandidate struct {
 	ShopeeOrderStatus     string  `json:"shopee_order_status"`
 }
 
+// Statuses of a FailedReconciliation.
+const (
+	FailedReconPending        = "pending"         // waiting for its next retry
+	FailedReconResolved       = "resolved"        // a retry succeeded
+	FailedReconNeedsAttention = "needs_attention" // permanent failure or out of attempts
+)
+
 // FailedReconciliation represents transactions that failed during reconciliation
 // and need to be reviewed or retried later.
 type FailedReconciliation struct {
-	ID         int64      `db:"id" json:"id"`
-	PurchaseID string     `db:"purchase_id" json:"purchase_id"`
-	OrderID    *string    `db:"order_id" json:"order_id"`
-	Shop       string     `db:"shop" json:"shop"`
-	ErrorType  string     `db:"error_type" json:"error_type"`
-	ErrorMsg   string     `db:"error_message" json:"error_message"`
-	Context    *string    `db:"context" json:"context"` // Additional context as JSON
-	FailedAt   time.Time  `db:"failed_at" json:"failed_at"`
-	Retried    bool       `db:"retried" json:"retried"`
-	RetriedAt  *time.Time `db:"retried_at" json:"retried_at"`
-	BatchID    *int64     `db:"batch_id" json:"batch_id"` // FK to batch_history if part of batch
+	ID            int64      `db:"id" json:"id"`
+	PurchaseID    string     `db:"purchase_id" json:"purchase_id"`
+	OrderID       *string    `db:"order_id" json:"order_id"`
+	Shop          string     `db:"shop" json:"shop"`
+	ErrorType     string     `db:"error_type" json:"error_type"`
+	ErrorMsg      string     `db:"error_message" json:"error_message"`
+	Context       *string    `db:"context" json:"context"` // Additional context as JSON
+	FailedAt      time.Time  `db:"failed_at" json:"failed_at"`
+	BatchID       *int64     `db:"batch_id" json:"batch_id"` // FK to batch_history if part of batch
+	Status        string     `db:"status" json:"status"`
+	Attempts      int        `db:"attempts" json:"attempts"`
+	MaxAttempts   int        `db:"max_attempts" json:"max_attempts"`
+	NextRetryAt   *time.Time `db:"next_retry_at" json:"next_retry_at"`
+	LastAttemptAt *time.Time `db:"last_attempt_at" json:"last_attempt_at"`
+	ResolvedAt    *time.Time `db:"resolved_at" json:"resolved_at"`
+}
+
+// FailedReconciliationAttempt is one retry of a FailedReconciliation.
+type FailedReconciliationAttempt struct {
+	ID                     int64     `db:"id" json:"id"`
+	FailedReconciliationID int64     `db:"failed_reconciliation_id" json:"failed_reconciliation_id"`
+	Attempt                int       `db:"attempt" json:"attempt"`
+	Succeeded              bool      `db:"succeeded" json:"succeeded"`
+	ErrorType              *string   `db:"error_type" json:"error_type"`
+	ErrorMsg               *string   `db:"error_message" json:"error_message"`
+	AttemptedAt            time.Time `db:"attempted_at" json:"attempted_at"`
 }
 
 // CachedMetric represents the D5 table: cached_metrics
 type CachedMetric struct {
-	ID                int64     `db:"id" json:"id"`
-	ShopUsername      string    `db:"shop_username" json:"shop_username"`
-	Period            string    `db:"period" json:"period"` // e.g., "2025-05"
-	SumRevenue        float64   `db:"sum_revenue" json:"sum_revenue"`
-	SumCOGS           float64   `db:"sum_cogs" json:"sum_cogs"`
-	SumFees           float64   `db:"sum_fees" json:"sum_fees"`
-	NetProfit         float64   `db:"net_profit" json:"net_profit"`
-	EndingCashBalance float64   `db:"ending_cash_balance" json:"ending_cash_balance"`
-	UpdatedAt         time.Time `db:"updated_at" json:"updated_at"`
+	ID                int64        `db:"id" json:"id"`
+	ShopUsername      string       `db:"shop_username" json:"shop_username"`
+	Period            string       `db:"period" json:"period"` // e.g., "2025-05"
+	SumRevenue        money.Amount `db:"sum_revenue" json:"sum_revenue"`
+	SumCOGS           money.Amount `db:"sum_cogs" json:"sum_cogs"`
+	SumFees           money.Amount `db:"sum_fees" json:"sum_fees"`
+	NetProfit         money.Amount `db:"net_profit" json:"net_profit"`
+	EndingCashBalance money.Amount `db:"ending_cash_balance" json:"ending_cash_balance"`
+	UpdatedAt         time.Time    `db:"updated_at" json:"updated_at"`
 }
 
 // JenisChannel represents e-commerce channel types such as Shopee or Tokopedia.
@@ -199,6 +229,7 @@ type Store struct {
 	StoreID        int64      `db:"store_id" json:"store_id"`
 	JenisChannelID int64      `db:"jenis_channel_id" json:"jenis_channel_id"`
 	NamaToko       string     `db:"nama_toko" json:"nama_toko"`
+	EntityID       *int64     `db:"entity_id" json:"entity_id"`
 	CodeID         *string    `db:"code_id" json:"code_id"`
 	ShopID         *string    `db:"shop_id" json:"shop_id"`
 	AccessToken    *string    `db:"access_token" json:"-"`
@@ -314,14 +345,14 @@ type Withdrawal struct {
 
 // ShopeeAdjustment records adjustment entries from Shopee income reports.
 type ShopeeAdjustment struct {
-	ID                 int64     `db:"id" json:"id"`
-	NamaToko           string    `db:"nama_toko" json:"nama_toko"`
-	TanggalPenyesuaian time.Time `db:"tanggal_penyesuaian" json:"tanggal_penyesuaian"`
-	TipePenyesuaian    string    `db:"tipe_penyesuaian" json:"tipe_penyesuaian"`
-	AlasanPenyesuaian  string    `db:"alasan_penyesuaian" json:"alasan_penyesuaian"`
-	BiayaPenyesuaian   float64   `db:"biaya_penyesuaian" json:"biaya_penyesuaian"`
-	NoPesanan          string    `db:"no_pesanan" json:"no_pesanan"`
-	CreatedAt          time.Time `db:"created_at" json:"created_at"`
+	ID                 int64        `db:"id" json:"id"`
+	NamaToko           string       `db:"nama_toko" json:"nama_toko"`
+	TanggalPenyesuaian time.Time    `db:"tanggal_penyesuaian" json:"tanggal_penyesuaian"`
+	TipePenyesuaian    string       `db:"tipe_penyesuaian" json:"tipe_penyesuaian"`
+	AlasanPenyesuaian  string       `db:"alasan_penyesuaian" json:"alasan_penyesuaian"`
+	BiayaPenyesuaian   money.Amount `db:"biaya_penyesuaian" json:"biaya_penyesuaian"`
+	NoPesanan          string       `db:"no_pesanan" json:"no_pesanan"`
+	CreatedAt          time.Time    `db:"created_at" json:"created_at"`
 }
 
 // ShopeeOrderDetailRow stores key fields from Shopee order detail.
@@ -415,9 +446,10 @@ type ShopeeOrderPackageRow struct {
 
 // ReconcileBatchInfo contains information about created reconcile batches
 type ReconcileBatchInfo struct {
-	BatchCount        int    `json:"batch_count"`
-	TotalTransactions int    `json:"total_transactions"`
-	MasterBatchID     *int64 `json:"master_batch_id,omitempty"`
+	BatchCount        int     `json:"batch_count"`
+	TotalTransactions int     `json:"total_transactions"`
+	MasterBatchID     *int64  `json:"master_batch_id,omitempty"`
+	BatchIDs          []int64 `json:"batch_ids,omitempty"`
 }
 
 // ReconciliationReport provides a summary of reconciliation results
@@ -425,21 +457,39 @@ type ReconciliationReport struct {
 	TotalTransactions      int                    `json:"total_transactions"`
 	SuccessfulTransactions int                    `json:"successful_transactions"`
 	FailedTransactions     int                    `json:"failed_transactions"`
+	SkippedTransactions    int                    `json:"skipped_transactions"` // permanent failures not retried
 	FailureRate            float64                `json:"failure_rate"`
 	ProcessingStartTime    time.Time              `json:"processing_start_time"`
 	ProcessingEndTime      time.Time              `json:"processing_end_time"`
 	Duration               string                 `json:"duration"`
 	FailureCategories      map[string]int         `json:"failure_categories"`
 	FailedTransactionList  []FailedReconciliation `json:"failed_transaction_list,omitempty"`
+	Halted                 bool                   `json:"halted,omitempty"` // stopped early by the store's policy
 }
 
 // ReconciliationConfig holds configuration options for error handling behavior
 type ReconciliationConfig struct {
-	MaxAllowedFailures      int      `json:"max_allowed_failures"`      // Stop process if failures exceed this
-	FailureThresholdPercent float64  `json:"failure_threshold_percent"` // Alert if failure rate exceeds this
-	CriticalErrorTypes      []string `json:"critical_error_types"`      // Error types that should halt processing
-	RetryFailedTransactions bool     `json:"retry_failed_transactions"` // Whether to automatically retry failed transactions
-	GenerateDetailedReport  bool     `json:"generate_detailed_report"`  // Whether to include failed transaction details in report
+	MaxAllowedFailures      int            `db:"max_allowed_failures" json:"max_allowed_failures"`           // Stop process if failures exceed this
+	FailureThresholdPercent float64        `db:"failure_threshold_percent" json:"failure_threshold_percent"` // Alert if failure rate exceeds this
+	CriticalErrorTypes      pq.StringArray `db:"critical_error_types" json:"critical_error_types"`           // Error types that should halt processing
+	RetryFailedTransactions bool           `db:"retry_failed_transactions" json:"retry_failed_transactions"` // Whether to automatically retry failed transactions
+	GenerateDetailedReport  bool           `db:"generate_detailed_report" json:"generate_detailed_report"`   // Whether to include failed transaction details in report
+	// AmountTolerance is the largest escrow difference booked as selisih
+	// instead of failing the settlement as unbalanced.
+	AmountTolerance money.Amount `db:"amount_tolerance" json:"amount_tolerance"`
+	// ShippingDiscrepancyThreshold is the shipping fee difference above
+	// which a row is recorded in shipping_discrepancies.
+	ShippingDiscrepancyThreshold money.Amount `db:"shipping_discrepancy_threshold" json:"shipping_discrepancy_threshold"`
+}
+
+// ReconciliationPolicy is a store's row in reconciliation_policies.
+type ReconciliationPolicy struct {
+	Store string `db:"store" json:"store"`
+	ReconciliationConfig
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+	// IsDefault is set when the store has no row and the defaults apply.
+	IsDefault bool `db:"-" json:"is_default"`
 }
 
 // ShopeeOrderReturn represents a return from Shopee's get_return_list API
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
+)
+
+// Supplier refund statuses of a return: whether Jakmall gave us back the
+// product cost of the returned goods.
+const (
+	SupplierRefundPending  = "pending"
+	SupplierRefundRefunded = "refunded"
+	SupplierRefundRejected = "rejected"
+)
+
+// OrderReturn is a marketplace return request stored in order_returns.
+type OrderReturn struct {
+	ID                       int64          `db:"id" json:"id"`
+	ReturnSN                 string         `db:"return_sn" json:"return_sn"`
+	OrderSN                  string         `db:"order_sn" json:"order_sn"`
+	Store                    string         `db:"store" json:"store"`
+	KodePesanan              *string        `db:"kode_pesanan" json:"kode_pesanan"`
+	Status                   string         `db:"status" json:"status"`
+	NegotiationStatus        string         `db:"negotiation_status" json:"negotiation_status"`
+	SellerProofStatus        string         `db:"seller_proof_status" json:"seller_proof_status"`
+	SellerCompensationStatus string         `db:"seller_compensation_status" json:"seller_compensation_status"`
+	Reason                   string         `db:"reason" json:"reason"`
+	RefundAmount             money.Amount   `db:"refund_amount" json:"refund_amount"`
+	Currency                 string         `db:"currency" json:"currency"`
+	BuyerUsername            string         `db:"buyer_username" json:"buyer_username"`
+	ImageURLs                pq.StringArray `db:"image_urls" json:"image_urls"`
+	SellerProofURLs          pq.StringArray `db:"seller_proof_urls" json:"seller_proof_urls"`
+	ReturnCreatedAt          time.Time      `db:"return_created_at" json:"return_created_at"`
+	ReturnUpdatedAt          time.Time      `db:"return_updated_at" json:"return_updated_at"`
+	DueDate                  *time.Time     `db:"due_date" json:"due_date"`
+	SupplierRefundStatus     string         `db:"supplier_refund_status" json:"supplier_refund_status"`
+	SupplierRefundAmount     money.Amount   `db:"supplier_refund_amount" json:"supplier_refund_amount"`
+	SupplierRefundedAt       *time.Time     `db:"supplier_refunded_at" json:"supplier_refunded_at"`
+	SupplierRefundNote       *string        `db:"supplier_refund_note" json:"supplier_refund_note"`
+	SupplierJournalID        *int64         `db:"supplier_journal_id" json:"supplier_journal_id"`
+	CreatedAt                time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt                time.Time      `db:"updated_at" json:"updated_at"`
+
+	Items []OrderReturnItem `db:"-" json:"items,omitempty"`
+}
+
+// OrderReturnItem is one returned product line.
+type OrderReturnItem struct {
+	ID         int64        `db:"id" json:"id"`
+	ReturnID   int64        `db:"return_id" json:"return_id"`
+	ItemID     int64        `db:"item_id" json:"item_id"`
+	ModelID    int64        `db:"model_id" json:"model_id"`
+	ItemName   string       `db:"item_name" json:"item_name"`
+	ItemSKU    string       `db:"item_sku" json:"item_sku"`
+	ModelName  string       `db:"model_name" json:"model_name"`
+	ModelSKU   string       `db:"model_sku" json:"model_sku"`
+	Qty        int          `db:"qty" json:"qty"`
+	ItemPrice  money.Amount `db:"item_price" json:"item_price"`
+	IsMainItem bool         `db:"is_main_item" json:"is_main_item"`
+}
+
+// ReturnFilter narrows a list of returns. Empty fields match everything.
+type ReturnFilter struct {
+	Store                string
+	Status               string
+	SupplierRefundStatus string
+	OrderSN              string
+	From                 *time.Time
+	To                   *time.Time
+}
+
+// ReturnLoss aggregates returned goods per store and SKU. Cost is the
+// supplier price of the returned quantity; Refunded is the share of supplier
+// refunds allocated to it and Loss what remains unrecovered.
+type ReturnLoss struct {
+	Store    string       `db:"store" json:"store"`
+	SKU      string       `db:"sku" json:"sku"`
+	ItemName string       `db:"item_name" json:"item_name"`
+	Qty      int          `db:"qty" json:"qty"`
+	Returns  int          `db:"returns" json:"returns"`
+	Cost     money.Amount `db:"cost" json:"cost"`
+	Refunded money.Amount `db:"refunded" json:"refunded"`
+	Loss     money.Amount `db:"loss" json:"loss"`
+}
+
+// SupplierRefundInput records the supplier's answer to a return. Amount and
+// Date only apply when Status is SupplierRefundRefunded.
+type SupplierRefundInput struct {
+	Status string       `json:"status"`
+	Amount money.Amount `json:"amount"`
+	Date   *time.Time   `json:"date"`
+	Note   *string      `json:"note"`
+}
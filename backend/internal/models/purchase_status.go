@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/lifecycle"
+)
+
+// PurchaseStatusEvent is one row of purchase_status_history: a lifecycle
+// transition of a dropship purchase.
+type PurchaseStatusEvent struct {
+	ID          int64             `db:"id" json:"id"`
+	KodePesanan string            `db:"kode_pesanan" json:"kode_pesanan"`
+	FromStatus  *lifecycle.Status `db:"from_status" json:"from_status"`
+	ToStatus    lifecycle.Status  `db:"to_status" json:"to_status"`
+	Label       string            `db:"label" json:"label"`
+	Cause       lifecycle.Cause   `db:"cause" json:"cause"`
+	Note        *string           `db:"note" json:"note,omitempty"`
+	ChangedAt   time.Time         `db:"changed_at" json:"changed_at"`
+	CreatedAt   time.Time         `db:"created_at" json:"created_at"`
+}
+
+// PurchaseTimeline is an order's current state together with its history.
+type PurchaseTimeline struct {
+	KodePesanan        string                `json:"kode_pesanan"`
+	KodeInvoiceChannel string                `json:"kode_invoice_channel"`
+	Status             lifecycle.Status      `json:"status"`
+	Label              string                `json:"label"`
+	Terminal           bool                  `json:"terminal"`
+	Next               []lifecycle.Status    `json:"next"`
+	Events             []PurchaseStatusEvent `json:"events"`
+}
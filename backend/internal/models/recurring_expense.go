@@ -0,0 +1,88 @@
+package models
+
+import (
+	"time"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
+)
+
+// Recurring expense modes.
+const (
+	// RecurringCash books the whole amount as an expense on each run.
+	RecurringCash = "cash"
+	// RecurringPrepaid books each payment to a prepaid asset account and
+	// moves it to the expense accounts in equal monthly slices.
+	RecurringPrepaid = "prepaid"
+	// RecurringAccrued recognises the expense on each run against an accrued
+	// liability account; the liability is cleared when the bill is paid.
+	RecurringAccrued = "accrued"
+)
+
+// RecurringExpense is a template for an expense that repeats on a cron
+// schedule, such as rent, subscriptions or salaries.
+type RecurringExpense struct {
+	ID                int64                  `db:"id" json:"id"`
+	Name              string                 `db:"name" json:"name"`
+	Description       string                 `db:"description" json:"description"`
+	Schedule          string                 `db:"schedule" json:"schedule"`
+	AssetAccountID    int64                  `db:"asset_account_id" json:"asset_account_id"`
+	Store             string                 `db:"store" json:"store"`
+	Mode              string                 `db:"mode" json:"mode"`
+	DeferralAccountID *int64                 `db:"deferral_account_id" json:"deferral_account_id"`
+	SpreadMonths      int                    `db:"spread_months" json:"spread_months"`
+	StartDate         time.Time              `db:"start_date" json:"start_date"`
+	EndDate           *time.Time             `db:"end_date" json:"end_date"`
+	Enabled           bool                   `db:"enabled" json:"enabled"`
+	NextRunAt         *time.Time             `db:"next_run_at" json:"next_run_at"`
+	LastRunAt         *time.Time             `db:"last_run_at" json:"last_run_at"`
+	CreatedAt         time.Time              `db:"created_at" json:"created_at"`
+	UpdatedAt         time.Time              `db:"updated_at" json:"updated_at"`
+	Lines             []RecurringExpenseLine `json:"lines"`
+}
+
+// Total returns the sum of the template's lines.
+func (r *RecurringExpense) Total() money.Amount {
+	var t money.Amount
+	for _, l := range r.Lines {
+		t += l.Amount
+	}
+	return t
+}
+
+// RecurringExpenseLine is one expense account charged by a template.
+type RecurringExpenseLine struct {
+	ID                 int64        `db:"id" json:"id"`
+	RecurringExpenseID int64        `db:"recurring_expense_id" json:"recurring_expense_id"`
+	AccountID          int64        `db:"account_id" json:"account_id"`
+	Amount             money.Amount `db:"amount" json:"amount"`
+}
+
+// RecurringExpenseRun records one materialised occurrence of a template.
+type RecurringExpenseRun struct {
+	ID                 int64        `db:"id" json:"id"`
+	RecurringExpenseID int64        `db:"recurring_expense_id" json:"recurring_expense_id"`
+	ScheduledFor       time.Time    `db:"scheduled_for" json:"scheduled_for"`
+	Mode               string       `db:"mode" json:"mode"`
+	Amount             money.Amount `db:"amount" json:"amount"`
+	ExpenseID          *string      `db:"expense_id" json:"expense_id"`
+	JournalID          *int64       `db:"journal_id" json:"journal_id"`
+	SettledExpenseID   *string      `db:"settled_expense_id" json:"settled_expense_id"`
+	CreatedAt          time.Time    `db:"created_at" json:"created_at"`
+}
+
+// RecurringExpenseRecognition is the monthly slice of a prepaid run charged
+// to one expense account. The deferral account, store and description are
+// copied from the template when the run is made, so later edits do not
+// change how an existing prepayment is released. JournalID is set once the
+// slice has been posted.
+type RecurringExpenseRecognition struct {
+	ID                int64        `db:"id" json:"id"`
+	RunID             int64        `db:"run_id" json:"run_id"`
+	DueDate           time.Time    `db:"due_date" json:"due_date"`
+	AccountID         int64        `db:"account_id" json:"account_id"`
+	DeferralAccountID int64        `db:"deferral_account_id" json:"deferral_account_id"`
+	Store             string       `db:"store" json:"store"`
+	Description       string       `db:"description" json:"description"`
+	Amount            money.Amount `db:"amount" json:"amount"`
+	JournalID         *int64       `db:"journal_id" json:"journal_id"`
+}
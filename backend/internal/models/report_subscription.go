@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Report delivery statuses.
+const (
+	DeliveryPending  = "pending"
+	DeliverySent     = "sent"
+	DeliveryRetrying = "retrying"
+	DeliveryFailed   = "failed"
+)
+
+// ReportSubscription schedules a report to be rendered and delivered to a
+// sink (email, webhook or local directory).
+type ReportSubscription struct {
+	ID         int64      `db:"id" json:"id"`
+	Name       string     `db:"name" json:"name"`
+	Report     string     `db:"report" json:"report"`
+	Format     string     `db:"format" json:"format"`
+	Store      string     `db:"store" json:"store"`
+	Channel    string     `db:"channel" json:"channel"`
+	Period     string     `db:"period" json:"period"`
+	Comparison bool       `db:"comparison" json:"comparison"`
+	Schedule   string     `db:"schedule" json:"schedule"`
+	SinkType   string     `db:"sink_type" json:"sink_type"`
+	SinkTarget string     `db:"sink_target" json:"sink_target"`
+	Enabled    bool       `db:"enabled" json:"enabled"`
+	NextRunAt  *time.Time `db:"next_run_at" json:"next_run_at"`
+	LastRunAt  *time.Time `db:"last_run_at" json:"last_run_at"`
+	// ClaimedUntil is set while a scheduler run holds the subscription.
+	ClaimedUntil *time.Time `db:"claimed_until" json:"-"`
+	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// ReportDelivery records one attempt series to deliver a scheduled report.
+type ReportDelivery struct {
+	ID             int64      `db:"id" json:"id"`
+	SubscriptionID int64      `db:"subscription_id" json:"subscription_id"`
+	ScheduledFor   time.Time  `db:"scheduled_for" json:"scheduled_for"`
+	Status         string     `db:"status" json:"status"`
+	Attempts       int        `db:"attempts" json:"attempts"`
+	FileName       *string    `db:"file_name" json:"file_name"`
+	LastError      *string    `db:"last_error" json:"last_error"`
+	NextRetryAt    *time.Time `db:"next_retry_at" json:"next_retry_at"`
+	DeliveredAt    *time.Time `db:"delivered_at" json:"delivered_at"`
+	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time  `db:"updated_at" json:"updated_at"`
+}
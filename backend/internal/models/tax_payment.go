@@ -1,15 +1,48 @@
 package models
 
-import "time"
+import (
+	"time"
 
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
+)
+
+// Taxes a store can owe for a period.
+const (
+	// TaxPPhFinal is PPh Final UMKM on gross revenue (PP 55/2022).
+	TaxPPhFinal = "pph_final"
+	// TaxPPh25 is the monthly income tax instalment under the normal regime.
+	TaxPPh25 = "pph_25"
+	// TaxPPN is VAT collected by a PKP.
+	TaxPPN = "ppn"
+)
+
+// TaxPayment is a store's obligation for one tax and period, paid or not.
+// Revenue is the period's gross revenue; TaxBase is the part the rate was
+// applied to after ExemptAmount (or the profit, for PPh 25). For PPN,
+// TaxAmount is OutputTax less the InputTax set off against it, and
+// OutputBooked is how much of OutputTax has been booked to Utang PPN.
 type TaxPayment struct {
-	ID          string    `db:"id" json:"id"`
-	Store       string    `db:"store" json:"store"`
-	PeriodType  string    `db:"period_type" json:"period_type"`
-	PeriodValue string    `db:"period_value" json:"period_value"`
-	Revenue     float64   `db:"revenue" json:"revenue"`
-	TaxRate     float64   `db:"tax_rate" json:"tax_rate"`
-	TaxAmount   float64   `db:"tax_amount" json:"tax_amount"`
-	IsPaid      bool      `db:"is_paid" json:"is_paid"`
-	PaidAt      time.Time `db:"paid_at" json:"paid_at"`
+	ID                string       `db:"id" json:"id"`
+	Store             string       `db:"store" json:"store"`
+	EntityID          *int64       `db:"entity_id" json:"entity_id"`
+	PeriodType        string       `db:"period_type" json:"period_type"`
+	PeriodValue       string       `db:"period_value" json:"period_value"`
+	TaxType           string       `db:"tax_type" json:"tax_type"`
+	Regime            string       `db:"regime" json:"regime"`
+	Revenue           money.Amount `db:"revenue" json:"revenue"`
+	CumulativeRevenue money.Amount `db:"cumulative_revenue" json:"cumulative_revenue"`
+	ExemptAmount      money.Amount `db:"exempt_amount" json:"exempt_amount"`
+	TaxBase           money.Amount `db:"tax_base" json:"tax_base"`
+	TaxRate           float64      `db:"tax_rate" json:"tax_rate"`
+	TaxAmount         money.Amount `db:"tax_amount" json:"tax_amount"`
+	OutputTax         money.Amount `db:"output_tax" json:"output_tax"`
+	InputTax          money.Amount `db:"input_tax" json:"input_tax"`
+	OutputBooked      money.Amount `db:"output_booked" json:"-"`
+	DueDate           *time.Time   `db:"due_date" json:"due_date"`
+	IsPaid            bool         `db:"is_paid" json:"is_paid"`
+	PaidAt            *time.Time   `db:"paid_at" json:"paid_at"`
+	RemindedAt        *time.Time   `db:"reminded_at" json:"reminded_at"`
+	// Note explains how the amount was reached, e.g. that the UMKM
+	// regime lapsed. It is not stored.
+	Note string `db:"-" json:"note,omitempty"`
 }
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"time"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
+)
+
+// Income tax regimes.
+const (
+	// TaxRegimeUMKM taxes gross revenue at a final 0.5%.
+	TaxRegimeUMKM = "pph_final_umkm"
+	// TaxRegimeNormal taxes profit at the Pasal 17 rates, paid in monthly
+	// PPh 25 instalments.
+	TaxRegimeNormal = "pph_normal"
+)
+
+// Taxpayer types. Individuals get the Rp500 million UMKM threshold and the
+// progressive Pasal 17 rates; corporations pay the flat corporate rate.
+const (
+	TaxpayerIndividual = "individual"
+	TaxpayerCorporate  = "corporate"
+)
+
+// TaxRegime is the tax treatment of a store from EffectiveFrom until
+// EffectiveTo (open ended when nil). Rate overrides the regime's default
+// rate when set.
+type TaxRegime struct {
+	ID            int64      `db:"id" json:"id"`
+	Store         string     `db:"store" json:"store"`
+	Regime        string     `db:"regime" json:"regime"`
+	TaxpayerType  string     `db:"taxpayer_type" json:"taxpayer_type"`
+	PKP           bool       `db:"pkp" json:"pkp"`
+	Rate          *float64   `db:"rate" json:"rate"`
+	PPNRate       float64    `db:"ppn_rate" json:"ppn_rate"`
+	EffectiveFrom time.Time  `db:"effective_from" json:"effective_from"`
+	EffectiveTo   *time.Time `db:"effective_to" json:"effective_to"`
+	Notes         string     `db:"notes" json:"notes"`
+	CreatedAt     time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt     time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// TaxMonth is one month of a store's tax year with its running revenue.
+type TaxMonth struct {
+	Period            string       `json:"period"`
+	Regime            string       `json:"regime"`
+	Revenue           money.Amount `json:"revenue"`
+	CumulativeRevenue money.Amount `json:"cumulative_revenue"`
+	ExemptAmount      money.Amount `json:"exempt_amount"`
+	NetProfit         money.Amount `json:"net_profit"`
+	PPhFinal          money.Amount `json:"pph_final"`
+	PPh25             money.Amount `json:"pph_25"`
+	PPN               money.Amount `json:"ppn"`
+	Paid              money.Amount `json:"paid"`
+}
+
+// TaxAnnualSummary collects what a store needs for its annual SPT.
+type TaxAnnualSummary struct {
+	Store        string     `json:"store"`
+	Year         int        `json:"year"`
+	TaxpayerType string     `json:"taxpayer_type"`
+	Months       []TaxMonth `json:"months"`
+
+	Revenue   money.Amount `json:"revenue"`
+	NetProfit money.Amount `json:"net_profit"`
+	// ExemptRevenue is the part of revenue under the individual UMKM
+	// threshold.
+	ExemptRevenue money.Amount `json:"exempt_revenue"`
+	// ThresholdMonth is the first month revenue passed the individual
+	// threshold, empty if it never did.
+	ThresholdMonth string `json:"threshold_month"`
+	// UMKMLimitExceeded is set when revenue passed the Rp4.8 billion UMKM
+	// limit; the normal regime applies from the next year.
+	UMKMLimitExceeded bool `json:"umkm_limit_exceeded"`
+
+	PPhFinal money.Amount `json:"pph_final"`
+	PPh25    money.Amount `json:"pph_25"`
+	PPN      money.Amount `json:"ppn"`
+	Paid     money.Amount `json:"paid"`
+	// AnnualPPh is the income tax due on the year's profit for months under
+	// the normal regime; PPh29 is what remains after the PPh 25
+	// instalments, negative when overpaid.
+	AnnualPPh money.Amount `json:"annual_pph"`
+	PPh29     money.Amount `json:"pph_29"`
+	SPTDue    time.Time    `json:"spt_due"`
+}
@@ -0,0 +1,395 @@
+// Package money provides Amount, an exact fixed-point Rupiah value with two
+// decimal places. Amounts are stored as a count of sen (1/100 Rupiah), so
+// sums never pick up the rounding drift that float64 accumulates.
+//
+// Amount scans from and writes to Postgres NUMERIC without going through
+// float64, and encodes to JSON as a decimal string such as "1234.50".
+package money
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Scale is the number of sen in one Rupiah.
+const Scale = 100
+
+// Amount is a Rupiah value counted in sen.
+type Amount int64
+
+// Zero is the zero Amount.
+const Zero Amount = 0
+
+// New returns the Amount of whole Rupiah.
+func New(rupiah int64) Amount {
+	return Amount(rupiah * Scale)
+}
+
+// FromSen returns the Amount of sen.
+func FromSen(sen int64) Amount {
+	return Amount(sen)
+}
+
+// FromFloat converts f to the nearest sen, rounding halves away from zero.
+// Use it only at boundaries where a value arrives as a float (external APIs,
+// ratios); never to carry amounts between calculations.
+func FromFloat(f float64) Amount {
+	return Amount(math.Round(f * Scale))
+}
+
+// Sen returns a as a count of sen.
+func (a Amount) Sen() int64 { return int64(a) }
+
+// Float64 returns a in Rupiah as a float, for ratios and charts only.
+func (a Amount) Float64() float64 { return float64(a) / Scale }
+
+// IsZero reports whether a is zero.
+func (a Amount) IsZero() bool { return a == 0 }
+
+// Abs returns the absolute value of a.
+func (a Amount) Abs() Amount {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+// Mul returns a multiplied by f, rounded to the nearest sen. It is meant
+// for rates and percentages such as a tax rate of 0.005.
+func (a Amount) Mul(f float64) Amount {
+	return Amount(math.Round(float64(a) * f))
+}
+
+// Ratio returns a / b as a float, or 0 when b is zero.
+func (a Amount) Ratio(b Amount) float64 {
+	if b == 0 {
+		return 0
+	}
+	return float64(a) / float64(b)
+}
+
+// Sum adds up amounts.
+func Sum(amounts ...Amount) Amount {
+	var t Amount
+	for _, a := range amounts {
+		t += a
+	}
+	return t
+}
+
+// Split divides a into n parts that add up to a exactly. The sen left over
+// by integer division go to the first parts, so parts differ by at most one
+// sen. It returns nil when n is not positive.
+func (a Amount) Split(n int) []Amount {
+	if n <= 0 {
+		return nil
+	}
+	parts := make([]Amount, n)
+	base, rem := int64(a)/int64(n), int64(a)%int64(n)
+	for i := range parts {
+		parts[i] = Amount(base)
+		switch {
+		case int64(i) < rem:
+			parts[i]++
+		case int64(i) < -rem:
+			parts[i]--
+		}
+	}
+	return parts
+}
+
+// Allocate divides a in proportion to weights so the parts add up to a
+// exactly. Leftover sen go to the parts with the largest rounding remainder.
+// It returns nil when the weights do not sum to a positive number; negative
+// weights count as zero.
+func (a Amount) Allocate(weights []float64) []Amount {
+	var sum float64
+	for _, w := range weights {
+		if w > 0 {
+			sum += w
+		}
+	}
+	if sum <= 0 {
+		return nil
+	}
+	total := int64(a)
+	if total < 0 {
+		total = -total
+	}
+	parts := make([]Amount, len(weights))
+	rems := make([]float64, len(weights))
+	var given int64
+	for i, w := range weights {
+		if w <= 0 {
+			continue
+		}
+		exact := float64(total) * w / sum
+		floor := math.Floor(exact)
+		parts[i] = Amount(floor)
+		rems[i] = exact - floor
+		given += int64(floor)
+	}
+	order := make([]int, 0, len(weights))
+	for i, w := range weights {
+		if w > 0 {
+			order = append(order, i)
+		}
+	}
+	sort.SliceStable(order, func(x, y int) bool { return rems[order[x]] > rems[order[y]] })
+	for k := 0; given < total; k = (k + 1) % len(order) {
+		parts[order[k]]++
+		given++
+	}
+	if a < 0 {
+		for i := range parts {
+			parts[i] = -parts[i]
+		}
+	}
+	return parts
+}
+
+// String formats a as a plain decimal with two places, e.g. "-1234.50".
+func (a Amount) String() string {
+	sign := ""
+	v := int64(a)
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, v/Scale, v%Scale)
+}
+
+// Parse reads an amount as written in imports and forms. It accepts an
+// optional "Rp"/"IDR" prefix, a leading minus or surrounding parentheses
+// for negatives, and either "." or "," as the decimal separator:
+//
+//	1234.5  1,234.50  1.234.567,89  Rp 15.000  (2.500)
+//
+// A single separator followed by exactly three digits is read as a
+// thousands separator ("15.000" is fifteen thousand), since Rupiah prices
+// rarely carry fractions. More than two decimal places is an error rather
+// than silently rounding. An empty string parses as zero.
+func Parse(s string) (Amount, error) {
+	orig := s
+	s = strings.TrimSpace(s)
+	neg := false
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		neg = true
+		s = strings.TrimSpace(s[1 : len(s)-1])
+	}
+	for _, p := range []string{"Rp.", "Rp", "IDR"} {
+		if len(s) >= len(p) && strings.EqualFold(s[:len(p)], p) {
+			s = strings.TrimSpace(s[len(p):])
+			break
+		}
+	}
+	if strings.HasPrefix(s, "-") {
+		neg = !neg
+		s = strings.TrimSpace(s[1:])
+	} else if strings.HasPrefix(s, "+") {
+		s = strings.TrimSpace(s[1:])
+	}
+	s = strings.ReplaceAll(s, " ", "")
+	if s == "" {
+		return 0, nil
+	}
+
+	intPart, frac := s, ""
+	lastDot, lastComma := strings.LastIndex(s, "."), strings.LastIndex(s, ",")
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		// Both present: the later one is the decimal separator.
+		dec := lastDot
+		if lastComma > lastDot {
+			dec = lastComma
+		}
+		intPart, frac = s[:dec], s[dec+1:]
+	case lastDot >= 0 || lastComma >= 0:
+		sep := "."
+		if lastComma >= 0 {
+			sep = ","
+		}
+		idx := strings.LastIndex(s, sep)
+		if strings.Count(s, sep) == 1 && (len(s)-idx-1 != 3 || strings.HasPrefix(s, "0")) {
+			intPart, frac = s[:idx], s[idx+1:]
+		}
+	}
+	intPart, ok := ungroup(intPart)
+	if !ok {
+		return 0, fmt.Errorf("money: invalid digit grouping in %q", orig)
+	}
+	if intPart == "" {
+		intPart = "0"
+	}
+	if len(frac) > 2 {
+		if strings.TrimRight(frac[2:], "0") != "" {
+			return 0, fmt.Errorf("money: %q has more than two decimal places", orig)
+		}
+		frac = frac[:2]
+	}
+	for len(frac) < 2 {
+		frac += "0"
+	}
+	if !isDigits(intPart) || !isDigits(frac) {
+		return 0, fmt.Errorf("money: invalid amount %q", orig)
+	}
+	whole, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil || whole > math.MaxInt64/Scale-1 {
+		return 0, fmt.Errorf("money: amount %q out of range", orig)
+	}
+	cents, _ := strconv.ParseInt(frac, 10, 64)
+	v := Amount(whole*Scale + cents)
+	if neg {
+		v = -v
+	}
+	return v, nil
+}
+
+// MustParse is like Parse but panics on error. It is intended for
+// constants and tests.
+func MustParse(s string) Amount {
+	a, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// ungroup removes thousands separators from s, checking that a single
+// separator is used and every group after the first has three digits.
+func ungroup(s string) (string, bool) {
+	if !strings.ContainsAny(s, ".,") {
+		return s, true
+	}
+	if strings.Contains(s, ".") && strings.Contains(s, ",") {
+		return "", false
+	}
+	groups := strings.FieldsFunc(s, func(r rune) bool { return r == '.' || r == ',' })
+	if len(groups) != strings.Count(s, ".")+strings.Count(s, ",")+1 {
+		return "", false
+	}
+	for i, g := range groups {
+		if (i == 0 && (len(g) == 0 || len(g) > 3)) || (i > 0 && len(g) != 3) {
+			return "", false
+		}
+	}
+	return strings.Join(groups, ""), true
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseDecimal reads the canonical decimal text Postgres and JSON use
+// ("1234.5", "-0.25", "1e3" is rejected), rounding extra places half away
+// from zero so NUMERIC columns without a scale still scan.
+func parseDecimal(s string) (Amount, error) {
+	s = strings.TrimSpace(s)
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "-"), "+")
+	intPart, frac, _ := strings.Cut(s, ".")
+	if intPart == "" {
+		intPart = "0"
+	}
+	if !isDigits(intPart) || !isDigits(frac) {
+		return 0, fmt.Errorf("money: invalid decimal %q", s)
+	}
+	roundUp := false
+	if len(frac) > 2 {
+		roundUp = frac[2] >= '5'
+		frac = frac[:2]
+	}
+	for len(frac) < 2 {
+		frac += "0"
+	}
+	whole, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil || whole > math.MaxInt64/Scale-1 {
+		return 0, fmt.Errorf("money: decimal %q out of range", s)
+	}
+	cents, _ := strconv.ParseInt(frac, 10, 64)
+	v := Amount(whole*Scale + cents)
+	if roundUp {
+		v++
+	}
+	if neg {
+		v = -v
+	}
+	return v, nil
+}
+
+// Scan implements sql.Scanner. lib/pq returns NUMERIC as text, which is
+// parsed exactly; integer and float columns are accepted as well.
+func (a *Amount) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*a = 0
+		return nil
+	case []byte:
+		p, err := parseDecimal(string(v))
+		*a = p
+		return err
+	case string:
+		p, err := parseDecimal(v)
+		*a = p
+		return err
+	case int64:
+		*a = New(v)
+		return nil
+	case float64:
+		*a = FromFloat(v)
+		return nil
+	}
+	return fmt.Errorf("money: cannot scan %T", src)
+}
+
+// Value implements driver.Valuer, sending the exact decimal text.
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
+
+// MarshalJSON encodes a as a decimal string.
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + a.String() + `"`), nil
+}
+
+// UnmarshalJSON accepts a decimal string or a JSON number.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*a = 0
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		if s == "" {
+			*a = 0
+			return nil
+		}
+		p, err := parseDecimal(s)
+		*a = p
+		return err
+	}
+	p, err := parseDecimal(string(data))
+	if err != nil {
+		// Numbers in exponent form, e.g. 1e+06.
+		var f float64
+		if jerr := json.Unmarshal(data, &f); jerr != nil {
+			return err
+		}
+		p = FromFloat(f)
+	}
+	*a = p
+	return nil
+}
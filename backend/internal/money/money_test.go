@@ -0,0 +1,135 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cases := map[string]Amount{
+		"":              0,
+		"0":             0,
+		"1234":          123400,
+		"1234.5":        123450,
+		"1,234.50":      123450,
+		"1.234.567,89":  123456789,
+		"Rp 15.000":     1500000,
+		"Rp15.000,00":   1500000,
+		"15,000":        1500000,
+		"-2500":         -250000,
+		"(2.500)":       -250000,
+		"0.1":           10,
+		"12.3400":       1234,
+		"IDR 1,000,000": 100000000,
+	}
+	for in, want := range cases {
+		got, err := Parse(in)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", in, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("Parse(%q) = %s, want %s", in, got, want)
+		}
+	}
+	for _, in := range []string{"abc", "1.2.3,4.5", "0.125", "12a"} {
+		if _, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q) should fail", in)
+		}
+	}
+}
+
+func TestSumIsExact(t *testing.T) {
+	// 0.1 + 0.2 drifts in float64 but not in sen.
+	if got := MustParse("0.10") + MustParse("0.20"); got != MustParse("0.30") {
+		t.Errorf("0.10 + 0.20 = %s", got)
+	}
+	var total Amount
+	for i := 0; i < 1000; i++ {
+		total += MustParse("0.01")
+	}
+	if total != New(10) {
+		t.Errorf("1000 x 0.01 = %s", total)
+	}
+}
+
+func TestSplit(t *testing.T) {
+	parts := New(100).Split(3)
+	if len(parts) != 3 || parts[0] != MustParse("33.34") || parts[2] != MustParse("33.33") || Sum(parts...) != New(100) {
+		t.Errorf("100 / 3 = %v", parts)
+	}
+	if neg := MustParse("-0.05").Split(2); Sum(neg...) != MustParse("-0.05") || neg[0] != MustParse("-0.03") {
+		t.Errorf("-0.05 / 2 = %v", neg)
+	}
+	if New(1).Split(0) != nil {
+		t.Error("Split(0) should be nil")
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	parts := New(100).Allocate([]float64{1, 1, 1})
+	if Sum(parts...) != New(100) || parts[0] != MustParse("33.34") {
+		t.Errorf("100 by 1:1:1 = %v", parts)
+	}
+	parts = MustParse("-10.00").Allocate([]float64{3, 0, 1, -2})
+	if parts[0] != MustParse("-7.50") || parts[1] != 0 || parts[2] != MustParse("-2.50") || parts[3] != 0 {
+		t.Errorf("-10 by 3:0:1:-2 = %v", parts)
+	}
+	if New(5).Allocate([]float64{0, 0}) != nil {
+		t.Error("zero weights should return nil")
+	}
+}
+
+func TestScanNumeric(t *testing.T) {
+	var a Amount
+	for src, want := range map[interface{}]Amount{
+		"123.45":          12345,
+		"-0.50":           -50,
+		"7":               700,
+		"1.005":           101,
+		int64(3):          300,
+		float64(2.675):    268,
+		"99999999999.99":  9999999999999,
+		"-12345678.90000": -1234567890,
+	} {
+		var in interface{} = src
+		if s, ok := src.(string); ok {
+			in = []byte(s)
+		}
+		if err := a.Scan(in); err != nil || a != want {
+			t.Errorf("Scan(%v) = %s, %v; want %s", src, a, err, want)
+		}
+	}
+	if err := a.Scan(nil); err != nil || a != 0 {
+		t.Errorf("Scan(nil) = %s, %v", a, err)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	b, err := json.Marshal(struct {
+		A Amount `json:"a"`
+	}{MustParse("-1234.5")})
+	if err != nil || string(b) != `{"a":"-1234.50"}` {
+		t.Fatalf("marshal = %s, %v", b, err)
+	}
+	var v struct {
+		A Amount `json:"a"`
+		B Amount `json:"b"`
+		C Amount `json:"c"`
+	}
+	if err := json.Unmarshal([]byte(`{"a":"10.25","b":99.9,"c":1e6}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.A != 1025 || v.B != 9990 || v.C != New(1000000) {
+		t.Errorf("unmarshal = %+v", v)
+	}
+}
+
+func TestMul(t *testing.T) {
+	if got := New(1000000).Mul(0.005); got != New(5000) {
+		t.Errorf("1,000,000 x 0.5%% = %s", got)
+	}
+	if got := MustParse("0.03").Mul(0.5); got != 2 {
+		t.Errorf("0.03 x 0.5 = %s, want 0.02", got)
+	}
+}
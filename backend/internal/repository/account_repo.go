@@ -2,7 +2,6 @@ package repository
 
 import (
 	"context"
-	"log"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/ramadhan22/dropship-erp/backend/internal/logutil"
@@ -21,18 +20,22 @@ func NewAccountRepo(db *sqlx.DB) *AccountRepo {
 
 // CreateAccount inserts a new account row and returns its ID.
 func (r *AccountRepo) CreateAccount(ctx context.Context, a *models.Account) (int64, error) {
-	log.Printf("AccountRepo.CreateAccount %s", a.AccountCode)
+	logutil.Debug(ctx, "AccountRepo.CreateAccount", "Inserting account", map[string]interface{}{
+		"account_code": a.AccountCode,
+	})
 	var id int64
 	err := r.db.QueryRowxContext(ctx,
-		`INSERT INTO accounts (account_code, account_name, account_type, parent_id)
-         VALUES ($1, $2, $3, $4) RETURNING account_id`,
-		a.AccountCode, a.AccountName, a.AccountType, a.ParentID,
+		`INSERT INTO accounts (account_code, account_name, account_type, parent_id, entity_id, intercompany)
+         VALUES ($1, $2, $3, $4, $5, $6) RETURNING account_id`,
+		a.AccountCode, a.AccountName, a.AccountType, a.ParentID, a.EntityID, a.Intercompany,
 	).Scan(&id)
 	if err != nil {
 		logutil.Errorf("AccountRepo.CreateAccount error: %v", err)
 		return 0, err
 	}
-	log.Printf("AccountRepo.CreateAccount id=%d", id)
+	logutil.Debug(ctx, "AccountRepo.CreateAccount", "Account inserted", map[string]interface{}{
+		"account_id": id,
+	})
 	return id, nil
 }
 
@@ -57,12 +60,15 @@ func (r *AccountRepo) ListAccounts(ctx context.Context) ([]models.Account, error
 
 // UpdateAccount updates an existing account by ID.
 func (r *AccountRepo) UpdateAccount(ctx context.Context, a *models.Account) error {
-	log.Printf("AccountRepo.UpdateAccount %d", a.AccountID)
+	logutil.Debug(ctx, "AccountRepo.UpdateAccount", "Updating account", map[string]interface{}{
+		"account_id": a.AccountID,
+	})
 	_, err := r.db.ExecContext(ctx,
 		`UPDATE accounts
-         SET account_code=$1, account_name=$2, account_type=$3, parent_id=$4, updated_at=NOW()
-         WHERE account_id=$5`,
-		a.AccountCode, a.AccountName, a.AccountType, a.ParentID, a.AccountID,
+         SET account_code=$1, account_name=$2, account_type=$3, parent_id=$4,
+             entity_id=$5, intercompany=$6, updated_at=NOW()
+         WHERE account_id=$7`,
+		a.AccountCode, a.AccountName, a.AccountType, a.ParentID, a.EntityID, a.Intercompany, a.AccountID,
 	)
 	if err != nil {
 		logutil.Errorf("AccountRepo.UpdateAccount error: %v", err)
@@ -72,7 +78,9 @@ func (r *AccountRepo) UpdateAccount(ctx context.Context, a *models.Account) erro
 
 // DeleteAccount removes an account row.
 func (r *AccountRepo) DeleteAccount(ctx context.Context, id int64) error {
-	log.Printf("AccountRepo.DeleteAccount %d", id)
+	logutil.Debug(ctx, "AccountRepo.DeleteAccount", "Deleting account", map[string]interface{}{
+		"account_id": id,
+	})
 	_, err := r.db.ExecContext(ctx, `DELETE FROM accounts WHERE account_id=$1`, id)
 	if err != nil {
 		logutil.Errorf("AccountRepo.DeleteAccount error: %v", err)
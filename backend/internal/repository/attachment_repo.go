@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+)
+
+// attachmentTargets maps each linkable record type to the table and key
+// column that identify it.
+var attachmentTargets = map[string]struct{ table, column string }{
+	models.AttachToExpense:          {"expenses", "id"},
+	models.AttachToJournalEntry:     {"journal_entries", "journal_id"},
+	models.AttachToAdInvoice:        {"ad_invoices", "invoice_no"},
+	models.AttachToTaxPayment:       {"tax_payments", "id"},
+	models.AttachToWithdrawal:       {"withdrawals", "id"},
+	models.AttachToShopeeAdjustment: {"shopee_adjustments", "id"},
+	models.AttachToBatch:            {"batch_history", "id"},
+}
+
+// AttachmentRepo stores attachment metadata and the records they are
+// linked to.
+type AttachmentRepo struct{ db DBTX }
+
+// NewAttachmentRepo constructs an AttachmentRepo.
+func NewAttachmentRepo(db DBTX) *AttachmentRepo { return &AttachmentRepo{db: db} }
+
+// Insert stores a and its links and fills in its ID and creation time.
+func (r *AttachmentRepo) Insert(ctx context.Context, a *models.Attachment) error {
+	err := r.db.QueryRowxContext(ctx,
+		`INSERT INTO attachments (storage_key, file_name, content_type, size_bytes, sha256, description)
+          VALUES ($1,$2,$3,$4,$5,$6) RETURNING id, created_at`,
+		a.StorageKey, a.FileName, a.ContentType, a.SizeBytes, a.SHA256, a.Description).Scan(&a.ID, &a.CreatedAt)
+	if err != nil {
+		return err
+	}
+	for i := range a.Links {
+		a.Links[i].AttachmentID = a.ID
+		if err := r.Link(ctx, a.Links[i]); err != nil {
+			return err
+		}
+	}
+	if a.Links == nil {
+		a.Links = []models.AttachmentLink{}
+	}
+	return nil
+}
+
+// Get fetches an attachment with its links.
+func (r *AttachmentRepo) Get(ctx context.Context, id int64) (*models.Attachment, error) {
+	var a models.Attachment
+	if err := r.db.GetContext(ctx, &a, `SELECT * FROM attachments WHERE id=$1`, id); err != nil {
+		return nil, err
+	}
+	list := []models.Attachment{a}
+	if err := r.attachLinks(ctx, list); err != nil {
+		return nil, err
+	}
+	return &list[0], nil
+}
+
+// Delete removes an attachment and its links.
+func (r *AttachmentRepo) Delete(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM attachments WHERE id=$1`, id)
+	return err
+}
+
+// Link ties an attachment to a record. Linking twice is a no-op.
+func (r *AttachmentRepo) Link(ctx context.Context, l models.AttachmentLink) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO attachment_links (attachment_id, entity_type, entity_id) VALUES ($1,$2,$3)
+          ON CONFLICT DO NOTHING`,
+		l.AttachmentID, l.EntityType, l.EntityID)
+	return err
+}
+
+// Unlink removes the link between an attachment and a record.
+func (r *AttachmentRepo) Unlink(ctx context.Context, l models.AttachmentLink) error {
+	_, err := r.db.ExecContext(ctx,
+		`DELETE FROM attachment_links WHERE attachment_id=$1 AND entity_type=$2 AND entity_id=$3`,
+		l.AttachmentID, l.EntityType, l.EntityID)
+	return err
+}
+
+// List returns attachments newest first. When entityType is set only those
+// linked to that record type, and to entityID if it is set too, are
+// returned.
+func (r *AttachmentRepo) List(ctx context.Context, entityType, entityID string, limit, offset int) ([]models.Attachment, error) {
+	var list []models.Attachment
+	err := r.db.SelectContext(ctx, &list,
+		`SELECT a.* FROM attachments a
+          WHERE $1 = '' OR EXISTS (
+                SELECT 1 FROM attachment_links l
+                 WHERE l.attachment_id = a.id AND l.entity_type = $1
+                   AND ($2 = '' OR l.entity_id = $2))
+          ORDER BY a.created_at DESC, a.id DESC
+          LIMIT $3 OFFSET $4`,
+		entityType, entityID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if list == nil {
+		list = []models.Attachment{}
+	}
+	return list, r.attachLinks(ctx, list)
+}
+
+func (r *AttachmentRepo) attachLinks(ctx context.Context, list []models.Attachment) error {
+	if len(list) == 0 {
+		return nil
+	}
+	ids := make([]interface{}, len(list))
+	for i, a := range list {
+		ids[i] = a.ID
+	}
+	query, args, err := sqlx.In(
+		`SELECT attachment_id, entity_type, entity_id FROM attachment_links
+          WHERE attachment_id IN (?) ORDER BY created_at, entity_type, entity_id`, ids)
+	if err != nil {
+		return err
+	}
+	var links []models.AttachmentLink
+	if err := r.db.SelectContext(ctx, &links, r.db.Rebind(query), args...); err != nil {
+		return err
+	}
+	byAttachment := map[int64][]models.AttachmentLink{}
+	for _, l := range links {
+		byAttachment[l.AttachmentID] = append(byAttachment[l.AttachmentID], l)
+	}
+	for i := range list {
+		list[i].Links = byAttachment[list[i].ID]
+		if list[i].Links == nil {
+			list[i].Links = []models.AttachmentLink{}
+		}
+	}
+	return nil
+}
+
+// EntityExists reports whether the record an attachment would be linked to
+// exists. Unknown record types are an error.
+func (r *AttachmentRepo) EntityExists(ctx context.Context, entityType, entityID string) (bool, error) {
+	target, ok := attachmentTargets[entityType]
+	if !ok {
+		return false, fmt.Errorf("unknown attachment target %q", entityType)
+	}
+	var exists bool
+	err := r.db.GetContext(ctx, &exists,
+		fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s WHERE %s::text = $1)`, target.table, target.column),
+		entityID)
+	return exists, err
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
+)
+
+// BalanceSnapshotRepo manages account_balance_daily, the per-day,
+// per-account, per-store totals that balance queries read instead of
+// scanning journal_lines. Database triggers keep it current; this repo only
+// rebuilds and verifies it.
+type BalanceSnapshotRepo struct {
+	db DBTX
+}
+
+// NewBalanceSnapshotRepo constructs a BalanceSnapshotRepo.
+func NewBalanceSnapshotRepo(db DBTX) *BalanceSnapshotRepo {
+	return &BalanceSnapshotRepo{db: db}
+}
+
+// BalanceSnapshotDrift is a day where the stored totals disagree with the
+// journal lines.
+type BalanceSnapshotDrift struct {
+	AccountID      int64        `db:"account_id" json:"account_id"`
+	ShopUsername   string       `db:"shop_username" json:"shop_username"`
+	EntityID       int64        `db:"entity_id" json:"entity_id"`
+	BalanceDate    time.Time    `db:"balance_date" json:"balance_date"`
+	SnapshotDebit  money.Amount `db:"snapshot_debit" json:"snapshot_debit"`
+	SnapshotCredit money.Amount `db:"snapshot_credit" json:"snapshot_credit"`
+	JournalDebit   money.Amount `db:"journal_debit" json:"journal_debit"`
+	JournalCredit  money.Amount `db:"journal_credit" json:"journal_credit"`
+}
+
+// Rebuild regenerates every snapshot row from journal_lines and returns the
+// number of rows written. Writes to journal_lines are blocked while it runs,
+// so call it inside a transaction to keep the lock short.
+func (r *BalanceSnapshotRepo) Rebuild(ctx context.Context) (int64, error) {
+	var n int64
+	if err := r.db.GetContext(ctx, &n, `SELECT rebuild_account_balance_daily()`); err != nil {
+		return 0, fmt.Errorf("rebuild balance snapshots: %w", err)
+	}
+	return n, nil
+}
+
+// FindDrift compares the snapshot table with journal_lines and returns the
+// days that differ. An empty result means the snapshots are consistent.
+func (r *BalanceSnapshotRepo) FindDrift(ctx context.Context) ([]BalanceSnapshotDrift, error) {
+	query := `
+        WITH actual AS (
+          SELECT jl.account_id, je.shop_username, je.entity_id, je.entry_date AS balance_date,
+                 SUM(CASE WHEN jl.is_debit THEN jl.amount ELSE 0 END) AS debit_total,
+                 SUM(CASE WHEN jl.is_debit THEN 0 ELSE jl.amount END) AS credit_total
+            FROM journal_lines jl
+            JOIN journal_entries je ON je.journal_id = jl.journal_id
+           GROUP BY jl.account_id, je.shop_username, je.entity_id, je.entry_date
+        )
+        SELECT COALESCE(s.account_id, a.account_id) AS account_id,
+               COALESCE(s.shop_username, a.shop_username) AS shop_username,
+               COALESCE(s.entity_id, a.entity_id) AS entity_id,
+               COALESCE(s.balance_date, a.balance_date) AS balance_date,
+               COALESCE(s.debit_total, 0) AS snapshot_debit,
+               COALESCE(s.credit_total, 0) AS snapshot_credit,
+               COALESCE(a.debit_total, 0) AS journal_debit,
+               COALESCE(a.credit_total, 0) AS journal_credit
+          FROM account_balance_daily s
+          FULL OUTER JOIN actual a
+            ON a.account_id = s.account_id
+           AND a.shop_username = s.shop_username
+           AND a.entity_id = s.entity_id
+           AND a.balance_date = s.balance_date
+         WHERE COALESCE(s.debit_total, 0) <> COALESCE(a.debit_total, 0)
+            OR COALESCE(s.credit_total, 0) <> COALESCE(a.credit_total, 0)
+         ORDER BY balance_date, account_id`
+	var list []BalanceSnapshotDrift
+	if err := r.db.SelectContext(ctx, &list, query); err != nil {
+		return nil, fmt.Errorf("find balance snapshot drift: %w", err)
+	}
+	if list == nil {
+		list = []BalanceSnapshotDrift{}
+	}
+	return list, nil
+}
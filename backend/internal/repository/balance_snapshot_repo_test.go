@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
+)
+
+func TestBalanceSnapshotsFollowJournalWrites(t *testing.T) {
+	ctx := context.Background()
+	jrepo := NewJournalRepo(testDB)
+	srepo := NewBalanceSnapshotRepo(testDB)
+	day := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	acc1 := insertTestAccount(t, testDB, "SNAP100", "Snapshot Asset", "Asset", nil)
+	acc2 := insertTestAccount(t, testDB, "SNAP200", "Snapshot Revenue", "Revenue", nil)
+	defer tempCleanupAccount(t, testDB, acc1)
+	defer tempCleanupAccount(t, testDB, acc2)
+
+	balanceOf := func(acc int64) money.Amount {
+		bals, err := jrepo.GetAccountBalancesAsOf(ctx, "SnapShop", day)
+		if err != nil {
+			t.Fatalf("GetAccountBalancesAsOf failed: %v", err)
+		}
+		for _, b := range bals {
+			if b.AccountID == acc {
+				return b.Balance
+			}
+		}
+		return 0
+	}
+
+	id, err := jrepo.CreateJournalEntry(ctx, &models.JournalEntry{
+		EntryDate: day, SourceType: "snapshot_test", SourceID: "SNAP-1", ShopUsername: "SnapShop",
+	})
+	if err != nil {
+		t.Fatalf("CreateJournalEntry failed: %v", err)
+	}
+	lines := []models.JournalLine{
+		{JournalID: id, AccountID: acc1, IsDebit: true, Amount: money.New(250)},
+		{JournalID: id, AccountID: acc2, IsDebit: false, Amount: money.New(250)},
+	}
+	if err := jrepo.InsertJournalLines(ctx, lines); err != nil {
+		t.Fatalf("InsertJournalLines failed: %v", err)
+	}
+	if got := balanceOf(acc1); got != money.New(250) {
+		t.Errorf("expected snapshot balance 250 after insert, got %s", got)
+	}
+	assertNoDrift := func(step string) {
+		t.Helper()
+		drift, err := srepo.FindDrift(ctx)
+		if err != nil {
+			t.Fatalf("FindDrift failed: %v", err)
+		}
+		for _, d := range drift {
+			if d.AccountID == acc1 || d.AccountID == acc2 {
+				t.Errorf("%s: unexpected drift: %+v", step, d)
+			}
+		}
+	}
+	assertNoDrift("insert")
+
+	stored, err := jrepo.GetLinesByJournalID(ctx, id)
+	if err != nil || len(stored) != 2 {
+		t.Fatalf("GetLinesByJournalID: %v (%d lines)", err, len(stored))
+	}
+	for _, l := range stored {
+		if err := jrepo.UpdateJournalLineAmount(ctx, l.LineID, money.New(300)); err != nil {
+			t.Fatalf("UpdateJournalLineAmount failed: %v", err)
+		}
+	}
+	if got := balanceOf(acc1); got != money.New(300) {
+		t.Errorf("expected snapshot balance 300 after line update, got %s", got)
+	}
+	assertNoDrift("line update")
+
+	// Moving the entry to a later day and another store takes its lines
+	// out of SnapShop's balance as of day.
+	if _, err := testDB.ExecContext(ctx,
+		`UPDATE journal_entries SET entry_date = $1, shop_username = 'SnapShop2' WHERE journal_id = $2`,
+		day.AddDate(0, 0, 1), id); err != nil {
+		t.Fatalf("move entry failed: %v", err)
+	}
+	if got := balanceOf(acc1); got != 0 {
+		t.Errorf("expected snapshot balance 0 after moving the entry, got %s", got)
+	}
+	assertNoDrift("entry update")
+
+	if _, err := srepo.Rebuild(ctx); err != nil {
+		t.Fatalf("Rebuild failed: %v", err)
+	}
+	assertNoDrift("rebuild")
+
+	if err := jrepo.DeleteJournalEntry(ctx, id); err != nil {
+		t.Fatalf("DeleteJournalEntry failed: %v", err)
+	}
+	if got := balanceOf(acc1); got != 0 {
+		t.Errorf("expected snapshot balance 0 after delete, got %s", got)
+	}
+	assertNoDrift("delete")
+	testDB.ExecContext(ctx, "DELETE FROM account_balance_daily WHERE account_id IN ($1, $2)", acc1, acc2)
+}
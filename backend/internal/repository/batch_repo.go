@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
 )
 
@@ -14,8 +15,8 @@ type BatchRepo struct{ db DBTX }
 func NewBatchRepo(db DBTX) *BatchRepo { return &BatchRepo{db: db} }
 
 func (r *BatchRepo) Insert(ctx context.Context, b *models.BatchHistory) (int64, error) {
-	query := `INSERT INTO batch_history (process_type, started_at, ended_at, time_spent, total_data, done_data, status, error_message, file_name, file_path)
-              VALUES (:process_type, NOW(), :ended_at, :time_spent, :total_data, :done_data, :status, :error_message, :file_name, :file_path)
+	query := `INSERT INTO batch_history (process_type, started_at, ended_at, time_spent, total_data, done_data, status, error_message, file_name, file_path, claimed_by)
+              VALUES (:process_type, NOW(), :ended_at, :time_spent, :total_data, :done_data, :status, :error_message, :file_name, :file_path, :claimed_by)
               RETURNING id`
 	rows, err := sqlx.NamedQueryContext(ctx, r.db, query, b)
 	if err != nil {
@@ -52,6 +53,15 @@ func (r *BatchRepo) UpdateStatus(ctx context.Context, id int64, status, msg stri
 	return err
 }
 
+// MarkProcessing moves a batch to processing and records owner as the
+// instance running it.
+func (r *BatchRepo) MarkProcessing(ctx context.Context, id int64, msg, owner string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE batch_history SET status='processing', error_message=$2, claimed_by=$3 WHERE id=$1`,
+		id, msg, owner)
+	return err
+}
+
 // UpdateStatusWithEndTime updates the status and sets the end time and time spent.
 func (r *BatchRepo) UpdateStatusWithEndTime(ctx context.Context, id int64, status, msg string) error {
 	_, err := r.db.ExecContext(ctx,
@@ -118,6 +128,27 @@ func (r *BatchRepo) ListFiltered(ctx context.Context, types, statuses []string)
 	return list, err
 }
 
+// ListQuery returns batch history using the filtering framework.
+func (r *BatchRepo) ListQuery(ctx context.Context, params *models.FilterParams) (*models.QueryResult, error) {
+	return listFiltered[models.BatchHistory](ctx, r.db, filteredList{
+		base: "SELECT * FROM batch_history",
+		fields: map[string]string{
+			"id":            "id",
+			"process_type":  "process_type",
+			"started_at":    "started_at",
+			"ended_at":      "ended_at",
+			"total_data":    "total_data",
+			"done_data":     "done_data",
+			"status":        "status",
+			"error_message": "error_message",
+			"file_name":     "file_name",
+			"created_at":    "started_at",
+		},
+		keys: []string{"id"},
+		sort: models.SortCondition{Field: "started_at", Direction: "desc"},
+	}, params)
+}
+
 // GetByID retrieves a batch record by its ID.
 func (r *BatchRepo) GetByID(ctx context.Context, id int64) (*models.BatchHistory, error) {
 	var batch models.BatchHistory
@@ -127,3 +158,84 @@ func (r *BatchRepo) GetByID(ctx context.Context, id int64) (*models.BatchHistory
 	}
 	return &batch, nil
 }
+
+// QueueDepth counts pending and processing batches by process type.
+func (r *BatchRepo) QueueDepth(ctx context.Context) ([]models.BatchQueueCount, error) {
+	var list []models.BatchQueueCount
+	err := r.db.SelectContext(ctx, &list,
+		`SELECT process_type, status, COUNT(*) AS count FROM batch_history
+		 WHERE status IN ('pending', 'processing')
+		 GROUP BY process_type, status`)
+	if list == nil {
+		list = []models.BatchQueueCount{}
+	}
+	return list, err
+}
+
+// Claim moves a pending batch to processing for owner. Only one caller can
+// claim a batch, so the API scheduler and erpctl never process the same
+// batch. It reports whether the batch was claimed.
+func (r *BatchRepo) Claim(ctx context.Context, id int64, owner string) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE batch_history SET status='processing', error_message='', claimed_by=$2
+		 WHERE id=$1 AND status='pending'`, id, owner)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// Requeue puts a failed or cancelled batch back to pending so its scheduler
+// processes it again. It reports whether the batch was requeued.
+func (r *BatchRepo) Requeue(ctx context.Context, id int64) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE batch_history
+		 SET status='pending', error_message='', done_data=0, ended_at=NULL, time_spent=NULL
+		 WHERE id=$1 AND status IN ('failed', 'cancelled')`, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// Cancel marks a pending batch as cancelled so no scheduler picks it up.
+// It reports whether the batch was cancelled.
+func (r *BatchRepo) Cancel(ctx context.Context, id int64, msg string) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE batch_history
+		 SET status='cancelled', error_message=$2, ended_at=NOW(), time_spent=(NOW() - started_at)
+		 WHERE id=$1 AND status='pending'`, id, msg)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// ResetProcessing moves the batches owner left in status processing back to
+// pending when their type is in resumable and marks the rest failed with msg.
+// Batches other instances are running are not touched. It returns how many
+// batches were resumed and failed.
+func (r *BatchRepo) ResetProcessing(ctx context.Context, owner string, resumable []string, msg string) (int64, int64, error) {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE batch_history SET status='pending', done_data=0, ended_at=NULL, time_spent=NULL, claimed_by=NULL
+		 WHERE status='processing' AND claimed_by=$1 AND process_type = ANY($2)`, owner, pq.Array(resumable))
+	if err != nil {
+		return 0, 0, err
+	}
+	resumed, err := res.RowsAffected()
+	if err != nil {
+		return 0, 0, err
+	}
+	res, err = r.db.ExecContext(ctx,
+		`UPDATE batch_history
+		 SET status='failed', error_message=$2, ended_at=NOW(), time_spent=(NOW() - started_at)
+		 WHERE status='processing' AND claimed_by=$1`, owner, msg)
+	if err != nil {
+		return resumed, 0, err
+	}
+	failed, err := res.RowsAffected()
+	return resumed, failed, err
+}
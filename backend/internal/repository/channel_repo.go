@@ -29,9 +29,9 @@ func (r *ChannelRepo) CreateJenisChannel(ctx context.Context, c *models.JenisCha
 
 // CreateStore inserts a new store row and returns the generated ID.
 func (r *ChannelRepo) CreateStore(ctx context.Context, s *models.Store) (int64, error) {
-	query := `INSERT INTO stores (jenis_channel_id, nama_toko) VALUES ($1, $2) RETURNING store_id`
+	query := `INSERT INTO stores (jenis_channel_id, nama_toko, entity_id) VALUES ($1, $2, $3) RETURNING store_id`
 	var id int64
-	if err := r.db.QueryRowContext(ctx, query, s.JenisChannelID, s.NamaToko).Scan(&id); err != nil {
+	if err := r.db.QueryRowContext(ctx, query, s.JenisChannelID, s.NamaToko, s.EntityID).Scan(&id); err != nil {
 		return 0, err
 	}
 	return id, nil
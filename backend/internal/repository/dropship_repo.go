@@ -2,13 +2,17 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
-	"log"
 	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/ramadhan22/dropship-erp/backend/internal/apperr"
+	"github.com/ramadhan22/dropship-erp/backend/internal/lifecycle"
 	"github.com/ramadhan22/dropship-erp/backend/internal/logutil"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 )
 
 // DropshipRepo handles all database operations related to the dropship_purchases table.
@@ -44,8 +48,13 @@ func NewDropshipRepo(db DBTX) *DropshipRepo {
 // InsertDropshipPurchase receives a *models.DropshipPurchase and executes an INSERT into dropship_purchases.
 // It uses NamedExecContext so the struct fields map to column names automatically (via db tags).
 func (r *DropshipRepo) InsertDropshipPurchase(ctx context.Context, p *models.DropshipPurchase) error {
-	log.Printf("DropshipRepo.InsertDropshipPurchase %s", p.KodePesanan)
+	logutil.Debug(ctx, "DropshipRepo.InsertDropshipPurchase", "Inserting purchase", map[string]interface{}{
+		"kode_pesanan": p.KodePesanan,
+	})
+	// The initial lifecycle state is recorded in the same statement so
+	// every purchase starts its history at "imported".
 	query := `
+        WITH ins AS (
         INSERT INTO dropship_purchases (
             kode_pesanan, kode_transaksi, waktu_pesanan_terbuat, status_pesanan_terakhir,
             biaya_lainnya, biaya_mitra_jakmall, total_transaksi, dibuat_oleh,
@@ -59,7 +68,13 @@ func (r *DropshipRepo) InsertDropshipPurchase(ctx context.Context, p *models.Dro
             :jenis_ekspedisi, :cashless, :nomor_resi, :waktu_pengiriman,
             :provinsi, :kota
         )
-        ON CONFLICT (kode_pesanan) DO NOTHING`
+        ON CONFLICT (kode_pesanan) DO NOTHING
+        RETURNING kode_pesanan, lifecycle_status, status_pesanan_terakhir, waktu_pesanan_terbuat
+        )
+        INSERT INTO purchase_status_history (kode_pesanan, to_status, label, cause, changed_at)
+        SELECT kode_pesanan, lifecycle_status, status_pesanan_terakhir, '` + string(lifecycle.CauseImport) + `',
+               COALESCE(waktu_pesanan_terbuat, NOW())
+        FROM ins`
 	_, err := r.db.NamedExecContext(ctx, query, p)
 	if err != nil {
 		logutil.Errorf("InsertDropshipPurchase error: %v", err)
@@ -100,7 +115,10 @@ func (r *DropshipRepo) ListExistingPurchases(ctx context.Context, ids []string)
 
 // InsertDropshipPurchaseDetail inserts a record into dropship_purchase_details.
 func (r *DropshipRepo) InsertDropshipPurchaseDetail(ctx context.Context, d *models.DropshipPurchaseDetail) error {
-	log.Printf("DropshipRepo.InsertDropshipPurchaseDetail %s %s", d.KodePesanan, d.SKU)
+	logutil.Debug(ctx, "DropshipRepo.InsertDropshipPurchaseDetail", "Inserting purchase detail", map[string]interface{}{
+		"kode_pesanan": d.KodePesanan,
+		"sku":          d.SKU,
+	})
 	query := `
         INSERT INTO dropship_purchase_details (
             kode_pesanan, sku, nama_produk, harga_produk, qty,
@@ -125,19 +143,21 @@ func (r *DropshipRepo) GetDropshipPurchaseByID(ctx context.Context, kodePesanan
 	err := r.db.GetContext(ctx, &p,
 		`SELECT * FROM dropship_purchases WHERE kode_pesanan = $1`, kodePesanan)
 	if err != nil {
-		return nil, err
+		return nil, apperr.NotFoundOr(err, apperr.EntityPurchase, kodePesanan)
 	}
 	return &p, nil
 }
 
 // GetDropshipPurchaseByInvoice retrieves a purchase by kode_invoice_channel.
 func (r *DropshipRepo) GetDropshipPurchaseByInvoice(ctx context.Context, kodeInvoice string) (*models.DropshipPurchase, error) {
-	log.Printf("DropshipRepo.GetDropshipPurchaseByInvoice %s", kodeInvoice)
+	logutil.Debug(ctx, "DropshipRepo.GetDropshipPurchaseByInvoice", "Fetching purchase by invoice", map[string]interface{}{
+		"invoice": kodeInvoice,
+	})
 	var p models.DropshipPurchase
 	err := r.db.GetContext(ctx, &p,
 		`SELECT * FROM dropship_purchases WHERE kode_invoice_channel = $1`, kodeInvoice)
 	if err != nil {
-		return nil, err
+		return nil, apperr.NotFoundOr(err, apperr.EntityPurchase, kodeInvoice)
 	}
 	return &p, nil
 }
@@ -159,7 +179,9 @@ func (r *DropshipRepo) GetDropshipPurchasesByInvoices(ctx context.Context, invoi
 	if len(invoices) == 0 {
 		return []*models.DropshipPurchase{}, nil
 	}
-	log.Printf("DropshipRepo.GetDropshipPurchasesByInvoices fetching %d invoices", len(invoices))
+	logutil.Debug(ctx, "DropshipRepo.GetDropshipPurchasesByInvoices", "Fetching purchases by invoices", map[string]interface{}{
+		"invoices": len(invoices),
+	})
 
 	// Build IN clause with placeholders
 	placeholders := make([]string, len(invoices))
@@ -184,13 +206,15 @@ func (r *DropshipRepo) GetDropshipPurchasesByInvoices(ctx context.Context, invoi
 		result[i] = &purchases[i]
 	}
 
-	log.Printf("DropshipRepo.GetDropshipPurchasesByInvoices found %d purchases", len(result))
+	logutil.Debug(ctx, "DropshipRepo.GetDropshipPurchasesByInvoices", "Fetched purchases", map[string]interface{}{
+		"purchases": len(result),
+	})
 	return result, nil
 }
 
 // SumDetailByInvoice sums total_harga_produk_channel for a given invoice.
-func (r *DropshipRepo) SumDetailByInvoice(ctx context.Context, kodeInvoice string) (float64, error) {
-	var sum float64
+func (r *DropshipRepo) SumDetailByInvoice(ctx context.Context, kodeInvoice string) (money.Amount, error) {
+	var sum money.Amount
 	err := r.db.GetContext(ctx, &sum,
 		`SELECT COALESCE(SUM(d.total_harga_produk_channel),0)
                 FROM dropship_purchase_details d
@@ -200,8 +224,8 @@ func (r *DropshipRepo) SumDetailByInvoice(ctx context.Context, kodeInvoice strin
 }
 
 // SumProductCostByInvoice sums total_harga_produk for a given invoice.
-func (r *DropshipRepo) SumProductCostByInvoice(ctx context.Context, kodeInvoice string) (float64, error) {
-	var sum float64
+func (r *DropshipRepo) SumProductCostByInvoice(ctx context.Context, kodeInvoice string) (money.Amount, error) {
+	var sum money.Amount
 	err := r.db.GetContext(ctx, &sum,
 		`SELECT COALESCE(SUM(d.total_harga_produk),0)
                 FROM dropship_purchase_details d
@@ -210,23 +234,75 @@ func (r *DropshipRepo) SumProductCostByInvoice(ctx context.Context, kodeInvoice
 	return sum, err
 }
 
-// UpdatePurchaseStatus sets status_pesanan_terakhir for the given kode_pesanan.
-func (r *DropshipRepo) UpdatePurchaseStatus(ctx context.Context, kodePesanan, status string) error {
-	log.Printf("DropshipRepo.UpdatePurchaseStatus %s %s", kodePesanan, status)
-	_, err := r.db.ExecContext(ctx,
-		`UPDATE dropship_purchases SET status_pesanan_terakhir=$2 WHERE kode_pesanan=$1`,
-		kodePesanan, status)
+// TransitionPurchaseStatus moves a purchase to a new lifecycle state and
+// appends the change to purchase_status_history. Transitions not allowed by
+// lifecycle.Check fail with an error wrapping lifecycle.ErrInvalidTransition;
+// moving to the current state is a no-op. Run it inside a transaction so the
+// status row stays locked until the history entry is written.
+func (r *DropshipRepo) TransitionPurchaseStatus(ctx context.Context, kodePesanan string, c lifecycle.Change) error {
+	logutil.Debug(ctx, "DropshipRepo.TransitionPurchaseStatus", "Transitioning purchase status", map[string]interface{}{
+		"kode_pesanan": kodePesanan,
+		"to":           c.To,
+		"cause":        c.Cause,
+	})
+	var cur lifecycle.Status
+	err := r.db.GetContext(ctx, &cur,
+		`SELECT lifecycle_status FROM dropship_purchases WHERE kode_pesanan=$1 FOR UPDATE`, kodePesanan)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("purchase %s not found", kodePesanan)
+	}
+	if err != nil {
+		return err
+	}
+	if err := lifecycle.Check(cur, c.To); err != nil {
+		return fmt.Errorf("purchase %s: %w", kodePesanan, err)
+	}
+	if cur == c.To {
+		return nil
+	}
+	at := c.At
+	if at.IsZero() {
+		at = time.Now()
+	}
+	var label string
+	err = r.db.GetContext(ctx, &label,
+		`UPDATE dropship_purchases
+                SET lifecycle_status=$2,
+                    status_pesanan_terakhir=COALESCE(NULLIF($3, ''), status_pesanan_terakhir)
+                WHERE kode_pesanan=$1 AND lifecycle_status=$4
+                RETURNING status_pesanan_terakhir`,
+		kodePesanan, c.To, c.DisplayLabel(), cur)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("purchase %s: status changed concurrently", kodePesanan)
+	}
 	if err != nil {
-		logutil.Errorf("UpdatePurchaseStatus error: %v", err)
+		logutil.Errorf("TransitionPurchaseStatus error: %v", err)
+		return err
+	}
+	var note *string
+	if c.Note != "" {
+		note = &c.Note
+	}
+	_, err = r.db.ExecContext(ctx,
+		`INSERT INTO purchase_status_history (kode_pesanan, from_status, to_status, label, cause, note, changed_at)
+                VALUES ($1,$2,$3,$4,$5,$6,$7)`,
+		kodePesanan, cur, c.To, label, c.Cause, note, at)
+	if err != nil {
+		logutil.Errorf("TransitionPurchaseStatus history error: %v", err)
 	}
 	return err
 }
 
-// UpdateDropshipStatus is a compatibility alias for UpdatePurchaseStatus.
-// ShopeeService expects this method to exist on its dropship repository
-// dependency, so we simply delegate to UpdatePurchaseStatus.
-func (r *DropshipRepo) UpdateDropshipStatus(ctx context.Context, kodePesanan, status string) error {
-	return r.UpdatePurchaseStatus(ctx, kodePesanan, status)
+// ListPurchaseStatusHistory returns the lifecycle transitions of a purchase in
+// the order they happened.
+func (r *DropshipRepo) ListPurchaseStatusHistory(ctx context.Context, kodePesanan string) ([]models.PurchaseStatusEvent, error) {
+	var list []models.PurchaseStatusEvent
+	err := r.db.SelectContext(ctx, &list,
+		`SELECT * FROM purchase_status_history WHERE kode_pesanan=$1 ORDER BY changed_at, id`, kodePesanan)
+	if list == nil {
+		list = []models.PurchaseStatusEvent{}
+	}
+	return list, err
 }
 
 // ListDropshipPurchasesByShopAndDate returns all dropship purchases for a given shop_username
@@ -329,40 +405,12 @@ func (r *DropshipRepo) ListDropshipPurchasesFiltered(
 		"created_at":              "waktu_pesanan_terbuat", // Alias for convenience
 	}
 
-	baseQuery := "SELECT * FROM dropship_purchases"
-
-	// Build query with filters
-	qb := NewQueryBuilder(baseQuery).SetAllowedFields(allowedFields)
-
-	// Get total count first
-	countQuery, countArgs, err := qb.BuildCountQuery(params)
-	if err != nil {
-		return nil, fmt.Errorf("failed to build count query: %w", err)
-	}
-
-	var total int
-	if err := r.db.GetContext(ctx, &total, countQuery, countArgs...); err != nil {
-		return nil, fmt.Errorf("failed to get total count: %w", err)
-	}
-
-	// Build main query with pagination
-	query, args, err := qb.BuildQuery(params)
-	if err != nil {
-		return nil, fmt.Errorf("failed to build query: %w", err)
-	}
-
-	// Execute query
-	var list []models.DropshipPurchase
-	if err := r.db.SelectContext(ctx, &list, query, args...); err != nil {
-		return nil, fmt.Errorf("failed to execute query: %w", err)
-	}
-
-	if list == nil {
-		list = []models.DropshipPurchase{}
-	}
-
-	// Return structured result
-	return models.NewQueryResult(list, total, params.Pagination.Page, params.Pagination.PageSize), nil
+	return listFiltered[models.DropshipPurchase](ctx, r.db, filteredList{
+		base:   "SELECT * FROM dropship_purchases",
+		fields: allowedFields,
+		keys:   []string{"kode_pesanan"},
+		sort:   models.SortCondition{Field: "waktu_pesanan_terbuat", Direction: "desc"},
+	}, params)
 }
 
 // SumDropshipPurchases returns the total sum of total_transaksi for all rows
@@ -370,13 +418,13 @@ func (r *DropshipRepo) ListDropshipPurchasesFiltered(
 func (r *DropshipRepo) SumDropshipPurchases(
 	ctx context.Context,
 	channel, store, from, to string,
-) (float64, error) {
+) (money.Amount, error) {
 	query := `SELECT COALESCE(SUM(total_transaksi),0) FROM dropship_purchases
                 WHERE ($1 = '' OR jenis_channel = $1)
                   AND ($2 = '' OR nama_toko = $2)
                   AND ($3 = '' OR DATE(waktu_pesanan_terbuat) >= $3::date)
                   AND ($4 = '' OR DATE(waktu_pesanan_terbuat) <= $4::date)`
-	var sum float64
+	var sum money.Amount
 	if err := r.db.GetContext(ctx, &sum, query,
 		channel, store, from, to); err != nil {
 		return 0, err
@@ -4,10 +4,13 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
+	"github.com/ramadhan22/dropship-erp/backend/internal/lifecycle"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 )
 
 // tempCleanupDropship deletes any row with the given purchaseID.
@@ -29,9 +32,9 @@ func TestInsertAndGetDropshipPurchase(t *testing.T) {
 		KodeTransaksi:         "TRX-1",
 		WaktuPesananTerbuat:   time.Now(),
 		StatusPesananTerakhir: "baru",
-		BiayaLainnya:          1.0,
-		BiayaMitraJakmall:     0.5,
-		TotalTransaksi:        10.5,
+		BiayaLainnya:          money.New(1),
+		BiayaMitraJakmall:     money.MustParse("0.5"),
+		TotalTransaksi:        money.MustParse("10.5"),
 		DibuatOleh:            "user",
 		JenisChannel:          "online",
 		NamaToko:              "TestShop",
@@ -61,3 +64,61 @@ func TestInsertAndGetDropshipPurchase(t *testing.T) {
 	// Cleanup
 	tempCleanupDropship(t, kode)
 }
+
+func TestTransitionPurchaseStatus(t *testing.T) {
+	ctx := context.Background()
+	repo := NewDropshipRepo(testDB)
+
+	kode := "TEST-LC-" + time.Now().Format("20060102150405")
+	ds := &models.DropshipPurchase{
+		KodePesanan:           kode,
+		WaktuPesananTerbuat:   time.Now(),
+		StatusPesananTerakhir: "Diproses",
+		NamaToko:              "TestShop",
+		KodeInvoiceChannel:    "INV-LC",
+	}
+	if err := repo.InsertDropshipPurchase(ctx, ds); err != nil {
+		t.Fatalf("InsertDropshipPurchase failed: %v", err)
+	}
+	defer tempCleanupDropship(t, kode)
+
+	steps := []lifecycle.Change{
+		{To: lifecycle.PendingSale, Cause: lifecycle.CausePendingJournal},
+		{To: lifecycle.Settled, Cause: lifecycle.CauseEscrowSettlement},
+		{To: lifecycle.Settled, Cause: lifecycle.CauseSettledImport}, // no-op
+	}
+	for _, c := range steps {
+		if err := repo.TransitionPurchaseStatus(ctx, kode, c); err != nil {
+			t.Fatalf("transition to %s: %v", c.To, err)
+		}
+	}
+	err := repo.TransitionPurchaseStatus(ctx, kode, lifecycle.Change{To: lifecycle.Cancelled, Cause: lifecycle.CauseManualCancel})
+	if !errors.Is(err, lifecycle.ErrInvalidTransition) {
+		t.Fatalf("expected ErrInvalidTransition, got %v", err)
+	}
+
+	dp, err := repo.GetDropshipPurchaseByID(ctx, kode)
+	if err != nil {
+		t.Fatalf("GetDropshipPurchaseByID failed: %v", err)
+	}
+	if dp.LifecycleStatus != lifecycle.Settled || dp.StatusPesananTerakhir != "Pesanan selesai" {
+		t.Errorf("unexpected status %q / %q", dp.LifecycleStatus, dp.StatusPesananTerakhir)
+	}
+
+	events, err := repo.ListPurchaseStatusHistory(ctx, kode)
+	if err != nil {
+		t.Fatalf("ListPurchaseStatusHistory failed: %v", err)
+	}
+	want := []lifecycle.Status{lifecycle.Imported, lifecycle.PendingSale, lifecycle.Settled}
+	if len(events) != len(want) {
+		t.Fatalf("expected %d events, got %+v", len(want), events)
+	}
+	for i, ev := range events {
+		if ev.ToStatus != want[i] {
+			t.Errorf("event %d = %s, want %s", i, ev.ToStatus, want[i])
+		}
+	}
+	if events[0].FromStatus != nil || *events[2].FromStatus != lifecycle.PendingSale {
+		t.Errorf("unexpected from statuses: %+v", events)
+	}
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+)
+
+// EntityRepo manages entities, the stores they own, their charts of
+// accounts and the intercompany transactions between them.
+type EntityRepo struct{ db DBTX }
+
+// NewEntityRepo constructs an EntityRepo.
+func NewEntityRepo(db DBTX) *EntityRepo { return &EntityRepo{db: db} }
+
+// CreateEntity inserts an entity and fills in its ID and timestamps.
+func (r *EntityRepo) CreateEntity(ctx context.Context, e *models.Entity) error {
+	return r.db.QueryRowxContext(ctx,
+		`INSERT INTO entities (code, name, tax_id, address)
+          VALUES ($1,$2,$3,$4) RETURNING id, is_default, created_at, updated_at`,
+		e.Code, e.Name, e.TaxID, e.Address,
+	).Scan(&e.ID, &e.IsDefault, &e.CreatedAt, &e.UpdatedAt)
+}
+
+// UpdateEntity saves an entity. Which entity is the default does not change.
+func (r *EntityRepo) UpdateEntity(ctx context.Context, e *models.Entity) error {
+	return r.db.QueryRowxContext(ctx,
+		`UPDATE entities SET code=$2, name=$3, tax_id=$4, address=$5, updated_at=NOW()
+          WHERE id=$1 RETURNING is_default, created_at, updated_at`,
+		e.ID, e.Code, e.Name, e.TaxID, e.Address,
+	).Scan(&e.IsDefault, &e.CreatedAt, &e.UpdatedAt)
+}
+
+// DeleteEntity removes an entity. It fails while stores or journals still
+// reference it.
+func (r *EntityRepo) DeleteEntity(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM entities WHERE id=$1`, id)
+	return err
+}
+
+// GetEntity fetches an entity by ID.
+func (r *EntityRepo) GetEntity(ctx context.Context, id int64) (*models.Entity, error) {
+	var e models.Entity
+	if err := r.db.GetContext(ctx, &e, `SELECT * FROM entities WHERE id=$1`, id); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// ListEntities returns every entity ordered by code.
+func (r *EntityRepo) ListEntities(ctx context.Context) ([]models.Entity, error) {
+	var list []models.Entity
+	err := r.db.SelectContext(ctx, &list, `SELECT * FROM entities ORDER BY code`)
+	if list == nil {
+		list = []models.Entity{}
+	}
+	return list, err
+}
+
+// AssignStore moves a store to an entity. Journals already booked stay in
+// the entity they were booked in.
+func (r *EntityRepo) AssignStore(ctx context.Context, store string, entityID int64) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE stores SET entity_id=$2 WHERE nama_toko=$1`, store, entityID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ListStores returns the names of the stores an entity owns. Stores without
+// an entity belong to the default entity.
+func (r *EntityRepo) ListStores(ctx context.Context, entityID int64) ([]string, error) {
+	var list []string
+	err := r.db.SelectContext(ctx, &list,
+		`SELECT s.nama_toko FROM stores s
+          WHERE s.entity_id = $1
+             OR (s.entity_id IS NULL AND EXISTS (SELECT 1 FROM entities WHERE id = $1 AND is_default))
+          ORDER BY s.nama_toko`, entityID)
+	if list == nil {
+		list = []string{}
+	}
+	return list, err
+}
+
+// ListAccounts returns the chart of accounts of an entity: the shared
+// accounts plus its own.
+func (r *EntityRepo) ListAccounts(ctx context.Context, entityID int64) ([]models.Account, error) {
+	var list []models.Account
+	err := r.db.SelectContext(ctx, &list,
+		`SELECT * FROM accounts WHERE entity_id IS NULL OR entity_id = $1 ORDER BY account_code`, entityID)
+	if list == nil {
+		list = []models.Account{}
+	}
+	return list, err
+}
+
+// GetAccount fetches an account by ID.
+func (r *EntityRepo) GetAccount(ctx context.Context, id int64) (*models.Account, error) {
+	var a models.Account
+	if err := r.db.GetContext(ctx, &a, `SELECT * FROM accounts WHERE account_id=$1`, id); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// IntercompanyAccountIDs returns the accounts eliminated on consolidation.
+func (r *EntityRepo) IntercompanyAccountIDs(ctx context.Context) ([]int64, error) {
+	var ids []int64
+	err := r.db.SelectContext(ctx, &ids, `SELECT account_id FROM accounts WHERE intercompany ORDER BY account_id`)
+	return ids, err
+}
+
+// CreateIntercompany inserts an intercompany transaction and fills in its
+// ID and creation time.
+func (r *EntityRepo) CreateIntercompany(ctx context.Context, t *models.IntercompanyTransaction) error {
+	return r.db.QueryRowxContext(ctx,
+		`INSERT INTO intercompany_transactions
+            (from_entity_id, to_entity_id, txn_date, amount, from_account_id, to_account_id, description)
+          VALUES ($1,$2,$3,$4,$5,$6,$7) RETURNING id, created_at`,
+		t.FromEntityID, t.ToEntityID, t.TxnDate, t.Amount, t.FromAccountID, t.ToAccountID, t.Description,
+	).Scan(&t.ID, &t.CreatedAt)
+}
+
+// GetIntercompany fetches an intercompany transaction by ID.
+func (r *EntityRepo) GetIntercompany(ctx context.Context, id int64) (*models.IntercompanyTransaction, error) {
+	var t models.IntercompanyTransaction
+	if err := r.db.GetContext(ctx, &t, `SELECT * FROM intercompany_transactions WHERE id=$1`, id); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// ListIntercompany returns transactions dated between from and to that
+// involve entityID on either side, or all of them when entityID is zero.
+func (r *EntityRepo) ListIntercompany(ctx context.Context, entityID int64, from, to time.Time) ([]models.IntercompanyTransaction, error) {
+	var list []models.IntercompanyTransaction
+	err := r.db.SelectContext(ctx, &list,
+		`SELECT * FROM intercompany_transactions
+          WHERE txn_date BETWEEN $2 AND $3
+            AND ($1 = 0 OR from_entity_id = $1 OR to_entity_id = $1)
+          ORDER BY txn_date, id`, entityID, from, to)
+	if list == nil {
+		list = []models.IntercompanyTransaction{}
+	}
+	return list, err
+}
+
+// MarkIntercompanyReversed records when a transaction was reversed.
+func (r *EntityRepo) MarkIntercompanyReversed(ctx context.Context, id int64, at time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE intercompany_transactions SET reversed_at=$2 WHERE id=$1`, id, at)
+	return err
+}
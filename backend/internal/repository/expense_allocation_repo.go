@@ -0,0 +1,393 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/ramadhan22/dropship-erp/backend/internal/lifecycle"
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
+)
+
+// ExpenseAllocationRepo manages allocation rules, the allocations made from
+// them and the store figures revenue and order rules are based on.
+type ExpenseAllocationRepo struct{ db DBTX }
+
+// NewExpenseAllocationRepo constructs an ExpenseAllocationRepo.
+func NewExpenseAllocationRepo(db DBTX) *ExpenseAllocationRepo {
+	return &ExpenseAllocationRepo{db: db}
+}
+
+// CreateRule inserts a rule with its stores and fills in its ID and
+// timestamps.
+func (r *ExpenseAllocationRepo) CreateRule(ctx context.Context, rule *models.ExpenseAllocationRule) error {
+	err := r.db.QueryRowxContext(ctx,
+		`INSERT INTO expense_allocation_rules (name, method, description)
+          VALUES ($1,$2,$3) RETURNING id, created_at, updated_at`,
+		rule.Name, rule.Method, rule.Description).Scan(&rule.ID, &rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	return r.insertRuleStores(ctx, rule)
+}
+
+// UpdateRule saves a rule and replaces its stores.
+func (r *ExpenseAllocationRepo) UpdateRule(ctx context.Context, rule *models.ExpenseAllocationRule) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE expense_allocation_rules SET name=$2, method=$3, description=$4, updated_at=NOW() WHERE id=$1`,
+		rule.ID, rule.Name, rule.Method, rule.Description)
+	if err != nil {
+		return err
+	}
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM expense_allocation_rule_stores WHERE rule_id=$1`, rule.ID); err != nil {
+		return err
+	}
+	return r.insertRuleStores(ctx, rule)
+}
+
+func (r *ExpenseAllocationRepo) insertRuleStores(ctx context.Context, rule *models.ExpenseAllocationRule) error {
+	for i := range rule.Stores {
+		s := &rule.Stores[i]
+		s.RuleID = rule.ID
+		if _, err := r.db.ExecContext(ctx,
+			`INSERT INTO expense_allocation_rule_stores (rule_id, store, weight) VALUES ($1,$2,$3)`,
+			s.RuleID, s.Store, s.Weight); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteRule removes a rule and its stores. The foreign key on
+// expense_allocations refuses to delete a rule that has allocations.
+func (r *ExpenseAllocationRepo) DeleteRule(ctx context.Context, id int64) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM expense_allocation_rule_stores WHERE rule_id=$1`, id); err != nil {
+		return err
+	}
+	_, err := r.db.ExecContext(ctx, `DELETE FROM expense_allocation_rules WHERE id=$1`, id)
+	return err
+}
+
+// GetRule fetches a rule with its stores.
+func (r *ExpenseAllocationRepo) GetRule(ctx context.Context, id int64) (*models.ExpenseAllocationRule, error) {
+	var rule models.ExpenseAllocationRule
+	if err := r.db.GetContext(ctx, &rule, `SELECT * FROM expense_allocation_rules WHERE id=$1`, id); err != nil {
+		return nil, err
+	}
+	list := []models.ExpenseAllocationRule{rule}
+	if err := r.attachRuleStores(ctx, list); err != nil {
+		return nil, err
+	}
+	return &list[0], nil
+}
+
+// ListRules returns all rules ordered by name.
+func (r *ExpenseAllocationRepo) ListRules(ctx context.Context) ([]models.ExpenseAllocationRule, error) {
+	var list []models.ExpenseAllocationRule
+	if err := r.db.SelectContext(ctx, &list, `SELECT * FROM expense_allocation_rules ORDER BY name, id`); err != nil {
+		return nil, err
+	}
+	if list == nil {
+		list = []models.ExpenseAllocationRule{}
+	}
+	return list, r.attachRuleStores(ctx, list)
+}
+
+func (r *ExpenseAllocationRepo) attachRuleStores(ctx context.Context, list []models.ExpenseAllocationRule) error {
+	if len(list) == 0 {
+		return nil
+	}
+	ids := make([]interface{}, len(list))
+	for i, rule := range list {
+		ids[i] = rule.ID
+	}
+	query, args, err := sqlx.In(`SELECT * FROM expense_allocation_rule_stores WHERE rule_id IN (?) ORDER BY store`, ids)
+	if err != nil {
+		return err
+	}
+	var stores []models.ExpenseAllocationRuleStore
+	if err := r.db.SelectContext(ctx, &stores, r.db.Rebind(query), args...); err != nil {
+		return err
+	}
+	byRule := map[int64][]models.ExpenseAllocationRuleStore{}
+	for _, s := range stores {
+		byRule[s.RuleID] = append(byRule[s.RuleID], s)
+	}
+	for i := range list {
+		list[i].Stores = byRule[list[i].ID]
+		if list[i].Stores == nil {
+			list[i].Stores = []models.ExpenseAllocationRuleStore{}
+		}
+	}
+	return nil
+}
+
+// StoreAmount is a per-store figure used as an allocation basis.
+type StoreAmount struct {
+	Store  string  `db:"store"`
+	Amount float64 `db:"amount"`
+}
+
+// RevenueByStore returns the net revenue (credits less debits on Revenue
+// accounts) each store booked between from and to inclusive.
+func (r *ExpenseAllocationRepo) RevenueByStore(ctx context.Context, from, to time.Time) ([]StoreAmount, error) {
+	var rows []struct {
+		Store  string       `db:"store"`
+		Amount money.Amount `db:"amount"`
+	}
+	err := r.db.SelectContext(ctx, &rows,
+		`SELECT d.shop_username AS store, SUM(d.credit_total - d.debit_total) AS amount
+           FROM account_balance_daily d
+           JOIN accounts a ON a.account_id = d.account_id
+          WHERE a.account_type = 'Revenue' AND d.shop_username <> ''
+            AND d.balance_date BETWEEN $1 AND $2
+          GROUP BY d.shop_username
+          ORDER BY d.shop_username`, from, to)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]StoreAmount, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, StoreAmount{Store: row.Store, Amount: row.Amount.Float64()})
+	}
+	return out, nil
+}
+
+// OrderCountByStore returns how many non-cancelled dropship orders each
+// store created between from and to inclusive.
+func (r *ExpenseAllocationRepo) OrderCountByStore(ctx context.Context, from, to time.Time) ([]StoreAmount, error) {
+	var out []StoreAmount
+	err := r.db.SelectContext(ctx, &out,
+		`SELECT nama_toko AS store, COUNT(*)::float8 AS amount
+           FROM dropship_purchases
+          WHERE nama_toko <> '' AND lifecycle_status <> $3
+            AND waktu_pesanan_terbuat >= $1 AND waktu_pesanan_terbuat < $2::date + 1
+          GROUP BY nama_toko
+          ORDER BY nama_toko`, from, to, lifecycle.Cancelled)
+	if out == nil {
+		out = []StoreAmount{}
+	}
+	return out, err
+}
+
+// allocationColumns selects an allocation with a journal allocation's
+// missing expense ID read as an empty string.
+const allocationColumns = `id, COALESCE(expense_id::text, '') AS expense_id, source_journal_id, rule_id,
+       method, basis_from, basis_to, total, pool_journal_id, reversed_at, created_at`
+
+// InsertAllocation stores an allocation with its lines and fills in their
+// IDs.
+func (r *ExpenseAllocationRepo) InsertAllocation(ctx context.Context, a *models.ExpenseAllocation) error {
+	err := r.db.QueryRowxContext(ctx,
+		`INSERT INTO expense_allocations (expense_id, source_journal_id, rule_id, method, basis_from, basis_to, total, pool_journal_id)
+          VALUES (NULLIF($1, '')::uuid,$2,$3,$4,$5,$6,$7,$8) RETURNING id, created_at`,
+		a.ExpenseID, a.SourceJournalID, a.RuleID, a.Method, a.BasisFrom, a.BasisTo, a.Total, a.PoolJournalID).Scan(&a.ID, &a.CreatedAt)
+	if err != nil {
+		return err
+	}
+	for i := range a.Lines {
+		l := &a.Lines[i]
+		l.AllocationID = a.ID
+		err := r.db.QueryRowxContext(ctx,
+			`INSERT INTO expense_allocation_lines (allocation_id, store, account_id, share, amount, journal_id)
+             VALUES ($1,$2,$3,$4,$5,$6) RETURNING id`,
+			l.AllocationID, l.Store, l.AccountID, l.Share, l.Amount, l.JournalID).Scan(&l.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetAllocation fetches an allocation with its lines.
+func (r *ExpenseAllocationRepo) GetAllocation(ctx context.Context, id int64) (*models.ExpenseAllocation, error) {
+	var a models.ExpenseAllocation
+	if err := r.db.GetContext(ctx, &a, `SELECT `+allocationColumns+` FROM expense_allocations WHERE id=$1`, id); err != nil {
+		return nil, err
+	}
+	list := []models.ExpenseAllocation{a}
+	if err := r.attachAllocationLines(ctx, list); err != nil {
+		return nil, err
+	}
+	return &list[0], nil
+}
+
+// GetActiveAllocation returns the allocation of an expense that has not
+// been reversed, or nil when there is none.
+func (r *ExpenseAllocationRepo) GetActiveAllocation(ctx context.Context, expenseID string) (*models.ExpenseAllocation, error) {
+	var list []models.ExpenseAllocation
+	if err := r.db.SelectContext(ctx, &list,
+		`SELECT `+allocationColumns+` FROM expense_allocations WHERE expense_id=$1 AND reversed_at IS NULL`, expenseID); err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, nil
+	}
+	if err := r.attachAllocationLines(ctx, list); err != nil {
+		return nil, err
+	}
+	return &list[0], nil
+}
+
+// ListAllocations returns allocations newest first. A non-empty expenseID
+// lists only the allocations of that expense.
+func (r *ExpenseAllocationRepo) ListAllocations(ctx context.Context, expenseID string, limit, offset int) ([]models.ExpenseAllocation, error) {
+	var list []models.ExpenseAllocation
+	err := r.db.SelectContext(ctx, &list,
+		`SELECT `+allocationColumns+` FROM expense_allocations
+          WHERE ($1 = '' OR expense_id::text = $1)
+          ORDER BY created_at DESC, id DESC LIMIT $2 OFFSET $3`, expenseID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if list == nil {
+		list = []models.ExpenseAllocation{}
+	}
+	return list, r.attachAllocationLines(ctx, list)
+}
+
+func (r *ExpenseAllocationRepo) attachAllocationLines(ctx context.Context, list []models.ExpenseAllocation) error {
+	if len(list) == 0 {
+		return nil
+	}
+	ids := make([]interface{}, len(list))
+	for i, a := range list {
+		ids[i] = a.ID
+	}
+	query, args, err := sqlx.In(`SELECT * FROM expense_allocation_lines WHERE allocation_id IN (?) ORDER BY store, account_id`, ids)
+	if err != nil {
+		return err
+	}
+	var lines []models.ExpenseAllocationLine
+	if err := r.db.SelectContext(ctx, &lines, r.db.Rebind(query), args...); err != nil {
+		return err
+	}
+	byAlloc := map[int64][]models.ExpenseAllocationLine{}
+	for _, l := range lines {
+		byAlloc[l.AllocationID] = append(byAlloc[l.AllocationID], l)
+	}
+	for i := range list {
+		list[i].Lines = byAlloc[list[i].ID]
+		if list[i].Lines == nil {
+			list[i].Lines = []models.ExpenseAllocationLine{}
+		}
+	}
+	return nil
+}
+
+// SetJournals records the journals posted for an allocation and its lines.
+func (r *ExpenseAllocationRepo) SetJournals(ctx context.Context, a *models.ExpenseAllocation) error {
+	if _, err := r.db.ExecContext(ctx,
+		`UPDATE expense_allocations SET pool_journal_id=$2 WHERE id=$1`, a.ID, a.PoolJournalID); err != nil {
+		return err
+	}
+	for _, l := range a.Lines {
+		if _, err := r.db.ExecContext(ctx,
+			`UPDATE expense_allocation_lines SET journal_id=$2 WHERE id=$1`, l.ID, l.JournalID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MarkReversed records that an allocation's journals were reversed.
+func (r *ExpenseAllocationRepo) MarkReversed(ctx context.Context, id int64, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE expense_allocations SET reversed_at=$2 WHERE id=$1`, id, at)
+	return err
+}
+
+// ListUnallocatedExpenses returns expenses dated between from and to
+// inclusive that are not booked to a store, charge at least one Expense
+// account and have no active allocation. Prepaid payments and accrual
+// settlements only touch balance sheet accounts and are left out.
+func (r *ExpenseAllocationRepo) ListUnallocatedExpenses(ctx context.Context, from, to time.Time) ([]models.Expense, error) {
+	var list []models.Expense
+	err := r.db.SelectContext(ctx, &list,
+		`SELECT * FROM expenses e
+          WHERE e.store = '' AND e.date >= $1 AND e.date < $2::date + 1
+            AND EXISTS (SELECT 1 FROM expense_lines l
+                          JOIN accounts acc ON acc.account_id = l.account_id
+                         WHERE l.expense_id = e.id AND acc.account_type = 'Expense')
+            AND NOT EXISTS (SELECT 1 FROM expense_allocations a
+                             WHERE a.expense_id = e.id AND a.reversed_at IS NULL)
+          ORDER BY e.date, e.id`, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if list == nil {
+		list = []models.Expense{}
+	}
+	return list, NewExpenseRepo(r.db).attachLines(ctx, list)
+}
+
+// UnallocatedJournal is a store-less recurring amortisation or accrual
+// journal, reduced to its Expense account lines.
+type UnallocatedJournal struct {
+	JournalID   int64
+	EntryDate   time.Time
+	Description string
+	Lines       []models.ExpenseLine
+}
+
+// ListUnallocatedJournals returns the recurring expense amortisation and
+// accrual journals dated between from and to inclusive that are not booked
+// to a store and have no active allocation. Only their Expense account
+// lines are returned; the prepaid and accrued accounts stay with the pool.
+func (r *ExpenseAllocationRepo) ListUnallocatedJournals(ctx context.Context, from, to time.Time) ([]UnallocatedJournal, error) {
+	var rows []struct {
+		JournalID   int64        `db:"journal_id"`
+		EntryDate   time.Time    `db:"entry_date"`
+		Description string       `db:"description"`
+		AccountID   int64        `db:"account_id"`
+		Amount      money.Amount `db:"amount"`
+	}
+	err := r.db.SelectContext(ctx, &rows,
+		`SELECT je.journal_id, je.entry_date, COALESCE(je.description, '') AS description, jl.account_id,
+                SUM(CASE WHEN jl.is_debit THEN jl.amount ELSE -jl.amount END) AS amount
+           FROM journal_entries je
+           JOIN journal_lines jl ON jl.journal_id = je.journal_id
+           JOIN accounts acc ON acc.account_id = jl.account_id
+          WHERE je.source_type IN ('expense_amortization', 'expense_accrual')
+            AND je.shop_username = '' AND acc.account_type = 'Expense'
+            AND je.entry_date >= $1 AND je.entry_date < $2::date + 1
+            AND NOT EXISTS (SELECT 1 FROM expense_allocations a
+                             WHERE a.source_journal_id = je.journal_id AND a.reversed_at IS NULL)
+          GROUP BY je.journal_id, je.entry_date, je.description, jl.account_id
+          ORDER BY je.entry_date, je.journal_id, jl.account_id`, from, to)
+	if err != nil {
+		return nil, err
+	}
+	out := []UnallocatedJournal{}
+	for _, row := range rows {
+		if n := len(out); n == 0 || out[n-1].JournalID != row.JournalID {
+			out = append(out, UnallocatedJournal{JournalID: row.JournalID, EntryDate: row.EntryDate, Description: row.Description})
+		}
+		out[len(out)-1].Lines = append(out[len(out)-1].Lines, models.ExpenseLine{AccountID: row.AccountID, Amount: row.Amount})
+	}
+	return out, nil
+}
+
+// ExpenseAccounts reports which of ids are Expense accounts.
+func (r *ExpenseAllocationRepo) ExpenseAccounts(ctx context.Context, ids []int64) (map[int64]bool, error) {
+	out := map[int64]bool{}
+	if len(ids) == 0 {
+		return out, nil
+	}
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	query, args, err := sqlx.In(`SELECT account_id FROM accounts WHERE account_type = 'Expense' AND account_id IN (?)`, args)
+	if err != nil {
+		return nil, err
+	}
+	var found []int64
+	if err := r.db.SelectContext(ctx, &found, r.db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+	for _, id := range found {
+		out[id] = true
+	}
+	return out, nil
+}
@@ -3,7 +3,6 @@ package repository
 import (
 	"context"
 	"fmt"
-	"log"
 	"strings"
 
 	"github.com/google/uuid"
@@ -17,13 +16,15 @@ type ExpenseRepo struct{ db DBTX }
 func NewExpenseRepo(db DBTX) *ExpenseRepo { return &ExpenseRepo{db: db} }
 
 func (r *ExpenseRepo) Create(ctx context.Context, e *models.Expense) error {
-	log.Printf("ExpenseRepo.Create %s", e.ID)
+	logutil.Debug(ctx, "ExpenseRepo.Create", "Inserting expense", map[string]interface{}{
+		"expense_id": e.ID,
+	})
 	// Ensure an ID exists so that expense_lines can reference it
 	if e.ID == "" {
 		e.ID = uuid.NewString()
 	}
-	query := `INSERT INTO expenses (id, date, description, amount, asset_account_id)
-       VALUES (:id,:date,:description,:amount,:asset_account_id) RETURNING id`
+	query := `INSERT INTO expenses (id, date, description, amount, asset_account_id, store, allocation_rule_id)
+       VALUES (:id,:date,:description,:amount,:asset_account_id,:store,:allocation_rule_id) RETURNING id`
 	q, args, err := sqlx.Named(query, e)
 	if err != nil {
 		return err
@@ -42,7 +43,9 @@ func (r *ExpenseRepo) Create(ctx context.Context, e *models.Expense) error {
 			return err
 		}
 	}
-	log.Printf("ExpenseRepo.Create done %s", e.ID)
+	logutil.Debug(ctx, "ExpenseRepo.Create", "Expense inserted", map[string]interface{}{
+		"expense_id": e.ID,
+	})
 	return nil
 }
 
@@ -96,8 +99,41 @@ func (r *ExpenseRepo) List(ctx context.Context, accountID int64, sortBy, dir str
 	if list == nil {
 		list = []models.Expense{}
 	}
+	if err := r.attachLines(ctx, list); err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
+
+// ListFiltered returns expenses with their lines using the filtering framework.
+func (r *ExpenseRepo) ListFiltered(ctx context.Context, params *models.FilterParams) (*models.QueryResult, error) {
+	result, err := listFiltered[models.Expense](ctx, r.db, filteredList{
+		base: "SELECT * FROM expenses",
+		fields: map[string]string{
+			"id":               "id",
+			"date":             "date",
+			"description":      "description",
+			"amount":           "amount",
+			"asset_account_id": "asset_account_id",
+			"store":            "store",
+			"created_at":       "created_at",
+		},
+		keys: []string{"id"},
+		sort: models.SortCondition{Field: "date", Direction: "desc"},
+	}, params)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.attachLines(ctx, result.Data.([]models.Expense)); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// attachLines loads the lines of every expense in list.
+func (r *ExpenseRepo) attachLines(ctx context.Context, list []models.Expense) error {
 	if len(list) == 0 {
-		return list, total, nil
+		return nil
 	}
 	ids := make([]interface{}, len(list))
 	for i, e := range list {
@@ -105,12 +141,12 @@ func (r *ExpenseRepo) List(ctx context.Context, accountID int64, sortBy, dir str
 	}
 	queryLines, args, err := sqlx.In(`SELECT * FROM expense_lines WHERE expense_id IN (?) ORDER BY line_id`, ids)
 	if err != nil {
-		return nil, 0, err
+		return err
 	}
 	queryLines = r.db.Rebind(queryLines)
 	var lines []models.ExpenseLine
 	if err := r.db.SelectContext(ctx, &lines, queryLines, args...); err != nil {
-		return nil, 0, err
+		return err
 	}
 	lineMap := map[string][]models.ExpenseLine{}
 	for _, l := range lines {
@@ -119,13 +155,15 @@ func (r *ExpenseRepo) List(ctx context.Context, accountID int64, sortBy, dir str
 	for i := range list {
 		list[i].Lines = lineMap[list[i].ID]
 	}
-	return list, total, nil
+	return nil
 }
 
 func (r *ExpenseRepo) Update(ctx context.Context, e *models.Expense) error {
-	log.Printf("ExpenseRepo.Update %s", e.ID)
+	logutil.Debug(ctx, "ExpenseRepo.Update", "Updating expense", map[string]interface{}{
+		"expense_id": e.ID,
+	})
 	_, err := r.db.NamedExecContext(ctx,
-		`UPDATE expenses SET date=:date, description=:description, amount=:amount, asset_account_id=:asset_account_id WHERE id=:id`, e)
+		`UPDATE expenses SET date=:date, description=:description, amount=:amount, asset_account_id=:asset_account_id, store=:store, allocation_rule_id=:allocation_rule_id WHERE id=:id`, e)
 	if err != nil {
 		logutil.Errorf("ExpenseRepo.Update error: %v", err)
 		return err
@@ -144,12 +182,16 @@ func (r *ExpenseRepo) Update(ctx context.Context, e *models.Expense) error {
 			return err
 		}
 	}
-	log.Printf("ExpenseRepo.Update done %s", e.ID)
+	logutil.Debug(ctx, "ExpenseRepo.Update", "Expense updated", map[string]interface{}{
+		"expense_id": e.ID,
+	})
 	return nil
 }
 
 func (r *ExpenseRepo) Delete(ctx context.Context, id string) error {
-	log.Printf("ExpenseRepo.Delete %s", id)
+	logutil.Debug(ctx, "ExpenseRepo.Delete", "Deleting expense", map[string]interface{}{
+		"expense_id": id,
+	})
 	_, err := r.db.ExecContext(ctx, `DELETE FROM expenses WHERE id=$1`, id)
 	if err != nil {
 		logutil.Errorf("ExpenseRepo.Delete error: %v", err)
@@ -24,12 +24,14 @@ func (r *FailedReconciliationRepo) InsertFailedReconciliation(
 ) error {
 	query := `
         INSERT INTO failed_reconciliations
-          (purchase_id, order_id, shop, error_type, error_message, context, failed_at, batch_id)
-        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
-	_, err := r.db.ExecContext(ctx, query,
+          (purchase_id, order_id, shop, error_type, error_message, context, failed_at, batch_id,
+           status, max_attempts, next_retry_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+        RETURNING id`
+	return r.db.QueryRowxContext(ctx, query,
 		failed.PurchaseID, failed.OrderID, failed.Shop, failed.ErrorType,
-		failed.ErrorMsg, failed.Context, failed.FailedAt, failed.BatchID)
-	return err
+		failed.ErrorMsg, failed.Context, failed.FailedAt, failed.BatchID,
+		failed.Status, failed.MaxAttempts, failed.NextRetryAt).Scan(&failed.ID)
 }
 
 // GetFailedReconciliationsByShop retrieves failed reconciliations for a specific shop.
@@ -95,19 +97,21 @@ func (r *FailedReconciliationRepo) CountFailedReconciliationsByErrorType(
 	return result, rows.Err()
 }
 
-// MarkAsRetried updates a failed reconciliation as retried.
-func (r *FailedReconciliationRepo) MarkAsRetried(
+// GetFailedReconciliation returns one failed reconciliation by id.
+func (r *FailedReconciliationRepo) GetFailedReconciliation(
 	ctx context.Context,
 	id int64,
-) error {
-	query := `UPDATE failed_reconciliations 
-              SET retried = TRUE, retried_at = NOW() 
-              WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
-	return err
+) (*models.FailedReconciliation, error) {
+	var f models.FailedReconciliation
+	err := r.db.GetContext(ctx, &f, `SELECT * FROM failed_reconciliations WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
 }
 
-// GetUnretriedFailedReconciliations gets failed reconciliations that haven't been retried.
+// GetUnretriedFailedReconciliations gets pending failed reconciliations of a
+// shop, oldest first, whether or not their next retry is due.
 func (r *FailedReconciliationRepo) GetUnretriedFailedReconciliations(
 	ctx context.Context,
 	shop string,
@@ -115,16 +119,152 @@ func (r *FailedReconciliationRepo) GetUnretriedFailedReconciliations(
 ) ([]models.FailedReconciliation, error) {
 	var list []models.FailedReconciliation
 	query := `SELECT * FROM failed_reconciliations 
-              WHERE shop = $1 AND retried = FALSE 
+              WHERE shop = $1 AND status = $2 
               ORDER BY failed_at ASC 
-              LIMIT $1`
-	err := r.db.SelectContext(ctx, &list, query, shop, limit)
+              LIMIT $3`
+	err := r.db.SelectContext(ctx, &list, query, shop, models.FailedReconPending, limit)
+	if list == nil {
+		list = []models.FailedReconciliation{}
+	}
+	return list, err
+}
+
+// ClaimDueRetries claims up to limit pending failed reconciliations whose
+// next retry is due at now and whose store allows automatic retries, either
+// by its row in reconciliation_policies or by retryByDefault when it has
+// none. Claimed rows have next_retry_at pushed to leaseUntil, so concurrent
+// workers skip them and a crashed worker's rows become due again.
+func (r *FailedReconciliationRepo) ClaimDueRetries(
+	ctx context.Context,
+	now, leaseUntil time.Time,
+	retryByDefault bool,
+	limit int,
+) ([]models.FailedReconciliation, error) {
+	var list []models.FailedReconciliation
+	query := `UPDATE failed_reconciliations SET next_retry_at = $2
+              WHERE id IN (
+                  SELECT f.id FROM failed_reconciliations f
+                  LEFT JOIN reconciliation_policies p ON p.store = f.shop
+                  WHERE f.status = $3 AND f.next_retry_at <= $1
+                    AND COALESCE(p.retry_failed_transactions, $4)
+                  ORDER BY f.next_retry_at
+                  LIMIT $5
+                  FOR UPDATE OF f SKIP LOCKED)
+              RETURNING *`
+	err := r.db.SelectContext(ctx, &list, query, now, leaseUntil, models.FailedReconPending, retryByDefault, limit)
 	if list == nil {
 		list = []models.FailedReconciliation{}
 	}
 	return list, err
 }
 
+// ListFailedReconciliations returns failed reconciliations filtered by
+// status and shop (empty matches all), newest first, with the total count.
+func (r *FailedReconciliationRepo) ListFailedReconciliations(
+	ctx context.Context,
+	status, shop string,
+	limit, offset int,
+) ([]models.FailedReconciliation, int, error) {
+	where := `WHERE ($1 = '' OR status = $1) AND ($2 = '' OR shop = $2)`
+	var total int
+	if err := r.db.GetContext(ctx, &total, `SELECT COUNT(*) FROM failed_reconciliations `+where, status, shop); err != nil {
+		return nil, 0, err
+	}
+	var list []models.FailedReconciliation
+	err := r.db.SelectContext(ctx, &list,
+		`SELECT * FROM failed_reconciliations `+where+` ORDER BY failed_at DESC LIMIT $3 OFFSET $4`,
+		status, shop, limit, offset)
+	if list == nil {
+		list = []models.FailedReconciliation{}
+	}
+	return list, total, err
+}
+
+// ListFailedReconciliationsFiltered returns failed reconciliations using the
+// filtering framework.
+func (r *FailedReconciliationRepo) ListFailedReconciliationsFiltered(
+	ctx context.Context,
+	params *models.FilterParams,
+) (*models.QueryResult, error) {
+	return listFiltered[models.FailedReconciliation](ctx, r.db, filteredList{
+		base: "SELECT * FROM failed_reconciliations",
+		fields: map[string]string{
+			"id":              "id",
+			"purchase_id":     "purchase_id",
+			"order_id":        "order_id",
+			"shop":            "shop",
+			"error_type":      "error_type",
+			"error_message":   "error_message",
+			"failed_at":       "failed_at",
+			"batch_id":        "batch_id",
+			"status":          "status",
+			"attempts":        "attempts",
+			"next_retry_at":   "next_retry_at",
+			"last_attempt_at": "last_attempt_at",
+			"resolved_at":     "resolved_at",
+			"store":           "shop",
+			"order":           "purchase_id",
+			"order_no":        "purchase_id",
+			"created_at":      "failed_at",
+		},
+		keys: []string{"id"},
+		sort: models.SortCondition{Field: "failed_at", Direction: "desc"},
+	}, params)
+}
+
+// RecordAttempt appends a to the attempt history of failed and saves the
+// outcome fields of failed (status, attempts, error, next retry) in one
+// statement.
+func (r *FailedReconciliationRepo) RecordAttempt(
+	ctx context.Context,
+	failed *models.FailedReconciliation,
+	a *models.FailedReconciliationAttempt,
+) error {
+	query := `
+        WITH attempt AS (
+            INSERT INTO failed_reconciliation_attempts
+              (failed_reconciliation_id, attempt, succeeded, error_type, error_message, attempted_at)
+            VALUES ($1, $2, $3, $4, $5, $6)
+        )
+        UPDATE failed_reconciliations
+           SET status = $7, attempts = $2, error_type = $8, error_message = $9,
+               next_retry_at = $10, last_attempt_at = $6, resolved_at = $11
+         WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query,
+		failed.ID, a.Attempt, a.Succeeded, a.ErrorType, a.ErrorMsg, a.AttemptedAt,
+		failed.Status, failed.ErrorType, failed.ErrorMsg, failed.NextRetryAt, failed.ResolvedAt)
+	return err
+}
+
+// MarkNeedsAttention moves a failed reconciliation to the needs_attention
+// queue without recording an attempt.
+func (r *FailedReconciliationRepo) MarkNeedsAttention(
+	ctx context.Context,
+	id int64,
+) error {
+	query := `UPDATE failed_reconciliations 
+              SET status = $2, next_retry_at = NULL 
+              WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id, models.FailedReconNeedsAttention)
+	return err
+}
+
+// ListAttempts returns the attempt history of a failed reconciliation.
+func (r *FailedReconciliationRepo) ListAttempts(
+	ctx context.Context,
+	id int64,
+) ([]models.FailedReconciliationAttempt, error) {
+	var list []models.FailedReconciliationAttempt
+	err := r.db.SelectContext(ctx, &list,
+		`SELECT * FROM failed_reconciliation_attempts 
+          WHERE failed_reconciliation_id = $1 
+          ORDER BY attempt, id`, id)
+	if list == nil {
+		list = []models.FailedReconciliationAttempt{}
+	}
+	return list, err
+}
+
 // DeleteFailedReconciliation removes a failed reconciliation record (useful for cleanup).
 func (r *FailedReconciliationRepo) DeleteFailedReconciliation(
 	ctx context.Context,
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+)
+
+// filteredList describes how a list endpoint maps onto the QueryBuilder:
+// the base query, the fields clients may filter and sort on, the columns
+// that identify a row and the sort used when none is requested.
+type filteredList struct {
+	base   string
+	fields map[string]string
+	keys   []string
+	sort   models.SortCondition
+}
+
+// listFiltered runs a filtered, sorted and paginated query for l and wraps
+// the rows of type T in a QueryResult. Pages are fetched by OFFSET, or by
+// keyset when params carries a cursor; either way NextCursor is set while
+// more rows follow.
+func listFiltered[T any](ctx context.Context, db DBTX, l filteredList, params *models.FilterParams) (*models.QueryResult, error) {
+	if params == nil {
+		params = &models.FilterParams{}
+	}
+	if params.Pagination == nil {
+		params.Pagination = models.NewPaginationParams(1, 20)
+	}
+	qb := NewQueryBuilder(l.base).
+		SetAllowedFields(l.fields).
+		SetKeyColumns(l.keys...).
+		SetDefaultSort(l.sort)
+
+	countQuery, countArgs, err := qb.BuildCountQuery(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build count query: %w", err)
+	}
+	var total int
+	if err := db.GetContext(ctx, &total, countQuery, countArgs...); err != nil {
+		return nil, fmt.Errorf("failed to get total count: %w", err)
+	}
+
+	// Fetch one extra row to learn whether another page follows.
+	page := *params.Pagination
+	page.Limit++
+	peek := *params
+	peek.Pagination = &page
+	query, args, err := qb.BuildQuery(&peek)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var list []T
+	if err := db.SelectContext(ctx, &list, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	if list == nil {
+		list = []T{}
+	}
+
+	var next string
+	if params.Pagination.Limit > 0 && len(list) > params.Pagination.Limit {
+		list = list[:params.Pagination.Limit]
+		next = qb.NextCursor(list[len(list)-1], params)
+	}
+	result := models.NewQueryResult(list, total, params.Pagination.Page, params.Pagination.PageSize)
+	result.NextCursor = next
+	return result, nil
+}
@@ -7,20 +7,24 @@ import (
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/ramadhan22/dropship-erp/backend/internal/apperr"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 )
 
 const insertJournalSQL = `
 INSERT INTO journal_entries (
-  entry_date, description, source_type, source_id, shop_username, store, created_at
+  entry_date, description, source_type, source_id, shop_username, store, entity_id, created_at
 ) VALUES (
-  :entry_date, :description, :source_type, :source_id, :shop_username, :store, :created_at
+  :entry_date, :description, :source_type, :source_id, :shop_username, :store, :entity_id, :created_at
 ) ON CONFLICT (source_type, source_id) DO NOTHING RETURNING journal_id`
 
 // JournalRepo manages the journal_entries and journal_lines tables,
 // which are at the heart of double-entry bookkeeping.
 type JournalRepo struct {
 	db DBTX
+	// entityID limits the balance queries to one entity when non-zero.
+	entityID int64
 }
 
 // NewJournalRepo constructs a new JournalRepo.
@@ -28,6 +32,21 @@ func NewJournalRepo(db DBTX) *JournalRepo {
 	return &JournalRepo{db: db}
 }
 
+// ForEntity returns a copy of r whose account balance queries only see
+// entries booked in entity id.
+func (r *JournalRepo) ForEntity(id int64) *JournalRepo {
+	return &JournalRepo{db: r.db, entityID: id}
+}
+
+// entityFilter appends the entity condition to a balance subquery whose
+// last placeholder is $n, or nothing when r is not scoped.
+func (r *JournalRepo) entityFilter(n int, args []interface{}) (string, []interface{}) {
+	if r.entityID == 0 {
+		return "", args
+	}
+	return fmt.Sprintf("\n             AND entity_id = $%d", n+1), append(args, r.entityID)
+}
+
 // CreateJournalEntry inserts a row into journal_entries and returns the new journal_id.
 // We need this so we can capture the returned primary key for inserting lines.
 func (r *JournalRepo) CreateJournalEntry(ctx context.Context, e *models.JournalEntry) (int64, error) {
@@ -77,7 +96,7 @@ func (r *JournalRepo) InsertJournalLines(ctx context.Context, lines []models.Jou
 	}
 
 	// Validate that debits equal credits
-	var totalDebit, totalCredit float64
+	var totalDebit, totalCredit money.Amount
 	for _, line := range lines {
 		if line.IsDebit {
 			totalDebit += line.Amount
@@ -87,7 +106,7 @@ func (r *JournalRepo) InsertJournalLines(ctx context.Context, lines []models.Jou
 	}
 
 	if totalDebit != totalCredit {
-		return fmt.Errorf("debits %.2f do not equal credits %.2f", totalDebit, totalCredit)
+		return fmt.Errorf("%w: debits %s do not equal credits %s", apperr.ErrUnbalancedJournal, totalDebit, totalCredit)
 	}
 
 	// For single line, use the existing method
@@ -145,23 +164,23 @@ func (r *JournalRepo) GetJournalLinesByShopAndDate(
 // AccountBalance is a helper type for producing Balance Sheet data.
 // It represents the net (debit – credit) balance of one account as of a given date.
 type AccountBalance struct {
-	AccountID   int64   `db:"account_id" json:"account_id"`
-	AccountCode string  `db:"account_code" json:"account_code"`
-	AccountName string  `db:"account_name" json:"account_name"`
-	AccountType string  `db:"account_type" json:"account_type"` // e.g. “Asset”/“Liability”/“Equity”
-	ParentID    *int64  `db:"parent_id" json:"parent_id"`
-	Balance     float64 `db:"balance" json:"balance"`
+	AccountID   int64        `db:"account_id" json:"account_id"`
+	AccountCode string       `db:"account_code" json:"account_code"`
+	AccountName string       `db:"account_name" json:"account_name"`
+	AccountType string       `db:"account_type" json:"account_type"` // e.g. “Asset”/“Liability”/“Equity”
+	ParentID    *int64       `db:"parent_id" json:"parent_id"`
+	Balance     money.Amount `db:"balance" json:"balance"`
 }
 
 // JournalLineDetail represents a journal line joined with its account name.
 type JournalLineDetail struct {
-	LineID      int64   `db:"line_id" json:"line_id"`
-	JournalID   int64   `db:"journal_id" json:"journal_id"`
-	AccountID   int64   `db:"account_id" json:"account_id"`
-	AccountName string  `db:"account_name" json:"account_name"`
-	IsDebit     bool    `db:"is_debit" json:"is_debit"`
-	Amount      float64 `db:"amount" json:"amount"`
-	Memo        *string `db:"memo" json:"memo"`
+	LineID      int64        `db:"line_id" json:"line_id"`
+	JournalID   int64        `db:"journal_id" json:"journal_id"`
+	AccountID   int64        `db:"account_id" json:"account_id"`
+	AccountName string       `db:"account_name" json:"account_name"`
+	IsDebit     bool         `db:"is_debit" json:"is_debit"`
+	Amount      money.Amount `db:"amount" json:"amount"`
+	Memo        *string      `db:"memo" json:"memo"`
 }
 
 // EntryWithLines bundles a journal entry with its lines.
@@ -171,7 +190,8 @@ type EntryWithLines struct {
 }
 
 // GetAccountBalancesAsOf returns each account’s cumulative balance up to and including asOfDate.
-// It sums debit amounts as positive and credit amounts as negative.
+// It sums the daily debit/credit totals in account_balance_daily, which the
+// journal triggers keep in step with journal_lines.
 func (r *JournalRepo) GetAccountBalancesAsOf(
 	ctx context.Context,
 	shop string,
@@ -184,23 +204,21 @@ func (r *JournalRepo) GetAccountBalancesAsOf(
           a.account_name,
           a.account_type,
           a.parent_id,
-          COALESCE(SUM(
-            CASE WHEN jl.is_debit THEN jl.amount ELSE -jl.amount END
-          ), 0) AS balance
+          COALESCE(SUM(d.debit_total - d.credit_total), 0) AS balance
         FROM accounts a
         LEFT JOIN (
-          SELECT jl.account_id, jl.is_debit, jl.amount
-            FROM journal_lines jl
-            JOIN journal_entries je ON jl.journal_id = je.journal_id
-           WHERE je.entry_date <= $1
-             AND ($2 = '' OR je.shop_username = $2)
-        ) jl ON a.account_id = jl.account_id
+          SELECT account_id, debit_total, credit_total
+            FROM account_balance_daily
+           WHERE balance_date <= $1
+             AND ($2 = '' OR shop_username = $2)%s
+        ) d ON a.account_id = d.account_id
         GROUP BY
           a.account_id, a.account_code, a.account_name,
           a.account_type, a.parent_id
         ORDER BY a.account_code;`
 
-	args := []interface{}{asOfDate, shop}
+	filter, args := r.entityFilter(2, []interface{}{asOfDate, shop})
+	query = fmt.Sprintf(query, filter)
 
 	var result []AccountBalance
 	if err := r.db.SelectContext(ctx, &result, query, args...); err != nil {
@@ -210,8 +228,8 @@ func (r *JournalRepo) GetAccountBalancesAsOf(
 }
 
 // GetAccountBalancesBetween returns each account's net balance within the given
-// date range. It sums debit amounts as positive and credit amounts as negative
-// for journal entries between the from and to dates (inclusive).
+// date range. Debits count as positive and credits as negative, summed from
+// the daily totals between the from and to dates (inclusive).
 func (r *JournalRepo) GetAccountBalancesBetween(
 	ctx context.Context,
 	shop string,
@@ -224,23 +242,21 @@ func (r *JournalRepo) GetAccountBalancesBetween(
           a.account_name,
           a.account_type,
           a.parent_id,
-          COALESCE(SUM(
-            CASE WHEN jl.is_debit THEN jl.amount ELSE -jl.amount END
-          ), 0) AS balance
+          COALESCE(SUM(d.debit_total - d.credit_total), 0) AS balance
         FROM accounts a
         LEFT JOIN (
-          SELECT jl.account_id, jl.is_debit, jl.amount
-            FROM journal_lines jl
-            JOIN journal_entries je ON jl.journal_id = je.journal_id
-           WHERE je.entry_date BETWEEN $1 AND $2
-             AND ($3 = '' OR je.shop_username = $3)
-        ) jl ON a.account_id = jl.account_id
+          SELECT account_id, debit_total, credit_total
+            FROM account_balance_daily
+           WHERE balance_date BETWEEN $1 AND $2
+             AND ($3 = '' OR shop_username = $3)%s
+        ) d ON a.account_id = d.account_id
         GROUP BY
           a.account_id, a.account_code, a.account_name,
           a.account_type, a.parent_id
         ORDER BY a.account_code;`
 
-	args := []interface{}{from, to, shop}
+	filter, args := r.entityFilter(3, []interface{}{from, to, shop})
+	query = fmt.Sprintf(query, filter)
 
 	var result []AccountBalance
 	if err := r.db.SelectContext(ctx, &result, query, args...); err != nil {
@@ -249,6 +265,57 @@ func (r *JournalRepo) GetAccountBalancesBetween(
 	return result, nil
 }
 
+// AccountActivity holds the debit and credit turnover of one account within
+// a period.
+type AccountActivity struct {
+	AccountID   int64        `db:"account_id" json:"account_id"`
+	AccountCode string       `db:"account_code" json:"account_code"`
+	AccountName string       `db:"account_name" json:"account_name"`
+	AccountType string       `db:"account_type" json:"account_type"`
+	ParentID    *int64       `db:"parent_id" json:"parent_id"`
+	Debit       money.Amount `db:"debit" json:"debit"`
+	Credit      money.Amount `db:"credit" json:"credit"`
+}
+
+// GetAccountActivityBetween returns the debit and credit totals of every
+// account between the from and to dates (inclusive), read from
+// account_balance_daily.
+func (r *JournalRepo) GetAccountActivityBetween(
+	ctx context.Context,
+	shop string,
+	from, to time.Time,
+) ([]AccountActivity, error) {
+	query := `
+        SELECT
+          a.account_id,
+          a.account_code,
+          a.account_name,
+          a.account_type,
+          a.parent_id,
+          COALESCE(SUM(d.debit_total), 0) AS debit,
+          COALESCE(SUM(d.credit_total), 0) AS credit
+        FROM accounts a
+        LEFT JOIN (
+          SELECT account_id, debit_total, credit_total
+            FROM account_balance_daily
+           WHERE balance_date BETWEEN $1 AND $2
+             AND ($3 = '' OR shop_username = $3)%s
+        ) d ON a.account_id = d.account_id
+        GROUP BY
+          a.account_id, a.account_code, a.account_name,
+          a.account_type, a.parent_id
+        ORDER BY a.account_code;`
+
+	filter, args := r.entityFilter(3, []interface{}{from, to, shop})
+	query = fmt.Sprintf(query, filter)
+
+	var result []AccountActivity
+	if err := r.db.SelectContext(ctx, &result, query, args...); err != nil {
+		return nil, fmt.Errorf("GetAccountActivityBetween: %w", err)
+	}
+	return result, nil
+}
+
 // GetLinesByJournalID returns all journal lines for a given journal entry
 // joined with the account name.
 func (r *JournalRepo) GetLinesByJournalID(ctx context.Context, id int64) ([]JournalLineDetail, error) {
@@ -296,6 +363,29 @@ func (r *JournalRepo) ListJournalEntries(
 	return list, err
 }
 
+// ListJournalEntriesFiltered returns journal entries using the filtering
+// framework.
+func (r *JournalRepo) ListJournalEntriesFiltered(
+	ctx context.Context,
+	params *models.FilterParams,
+) (*models.QueryResult, error) {
+	return listFiltered[models.JournalEntry](ctx, r.db, filteredList{
+		base: "SELECT * FROM journal_entries",
+		fields: map[string]string{
+			"journal_id":    "journal_id",
+			"entry_date":    "entry_date",
+			"description":   "description",
+			"source_type":   "source_type",
+			"source_id":     "source_id",
+			"shop_username": "shop_username",
+			"store":         "store",
+			"created_at":    "created_at",
+		},
+		keys: []string{"journal_id"},
+		sort: models.SortCondition{Field: "entry_date", Direction: "desc"},
+	}, params)
+}
+
 // DeleteJournalEntry removes the entry (lines cascade).
 func (r *JournalRepo) DeleteJournalEntry(ctx context.Context, id int64) error {
 	_, err := r.db.ExecContext(ctx, `DELETE FROM journal_entries WHERE journal_id=$1`, id)
@@ -337,7 +427,7 @@ func (r *JournalRepo) ListEntriesBySourceID(ctx context.Context, sourceID string
 }
 
 // UpdateJournalLineAmount updates the amount of a journal line identified by line_id.
-func (r *JournalRepo) UpdateJournalLineAmount(ctx context.Context, lineID int64, amount float64) error {
+func (r *JournalRepo) UpdateJournalLineAmount(ctx context.Context, lineID int64, amount money.Amount) error {
 	_, err := r.db.ExecContext(ctx,
 		`UPDATE journal_lines SET amount=$1 WHERE line_id=$2`, amount, lineID)
 	return err
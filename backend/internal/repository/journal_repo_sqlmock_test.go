@@ -8,6 +8,8 @@ import (
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 )
 
 func TestGetAccountBalancesBetween_DifferentDates(t *testing.T) {
@@ -31,17 +33,14 @@ func TestGetAccountBalancesBetween_DifferentDates(t *testing.T) {
           a.account_name,
           a.account_type,
           a.parent_id,
-          COALESCE(SUM(
-            CASE WHEN jl.is_debit THEN jl.amount ELSE -jl.amount END
-          ), 0) AS balance
+          COALESCE(SUM(d.debit_total - d.credit_total), 0) AS balance
         FROM accounts a
         LEFT JOIN (
-          SELECT jl.account_id, jl.is_debit, jl.amount
-            FROM journal_lines jl
-            JOIN journal_entries je ON jl.journal_id = je.journal_id
-           WHERE je.entry_date BETWEEN $1 AND $2
-             AND ($3 = '' OR je.shop_username = $3)
-        ) jl ON a.account_id = jl.account_id
+          SELECT account_id, debit_total, credit_total
+            FROM account_balance_daily
+           WHERE balance_date BETWEEN $1 AND $2
+             AND ($3 = '' OR shop_username = $3)
+        ) d ON a.account_id = d.account_id
         GROUP BY
           a.account_id, a.account_code, a.account_name,
           a.account_type, a.parent_id
@@ -55,7 +54,7 @@ func TestGetAccountBalancesBetween_DifferentDates(t *testing.T) {
 	if err != nil {
 		t.Fatalf("GetAccountBalancesBetween failed: %v", err)
 	}
-	if len(res1) != 1 || res1[0].Balance != 100 {
+	if len(res1) != 1 || res1[0].Balance != money.New(100) {
 		t.Fatalf("unexpected result for May: %+v", res1)
 	}
 
@@ -4,11 +4,14 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/ramadhan22/dropship-erp/backend/internal/apperr"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 )
 
 // insertTestAccount inserts a dummy account and returns its account_id.
@@ -61,7 +64,7 @@ func TestCreateJournalEntryAndLinesAndBalance(t *testing.T) {
 		JournalID: journalID,
 		AccountID: acc1,
 		IsDebit:   true,
-		Amount:    100,
+		Amount:    money.New(100),
 		Memo:      ptrString("Debit test"),
 	}
 	if err := jrepo.InsertJournalLine(ctx, jl1); err != nil {
@@ -71,7 +74,7 @@ func TestCreateJournalEntryAndLinesAndBalance(t *testing.T) {
 		JournalID: journalID,
 		AccountID: acc2,
 		IsDebit:   false,
-		Amount:    100,
+		Amount:    money.New(100),
 		Memo:      ptrString("Credit test"),
 	}
 	if err := jrepo.InsertJournalLine(ctx, jl2); err != nil {
@@ -100,14 +103,14 @@ func TestCreateJournalEntryAndLinesAndBalance(t *testing.T) {
 	for _, ab := range balances {
 		if ab.AccountID == acc1 {
 			foundAcc1 = true
-			if ab.Balance != 100 {
-				t.Errorf("Expected balance 100 for acc1, got %f", ab.Balance)
+			if ab.Balance != money.New(100) {
+				t.Errorf("Expected balance 100 for acc1, got %s", ab.Balance)
 			}
 		}
 		if ab.AccountID == acc2 {
 			foundAcc2 = true
-			if ab.Balance != -100 {
-				t.Errorf("Expected balance -100 for acc2, got %f", ab.Balance)
+			if ab.Balance != money.New(-100) {
+				t.Errorf("Expected balance -100 for acc2, got %s", ab.Balance)
 			}
 		}
 	}
@@ -128,14 +131,14 @@ func TestCreateJournalEntryAndLinesAndBalance(t *testing.T) {
 	for _, ab := range rangeBalances {
 		if ab.AccountID == acc1 {
 			foundAcc1 = true
-			if ab.Balance != 100 {
-				t.Errorf("Expected range balance 100 for acc1, got %f", ab.Balance)
+			if ab.Balance != money.New(100) {
+				t.Errorf("Expected range balance 100 for acc1, got %s", ab.Balance)
 			}
 		}
 		if ab.AccountID == acc2 {
 			foundAcc2 = true
-			if ab.Balance != -100 {
-				t.Errorf("Expected range balance -100 for acc2, got %f", ab.Balance)
+			if ab.Balance != money.New(-100) {
+				t.Errorf("Expected range balance -100 for acc2, got %s", ab.Balance)
 			}
 		}
 	}
@@ -171,9 +174,9 @@ func TestInsertJournalLinesBulk(t *testing.T) {
 
 	// 3. Test bulk insert with multiple lines
 	lines := []models.JournalLine{
-		{JournalID: journalID, AccountID: acc1, IsDebit: true, Amount: 150, Memo: ptrString("Bulk debit 1")},
-		{JournalID: journalID, AccountID: acc2, IsDebit: true, Amount: 50, Memo: ptrString("Bulk debit 2")},
-		{JournalID: journalID, AccountID: acc3, IsDebit: false, Amount: 200, Memo: ptrString("Bulk credit")},
+		{JournalID: journalID, AccountID: acc1, IsDebit: true, Amount: money.New(150), Memo: ptrString("Bulk debit 1")},
+		{JournalID: journalID, AccountID: acc2, IsDebit: true, Amount: money.New(50), Memo: ptrString("Bulk debit 2")},
+		{JournalID: journalID, AccountID: acc3, IsDebit: false, Amount: money.New(200), Memo: ptrString("Bulk credit")},
 	}
 
 	if err := jrepo.InsertJournalLines(ctx, lines); err != nil {
@@ -199,7 +202,7 @@ func TestInsertJournalLinesBulk(t *testing.T) {
 
 	// Test single line (should use InsertJournalLine internally)
 	singleLine := []models.JournalLine{
-		{JournalID: journalID, AccountID: acc1, IsDebit: true, Amount: 25, Memo: ptrString("Single line test")},
+		{JournalID: journalID, AccountID: acc1, IsDebit: true, Amount: money.New(25), Memo: ptrString("Single line test")},
 	}
 	if err := jrepo.InsertJournalLines(ctx, singleLine); err != nil {
 		t.Fatalf("InsertJournalLines with single line failed: %v", err)
@@ -244,8 +247,8 @@ func TestInsertJournalLinesValidation(t *testing.T) {
 
 	// 3. Test balanced lines (should succeed)
 	balancedLines := []models.JournalLine{
-		{JournalID: journalID, AccountID: acc1, IsDebit: true, Amount: 100, Memo: ptrString("Balanced debit")},
-		{JournalID: journalID, AccountID: acc2, IsDebit: false, Amount: 100, Memo: ptrString("Balanced credit")},
+		{JournalID: journalID, AccountID: acc1, IsDebit: true, Amount: money.New(100), Memo: ptrString("Balanced debit")},
+		{JournalID: journalID, AccountID: acc2, IsDebit: false, Amount: money.New(100), Memo: ptrString("Balanced credit")},
 	}
 
 	if err := jrepo.InsertJournalLines(ctx, balancedLines); err != nil {
@@ -254,25 +257,28 @@ func TestInsertJournalLinesValidation(t *testing.T) {
 
 	// 4. Test unbalanced lines (should fail)
 	unbalancedLines := []models.JournalLine{
-		{JournalID: journalID, AccountID: acc1, IsDebit: true, Amount: 150, Memo: ptrString("Unbalanced debit")},
-		{JournalID: journalID, AccountID: acc2, IsDebit: false, Amount: 100, Memo: ptrString("Unbalanced credit")},
+		{JournalID: journalID, AccountID: acc1, IsDebit: true, Amount: money.New(150), Memo: ptrString("Unbalanced debit")},
+		{JournalID: journalID, AccountID: acc2, IsDebit: false, Amount: money.New(100), Memo: ptrString("Unbalanced credit")},
 	}
 
 	err = jrepo.InsertJournalLines(ctx, unbalancedLines)
 	if err == nil {
 		t.Error("InsertJournalLines with unbalanced amounts should fail")
 	}
-	expectedErrorMsg := "debits 150.00 do not equal credits 100.00"
+	if !errors.Is(err, apperr.ErrUnbalancedJournal) {
+		t.Errorf("expected ErrUnbalancedJournal, got %v", err)
+	}
+	expectedErrorMsg := "unbalanced journal: debits 150.00 do not equal credits 100.00"
 	if err.Error() != expectedErrorMsg {
 		t.Errorf("Expected error message '%s', got '%s'", expectedErrorMsg, err.Error())
 	}
 
 	// 5. Test complex balanced scenario with multiple debits and credits
 	complexBalancedLines := []models.JournalLine{
-		{JournalID: journalID, AccountID: acc1, IsDebit: true, Amount: 75, Memo: ptrString("Debit 1")},
-		{JournalID: journalID, AccountID: acc1, IsDebit: true, Amount: 25, Memo: ptrString("Debit 2")},
-		{JournalID: journalID, AccountID: acc2, IsDebit: false, Amount: 60, Memo: ptrString("Credit 1")},
-		{JournalID: journalID, AccountID: acc2, IsDebit: false, Amount: 40, Memo: ptrString("Credit 2")},
+		{JournalID: journalID, AccountID: acc1, IsDebit: true, Amount: money.New(75), Memo: ptrString("Debit 1")},
+		{JournalID: journalID, AccountID: acc1, IsDebit: true, Amount: money.New(25), Memo: ptrString("Debit 2")},
+		{JournalID: journalID, AccountID: acc2, IsDebit: false, Amount: money.New(60), Memo: ptrString("Credit 1")},
+		{JournalID: journalID, AccountID: acc2, IsDebit: false, Amount: money.New(40), Memo: ptrString("Credit 2")},
 	}
 
 	if err := jrepo.InsertJournalLines(ctx, complexBalancedLines); err != nil {
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+)
+
+// LedgerIntegrityRepo runs the ledger integrity queries and stores the audit
+// trail of repairs in ledger_repairs.
+type LedgerIntegrityRepo struct{ db DBTX }
+
+// NewLedgerIntegrityRepo constructs a LedgerIntegrityRepo.
+func NewLedgerIntegrityRepo(db DBTX) *LedgerIntegrityRepo {
+	return &LedgerIntegrityRepo{db: db}
+}
+
+// notReversed excludes journals already reversed by an integrity repair.
+const notReversed = `NOT EXISTS (SELECT 1 FROM journal_entries rev
+                      WHERE rev.source_type = 'integrity_reversal' AND rev.source_id = je.journal_id::text)`
+
+// integrityChecks holds the query of each check. The %s placeholder takes an
+// optional condition on $1 that narrows the check to one target.
+var integrityChecks = map[string]struct{ query, byTarget string }{
+	models.CheckUnbalancedJournal: {
+		query: `SELECT 'unbalanced_journal' AS check_name, je.journal_id::text AS target,
+                       je.journal_id, je.source_type, je.source_id,
+                       COALESCE(SUM(jl.amount) FILTER (WHERE jl.is_debit), 0) AS debit,
+                       COALESCE(SUM(jl.amount) FILTER (WHERE NOT jl.is_debit), 0) AS credit,
+                       'debits do not equal credits' AS detail
+                  FROM journal_entries je
+                  JOIN journal_lines jl ON jl.journal_id = je.journal_id
+                 WHERE TRUE %s
+                 GROUP BY je.journal_id
+                HAVING COALESCE(SUM(jl.amount) FILTER (WHERE jl.is_debit), 0)
+                    <> COALESCE(SUM(jl.amount) FILTER (WHERE NOT jl.is_debit), 0)
+                 ORDER BY je.journal_id`,
+		byTarget: `AND je.journal_id::text = $1`,
+	},
+	// Source ids are compared without case, apostrophes and punctuation and
+	// the earliest journal of each group is kept. exact marks journals whose
+	// source id also matches an earlier one character for character; only
+	// those are repaired. The unique index on (source_type, source_id)
+	// normally rules them out, so most issues here are report-only.
+	models.CheckDuplicateSource: {
+		query: `WITH src AS (
+                    SELECT je.journal_id, je.source_type, je.source_id,
+                           upper(regexp_replace(je.source_id, '[^A-Za-z0-9]', '', 'g')) AS norm
+                      FROM journal_entries je
+                     WHERE je.source_type NOT LIKE 'integrity_%%' AND ` + notReversed + `
+                ), ranked AS (
+                    SELECT src.*,
+                           ROW_NUMBER() OVER w AS rn,
+                           FIRST_VALUE(journal_id) OVER w AS kept,
+                           ROW_NUMBER() OVER (PARTITION BY source_type, source_id ORDER BY journal_id) > 1 AS exact
+                      FROM src WHERE norm <> ''
+                    WINDOW w AS (PARTITION BY source_type, norm ORDER BY journal_id)
+                )
+                SELECT 'duplicate_source_journal' AS check_name, journal_id::text AS target,
+                       journal_id, source_type, source_id, exact,
+                       CASE WHEN exact THEN 'duplicate of journal ' || kept
+                            ELSE 'possible duplicate of journal ' || kept || ': source ids differ only in case or punctuation'
+                       END AS detail
+                  FROM ranked WHERE rn > 1 %s
+                 ORDER BY journal_id`,
+		byTarget: `AND journal_id::text = $1`,
+	},
+	models.CheckSettledWithoutJournal: {
+		query: `SELECT 'settled_without_journal' AS check_name, dp.kode_invoice_channel AS target,
+                       dp.kode_pesanan, dp.lifecycle_status,
+                       'settled purchase has no shopee_escrow journal' AS detail
+                  FROM dropship_purchases dp
+                 WHERE (dp.lifecycle_status = 'settled' OR dp.status_pesanan_terakhir ILIKE 'pesanan selesai')
+                   AND dp.jenis_channel ILIKE 'shopee' AND dp.kode_invoice_channel <> ''
+                   AND NOT EXISTS (SELECT 1 FROM journal_entries je
+                                    WHERE je.source_type = 'shopee_escrow' AND je.source_id = dp.kode_invoice_channel) %s
+                 ORDER BY dp.waktu_pesanan_terbuat`,
+		byTarget: `AND dp.kode_invoice_channel = $1`,
+	},
+	models.CheckMissingAccount: {
+		query: `SELECT 'missing_account' AS check_name, jl.line_id::text AS target,
+                       jl.journal_id, je.source_type, je.source_id, jl.account_id,
+                       'line ' || jl.line_id || ' references missing account ' || jl.account_id AS detail
+                  FROM journal_lines jl
+                  LEFT JOIN accounts a ON a.account_id = jl.account_id
+                  LEFT JOIN journal_entries je ON je.journal_id = jl.journal_id
+                 WHERE a.account_id IS NULL %s
+                 ORDER BY jl.line_id`,
+		byTarget: `AND jl.line_id::text = $1`,
+	},
+	models.CheckStatusMismatch: {
+		query: `SELECT 'status_journal_mismatch' AS check_name, dp.kode_invoice_channel AS target,
+                       dp.kode_pesanan, dp.lifecycle_status, je.journal_id, je.source_type, je.source_id,
+                       CASE WHEN je.source_type = 'reconcile_cancel'
+                            THEN 'cancellation journal but purchase is ' || dp.lifecycle_status
+                            ELSE 'escrow journal but purchase is ' || dp.lifecycle_status END AS detail
+                  FROM dropship_purchases dp
+                  JOIN journal_entries je ON je.source_id = dp.kode_invoice_channel
+                 WHERE ((je.source_type = 'shopee_escrow'
+                         AND dp.lifecycle_status IN ('imported', 'pending_sale', 'shipped', 'cancelled'))
+                     OR (je.source_type = 'reconcile_cancel' AND dp.lifecycle_status <> 'cancelled'))
+                   AND ` + notReversed + ` %s
+                 ORDER BY je.journal_id`,
+		byTarget: `AND dp.kode_invoice_channel = $1`,
+	},
+}
+
+// FindIssues runs one integrity check over the whole ledger.
+func (r *LedgerIntegrityRepo) FindIssues(ctx context.Context, check string) ([]models.IntegrityIssue, error) {
+	c, ok := integrityChecks[check]
+	if !ok {
+		return nil, fmt.Errorf("unknown integrity check %q", check)
+	}
+	var list []models.IntegrityIssue
+	err := r.db.SelectContext(ctx, &list, fmt.Sprintf(c.query, ""))
+	if list == nil {
+		list = []models.IntegrityIssue{}
+	}
+	return list, err
+}
+
+// FindIssue runs one integrity check for a single target and returns the
+// first issue found, or sql.ErrNoRows when the target is clean.
+func (r *LedgerIntegrityRepo) FindIssue(ctx context.Context, check, target string) (*models.IntegrityIssue, error) {
+	c, ok := integrityChecks[check]
+	if !ok {
+		return nil, fmt.Errorf("unknown integrity check %q", check)
+	}
+	var list []models.IntegrityIssue
+	if err := r.db.SelectContext(ctx, &list, fmt.Sprintf(c.query, c.byTarget), target); err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return &list[0], nil
+}
+
+// JournalLines returns the raw lines of a journal, including lines whose
+// account no longer exists.
+func (r *LedgerIntegrityRepo) JournalLines(ctx context.Context, journalID int64) ([]models.JournalLine, error) {
+	var list []models.JournalLine
+	err := r.db.SelectContext(ctx, &list,
+		`SELECT * FROM journal_lines WHERE journal_id = $1 ORDER BY line_id`, journalID)
+	if list == nil {
+		list = []models.JournalLine{}
+	}
+	return list, err
+}
+
+// InsertRepair records a repair in ledger_repairs.
+func (r *LedgerIntegrityRepo) InsertRepair(ctx context.Context, rep *models.LedgerRepair) error {
+	return r.db.QueryRowxContext(ctx,
+		`INSERT INTO ledger_repairs (check_name, target, action, detail, journal_id, performed_by)
+         VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, performed_at`,
+		rep.Check, rep.Target, rep.Action, rep.Detail, rep.JournalID, rep.PerformedBy,
+	).Scan(&rep.ID, &rep.PerformedAt)
+}
+
+// ListRepairs returns recorded repairs, newest first, with the total count.
+func (r *LedgerIntegrityRepo) ListRepairs(ctx context.Context, limit, offset int) ([]models.LedgerRepair, int, error) {
+	var total int
+	if err := r.db.GetContext(ctx, &total, `SELECT COUNT(*) FROM ledger_repairs`); err != nil {
+		return nil, 0, err
+	}
+	var list []models.LedgerRepair
+	err := r.db.SelectContext(ctx, &list,
+		`SELECT * FROM ledger_repairs ORDER BY performed_at DESC, id DESC LIMIT $1 OFFSET $2`, limit, offset)
+	if list == nil {
+		list = []models.LedgerRepair{}
+	}
+	return list, total, err
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+)
+
+// MatchSuggestionRepo manages match_suggestions and reads the purchases and
+// settlements that the exact invoice join leaves unmatched.
+type MatchSuggestionRepo struct{ db DBTX }
+
+// NewMatchSuggestionRepo constructs a MatchSuggestionRepo.
+func NewMatchSuggestionRepo(db DBTX) *MatchSuggestionRepo {
+	return &MatchSuggestionRepo{db: db}
+}
+
+// ListUnmatchedPurchases returns purchases created in [from, to) that have
+// no shopee_settled row with their invoice and were not matched by hand.
+// Cancelled purchases are left out. An empty store matches all stores.
+func (r *MatchSuggestionRepo) ListUnmatchedPurchases(ctx context.Context, store string, from, to time.Time, limit int) ([]models.MatchPurchase, error) {
+	var list []models.MatchPurchase
+	err := r.db.SelectContext(ctx, &list, `
+        SELECT dp.kode_pesanan, dp.kode_invoice_channel, dp.nama_toko, dp.waktu_pesanan_terbuat,
+               COALESCE((SELECT SUM(d.total_harga_produk_channel)
+                           FROM dropship_purchase_details d
+                          WHERE d.kode_pesanan = dp.kode_pesanan), 0) AS channel_total
+          FROM dropship_purchases dp
+         WHERE ($1 = '' OR dp.nama_toko = $1)
+           AND dp.waktu_pesanan_terbuat >= $2 AND dp.waktu_pesanan_terbuat < $3
+           AND dp.lifecycle_status <> 'cancelled'
+           AND NOT EXISTS (SELECT 1 FROM shopee_settled ss WHERE ss.no_pesanan = dp.kode_invoice_channel)
+           AND NOT EXISTS (SELECT 1 FROM reconciled_transactions rt
+                            WHERE rt.dropship_id = dp.kode_pesanan AND rt.status = 'matched')
+         ORDER BY dp.waktu_pesanan_terbuat
+         LIMIT $4`, store, from, to, limit)
+	if list == nil {
+		list = []models.MatchPurchase{}
+	}
+	return list, err
+}
+
+// ListUnmatchedOrders returns settlements of orders created in [from, to)
+// that have no purchase with their invoice and were not matched by hand.
+func (r *MatchSuggestionRepo) ListUnmatchedOrders(ctx context.Context, from, to time.Time) ([]models.MatchOrder, error) {
+	var list []models.MatchOrder
+	err := r.db.SelectContext(ctx, &list, `
+        SELECT ss.no_pesanan, ss.nama_toko, ss.waktu_pesanan_dibuat,
+               ss.harga_asli_produk - ss.total_diskon_produk AS product_total
+          FROM shopee_settled ss
+         WHERE ss.waktu_pesanan_dibuat >= $1 AND ss.waktu_pesanan_dibuat < $2
+           AND NOT EXISTS (SELECT 1 FROM dropship_purchases dp WHERE dp.kode_invoice_channel = ss.no_pesanan)
+           AND NOT EXISTS (SELECT 1 FROM reconciled_transactions rt
+                            WHERE rt.shopee_id = ss.no_pesanan AND rt.status = 'matched')
+         ORDER BY ss.waktu_pesanan_dibuat`, from, to)
+	if list == nil {
+		list = []models.MatchOrder{}
+	}
+	return list, err
+}
+
+// DeletePending removes the pending suggestions of store (all stores when
+// empty) so a new run replaces them. Decided suggestions are kept.
+func (r *MatchSuggestionRepo) DeletePending(ctx context.Context, store string) error {
+	_, err := r.db.ExecContext(ctx,
+		`DELETE FROM match_suggestions WHERE status = 'pending' AND ($1 = '' OR store = $1)`, store)
+	return err
+}
+
+// Upsert stores a pending suggestion. A pair that was already accepted or
+// rejected is left alone; the returned bool reports whether s was stored.
+func (r *MatchSuggestionRepo) Upsert(ctx context.Context, s *models.MatchSuggestion) (bool, error) {
+	res, err := r.db.NamedExecContext(ctx, `
+        INSERT INTO match_suggestions
+          (store, kode_pesanan, kode_invoice_channel, no_pesanan, confidence,
+           invoice_score, store_score, date_score, amount_score)
+        VALUES (:store, :kode_pesanan, :kode_invoice_channel, :no_pesanan, :confidence,
+                :invoice_score, :store_score, :date_score, :amount_score)
+        ON CONFLICT (kode_pesanan, no_pesanan) DO UPDATE SET
+          store = EXCLUDED.store, kode_invoice_channel = EXCLUDED.kode_invoice_channel,
+          confidence = EXCLUDED.confidence, invoice_score = EXCLUDED.invoice_score,
+          store_score = EXCLUDED.store_score, date_score = EXCLUDED.date_score,
+          amount_score = EXCLUDED.amount_score, created_at = NOW()
+        WHERE match_suggestions.status = 'pending'`, s)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// List returns suggestions filtered by store and status (empty matches all),
+// most confident first, with the total count.
+func (r *MatchSuggestionRepo) List(ctx context.Context, store, status string, limit, offset int) ([]models.MatchSuggestion, int, error) {
+	where := ` WHERE ($1 = '' OR store = $1) AND ($2 = '' OR status = $2)`
+	var total int
+	if err := r.db.GetContext(ctx, &total, `SELECT COUNT(*) FROM match_suggestions`+where, store, status); err != nil {
+		return nil, 0, err
+	}
+	var list []models.MatchSuggestion
+	err := r.db.SelectContext(ctx, &list,
+		`SELECT * FROM match_suggestions`+where+` ORDER BY confidence DESC, id LIMIT $3 OFFSET $4`,
+		store, status, limit, offset)
+	if list == nil {
+		list = []models.MatchSuggestion{}
+	}
+	return list, total, err
+}
+
+// Get returns one suggestion.
+func (r *MatchSuggestionRepo) Get(ctx context.Context, id int64) (*models.MatchSuggestion, error) {
+	var s models.MatchSuggestion
+	if err := r.db.GetContext(ctx, &s, `SELECT * FROM match_suggestions WHERE id = $1`, id); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Decide records the decision on a suggestion. Accepting one also drops the
+// other pending suggestions for the same purchase or settlement.
+func (r *MatchSuggestionRepo) Decide(ctx context.Context, s *models.MatchSuggestion) error {
+	if _, err := r.db.ExecContext(ctx,
+		`UPDATE match_suggestions SET status = $2, decided_at = $3 WHERE id = $1`,
+		s.ID, s.Status, s.DecidedAt); err != nil {
+		return err
+	}
+	if s.Status != models.SuggestionAccepted {
+		return nil
+	}
+	_, err := r.db.ExecContext(ctx,
+		`DELETE FROM match_suggestions
+          WHERE status = 'pending' AND id <> $1 AND (kode_pesanan = $2 OR no_pesanan = $3)`,
+		s.ID, s.KodePesanan, s.NoPesanan)
+	return err
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 )
 
 // cleanupMetric deletes a cached_metrics row by shop and period.
@@ -28,11 +29,11 @@ func TestUpsertAndGetCachedMetric(t *testing.T) {
 	cm := &models.CachedMetric{
 		ShopUsername:      shop,
 		Period:            period,
-		SumRevenue:        100.00,
-		SumCOGS:           50.00,
-		SumFees:           5.00,
-		NetProfit:         45.00,
-		EndingCashBalance: 1000.00,
+		SumRevenue:        money.New(100),
+		SumCOGS:           money.New(50),
+		SumFees:           money.New(5),
+		NetProfit:         money.New(45),
+		EndingCashBalance: money.New(1000),
 		UpdatedAt:         time.Now(),
 	}
 	if err := repo.UpsertCachedMetric(ctx, cm); err != nil {
@@ -44,8 +45,8 @@ func TestUpsertAndGetCachedMetric(t *testing.T) {
 	if err != nil {
 		t.Fatalf("GetCachedMetric failed: %v", err)
 	}
-	if fetched.NetProfit != 45.00 {
-		t.Errorf("Expected NetProfit 45.00, got %f", fetched.NetProfit)
+	if fetched.NetProfit != money.New(45) {
+		t.Errorf("Expected NetProfit 45.00, got %s", fetched.NetProfit)
 	}
 	t.Log("GetCachedMetric succeeded")
 
@@ -106,6 +106,34 @@ func (r *OrderDetailRepo) ListOrderDetails(ctx context.Context, store, order str
 	return list, total, nil
 }
 
+// ListOrderDetailsFiltered returns detail rows using the filtering framework.
+func (r *OrderDetailRepo) ListOrderDetailsFiltered(ctx context.Context, params *models.FilterParams) (*models.QueryResult, error) {
+	return listFiltered[models.ShopeeOrderDetailRow](ctx, r.db, filteredList{
+		base: "SELECT * FROM shopee_order_details",
+		fields: map[string]string{
+			"order_sn":         "order_sn",
+			"nama_toko":        "nama_toko",
+			"status":           "status",
+			"order_status":     "order_status",
+			"checkout_time":    "checkout_time",
+			"update_time":      "update_time",
+			"pay_time":         "pay_time",
+			"total_amount":     "total_amount",
+			"buyer_username":   "buyer_username",
+			"cod":              "cod",
+			"shipping_carrier": "shipping_carrier",
+			"payment_method":   "payment_method",
+			"recipient_city":   "recipient_city",
+			"store":            "nama_toko",
+			"order":            "order_sn",
+			"order_no":         "order_sn",
+			"created_at":       "created_at",
+		},
+		keys: []string{"order_sn"},
+		sort: models.SortCondition{Field: "created_at", Direction: "desc"},
+	}, params)
+}
+
 // GetOrderDetail fetches a detail row and associated items and packages by order_sn.
 func (r *OrderDetailRepo) GetOrderDetail(ctx context.Context, sn string) (*models.ShopeeOrderDetailRow, []models.ShopeeOrderItemRow, []models.ShopeeOrderPackageRow, error) {
 	var det models.ShopeeOrderDetailRow
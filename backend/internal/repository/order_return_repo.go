@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
+)
+
+// OrderReturnRepo manages order_returns and order_return_items.
+type OrderReturnRepo struct{ db DBTX }
+
+// NewOrderReturnRepo constructs an OrderReturnRepo.
+func NewOrderReturnRepo(db DBTX) *OrderReturnRepo {
+	return &OrderReturnRepo{db: db}
+}
+
+// Upsert inserts a return or refreshes the marketplace fields of an existing
+// one with the same return_sn. Supplier refund fields are never overwritten.
+// ID, supplier refund state and timestamps are filled in from the stored row.
+func (r *OrderReturnRepo) Upsert(ctx context.Context, ret *models.OrderReturn) error {
+	query := `INSERT INTO order_returns
+              (return_sn, order_sn, store, kode_pesanan, status, negotiation_status,
+               seller_proof_status, seller_compensation_status, reason, refund_amount,
+               currency, buyer_username, image_urls, seller_proof_urls,
+               return_created_at, return_updated_at, due_date)
+              VALUES (:return_sn,:order_sn,:store,:kode_pesanan,:status,:negotiation_status,
+                      :seller_proof_status,:seller_compensation_status,:reason,:refund_amount,
+                      :currency,:buyer_username,:image_urls,:seller_proof_urls,
+                      :return_created_at,:return_updated_at,:due_date)
+              ON CONFLICT (return_sn) DO UPDATE SET
+                order_sn=EXCLUDED.order_sn, store=EXCLUDED.store,
+                kode_pesanan=COALESCE(EXCLUDED.kode_pesanan, order_returns.kode_pesanan),
+                status=EXCLUDED.status, negotiation_status=EXCLUDED.negotiation_status,
+                seller_proof_status=EXCLUDED.seller_proof_status,
+                seller_compensation_status=EXCLUDED.seller_compensation_status,
+                reason=EXCLUDED.reason, refund_amount=EXCLUDED.refund_amount,
+                currency=EXCLUDED.currency, buyer_username=EXCLUDED.buyer_username,
+                image_urls=EXCLUDED.image_urls, seller_proof_urls=EXCLUDED.seller_proof_urls,
+                return_created_at=EXCLUDED.return_created_at,
+                return_updated_at=EXCLUDED.return_updated_at,
+                due_date=EXCLUDED.due_date, updated_at=NOW()
+              RETURNING id, kode_pesanan, supplier_refund_status, supplier_refund_amount,
+                        supplier_refunded_at, supplier_refund_note, supplier_journal_id,
+                        created_at, updated_at`
+	stmt, args, err := r.db.BindNamed(query, ret)
+	if err != nil {
+		return err
+	}
+	return r.db.QueryRowxContext(ctx, stmt, args...).Scan(
+		&ret.ID, &ret.KodePesanan, &ret.SupplierRefundStatus, &ret.SupplierRefundAmount,
+		&ret.SupplierRefundedAt, &ret.SupplierRefundNote, &ret.SupplierJournalID,
+		&ret.CreatedAt, &ret.UpdatedAt)
+}
+
+// ReplaceItems swaps the stored items of a return for the given list.
+func (r *OrderReturnRepo) ReplaceItems(ctx context.Context, returnID int64, items []models.OrderReturnItem) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM order_return_items WHERE return_id=$1`, returnID); err != nil {
+		return err
+	}
+	for i := range items {
+		items[i].ReturnID = returnID
+		query := `INSERT INTO order_return_items
+                  (return_id, item_id, model_id, item_name, item_sku, model_name, model_sku,
+                   qty, item_price, is_main_item)
+                  VALUES (:return_id,:item_id,:model_id,:item_name,:item_sku,:model_name,:model_sku,
+                          :qty,:item_price,:is_main_item)
+                  RETURNING id`
+		stmt, args, err := r.db.BindNamed(query, &items[i])
+		if err != nil {
+			return err
+		}
+		if err := r.db.QueryRowxContext(ctx, stmt, args...).Scan(&items[i].ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Get fetches a return and its items by ID.
+func (r *OrderReturnRepo) Get(ctx context.Context, id int64) (*models.OrderReturn, error) {
+	var ret models.OrderReturn
+	if err := r.db.GetContext(ctx, &ret, `SELECT * FROM order_returns WHERE id=$1`, id); err != nil {
+		return nil, err
+	}
+	items, err := r.ListItems(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	ret.Items = items
+	return &ret, nil
+}
+
+// ListItems returns the items of a return.
+func (r *OrderReturnRepo) ListItems(ctx context.Context, returnID int64) ([]models.OrderReturnItem, error) {
+	var list []models.OrderReturnItem
+	err := r.db.SelectContext(ctx, &list,
+		`SELECT * FROM order_return_items WHERE return_id=$1 ORDER BY id`, returnID)
+	if list == nil {
+		list = []models.OrderReturnItem{}
+	}
+	return list, err
+}
+
+// List returns returns matching f, newest first, with the total match count.
+func (r *OrderReturnRepo) List(ctx context.Context, f models.ReturnFilter, limit, offset int) ([]models.OrderReturn, int, error) {
+	where := `WHERE ($1 = '' OR store = $1)
+                AND ($2 = '' OR status = $2)
+                AND ($3 = '' OR supplier_refund_status = $3)
+                AND ($4 = '' OR order_sn = $4)
+                AND ($5::timestamptz IS NULL OR return_created_at >= $5)
+                AND ($6::timestamptz IS NULL OR return_created_at <= $6)`
+	args := []interface{}{f.Store, f.Status, f.SupplierRefundStatus, f.OrderSN, f.From, f.To}
+	var total int
+	if err := r.db.GetContext(ctx, &total, `SELECT COUNT(*) FROM order_returns `+where, args...); err != nil {
+		return nil, 0, err
+	}
+	var list []models.OrderReturn
+	err := r.db.SelectContext(ctx, &list,
+		`SELECT * FROM order_returns `+where+`
+          ORDER BY return_created_at DESC, id DESC LIMIT $7 OFFSET $8`,
+		append(args, limit, offset)...)
+	if list == nil {
+		list = []models.OrderReturn{}
+	}
+	return list, total, err
+}
+
+// SetSupplierRefund records the outcome of the supplier refund of a return.
+// A refunded return is final: it reports false when the return is already
+// refunded, including by a concurrent transaction that committed first.
+func (r *OrderReturnRepo) SetSupplierRefund(ctx context.Context, id int64, status string, amount money.Amount, at *time.Time, note *string, journalID *int64) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE order_returns SET supplier_refund_status=$2, supplier_refund_amount=$3,
+                supplier_refunded_at=$4, supplier_refund_note=$5, supplier_journal_id=$6, updated_at=NOW()
+          WHERE id=$1 AND supplier_refund_status <> 'refunded'`,
+		id, status, amount, at, note, journalID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// Losses totals the cost of returned goods per store and SKU for returns
+// created in [from, to] that were not cancelled. Each item is costed at the
+// supplier price of the matching purchase detail (by SKU or product name);
+// when nothing matches, the purchase's product cost is split across the
+// return's items by sale value. Supplier refunds are allocated to items in
+// proportion to their cost.
+func (r *OrderReturnRepo) Losses(ctx context.Context, store string, from, to *time.Time) ([]models.ReturnLoss, error) {
+	query := `
+        WITH items AS (
+            SELECT r.id AS return_id, r.store, r.kode_pesanan, r.supplier_refund_amount,
+                   COALESCE(NULLIF(i.model_sku, ''), NULLIF(i.item_sku, ''), i.item_name) AS sku,
+                   i.item_name, i.qty, i.item_price * i.qty AS sale_value
+            FROM order_returns r
+            JOIN order_return_items i ON i.return_id = r.id
+            WHERE r.status <> 'CANCELLED'
+              AND ($1 = '' OR r.store = $1)
+              AND ($2::timestamptz IS NULL OR r.return_created_at >= $2)
+              AND ($3::timestamptz IS NULL OR r.return_created_at <= $3)
+        ), costed AS (
+            SELECT it.*, COALESCE(
+                (SELECT d.harga_produk * it.qty FROM dropship_purchase_details d
+                  WHERE d.kode_pesanan = it.kode_pesanan
+                    AND (d.sku = it.sku OR d.nama_produk = it.item_name)
+                  ORDER BY d.id LIMIT 1),
+                (SELECT SUM(d.total_harga_produk) FROM dropship_purchase_details d
+                  WHERE d.kode_pesanan = it.kode_pesanan)
+                  * it.sale_value / NULLIF(SUM(it.sale_value) OVER (PARTITION BY it.return_id), 0),
+                0) AS cost
+            FROM items it
+        ), alloc AS (
+            SELECT c.*,
+                   CASE WHEN SUM(c.cost) OVER (PARTITION BY c.return_id) > 0
+                        THEN c.supplier_refund_amount * c.cost / SUM(c.cost) OVER (PARTITION BY c.return_id)
+                        ELSE 0 END AS refunded
+            FROM costed c
+        )
+        SELECT store, sku, MAX(item_name) AS item_name,
+               SUM(qty) AS qty, COUNT(DISTINCT return_id) AS returns,
+               ROUND(SUM(cost), 2) AS cost,
+               ROUND(SUM(refunded), 2) AS refunded,
+               ROUND(SUM(cost), 2) - ROUND(SUM(refunded), 2) AS loss
+        FROM alloc
+        GROUP BY store, sku
+        ORDER BY loss DESC, store, sku`
+	var list []models.ReturnLoss
+	err := r.db.SelectContext(ctx, &list, query, store, from, to)
+	if list == nil {
+		list = []models.ReturnLoss{}
+	}
+	return list, err
+}
@@ -1,6 +1,11 @@
 package repository
 
 import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
 	"strconv"
@@ -9,13 +14,18 @@ import (
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
 )
 
+// ErrInvalidFilter is returned when filter, sort or cursor parameters refer
+// to fields a list does not allow or cannot be decoded. Handlers map it to 400.
+var ErrInvalidFilter = errors.New("invalid filter")
+
 // QueryBuilder builds SQL queries from filter parameters
 type QueryBuilder struct {
 	baseQuery     string
-	whereClause   strings.Builder
 	args          []interface{}
 	argIndex      int
 	allowedFields map[string]string // field name -> column name mapping
+	keyColumns    []string          // unique columns used as sort tiebreak and keyset position
+	defaultSort   []models.SortCondition
 }
 
 // NewQueryBuilder creates a new query builder
@@ -33,91 +43,122 @@ func (qb *QueryBuilder) SetAllowedFields(fields map[string]string) *QueryBuilder
 	return qb
 }
 
-// BuildQuery builds the complete SQL query from filter parameters
+// SetKeyColumns sets the columns that identify a row. They are appended to
+// every ORDER BY so paging is stable and are part of each cursor.
+func (qb *QueryBuilder) SetKeyColumns(columns ...string) *QueryBuilder {
+	qb.keyColumns = columns
+	return qb
+}
+
+// SetDefaultSort sets the sort used when the request does not specify one.
+func (qb *QueryBuilder) SetDefaultSort(sorts ...models.SortCondition) *QueryBuilder {
+	qb.defaultSort = sorts
+	return qb
+}
+
+// reset clears the arguments collected by a previous build so the count
+// query and the page query each number their placeholders from $1.
+func (qb *QueryBuilder) reset() {
+	qb.args = []interface{}{}
+	qb.argIndex = 0
+}
+
+// BuildQuery builds the complete SQL query from filter parameters. When the
+// pagination carries a cursor the page is selected by keyset (rows after the
+// cursor position) instead of OFFSET.
 func (qb *QueryBuilder) BuildQuery(params *models.FilterParams) (string, []interface{}, error) {
+	qb.reset()
 	var query strings.Builder
 	query.WriteString(qb.baseQuery)
 
-	// Build WHERE clause from filters
-	if params != nil && params.Filters != nil {
-		whereSQL, err := qb.buildWhereClause(params.Filters)
+	conditions, err := qb.filterConditions(params)
+	if err != nil {
+		return "", nil, err
+	}
+
+	order, err := qb.orderColumns(params)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var cursor string
+	if params != nil && params.Pagination != nil {
+		cursor = params.Pagination.Cursor
+	}
+	if cursor != "" {
+		keyset, err := qb.buildKeysetCondition(order, cursor)
 		if err != nil {
 			return "", nil, err
 		}
-		if whereSQL != "" {
-			if strings.Contains(strings.ToUpper(qb.baseQuery), "WHERE") {
-				query.WriteString(" AND (")
-				query.WriteString(whereSQL)
-				query.WriteString(")")
-			} else {
-				query.WriteString(" WHERE ")
-				query.WriteString(whereSQL)
-			}
-		}
+		conditions = append(conditions, keyset)
 	}
+	qb.writeWhere(&query, conditions)
 
-	// Build ORDER BY clause
-	if params != nil && len(params.Sort) > 0 {
-		orderBy, err := qb.buildOrderBy(params.Sort)
-		if err != nil {
-			return "", nil, err
+	if len(order) > 0 {
+		clauses := make([]string, len(order))
+		for i, o := range order {
+			clauses[i] = o.column + " " + o.direction
 		}
 		query.WriteString(" ORDER BY ")
-		query.WriteString(orderBy)
+		query.WriteString(strings.Join(clauses, ", "))
 	}
 
 	// Build LIMIT and OFFSET
 	if params != nil && params.Pagination != nil {
-		query.WriteString(fmt.Sprintf(" LIMIT %d OFFSET %d", params.Pagination.Limit, params.Pagination.Offset))
+		if cursor != "" {
+			query.WriteString(fmt.Sprintf(" LIMIT %d", params.Pagination.Limit))
+		} else {
+			query.WriteString(fmt.Sprintf(" LIMIT %d OFFSET %d", params.Pagination.Limit, params.Pagination.Offset))
+		}
 	}
 
 	return query.String(), qb.args, nil
 }
 
-// BuildCountQuery builds a count query for pagination
+// BuildCountQuery builds a count query for pagination. The cursor is
+// ignored so the total covers every row matching the filters.
 func (qb *QueryBuilder) BuildCountQuery(params *models.FilterParams) (string, []interface{}, error) {
-	// Reset args for count query
-	qb.args = []interface{}{}
-	qb.argIndex = 0
-
+	qb.reset()
 	var query strings.Builder
+	query.WriteString(qb.baseQuery)
 
-	// Extract the base table/joins part from the base query
-	baseQuery := qb.baseQuery
-	if strings.Contains(strings.ToUpper(baseQuery), "SELECT") {
-		// Replace SELECT ... FROM with SELECT COUNT(*) FROM
-		parts := strings.SplitN(strings.ToUpper(baseQuery), "FROM", 2)
-		if len(parts) == 2 {
-			query.WriteString("SELECT COUNT(*) FROM")
-			query.WriteString(parts[1])
-		} else {
-			return "", nil, fmt.Errorf("invalid base query for count")
-		}
-	} else {
-		// Assume it's already a FROM clause
-		query.WriteString("SELECT COUNT(*) ")
-		query.WriteString(baseQuery)
+	conditions, err := qb.filterConditions(params)
+	if err != nil {
+		return "", nil, err
 	}
+	qb.writeWhere(&query, conditions)
 
-	// Build WHERE clause from filters (same as regular query)
-	if params != nil && params.Filters != nil {
-		whereSQL, err := qb.buildWhereClause(params.Filters)
-		if err != nil {
-			return "", nil, err
-		}
-		if whereSQL != "" {
-			if strings.Contains(strings.ToUpper(query.String()), "WHERE") {
-				query.WriteString(" AND (")
-				query.WriteString(whereSQL)
-				query.WriteString(")")
-			} else {
-				query.WriteString(" WHERE ")
-				query.WriteString(whereSQL)
-			}
-		}
+	return "SELECT COUNT(*) FROM (" + query.String() + ") AS sub", qb.args, nil
+}
+
+// filterConditions returns the WHERE condition built from the filters, if any.
+func (qb *QueryBuilder) filterConditions(params *models.FilterParams) ([]string, error) {
+	if params == nil || params.Filters == nil {
+		return nil, nil
+	}
+	whereSQL, err := qb.buildWhereClause(params.Filters)
+	if err != nil || whereSQL == "" {
+		return nil, err
 	}
+	return []string{whereSQL}, nil
+}
 
-	return query.String(), qb.args, nil
+// writeWhere appends conditions to query, extending a WHERE already present
+// in the base query.
+func (qb *QueryBuilder) writeWhere(query *strings.Builder, conditions []string) {
+	if len(conditions) == 0 {
+		return
+	}
+	for i, c := range conditions {
+		conditions[i] = "(" + c + ")"
+	}
+	joined := strings.Join(conditions, " AND ")
+	if strings.Contains(strings.ToUpper(qb.baseQuery), "WHERE") {
+		query.WriteString(" AND ")
+	} else {
+		query.WriteString(" WHERE ")
+	}
+	query.WriteString(joined)
 }
 
 // buildWhereClause builds the WHERE clause from a filter group
@@ -158,7 +199,7 @@ func (qb *QueryBuilder) buildCondition(condition *models.FilterCondition) (strin
 	// Get the column name from the field mapping
 	columnName, ok := qb.allowedFields[condition.Field]
 	if !ok {
-		return "", fmt.Errorf("field '%s' is not allowed for filtering", condition.Field)
+		return "", fmt.Errorf("%w: field '%s' is not allowed for filtering", ErrInvalidFilter, condition.Field)
 	}
 
 	switch condition.Operator {
@@ -191,7 +232,7 @@ func (qb *QueryBuilder) buildCondition(condition *models.FilterCondition) (strin
 	case models.FilterOpIsNotNull:
 		return fmt.Sprintf("%s IS NOT NULL", columnName), nil
 	default:
-		return "", fmt.Errorf("unsupported operator: %s", condition.Operator)
+		return "", fmt.Errorf("%w: unsupported operator: %s", ErrInvalidFilter, condition.Operator)
 	}
 }
 
@@ -246,19 +287,164 @@ func (qb *QueryBuilder) buildInCondition(columnName string, values []interface{}
 	return fmt.Sprintf("%s %s (%s)", columnName, operator, strings.Join(placeholders, ", ")), nil
 }
 
-// buildOrderBy builds the ORDER BY clause
-func (qb *QueryBuilder) buildOrderBy(sorts []models.SortCondition) (string, error) {
-	var orderClauses []string
+// orderColumn is one ORDER BY term.
+type orderColumn struct {
+	column    string
+	direction string
+}
+
+// orderColumns resolves the requested sort, or the default sort, to columns
+// and appends the key columns as a tiebreak in the direction of the last
+// sort so that every row has a unique position.
+func (qb *QueryBuilder) orderColumns(params *models.FilterParams) ([]orderColumn, error) {
+	sorts := qb.defaultSort
+	if params != nil && len(params.Sort) > 0 {
+		sorts = params.Sort
+	}
 
+	var order []orderColumn
+	seen := map[string]bool{}
 	for _, sort := range sorts {
 		columnName, ok := qb.allowedFields[sort.Field]
 		if !ok {
-			return "", fmt.Errorf("field '%s' is not allowed for sorting", sort.Field)
+			return nil, fmt.Errorf("%w: field '%s' is not allowed for sorting", ErrInvalidFilter, sort.Field)
+		}
+		order = append(order, orderColumn{column: columnName, direction: strings.ToUpper(sort.Direction)})
+		seen[columnName] = true
+	}
+
+	direction := "ASC"
+	if len(order) > 0 {
+		direction = order[len(order)-1].direction
+	}
+	for _, key := range qb.keyColumns {
+		if !seen[key] {
+			order = append(order, orderColumn{column: key, direction: direction})
+		}
+	}
+	return order, nil
+}
+
+// buildKeysetCondition builds the row comparison that selects rows after
+// the cursor position. All sort terms must share one direction.
+func (qb *QueryBuilder) buildKeysetCondition(order []orderColumn, cursor string) (string, error) {
+	if len(qb.keyColumns) == 0 {
+		return "", fmt.Errorf("%w: cursor pagination is not supported for this list", ErrInvalidFilter)
+	}
+	values, err := DecodeCursor(cursor)
+	if err != nil {
+		return "", err
+	}
+	if len(values) != len(order) {
+		return "", fmt.Errorf("%w: cursor does not match the sort", ErrInvalidFilter)
+	}
+
+	columns := make([]string, len(order))
+	placeholders := make([]string, len(order))
+	for i, o := range order {
+		if o.direction != order[0].direction {
+			return "", fmt.Errorf("%w: cursor pagination requires every sort field to use the same direction", ErrInvalidFilter)
+		}
+		columns[i] = o.column
+		qb.argIndex++
+		placeholders[i] = fmt.Sprintf("$%d", qb.argIndex)
+		qb.args = append(qb.args, values[i])
+	}
+
+	operator := ">"
+	if order[0].direction == "DESC" {
+		operator = "<"
+	}
+	return fmt.Sprintf("(%s) %s (%s)", strings.Join(columns, ", "), operator, strings.Join(placeholders, ", ")), nil
+}
+
+// NextCursor returns the cursor positioned after row, which must be the last
+// row of a page built with params. It returns "" when a sort column is not
+// a plain column of row, since such a page cannot be continued by keyset.
+func (qb *QueryBuilder) NextCursor(row interface{}, params *models.FilterParams) string {
+	if len(qb.keyColumns) == 0 {
+		return ""
+	}
+	order, err := qb.orderColumns(params)
+	if err != nil {
+		return ""
+	}
+	values := make([]interface{}, len(order))
+	for i, o := range order {
+		v, ok := columnValue(row, o.column)
+		if !ok || v == nil {
+			return ""
+		}
+		values[i] = v
+	}
+	return EncodeCursor(values)
+}
+
+// columnValue reads the field of row tagged with column's name, ignoring a
+// table alias such as "s.". Valuers such as money.Amount are converted to
+// their database form.
+func columnValue(row interface{}, column string) (interface{}, bool) {
+	if i := strings.LastIndex(column, "."); i >= 0 {
+		column = column[i+1:]
+	}
+	v := reflect.Indirect(reflect.ValueOf(row))
+	if v.Kind() != reflect.Struct {
+		return nil, false
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("db") != column {
+			continue
+		}
+		f := v.Field(i)
+		if f.Kind() == reflect.Ptr {
+			if f.IsNil() {
+				return nil, true
+			}
+			f = f.Elem()
+		}
+		val := f.Interface()
+		if valuer, ok := val.(driver.Valuer); ok {
+			dv, err := valuer.Value()
+			if err != nil {
+				return nil, false
+			}
+			return dv, true
 		}
-		orderClauses = append(orderClauses, fmt.Sprintf("%s %s", columnName, strings.ToUpper(sort.Direction)))
+		return val, true
 	}
+	return nil, false
+}
 
-	return strings.Join(orderClauses, ", "), nil
+// EncodeCursor encodes the sort and key values of a row as an opaque cursor.
+func EncodeCursor(values []interface{}) string {
+	b, err := json.Marshal(values)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor decodes a cursor made by EncodeCursor. Numbers are returned
+// as strings so Postgres converts them to the column type without float
+// rounding.
+func DecodeCursor(cursor string) ([]interface{}, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidFilter)
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var values []interface{}
+	if err := dec.Decode(&values); err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidFilter)
+	}
+	for i, v := range values {
+		if n, ok := v.(json.Number); ok {
+			values[i] = n.String()
+		}
+	}
+	return values, nil
 }
 
 // ParseValue parses a string value to the appropriate type
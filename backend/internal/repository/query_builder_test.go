@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func journalFilterParams() *models.FilterParams {
+	return &models.FilterParams{
+		Filters: &models.FilterGroup{
+			Logic: "AND",
+			Conditions: []models.FilterCondition{
+				{Field: "store", Operator: models.FilterOpEquals, Value: "ShopA"},
+				{Field: "description", Operator: models.FilterOpContains, Value: "Bayar"},
+			},
+		},
+		Sort:       []models.SortCondition{{Field: "entry_date", Direction: "desc"}},
+		Pagination: models.NewPaginationParams(2, 10),
+	}
+}
+
+func journalQueryBuilder() *QueryBuilder {
+	return NewQueryBuilder("SELECT * FROM journal_entries").
+		SetAllowedFields(map[string]string{
+			"entry_date":  "entry_date",
+			"description": "description",
+			"store":       "store",
+		}).
+		SetKeyColumns("journal_id")
+}
+
+func TestQueryBuilderCountThenQueryNumbersArgsFromOne(t *testing.T) {
+	qb := journalQueryBuilder()
+	params := journalFilterParams()
+
+	count, countArgs, err := qb.BuildCountQuery(params)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT COUNT(*) FROM (SELECT * FROM journal_entries WHERE (store = $1 AND description ILIKE $2)) AS sub", count)
+	assert.Equal(t, []interface{}{"ShopA", "%Bayar%"}, countArgs)
+
+	query, args, err := qb.BuildQuery(params)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM journal_entries WHERE (store = $1 AND description ILIKE $2)"+
+		" ORDER BY entry_date DESC, journal_id DESC LIMIT 10 OFFSET 10", query)
+	assert.Equal(t, []interface{}{"ShopA", "%Bayar%"}, args)
+}
+
+func TestQueryBuilderCountKeepsLiteralCase(t *testing.T) {
+	qb := NewQueryBuilder("SELECT * FROM journal_entries WHERE source_type = 'Expense'")
+	count, _, err := qb.BuildCountQuery(nil)
+	require.NoError(t, err)
+	assert.Contains(t, count, "'Expense'")
+}
+
+func TestQueryBuilderCursorUsesKeyset(t *testing.T) {
+	qb := journalQueryBuilder()
+	params := journalFilterParams()
+	entryDate := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	row := models.JournalEntry{JournalID: 42, EntryDate: entryDate}
+
+	params.Pagination.Cursor = qb.NextCursor(row, params)
+	require.NotEmpty(t, params.Pagination.Cursor)
+
+	query, args, err := qb.BuildQuery(params)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM journal_entries WHERE (store = $1 AND description ILIKE $2)"+
+		" AND ((entry_date, journal_id) < ($3, $4))"+
+		" ORDER BY entry_date DESC, journal_id DESC LIMIT 10", query)
+	assert.Equal(t, []interface{}{"ShopA", "%Bayar%", "2025-03-01T00:00:00Z", "42"}, args)
+}
+
+func TestQueryBuilderRejectsInvalidInput(t *testing.T) {
+	qb := journalQueryBuilder()
+
+	_, _, err := qb.BuildQuery(&models.FilterParams{
+		Sort: []models.SortCondition{{Field: "password", Direction: "asc"}},
+	})
+	assert.True(t, errors.Is(err, ErrInvalidFilter))
+
+	params := journalFilterParams()
+	params.Pagination.Cursor = "not-a-cursor!"
+	_, _, err = qb.BuildQuery(params)
+	assert.True(t, errors.Is(err, ErrInvalidFilter))
+
+	params.Pagination.Cursor = EncodeCursor([]interface{}{"2025-03-01"})
+	_, _, err = qb.BuildQuery(params)
+	assert.True(t, errors.Is(err, ErrInvalidFilter), "cursor must match the sort")
+}
+
+func TestListFilteredSetsNextCursor(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	repo := NewJournalRepo(sqlx.NewDb(db, "postgres"))
+
+	params := &models.FilterParams{Pagination: models.NewPaginationParams(1, 2)}
+	day := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM \(SELECT \* FROM journal_entries\) AS sub`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+	mock.ExpectQuery(`SELECT \* FROM journal_entries ORDER BY entry_date DESC, journal_id DESC LIMIT 3 OFFSET 0`).
+		WillReturnRows(sqlmock.NewRows([]string{"journal_id", "entry_date"}).
+			AddRow(int64(9), day).AddRow(int64(8), day).AddRow(int64(7), day))
+
+	res, err := repo.ListJournalEntriesFiltered(context.Background(), params)
+	require.NoError(t, err)
+	assert.Equal(t, 5, res.Total)
+	assert.Len(t, res.Data, 2)
+
+	values, err := DecodeCursor(res.NextCursor)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"2025-03-01T00:00:00Z", "8"}, values)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+)
+
+// ReconciliationPolicyRepo manages reconciliation_policies.
+type ReconciliationPolicyRepo struct{ db DBTX }
+
+// NewReconciliationPolicyRepo constructs a ReconciliationPolicyRepo.
+func NewReconciliationPolicyRepo(db DBTX) *ReconciliationPolicyRepo {
+	return &ReconciliationPolicyRepo{db: db}
+}
+
+// Get fetches the policy of a store.
+func (r *ReconciliationPolicyRepo) Get(ctx context.Context, store string) (*models.ReconciliationPolicy, error) {
+	var p models.ReconciliationPolicy
+	if err := r.db.GetContext(ctx, &p, `SELECT * FROM reconciliation_policies WHERE store=$1`, store); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// List returns every stored policy ordered by store.
+func (r *ReconciliationPolicyRepo) List(ctx context.Context) ([]models.ReconciliationPolicy, error) {
+	var list []models.ReconciliationPolicy
+	err := r.db.SelectContext(ctx, &list, `SELECT * FROM reconciliation_policies ORDER BY store`)
+	if list == nil {
+		list = []models.ReconciliationPolicy{}
+	}
+	return list, err
+}
+
+// Upsert inserts or replaces the policy of p.Store and fills in timestamps.
+func (r *ReconciliationPolicyRepo) Upsert(ctx context.Context, p *models.ReconciliationPolicy) error {
+	query := `INSERT INTO reconciliation_policies
+              (store, max_allowed_failures, failure_threshold_percent, critical_error_types,
+               retry_failed_transactions, generate_detailed_report, amount_tolerance,
+               shipping_discrepancy_threshold)
+              VALUES (:store,:max_allowed_failures,:failure_threshold_percent,:critical_error_types,
+                      :retry_failed_transactions,:generate_detailed_report,:amount_tolerance,
+                      :shipping_discrepancy_threshold)
+              ON CONFLICT (store) DO UPDATE SET
+                max_allowed_failures=EXCLUDED.max_allowed_failures,
+                failure_threshold_percent=EXCLUDED.failure_threshold_percent,
+                critical_error_types=EXCLUDED.critical_error_types,
+                retry_failed_transactions=EXCLUDED.retry_failed_transactions,
+                generate_detailed_report=EXCLUDED.generate_detailed_report,
+                amount_tolerance=EXCLUDED.amount_tolerance,
+                shipping_discrepancy_threshold=EXCLUDED.shipping_discrepancy_threshold,
+                updated_at=NOW()
+              RETURNING created_at, updated_at`
+	stmt, args, err := r.db.BindNamed(query, p)
+	if err != nil {
+		return err
+	}
+	return r.db.QueryRowxContext(ctx, stmt, args...).Scan(&p.CreatedAt, &p.UpdatedAt)
+}
+
+// Delete removes the policy of a store so it falls back to the defaults.
+func (r *ReconciliationPolicyRepo) Delete(ctx context.Context, store string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM reconciliation_policies WHERE store=$1`, store)
+	return err
+}
@@ -0,0 +1,274 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+)
+
+// RecurringExpenseRepo manages recurring_expenses, their lines, runs and
+// prepaid recognitions.
+type RecurringExpenseRepo struct{ db DBTX }
+
+// NewRecurringExpenseRepo constructs a RecurringExpenseRepo.
+func NewRecurringExpenseRepo(db DBTX) *RecurringExpenseRepo {
+	return &RecurringExpenseRepo{db: db}
+}
+
+// Create inserts a template with its lines and fills in its ID and
+// timestamps.
+func (r *RecurringExpenseRepo) Create(ctx context.Context, e *models.RecurringExpense) error {
+	query := `INSERT INTO recurring_expenses
+              (name, description, schedule, asset_account_id, store, mode,
+               deferral_account_id, spread_months, start_date, end_date, enabled, next_run_at)
+              VALUES (:name,:description,:schedule,:asset_account_id,:store,:mode,
+                      :deferral_account_id,:spread_months,:start_date,:end_date,:enabled,:next_run_at)
+              RETURNING id, created_at, updated_at`
+	stmt, args, err := r.db.BindNamed(query, e)
+	if err != nil {
+		return err
+	}
+	if err := r.db.QueryRowxContext(ctx, stmt, args...).Scan(&e.ID, &e.CreatedAt, &e.UpdatedAt); err != nil {
+		return err
+	}
+	return r.insertLines(ctx, e)
+}
+
+// Update saves every editable field of a template and replaces its lines.
+func (r *RecurringExpenseRepo) Update(ctx context.Context, e *models.RecurringExpense) error {
+	_, err := r.db.NamedExecContext(ctx, `UPDATE recurring_expenses SET
+              name=:name, description=:description, schedule=:schedule,
+              asset_account_id=:asset_account_id, store=:store, mode=:mode,
+              deferral_account_id=:deferral_account_id, spread_months=:spread_months,
+              start_date=:start_date, end_date=:end_date, enabled=:enabled,
+              next_run_at=:next_run_at, updated_at=NOW()
+              WHERE id=:id`, e)
+	if err != nil {
+		return err
+	}
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM recurring_expense_lines WHERE recurring_expense_id=$1`, e.ID); err != nil {
+		return err
+	}
+	return r.insertLines(ctx, e)
+}
+
+func (r *RecurringExpenseRepo) insertLines(ctx context.Context, e *models.RecurringExpense) error {
+	for i := range e.Lines {
+		l := &e.Lines[i]
+		l.RecurringExpenseID = e.ID
+		err := r.db.QueryRowxContext(ctx,
+			`INSERT INTO recurring_expense_lines (recurring_expense_id, account_id, amount)
+             VALUES ($1,$2,$3) RETURNING id`, l.RecurringExpenseID, l.AccountID, l.Amount).Scan(&l.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Delete removes a template with its lines and run history. Posted expenses
+// and journals are kept.
+func (r *RecurringExpenseRepo) Delete(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM recurring_expenses WHERE id=$1`, id)
+	return err
+}
+
+// Get fetches a template with its lines.
+func (r *RecurringExpenseRepo) Get(ctx context.Context, id int64) (*models.RecurringExpense, error) {
+	var e models.RecurringExpense
+	if err := r.db.GetContext(ctx, &e, `SELECT * FROM recurring_expenses WHERE id=$1`, id); err != nil {
+		return nil, err
+	}
+	list := []models.RecurringExpense{e}
+	if err := r.attachLines(ctx, list); err != nil {
+		return nil, err
+	}
+	return &list[0], nil
+}
+
+// List returns all templates ordered by name.
+func (r *RecurringExpenseRepo) List(ctx context.Context) ([]models.RecurringExpense, error) {
+	var list []models.RecurringExpense
+	if err := r.db.SelectContext(ctx, &list, `SELECT * FROM recurring_expenses ORDER BY name, id`); err != nil {
+		return nil, err
+	}
+	if list == nil {
+		list = []models.RecurringExpense{}
+	}
+	return list, r.attachLines(ctx, list)
+}
+
+// ListDue returns enabled templates whose next run is at or before now.
+func (r *RecurringExpenseRepo) ListDue(ctx context.Context, now time.Time) ([]models.RecurringExpense, error) {
+	var list []models.RecurringExpense
+	err := r.db.SelectContext(ctx, &list,
+		`SELECT * FROM recurring_expenses
+          WHERE enabled AND next_run_at IS NOT NULL AND next_run_at <= $1
+          ORDER BY next_run_at`, now)
+	if err != nil {
+		return nil, err
+	}
+	if list == nil {
+		list = []models.RecurringExpense{}
+	}
+	return list, r.attachLines(ctx, list)
+}
+
+func (r *RecurringExpenseRepo) attachLines(ctx context.Context, list []models.RecurringExpense) error {
+	if len(list) == 0 {
+		return nil
+	}
+	ids := make([]interface{}, len(list))
+	for i, e := range list {
+		ids[i] = e.ID
+	}
+	query, args, err := sqlx.In(`SELECT * FROM recurring_expense_lines WHERE recurring_expense_id IN (?) ORDER BY id`, ids)
+	if err != nil {
+		return err
+	}
+	var lines []models.RecurringExpenseLine
+	if err := r.db.SelectContext(ctx, &lines, r.db.Rebind(query), args...); err != nil {
+		return err
+	}
+	byID := map[int64][]models.RecurringExpenseLine{}
+	for _, l := range lines {
+		byID[l.RecurringExpenseID] = append(byID[l.RecurringExpenseID], l)
+	}
+	for i := range list {
+		list[i].Lines = byID[list[i].ID]
+		if list[i].Lines == nil {
+			list[i].Lines = []models.RecurringExpenseLine{}
+		}
+	}
+	return nil
+}
+
+// MarkRun records a run and schedules the next one.
+func (r *RecurringExpenseRepo) MarkRun(ctx context.Context, id int64, ranAt time.Time, next *time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE recurring_expenses SET last_run_at=$2, next_run_at=$3, updated_at=NOW() WHERE id=$1`,
+		id, ranAt, next)
+	return err
+}
+
+// InsertRun records an occurrence and fills in its ID. It returns false
+// without error when the occurrence was recorded already.
+func (r *RecurringExpenseRepo) InsertRun(ctx context.Context, run *models.RecurringExpenseRun) (bool, error) {
+	err := r.db.QueryRowxContext(ctx,
+		`INSERT INTO recurring_expense_runs
+          (recurring_expense_id, scheduled_for, mode, amount)
+          VALUES ($1,$2,$3,$4)
+          ON CONFLICT (recurring_expense_id, scheduled_for) DO NOTHING
+          RETURNING id, created_at`,
+		run.RecurringExpenseID, run.ScheduledFor, run.Mode, run.Amount).
+		Scan(&run.ID, &run.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// SetRunPosting links a run to the expense or journal that posted it.
+func (r *RecurringExpenseRepo) SetRunPosting(ctx context.Context, run *models.RecurringExpenseRun) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE recurring_expense_runs SET expense_id=$2, journal_id=$3 WHERE id=$1`,
+		run.ID, run.ExpenseID, run.JournalID)
+	return err
+}
+
+// ListRuns returns the runs of a template, newest first.
+func (r *RecurringExpenseRepo) ListRuns(ctx context.Context, id int64, limit, offset int) ([]models.RecurringExpenseRun, error) {
+	var list []models.RecurringExpenseRun
+	err := r.db.SelectContext(ctx, &list,
+		`SELECT * FROM recurring_expense_runs WHERE recurring_expense_id=$1
+          ORDER BY scheduled_for DESC, id DESC LIMIT $2 OFFSET $3`, id, limit, offset)
+	if list == nil {
+		list = []models.RecurringExpenseRun{}
+	}
+	return list, err
+}
+
+// ListUnsettledRuns returns accrued runs of a template that have not been
+// paid yet, oldest first.
+func (r *RecurringExpenseRepo) ListUnsettledRuns(ctx context.Context, id int64) ([]models.RecurringExpenseRun, error) {
+	var list []models.RecurringExpenseRun
+	err := r.db.SelectContext(ctx, &list,
+		`SELECT * FROM recurring_expense_runs
+          WHERE recurring_expense_id=$1 AND mode=$2 AND settled_expense_id IS NULL
+          ORDER BY scheduled_for`, id, models.RecurringAccrued)
+	if list == nil {
+		list = []models.RecurringExpenseRun{}
+	}
+	return list, err
+}
+
+// SettleRuns marks accrued runs as paid by expenseID.
+func (r *RecurringExpenseRepo) SettleRuns(ctx context.Context, runIDs []int64, expenseID string) error {
+	if len(runIDs) == 0 {
+		return nil
+	}
+	query, args, err := sqlx.In(`UPDATE recurring_expense_runs SET settled_expense_id=? WHERE id IN (?)`, expenseID, runIDs)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, r.db.Rebind(query), args...)
+	return err
+}
+
+// InsertRecognitions stores the monthly slices of a prepaid run.
+func (r *RecurringExpenseRepo) InsertRecognitions(ctx context.Context, recs []models.RecurringExpenseRecognition) error {
+	for i := range recs {
+		rec := &recs[i]
+		err := r.db.QueryRowxContext(ctx,
+			`INSERT INTO recurring_expense_recognitions
+             (run_id, due_date, account_id, deferral_account_id, store, description, amount)
+             VALUES ($1,$2,$3,$4,$5,$6,$7) RETURNING id`,
+			rec.RunID, rec.DueDate, rec.AccountID, rec.DeferralAccountID, rec.Store, rec.Description, rec.Amount).Scan(&rec.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListDueRecognitions returns unposted recognitions due at or before now,
+// ordered so slices of the same run and month are adjacent.
+func (r *RecurringExpenseRepo) ListDueRecognitions(ctx context.Context, now time.Time) ([]models.RecurringExpenseRecognition, error) {
+	var list []models.RecurringExpenseRecognition
+	err := r.db.SelectContext(ctx, &list,
+		`SELECT * FROM recurring_expense_recognitions
+          WHERE journal_id IS NULL AND due_date <= $1
+          ORDER BY due_date, run_id, id`, now)
+	if list == nil {
+		list = []models.RecurringExpenseRecognition{}
+	}
+	return list, err
+}
+
+// CountUnpostedRecognitions counts the prepaid slices of a template that
+// have not been released to the expense accounts yet.
+func (r *RecurringExpenseRepo) CountUnpostedRecognitions(ctx context.Context, id int64) (int, error) {
+	var n int
+	err := r.db.GetContext(ctx, &n,
+		`SELECT COUNT(*) FROM recurring_expense_recognitions c
+           JOIN recurring_expense_runs r ON r.id = c.run_id
+          WHERE r.recurring_expense_id=$1 AND c.journal_id IS NULL`, id)
+	return n, err
+}
+
+// MarkRecognitionsPosted links recognitions to the journal that posted them.
+func (r *RecurringExpenseRepo) MarkRecognitionsPosted(ctx context.Context, ids []int64, journalID int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	query, args, err := sqlx.In(`UPDATE recurring_expense_recognitions SET journal_id=? WHERE id IN (?)`, journalID, ids)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, r.db.Rebind(query), args...)
+	return err
+}
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+)
+
+// ReportSubscriptionRepo manages report_subscriptions and report_deliveries.
+type ReportSubscriptionRepo struct{ db DBTX }
+
+// NewReportSubscriptionRepo constructs a ReportSubscriptionRepo.
+func NewReportSubscriptionRepo(db DBTX) *ReportSubscriptionRepo {
+	return &ReportSubscriptionRepo{db: db}
+}
+
+// Create inserts a subscription and fills in its ID and timestamps.
+func (r *ReportSubscriptionRepo) Create(ctx context.Context, s *models.ReportSubscription) error {
+	query := `INSERT INTO report_subscriptions
+              (name, report, format, store, channel, period, comparison, schedule,
+               sink_type, sink_target, enabled, next_run_at)
+              VALUES (:name,:report,:format,:store,:channel,:period,:comparison,:schedule,
+                      :sink_type,:sink_target,:enabled,:next_run_at)
+              RETURNING id, created_at, updated_at`
+	stmt, args, err := r.db.BindNamed(query, s)
+	if err != nil {
+		return err
+	}
+	return r.db.QueryRowxContext(ctx, stmt, args...).Scan(&s.ID, &s.CreatedAt, &s.UpdatedAt)
+}
+
+// Update saves every editable field of a subscription.
+func (r *ReportSubscriptionRepo) Update(ctx context.Context, s *models.ReportSubscription) error {
+	_, err := r.db.NamedExecContext(ctx, `UPDATE report_subscriptions SET
+              name=:name, report=:report, format=:format, store=:store, channel=:channel,
+              period=:period, comparison=:comparison, schedule=:schedule,
+              sink_type=:sink_type, sink_target=:sink_target, enabled=:enabled,
+              next_run_at=:next_run_at, updated_at=NOW()
+              WHERE id=:id`, s)
+	return err
+}
+
+// Delete removes a subscription and its delivery history.
+func (r *ReportSubscriptionRepo) Delete(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM report_subscriptions WHERE id=$1`, id)
+	return err
+}
+
+// Get fetches a subscription by ID.
+func (r *ReportSubscriptionRepo) Get(ctx context.Context, id int64) (*models.ReportSubscription, error) {
+	var s models.ReportSubscription
+	if err := r.db.GetContext(ctx, &s, `SELECT * FROM report_subscriptions WHERE id=$1`, id); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// List returns all subscriptions ordered by name.
+func (r *ReportSubscriptionRepo) List(ctx context.Context) ([]models.ReportSubscription, error) {
+	var list []models.ReportSubscription
+	err := r.db.SelectContext(ctx, &list, `SELECT * FROM report_subscriptions ORDER BY name, id`)
+	if list == nil {
+		list = []models.ReportSubscription{}
+	}
+	return list, err
+}
+
+// ClaimDue claims the enabled subscriptions whose next run is at or before
+// now until claimedUntil and returns them. Subscriptions claimed by another
+// run, or locked by a concurrent claim, are skipped; a claim that was not
+// released by MarkRun expires at its claimedUntil.
+func (r *ReportSubscriptionRepo) ClaimDue(ctx context.Context, now, claimedUntil time.Time) ([]models.ReportSubscription, error) {
+	var list []models.ReportSubscription
+	err := r.db.SelectContext(ctx, &list,
+		`UPDATE report_subscriptions SET claimed_until = $2
+          WHERE id IN (
+            SELECT id FROM report_subscriptions
+             WHERE enabled AND next_run_at IS NOT NULL AND next_run_at <= $1
+               AND (claimed_until IS NULL OR claimed_until <= $1)
+             ORDER BY next_run_at
+             FOR UPDATE SKIP LOCKED)
+          RETURNING *`, now, claimedUntil)
+	if list == nil {
+		list = []models.ReportSubscription{}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].NextRunAt.Before(*list[j].NextRunAt) })
+	return list, err
+}
+
+// MarkRun records a run, schedules the next one and releases the claim.
+func (r *ReportSubscriptionRepo) MarkRun(ctx context.Context, id int64, ranAt time.Time, next *time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE report_subscriptions SET last_run_at=$2, next_run_at=$3, claimed_until=NULL, updated_at=NOW() WHERE id=$1`,
+		id, ranAt, next)
+	return err
+}
+
+// InsertDelivery creates a delivery row and fills in its ID and timestamps.
+func (r *ReportSubscriptionRepo) InsertDelivery(ctx context.Context, d *models.ReportDelivery) error {
+	query := `INSERT INTO report_deliveries (subscription_id, scheduled_for, status, attempts)
+              VALUES ($1, $2, $3, $4) RETURNING id, created_at, updated_at`
+	return r.db.QueryRowxContext(ctx, query, d.SubscriptionID, d.ScheduledFor, d.Status, d.Attempts).
+		Scan(&d.ID, &d.CreatedAt, &d.UpdatedAt)
+}
+
+// UpdateDelivery saves the outcome of a delivery attempt.
+func (r *ReportSubscriptionRepo) UpdateDelivery(ctx context.Context, d *models.ReportDelivery) error {
+	_, err := r.db.NamedExecContext(ctx, `UPDATE report_deliveries SET
+              status=:status, attempts=:attempts, file_name=:file_name, last_error=:last_error,
+              next_retry_at=:next_retry_at, delivered_at=:delivered_at, updated_at=NOW()
+              WHERE id=:id`, d)
+	return err
+}
+
+// GetDelivery fetches a delivery by ID.
+func (r *ReportSubscriptionRepo) GetDelivery(ctx context.Context, id int64) (*models.ReportDelivery, error) {
+	var d models.ReportDelivery
+	if err := r.db.GetContext(ctx, &d, `SELECT * FROM report_deliveries WHERE id=$1`, id); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// ListDeliveries returns the delivery history of a subscription, newest first.
+// A zero subscriptionID lists deliveries of all subscriptions.
+func (r *ReportSubscriptionRepo) ListDeliveries(ctx context.Context, subscriptionID int64, limit, offset int) ([]models.ReportDelivery, error) {
+	var list []models.ReportDelivery
+	err := r.db.SelectContext(ctx, &list,
+		`SELECT * FROM report_deliveries
+          WHERE ($1 = 0 OR subscription_id = $1)
+          ORDER BY created_at DESC, id DESC
+          LIMIT $2 OFFSET $3`, subscriptionID, limit, offset)
+	if list == nil {
+		list = []models.ReportDelivery{}
+	}
+	return list, err
+}
+
+// ClaimRetryableDeliveries claims deliveries whose retry is due and pending
+// deliveries last touched before staleBefore, which a crashed run left
+// behind. Claimed deliveries are set back to pending, so a concurrent claim
+// skips them until they go stale again.
+func (r *ReportSubscriptionRepo) ClaimRetryableDeliveries(ctx context.Context, now, staleBefore time.Time) ([]models.ReportDelivery, error) {
+	var list []models.ReportDelivery
+	err := r.db.SelectContext(ctx, &list,
+		`UPDATE report_deliveries SET status = $1, updated_at = $2
+          WHERE id IN (
+            SELECT id FROM report_deliveries
+             WHERE (status = $3 AND next_retry_at <= $2)
+                OR (status = $1 AND updated_at < $4)
+             ORDER BY id
+             FOR UPDATE SKIP LOCKED)
+          RETURNING *`, models.DeliveryPending, now, models.DeliveryRetrying, staleBefore)
+	if list == nil {
+		list = []models.ReportDelivery{}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list, err
+}
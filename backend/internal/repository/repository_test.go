@@ -31,17 +31,25 @@ func TestMain(m *testing.M) {
 	// Load configuration
 	cfg := config.MustLoadConfig()
 
-	// Connect to Postgres (skip tests if not available)
+	// Connect to Postgres (skip tests if not available, unless
+	// REQUIRE_TEST_DB is set as it is in CI)
 	var err error
 	testDB, err = db.ConnectPostgres(cfg.Database.URL)
 	if err != nil {
+		if os.Getenv("REQUIRE_TEST_DB") != "" {
+			fmt.Fprintf(os.Stderr, "repository tests need a database: %v\n", err)
+			os.Exit(1)
+		}
 		fmt.Fprintf(os.Stderr, "skipping repository tests: %v\n", err)
 		os.Exit(0)
 	}
 
 	// Run migrations (ensure tables exist)
 	migrationsDir := "file://internal/migrations"
-	_ = db.RunMigrations(cfg.Database.URL, migrationsDir)
+	if err := db.RunMigrations(cfg.Database.URL, migrationsDir); err != nil && os.Getenv("REQUIRE_TEST_DB") != "" {
+		fmt.Fprintf(os.Stderr, "repository test migrations failed: %v\n", err)
+		os.Exit(1)
+	}
 
 	// Run tests
 	code := m.Run()
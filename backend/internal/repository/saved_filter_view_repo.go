@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+)
+
+// SavedFilterViewRepo manages saved_filter_views.
+type SavedFilterViewRepo struct{ db DBTX }
+
+// NewSavedFilterViewRepo constructs a SavedFilterViewRepo.
+func NewSavedFilterViewRepo(db DBTX) *SavedFilterViewRepo {
+	return &SavedFilterViewRepo{db: db}
+}
+
+// Create inserts a view and fills in its ID and timestamps.
+func (r *SavedFilterViewRepo) Create(ctx context.Context, v *models.SavedFilterView) error {
+	query := `INSERT INTO saved_filter_views
+              (name, resource, owner, shared, description, filters, sort, page_size)
+              VALUES (:name,:resource,:owner,:shared,:description,:filters,:sort,:page_size)
+              RETURNING id, created_at, updated_at`
+	stmt, args, err := r.db.BindNamed(query, v)
+	if err != nil {
+		return err
+	}
+	return r.db.QueryRowxContext(ctx, stmt, args...).Scan(&v.ID, &v.CreatedAt, &v.UpdatedAt)
+}
+
+// Update saves every editable field of a view. The owner and resource do
+// not change.
+func (r *SavedFilterViewRepo) Update(ctx context.Context, v *models.SavedFilterView) error {
+	query := `UPDATE saved_filter_views SET
+              name=:name, shared=:shared, description=:description, filters=:filters,
+              sort=:sort, page_size=:page_size, updated_at=NOW()
+              WHERE id=:id
+              RETURNING updated_at`
+	stmt, args, err := r.db.BindNamed(query, v)
+	if err != nil {
+		return err
+	}
+	return r.db.QueryRowxContext(ctx, stmt, args...).Scan(&v.UpdatedAt)
+}
+
+// Delete removes a view.
+func (r *SavedFilterViewRepo) Delete(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM saved_filter_views WHERE id=$1`, id)
+	return err
+}
+
+// Get fetches a view by ID.
+func (r *SavedFilterViewRepo) Get(ctx context.Context, id int64) (*models.SavedFilterView, error) {
+	var v models.SavedFilterView
+	if err := r.db.GetContext(ctx, &v, `SELECT * FROM saved_filter_views WHERE id=$1`, id); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// GetByName fetches the view named name for resource that user can see.
+// The user's own view wins over a shared view with the same name.
+func (r *SavedFilterViewRepo) GetByName(ctx context.Context, resource, name, user string) (*models.SavedFilterView, error) {
+	var v models.SavedFilterView
+	err := r.db.GetContext(ctx, &v,
+		`SELECT * FROM saved_filter_views
+          WHERE resource=$1 AND name=$2 AND (owner=$3 OR shared)
+          ORDER BY (owner=$3) DESC, id
+          LIMIT 1`, resource, name, user)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// ListVisible returns the views user owns plus every shared view, for one
+// resource or for all when resource is empty.
+func (r *SavedFilterViewRepo) ListVisible(ctx context.Context, resource, user string) ([]models.SavedFilterView, error) {
+	var list []models.SavedFilterView
+	err := r.db.SelectContext(ctx, &list,
+		`SELECT * FROM saved_filter_views
+          WHERE ($1 = '' OR resource=$1) AND (owner=$2 OR shared)
+          ORDER BY resource, name, id`, resource, user)
+	if list == nil {
+		list = []models.SavedFilterView{}
+	}
+	return list, err
+}
@@ -2,9 +2,12 @@ package repository
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 )
 
 // ShippingDiscrepancyRepo handles database operations for shipping_discrepancies.
@@ -73,6 +76,34 @@ func (r *ShippingDiscrepancyRepo) GetAllShippingDiscrepancies(
 	return list, err
 }
 
+// ListShippingDiscrepanciesFiltered returns discrepancies using the filtering
+// framework.
+func (r *ShippingDiscrepancyRepo) ListShippingDiscrepanciesFiltered(
+	ctx context.Context,
+	params *models.FilterParams,
+) (*models.QueryResult, error) {
+	return listFiltered[models.ShippingDiscrepancy](ctx, r.db, filteredList{
+		base: "SELECT * FROM shipping_discrepancies",
+		fields: map[string]string{
+			"id":                  "id",
+			"invoice_number":      "invoice_number",
+			"return_id":           "return_id",
+			"discrepancy_type":    "discrepancy_type",
+			"discrepancy_amount":  "discrepancy_amount",
+			"actual_shipping_fee": "actual_shipping_fee",
+			"order_date":          "order_date",
+			"store_name":          "store_name",
+			"type":                "discrepancy_type",
+			"store":               "store_name",
+			"order":               "invoice_number",
+			"order_no":            "invoice_number",
+			"created_at":          "created_at",
+		},
+		keys: []string{"id"},
+		sort: models.SortCondition{Field: "created_at", Direction: "desc"},
+	}, params)
+}
+
 // GetShippingDiscrepanciesByType retrieves shipping discrepancies by type.
 func (r *ShippingDiscrepancyRepo) GetShippingDiscrepanciesByType(
 	ctx context.Context,
@@ -149,6 +180,85 @@ func (r *ShippingDiscrepancyRepo) GetShippingDiscrepancySumsByDateRange(
 	return result, rows.Err()
 }
 
+// ShippingDiscrepancyQuery narrows discrepancies for reports. Empty fields
+// and zero times match everything. Dates compare the order date, or the
+// creation time when the order date is unknown.
+type ShippingDiscrepancyQuery struct {
+	Store string
+	Type  string
+	From  time.Time
+	To    time.Time
+}
+
+func (q ShippingDiscrepancyQuery) where() (string, []interface{}) {
+	var conds []string
+	var args []interface{}
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if q.Store != "" {
+		add("store_name = $%d", q.Store)
+	}
+	if q.Type != "" {
+		add("discrepancy_type = $%d", q.Type)
+	}
+	if !q.From.IsZero() {
+		add("COALESCE(order_date, created_at) >= $%d", q.From)
+	}
+	if !q.To.IsZero() {
+		add("COALESCE(order_date, created_at) <= $%d", q.To)
+	}
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+// ListShippingDiscrepanciesForReport returns the discrepancies matching q
+// by order date, one page at a time.
+func (r *ShippingDiscrepancyRepo) ListShippingDiscrepanciesForReport(
+	ctx context.Context,
+	q ShippingDiscrepancyQuery,
+	limit, offset int,
+) ([]models.ShippingDiscrepancy, error) {
+	where, args := q.where()
+	n := len(args)
+	query := fmt.Sprintf(`SELECT * FROM shipping_discrepancies%s
+              ORDER BY COALESCE(order_date, created_at), id
+              LIMIT $%d OFFSET $%d`, where, n+1, n+2)
+	var list []models.ShippingDiscrepancy
+	err := r.db.SelectContext(ctx, &list, query, append(args, limit, offset)...)
+	if list == nil {
+		list = []models.ShippingDiscrepancy{}
+	}
+	return list, err
+}
+
+// SumShippingDiscrepanciesForReport totals the discrepancies matching q by
+// type.
+func (r *ShippingDiscrepancyRepo) SumShippingDiscrepanciesForReport(
+	ctx context.Context,
+	q ShippingDiscrepancyQuery,
+) (map[string]money.Amount, error) {
+	where, args := q.where()
+	query := `SELECT discrepancy_type, COALESCE(SUM(discrepancy_amount), 0) AS total_amount
+              FROM shipping_discrepancies` + where + `
+              GROUP BY discrepancy_type`
+	var rows []struct {
+		Type  string       `db:"discrepancy_type"`
+		Total money.Amount `db:"total_amount"`
+	}
+	if err := r.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, err
+	}
+	result := make(map[string]money.Amount, len(rows))
+	for _, row := range rows {
+		result[row.Type] = row.Total
+	}
+	return result, nil
+}
+
 // GetShippingDiscrepancyByInvoice retrieves a shipping discrepancy by invoice number.
 func (r *ShippingDiscrepancyRepo) GetShippingDiscrepancyByInvoice(
 	ctx context.Context,
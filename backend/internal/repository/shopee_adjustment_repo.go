@@ -77,6 +77,28 @@ func (r *ShopeeAdjustmentRepo) List(ctx context.Context, from, to string) ([]mod
 	return res, err
 }
 
+// ListFiltered returns adjustments using the filtering framework.
+func (r *ShopeeAdjustmentRepo) ListFiltered(ctx context.Context, params *models.FilterParams) (*models.QueryResult, error) {
+	return listFiltered[models.ShopeeAdjustment](ctx, r.db, filteredList{
+		base: "SELECT * FROM shopee_adjustments",
+		fields: map[string]string{
+			"id":                  "id",
+			"nama_toko":           "nama_toko",
+			"tanggal_penyesuaian": "tanggal_penyesuaian",
+			"tipe_penyesuaian":    "tipe_penyesuaian",
+			"alasan_penyesuaian":  "alasan_penyesuaian",
+			"biaya_penyesuaian":   "biaya_penyesuaian",
+			"no_pesanan":          "no_pesanan",
+			"store":               "nama_toko",
+			"order":               "no_pesanan",
+			"order_no":            "no_pesanan",
+			"created_at":          "tanggal_penyesuaian",
+		},
+		keys: []string{"id"},
+		sort: models.SortCondition{Field: "tanggal_penyesuaian", Direction: "desc"},
+	}, params)
+}
+
 func (r *ShopeeAdjustmentRepo) ListByOrder(ctx context.Context, order string) ([]models.ShopeeAdjustment, error) {
 	var res []models.ShopeeAdjustment
 	err := r.db.SelectContext(ctx, &res, `SELECT * FROM shopee_adjustments WHERE no_pesanan=$1 ORDER BY tanggal_penyesuaian`, order)
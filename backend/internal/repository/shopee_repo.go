@@ -2,11 +2,14 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/ramadhan22/dropship-erp/backend/internal/apperr"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
 )
 
@@ -234,14 +237,57 @@ func (r *ShopeeRepo) ListShopeeAffiliateSales(
 	return list, total, nil
 }
 
-// GetShopeeOrderByID retrieves one settled order by its unique order_id.
-// This is used when reconciling with dropship purchases or calculating revenue.
+// ListShopeeAffiliateSalesFiltered returns affiliate sales using the
+// filtering framework.
+func (r *ShopeeRepo) ListShopeeAffiliateSalesFiltered(
+	ctx context.Context,
+	params *models.FilterParams,
+) (*models.QueryResult, error) {
+	return listFiltered[models.ShopeeAffiliateSale](ctx, r.db, filteredList{
+		base: "SELECT * FROM shopee_affiliate_sales",
+		fields: map[string]string{
+			"nama_toko":                            "nama_toko",
+			"kode_pesanan":                         "kode_pesanan",
+			"status_pesanan":                       "status_pesanan",
+			"status_terverifikasi":                 "status_terverifikasi",
+			"waktu_pesanan":                        "waktu_pesanan",
+			"waktu_pesanan_selesai":                "waktu_pesanan_selesai",
+			"kode_produk":                          "kode_produk",
+			"nama_produk":                          "nama_produk",
+			"nama_affiliate":                       "nama_affiliate",
+			"username_affiliate":                   "username_affiliate",
+			"tipe_pesanan":                         "tipe_pesanan",
+			"nilai_pembelian":                      "nilai_pembelian",
+			"estimasi_komisi_affiliate_per_produk": "estimasi_komisi_affiliate_per_produk",
+			"platform":                             "platform",
+			"store":                                "nama_toko",
+			"order":                                "kode_pesanan",
+			"order_no":                             "kode_pesanan",
+			"status":                               "status_pesanan",
+			"created_at":                           "waktu_pesanan",
+		},
+		keys: []string{"kode_pesanan", "kode_produk", "id_komisi_pesanan"},
+		sort: models.SortCondition{Field: "waktu_pesanan", Direction: "desc"},
+	}, params)
+}
+
+// GetShopeeOrderByID retrieves one settled order by its order number for
+// reconciliation. Settlements are imported into shopee_settled, so it is read
+// first; shopee_settled_orders only holds rows from before that import.
+// Rows without a release date fall back to the order date.
 func (r *ShopeeRepo) GetShopeeOrderByID(ctx context.Context, orderID string) (*models.ShopeeSettledOrder, error) {
 	var o models.ShopeeSettledOrder
 	err := r.db.GetContext(ctx, &o,
-		`SELECT * FROM shopee_settled_orders WHERE order_id = $1`, orderID)
+		`SELECT no_pesanan AS order_id, COALESCE(total_penghasilan, 0) AS net_income,
+                COALESCE(tanggal_dana_dilepaskan, waktu_pesanan_dibuat) AS settled_date,
+                COALESCE(nama_toko, '') AS seller_username
+           FROM shopee_settled WHERE no_pesanan = $1 LIMIT 1`, orderID)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = r.db.GetContext(ctx, &o,
+			`SELECT * FROM shopee_settled_orders WHERE order_id = $1`, orderID)
+	}
 	if err != nil {
-		return nil, err
+		return nil, apperr.NotFoundOr(err, apperr.EntityShopeeOrder, orderID)
 	}
 	return &o, nil
 }
@@ -318,6 +364,38 @@ func (r *ShopeeRepo) ListShopeeSettled(
 	return list, count, nil
 }
 
+// ListShopeeSettledFiltered returns shopee_settled rows using the filtering
+// framework. Channel filters go through the store's jenis_channel.
+func (r *ShopeeRepo) ListShopeeSettledFiltered(
+	ctx context.Context,
+	params *models.FilterParams,
+) (*models.QueryResult, error) {
+	return listFiltered[models.ShopeeSettled](ctx, r.db, filteredList{
+		base: `SELECT s.* FROM shopee_settled s
+        LEFT JOIN stores st ON s.nama_toko = st.nama_toko
+        LEFT JOIN jenis_channels jc ON st.jenis_channel_id = jc.jenis_channel_id`,
+		fields: map[string]string{
+			"nama_toko":               "s.nama_toko",
+			"no_pesanan":              "s.no_pesanan",
+			"username_pembeli":        "s.username_pembeli",
+			"waktu_pesanan_dibuat":    "s.waktu_pesanan_dibuat",
+			"tanggal_dana_dilepaskan": "s.tanggal_dana_dilepaskan",
+			"total_penghasilan":       "s.total_penghasilan",
+			"jasa_kirim":              "s.jasa_kirim",
+			"is_data_mismatch":        "s.is_data_mismatch",
+			"is_settled_confirmed":    "s.is_settled_confirmed",
+			"jenis_channel":           "jc.jenis_channel",
+			"channel":                 "jc.jenis_channel",
+			"store":                   "s.nama_toko",
+			"order":                   "s.no_pesanan",
+			"order_no":                "s.no_pesanan",
+			"created_at":              "s.waktu_pesanan_dibuat",
+		},
+		keys: []string{"s.no_pesanan"},
+		sort: models.SortCondition{Field: "waktu_pesanan_dibuat", Direction: "desc"},
+	}, params)
+}
+
 // SumShopeeSettled returns the aggregated totals for rows matching the provided
 // channel, store and optional date range filters.
 func (r *ShopeeRepo) SumShopeeSettled(
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 )
 
 // tempCleanupShopee deletes any row with the given orderID.
@@ -26,12 +27,12 @@ func TestInsertAndGetShopeeOrder(t *testing.T) {
 	orderID := "TEST-SP-" + time.Now().Format("20060102150405")
 	so := &models.ShopeeSettledOrder{
 		OrderID:         orderID,
-		NetIncome:       20.00,
-		ServiceFee:      1.00,
-		CampaignFee:     0.00,
-		CreditCardFee:   0.20,
-		ShippingSubsidy: 0.00,
-		TaxImportFee:    0.00,
+		NetIncome:       money.New(20),
+		ServiceFee:      money.New(1),
+		CampaignFee:     money.Zero,
+		CreditCardFee:   money.MustParse("0.20"),
+		ShippingSubsidy: money.Zero,
+		TaxImportFee:    money.Zero,
 		SettledDate:     time.Now(),
 		SellerUsername:  "TestShop",
 	}
@@ -52,3 +53,44 @@ func TestInsertAndGetShopeeOrder(t *testing.T) {
 	// Cleanup
 	tempCleanupShopee(t, orderID)
 }
+
+func TestGetShopeeOrderByIDReadsShopeeSettled(t *testing.T) {
+	ctx := context.Background()
+	repo := NewShopeeRepo(testDB)
+
+	orderID := "TEST-SS-" + time.Now().Format("20060102150405")
+	created := time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)
+	ss := &models.ShopeeSettled{
+		NamaToko:              "ShopA",
+		NoPesanan:             orderID,
+		WaktuPesananDibuat:    created,
+		TanggalDanaDilepaskan: created.AddDate(0, 0, 5),
+		TotalPenerimaan:       money.MustParse("87500.50"),
+	}
+	if err := repo.InsertShopeeSettled(ctx, ss); err != nil {
+		t.Fatalf("InsertShopeeSettled failed: %v", err)
+	}
+	defer testDB.ExecContext(ctx, "DELETE FROM shopee_settled WHERE no_pesanan=$1", orderID)
+
+	got, err := repo.GetShopeeOrderByID(ctx, orderID)
+	if err != nil {
+		t.Fatalf("GetShopeeOrderByID failed: %v", err)
+	}
+	if got.OrderID != orderID || got.NetIncome != money.MustParse("87500.50") || got.SellerUsername != "ShopA" ||
+		!got.SettledDate.Equal(time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected order %+v", got)
+	}
+
+	// Imports without a store or release date must still be readable.
+	if _, err := testDB.ExecContext(ctx,
+		"UPDATE shopee_settled SET nama_toko=NULL, tanggal_dana_dilepaskan=NULL WHERE no_pesanan=$1", orderID); err != nil {
+		t.Fatalf("clear columns: %v", err)
+	}
+	got, err = repo.GetShopeeOrderByID(ctx, orderID)
+	if err != nil {
+		t.Fatalf("GetShopeeOrderByID with NULLs failed: %v", err)
+	}
+	if got.SellerUsername != "" || !got.SettledDate.Equal(created) {
+		t.Errorf("unexpected order %+v", got)
+	}
+}
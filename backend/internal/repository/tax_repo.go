@@ -2,9 +2,13 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"time"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 )
 
 // TaxRepo manages tax_payments table.
@@ -23,15 +27,86 @@ func (r *TaxRepo) Get(ctx context.Context, store, periodType, periodValue string
 	return &tp, nil
 }
 
+// GetObligation fetches the row of one tax for a store and period.
+func (r *TaxRepo) GetObligation(ctx context.Context, store, periodType, periodValue, taxType string) (*models.TaxPayment, error) {
+	var tp models.TaxPayment
+	err := r.db.GetContext(ctx, &tp,
+		`SELECT * FROM tax_payments
+          WHERE store=$1 AND period_type=$2 AND period_value=$3 AND tax_type=$4
+          ORDER BY is_paid DESC LIMIT 1`,
+		store, periodType, periodValue, taxType)
+	if err != nil {
+		return nil, err
+	}
+	return &tp, nil
+}
+
+// GetByID fetches a tax payment by ID.
+func (r *TaxRepo) GetByID(ctx context.Context, id string) (*models.TaxPayment, error) {
+	var tp models.TaxPayment
+	if err := r.db.GetContext(ctx, &tp, `SELECT * FROM tax_payments WHERE id=$1`, id); err != nil {
+		return nil, err
+	}
+	return &tp, nil
+}
+
+// Create stores an obligation. When the store already has one for the same
+// period and tax, an unpaid row is refreshed with tp's figures and a paid
+// one is kept; either way tp is left holding the stored row.
 func (r *TaxRepo) Create(ctx context.Context, tp *models.TaxPayment) error {
-	query := `INSERT INTO tax_payments (store, period_type, period_value, revenue, tax_rate, tax_amount, is_paid, paid_at)
-              VALUES (:store,:period_type,:period_value,:revenue,:tax_rate,:tax_amount,:is_paid,:paid_at)
-              RETURNING id`
+	if tp.TaxType == "" {
+		tp.TaxType = models.TaxPPhFinal
+	}
+	if tp.Regime == "" {
+		tp.Regime = models.TaxRegimeUMKM
+	}
+	query := `INSERT INTO tax_payments (store, period_type, period_value, tax_type, regime, revenue,
+                cumulative_revenue, exempt_amount, tax_base, tax_rate, tax_amount, output_tax, input_tax,
+                due_date, is_paid, paid_at)
+              VALUES (:store,:period_type,:period_value,:tax_type,:regime,:revenue,
+                :cumulative_revenue,:exempt_amount,:tax_base,:tax_rate,:tax_amount,:output_tax,:input_tax,
+                :due_date,:is_paid,:paid_at)
+              ON CONFLICT (store, period_type, period_value, tax_type) DO UPDATE SET
+                regime=EXCLUDED.regime, revenue=EXCLUDED.revenue, cumulative_revenue=EXCLUDED.cumulative_revenue,
+                exempt_amount=EXCLUDED.exempt_amount, tax_base=EXCLUDED.tax_base, tax_rate=EXCLUDED.tax_rate,
+                tax_amount=EXCLUDED.tax_amount, output_tax=EXCLUDED.output_tax, input_tax=EXCLUDED.input_tax,
+                due_date=EXCLUDED.due_date
+              WHERE NOT tax_payments.is_paid
+              RETURNING id, output_booked`
 	stmt, args, err := r.db.BindNamed(query, tp)
 	if err != nil {
 		return err
 	}
-	return r.db.QueryRowxContext(ctx, stmt, args...).Scan(&tp.ID)
+	err = r.db.QueryRowxContext(ctx, stmt, args...).Scan(&tp.ID, &tp.OutputBooked)
+	if errors.Is(err, sql.ErrNoRows) {
+		// The conflicting row is paid and was left alone.
+		paid, err := r.GetObligation(ctx, tp.Store, tp.PeriodType, tp.PeriodValue, tp.TaxType)
+		if err != nil {
+			return err
+		}
+		*tp = *paid
+		return nil
+	}
+	return err
+}
+
+// UpdateUnpaid refreshes the computed figures of an obligation that has not
+// been paid yet. Paid rows are left as they were paid.
+func (r *TaxRepo) UpdateUnpaid(ctx context.Context, tp *models.TaxPayment) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE tax_payments SET regime=$2, revenue=$3, cumulative_revenue=$4, exempt_amount=$5,
+                tax_base=$6, tax_rate=$7, tax_amount=$8, output_tax=$9, input_tax=$10, due_date=$11
+          WHERE id=$1 AND NOT is_paid`,
+		tp.ID, tp.Regime, tp.Revenue, tp.CumulativeRevenue, tp.ExemptAmount,
+		tp.TaxBase, tp.TaxRate, tp.TaxAmount, tp.OutputTax, tp.InputTax, tp.DueDate)
+	return err
+}
+
+// SetOutputBooked records how much of an obligation's output VAT has been
+// booked to Utang PPN.
+func (r *TaxRepo) SetOutputBooked(ctx context.Context, id string, amount money.Amount) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE tax_payments SET output_booked=$2 WHERE id=$1`, id, amount)
+	return err
 }
 
 func (r *TaxRepo) MarkPaid(ctx context.Context, id string, paidAt time.Time) error {
@@ -39,3 +114,136 @@ func (r *TaxRepo) MarkPaid(ctx context.Context, id string, paidAt time.Time) err
 		`UPDATE tax_payments SET is_paid=TRUE, paid_at=$2 WHERE id=$1`, id, paidAt)
 	return err
 }
+
+// ListPayments returns obligations ordered by period, optionally for one
+// store, for periods starting with periodPrefix (e.g. "2025") and only
+// unpaid ones.
+func (r *TaxRepo) ListPayments(ctx context.Context, store, periodPrefix string, unpaidOnly bool) ([]models.TaxPayment, error) {
+	var list []models.TaxPayment
+	err := r.db.SelectContext(ctx, &list,
+		`SELECT * FROM tax_payments
+          WHERE ($1 = '' OR store = $1)
+            AND period_value LIKE $2 || '%'
+            AND (NOT $3 OR NOT is_paid)
+          ORDER BY period_value, store, tax_type`,
+		store, periodPrefix, unpaidOnly)
+	if list == nil {
+		list = []models.TaxPayment{}
+	}
+	return list, err
+}
+
+// ListReminders returns unpaid obligations due on or before until that have
+// not been reminded about yet, or that are overdue at now and were last
+// reminded about before they fell due.
+func (r *TaxRepo) ListReminders(ctx context.Context, until, now time.Time) ([]models.TaxPayment, error) {
+	var list []models.TaxPayment
+	err := r.db.SelectContext(ctx, &list,
+		`SELECT * FROM tax_payments
+          WHERE NOT is_paid AND tax_amount > 0 AND due_date IS NOT NULL AND due_date <= $1
+            AND (reminded_at IS NULL OR (due_date < $2::date AND reminded_at < due_date))
+          ORDER BY due_date, store, tax_type`,
+		until, now)
+	if list == nil {
+		list = []models.TaxPayment{}
+	}
+	return list, err
+}
+
+// MarkReminded records when reminders were sent for ids.
+func (r *TaxRepo) MarkReminded(ctx context.Context, ids []string, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	query, args, err := sqlx.In(`UPDATE tax_payments SET reminded_at=? WHERE id IN (?)`, at, ids)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, r.db.Rebind(query), args...)
+	return err
+}
+
+// CreateRegime inserts a regime and fills in its ID and timestamps.
+func (r *TaxRepo) CreateRegime(ctx context.Context, g *models.TaxRegime) error {
+	return r.db.QueryRowxContext(ctx,
+		`INSERT INTO tax_regimes (store, regime, taxpayer_type, pkp, rate, ppn_rate, effective_from, effective_to, notes)
+          VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9) RETURNING id, created_at, updated_at`,
+		g.Store, g.Regime, g.TaxpayerType, g.PKP, g.Rate, g.PPNRate, g.EffectiveFrom, g.EffectiveTo, g.Notes,
+	).Scan(&g.ID, &g.CreatedAt, &g.UpdatedAt)
+}
+
+// UpdateRegime saves a regime.
+func (r *TaxRepo) UpdateRegime(ctx context.Context, g *models.TaxRegime) error {
+	return r.db.QueryRowxContext(ctx,
+		`UPDATE tax_regimes SET store=$2, regime=$3, taxpayer_type=$4, pkp=$5, rate=$6, ppn_rate=$7,
+                effective_from=$8, effective_to=$9, notes=$10, updated_at=NOW()
+          WHERE id=$1 RETURNING created_at, updated_at`,
+		g.ID, g.Store, g.Regime, g.TaxpayerType, g.PKP, g.Rate, g.PPNRate, g.EffectiveFrom, g.EffectiveTo, g.Notes,
+	).Scan(&g.CreatedAt, &g.UpdatedAt)
+}
+
+// DeleteRegime removes a regime.
+func (r *TaxRepo) DeleteRegime(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM tax_regimes WHERE id=$1`, id)
+	return err
+}
+
+// ListRegimes returns the regimes of a store, or of every store when store
+// is empty, oldest first.
+func (r *TaxRepo) ListRegimes(ctx context.Context, store string) ([]models.TaxRegime, error) {
+	var list []models.TaxRegime
+	err := r.db.SelectContext(ctx, &list,
+		`SELECT * FROM tax_regimes WHERE $1 = '' OR store = $1 ORDER BY store, effective_from, id`, store)
+	if list == nil {
+		list = []models.TaxRegime{}
+	}
+	return list, err
+}
+
+// ListTaxStores returns every store that is set up in the ERP or has a
+// tax regime.
+func (r *TaxRepo) ListTaxStores(ctx context.Context) ([]string, error) {
+	var list []string
+	err := r.db.SelectContext(ctx, &list,
+		`SELECT nama_toko FROM stores WHERE nama_toko <> ''
+         UNION
+         SELECT store FROM tax_regimes
+         ORDER BY 1`)
+	return list, err
+}
+
+// NetProfit returns revenue less expenses booked to store between from and
+// to inclusive, which is credits less debits over both account types.
+// Income tax accounts (5.4) are left out so the tax is not computed on
+// itself, and so is the PPN contra revenue account (4.4): the service takes
+// the month's output VAT off itself, whether or not it has been booked yet.
+func (r *TaxRepo) NetProfit(ctx context.Context, store string, from, to time.Time) (money.Amount, error) {
+	var profit money.Amount
+	err := r.db.GetContext(ctx, &profit,
+		`SELECT COALESCE(SUM(d.credit_total - d.debit_total), 0)
+           FROM account_balance_daily d
+           JOIN accounts a ON a.account_id = d.account_id
+          WHERE d.shop_username = $1
+            AND d.balance_date BETWEEN $2 AND $3
+            AND a.account_type IN ('Revenue', 'Expense')
+            AND a.account_code NOT LIKE '5.4%' AND a.account_code <> '4.4'`,
+		store, from, to)
+	return profit, err
+}
+
+// InputVAT returns the input VAT (PPN Masukan, 1.1.16) booked to store
+// between from and to inclusive. Tax payments that set it off are left out.
+func (r *TaxRepo) InputVAT(ctx context.Context, store string, from, to time.Time) (money.Amount, error) {
+	var vat money.Amount
+	err := r.db.GetContext(ctx, &vat,
+		`SELECT COALESCE(SUM(CASE WHEN jl.is_debit THEN jl.amount ELSE -jl.amount END), 0)
+           FROM journal_lines jl
+           JOIN journal_entries je ON je.journal_id = jl.journal_id
+           JOIN accounts a ON a.account_id = jl.account_id
+          WHERE je.shop_username = $1
+            AND je.entry_date >= $2 AND je.entry_date < $3::date + 1
+            AND a.account_code = '1.1.16'
+            AND je.source_type <> 'tax_payment'`,
+		store, from, to)
+	return vat, err
+}
@@ -0,0 +1,42 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	base := time.Date(2025, 1, 31, 10, 17, 42, 0, time.UTC) // Friday
+	cases := []struct {
+		spec string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2025, 1, 31, 10, 30, 0, 0, time.UTC)},
+		{"0 7 * * *", time.Date(2025, 2, 1, 7, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 8 * * 1-5", time.Date(2025, 2, 3, 8, 0, 0, 0, time.UTC)},
+		{"30 6 1,15 * *", time.Date(2025, 2, 1, 6, 30, 0, 0, time.UTC)},
+		{"0 9 * * 7", time.Date(2025, 2, 2, 9, 0, 0, 0, time.UTC)},
+		{"0 0 31 * *", time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		got := MustParse(c.spec).Next(base)
+		if !got.Equal(c.want) {
+			t.Errorf("%s: Next = %v, want %v", c.spec, got, c.want)
+		}
+	}
+}
+
+func TestNextNeverMatches(t *testing.T) {
+	if got := MustParse("0 0 30 2 *").Next(time.Now()); !got.IsZero() {
+		t.Errorf("expected zero time, got %v", got)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* * * * 8", "*/0 * * * *", "a * * * *", "5-1 * * * *"} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("expected error for %q", spec)
+		}
+	}
+}
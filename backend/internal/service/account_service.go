@@ -2,7 +2,6 @@ package service
 
 import (
 	"context"
-	"log"
 
 	"github.com/ramadhan22/dropship-erp/backend/internal/logutil"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
@@ -28,13 +27,17 @@ func NewAccountService(r AccountRepoInterface) *AccountService {
 }
 
 func (s *AccountService) CreateAccount(ctx context.Context, a *models.Account) (int64, error) {
-	log.Printf("CreateAccount: %s", a.AccountCode)
+	logutil.Debug(ctx, "CreateAccount", "Creating account", map[string]interface{}{
+		"account_code": a.AccountCode,
+	})
 	id, err := s.repo.CreateAccount(ctx, a)
 	if err != nil {
 		logutil.Errorf("CreateAccount error: %v", err)
 		return 0, err
 	}
-	log.Printf("CreateAccount done: %d", id)
+	logutil.Debug(ctx, "CreateAccount", "Account created", map[string]interface{}{
+		"account_id": id,
+	})
 	return id, nil
 }
 
@@ -47,7 +50,9 @@ func (s *AccountService) ListAccounts(ctx context.Context) ([]models.Account, er
 }
 
 func (s *AccountService) UpdateAccount(ctx context.Context, a *models.Account) error {
-	log.Printf("UpdateAccount: %d", a.AccountID)
+	logutil.Debug(ctx, "UpdateAccount", "Updating account", map[string]interface{}{
+		"account_id": a.AccountID,
+	})
 	err := s.repo.UpdateAccount(ctx, a)
 	if err != nil {
 		logutil.Errorf("UpdateAccount error: %v", err)
@@ -56,7 +61,9 @@ func (s *AccountService) UpdateAccount(ctx context.Context, a *models.Account) e
 }
 
 func (s *AccountService) DeleteAccount(ctx context.Context, id int64) error {
-	log.Printf("DeleteAccount: %d", id)
+	logutil.Debug(ctx, "DeleteAccount", "Deleting account", map[string]interface{}{
+		"account_id": id,
+	})
 	err := s.repo.DeleteAccount(ctx, id)
 	if err != nil {
 		logutil.Errorf("DeleteAccount error: %v", err)
@@ -13,6 +13,7 @@ import (
 
 	"github.com/jmoiron/sqlx"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
 )
 
@@ -21,6 +22,7 @@ type AdInvoiceService struct {
 	db          *sqlx.DB
 	repo        *repository.AdInvoiceRepo
 	journalRepo *repository.JournalRepo
+	cache       Cache
 }
 
 var amountRe = regexp.MustCompile(`-?[0-9][0-9.,]*`)
@@ -29,6 +31,10 @@ func NewAdInvoiceService(db *sqlx.DB, r *repository.AdInvoiceRepo, jr *repositor
 	return &AdInvoiceService{db: db, repo: r, journalRepo: jr}
 }
 
+// SetCache enables invalidation of cached reports after invoice journals
+// are posted.
+func (s *AdInvoiceService) SetCache(c Cache) { s.cache = c }
+
 func formatStoreName(username string) string {
 	u := strings.ToLower(strings.TrimSpace(username))
 	if u == "" {
@@ -213,13 +219,15 @@ func (s *AdInvoiceService) ImportInvoicePDF(ctx context.Context, r io.Reader) er
 			return err
 		}
 		lines := []models.JournalLine{
-			{JournalID: jid, AccountID: 55003, IsDebit: true, Amount: inv.Total, Memo: strPtr("Biaya Iklan " + inv.InvoiceNo)},
-			{JournalID: jid, AccountID: adsSaldoShopeeAccountID(inv.Store), IsDebit: false, Amount: inv.Total, Memo: strPtr("Pembayaran Iklan " + inv.InvoiceNo)},
+			{JournalID: jid, AccountID: 55003, IsDebit: true, Amount: money.FromFloat(inv.Total), Memo: strPtr("Biaya Iklan " + inv.InvoiceNo)},
+			{JournalID: jid, AccountID: adsSaldoShopeeAccountID(inv.Store), IsDebit: false, Amount: money.FromFloat(inv.Total),
+				Memo: strPtr("Pembayaran Iklan " + inv.InvoiceNo)},
 		}
 		// Use bulk insert for lines
 		if err := s.journalRepo.InsertJournalLines(ctx, lines); err != nil {
 			return err
 		}
+		invalidateCache(ctx, s.cache, journalWriteTags...)
 	}
 	return nil
 }
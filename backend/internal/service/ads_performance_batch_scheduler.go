@@ -3,7 +3,6 @@ package service
 import (
 	"context"
 	"encoding/json"
-	"log"
 	"time"
 
 	"github.com/ramadhan22/dropship-erp/backend/internal/logutil"
@@ -15,6 +14,7 @@ type AdsPerformanceBatchScheduler struct {
 	batch    *BatchService
 	svc      *AdsPerformanceService
 	interval time.Duration
+	tickerLoop
 }
 
 // NewAdsPerformanceBatchScheduler creates a scheduler with the given interval.
@@ -30,18 +30,7 @@ func (s *AdsPerformanceBatchScheduler) Start(ctx context.Context) {
 	if s == nil {
 		return
 	}
-	go func() {
-		ticker := time.NewTicker(s.interval)
-		defer ticker.Stop()
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case <-ticker.C:
-				s.run(ctx)
-			}
-		}
-	}()
+	s.tickerLoop.run(ctx, s.interval, s.run)
 }
 
 func (s *AdsPerformanceBatchScheduler) run(ctx context.Context) {
@@ -52,16 +41,25 @@ func (s *AdsPerformanceBatchScheduler) run(ctx context.Context) {
 	}
 
 	if len(list) > 0 {
-		log.Printf("ads performance scheduler found %d pending batches to process", len(list))
+		logutil.Info(ctx, "AdsPerformanceBatchScheduler", "Found pending ads performance sync batches", map[string]interface{}{
+			"batch_count": len(list),
+		})
 	}
 
 	for _, b := range list {
+		if s.stopping() {
+			break
+		}
 		s.processBatch(ctx, b)
 	}
 }
 
 func (s *AdsPerformanceBatchScheduler) processBatch(ctx context.Context, batch models.BatchHistory) {
-	log.Printf("Starting to process ads performance sync batch %d", batch.ID)
+	ctx, span := startBatchSpan(ctx, batch.ProcessType, batch.ID)
+	defer span.End()
+	logutil.Info(ctx, "ProcessAdsPerformanceBatch", "Starting ads performance sync batch", map[string]interface{}{
+		"batch_id": batch.ID,
+	})
 
 	// Update batch status to processing
 	err := s.batch.UpdateStatus(ctx, batch.ID, "processing", "Starting ads performance sync")
@@ -89,7 +87,10 @@ func (s *AdsPerformanceBatchScheduler) processBatch(ctx context.Context, batch m
 		return
 	}
 
-	log.Printf("Processing ads performance sync batch %d for store %d", batch.ID, syncRequest.StoreID)
+	logutil.Info(ctx, "ProcessAdsPerformanceBatch", "Syncing historical ads performance", map[string]interface{}{
+		"batch_id": batch.ID,
+		"store_id": syncRequest.StoreID,
+	})
 
 	// Perform the historical sync
 	err = s.svc.SyncHistoricalAdsPerformance(ctx, syncRequest.StoreID)
@@ -105,12 +106,17 @@ func (s *AdsPerformanceBatchScheduler) processBatch(ctx context.Context, batch m
 		logutil.Errorf("Failed to update batch completion status for batch %d: %v", batch.ID, err)
 	}
 
-	log.Printf("Successfully completed ads performance sync batch %d for store %d", batch.ID, syncRequest.StoreID)
+	logutil.Info(ctx, "ProcessAdsPerformanceBatch", "Ads performance sync batch completed", map[string]interface{}{
+		"batch_id": batch.ID,
+		"store_id": syncRequest.StoreID,
+	})
 }
 
 // CreateSyncBatch creates a new batch for historical ads performance sync
 func (s *AdsPerformanceBatchScheduler) CreateSyncBatch(ctx context.Context, storeID int) (int64, error) {
-	log.Printf("Creating ads performance sync batch for store %d", storeID)
+	logutil.Debug(ctx, "CreateSyncBatch", "Creating ads performance sync batch", map[string]interface{}{
+		"store_id": storeID,
+	})
 
 	syncRequest := struct {
 		StoreID int `json:"store_id"`
@@ -138,6 +144,9 @@ func (s *AdsPerformanceBatchScheduler) CreateSyncBatch(ctx context.Context, stor
 		return 0, err
 	}
 
-	log.Printf("Successfully created ads performance sync batch %d for store %d", batchID, storeID)
+	logutil.Info(ctx, "CreateSyncBatch", "Created ads performance sync batch", map[string]interface{}{
+		"batch_id": batchID,
+		"store_id": storeID,
+	})
 	return batchID, nil
 }
@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/url"
 	"strconv"
 	"strings"
@@ -17,6 +16,8 @@ import (
 	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
 )
 
+var adsLog = logutil.NewLogger("ads-performance-service", logutil.INFO)
+
 // AdsPerformanceService handles ads campaign and performance metrics
 type AdsPerformanceService struct {
 	db           *sqlx.DB
@@ -157,7 +158,9 @@ type ShopeeAdsPerformanceResponse struct {
 
 // FetchAdsCampaigns retrieves ads campaigns from Shopee Marketing API for a specific store
 func (s *AdsPerformanceService) FetchAdsCampaigns(ctx context.Context, storeID int) error {
-	log.Printf("Starting to fetch ads campaigns for store %d", storeID)
+	adsLog.Info(ctx, "FetchAdsCampaigns", "Fetching ads campaigns", map[string]interface{}{
+		"store_id": storeID,
+	})
 
 	// Get store details and validate credentials
 	store, err := s.repo.ChannelRepo.GetStoreByID(ctx, int64(storeID))
@@ -171,7 +174,10 @@ func (s *AdsPerformanceService) FetchAdsCampaigns(ctx context.Context, storeID i
 		return fmt.Errorf("store %d does not have shop_id or access_token configured", storeID)
 	}
 
-	log.Printf("Store %d credentials validated - shop_id: %s", storeID, *store.ShopID)
+	adsLog.Debug(ctx, "FetchAdsCampaigns", "Store credentials validated", map[string]interface{}{
+		"store_id": storeID,
+		"shop_id":  *store.ShopID,
+	})
 
 	// Validate token before making API calls
 	if err := s.ensureStoreTokenValid(ctx, store); err != nil {
@@ -190,7 +196,10 @@ func (s *AdsPerformanceService) FetchAdsCampaigns(ctx context.Context, storeID i
 
 	// Fetch campaigns with pagination
 	for {
-		log.Printf("Fetching campaigns page %d for store %d", pageNo, storeID)
+		adsLog.Debug(ctx, "FetchAdsCampaigns", "Fetching campaigns page", map[string]interface{}{
+			"store_id": storeID,
+			"page":     pageNo,
+		})
 
 		// Build API request
 		path := "/api/v2/ads/get_product_level_campaign_id_list"
@@ -208,7 +217,10 @@ func (s *AdsPerformanceService) FetchAdsCampaigns(ctx context.Context, storeID i
 		params.Set("page_size", strconv.Itoa(pageSize))
 
 		apiURL := s.shopeeClient.BaseURL + path + "?" + params.Encode()
-		log.Printf("Making API request to Shopee for store %d: %s", storeID, path)
+		adsLog.Debug(ctx, "FetchAdsCampaigns", "Requesting campaign list", map[string]interface{}{
+			"store_id": storeID,
+			"path":     path,
+		})
 
 		// Make API request
 		resp, err := s.shopeeClient.makeRequestWithRetry(ctx, "GET", apiURL, nil, nil)
@@ -229,7 +241,11 @@ func (s *AdsPerformanceService) FetchAdsCampaigns(ctx context.Context, storeID i
 			return fmt.Errorf("Shopee API error: %s - %s", campaignsResp.Error, campaignsResp.Message)
 		}
 
-		log.Printf("Successfully received %d campaign objects from Shopee API for store %d on page %d", len(campaignsResp.Response.CampaignList), storeID, pageNo)
+		adsLog.Debug(ctx, "FetchAdsCampaigns", "Campaign page received", map[string]interface{}{
+			"store_id":  storeID,
+			"page":      pageNo,
+			"campaigns": len(campaignsResp.Response.CampaignList),
+		})
 
 		// Store campaign IDs in database (with minimal campaign data) and collect IDs for settings fetch
 		successCount := 0
@@ -260,23 +276,37 @@ func (s *AdsPerformanceService) FetchAdsCampaigns(ctx context.Context, storeID i
 		totalCampaigns += len(campaignsResp.Response.CampaignList)
 		totalSuccessCount += successCount
 
-		log.Printf("Successfully processed %d/%d campaigns from page %d for store %d", successCount, len(campaignsResp.Response.CampaignList), pageNo, storeID)
+		adsLog.Debug(ctx, "FetchAdsCampaigns", "Campaign page stored", map[string]interface{}{
+			"store_id":  storeID,
+			"page":      pageNo,
+			"stored":    successCount,
+			"campaigns": len(campaignsResp.Response.CampaignList),
+		})
 
 		// Fetch detailed campaign settings for the campaigns we just stored
 		if len(campaignIDsForSettings) > 0 {
-			log.Printf("Fetching detailed settings for %d campaigns from page %d for store %d", len(campaignIDsForSettings), pageNo, storeID)
+			adsLog.Debug(ctx, "FetchAdsCampaigns", "Fetching campaign settings for page", map[string]interface{}{
+				"store_id":  storeID,
+				"page":      pageNo,
+				"campaigns": len(campaignIDsForSettings),
+			})
 			err := s.FetchAdsCampaignSettings(ctx, storeID, campaignIDsForSettings)
 			if err != nil {
 				logutil.Errorf("Failed to fetch campaign settings for page %d, store %d: %v", pageNo, storeID, err)
 				// Don't fail the entire operation, just log the error
 			} else {
-				log.Printf("Successfully fetched campaign settings for page %d, store %d", pageNo, storeID)
+				adsLog.Debug(ctx, "FetchAdsCampaigns", "Campaign settings fetched for page", map[string]interface{}{
+					"store_id": storeID,
+					"page":     pageNo,
+				})
 			}
 		}
 
 		// Check if there are more pages
 		if !campaignsResp.Response.HasNextPage {
-			log.Printf("No more pages available for store %d", storeID)
+			adsLog.Debug(ctx, "FetchAdsCampaigns", "No more campaign pages", map[string]interface{}{
+				"store_id": storeID,
+			})
 			break
 		}
 
@@ -286,18 +316,27 @@ func (s *AdsPerformanceService) FetchAdsCampaigns(ctx context.Context, storeID i
 		time.Sleep(100 * time.Millisecond)
 	}
 
-	log.Printf("Successfully fetched and stored %d/%d campaigns across all pages for store %d", totalSuccessCount, totalCampaigns, storeID)
+	adsLog.Info(ctx, "FetchAdsCampaigns", "Ads campaigns fetched", map[string]interface{}{
+		"store_id":  storeID,
+		"stored":    totalSuccessCount,
+		"campaigns": totalCampaigns,
+	})
 	return nil
 }
 
 // FetchAdsCampaignSettings retrieves detailed campaign settings from Shopee Marketing API
 func (s *AdsPerformanceService) FetchAdsCampaignSettings(ctx context.Context, storeID int, campaignIDs []int64) error {
 	if len(campaignIDs) == 0 {
-		log.Printf("No campaign IDs provided for fetching settings for store %d", storeID)
+		adsLog.Debug(ctx, "FetchAdsCampaignSettings", "No campaign IDs to fetch settings for", map[string]interface{}{
+			"store_id": storeID,
+		})
 		return nil
 	}
 
-	log.Printf("Starting to fetch campaign settings for %d campaigns, store %d", len(campaignIDs), storeID)
+	adsLog.Debug(ctx, "FetchAdsCampaignSettings", "Fetching campaign settings", map[string]interface{}{
+		"store_id":  storeID,
+		"campaigns": len(campaignIDs),
+	})
 
 	// Get store details and validate credentials
 	store, err := s.repo.ChannelRepo.GetStoreByID(ctx, int64(storeID))
@@ -330,7 +369,11 @@ func (s *AdsPerformanceService) FetchAdsCampaignSettings(ctx context.Context, st
 		}
 		batch := campaignIDs[i:end]
 
-		log.Printf("Fetching campaign settings batch %d-%d for store %d", i+1, end, storeID)
+		adsLog.Debug(ctx, "FetchAdsCampaignSettings", "Fetching campaign settings batch", map[string]interface{}{
+			"store_id": storeID,
+			"from":     i + 1,
+			"to":       end,
+		})
 
 		err := s.fetchCampaignSettingsBatch(ctx, storeID, batch, store)
 		if err != nil {
@@ -343,7 +386,9 @@ func (s *AdsPerformanceService) FetchAdsCampaignSettings(ctx context.Context, st
 		time.Sleep(100 * time.Millisecond)
 	}
 
-	log.Printf("Successfully completed fetching campaign settings for store %d", storeID)
+	adsLog.Debug(ctx, "FetchAdsCampaignSettings", "Campaign settings fetched", map[string]interface{}{
+		"store_id": storeID,
+	})
 	return nil
 }
 
@@ -371,7 +416,10 @@ func (s *AdsPerformanceService) fetchCampaignSettingsBatch(ctx context.Context,
 	params.Set("campaign_id_list", campaignIDList)
 
 	apiURL := s.shopeeClient.BaseURL + path + "?" + params.Encode()
-	log.Printf("Making API request to Shopee for campaign settings - store %d: %s", storeID, path)
+	adsLog.Debug(ctx, "fetchCampaignSettingsBatch", "Requesting campaign settings", map[string]interface{}{
+		"store_id": storeID,
+		"path":     path,
+	})
 
 	// Make API request
 	resp, err := s.shopeeClient.makeRequestWithRetry(ctx, "GET", apiURL, nil, nil)
@@ -392,7 +440,10 @@ func (s *AdsPerformanceService) fetchCampaignSettingsBatch(ctx context.Context,
 		return fmt.Errorf("Shopee API error: %s - %s", settingsResp.Error, settingsResp.Message)
 	}
 
-	log.Printf("Successfully received %d campaign settings from Shopee API for store %d", len(settingsResp.Response.CampaignList), storeID)
+	adsLog.Debug(ctx, "fetchCampaignSettingsBatch", "Campaign settings received", map[string]interface{}{
+		"store_id":  storeID,
+		"campaigns": len(settingsResp.Response.CampaignList),
+	})
 
 	// Update campaign data in database
 	successCount := 0
@@ -405,7 +456,11 @@ func (s *AdsPerformanceService) fetchCampaignSettingsBatch(ctx context.Context,
 		successCount++
 	}
 
-	log.Printf("Successfully updated %d/%d campaigns with settings for store %d", successCount, len(settingsResp.Response.CampaignList), storeID)
+	adsLog.Debug(ctx, "fetchCampaignSettingsBatch", "Campaign settings stored", map[string]interface{}{
+		"store_id":  storeID,
+		"stored":    successCount,
+		"campaigns": len(settingsResp.Response.CampaignList),
+	})
 	return nil
 }
 
@@ -467,8 +522,12 @@ func (s *AdsPerformanceService) updateCampaignWithSettings(ctx context.Context,
 	return s.upsertCampaignWithSettings(ctx, storeID, &campaignData)
 }
 func (s *AdsPerformanceService) FetchAdsPerformance(ctx context.Context, storeID int, campaignID int64, startDate, endDate time.Time) error {
-	log.Printf("Starting to fetch ads performance for campaign %d, store %d, from %s to %s",
-		campaignID, storeID, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+	adsLog.Debug(ctx, "FetchAdsPerformance", "Fetching ads performance", map[string]interface{}{
+		"store_id":    storeID,
+		"campaign_id": campaignID,
+		"start_date":  startDate.Format("2006-01-02"),
+		"end_date":    endDate.Format("2006-01-02"),
+	})
 
 	// Get store details and validate credentials
 	store, err := s.repo.ChannelRepo.GetStoreByID(ctx, int64(storeID))
@@ -492,7 +551,10 @@ func (s *AdsPerformanceService) FetchAdsPerformance(ctx context.Context, storeID
 	currentDate := startDate
 
 	for currentDate.Before(endDate) || currentDate.Equal(endDate) {
-		log.Printf("Fetching performance data for campaign %d on date %s", campaignID, currentDate.Format("2006-01-02"))
+		adsLog.Debug(ctx, "FetchAdsPerformance", "Fetching performance for date", map[string]interface{}{
+			"campaign_id": campaignID,
+			"date":        currentDate.Format("2006-01-02"),
+		})
 
 		dataPoints, err := s.fetchAdsPerformanceForDate(ctx, storeID, campaignID, currentDate, store)
 		if err != nil {
@@ -513,8 +575,12 @@ func (s *AdsPerformanceService) FetchAdsPerformance(ctx context.Context, storeID
 		time.Sleep(100 * time.Millisecond)
 	}
 
-	log.Printf("Successfully fetched and stored %d/%d performance data points for campaign %d, store %d",
-		successCount, totalDataPoints, campaignID, storeID)
+	adsLog.Info(ctx, "FetchAdsPerformance", "Ads performance fetched", map[string]interface{}{
+		"store_id":    storeID,
+		"campaign_id": campaignID,
+		"stored":      successCount,
+		"data_points": totalDataPoints,
+	})
 	return nil
 }
 
@@ -535,8 +601,12 @@ func (s *AdsPerformanceService) fetchAdsPerformanceForDate(ctx context.Context,
 	params.Set("performance_date", date.Format("02-01-2006")) // DD-MM-YYYY format as per Shopee API
 
 	apiURL := s.shopeeClient.BaseURL + path + "?" + params.Encode()
-	log.Printf("Making API request to Shopee for performance data - campaign %d, store %d, date %s: %s",
-		campaignID, storeID, date.Format("2006-01-02"), path)
+	adsLog.Debug(ctx, "fetchAdsPerformanceForDate", "Requesting performance data", map[string]interface{}{
+		"store_id":    storeID,
+		"campaign_id": campaignID,
+		"date":        date.Format("2006-01-02"),
+		"path":        path,
+	})
 
 	// Make API request
 	resp, err := s.shopeeClient.makeRequestWithRetry(ctx, "GET", apiURL, nil, nil)
@@ -611,8 +681,13 @@ func (s *AdsPerformanceService) fetchAdsPerformanceForDate(ctx context.Context,
 		}
 	}
 
-	log.Printf("Processed %d/%d performance data points for campaign %d, store %d, date %s",
-		successCount, totalDataPoints, campaignID, storeID, date.Format("2006-01-02"))
+	adsLog.Debug(ctx, "fetchAdsPerformanceForDate", "Performance data stored", map[string]interface{}{
+		"store_id":    storeID,
+		"campaign_id": campaignID,
+		"date":        date.Format("2006-01-02"),
+		"stored":      successCount,
+		"data_points": totalDataPoints,
+	})
 	return successCount, nil
 }
 
@@ -1078,10 +1153,14 @@ func (s *AdsPerformanceService) GetPerformanceSummary(ctx context.Context, store
 
 // SyncHistoricalAdsPerformance syncs all historical ads performance data in background
 func (s *AdsPerformanceService) SyncHistoricalAdsPerformance(ctx context.Context, storeID int) error {
-	log.Printf("Starting historical ads performance sync for store %d", storeID)
+	adsLog.Info(ctx, "SyncHistoricalAdsPerformance", "Starting historical ads performance sync", map[string]interface{}{
+		"store_id": storeID,
+	})
 
 	// First, fetch campaigns from Shopee API to ensure we have the latest campaign data
-	log.Printf("Fetching campaigns from Shopee API for store %d", storeID)
+	adsLog.Debug(ctx, "SyncHistoricalAdsPerformance", "Fetching campaigns", map[string]interface{}{
+		"store_id": storeID,
+	})
 	err := s.FetchAdsCampaigns(ctx, storeID)
 	if err != nil {
 		logutil.Errorf("Failed to fetch campaigns from Shopee API for store %d: %v", storeID, err)
@@ -1100,7 +1179,10 @@ func (s *AdsPerformanceService) SyncHistoricalAdsPerformance(ctx context.Context
 		return fmt.Errorf("no campaigns found for store %d after fetching from Shopee API", storeID)
 	}
 
-	log.Printf("Found %d campaigns for store %d", len(campaigns), storeID)
+	adsLog.Info(ctx, "SyncHistoricalAdsPerformance", "Campaigns loaded", map[string]interface{}{
+		"store_id":  storeID,
+		"campaigns": len(campaigns),
+	})
 
 	// Use the optimized batch sync method
 	return s.SyncAdsPerformanceBatch(ctx, storeID, campaigns)
@@ -1108,7 +1190,10 @@ func (s *AdsPerformanceService) SyncHistoricalAdsPerformance(ctx context.Context
 
 // SyncAdsPerformanceBatch syncs performance data for multiple campaigns efficiently
 func (s *AdsPerformanceService) SyncAdsPerformanceBatch(ctx context.Context, storeID int, campaigns []models.AdsCampaignWithMetrics) error {
-	log.Printf("Starting batch sync for %d campaigns in store %d", len(campaigns), storeID)
+	adsLog.Debug(ctx, "SyncAdsPerformanceBatch", "Starting batch sync", map[string]interface{}{
+		"store_id":  storeID,
+		"campaigns": len(campaigns),
+	})
 
 	// Split campaigns into batches of 50 for optimal API performance
 	const batchSize = 50
@@ -1122,21 +1207,35 @@ func (s *AdsPerformanceService) SyncAdsPerformanceBatch(ctx context.Context, sto
 		batches = append(batches, campaigns[i:end])
 	}
 
-	log.Printf("Split %d campaigns into %d batches of max %d campaigns each for store %d", len(campaigns), len(batches), batchSize, storeID)
+	adsLog.Debug(ctx, "SyncAdsPerformanceBatch", "Campaigns split into batches", map[string]interface{}{
+		"store_id":   storeID,
+		"campaigns":  len(campaigns),
+		"batches":    len(batches),
+		"batch_size": batchSize,
+	})
 
 	// Process each batch with date range optimization
 	currentDate := time.Now().Truncate(24 * time.Hour)
 	consecutiveEmptyDays := 0
 	maxConsecutiveEmptyDays := 3 // Increased from 2 to 3 for better coverage
 
-	log.Printf("Starting batch sync for store %d from date %s, will stop after %d consecutive empty days", storeID, currentDate.Format("2006-01-02"), maxConsecutiveEmptyDays)
+	adsLog.Debug(ctx, "SyncAdsPerformanceBatch", "Walking back from date", map[string]interface{}{
+		"store_id":       storeID,
+		"from_date":      currentDate.Format("2006-01-02"),
+		"max_empty_days": maxConsecutiveEmptyDays,
+	})
 
 	for consecutiveEmptyDays < maxConsecutiveEmptyDays {
 		dayHasData := false
 
 		// Process each batch for the current date
 		for batchIndex, batch := range batches {
-			log.Printf("Processing batch %d/%d for store %d on date %s", batchIndex+1, len(batches), storeID, currentDate.Format("2006-01-02"))
+			adsLog.Debug(ctx, "SyncAdsPerformanceBatch", "Processing batch", map[string]interface{}{
+				"store_id": storeID,
+				"date":     currentDate.Format("2006-01-02"),
+				"batch":    batchIndex + 1,
+				"batches":  len(batches),
+			})
 
 			batchHasData, err := s.syncBatchForDateOptimized(ctx, storeID, batch, currentDate)
 			if err != nil {
@@ -1152,17 +1251,27 @@ func (s *AdsPerformanceService) SyncAdsPerformanceBatch(ctx context.Context, sto
 
 		if dayHasData {
 			consecutiveEmptyDays = 0
-			log.Printf("Successfully processed data for store %d on date %s", storeID, currentDate.Format("2006-01-02"))
+			adsLog.Debug(ctx, "SyncAdsPerformanceBatch", "Date synced", map[string]interface{}{
+				"store_id": storeID,
+				"date":     currentDate.Format("2006-01-02"),
+			})
 		} else {
 			consecutiveEmptyDays++
-			log.Printf("No data found for store %d on date %s, consecutive empty days: %d", storeID, currentDate.Format("2006-01-02"), consecutiveEmptyDays)
+			adsLog.Debug(ctx, "SyncAdsPerformanceBatch", "No data for date", map[string]interface{}{
+				"store_id":   storeID,
+				"date":       currentDate.Format("2006-01-02"),
+				"empty_days": consecutiveEmptyDays,
+			})
 		}
 
 		// Move to previous day
 		currentDate = currentDate.AddDate(0, 0, -1)
 	}
 
-	log.Printf("Batch sync completed for store %d. Stopped after %d consecutive empty days", storeID, consecutiveEmptyDays)
+	adsLog.Info(ctx, "SyncAdsPerformanceBatch", "Batch sync completed", map[string]interface{}{
+		"store_id":   storeID,
+		"empty_days": consecutiveEmptyDays,
+	})
 	return nil
 }
 
@@ -1170,7 +1279,11 @@ func (s *AdsPerformanceService) SyncAdsPerformanceBatch(ctx context.Context, sto
 func (s *AdsPerformanceService) syncBatchForDateOptimized(ctx context.Context, storeID int, campaigns []models.AdsCampaignWithMetrics, date time.Time) (bool, error) {
 	anyDataFound := false
 	campaignCount := len(campaigns)
-	log.Printf("Syncing optimized batch of %d campaigns for store %d on date %s", campaignCount, storeID, date.Format("2006-01-02"))
+	adsLog.Debug(ctx, "syncBatchForDateOptimized", "Syncing batch", map[string]interface{}{
+		"store_id":  storeID,
+		"date":      date.Format("2006-01-02"),
+		"campaigns": campaignCount,
+	})
 
 	// Check if we already have data for this date for any campaign (optimization)
 	existingDataCount := 0
@@ -1187,7 +1300,12 @@ func (s *AdsPerformanceService) syncBatchForDateOptimized(ctx context.Context, s
 
 	// If we have data for most campaigns, skip this date (optimization)
 	if existingDataCount > campaignCount/2 {
-		log.Printf("Skipping date %s for store %d - already have data for %d/%d campaigns", date.Format("2006-01-02"), storeID, existingDataCount, campaignCount)
+		adsLog.Debug(ctx, "syncBatchForDateOptimized", "Date already synced, skipping", map[string]interface{}{
+			"store_id":  storeID,
+			"date":      date.Format("2006-01-02"),
+			"existing":  existingDataCount,
+			"campaigns": campaignCount,
+		})
 		return existingDataCount > 0, nil
 	}
 
@@ -1214,7 +1332,11 @@ func (s *AdsPerformanceService) syncBatchForDateOptimized(ctx context.Context, s
 		time.Sleep(50 * time.Millisecond)
 	}
 
-	log.Printf("Completed optimized batch sync for store %d on date %s - data found: %v", storeID, date.Format("2006-01-02"), anyDataFound)
+	adsLog.Debug(ctx, "syncBatchForDateOptimized", "Batch synced", map[string]interface{}{
+		"store_id":   storeID,
+		"date":       date.Format("2006-01-02"),
+		"data_found": anyDataFound,
+	})
 	return anyDataFound, nil
 }
 
@@ -1262,10 +1384,21 @@ func (s *AdsPerformanceService) syncCampaignGroupForDate(ctx context.Context, st
 func (s *AdsPerformanceService) syncBatchForDate(ctx context.Context, storeID int, campaigns []models.AdsCampaignWithMetrics, date time.Time) (bool, error) {
 	anyDataFound := false
 	campaignCount := len(campaigns)
-	log.Printf("Syncing batch of %d campaigns for store %d on date %s", campaignCount, storeID, date.Format("2006-01-02"))
+	adsLog.Debug(ctx, "syncBatchForDate", "Syncing batch", map[string]interface{}{
+		"store_id":  storeID,
+		"date":      date.Format("2006-01-02"),
+		"campaigns": campaignCount,
+	})
 
 	for i, campaign := range campaigns {
-		log.Printf("Fetching performance for campaign %d (%s) [%d/%d] for store %d on date %s", campaign.CampaignID, campaign.CampaignName, i+1, campaignCount, storeID, date.Format("2006-01-02"))
+		adsLog.Debug(ctx, "syncBatchForDate", "Fetching campaign performance", map[string]interface{}{
+			"store_id":    storeID,
+			"date":        date.Format("2006-01-02"),
+			"campaign_id": campaign.CampaignID,
+			"campaign":    campaign.CampaignName,
+			"index":       i + 1,
+			"campaigns":   campaignCount,
+		})
 
 		err := s.FetchAdsPerformance(ctx, storeID, campaign.CampaignID, date, date)
 		if err != nil {
@@ -1283,16 +1416,28 @@ func (s *AdsPerformanceService) syncBatchForDate(ctx context.Context, storeID in
 
 		if hasData {
 			anyDataFound = true
-			log.Printf("Successfully fetched performance data for campaign %d (%s) on date %s", campaign.CampaignID, campaign.CampaignName, date.Format("2006-01-02"))
+			adsLog.Debug(ctx, "syncBatchForDate", "Campaign performance fetched", map[string]interface{}{
+				"date":        date.Format("2006-01-02"),
+				"campaign_id": campaign.CampaignID,
+				"campaign":    campaign.CampaignName,
+			})
 		} else {
-			log.Printf("No performance data available for campaign %d (%s) on date %s", campaign.CampaignID, campaign.CampaignName, date.Format("2006-01-02"))
+			adsLog.Debug(ctx, "syncBatchForDate", "No campaign performance for date", map[string]interface{}{
+				"date":        date.Format("2006-01-02"),
+				"campaign_id": campaign.CampaignID,
+				"campaign":    campaign.CampaignName,
+			})
 		}
 
 		// Add small delay to respect API rate limits
 		time.Sleep(100 * time.Millisecond)
 	}
 
-	log.Printf("Completed batch sync for store %d on date %s - data found: %v", storeID, date.Format("2006-01-02"), anyDataFound)
+	adsLog.Debug(ctx, "syncBatchForDate", "Batch synced", map[string]interface{}{
+		"store_id":   storeID,
+		"date":       date.Format("2006-01-02"),
+		"data_found": anyDataFound,
+	})
 	return anyDataFound, nil
 }
 
@@ -1402,7 +1547,9 @@ func (s *AdsPerformanceService) ensureStoreTokenValid(ctx context.Context, store
 		return fmt.Errorf("missing client or store repository")
 	}
 
-	log.Printf("ensureStoreTokenValid for store %d", store.StoreID)
+	adsLog.Debug(ctx, "ensureStoreTokenValid", "Checking store token", map[string]interface{}{
+		"store_id": store.StoreID,
+	})
 
 	// Parse timezone for proper token expiration calculation
 	loc, _ := time.LoadLocation("Asia/Jakarta")
@@ -1424,13 +1571,18 @@ func (s *AdsPerformanceService) ensureStoreTokenValid(ctx context.Context, store
 	// Check if token is still valid (not expired)
 	if store.ExpireIn != nil && store.LastUpdated != nil {
 		if time.Now().Before(exp.Local()) {
-			log.Printf("Token for store %d is still valid until %v", store.StoreID, exp)
+			adsLog.Debug(ctx, "ensureStoreTokenValid", "Token still valid", map[string]interface{}{
+				"store_id":   store.StoreID,
+				"expires_at": exp,
+			})
 			return nil
 		}
 	}
 
 	// Token is expired, refresh it
-	log.Printf("Token for store %d is expired, refreshing", store.StoreID)
+	adsLog.Info(ctx, "ensureStoreTokenValid", "Token expired, refreshing", map[string]interface{}{
+		"store_id": store.StoreID,
+	})
 
 	// Temporarily store original client credentials
 	oldShopID := s.shopeeClient.ShopID
@@ -1460,7 +1612,9 @@ func (s *AdsPerformanceService) ensureStoreTokenValid(ctx context.Context, store
 
 	// Save updated store
 	if err := s.repo.ChannelRepo.UpdateStore(ctx, store); err != nil {
-		log.Printf("Warning: failed to update store token in database: %v", err)
+		adsLog.Error(ctx, "ensureStoreTokenValid", "Failed to save refreshed token", err, map[string]interface{}{
+			"store_id": store.StoreID,
+		})
 		// Don't fail the operation, just log the warning
 	}
 
@@ -1468,6 +1622,8 @@ func (s *AdsPerformanceService) ensureStoreTokenValid(ctx context.Context, store
 	s.shopeeClient.ShopID = oldShopID
 	s.shopeeClient.RefreshToken = oldRefreshToken
 
-	log.Printf("Successfully refreshed token for store %d", store.StoreID)
+	adsLog.Info(ctx, "ensureStoreTokenValid", "Token refreshed", map[string]interface{}{
+		"store_id": store.StoreID,
+	})
 	return nil
 }
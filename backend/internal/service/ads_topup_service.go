@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
 )
 
@@ -13,12 +14,17 @@ import (
 type AdsTopupService struct {
 	walletSvc   *WalletTransactionService
 	journalRepo *repository.JournalRepo
+	cache       Cache
 }
 
 func NewAdsTopupService(w *WalletTransactionService, jr *repository.JournalRepo) *AdsTopupService {
 	return &AdsTopupService{walletSvc: w, journalRepo: jr}
 }
 
+// SetCache enables invalidation of cached reports after top-up journals are
+// posted.
+func (s *AdsTopupService) SetCache(c Cache) { s.cache = c }
+
 func (s *AdsTopupService) List(ctx context.Context, store string, p WalletTransactionParams) ([]WalletTransaction, bool, error) {
 	if s.walletSvc == nil {
 		return nil, false, fmt.Errorf("wallet service nil")
@@ -43,12 +49,22 @@ func (s *AdsTopupService) List(ctx context.Context, store string, p WalletTransa
 }
 
 func (s *AdsTopupService) CreateJournal(ctx context.Context, store string, t WalletTransaction) error {
+	posted, err := s.createJournal(ctx, store, t)
+	if posted {
+		invalidateCache(ctx, s.cache, journalWriteTags...)
+	}
+	return err
+}
+
+// createJournal posts the journal of t unless it was posted before and
+// reports whether it did.
+func (s *AdsTopupService) createJournal(ctx context.Context, store string, t WalletTransaction) (bool, error) {
 	if s.journalRepo == nil {
-		return fmt.Errorf("journal repo nil")
+		return false, fmt.Errorf("journal repo nil")
 	}
 	sid := fmt.Sprintf("%d", t.TransactionID)
 	if je, _ := s.journalRepo.GetJournalEntryBySource(ctx, "ads_topup", sid); je != nil {
-		return nil
+		return false, nil
 	}
 	amt := -t.Amount
 	je := &models.JournalEntry{
@@ -62,17 +78,17 @@ func (s *AdsTopupService) CreateJournal(ctx context.Context, store string, t Wal
 	}
 	jid, err := s.journalRepo.CreateJournalEntry(ctx, je)
 	if err != nil {
-		return err
+		return false, err
 	}
 	lines := []models.JournalLine{
-		{JournalID: jid, AccountID: 55003, IsDebit: true, Amount: amt},
-		{JournalID: jid, AccountID: saldoShopeeAccountID(store), IsDebit: false, Amount: amt},
+		{JournalID: jid, AccountID: 55003, IsDebit: true, Amount: money.FromFloat(amt)},
+		{JournalID: jid, AccountID: saldoShopeeAccountID(store), IsDebit: false, Amount: money.FromFloat(amt)},
 	}
 	// Use bulk insert for lines
 	if err := s.journalRepo.InsertJournalLines(ctx, lines); err != nil {
-		return err
+		return true, err
 	}
-	return nil
+	return true, nil
 }
 
 // CreateAllJournal fetches wallet transactions in 15-day windows going backwards
@@ -82,6 +98,12 @@ func (s *AdsTopupService) CreateAllJournal(ctx context.Context, store string) er
 	if s.walletSvc == nil {
 		return fmt.Errorf("wallet service nil")
 	}
+	var posted bool
+	defer func() {
+		if posted {
+			invalidateCache(ctx, s.cache, journalWriteTags...)
+		}
+	}()
 	var emptyRanges int
 	to := time.Now()
 	for emptyRanges < 2 {
@@ -103,7 +125,9 @@ func (s *AdsTopupService) CreateAllJournal(ctx context.Context, store string) er
 				return err
 			}
 			for _, tx := range txs {
-				if err := s.CreateJournal(ctx, store, tx); err != nil {
+				ok, err := s.createJournal(ctx, store, tx)
+				posted = posted || ok
+				if err != nil {
 					return err
 				}
 			}
@@ -3,10 +3,10 @@ package service
 import (
 	"context"
 	"fmt"
-	"math"
 	"time"
 
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
 )
 
@@ -19,18 +19,23 @@ type AssetAccountRepo interface {
 type AssetAccountService struct {
 	repo        AssetAccountRepo
 	journalRepo *repository.JournalRepo
+	cache       Cache
 }
 
 type AssetAccountBalance struct {
-	AssetID   int64   `json:"asset_id"`
-	AccountID int64   `json:"account_id"`
-	Balance   float64 `json:"balance"`
+	AssetID   int64        `json:"asset_id"`
+	AccountID int64        `json:"account_id"`
+	Balance   money.Amount `json:"balance"`
 }
 
 func NewAssetAccountService(r AssetAccountRepo, jr *repository.JournalRepo) *AssetAccountService {
 	return &AssetAccountService{repo: r, journalRepo: jr}
 }
 
+// SetCache enables invalidation of cached reports after balance adjustments
+// are posted.
+func (s *AssetAccountService) SetCache(c Cache) { s.cache = c }
+
 func (s *AssetAccountService) ListBalances(ctx context.Context) ([]AssetAccountBalance, error) {
 	assets, err := s.repo.List(ctx)
 	if err != nil {
@@ -40,7 +45,7 @@ func (s *AssetAccountService) ListBalances(ctx context.Context) ([]AssetAccountB
 	if err != nil {
 		return nil, err
 	}
-	balMap := map[int64]float64{}
+	balMap := map[int64]money.Amount{}
 	for _, b := range bals {
 		balMap[b.AccountID] = b.Balance
 	}
@@ -55,7 +60,7 @@ func (s *AssetAccountService) ListBalances(ctx context.Context) ([]AssetAccountB
 	return res, nil
 }
 
-func (s *AssetAccountService) AdjustBalance(ctx context.Context, id int64, newBal float64) error {
+func (s *AssetAccountService) AdjustBalance(ctx context.Context, id int64, newBal money.Amount) error {
 	aa, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return err
@@ -64,7 +69,7 @@ func (s *AssetAccountService) AdjustBalance(ctx context.Context, id int64, newBa
 	if err != nil {
 		return err
 	}
-	var cur float64
+	var cur money.Amount
 	for _, b := range bals {
 		if b.AccountID == aa.AccountID {
 			cur = b.Balance
@@ -89,7 +94,8 @@ func (s *AssetAccountService) AdjustBalance(ctx context.Context, id int64, newBa
 	if err != nil {
 		return err
 	}
-	amt := math.Abs(diff)
+	amt := diff.Abs()
+
 	lines := []models.JournalLine{
 		{JournalID: jid, AccountID: aa.AccountID, IsDebit: diff > 0, Amount: amt},
 		{JournalID: jid, AccountID: 3001, IsDebit: diff < 0, Amount: amt},
@@ -98,5 +104,6 @@ func (s *AssetAccountService) AdjustBalance(ctx context.Context, id int64, newBa
 	if err := s.journalRepo.InsertJournalLines(ctx, lines); err != nil {
 		return err
 	}
+	invalidateCache(ctx, s.cache, journalWriteTags...)
 	return nil
 }
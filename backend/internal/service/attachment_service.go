@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/logutil"
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/storage"
+)
+
+// ErrInvalidAttachment is wrapped by attachment errors caused by the input,
+// such as an oversized file or a link to a record that does not exist.
+var ErrInvalidAttachment = errors.New("invalid attachment")
+
+// AttachmentStore persists attachment metadata and links.
+// repository.AttachmentRepo implements it.
+type AttachmentStore interface {
+	Insert(ctx context.Context, a *models.Attachment) error
+	Get(ctx context.Context, id int64) (*models.Attachment, error)
+	Delete(ctx context.Context, id int64) error
+	Link(ctx context.Context, l models.AttachmentLink) error
+	Unlink(ctx context.Context, l models.AttachmentLink) error
+	List(ctx context.Context, entityType, entityID string, limit, offset int) ([]models.Attachment, error)
+	EntityExists(ctx context.Context, entityType, entityID string) (bool, error)
+}
+
+// AttachmentService keeps receipts, adjustment proofs and import files in
+// the configured storage and links them to the records they support.
+type AttachmentService struct {
+	repo    AttachmentStore
+	storage storage.Storage
+	maxSize int64
+	now     func() time.Time
+}
+
+// NewAttachmentService constructs an AttachmentService. Uploads larger than
+// maxSize bytes are rejected; zero means no limit.
+func NewAttachmentService(repo AttachmentStore, st storage.Storage, maxSize int64) *AttachmentService {
+	return &AttachmentService{repo: repo, storage: st, maxSize: maxSize, now: time.Now}
+}
+
+// Upload stores the content of r as fileName and links it to links.
+func (s *AttachmentService) Upload(ctx context.Context, fileName, contentType, description string, r io.Reader, links []models.AttachmentLink) (*models.Attachment, error) {
+	return s.save(ctx, fileName, contentType, description, r, links, s.maxSize)
+}
+
+// ArchiveFile copies a file from local disk into attachment storage and
+// links it to one record. Import batches use it so the original upload is
+// kept after the working copy is gone. The size limit does not apply.
+func (s *AttachmentService) ArchiveFile(ctx context.Context, filePath, fileName, entityType, entityID string) (*models.Attachment, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if fileName == "" {
+		fileName = filepath.Base(filePath)
+	}
+	link := models.AttachmentLink{EntityType: entityType, EntityID: entityID}
+	return s.save(ctx, fileName, "", "", f, []models.AttachmentLink{link}, 0)
+}
+
+func (s *AttachmentService) save(ctx context.Context, fileName, contentType, description string, r io.Reader, links []models.AttachmentLink, limit int64) (*models.Attachment, error) {
+	fileName = sanitizeFileName(fileName)
+	if fileName == "" {
+		return nil, fmt.Errorf("%w: file name is required", ErrInvalidAttachment)
+	}
+	for _, l := range links {
+		if err := s.checkTarget(ctx, l); err != nil {
+			return nil, err
+		}
+	}
+	if contentType == "" || contentType == "application/octet-stream" {
+		if t := mime.TypeByExtension(path.Ext(fileName)); t != "" {
+			contentType = t
+		}
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	a := &models.Attachment{
+		StorageKey:  path.Join(s.now().Format("2006/01"), uuid.NewString()+"-"+fileName),
+		FileName:    fileName,
+		ContentType: contentType,
+		Description: strings.TrimSpace(description),
+		Links:       links,
+	}
+	hash := sha256.New()
+	counter := &countingReader{r: io.TeeReader(r, hash)}
+	var body io.Reader = counter
+	if limit > 0 {
+		// One byte over the limit is enough to tell an oversized file.
+		body = io.LimitReader(counter, limit+1)
+	}
+	if err := s.storage.Put(ctx, a.StorageKey, body, contentType); err != nil {
+		return nil, err
+	}
+	if limit > 0 && counter.n > limit {
+		s.removeObject(ctx, a.StorageKey)
+		return nil, fmt.Errorf("%w: %s is larger than %d MB", ErrInvalidAttachment, fileName, limit>>20)
+	}
+	a.SizeBytes = counter.n
+	a.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	if err := s.repo.Insert(ctx, a); err != nil {
+		s.removeObject(ctx, a.StorageKey)
+		return nil, err
+	}
+	logutil.Info(ctx, "SaveAttachment", "Attachment stored", map[string]interface{}{
+		"attachment_id": a.ID,
+		"file_name":     a.FileName,
+		"size_bytes":    a.SizeBytes,
+	})
+	return a, nil
+}
+
+func (s *AttachmentService) removeObject(ctx context.Context, key string) {
+	if err := s.storage.Delete(ctx, key); err != nil {
+		logutil.Errorf("remove attachment object %s: %v", key, err)
+	}
+}
+
+func (s *AttachmentService) checkTarget(ctx context.Context, l models.AttachmentLink) error {
+	if l.EntityType == "" || l.EntityID == "" {
+		return fmt.Errorf("%w: entity_type and entity_id are required", ErrInvalidAttachment)
+	}
+	if !isAttachmentTarget(l.EntityType) {
+		return fmt.Errorf("%w: cannot attach to %q", ErrInvalidAttachment, l.EntityType)
+	}
+	exists, err := s.repo.EntityExists(ctx, l.EntityType, l.EntityID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("%w: %s %s does not exist", ErrInvalidAttachment, l.EntityType, l.EntityID)
+	}
+	return nil
+}
+
+func isAttachmentTarget(entityType string) bool {
+	switch entityType {
+	case models.AttachToExpense, models.AttachToJournalEntry, models.AttachToAdInvoice,
+		models.AttachToTaxPayment, models.AttachToWithdrawal, models.AttachToShopeeAdjustment,
+		models.AttachToBatch:
+		return true
+	}
+	return false
+}
+
+// Link ties an existing attachment to another record.
+func (s *AttachmentService) Link(ctx context.Context, l models.AttachmentLink) error {
+	if _, err := s.repo.Get(ctx, l.AttachmentID); err != nil {
+		return err
+	}
+	if err := s.checkTarget(ctx, l); err != nil {
+		return err
+	}
+	return s.repo.Link(ctx, l)
+}
+
+// Unlink removes one link. The attachment itself is kept.
+func (s *AttachmentService) Unlink(ctx context.Context, l models.AttachmentLink) error {
+	return s.repo.Unlink(ctx, l)
+}
+
+// Get returns an attachment's metadata, or nil when it does not exist.
+func (s *AttachmentService) Get(ctx context.Context, id int64) (*models.Attachment, error) {
+	a, err := s.repo.Get(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return a, err
+}
+
+// List returns attachments newest first, optionally only those linked to a
+// record type or one record.
+func (s *AttachmentService) List(ctx context.Context, entityType, entityID string, limit, offset int) ([]models.Attachment, error) {
+	return s.repo.List(ctx, entityType, entityID, limit, offset)
+}
+
+// Open returns an attachment with a reader for its content. Callers close
+// the reader.
+func (s *AttachmentService) Open(ctx context.Context, id int64) (*models.Attachment, io.ReadCloser, error) {
+	a, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	rc, err := s.storage.Open(ctx, a.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, fmt.Errorf("attachment %d content is missing: %w", id, sql.ErrNoRows)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return a, rc, nil
+}
+
+// Delete removes an attachment, its links and its content.
+func (s *AttachmentService) Delete(ctx context.Context, id int64) error {
+	a, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.removeObject(ctx, a.StorageKey)
+	return nil
+}
+
+// sanitizeFileName keeps the base name of an uploaded file and drops
+// characters that are awkward in storage keys and download headers.
+func sanitizeFileName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	if name == "." || name == "/" {
+		return ""
+	}
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || strings.ContainsRune(`"/\?*:<>|`, r) {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/storage"
+)
+
+type fakeAttachmentStore struct {
+	items    map[int64]*models.Attachment
+	existing map[string]bool
+	nextID   int64
+}
+
+func newFakeAttachmentStore(existing ...string) *fakeAttachmentStore {
+	f := &fakeAttachmentStore{items: map[int64]*models.Attachment{}, existing: map[string]bool{}}
+	for _, e := range existing {
+		f.existing[e] = true
+	}
+	return f
+}
+
+func (f *fakeAttachmentStore) Insert(ctx context.Context, a *models.Attachment) error {
+	f.nextID++
+	a.ID = f.nextID
+	cp := *a
+	f.items[a.ID] = &cp
+	return nil
+}
+func (f *fakeAttachmentStore) Get(ctx context.Context, id int64) (*models.Attachment, error) {
+	a, ok := f.items[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	cp := *a
+	return &cp, nil
+}
+func (f *fakeAttachmentStore) Delete(ctx context.Context, id int64) error {
+	delete(f.items, id)
+	return nil
+}
+func (f *fakeAttachmentStore) Link(ctx context.Context, l models.AttachmentLink) error {
+	f.items[l.AttachmentID].Links = append(f.items[l.AttachmentID].Links, l)
+	return nil
+}
+func (f *fakeAttachmentStore) Unlink(ctx context.Context, l models.AttachmentLink) error {
+	return nil
+}
+func (f *fakeAttachmentStore) List(ctx context.Context, entityType, entityID string, limit, offset int) ([]models.Attachment, error) {
+	return nil, nil
+}
+func (f *fakeAttachmentStore) EntityExists(ctx context.Context, entityType, entityID string) (bool, error) {
+	return f.existing[entityType+":"+entityID], nil
+}
+
+func newTestAttachmentService(t *testing.T, repo AttachmentStore, maxSize int64) (*AttachmentService, string) {
+	t.Helper()
+	dir := t.TempDir()
+	st, err := storage.NewLocal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewAttachmentService(repo, st, maxSize), dir
+}
+
+func TestAttachmentUploadAndOpen(t *testing.T) {
+	repo := newFakeAttachmentStore("expense:e1")
+	svc, _ := newTestAttachmentService(t, repo, 1<<20)
+	ctx := context.Background()
+
+	a, err := svc.Upload(ctx, `C:\scans\nota sewa.pdf`, "", " Mei ", strings.NewReader("abc"),
+		[]models.AttachmentLink{{EntityType: models.AttachToExpense, EntityID: "e1"}})
+	if err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+	if a.FileName != "nota sewa.pdf" || a.ContentType != "application/pdf" || a.Description != "Mei" {
+		t.Errorf("unexpected metadata %+v", a)
+	}
+	// sha256("abc")
+	if a.SizeBytes != 3 || a.SHA256 != "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad" {
+		t.Errorf("unexpected size/hash %d %s", a.SizeBytes, a.SHA256)
+	}
+
+	got, rc, err := svc.Open(ctx, a.ID)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	data, _ := io.ReadAll(rc)
+	rc.Close()
+	if string(data) != "abc" || got.ID != a.ID {
+		t.Fatalf("read back %q", data)
+	}
+
+	if err := svc.Delete(ctx, a.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, _, err := svc.Open(ctx, a.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected ErrNoRows after delete, got %v", err)
+	}
+}
+
+func TestAttachmentUploadRejectsBadInput(t *testing.T) {
+	repo := newFakeAttachmentStore("expense:e1")
+	svc, dir := newTestAttachmentService(t, repo, 4)
+	ctx := context.Background()
+
+	cases := []struct {
+		name  string
+		body  string
+		links []models.AttachmentLink
+	}{
+		{"big.pdf", "12345", nil},
+		{"a.pdf", "1", []models.AttachmentLink{{EntityType: "invoice", EntityID: "1"}}},
+		{"a.pdf", "1", []models.AttachmentLink{{EntityType: models.AttachToExpense, EntityID: "missing"}}},
+		{"", "1", nil},
+	}
+	for _, tc := range cases {
+		if _, err := svc.Upload(ctx, tc.name, "", "", strings.NewReader(tc.body), tc.links); !errors.Is(err, ErrInvalidAttachment) {
+			t.Errorf("%q %+v: expected ErrInvalidAttachment, got %v", tc.name, tc.links, err)
+		}
+	}
+	if len(repo.items) != 0 {
+		t.Errorf("rejected uploads were recorded: %+v", repo.items)
+	}
+	var files []string
+	filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			files = append(files, p)
+		}
+		return nil
+	})
+	if len(files) != 0 {
+		t.Errorf("rejected uploads left files behind: %v", files)
+	}
+}
+
+func TestAttachmentArchiveFile(t *testing.T) {
+	repo := newFakeAttachmentStore("batch:7")
+	svc, _ := newTestAttachmentService(t, repo, 1)
+	src := filepath.Join(t.TempDir(), "20250501_orders.csv")
+	if err := os.WriteFile(src, []byte("order_sn,total\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := svc.ArchiveFile(context.Background(), src, "orders.csv", models.AttachToBatch, "7")
+	if err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	if a.FileName != "orders.csv" || a.SizeBytes != 15 || len(a.Links) != 1 || a.Links[0].EntityID != "7" {
+		t.Fatalf("unexpected archive %+v", a)
+	}
+}
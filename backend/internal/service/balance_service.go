@@ -4,8 +4,11 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"time"
 
+	"github.com/ramadhan22/dropship-erp/backend/internal/cache"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
 )
 
@@ -18,12 +21,14 @@ type BalanceServiceJournalRepo interface {
 type CategoryBalance struct {
 	Category string                      `json:"category"` // e.g. "Assets"
 	Accounts []repository.AccountBalance `json:"accounts"` // list of account balances in this category
-	Total    float64                     `json:"total"`    // sum of balances
+	Total    money.Amount                `json:"total"`
+	// sum of balances
 }
 
 // BalanceService provides balance sheet data by category.
 type BalanceService struct {
 	journalRepo BalanceServiceJournalRepo
+	cache       Cache
 }
 
 // NewBalanceService constructs a BalanceService with the given JournalRepo.
@@ -31,11 +36,29 @@ func NewBalanceService(jr BalanceServiceJournalRepo) *BalanceService {
 	return &BalanceService{journalRepo: jr}
 }
 
+// SetCache enables caching of computed balance sheets. Entries are tagged
+// with cache.TagJournals so any journal write invalidates them.
+func (s *BalanceService) SetCache(c Cache) {
+	s.cache = c
+}
+
 // GetBalanceSheet returns balances grouped into Assets, Liabilities, Equity as of asOfDate.
 func (s *BalanceService) GetBalanceSheet(
 	ctx context.Context,
 	shop string,
 	asOfDate time.Time,
+) ([]CategoryBalance, error) {
+	key := fmt.Sprintf("report:balancesheet:%s:%s", shop, asOfDate.Format(time.RFC3339))
+	tags := []string{cache.TagJournals, cache.TagReports}
+	return cachedJSON(ctx, s.cache, key, reportCacheTTL, tags, func() ([]CategoryBalance, error) {
+		return s.computeBalanceSheet(ctx, shop, asOfDate)
+	})
+}
+
+func (s *BalanceService) computeBalanceSheet(
+	ctx context.Context,
+	shop string,
+	asOfDate time.Time,
 ) ([]CategoryBalance, error) {
 	// Fetch raw account balances
 	accBalances, err := s.journalRepo.GetAccountBalancesAsOf(ctx, shop, asOfDate)
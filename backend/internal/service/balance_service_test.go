@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
 )
 
@@ -26,9 +27,9 @@ func TestGetBalanceSheet(t *testing.T) {
 	fFake := &fakeJournalRepoB{
 		data: map[string][]repository.AccountBalance{
 			shop: {
-				{AccountID: 1001, AccountCode: "1001", AccountName: "Cash", AccountType: "Asset", Balance: 500.0},
-				{AccountID: 2001, AccountCode: "2001", AccountName: "Payable", AccountType: "Liability", Balance: -200.0},
-				{AccountID: 3001, AccountCode: "3001", AccountName: "Equity", AccountType: "Equity", Balance: 300.0},
+				{AccountID: 1001, AccountCode: "1001", AccountName: "Cash", AccountType: "Asset", Balance: money.New(500)},
+				{AccountID: 2001, AccountCode: "2001", AccountName: "Payable", AccountType: "Liability", Balance: money.New(-200)},
+				{AccountID: 3001, AccountCode: "3001", AccountName: "Equity", AccountType: "Equity", Balance: money.New(300)},
 			},
 		},
 	}
@@ -43,15 +44,15 @@ func TestGetBalanceSheet(t *testing.T) {
 		t.Fatalf("expected 3 categories, got %d", len(cats))
 	}
 	// Check Asset category
-	if cats[0].Category != "Assets" || cats[0].Total != 500.0 {
+	if cats[0].Category != "Assets" || cats[0].Total != money.New(500) {
 		t.Errorf("unexpected Assets group: %+v", cats[0])
 	}
 	// Check Liability category
-	if cats[1].Category != "Liabilities" || cats[1].Total != -200.0 {
+	if cats[1].Category != "Liabilities" || cats[1].Total != money.New(-200) {
 		t.Errorf("unexpected Liabilities group: %+v", cats[1])
 	}
 	// Check Equity category
-	if cats[2].Category != "Equity" || cats[2].Total != 300.0 {
+	if cats[2].Category != "Equity" || cats[2].Total != money.New(300) {
 		t.Errorf("unexpected Equity group: %+v", cats[2])
 	}
 }
@@ -72,10 +73,10 @@ func TestGetBalanceSheet_DifferentDates(t *testing.T) {
 	repo := &fakeJournalRepoBMap{data: map[string]map[string][]repository.AccountBalance{
 		shop: {
 			d1.Format("2006-01-02"): {
-				{AccountID: 1001, AccountCode: "1001", AccountName: "Cash", AccountType: "Asset", Balance: 100},
+				{AccountID: 1001, AccountCode: "1001", AccountName: "Cash", AccountType: "Asset", Balance: money.New(100)},
 			},
 			d2.Format("2006-01-02"): {
-				{AccountID: 1001, AccountCode: "1001", AccountName: "Cash", AccountType: "Asset", Balance: 200},
+				{AccountID: 1001, AccountCode: "1001", AccountName: "Cash", AccountType: "Asset", Balance: money.New(200)},
 			},
 		},
 	}}
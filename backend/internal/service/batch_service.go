@@ -2,23 +2,83 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
 
+	"github.com/ramadhan22/dropship-erp/backend/internal/logutil"
+	"github.com/ramadhan22/dropship-erp/backend/internal/metrics"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
 	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
+	"github.com/ramadhan22/dropship-erp/backend/internal/tracing"
 )
 
+// ErrBatchState is returned when a batch is not in a state that allows the
+// requested change, such as retrying a batch that is still running.
+var ErrBatchState = errors.New("batch state does not allow this action")
+
+// resumableBatchTypes are the batch types whose processing can start over
+// after an interrupted run without posting anything twice.
+var resumableBatchTypes = []string{"dropship_import", "streaming_dropship_import", "reconcile_batch", "ads_performance_sync"}
+
+// instanceID identifies this process in batch_history.claimed_by, so a
+// shutdown resets only the batches it was running.
+var instanceID = newInstanceID()
+
+func newInstanceID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s/%d/%d", host, os.Getpid(), time.Now().UnixNano())
+}
+
+// importBatchTypes are the batch types whose FilePath is an uploaded file
+// worth keeping as an attachment of the batch.
+var importBatchTypes = map[string]bool{"dropship_import": true, "streaming_dropship_import": true}
+
+// FileArchiver keeps a copy of a local file linked to a record.
+// AttachmentService implements it.
+type FileArchiver interface {
+	ArchiveFile(ctx context.Context, filePath, fileName, entityType, entityID string) (*models.Attachment, error)
+}
+
 // BatchService provides operations on batch_history.
 type BatchService struct {
 	repo       *repository.BatchRepo
 	detailRepo *repository.BatchDetailRepo
+	archiver   FileArchiver
+	owner      string
 }
 
 func NewBatchService(r *repository.BatchRepo, d *repository.BatchDetailRepo) *BatchService {
-	return &BatchService{repo: r, detailRepo: d}
+	return &BatchService{repo: r, detailRepo: d, owner: instanceID}
+}
+
+// SetArchiver enables archiving of uploaded import files when their batch
+// is created.
+func (s *BatchService) SetArchiver(a FileArchiver) {
+	s.archiver = a
 }
 
 func (s *BatchService) Create(ctx context.Context, b *models.BatchHistory) (int64, error) {
-	return s.repo.Insert(ctx, b)
+	if b.Status == "processing" && b.ClaimedBy == nil {
+		b.ClaimedBy = &s.owner
+	}
+	id, err := s.repo.Insert(ctx, b)
+	if err != nil {
+		return id, err
+	}
+	if s.archiver != nil && importBatchTypes[b.ProcessType] && b.FilePath != "" {
+		// The import goes ahead even when the copy fails; the working file
+		// is still on disk.
+		if _, err := s.archiver.ArchiveFile(ctx, b.FilePath, b.FileName, models.AttachToBatch, strconv.FormatInt(id, 10)); err != nil {
+			logutil.Errorf("archive import file of batch %d: %v", id, err)
+		}
+	}
+	return id, nil
 }
 
 func (s *BatchService) UpdateDone(ctx context.Context, id int64, done int) error {
@@ -30,13 +90,77 @@ func (s *BatchService) UpdateTotal(ctx context.Context, id int64, total int) err
 	return s.repo.UpdateTotal(ctx, id, total)
 }
 
+// UpdateStatus sets the status of a batch. A batch moved to processing is
+// recorded as run by this instance.
 func (s *BatchService) UpdateStatus(ctx context.Context, id int64, status, msg string) error {
-	return s.repo.UpdateStatus(ctx, id, status, msg)
+	var err error
+	if status == "processing" {
+		err = s.repo.MarkProcessing(ctx, id, msg, s.owner)
+	} else {
+		err = s.repo.UpdateStatus(ctx, id, status, msg)
+	}
+	if err != nil {
+		return err
+	}
+	s.observeFinished(ctx, id, status, msg)
+	return nil
 }
 
 // UpdateStatusWithEndTime updates the status and records the end time and duration.
 func (s *BatchService) UpdateStatusWithEndTime(ctx context.Context, id int64, status, msg string) error {
-	return s.repo.UpdateStatusWithEndTime(ctx, id, status, msg)
+	if err := s.repo.UpdateStatusWithEndTime(ctx, id, status, msg); err != nil {
+		return err
+	}
+	s.observeFinished(ctx, id, status, msg)
+	return nil
+}
+
+// observeFinished records the run time of a batch that just completed or
+// failed in the batch_job_duration_seconds histogram, and the outcome on the
+// current span.
+func (s *BatchService) observeFinished(ctx context.Context, id int64, status, msg string) {
+	if status != "completed" && status != "failed" {
+		return
+	}
+	span := tracing.FromContext(ctx)
+	span.SetAttr("batch.status", status)
+	if status == "failed" {
+		span.RecordError(errors.New(msg))
+	}
+	b, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return
+	}
+	end := time.Now()
+	if b.EndedAt != nil {
+		end = *b.EndedAt
+	}
+	metrics.BatchJobDuration.Observe(end.Sub(b.StartedAt).Seconds(), b.ProcessType, status)
+}
+
+// startBatchSpan starts the span of one batch job. Queries and Shopee calls
+// made with the returned context are recorded beneath it.
+func startBatchSpan(ctx context.Context, processType string, id int64) (context.Context, *tracing.Span) {
+	ctx, span := tracing.Start(ctx, "batch "+processType, tracing.KindInternal)
+	span.SetAttr("batch.id", id)
+	span.SetAttr("batch.process_type", processType)
+	return ctx, span
+}
+
+// CollectQueueDepth publishes the number of pending and processing batches
+// per process type. It is a metrics.CollectFunc.
+func (s *BatchService) CollectQueueDepth(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	counts, err := s.repo.QueueDepth(ctx)
+	if err != nil {
+		logutil.Error(ctx, "CollectQueueDepth", "Failed to read batch queue depth", err)
+		return
+	}
+	metrics.BatchQueueDepth.Reset()
+	for _, c := range counts {
+		metrics.BatchQueueDepth.Set(float64(c.Count), c.ProcessType, c.Status)
+	}
 }
 
 func (s *BatchService) List(ctx context.Context) ([]models.BatchHistory, error) {
@@ -51,6 +175,11 @@ func (s *BatchService) ListFiltered(ctx context.Context, types, statuses []strin
 	return s.repo.ListFiltered(ctx, types, statuses)
 }
 
+// ListQuery uses the filtering framework to fetch batch history.
+func (s *BatchService) ListQuery(ctx context.Context, params *models.FilterParams) (*models.QueryResult, error) {
+	return s.repo.ListQuery(ctx, params)
+}
+
 func (s *BatchService) CreateDetail(ctx context.Context, d *models.BatchHistoryDetail) error {
 	if s.detailRepo == nil {
 		return nil
@@ -77,6 +206,12 @@ func (s *BatchService) ListPendingByType(ctx context.Context, typ string) ([]mod
 	return s.repo.ListByProcessAndStatus(ctx, typ, "pending")
 }
 
+// Claim marks a pending batch as processing and reports whether this caller
+// won it. A batch another worker already claimed returns false.
+func (s *BatchService) Claim(ctx context.Context, id int64) (bool, error) {
+	return s.repo.Claim(ctx, id, s.owner)
+}
+
 // GetByID retrieves a batch record by its ID.
 func (s *BatchService) GetByID(ctx context.Context, id int64) (*models.BatchHistory, error) {
 	return s.repo.GetByID(ctx, id)
@@ -89,3 +224,47 @@ func (s *BatchService) UpdateBatchData(ctx context.Context, id int64, total, don
 	}
 	return s.repo.UpdateDone(ctx, id, done)
 }
+
+// Retry requeues a failed or cancelled batch.
+func (s *BatchService) Retry(ctx context.Context, id int64) error {
+	b, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	ok, err := s.repo.Requeue(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: batch %d is %s", ErrBatchState, id, b.Status)
+	}
+	return nil
+}
+
+// Cancel stops a pending batch from being processed. Batches that are
+// already processing cannot be cancelled.
+func (s *BatchService) Cancel(ctx context.Context, id int64, reason string) error {
+	b, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if reason == "" {
+		reason = "cancelled"
+	}
+	ok, err := s.repo.Cancel(ctx, id, reason)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: batch %d is %s", ErrBatchState, id, b.Status)
+	}
+	return nil
+}
+
+// ResumeInterrupted resets the batches this instance left processing when a
+// shutdown aborted them. Resumable types go back to pending so the schedulers
+// pick them up on the next start; other batches are marked failed and can be
+// retried by hand. Batches run by other instances are left alone.
+func (s *BatchService) ResumeInterrupted(ctx context.Context) (int64, int64, error) {
+	return s.repo.ResetProcessing(ctx, s.owner, resumableBatchTypes, "interrupted by shutdown")
+}
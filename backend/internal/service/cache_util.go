@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/cache"
+	"github.com/ramadhan22/dropship-erp/backend/internal/logutil"
+)
+
+// reportCacheTTL is how long rendered summaries and reports stay cached.
+// Writers invalidate them by tag, so the TTL only bounds staleness caused
+// by writes that bypass the services.
+const reportCacheTTL = 10 * time.Minute
+
+// cachedJSON returns the value stored under key, or calls load and caches
+// its result under key labelled with tags. A nil cache or any cache error
+// falls through to load so caching never fails a request.
+func cachedJSON[T any](ctx context.Context, c Cache, key string, ttl time.Duration, tags []string, load func() (T, error)) (T, error) {
+	if c == nil {
+		return load()
+	}
+	if data, err := c.Get(ctx, key); err == nil {
+		var v T
+		if err := json.Unmarshal(data, &v); err == nil {
+			return v, nil
+		}
+	}
+	v, err := load()
+	if err != nil {
+		return v, err
+	}
+	if data, err := json.Marshal(v); err == nil {
+		if err := c.SetWithTags(ctx, key, data, ttl, tags...); err != nil {
+			logutil.Warn(ctx, "CacheSet", "Failed to cache value", map[string]interface{}{
+				"key":   key,
+				"error": err,
+			})
+		}
+	}
+	return v, nil
+}
+
+// invalidateCache drops every cached entry labelled with any of the tags.
+// Errors are logged only: the write that triggered the invalidation has
+// already been committed.
+func invalidateCache(ctx context.Context, c Cache, tags ...string) {
+	if c == nil || len(tags) == 0 {
+		return
+	}
+	if err := c.InvalidateTags(ctx, tags...); err != nil {
+		logutil.Error(ctx, "CacheInvalidate", "Failed to invalidate cache tags", err, map[string]interface{}{
+			"tags": tags,
+		})
+	}
+}
+
+// journalWriteTags are invalidated whenever journal entries change.
+var journalWriteTags = []string{cache.TagJournals, cache.TagReports}
@@ -2,8 +2,11 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"time"
 
+	"github.com/ramadhan22/dropship-erp/backend/internal/cache"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
 )
 
@@ -14,7 +17,7 @@ type DashboardRepo interface {
 	DistinctCustomers(ctx context.Context, channel, store, from, to string) (int, error)
 	DailyTotals(ctx context.Context, channel, store, from, to string) ([]repository.DailyPurchaseTotal, error)
 	MonthlyTotals(ctx context.Context, channel, store, from, to string) ([]repository.MonthlyPurchaseTotal, error)
-	SumDropshipPurchases(ctx context.Context, channel, store, from, to string) (float64, error)
+	SumDropshipPurchases(ctx context.Context, channel, store, from, to string) (money.Amount, error)
 }
 
 type DashboardJournalRepo interface {
@@ -25,12 +28,19 @@ type DashboardService struct {
 	dropRepo    DashboardRepo
 	journalRepo DashboardJournalRepo
 	plSvc       *ProfitLossReportService
+	cache       Cache
 }
 
 func NewDashboardService(dr DashboardRepo, jr DashboardJournalRepo, pl *ProfitLossReportService) *DashboardService {
 	return &DashboardService{dropRepo: dr, journalRepo: jr, plSvc: pl}
 }
 
+// SetCache enables caching of dashboard summaries. Entries are tagged with
+// the purchase and journal tags so imports and journal writes invalidate them.
+func (s *DashboardService) SetCache(c Cache) {
+	s.cache = c
+}
+
 type DashboardFilters struct {
 	Channel string
 	Store   string
@@ -55,6 +65,14 @@ type DashboardData struct {
 }
 
 func (s *DashboardService) GetDashboardData(ctx context.Context, f DashboardFilters) (*DashboardData, error) {
+	key := fmt.Sprintf("report:dashboard:%s:%s:%s:%d:%d", f.Channel, f.Store, f.Period, f.Month, f.Year)
+	tags := []string{cache.TagPurchases, cache.TagJournals, cache.TagReports}
+	return cachedJSON(ctx, s.cache, key, reportCacheTTL, tags, func() (*DashboardData, error) {
+		return s.computeDashboardData(ctx, f)
+	})
+}
+
+func (s *DashboardService) computeDashboardData(ctx context.Context, f DashboardFilters) (*DashboardData, error) {
 	var start, end time.Time
 	if f.Period == "Yearly" {
 		start = time.Date(f.Year, 1, 1, 0, 0, 0, 0, time.UTC)
@@ -96,7 +114,7 @@ func (s *DashboardService) GetDashboardData(ctx context.Context, f DashboardFilt
 	if err == nil {
 		for _, ab := range balances {
 			if ab.AccountID == 11010 {
-				outstanding = ab.Balance
+				outstanding = ab.Balance.Float64()
 				break
 			}
 		}
@@ -105,7 +123,7 @@ func (s *DashboardService) GetDashboardData(ctx context.Context, f DashboardFilt
 	pl, _ := s.plSvc.GetProfitLoss(ctx, f.Period, f.Month, f.Year, f.Store, false)
 	netProfit := 0.0
 	if pl != nil {
-		netProfit = pl.LabaRugiBersih.Amount
+		netProfit = pl.LabaRugiBersih.Amount.Float64()
 	}
 
 	charts := make(map[string][]Point)
@@ -143,7 +161,7 @@ func (s *DashboardService) GetDashboardData(ctx context.Context, f DashboardFilt
 			"avg_order_value":    {Value: avgOrder},
 			"total_cancelled":    {Value: float64(cancelled.Count)},
 			"total_customers":    {Value: float64(customers)},
-			"total_price":        {Value: totalPrice},
+			"total_price":        {Value: totalPrice.Float64()},
 			"total_discounts":    {Value: 0},
 			"total_net_profit":   {Value: netProfit},
 			"outstanding_amount": {Value: outstanding},
@@ -10,6 +10,8 @@ import (
 // ProcessImportFile reads the CSV file at the given path and stores its rows.
 // Batch progress is recorded using the BatchService if available.
 func (s *DropshipService) ProcessImportFile(ctx context.Context, batchID int64, path, channel string) {
+	ctx, span := startBatchSpan(ctx, "dropship_import", batchID)
+	defer span.End()
 	f, err := os.Open(path)
 	if err != nil {
 		if s.batchSvc != nil {
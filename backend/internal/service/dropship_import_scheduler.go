@@ -2,8 +2,9 @@ package service
 
 import (
 	"context"
-	"log"
 	"time"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/logutil"
 )
 
 // DropshipImportScheduler periodically processes pending dropship import batches.
@@ -11,6 +12,7 @@ type DropshipImportScheduler struct {
 	batch    *BatchService
 	svc      *DropshipService
 	interval time.Duration
+	tickerLoop
 }
 
 // NewDropshipImportScheduler creates a scheduler with the given interval.
@@ -27,27 +29,19 @@ func (s *DropshipImportScheduler) Start(ctx context.Context) {
 	if s == nil {
 		return
 	}
-	go func() {
-		ticker := time.NewTicker(s.interval)
-		defer ticker.Stop()
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case <-ticker.C:
-				s.run(ctx)
-			}
-		}
-	}()
+	s.tickerLoop.run(ctx, s.interval, s.run)
 }
 
 func (s *DropshipImportScheduler) run(ctx context.Context) {
 	list, err := s.batch.ListPendingByType(ctx, "dropship_import")
 	if err != nil {
-		log.Printf("scheduler list pending: %v", err)
+		logutil.Error(ctx, "DropshipImportScheduler", "Failed to list pending imports", err)
 		return
 	}
 	for _, b := range list {
+		if s.stopping() {
+			break
+		}
 		s.svc.ProcessImportFile(ctx, b.ID, b.FilePath, "")
 	}
 }
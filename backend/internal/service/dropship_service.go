@@ -6,20 +6,26 @@ import (
 	"context"
 	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/ramadhan22/dropship-erp/backend/internal/apperr"
+	"github.com/ramadhan22/dropship-erp/backend/internal/cache"
+	"github.com/ramadhan22/dropship-erp/backend/internal/lifecycle"
 	"github.com/ramadhan22/dropship-erp/backend/internal/logutil"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
 )
 
+var dropshipLog = logutil.NewLogger("dropship-service", logutil.INFO)
+
 // DropshipRepoInterface defines the subset of DropshipRepo methods that the service needs.
 // In production, you pass in *repository.DropshipRepo; in tests you pass a fake implementing this.
 type DropshipRepoInterface interface {
@@ -29,13 +35,14 @@ type DropshipRepoInterface interface {
 	ListExistingPurchases(ctx context.Context, ids []string) (map[string]bool, error)
 	ListDropshipPurchases(ctx context.Context, channel, store, from, to, orderNo, sortBy, dir string, limit, offset int) ([]models.DropshipPurchase, int, error)
 	ListDropshipPurchasesFiltered(ctx context.Context, params *models.FilterParams) (*models.QueryResult, error)
-	SumDropshipPurchases(ctx context.Context, channel, store, from, to string) (float64, error)
+	SumDropshipPurchases(ctx context.Context, channel, store, from, to string) (money.Amount, error)
 	GetDropshipPurchaseByID(ctx context.Context, kodePesanan string) (*models.DropshipPurchase, error)
 	ListDropshipPurchaseDetails(ctx context.Context, kodePesanan string) ([]models.DropshipPurchaseDetail, error)
 	TopProducts(ctx context.Context, channel, store, from, to string, limit int) ([]models.ProductSales, error)
 	DailyTotals(ctx context.Context, channel, store, from, to string) ([]repository.DailyPurchaseTotal, error)
 	MonthlyTotals(ctx context.Context, channel, store, from, to string) ([]repository.MonthlyPurchaseTotal, error)
 	CancelledSummary(ctx context.Context, channel, store, from, to string) (repository.CancelledSummary, error)
+	ListPurchaseStatusHistory(ctx context.Context, kodePesanan string) ([]models.PurchaseStatusEvent, error)
 }
 
 // DropshipService handles CSV‐import and any Dropship‐related business logic.
@@ -75,11 +82,15 @@ type DropshipService struct {
 	batchSize   int
 }
 
-// Cache interface for dropship service
+// Cache is the subset of cache.Cache used by services to store derived
+// summaries and reports and to invalidate them after writes.
 type Cache interface {
 	Get(ctx context.Context, key string) ([]byte, error)
 	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	SetWithTags(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error
 	Delete(ctx context.Context, key string) error
+	DeleteByPrefix(ctx context.Context, prefix string) error
+	InvalidateTags(ctx context.Context, tags ...string) error
 	Exists(ctx context.Context, key string) (bool, error)
 }
 
@@ -127,7 +138,10 @@ func NewDropshipService(
 // Any parse error aborts the import and returns it.
 // ImportFromCSV inserts rows from a CSV reader and returns how many rows were inserted.
 func (s *DropshipService) ImportFromCSV(ctx context.Context, r io.Reader, channel string, batchID int64) (int, error) {
-	log.Printf("ImportFromCSV channel=%s", channel)
+	dropshipLog.Info(ctx, "ImportFromCSV", "Importing dropship purchases", map[string]interface{}{
+		"channel":  channel,
+		"batch_id": batchID,
+	})
 	reader := csv.NewReader(r)
 	reader.ReuseRecord = true
 	if _, err := reader.Read(); err != nil {
@@ -201,7 +215,7 @@ func (s *DropshipService) ImportFromCSV(ctx context.Context, r io.Reader, channe
 		}
 	}
 
-	apiTotals := make(map[string]float64)
+	apiTotals := make(map[string]money.Amount)
 	var mu sync.Mutex
 	var wg sync.WaitGroup
 	limit := s.maxThreads
@@ -230,7 +244,10 @@ func (s *DropshipService) ImportFromCSV(ctx context.Context, r io.Reader, channe
 				amtMap, err := s.fetchAndStoreDetailBatch(ctx, batch)
 				mu.Lock()
 				if err != nil {
-					log.Printf("fetch batch detail store %s: %v", st, err)
+					dropshipLog.Error(ctx, "ImportFromCSV", "Failed to fetch order details, skipping the orders", err, map[string]interface{}{
+						"store":  st,
+						"orders": len(batch),
+					})
 					for _, h := range batch {
 						skipped[h.KodePesanan] = true
 						if s.batchSvc != nil && batchID != 0 {
@@ -260,18 +277,14 @@ func (s *DropshipService) ImportFromCSV(ctx context.Context, r io.Reader, channe
 	headersMap := make(map[string]*models.DropshipPurchase)
 	// accumulate product totals per purchase across multiple rows
 	type totals struct {
-		prod      float64
-		prodCh    float64
-		apiAmount float64
+		prod      money.Amount
+		prodCh    money.Amount
+		apiAmount money.Amount
 	}
 	agg := make(map[string]*totals)
 	count := 0
 
 	for _, record := range allRecords {
-		if record[18] == "MR eStore Free Sample" {
-			log.Printf("processing MR eStore Free Sample")
-		}
-
 		qty, err := strconv.Atoi(record[8])
 		if err != nil {
 			logutil.Errorf("ImportFromCSV parse qty error: %v", err)
@@ -287,14 +300,29 @@ func (s *DropshipService) ImportFromCSV(ctx context.Context, r io.Reader, channe
 			}
 			continue
 		}
-		hargaProduk, _ := strconv.ParseFloat(record[7], 64)
-		totalHargaProduk, _ := strconv.ParseFloat(record[9], 64)
-		biayaLain, _ := strconv.ParseFloat(record[10], 64)
-		biayaMitra, _ := strconv.ParseFloat(record[11], 64)
-		totalTransaksi, _ := strconv.ParseFloat(record[12], 64)
-		hargaChannel, _ := strconv.ParseFloat(record[13], 64)
-		totalHargaChannel, _ := strconv.ParseFloat(record[14], 64)
-		potensi, _ := strconv.ParseFloat(record[15], 64)
+		amounts, err := parseDropshipAmounts(record)
+		if err != nil {
+			logutil.Errorf("ImportFromCSV parse amount error: %v", err)
+			if s.batchSvc != nil && batchID != 0 {
+				d := &models.BatchHistoryDetail{
+					BatchID:   batchID,
+					Reference: record[19],
+					Store:     record[18],
+					Status:    "failed",
+					ErrorMsg:  err.Error(),
+				}
+				_ = s.batchSvc.CreateDetail(ctx, d)
+			}
+			continue
+		}
+		hargaProduk := amounts[7]
+		totalHargaProduk := amounts[9]
+		biayaLain := amounts[10]
+		biayaMitra := amounts[11]
+		totalTransaksi := amounts[12]
+		hargaChannel := amounts[13]
+		totalHargaChannel := amounts[14]
+		potensi := amounts[15]
 
 		waktuPesanan, err := time.Parse("02 January 2006, 15:04:05", record[1])
 		if err != nil {
@@ -356,7 +384,9 @@ func (s *DropshipService) ImportFromCSV(ctx context.Context, r io.Reader, channe
 			apiAmt := apiTotals[header.KodePesanan]
 
 			if err := repoTx.InsertDropshipPurchase(ctx, header); err != nil {
-				log.Printf("ImportFromCSV insert purchase %s error: %v", header.KodePesanan, err)
+				dropshipLog.Error(ctx, "ImportFromCSV", "Failed to insert purchase", err, map[string]interface{}{
+					"kode_pesanan": header.KodePesanan,
+				})
 				if s.batchSvc != nil && batchID != 0 {
 					d := &models.BatchHistoryDetail{
 						BatchID:   batchID,
@@ -437,7 +467,7 @@ func (s *DropshipService) ImportFromCSV(ctx context.Context, r io.Reader, channe
 	for kode := range inserted {
 		h := headersMap[kode]
 		sum := agg[kode]
-		var prod, prodCh, apiAmt float64
+		var prod, prodCh, apiAmt money.Amount
 		if sum != nil {
 			prod = sum.prod
 			prodCh = sum.prodCh
@@ -448,9 +478,10 @@ func (s *DropshipService) ImportFromCSV(ctx context.Context, r io.Reader, channe
 			pending = apiAmt
 		}
 		if strings.EqualFold(h.NamaToko, "MR eStore Free Sample") {
-			log.Printf("creating free sample journal for %s", kode)
 			if err := s.createFreeSampleJournal(ctx, jrTx, h, prod); err != nil {
-				log.Printf("journal %s: %v", kode, err)
+				dropshipLog.Error(ctx, "ImportFromCSV", "Failed to create free sample journal", err, map[string]interface{}{
+					"kode_pesanan": kode,
+				})
 				if s.batchSvc != nil && batchID != 0 {
 					d := &models.BatchHistoryDetail{
 						BatchID:   batchID,
@@ -463,17 +494,16 @@ func (s *DropshipService) ImportFromCSV(ctx context.Context, r io.Reader, channe
 				}
 				continue
 			}
-			if repoUp, ok := repoTx.(interface {
-				UpdatePurchaseStatus(context.Context, string, string) error
-			}); ok {
-				if err := repoUp.UpdatePurchaseStatus(ctx, h.KodePesanan, "Pesanan selesai"); err != nil {
-					log.Printf("update status %s: %v", h.KodePesanan, err)
-				}
+			if err := transitionPurchase(ctx, repoTx, h.KodePesanan, lifecycle.Change{To: lifecycle.Settled, Cause: lifecycle.CauseFreeSample}); err != nil {
+				logutil.Errorf("ImportFromCSV %v", err)
+				return 0, err
 			}
 			continue
 		}
 		if err := s.createPendingSalesJournal(ctx, jrTx, h, prod, pending); err != nil {
-			log.Printf("journal %s: %v", kode, err)
+			dropshipLog.Error(ctx, "ImportFromCSV", "Failed to create pending sales journal", err, map[string]interface{}{
+				"kode_pesanan": kode,
+			})
 			if s.batchSvc != nil && batchID != 0 {
 				d := &models.BatchHistoryDetail{
 					BatchID:   batchID,
@@ -486,14 +516,24 @@ func (s *DropshipService) ImportFromCSV(ctx context.Context, r io.Reader, channe
 			}
 			continue
 		}
+		if err := transitionPurchase(ctx, repoTx, h.KodePesanan, lifecycle.Change{To: lifecycle.PendingSale, Cause: lifecycle.CausePendingJournal}); err != nil {
+			logutil.Errorf("ImportFromCSV %v", err)
+			return 0, err
+		}
 	}
 	if tx != nil {
 		if err := tx.Commit(); err != nil {
 			return count, err
 		}
-		log.Printf("ImportFromCSV committed %d rows", count)
 	}
-	log.Printf("ImportFromCSV done count=%d", count)
+	if count > 0 {
+		invalidateCache(ctx, s.cache, cache.TagPurchases, cache.TagJournals, cache.TagReports)
+	}
+	dropshipLog.Info(ctx, "ImportFromCSV", "Dropship purchases imported", map[string]interface{}{
+		"channel":  channel,
+		"batch_id": batchID,
+		"count":    count,
+	})
 	return count, nil
 }
 
@@ -517,7 +557,7 @@ func (s *DropshipService) ListDropshipPurchasesFiltered(
 func (s *DropshipService) SumDropshipPurchases(
 	ctx context.Context,
 	channel, store, from, to string,
-) (float64, error) {
+) (money.Amount, error) {
 	return s.repo.SumDropshipPurchases(ctx, channel, store, from, to)
 }
 
@@ -529,6 +569,57 @@ func (s *DropshipService) ListDropshipPurchaseDetails(ctx context.Context, kodeP
 	return s.repo.ListDropshipPurchaseDetails(ctx, kodePesanan)
 }
 
+// dropshipAmountColumns names the money columns of a dropship CSV row.
+var dropshipAmountColumns = []struct {
+	idx  int
+	name string
+}{
+	{7, "harga_produk"},
+	{9, "total_harga_produk"},
+	{10, "biaya_lainnya"},
+	{11, "biaya_mitra_jakmall"},
+	{12, "total_transaksi"},
+	{13, "harga_produk_channel"},
+	{14, "total_harga_produk_channel"},
+	{15, "potensi_keuntungan"},
+}
+
+// parseDropshipAmounts parses the money columns of record keyed by column
+// index. The first unparsable column fails the whole row.
+func parseDropshipAmounts(record []string) (map[int]money.Amount, error) {
+	amounts := make(map[int]money.Amount, len(dropshipAmountColumns))
+	for _, c := range dropshipAmountColumns {
+		v, err := money.Parse(record[c.idx])
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", c.name, err)
+		}
+		amounts[c.idx] = v
+	}
+	return amounts, nil
+}
+
+// GetPurchaseTimeline returns a purchase's lifecycle state, the states it can
+// still move to and its status history.
+func (s *DropshipService) GetPurchaseTimeline(ctx context.Context, kodePesanan string) (*models.PurchaseTimeline, error) {
+	dp, err := s.repo.GetDropshipPurchaseByID(ctx, kodePesanan)
+	if err != nil {
+		return nil, err
+	}
+	events, err := s.repo.ListPurchaseStatusHistory(ctx, kodePesanan)
+	if err != nil {
+		return nil, err
+	}
+	return &models.PurchaseTimeline{
+		KodePesanan:        dp.KodePesanan,
+		KodeInvoiceChannel: dp.KodeInvoiceChannel,
+		Status:             dp.LifecycleStatus,
+		Label:              dp.StatusPesananTerakhir,
+		Terminal:           dp.LifecycleStatus.Terminal(),
+		Next:               dp.LifecycleStatus.Next(),
+		Events:             events,
+	}, nil
+}
+
 func (s *DropshipService) TopProducts(ctx context.Context, channel, store, from, to string, limit int) ([]models.ProductSales, error) {
 	return s.repo.TopProducts(ctx, channel, store, from, to, limit)
 }
@@ -564,7 +655,7 @@ func (s *DropshipService) fetchAndStoreDetail(ctx context.Context, header *model
 	defer cancel()
 
 	det, err := s.client.FetchShopeeOrderDetail(apiCtx, *st.AccessToken, *st.ShopID, header.KodeInvoiceChannel)
-	if err != nil && strings.Contains(err.Error(), "invalid_access_token") {
+	if errors.Is(err, apperr.ErrTokenExpired) {
 		if e := s.ensureStoreTokenValid(ctx, st); e == nil {
 			// Retry with fresh timeout context
 			apiCtx2, cancel2 := WithExternalAPITimeout(ctx)
@@ -584,7 +675,9 @@ func (s *DropshipService) fetchAndStoreDetail(ctx context.Context, header *model
 	}
 	if s.detailRepo != nil {
 		if err := s.detailRepo.SaveOrderDetail(ctx, row, items, packages); err != nil {
-			log.Printf("save order detail %s: %v", header.KodeInvoiceChannel, err)
+			dropshipLog.Error(ctx, "FetchOrderDetail", "Failed to save order detail", err, map[string]interface{}{
+				"invoice": header.KodeInvoiceChannel,
+			})
 		}
 	}
 	return total, nil
@@ -592,8 +685,8 @@ func (s *DropshipService) fetchAndStoreDetail(ctx context.Context, header *model
 
 // fetchAndStoreDetailBatch retrieves Shopee order details for multiple orders in a single API call.
 // It returns a map keyed by order_sn with summed original item prices for journal posting.
-func (s *DropshipService) fetchAndStoreDetailBatch(ctx context.Context, headers []*models.DropshipPurchase) (map[string]float64, error) {
-	res := make(map[string]float64)
+func (s *DropshipService) fetchAndStoreDetailBatch(ctx context.Context, headers []*models.DropshipPurchase) (map[string]money.Amount, error) {
+	res := make(map[string]money.Amount)
 	if len(headers) == 0 {
 		return res, nil
 	}
@@ -621,7 +714,7 @@ func (s *DropshipService) fetchAndStoreDetailBatch(ctx context.Context, headers
 	defer cancel()
 
 	details, err := s.client.FetchShopeeOrderDetails(apiCtx, *st.AccessToken, *st.ShopID, sns)
-	if err != nil && strings.Contains(err.Error(), "invalid_access_token") {
+	if errors.Is(err, apperr.ErrTokenExpired) {
 		if e := s.ensureStoreTokenValid(ctx, st); e == nil {
 			// Retry with fresh timeout context
 			apiCtx2, cancel2 := WithExternalAPITimeout(ctx)
@@ -637,15 +730,17 @@ func (s *DropshipService) fetchAndStoreDetailBatch(ctx context.Context, headers
 		snVal, _ := det["order_sn"].(string)
 		h := hdrMap[snVal]
 		row, items, packages := normalizeOrderDetail(snVal, storeName, det)
-		var total float64
+		var total money.Amount
 		for _, it := range items {
 			if it.ModelOriginalPrice != nil && it.ModelQuantityPurchased != nil {
-				total += *it.ModelOriginalPrice * float64(*it.ModelQuantityPurchased)
+				total += money.FromFloat(*it.ModelOriginalPrice).Mul(float64(*it.ModelQuantityPurchased))
 			}
 		}
 		if s.detailRepo != nil {
 			if err := s.detailRepo.SaveOrderDetail(ctx, row, items, packages); err != nil {
-				log.Printf("save order detail %s: %v", h.KodeInvoiceChannel, err)
+				dropshipLog.Error(ctx, "FetchOrderDetail", "Failed to save order detail", err, map[string]interface{}{
+					"invoice": h.KodeInvoiceChannel,
+				})
 			}
 		}
 		if h != nil {
@@ -661,7 +756,9 @@ func (s *DropshipService) ensureStoreTokenValid(ctx context.Context, st *models.
 	if s.client == nil || s.storeRepo == nil {
 		return fmt.Errorf("missing client or store repo")
 	}
-	log.Printf("ensureStoreTokenValid for store %d", st.StoreID)
+	dropshipLog.Debug(ctx, "EnsureStoreTokenValid", "Checking store token", map[string]interface{}{
+		"store_id": st.StoreID,
+	})
 	loc, _ := time.LoadLocation("Asia/Jakarta")
 	reinterpreted := time.Date(
 		st.LastUpdated.Year(), st.LastUpdated.Month(), st.LastUpdated.Day(),
@@ -670,19 +767,52 @@ func (s *DropshipService) ensureStoreTokenValid(ctx context.Context, st *models.
 	)
 	exp := reinterpreted.Add(time.Duration(*st.ExpireIn) * time.Second)
 	if st.RefreshToken == nil {
-		log.Fatalf("ensureStoreTokenValid: missing refresh token for store %d", st.StoreID)
-		return fmt.Errorf("missing refresh token")
+		dropshipLog.Error(ctx, "EnsureStoreTokenValid", "Store has no refresh token", apperr.ErrMissingCredentials, map[string]interface{}{
+			"store_id": st.StoreID,
+		})
+		return fmt.Errorf("%w: refresh token", apperr.ErrMissingCredentials)
 	}
 	if st.ShopID == nil || *st.ShopID == "" {
-		log.Fatalf("ensureStoreTokenValid: missing shop id for store %d", st.StoreID)
-		return fmt.Errorf("missing shop id")
+		dropshipLog.Error(ctx, "EnsureStoreTokenValid", "Store has no shop id", apperr.ErrMissingCredentials, map[string]interface{}{
+			"store_id": st.StoreID,
+		})
+		return fmt.Errorf("%w: shop id", apperr.ErrMissingCredentials)
 	}
 	if st.ExpireIn != nil && st.LastUpdated != nil {
 		if time.Now().Before(exp.Local()) {
-			log.Printf("Token for store %d is still valid until %v", st.StoreID, exp)
+			dropshipLog.Debug(ctx, "EnsureStoreTokenValid", "Store token still valid", map[string]interface{}{
+				"store_id":   st.StoreID,
+				"expires_at": exp,
+			})
 			return nil
 		}
 	}
+	return s.refreshStoreToken(ctx, st)
+}
+
+// RefreshStoreToken refreshes the Shopee access token of the named store even
+// if the current one has not expired, and saves it.
+func (s *DropshipService) RefreshStoreToken(ctx context.Context, name string) (*models.Store, error) {
+	if s.client == nil || s.storeRepo == nil {
+		return nil, fmt.Errorf("missing client or store repo")
+	}
+	st, err := s.storeRepo.GetStoreByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if st.RefreshToken == nil || *st.RefreshToken == "" {
+		return nil, fmt.Errorf("%w: refresh token", apperr.ErrMissingCredentials)
+	}
+	if st.ShopID == nil || *st.ShopID == "" {
+		return nil, fmt.Errorf("%w: shop id", apperr.ErrMissingCredentials)
+	}
+	if err := s.refreshStoreToken(ctx, st); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+func (s *DropshipService) refreshStoreToken(ctx context.Context, st *models.Store) error {
 	s.client.ShopID = *st.ShopID
 	s.client.RefreshToken = *st.RefreshToken
 	resp, err := s.client.RefreshAccessToken(ctx)
@@ -698,14 +828,36 @@ func (s *DropshipService) ensureStoreTokenValid(ctx context.Context, st *models.
 	now := time.Now()
 	st.LastUpdated = &now
 	if uerr := s.storeRepo.UpdateStore(ctx, st); uerr != nil {
-		log.Printf("update store token: %v", uerr)
+		dropshipLog.Error(ctx, "RefreshStoreToken", "Failed to save refreshed store token", uerr, map[string]interface{}{
+			"store_id": st.StoreID,
+		})
+	}
+	return nil
+}
+
+// purchaseTransitioner is implemented by repositories that record purchase
+// lifecycle changes.
+type purchaseTransitioner interface {
+	TransitionPurchaseStatus(ctx context.Context, kodePesanan string, c lifecycle.Change) error
+}
+
+// transitionPurchase moves a purchase to a new lifecycle state when repo
+// supports it. It runs in the import transaction, so a failure must abort
+// the import rather than leave a journal without its status.
+func transitionPurchase(ctx context.Context, repo interface{}, kodePesanan string, c lifecycle.Change) error {
+	tr, ok := repo.(purchaseTransitioner)
+	if !ok {
+		return nil
+	}
+	if err := tr.TransitionPurchaseStatus(ctx, kodePesanan, c); err != nil {
+		return fmt.Errorf("transition %s to %s: %w", kodePesanan, c.To, err)
 	}
 	return nil
 }
 
 // createPendingSalesJournal records pending receivable and sales using the
 // amounts derived from Shopee order detail when available.
-func (s *DropshipService) createPendingSalesJournal(ctx context.Context, jr DropshipJournalRepo, p *models.DropshipPurchase, totalProduk, pendingAmount float64) error {
+func (s *DropshipService) createPendingSalesJournal(ctx context.Context, jr DropshipJournalRepo, p *models.DropshipPurchase, totalProduk, pendingAmount money.Amount) error {
 	if jr == nil {
 		return nil
 	}
@@ -801,7 +953,8 @@ func saldoShopeeAccountID(store string) int64 {
 
 func freeSampleAccountID() int64 { return 55007 }
 
-func (s *DropshipService) createFreeSampleJournal(ctx context.Context, jr DropshipJournalRepo, p *models.DropshipPurchase, totalProduk float64) error {
+func (s *DropshipService) createFreeSampleJournal(ctx context.Context, jr DropshipJournalRepo, p *models.DropshipPurchase, totalProduk money.Amount) error {
+
 	if jr == nil {
 		return nil
 	}
@@ -838,7 +991,10 @@ func (s *DropshipService) BatchInsertPurchases(ctx context.Context, purchases []
 		return nil
 	}
 
-	log.Printf("BatchInsertPurchases: processing %d purchases in batches of %d", len(purchases), s.batchSize)
+	dropshipLog.Debug(ctx, "BatchInsertPurchases", "Inserting purchases", map[string]interface{}{
+		"purchases":  len(purchases),
+		"batch_size": s.batchSize,
+	})
 
 	tx, err := s.db.BeginTxx(ctx, nil)
 	if err != nil {
@@ -854,7 +1010,12 @@ func (s *DropshipService) BatchInsertPurchases(ctx context.Context, purchases []
 		}
 
 		batch := purchases[i:end]
-		log.Printf("Processing batch %d/%d (items %d-%d)", i/s.batchSize+1, (len(purchases)-1)/s.batchSize+1, i, end-1)
+		dropshipLog.Debug(ctx, "BatchInsertPurchases", "Inserting batch", map[string]interface{}{
+			"batch":   i/s.batchSize + 1,
+			"batches": (len(purchases)-1)/s.batchSize + 1,
+			"from":    i,
+			"to":      end - 1,
+		})
 
 		for _, purchase := range batch {
 			if err := s.repo.InsertDropshipPurchase(ctx, purchase); err != nil {
@@ -867,7 +1028,9 @@ func (s *DropshipService) BatchInsertPurchases(ctx context.Context, purchases []
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	log.Printf("Successfully processed %d purchases", len(purchases))
+	dropshipLog.Info(ctx, "BatchInsertPurchases", "Purchases inserted", map[string]interface{}{
+		"purchases": len(purchases),
+	})
 	return nil
 }
 
@@ -888,13 +1051,17 @@ func (s *DropshipService) GetCachedPurchaseData(ctx context.Context, channel, st
 			Total     int                       `json:"total"`
 		}
 		if err := json.Unmarshal(data, &cached); err == nil {
-			log.Printf("Cache hit for key: %s", cacheKey)
+			dropshipLog.Debug(ctx, "GetCachedPurchaseData", "Cache hit", map[string]interface{}{
+				"key": cacheKey,
+			})
 			return cached.Purchases, cached.Total, nil
 		}
 	}
 
 	// Cache miss - fetch from database
-	log.Printf("Cache miss for key: %s", cacheKey)
+	dropshipLog.Debug(ctx, "GetCachedPurchaseData", "Cache miss", map[string]interface{}{
+		"key": cacheKey,
+	})
 	purchases, total, err := s.repo.ListDropshipPurchases(ctx, channel, store, from, to, "", "kode_pesanan", "asc", limit, offset)
 	if err != nil {
 		return nil, 0, err
@@ -911,32 +1078,40 @@ func (s *DropshipService) GetCachedPurchaseData(ctx context.Context, channel, st
 
 	if data, err := json.Marshal(cached); err == nil {
 		// Cache for 5 minutes by default
-		if err := s.cache.Set(ctx, cacheKey, data, 5*time.Minute); err != nil {
-			log.Printf("Failed to cache data for key %s: %v", cacheKey, err)
+		if err := s.cache.SetWithTags(ctx, cacheKey, data, 5*time.Minute, cache.TagPurchases); err != nil {
+			dropshipLog.Warn(ctx, "GetCachedPurchaseData", "Failed to cache purchases", map[string]interface{}{
+				"key":   cacheKey,
+				"error": err,
+			})
 		}
 	}
 
 	return purchases, total, nil
 }
 
-// InvalidatePurchaseCache removes cached purchase data when data changes
-func (s *DropshipService) InvalidatePurchaseCache(ctx context.Context, patterns ...string) error {
+// InvalidatePurchaseCache removes cached purchase data when data changes.
+// Each prefix removes every key starting with it, e.g. "purchases:" drops
+// all cached purchase pages. With no prefixes every entry tagged as
+// purchase data is removed.
+func (s *DropshipService) InvalidatePurchaseCache(ctx context.Context, prefixes ...string) error {
 	if s.cache == nil {
 		return nil
 	}
-
-	// For now, we'll invalidate based on patterns
-	// In a production system, you might want to use Redis SCAN or maintain cache key sets
-	for _, pattern := range patterns {
-		if err := s.cache.Delete(ctx, pattern); err != nil {
-			log.Printf("Failed to invalidate cache pattern %s: %v", pattern, err)
+	if len(prefixes) == 0 {
+		return s.cache.InvalidateTags(ctx, cache.TagPurchases)
+	}
+	for _, prefix := range prefixes {
+		if err := s.cache.DeleteByPrefix(ctx, prefix); err != nil {
+			dropshipLog.Error(ctx, "InvalidatePurchaseCache", "Failed to invalidate cache prefix", err, map[string]interface{}{
+				"prefix": prefix,
+			})
 		}
 	}
 	return nil
 }
 
 // GetPurchaseSummaryCache retrieves cached purchase summary data
-func (s *DropshipService) GetPurchaseSummaryCache(ctx context.Context, channel, store, from, to string) (float64, error) {
+func (s *DropshipService) GetPurchaseSummaryCache(ctx context.Context, channel, store, from, to string) (money.Amount, error) {
 	if s.cache == nil {
 		return s.repo.SumDropshipPurchases(ctx, channel, store, from, to)
 	}
@@ -945,7 +1120,7 @@ func (s *DropshipService) GetPurchaseSummaryCache(ctx context.Context, channel,
 
 	// Try cache first
 	if data, err := s.cache.Get(ctx, cacheKey); err == nil {
-		if total, err := strconv.ParseFloat(string(data), 64); err == nil {
+		if total, err := money.Parse(string(data)); err == nil {
 			return total, nil
 		}
 	}
@@ -957,8 +1132,11 @@ func (s *DropshipService) GetPurchaseSummaryCache(ctx context.Context, channel,
 	}
 
 	// Cache the result
-	if err := s.cache.Set(ctx, cacheKey, []byte(fmt.Sprintf("%.2f", total)), 5*time.Minute); err != nil {
-		log.Printf("Failed to cache purchase summary: %v", err)
+	if err := s.cache.SetWithTags(ctx, cacheKey, []byte(total.String()), 5*time.Minute, cache.TagPurchases, cache.TagReports); err != nil {
+		dropshipLog.Warn(ctx, "GetPurchaseSummaryCache", "Failed to cache purchase summary", map[string]interface{}{
+			"key":   cacheKey,
+			"error": err,
+		})
 	}
 
 	return total, nil
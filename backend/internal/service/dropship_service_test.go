@@ -6,6 +6,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -15,7 +16,9 @@ import (
 	"time"
 
 	"github.com/ramadhan22/dropship-erp/backend/internal/config"
+	"github.com/ramadhan22/dropship-erp/backend/internal/lifecycle"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
 )
 
@@ -72,7 +75,7 @@ func (f *fakeDropshipRepo) ListDropshipPurchaseDetails(ctx context.Context, kode
 	return nil, nil
 }
 
-func (f *fakeDropshipRepo) SumDropshipPurchases(ctx context.Context, channel, store, from, to string) (float64, error) {
+func (f *fakeDropshipRepo) SumDropshipPurchases(ctx context.Context, channel, store, from, to string) (money.Amount, error) {
 	return 0, nil
 }
 
@@ -88,6 +91,10 @@ func (f *fakeDropshipRepo) MonthlyTotals(ctx context.Context, channel, store, fr
 	return nil, nil
 }
 
+func (f *fakeDropshipRepo) ListPurchaseStatusHistory(ctx context.Context, kodePesanan string) ([]models.PurchaseStatusEvent, error) {
+	return nil, nil
+}
+
 func (f *fakeDropshipRepo) CancelledSummary(ctx context.Context, channel, store, from, to string) (repository.CancelledSummary, error) {
 	return repository.CancelledSummary{}, nil
 }
@@ -142,7 +149,7 @@ func (f *fakeJournalRepoDrop) GetLinesByJournalID(ctx context.Context, id int64)
 	}
 	return res, nil
 }
-func (f *fakeJournalRepoDrop) UpdateJournalLineAmount(ctx context.Context, lineID int64, amount float64) error {
+func (f *fakeJournalRepoDrop) UpdateJournalLineAmount(ctx context.Context, lineID int64, amount money.Amount) error {
 	for _, l := range f.lines {
 		if l.LineID == lineID {
 			l.Amount = amount
@@ -255,6 +262,27 @@ func TestImportFromCSV_ParseError(t *testing.T) {
 	}
 }
 
+func TestImportFromCSV_AmountParseError(t *testing.T) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"No", "waktu", "status", "kode", "trx", "sku", "nama", "harga", "qty", "total_harga", "biaya_lain", "biaya_mitra", "total_transaksi", "harga_ch", "total_harga_ch", "potensi", "dibuat", "channel", "toko", "invoice", "gudang", "ekspedisi", "cashless", "resi", "waktu_kirim", "provinsi", "kota"})
+	w.Write([]string{"1", "01 January 2025, 10:00:00", "selesai", "PS-457", "TRX1", "SKU2", "ProdukB", "15.00", "2", "30", "1", "0.5", "31,5x", "15", "30", "2", "user", "online", "Shop", "INV", "G", "JNE", "Ya", "RESI", "02 January 2025, 10:00:00", "Jawa", "Bandung"})
+	w.Flush()
+
+	fake := &fakeDropshipRepo{}
+	svc := NewDropshipService(nil, fake, nil, nil, nil, nil, nil, nil, 5, 100)
+	count, err := svc.ImportFromCSV(context.Background(), &buf, "", 0)
+	if err != nil {
+		t.Fatalf("ImportFromCSV error: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("expected count 0, got %d", count)
+	}
+	if len(fake.insertedHeader) != 0 {
+		t.Errorf("expected no inserts, got %d", len(fake.insertedHeader))
+	}
+}
+
 func TestImportFromCSV_SkipExisting(t *testing.T) {
 	var buf bytes.Buffer
 	w := csv.NewWriter(&buf)
@@ -332,7 +360,7 @@ func TestImportFromCSV_JournalSumsProducts(t *testing.T) {
 		t.Fatalf("expected 1 journal entry, got %d", len(jfake.entries))
 	}
 
-	var hpp, sales float64
+	var hpp, sales money.Amount
 	for _, l := range jfake.lines {
 		if l.AccountID == 5001 {
 			hpp = l.Amount
@@ -341,11 +369,11 @@ func TestImportFromCSV_JournalSumsProducts(t *testing.T) {
 			sales = l.Amount
 		}
 	}
-	if hpp != 51.5 {
-		t.Errorf("expected HPP 51.5, got %.2f", hpp)
+	if hpp != money.MustParse("51.5") {
+		t.Errorf("expected HPP 51.5, got %s", hpp)
 	}
-	if sales != 51.5 {
-		t.Errorf("expected sales 51.5, got %.2f", sales)
+	if sales != money.MustParse("51.5") {
+		t.Errorf("expected sales 51.5, got %s", sales)
 	}
 }
 
@@ -451,6 +479,32 @@ func TestImportFromCSV_FreeSample(t *testing.T) {
 	}
 }
 
+// failingTransitionRepo rejects every lifecycle transition.
+type failingTransitionRepo struct {
+	*fakeDropshipRepo
+}
+
+func (f failingTransitionRepo) TransitionPurchaseStatus(ctx context.Context, kodePesanan string, c lifecycle.Change) error {
+	return os.ErrInvalid
+}
+
+func TestImportFromCSV_TransitionErrorFailsImport(t *testing.T) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	headers := []string{"No", "waktu", "status", "kode", "trx", "sku", "nama", "harga", "qty", "total_harga", "biaya_lain", "biaya_mitra", "total_transaksi", "harga_ch", "total_harga_ch", "potensi", "dibuat", "channel", "toko", "invoice", "gudang", "ekspedisi", "cashless", "resi", "waktu_kirim", "provinsi", "kota"}
+	w.Write(headers)
+	row := []string{"1", "01 January 2025, 10:00:00", "selesai", "PS-FS", "TRX1", "SKU1", "ProdukA", "10", "1", "10", "0", "0", "10", "10", "10", "0", "user", "online", "MR eStore Free Sample", "INVFS", "Gudang", "JNE", "Ya", "RESI", "02 January 2025, 10:00:00", "Jawa", "Bandung"}
+	w.Write(row)
+	w.Flush()
+
+	repo := failingTransitionRepo{&fakeDropshipRepo{}}
+	svc := NewDropshipService(nil, repo, &fakeJournalRepoDrop{}, nil, nil, nil, nil, nil, 5, 100)
+
+	if _, err := svc.ImportFromCSV(context.Background(), &buf, "", 0); !errors.Is(err, os.ErrInvalid) {
+		t.Fatalf("expected transition error, got %v", err)
+	}
+}
+
 func TestImportFromCSV_CleanSingleQuoteInKodeInvoiceChannel(t *testing.T) {
 	var buf bytes.Buffer
 	w := csv.NewWriter(&buf)
@@ -3,11 +3,11 @@ package service
 import (
 	"context"
 	"fmt"
-	"log"
 	"sort"
 	"sync"
 	"time"
 
+	"github.com/ramadhan22/dropship-erp/backend/internal/logutil"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
 )
 
@@ -24,6 +24,10 @@ type EnhancedImportScheduler struct {
 	workers            int
 	ctx                context.Context
 	cancel             context.CancelFunc
+	stopOnce           sync.Once
+	stop               chan struct{}
+	wg                 sync.WaitGroup
+	done               chan struct{}
 }
 
 // ImportJob represents a single import job
@@ -81,46 +85,90 @@ func NewEnhancedImportScheduler(
 		workers:            maxConcurrentFiles,
 		ctx:                ctx,
 		cancel:             cancel,
+		stop:               make(chan struct{}),
+		done:               make(chan struct{}),
 	}
 }
 
-// Start launches the enhanced scheduler
-func (s *EnhancedImportScheduler) Start() {
+// Start launches the enhanced scheduler. Imports run with ctx, so
+// cancelling it aborts the files being imported.
+func (s *EnhancedImportScheduler) Start(ctx context.Context) {
 	if s == nil {
 		return
 	}
 
-	log.Printf("Starting enhanced import scheduler with %d workers", s.workers)
+	importLog.Info(ctx, "EnhancedImportScheduler", "Starting enhanced import scheduler", map[string]interface{}{
+		"workers": s.workers,
+	})
+	s.ctx, s.cancel = context.WithCancel(ctx)
 
 	// Start worker goroutines
 	for i := 0; i < s.workers; i++ {
-		go s.worker(i)
+		s.goTracked(func() { s.worker(i) })
 	}
 
 	// Start job discovery goroutine
-	go s.jobDiscovery()
+	s.goTracked(s.jobDiscovery)
 
 	// Start cleanup goroutine
-	go s.cleanup()
+	s.goTracked(s.cleanup)
+
+	go func() {
+		s.wg.Wait()
+		close(s.done)
+	}()
 }
 
-// Stop stops the scheduler
+func (s *EnhancedImportScheduler) goTracked(fn func()) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		fn()
+	}()
+}
+
+// Stop stops the scheduler from taking new jobs. Jobs being imported run to
+// completion; queued jobs stay pending in batch_history.
 func (s *EnhancedImportScheduler) Stop() {
-	if s.cancel != nil {
-		s.cancel()
+	s.stopOnce.Do(func() { close(s.stop) })
+}
+
+// Done is closed once every worker has returned.
+func (s *EnhancedImportScheduler) Done() <-chan struct{} {
+	return s.done
+}
+
+func (s *EnhancedImportScheduler) stopping() bool {
+	select {
+	case <-s.stop:
+		return true
+	default:
+		return false
 	}
 }
 
 // worker processes import jobs from the queue
 func (s *EnhancedImportScheduler) worker(workerID int) {
-	log.Printf("Worker %d started", workerID)
+	importLog.Debug(s.ctx, "ImportWorker", "Worker started", map[string]interface{}{
+		"worker_id": workerID,
+	})
 
 	for {
 		select {
 		case <-s.ctx.Done():
-			log.Printf("Worker %d stopped", workerID)
+			importLog.Debug(s.ctx, "ImportWorker", "Worker stopped", map[string]interface{}{
+				"worker_id": workerID,
+			})
+			return
+		case <-s.stop:
+			importLog.Debug(s.ctx, "ImportWorker", "Worker stopped", map[string]interface{}{
+				"worker_id": workerID,
+			})
 			return
 		case job := <-s.jobQueue:
+			if s.stopping() {
+				return
+			}
 			s.processJob(workerID, job)
 		}
 	}
@@ -135,6 +183,8 @@ func (s *EnhancedImportScheduler) jobDiscovery() {
 		select {
 		case <-s.ctx.Done():
 			return
+		case <-s.stop:
+			return
 		case <-ticker.C:
 			s.discoverPendingJobs()
 		}
@@ -146,14 +196,14 @@ func (s *EnhancedImportScheduler) discoverPendingJobs() {
 	// Get pending dropship imports
 	pendingDropship, err := s.batch.ListPendingByType(s.ctx, "dropship_import")
 	if err != nil {
-		log.Printf("Error getting pending dropship imports: %v", err)
+		importLog.Error(s.ctx, "DiscoverPendingJobs", "Failed to list pending dropship imports", err)
 		return
 	}
 
 	// Get pending streaming imports
 	pendingStreaming, err := s.batch.ListPendingByType(s.ctx, "streaming_dropship_import")
 	if err != nil {
-		log.Printf("Error getting pending streaming imports: %v", err)
+		importLog.Error(s.ctx, "DiscoverPendingJobs", "Failed to list pending streaming imports", err)
 		return
 	}
 
@@ -170,9 +220,14 @@ func (s *EnhancedImportScheduler) discoverPendingJobs() {
 		if !isActive {
 			select {
 			case s.jobQueue <- job:
-				log.Printf("Queued job %d: %s", job.BatchID, job.FilePath)
+				importLog.Debug(s.ctx, "DiscoverPendingJobs", "Queued import job", map[string]interface{}{
+					"batch_id": job.BatchID,
+					"file":     job.FilePath,
+				})
 			default:
-				log.Printf("Job queue full, skipping job %d", job.BatchID)
+				importLog.Warn(s.ctx, "DiscoverPendingJobs", "Job queue full, skipping job", map[string]interface{}{
+					"batch_id": job.BatchID,
+				})
 			}
 		}
 	}
@@ -224,7 +279,13 @@ func (s *EnhancedImportScheduler) calculatePriority(batch models.BatchHistory) i
 
 // processJob processes a single import job
 func (s *EnhancedImportScheduler) processJob(workerID int, job *ImportJob) {
-	log.Printf("Worker %d processing job %d: %s", workerID, job.BatchID, job.FilePath)
+	ctx, span := startBatchSpan(logutil.WithNewCorrelationID(s.ctx), "import_job", job.BatchID)
+	defer span.End()
+	importLog.Info(ctx, "ProcessImportJob", "Processing import job", map[string]interface{}{
+		"worker_id": workerID,
+		"batch_id":  job.BatchID,
+		"file":      job.FilePath,
+	})
 
 	// Mark job as active
 	s.mu.Lock()
@@ -234,18 +295,20 @@ func (s *EnhancedImportScheduler) processJob(workerID int, job *ImportJob) {
 	s.mu.Unlock()
 
 	// Update batch status
-	if err := s.batch.UpdateStatus(s.ctx, job.BatchID, "processing", ""); err != nil {
-		log.Printf("Error updating batch status: %v", err)
+	if err := s.batch.UpdateStatus(ctx, job.BatchID, "processing", ""); err != nil {
+		importLog.Error(ctx, "ProcessImportJob", "Failed to mark batch processing", err, map[string]interface{}{
+			"batch_id": job.BatchID,
+		})
 	}
 
 	// Process the file
 	var err error
 	if s.streamingProcessor != nil {
 		// Use streaming processor for better performance
-		err = s.streamingProcessor.processFileWithStreaming(s.ctx, job.FilePath, job.Channel)
+		err = s.streamingProcessor.processFileWithStreaming(ctx, job.FilePath, job.Channel)
 	} else {
 		// Fallback to original processor
-		s.dropshipService.ProcessImportFile(s.ctx, job.BatchID, job.FilePath, job.Channel)
+		s.dropshipService.ProcessImportFile(ctx, job.BatchID, job.FilePath, job.Channel)
 	}
 
 	// Update job status
@@ -254,15 +317,20 @@ func (s *EnhancedImportScheduler) processJob(workerID int, job *ImportJob) {
 	if err != nil {
 		job.Status = "failed"
 		job.Error = err
-		s.batch.UpdateStatus(s.ctx, job.BatchID, "failed", err.Error())
+		s.batch.UpdateStatus(ctx, job.BatchID, "failed", err.Error())
 	} else {
 		job.Status = "completed"
-		s.batch.UpdateStatus(s.ctx, job.BatchID, "completed", "")
+		s.batch.UpdateStatus(ctx, job.BatchID, "completed", "")
 	}
 	s.mu.Unlock()
 
 	duration := job.CompletedAt.Sub(job.StartedAt)
-	log.Printf("Worker %d completed job %d in %v (status: %s)", workerID, job.BatchID, duration, job.Status)
+	importLog.Info(ctx, "ProcessImportJob", "Import job finished", map[string]interface{}{
+		"worker_id":   workerID,
+		"batch_id":    job.BatchID,
+		"status":      job.Status,
+		"duration_ms": duration.Milliseconds(),
+	})
 }
 
 // cleanup removes completed jobs from active jobs map
@@ -274,6 +342,8 @@ func (s *EnhancedImportScheduler) cleanup() {
 		select {
 		case <-s.ctx.Done():
 			return
+		case <-s.stop:
+			return
 		case <-ticker.C:
 			s.cleanupCompletedJobs()
 		}
@@ -379,7 +449,10 @@ func (s *EnhancedImportScheduler) ForceProcessBatch(batchID int64) error {
 
 	select {
 	case s.jobQueue <- job:
-		log.Printf("Force-queued job %d: %s", job.BatchID, job.FilePath)
+		importLog.Info(s.ctx, "ForceProcessBatch", "Force-queued import job", map[string]interface{}{
+			"batch_id": job.BatchID,
+			"file":     job.FilePath,
+		})
 		return nil
 	default:
 		return fmt.Errorf("job queue is full")
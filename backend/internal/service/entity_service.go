@@ -0,0 +1,417 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
+	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
+)
+
+const (
+	icReceivableAcctID int64 = 11015 // Piutang Antar Perusahaan
+	icPayableAcctID    int64 = 21007 // Utang Antar Perusahaan
+)
+
+// ErrInvalidEntity is returned for entities and intercompany transactions
+// that fail validation.
+var ErrInvalidEntity = errors.New("invalid entity")
+
+// EntityStore is the part of repository.EntityRepo used by EntityService.
+type EntityStore interface {
+	CreateEntity(ctx context.Context, e *models.Entity) error
+	UpdateEntity(ctx context.Context, e *models.Entity) error
+	DeleteEntity(ctx context.Context, id int64) error
+	GetEntity(ctx context.Context, id int64) (*models.Entity, error)
+	ListEntities(ctx context.Context) ([]models.Entity, error)
+	AssignStore(ctx context.Context, store string, entityID int64) error
+	ListStores(ctx context.Context, entityID int64) ([]string, error)
+	ListAccounts(ctx context.Context, entityID int64) ([]models.Account, error)
+	GetAccount(ctx context.Context, id int64) (*models.Account, error)
+	IntercompanyAccountIDs(ctx context.Context) ([]int64, error)
+	CreateIntercompany(ctx context.Context, t *models.IntercompanyTransaction) error
+	GetIntercompany(ctx context.Context, id int64) (*models.IntercompanyTransaction, error)
+	ListIntercompany(ctx context.Context, entityID int64, from, to time.Time) ([]models.IntercompanyTransaction, error)
+	MarkIntercompanyReversed(ctx context.Context, id int64, at time.Time) error
+}
+
+// EntityLedger returns journal balance queries limited to one entity.
+type EntityLedger func(entityID int64) FinancialStatementJournalRepo
+
+// ConsolidatedRow is one account of the consolidated statement. Amounts are
+// debit positive: balances as of the end date for balance sheet accounts
+// and the period's movement for revenue and expense accounts. Entities
+// lines up with ConsolidatedReport.Entities.
+type ConsolidatedRow struct {
+	AccountID    int64          `json:"account_id"`
+	AccountCode  string         `json:"account_code"`
+	AccountName  string         `json:"account_name"`
+	AccountType  string         `json:"account_type"`
+	Intercompany bool           `json:"intercompany,omitempty"`
+	Entities     []money.Amount `json:"entities"`
+	Elimination  money.Amount   `json:"elimination"`
+	Consolidated money.Amount   `json:"consolidated"`
+}
+
+// ConsolidatedReport combines the books of every entity and eliminates the
+// intercompany accounts. Unmatched is what the eliminations do not net to;
+// it is zero when every intercompany balance has its mirror.
+type ConsolidatedReport struct {
+	From      time.Time         `json:"from"`
+	To        time.Time         `json:"to"`
+	Entities  []models.Entity   `json:"entities"`
+	Rows      []ConsolidatedRow `json:"rows"`
+	NetIncome []money.Amount    `json:"net_income"`
+	// ConsolidatedNetIncome is credit positive, like NetIncome.
+	ConsolidatedNetIncome money.Amount `json:"consolidated_net_income"`
+	Unmatched             money.Amount `json:"unmatched"`
+}
+
+// EntityService manages entities and their stores, reports on one entity's
+// books and posts intercompany transactions into both sides.
+type EntityService struct {
+	db          *sqlx.DB
+	repo        EntityStore
+	journalRepo ExpenseJournalRepo
+	ledger      EntityLedger
+	cash        CashAccountSource
+	cache       Cache
+	now         func() time.Time
+}
+
+// NewEntityService constructs an EntityService.
+func NewEntityService(db *sqlx.DB, repo EntityStore, jr ExpenseJournalRepo, ledger EntityLedger, cash CashAccountSource) *EntityService {
+	return &EntityService{db: db, repo: repo, journalRepo: jr, ledger: ledger, cash: cash, now: time.Now}
+}
+
+// SetCache invalidates cached reports when intercompany journals are
+// posted.
+func (s *EntityService) SetCache(c Cache) { s.cache = c }
+
+// ListEntities returns every entity.
+func (s *EntityService) ListEntities(ctx context.Context) ([]models.Entity, error) {
+	return s.repo.ListEntities(ctx)
+}
+
+// GetEntity returns an entity.
+func (s *EntityService) GetEntity(ctx context.Context, id int64) (*models.Entity, error) {
+	return s.repo.GetEntity(ctx, id)
+}
+
+// CreateEntity validates and saves a new entity.
+func (s *EntityService) CreateEntity(ctx context.Context, e *models.Entity) error {
+	if err := validateEntity(e); err != nil {
+		return err
+	}
+	return s.repo.CreateEntity(ctx, e)
+}
+
+// UpdateEntity validates and saves an entity.
+func (s *EntityService) UpdateEntity(ctx context.Context, e *models.Entity) error {
+	if err := validateEntity(e); err != nil {
+		return err
+	}
+	return s.repo.UpdateEntity(ctx, e)
+}
+
+// DeleteEntity removes an entity that owns nothing. The default entity
+// cannot be removed.
+func (s *EntityService) DeleteEntity(ctx context.Context, id int64) error {
+	e, err := s.repo.GetEntity(ctx, id)
+	if err != nil {
+		return err
+	}
+	if e.IsDefault {
+		return fmt.Errorf("%w: the default entity cannot be deleted", ErrInvalidEntity)
+	}
+	stores, err := s.repo.ListStores(ctx, id)
+	if err != nil {
+		return err
+	}
+	if len(stores) > 0 {
+		return fmt.Errorf("%w: entity %s still owns %d store(s)", ErrInvalidEntity, e.Code, len(stores))
+	}
+	return s.repo.DeleteEntity(ctx, id)
+}
+
+func validateEntity(e *models.Entity) error {
+	e.Code = strings.ToUpper(strings.TrimSpace(e.Code))
+	e.Name = strings.TrimSpace(e.Name)
+	if e.Code == "" || e.Name == "" {
+		return fmt.Errorf("%w: code and name are required", ErrInvalidEntity)
+	}
+	if len(e.Code) > 20 {
+		return fmt.Errorf("%w: code is longer than 20 characters", ErrInvalidEntity)
+	}
+	return nil
+}
+
+// AssignStore moves a store to an entity. Its future journals are booked
+// in the entity; earlier ones stay where they are.
+func (s *EntityService) AssignStore(ctx context.Context, entityID int64, store string) error {
+	if _, err := s.repo.GetEntity(ctx, entityID); err != nil {
+		return err
+	}
+	return s.repo.AssignStore(ctx, store, entityID)
+}
+
+// ListStores returns the stores an entity owns.
+func (s *EntityService) ListStores(ctx context.Context, entityID int64) ([]string, error) {
+	return s.repo.ListStores(ctx, entityID)
+}
+
+// ListAccounts returns an entity's chart of accounts: the shared accounts
+// and those created for the entity.
+func (s *EntityService) ListAccounts(ctx context.Context, entityID int64) ([]models.Account, error) {
+	return s.repo.ListAccounts(ctx, entityID)
+}
+
+// BalanceSheet returns an entity's balance sheet as of asOf.
+func (s *EntityService) BalanceSheet(ctx context.Context, entityID int64, asOf time.Time) ([]CategoryBalance, error) {
+	return NewBalanceService(s.ledger(entityID)).GetBalanceSheet(ctx, "", asOf)
+}
+
+// ProfitLoss returns an entity's profit and loss for a month or year, as
+// ProfitLossReportService does for stores.
+func (s *EntityService) ProfitLoss(ctx context.Context, entityID int64, typ string, month, year int) (*ProfitLoss, error) {
+	return NewProfitLossReportService(s.ledger(entityID)).GetProfitLoss(ctx, typ, month, year, "", false)
+}
+
+// TrialBalance returns an entity's trial balance for a period.
+func (s *EntityService) TrialBalance(ctx context.Context, entityID int64, from, to time.Time) (*TrialBalance, error) {
+	return NewFinancialStatementService(s.ledger(entityID), s.cash).GetTrialBalance(ctx, "", from, to, false)
+}
+
+// Consolidated combines every entity's books for a period and eliminates
+// the intercompany accounts.
+func (s *EntityService) Consolidated(ctx context.Context, from, to time.Time) (*ConsolidatedReport, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: period ends before it starts", ErrInvalidEntity)
+	}
+	entities, err := s.repo.ListEntities(ctx)
+	if err != nil {
+		return nil, err
+	}
+	icIDs, err := s.repo.IntercompanyAccountIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ic := make(map[int64]bool, len(icIDs))
+	for _, id := range icIDs {
+		ic[id] = true
+	}
+
+	rep := &ConsolidatedReport{From: from, To: to, Entities: entities, NetIncome: make([]money.Amount, len(entities))}
+	rows := map[int64]*ConsolidatedRow{}
+	for i, e := range entities {
+		jr := s.ledger(e.ID)
+		closing, err := jr.GetAccountBalancesAsOf(ctx, "", to)
+		if err != nil {
+			return nil, err
+		}
+		period, err := jr.GetAccountBalancesBetween(ctx, "", from, to)
+		if err != nil {
+			return nil, err
+		}
+		movement := make(map[int64]money.Amount, len(period))
+		for _, b := range period {
+			movement[b.AccountID] = b.Balance
+		}
+		for _, b := range closing {
+			amt := b.Balance
+			if isProfitAccount(b.AccountType) {
+				amt = movement[b.AccountID]
+				rep.NetIncome[i] -= amt
+			}
+			row, ok := rows[b.AccountID]
+			if !ok {
+				row = &ConsolidatedRow{
+					AccountID:    b.AccountID,
+					AccountCode:  b.AccountCode,
+					AccountName:  b.AccountName,
+					AccountType:  b.AccountType,
+					Intercompany: ic[b.AccountID],
+					Entities:     make([]money.Amount, len(entities)),
+				}
+				rows[b.AccountID] = row
+			}
+			row.Entities[i] = amt
+		}
+	}
+
+	for _, row := range rows {
+		var total money.Amount
+		for _, a := range row.Entities {
+			total += a
+		}
+		if total == 0 && !row.Intercompany {
+			continue
+		}
+		if row.Intercompany {
+			row.Elimination = -total
+			rep.Unmatched += row.Elimination
+		}
+		row.Consolidated = total + row.Elimination
+		if isProfitAccount(row.AccountType) {
+			rep.ConsolidatedNetIncome -= row.Consolidated
+		}
+		rep.Rows = append(rep.Rows, *row)
+	}
+	sort.Slice(rep.Rows, func(i, j int) bool { return rep.Rows[i].AccountCode < rep.Rows[j].AccountCode })
+	if rep.Rows == nil {
+		rep.Rows = []ConsolidatedRow{}
+	}
+	return rep, nil
+}
+
+func isProfitAccount(accountType string) bool {
+	return accountType == "Revenue" || accountType == "Expense"
+}
+
+// ListIntercompany returns intercompany transactions in a period, for one
+// entity or all when entityID is zero.
+func (s *EntityService) ListIntercompany(ctx context.Context, entityID int64, from, to time.Time) ([]models.IntercompanyTransaction, error) {
+	return s.repo.ListIntercompany(ctx, entityID, from, to)
+}
+
+// CreateIntercompany records t and posts it in both entities: the sender
+// debits its intercompany receivable and credits t.FromAccountID, the
+// receiver debits t.ToAccountID and credits its intercompany payable.
+func (s *EntityService) CreateIntercompany(ctx context.Context, t *models.IntercompanyTransaction) error {
+	if err := s.validateIntercompany(ctx, t); err != nil {
+		return err
+	}
+	err := s.inTx(ctx, func(repo EntityStore, jr ExpenseJournalRepo) error {
+		if err := repo.CreateIntercompany(ctx, t); err != nil {
+			return err
+		}
+		return postIntercompany(ctx, jr, t, t.TxnDate, false)
+	})
+	if err != nil {
+		return err
+	}
+	invalidateCache(ctx, s.cache, journalWriteTags...)
+	return nil
+}
+
+// ReverseIntercompany posts the opposite of both journals of a transaction
+// dated today.
+func (s *EntityService) ReverseIntercompany(ctx context.Context, id int64) (*models.IntercompanyTransaction, error) {
+	t, err := s.repo.GetIntercompany(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if t.ReversedAt != nil {
+		return nil, fmt.Errorf("%w: intercompany transaction %d is already reversed", ErrInvalidEntity, id)
+	}
+	now := s.now()
+	err = s.inTx(ctx, func(repo EntityStore, jr ExpenseJournalRepo) error {
+		if err := postIntercompany(ctx, jr, t, now, true); err != nil {
+			return err
+		}
+		return repo.MarkIntercompanyReversed(ctx, t.ID, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+	t.ReversedAt = &now
+	invalidateCache(ctx, s.cache, journalWriteTags...)
+	return t, nil
+}
+
+func (s *EntityService) validateIntercompany(ctx context.Context, t *models.IntercompanyTransaction) error {
+	if t.FromEntityID == t.ToEntityID {
+		return fmt.Errorf("%w: an intercompany transaction needs two different entities", ErrInvalidEntity)
+	}
+	if t.Amount <= 0 {
+		return fmt.Errorf("%w: amount must be positive", ErrInvalidEntity)
+	}
+	if t.TxnDate.IsZero() {
+		return fmt.Errorf("%w: txn_date is required", ErrInvalidEntity)
+	}
+	for _, id := range []int64{t.FromEntityID, t.ToEntityID} {
+		if _, err := s.repo.GetEntity(ctx, id); err != nil {
+			return fmt.Errorf("%w: entity %d: %v", ErrInvalidEntity, id, err)
+		}
+	}
+	sides := []struct {
+		accountID, entityID int64
+	}{{t.FromAccountID, t.FromEntityID}, {t.ToAccountID, t.ToEntityID}}
+	for _, side := range sides {
+		a, err := s.repo.GetAccount(ctx, side.accountID)
+		if err != nil {
+			return fmt.Errorf("%w: account %d: %v", ErrInvalidEntity, side.accountID, err)
+		}
+		if a.EntityID != nil && *a.EntityID != side.entityID {
+			return fmt.Errorf("%w: account %s belongs to another entity", ErrInvalidEntity, a.AccountCode)
+		}
+	}
+	return nil
+}
+
+// postIntercompany posts the sender's and the receiver's journal of t,
+// swapping every side when reverse is set.
+func postIntercompany(ctx context.Context, jr ExpenseJournalRepo, t *models.IntercompanyTransaction, date time.Time, reverse bool) error {
+	sourceType := "intercompany"
+	desc := "Antar perusahaan " + strconv.FormatInt(t.ID, 10)
+	if reverse {
+		sourceType = "intercompany_reverse"
+		desc = "Batal antar perusahaan " + strconv.FormatInt(t.ID, 10)
+	}
+	if t.Description != "" {
+		desc += ": " + t.Description
+	}
+	sides := []struct {
+		suffix        string
+		entityID      int64
+		debit, credit int64
+	}{
+		{"from", t.FromEntityID, icReceivableAcctID, t.FromAccountID},
+		{"to", t.ToEntityID, t.ToAccountID, icPayableAcctID},
+	}
+	for _, side := range sides {
+		entityID := side.entityID
+		je := &models.JournalEntry{
+			EntryDate:   date,
+			Description: &desc,
+			SourceType:  sourceType,
+			SourceID:    fmt.Sprintf("%d-%s", t.ID, side.suffix),
+			EntityID:    &entityID,
+			CreatedAt:   time.Now(),
+		}
+		jid, err := jr.CreateJournalEntry(ctx, je)
+		if err != nil {
+			return err
+		}
+		lines := []models.JournalLine{
+			{JournalID: jid, AccountID: side.debit, IsDebit: !reverse, Amount: t.Amount, Memo: &desc},
+			{JournalID: jid, AccountID: side.credit, IsDebit: reverse, Amount: t.Amount, Memo: &desc},
+		}
+		if err := jr.InsertJournalLines(ctx, lines); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *EntityService) inTx(ctx context.Context, fn func(EntityStore, ExpenseJournalRepo) error) error {
+	if s.db == nil {
+		return fn(s.repo, s.journalRepo)
+	}
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := fn(repository.NewEntityRepo(tx), repository.NewJournalRepo(tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
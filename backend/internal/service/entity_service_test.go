@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
+)
+
+type fakeEntityStore struct {
+	entities map[int64]*models.Entity
+	accounts map[int64]*models.Account
+	stores   map[string]int64
+	icAccts  []int64
+	ic       []*models.IntercompanyTransaction
+	reversed map[int64]time.Time
+}
+
+func newFakeEntityStore() *fakeEntityStore {
+	shared := []int64{11001, icReceivableAcctID, icPayableAcctID, 4001, 5001}
+	f := &fakeEntityStore{
+		entities: map[int64]*models.Entity{
+			1: {ID: 1, Code: "MAIN", Name: "Main Entity", IsDefault: true},
+			2: {ID: 2, Code: "SUB", Name: "Second Entity"},
+		},
+		accounts: map[int64]*models.Account{},
+		stores:   map[string]int64{"ShopA": 1},
+		icAccts:  []int64{icReceivableAcctID, icPayableAcctID},
+		reversed: map[int64]time.Time{},
+	}
+	for _, id := range shared {
+		f.accounts[id] = &models.Account{AccountID: id, AccountCode: "X"}
+	}
+	sub := int64(2)
+	f.accounts[11099] = &models.Account{AccountID: 11099, AccountCode: "1.1.99", EntityID: &sub}
+	return f
+}
+
+func (f *fakeEntityStore) CreateEntity(ctx context.Context, e *models.Entity) error {
+	e.ID = int64(len(f.entities) + 1)
+	f.entities[e.ID] = e
+	return nil
+}
+func (f *fakeEntityStore) UpdateEntity(ctx context.Context, e *models.Entity) error {
+	f.entities[e.ID] = e
+	return nil
+}
+func (f *fakeEntityStore) DeleteEntity(ctx context.Context, id int64) error {
+	delete(f.entities, id)
+	return nil
+}
+func (f *fakeEntityStore) GetEntity(ctx context.Context, id int64) (*models.Entity, error) {
+	if e, ok := f.entities[id]; ok {
+		return e, nil
+	}
+	return nil, sql.ErrNoRows
+}
+func (f *fakeEntityStore) ListEntities(ctx context.Context) ([]models.Entity, error) {
+	out := []models.Entity{}
+	for id := int64(1); id <= int64(len(f.entities)); id++ {
+		if e, ok := f.entities[id]; ok {
+			out = append(out, *e)
+		}
+	}
+	return out, nil
+}
+func (f *fakeEntityStore) AssignStore(ctx context.Context, store string, entityID int64) error {
+	f.stores[store] = entityID
+	return nil
+}
+func (f *fakeEntityStore) ListStores(ctx context.Context, entityID int64) ([]string, error) {
+	out := []string{}
+	for s, id := range f.stores {
+		if id == entityID {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+func (f *fakeEntityStore) ListAccounts(ctx context.Context, entityID int64) ([]models.Account, error) {
+	return nil, nil
+}
+func (f *fakeEntityStore) GetAccount(ctx context.Context, id int64) (*models.Account, error) {
+	if a, ok := f.accounts[id]; ok {
+		return a, nil
+	}
+	return nil, sql.ErrNoRows
+}
+func (f *fakeEntityStore) IntercompanyAccountIDs(ctx context.Context) ([]int64, error) {
+	return f.icAccts, nil
+}
+func (f *fakeEntityStore) CreateIntercompany(ctx context.Context, t *models.IntercompanyTransaction) error {
+	t.ID = int64(len(f.ic) + 1)
+	f.ic = append(f.ic, t)
+	return nil
+}
+func (f *fakeEntityStore) GetIntercompany(ctx context.Context, id int64) (*models.IntercompanyTransaction, error) {
+	for _, t := range f.ic {
+		if t.ID == id {
+			cp := *t
+			return &cp, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+func (f *fakeEntityStore) ListIntercompany(ctx context.Context, entityID int64, from, to time.Time) ([]models.IntercompanyTransaction, error) {
+	return nil, nil
+}
+func (f *fakeEntityStore) MarkIntercompanyReversed(ctx context.Context, id int64, at time.Time) error {
+	f.reversed[id] = at
+	for _, t := range f.ic {
+		if t.ID == id {
+			t.ReversedAt = &at
+		}
+	}
+	return nil
+}
+
+func TestEntityService_CreateIntercompanyPostsBothSides(t *testing.T) {
+	store := newFakeEntityStore()
+	jr := &fakeJournalRepoT{}
+	svc := NewEntityService(nil, store, jr, nil, nil)
+
+	tx := &models.IntercompanyTransaction{
+		FromEntityID: 1, ToEntityID: 2, TxnDate: time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC),
+		Amount: money.New(1000000), FromAccountID: 11001, ToAccountID: 11099, Description: "modal kerja",
+	}
+	if err := svc.CreateIntercompany(context.Background(), tx); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if len(jr.entries) != 2 || len(jr.lines) != 4 {
+		t.Fatalf("want 2 journals with 4 lines, got %d/%d", len(jr.entries), len(jr.lines))
+	}
+	from, to := jr.entries[0], jr.entries[1]
+	if from.EntityID == nil || *from.EntityID != 1 || to.EntityID == nil || *to.EntityID != 2 {
+		t.Fatalf("journals not booked in their entities: %v %v", from.EntityID, to.EntityID)
+	}
+	if from.SourceType != "intercompany" || from.SourceID != "1-from" || to.SourceID != "1-to" {
+		t.Fatalf("unexpected sources %s/%s/%s", from.SourceType, from.SourceID, to.SourceID)
+	}
+	want := []struct {
+		acct    int64
+		isDebit bool
+	}{{icReceivableAcctID, true}, {11001, false}, {11099, true}, {icPayableAcctID, false}}
+	for i, w := range want {
+		l := jr.lines[i]
+		if l.AccountID != w.acct || l.IsDebit != w.isDebit || l.Amount != money.New(1000000) {
+			t.Errorf("line %d = %+v, want account %d debit=%v", i, l, w.acct, w.isDebit)
+		}
+	}
+
+	rev, err := svc.ReverseIntercompany(context.Background(), tx.ID)
+	if err != nil {
+		t.Fatalf("reverse: %v", err)
+	}
+	if rev.ReversedAt == nil || len(jr.entries) != 4 || jr.entries[2].SourceType != "intercompany_reverse" {
+		t.Fatalf("reverse did not post mirrored journals: %+v", jr.entries)
+	}
+	if jr.lines[4].AccountID != icReceivableAcctID || jr.lines[4].IsDebit {
+		t.Errorf("reversal should credit the receivable, got %+v", jr.lines[4])
+	}
+	if _, err := svc.ReverseIntercompany(context.Background(), tx.ID); !errors.Is(err, ErrInvalidEntity) {
+		t.Errorf("second reverse: want ErrInvalidEntity, got %v", err)
+	}
+}
+
+func TestEntityService_CreateIntercompanyValidation(t *testing.T) {
+	base := models.IntercompanyTransaction{
+		FromEntityID: 1, ToEntityID: 2, TxnDate: time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC),
+		Amount: money.New(500), FromAccountID: 11001, ToAccountID: 11001,
+	}
+	cases := map[string]func(*models.IntercompanyTransaction){
+		"same entity":       func(t *models.IntercompanyTransaction) { t.ToEntityID = 1 },
+		"zero amount":       func(t *models.IntercompanyTransaction) { t.Amount = 0 },
+		"unknown entity":    func(t *models.IntercompanyTransaction) { t.ToEntityID = 9 },
+		"other entity acct": func(t *models.IntercompanyTransaction) { t.FromAccountID = 11099 },
+		"missing date":      func(t *models.IntercompanyTransaction) { t.TxnDate = time.Time{} },
+	}
+	for name, mutate := range cases {
+		jr := &fakeJournalRepoT{}
+		svc := NewEntityService(nil, newFakeEntityStore(), jr, nil, nil)
+		tx := base
+		mutate(&tx)
+		if err := svc.CreateIntercompany(context.Background(), &tx); !errors.Is(err, ErrInvalidEntity) {
+			t.Errorf("%s: want ErrInvalidEntity, got %v", name, err)
+		}
+		if len(jr.entries) != 0 {
+			t.Errorf("%s: journals posted for invalid transaction", name)
+		}
+	}
+}
+
+func TestEntityService_DeleteEntity(t *testing.T) {
+	store := newFakeEntityStore()
+	svc := NewEntityService(nil, store, nil, nil, nil)
+	if err := svc.DeleteEntity(context.Background(), 1); !errors.Is(err, ErrInvalidEntity) {
+		t.Fatalf("default entity: want ErrInvalidEntity, got %v", err)
+	}
+	store.stores["ShopB"] = 2
+	if err := svc.DeleteEntity(context.Background(), 2); !errors.Is(err, ErrInvalidEntity) {
+		t.Fatalf("entity with stores: want ErrInvalidEntity, got %v", err)
+	}
+	delete(store.stores, "ShopB")
+	if err := svc.DeleteEntity(context.Background(), 2); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+}
+
+func TestEntityService_ConsolidatedEliminatesIntercompany(t *testing.T) {
+	// Entity 1 lent 1,000 to entity 2 and charged it 200 of services.
+	ledgers := map[int64]*fakeStatementRepo{
+		1: {accounts: []fsAccount{
+			{id: 11001, code: "1.1.1", name: "Kas", typ: "Asset", opening: money.New(4000)},
+			{id: icReceivableAcctID, code: "1.1.15", name: "Piutang Antar Perusahaan", typ: "Asset", opening: money.New(1200)},
+			{id: 4005, code: "4.5", name: "Pendapatan Antar Perusahaan", typ: "Revenue", credit: money.New(200)},
+			{id: 4001, code: "4.1", name: "Penjualan", typ: "Revenue", credit: money.New(3000)},
+		}},
+		2: {accounts: []fsAccount{
+			{id: 11001, code: "1.1.1", name: "Kas", typ: "Asset", opening: money.New(1000)},
+			{id: icPayableAcctID, code: "2.1.7", name: "Utang Antar Perusahaan", typ: "Liability", opening: money.New(-1200)},
+			{id: 5006, code: "5.6", name: "Beban Antar Perusahaan", typ: "Expense", debit: money.New(200)},
+		}},
+	}
+	store := newFakeEntityStore()
+	store.icAccts = append(store.icAccts, 4005, 5006)
+	ledger := func(id int64) FinancialStatementJournalRepo { return ledgers[id] }
+	svc := NewEntityService(nil, store, nil, ledger, nil)
+
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	rep, err := svc.Consolidated(context.Background(), from, from.AddDate(0, 1, -1))
+	if err != nil {
+		t.Fatalf("consolidated: %v", err)
+	}
+	if rep.Unmatched != 0 {
+		t.Errorf("unmatched = %s, want 0", rep.Unmatched)
+	}
+	got := map[int64]money.Amount{}
+	for _, r := range rep.Rows {
+		got[r.AccountID] = r.Consolidated
+	}
+	for _, id := range []int64{icReceivableAcctID, icPayableAcctID, 4005, 5006} {
+		if got[id] != 0 {
+			t.Errorf("account %d consolidated = %s, want 0", id, got[id])
+		}
+	}
+	if got[11001] != money.New(5000) {
+		t.Errorf("cash = %s, want 5000.00", got[11001])
+	}
+	if rep.NetIncome[0] != money.New(3200) || rep.NetIncome[1] != money.New(-200) {
+		t.Errorf("entity net income = %v", rep.NetIncome)
+	}
+	if rep.ConsolidatedNetIncome != money.New(3000) {
+		t.Errorf("consolidated net income = %s, want 3000.00", rep.ConsolidatedNetIncome)
+	}
+}
@@ -0,0 +1,512 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
+	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
+)
+
+// allocationClearingAccountID links the company-level overhead pool with the
+// stores it is allocated to (2.1.8 Alokasi Beban Antar Toko). It nets to
+// zero across all stores.
+const allocationClearingAccountID = 21008
+
+// ErrInvalidAllocation is wrapped by allocation errors caused by the input,
+// such as an unknown method or a period without revenue to share by.
+var ErrInvalidAllocation = errors.New("invalid expense allocation")
+
+// ErrAllocationRuleInUse is returned when deleting a rule that expenses
+// have been allocated by.
+var ErrAllocationRuleInUse = errors.New("allocation rule has allocations")
+
+// ExpenseAllocationStore persists allocation rules and allocations and
+// supplies the per-store figures revenue and order rules split by.
+type ExpenseAllocationStore interface {
+	CreateRule(ctx context.Context, rule *models.ExpenseAllocationRule) error
+	UpdateRule(ctx context.Context, rule *models.ExpenseAllocationRule) error
+	DeleteRule(ctx context.Context, id int64) error
+	GetRule(ctx context.Context, id int64) (*models.ExpenseAllocationRule, error)
+	ListRules(ctx context.Context) ([]models.ExpenseAllocationRule, error)
+	RevenueByStore(ctx context.Context, from, to time.Time) ([]repository.StoreAmount, error)
+	OrderCountByStore(ctx context.Context, from, to time.Time) ([]repository.StoreAmount, error)
+	InsertAllocation(ctx context.Context, a *models.ExpenseAllocation) error
+	SetJournals(ctx context.Context, a *models.ExpenseAllocation) error
+	GetAllocation(ctx context.Context, id int64) (*models.ExpenseAllocation, error)
+	GetActiveAllocation(ctx context.Context, expenseID string) (*models.ExpenseAllocation, error)
+	ListAllocations(ctx context.Context, expenseID string, limit, offset int) ([]models.ExpenseAllocation, error)
+	MarkReversed(ctx context.Context, id int64, at time.Time) error
+	ListUnallocatedExpenses(ctx context.Context, from, to time.Time) ([]models.Expense, error)
+	ListUnallocatedJournals(ctx context.Context, from, to time.Time) ([]repository.UnallocatedJournal, error)
+	ExpenseAccounts(ctx context.Context, ids []int64) (map[int64]bool, error)
+}
+
+// ExpenseAllocationService splits overhead expenses across stores so
+// store-level P&L shows fully loaded profit.
+//
+// An allocation leaves the expense journal untouched. A pool journal with no
+// store credits the expense accounts and debits the clearing account, and
+// one journal per store debits its share of the expense accounts against
+// the clearing account. The company-wide P&L is unchanged; each store's P&L
+// carries its share.
+type ExpenseAllocationService struct {
+	db          *sqlx.DB
+	repo        ExpenseAllocationStore
+	journalRepo ExpenseJournalRepo
+	cache       Cache
+	now         func() time.Time
+}
+
+// NewExpenseAllocationService constructs an ExpenseAllocationService.
+func NewExpenseAllocationService(db *sqlx.DB, repo ExpenseAllocationStore, jr ExpenseJournalRepo) *ExpenseAllocationService {
+	return &ExpenseAllocationService{db: db, repo: repo, journalRepo: jr, now: time.Now}
+}
+
+// SetCache enables invalidation of cached reports after allocations are
+// posted or reversed.
+func (s *ExpenseAllocationService) SetCache(c Cache) {
+	s.cache = c
+}
+
+// CreateRule validates and stores a rule.
+func (s *ExpenseAllocationService) CreateRule(ctx context.Context, rule *models.ExpenseAllocationRule) error {
+	if err := validateAllocationRule(rule); err != nil {
+		return err
+	}
+	return s.repo.CreateRule(ctx, rule)
+}
+
+// UpdateRule validates and saves a rule. Existing allocations keep the
+// shares they were made with.
+func (s *ExpenseAllocationService) UpdateRule(ctx context.Context, rule *models.ExpenseAllocationRule) error {
+	if err := validateAllocationRule(rule); err != nil {
+		return err
+	}
+	return s.repo.UpdateRule(ctx, rule)
+}
+
+// DeleteRule removes a rule. Rules with allocations are kept, since their
+// journals stay posted; it returns ErrAllocationRuleInUse for them.
+func (s *ExpenseAllocationService) DeleteRule(ctx context.Context, id int64) error {
+	err := s.inTx(ctx, func(repo ExpenseAllocationStore, _ ExpenseJournalRepo) error {
+		return repo.DeleteRule(ctx, id)
+	})
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+		return ErrAllocationRuleInUse
+	}
+	return err
+}
+
+// GetRule returns a rule, or nil when it does not exist.
+func (s *ExpenseAllocationService) GetRule(ctx context.Context, id int64) (*models.ExpenseAllocationRule, error) {
+	rule, err := s.repo.GetRule(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return rule, err
+}
+
+// ListRules returns all rules.
+func (s *ExpenseAllocationService) ListRules(ctx context.Context) ([]models.ExpenseAllocationRule, error) {
+	return s.repo.ListRules(ctx)
+}
+
+// ListAllocations returns allocations newest first, optionally only those
+// of one expense.
+func (s *ExpenseAllocationService) ListAllocations(ctx context.Context, expenseID string, limit, offset int) ([]models.ExpenseAllocation, error) {
+	return s.repo.ListAllocations(ctx, expenseID, limit, offset)
+}
+
+func validateAllocationRule(rule *models.ExpenseAllocationRule) error {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s", ErrInvalidAllocation, fmt.Sprintf(format, args...))
+	}
+	rule.Name = strings.TrimSpace(rule.Name)
+	if rule.Name == "" {
+		return invalid("name is required")
+	}
+	seen := map[string]bool{}
+	var weight float64
+	for i := range rule.Stores {
+		st := &rule.Stores[i]
+		st.Store = strings.TrimSpace(st.Store)
+		if st.Store == "" || seen[st.Store] {
+			return invalid("stores must be named and listed once")
+		}
+		if st.Weight < 0 {
+			return invalid("weight of %s is negative", st.Store)
+		}
+		seen[st.Store] = true
+		weight += st.Weight
+	}
+	switch rule.Method {
+	case models.AllocateFixed:
+		if weight <= 0 {
+			return invalid("a fixed rule needs stores with positive weights")
+		}
+	case models.AllocateRevenue, models.AllocateOrders:
+	default:
+		return invalid("unknown method %q", rule.Method)
+	}
+	return nil
+}
+
+// Shares returns how a rule would split an expense, using revenue or orders
+// between from and to for the rule types that need them.
+func (s *ExpenseAllocationService) Shares(ctx context.Context, ruleID int64, from, to time.Time) ([]models.StoreShare, error) {
+	rule, err := s.repo.GetRule(ctx, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	return s.shares(ctx, rule, from, to)
+}
+
+func (s *ExpenseAllocationService) shares(ctx context.Context, rule *models.ExpenseAllocationRule, from, to time.Time) ([]models.StoreShare, error) {
+	var basis []repository.StoreAmount
+	switch rule.Method {
+	case models.AllocateFixed:
+		for _, st := range rule.Stores {
+			basis = append(basis, repository.StoreAmount{Store: st.Store, Amount: st.Weight})
+		}
+	case models.AllocateRevenue, models.AllocateOrders:
+		var err error
+		if rule.Method == models.AllocateRevenue {
+			basis, err = s.repo.RevenueByStore(ctx, from, to)
+		} else {
+			basis, err = s.repo.OrderCountByStore(ctx, from, to)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(rule.Stores) > 0 {
+			listed := map[string]bool{}
+			for _, st := range rule.Stores {
+				listed[st.Store] = true
+			}
+			kept := basis[:0]
+			for _, b := range basis {
+				if listed[b.Store] {
+					kept = append(kept, b)
+				}
+			}
+			basis = kept
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown method %q", ErrInvalidAllocation, rule.Method)
+	}
+
+	var total float64
+	for _, b := range basis {
+		if b.Amount > 0 {
+			total += b.Amount
+		}
+	}
+	if total <= 0 {
+		return nil, fmt.Errorf("%w: rule %q has no %s to share by between %s and %s", ErrInvalidAllocation,
+			rule.Name, rule.Method, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	}
+	out := []models.StoreShare{}
+	for _, b := range basis {
+		if b.Amount > 0 {
+			out = append(out, models.StoreShare{Store: b.Store, Basis: b.Amount, Share: b.Amount / total})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Store < out[j].Store })
+	return out, nil
+}
+
+// allocationPlan is a rule with the shares it resolved to for one expense.
+type allocationPlan struct {
+	rule     *models.ExpenseAllocationRule
+	shares   []models.StoreShare
+	from, to time.Time
+}
+
+// AllocationBasis returns the period whose revenue or orders split an
+// expense dated date when it is posted: the calendar month before it, the
+// latest month whose figures are complete.
+func AllocationBasis(date time.Time) (time.Time, time.Time) {
+	first := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
+	return first.AddDate(0, -1, 0), first.AddDate(0, 0, -1)
+}
+
+// plan resolves the rule and shares used to allocate e when it is posted.
+func (s *ExpenseAllocationService) plan(ctx context.Context, e *models.Expense) (*allocationPlan, error) {
+	if e.Store != "" {
+		return nil, fmt.Errorf("%w: an expense booked to a store cannot also be allocated", ErrInvalidAllocation)
+	}
+	rule, err := s.repo.GetRule(ctx, *e.AllocationRuleID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: unknown rule %d", ErrInvalidAllocation, *e.AllocationRuleID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	from, to := AllocationBasis(e.Date)
+	shares, err := s.shares(ctx, rule, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return &allocationPlan{rule: rule, shares: shares, from: from, to: to}, nil
+}
+
+// store returns the allocation store bound to tx, or the service's own one
+// when there is no transaction.
+func (s *ExpenseAllocationService) store(tx *sqlx.Tx) ExpenseAllocationStore {
+	if tx == nil {
+		return s.repo
+	}
+	return repository.NewExpenseAllocationRepo(tx)
+}
+
+// RunPeriod allocates every expense dated between from and to that has no
+// store and no allocation yet, splitting by the rule's figures for that
+// period. The amortisation and accrual journals of store-less recurring
+// expenses are allocated too, since they carry the cost of prepaid and
+// accrued templates. It is meant for period-end closing.
+func (s *ExpenseAllocationService) RunPeriod(ctx context.Context, ruleID int64, from, to time.Time) ([]models.ExpenseAllocation, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: period ends before it starts", ErrInvalidAllocation)
+	}
+	rule, err := s.repo.GetRule(ctx, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	shares, err := s.shares(ctx, rule, from, to)
+	if err != nil {
+		return nil, err
+	}
+	expenses, err := s.repo.ListUnallocatedExpenses(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	p := &allocationPlan{rule: rule, shares: shares, from: from, to: to}
+	out := []models.ExpenseAllocation{}
+	for i := range expenses {
+		e := &expenses[i]
+		var a *models.ExpenseAllocation
+		err := s.inTx(ctx, func(repo ExpenseAllocationStore, jr ExpenseJournalRepo) error {
+			var err error
+			a, err = postAllocation(ctx, repo, jr, e, p)
+			return err
+		})
+		if err != nil {
+			return out, fmt.Errorf("allocate expense %s: %w", e.ID, err)
+		}
+		out = append(out, *a)
+	}
+	journals, err := s.repo.ListUnallocatedJournals(ctx, from, to)
+	if err != nil {
+		return out, err
+	}
+	for i := range journals {
+		j := &journals[i]
+		a := &models.ExpenseAllocation{SourceJournalID: &j.JournalID}
+		err := s.inTx(ctx, func(repo ExpenseAllocationStore, jr ExpenseJournalRepo) error {
+			return allocateLines(ctx, repo, jr, a, j.EntryDate, j.Description, j.Lines, p)
+		})
+		if err != nil {
+			return out, fmt.Errorf("allocate journal %d: %w", j.JournalID, err)
+		}
+		out = append(out, *a)
+	}
+	if len(out) > 0 {
+		invalidateCache(ctx, s.cache, journalWriteTags...)
+	}
+	return out, nil
+}
+
+// Reverse posts reversing journals for an allocation, returning the expense
+// to the company-level pool.
+func (s *ExpenseAllocationService) Reverse(ctx context.Context, id int64) error {
+	a, err := s.repo.GetAllocation(ctx, id)
+	if err != nil {
+		return err
+	}
+	if a.ReversedAt != nil {
+		return fmt.Errorf("%w: allocation %d is already reversed", ErrInvalidAllocation, id)
+	}
+	err = s.inTx(ctx, func(repo ExpenseAllocationStore, jr ExpenseJournalRepo) error {
+		return reverseAllocation(ctx, repo, jr, a, s.now())
+	})
+	if err != nil {
+		return err
+	}
+	invalidateCache(ctx, s.cache, journalWriteTags...)
+	return nil
+}
+
+func (s *ExpenseAllocationService) inTx(ctx context.Context, fn func(ExpenseAllocationStore, ExpenseJournalRepo) error) error {
+	if s.db == nil {
+		return fn(s.repo, s.journalRepo)
+	}
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := fn(repository.NewExpenseAllocationRepo(tx), repository.NewJournalRepo(tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// postAllocation splits the Expense account lines of e across the plan's
+// stores and posts the pool and store journals.
+func postAllocation(ctx context.Context, repo ExpenseAllocationStore, jr ExpenseJournalRepo, e *models.Expense, p *allocationPlan) (*models.ExpenseAllocation, error) {
+	a := &models.ExpenseAllocation{ExpenseID: e.ID}
+	if err := allocateLines(ctx, repo, jr, a, e.Date, e.Description, e.Lines, p); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// allocateLines fills in a from the plan, splits the lines on Expense
+// accounts across its stores and posts the pool and store journals. Lines
+// on other accounts, such as a prepaid asset or an accrued liability, are
+// not part of any store's P&L and stay with the pool.
+func allocateLines(ctx context.Context, repo ExpenseAllocationStore, jr ExpenseJournalRepo, a *models.ExpenseAllocation, date time.Time, desc string, lines []models.ExpenseLine, p *allocationPlan) error {
+	ids := make([]int64, len(lines))
+	for i, l := range lines {
+		ids[i] = l.AccountID
+	}
+	expense, err := repo.ExpenseAccounts(ctx, ids)
+	if err != nil {
+		return err
+	}
+	weights := make([]float64, len(p.shares))
+	for i, sh := range p.shares {
+		weights[i] = sh.Share
+	}
+	a.RuleID = p.rule.ID
+	a.Method = p.rule.Method
+	a.BasisFrom = p.from
+	a.BasisTo = p.to
+	for _, l := range lines {
+		if !expense[l.AccountID] {
+			continue
+		}
+		for i, part := range l.Amount.Allocate(weights) {
+			if part == 0 {
+				continue
+			}
+			a.Lines = append(a.Lines, models.ExpenseAllocationLine{
+				Store:     p.shares[i].Store,
+				AccountID: l.AccountID,
+				Share:     p.shares[i].Share,
+				Amount:    part,
+			})
+			a.Total += part
+		}
+	}
+	if len(a.Lines) == 0 {
+		return fmt.Errorf("%w: nothing on an expense account to allocate", ErrInvalidAllocation)
+	}
+	if err := repo.InsertAllocation(ctx, a); err != nil {
+		return err
+	}
+	if err := postAllocationJournals(ctx, jr, a, date, "Alokasi "+desc, false); err != nil {
+		return err
+	}
+	return repo.SetJournals(ctx, a)
+}
+
+// reverseAllocation posts the mirror of an allocation's journals on at and
+// marks it reversed.
+func reverseAllocation(ctx context.Context, repo ExpenseAllocationStore, jr ExpenseJournalRepo, a *models.ExpenseAllocation, at time.Time) error {
+	if err := postAllocationJournals(ctx, jr, a, at, fmt.Sprintf("Batal alokasi %d", a.ID), true); err != nil {
+		return err
+	}
+	return repo.MarkReversed(ctx, a.ID, at)
+}
+
+// postAllocationJournals posts the pool journal and one journal per store
+// for a, swapping every side when reverse is set. Journal IDs are written
+// back to a unless reversing.
+func postAllocationJournals(ctx context.Context, jr ExpenseJournalRepo, a *models.ExpenseAllocation, date time.Time, desc string, reverse bool) error {
+	sourceType := "expense_allocation"
+	if reverse {
+		sourceType = "expense_allocation_reverse"
+	}
+	post := func(store, sourceID string, lines []models.JournalLine) (int64, error) {
+		je := &models.JournalEntry{
+			EntryDate:    date,
+			Description:  &desc,
+			SourceType:   sourceType,
+			SourceID:     sourceID,
+			ShopUsername: store,
+			Store:        store,
+			CreatedAt:    time.Now(),
+		}
+		jid, err := jr.CreateJournalEntry(ctx, je)
+		if err != nil {
+			return 0, err
+		}
+		for i := range lines {
+			lines[i].JournalID = jid
+			lines[i].Memo = &desc
+			lines[i].IsDebit = lines[i].IsDebit != reverse
+		}
+		return jid, jr.InsertJournalLines(ctx, lines)
+	}
+
+	// Pool: the clearing account takes the whole allocation off the
+	// unassigned expense accounts.
+	byAccount := map[int64]money.Amount{}
+	var accounts []int64
+	byStore := map[string][]int{}
+	var stores []string
+	for i, l := range a.Lines {
+		if _, ok := byAccount[l.AccountID]; !ok {
+			accounts = append(accounts, l.AccountID)
+		}
+		byAccount[l.AccountID] += l.Amount
+		if _, ok := byStore[l.Store]; !ok {
+			stores = append(stores, l.Store)
+		}
+		byStore[l.Store] = append(byStore[l.Store], i)
+	}
+	pool := []models.JournalLine{{AccountID: allocationClearingAccountID, IsDebit: true, Amount: a.Total}}
+	for _, acc := range accounts {
+		pool = append(pool, models.JournalLine{AccountID: acc, IsDebit: false, Amount: byAccount[acc]})
+	}
+	poolID, err := post("", fmt.Sprintf("%d", a.ID), pool)
+	if err != nil {
+		return err
+	}
+	if !reverse {
+		a.PoolJournalID = &poolID
+	}
+
+	for _, store := range stores {
+		var lines []models.JournalLine
+		var total money.Amount
+		for _, i := range byStore[store] {
+			l := a.Lines[i]
+			lines = append(lines, models.JournalLine{AccountID: l.AccountID, IsDebit: true, Amount: l.Amount})
+			total += l.Amount
+		}
+		lines = append(lines, models.JournalLine{AccountID: allocationClearingAccountID, IsDebit: false, Amount: total})
+		jid, err := post(store, fmt.Sprintf("%d-%s", a.ID, store), lines)
+		if err != nil {
+			return err
+		}
+		if !reverse {
+			for _, i := range byStore[store] {
+				a.Lines[i].JournalID = &jid
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,350 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
+	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
+)
+
+type fakeAllocationStore struct {
+	rules       map[int64]*models.ExpenseAllocationRule
+	revenue     []repository.StoreAmount
+	orders      []repository.StoreAmount
+	allocations []*models.ExpenseAllocation
+	expenses    []models.Expense
+	journals    []repository.UnallocatedJournal
+	// balanceSheet lists accounts that are not Expense accounts.
+	balanceSheet map[int64]bool
+	nextID       int64
+}
+
+func newFakeAllocationStore() *fakeAllocationStore {
+	return &fakeAllocationStore{rules: map[int64]*models.ExpenseAllocationRule{}}
+}
+
+func (f *fakeAllocationStore) CreateRule(ctx context.Context, rule *models.ExpenseAllocationRule) error {
+	f.nextID++
+	rule.ID = f.nextID
+	cp := *rule
+	f.rules[rule.ID] = &cp
+	return nil
+}
+func (f *fakeAllocationStore) UpdateRule(ctx context.Context, rule *models.ExpenseAllocationRule) error {
+	cp := *rule
+	f.rules[rule.ID] = &cp
+	return nil
+}
+func (f *fakeAllocationStore) DeleteRule(ctx context.Context, id int64) error {
+	delete(f.rules, id)
+	return nil
+}
+func (f *fakeAllocationStore) GetRule(ctx context.Context, id int64) (*models.ExpenseAllocationRule, error) {
+	rule, ok := f.rules[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	cp := *rule
+	return &cp, nil
+}
+func (f *fakeAllocationStore) ListRules(ctx context.Context) ([]models.ExpenseAllocationRule, error) {
+	var out []models.ExpenseAllocationRule
+	for _, r := range f.rules {
+		out = append(out, *r)
+	}
+	return out, nil
+}
+func (f *fakeAllocationStore) RevenueByStore(ctx context.Context, from, to time.Time) ([]repository.StoreAmount, error) {
+	return append([]repository.StoreAmount(nil), f.revenue...), nil
+}
+func (f *fakeAllocationStore) OrderCountByStore(ctx context.Context, from, to time.Time) ([]repository.StoreAmount, error) {
+	return append([]repository.StoreAmount(nil), f.orders...), nil
+}
+func (f *fakeAllocationStore) InsertAllocation(ctx context.Context, a *models.ExpenseAllocation) error {
+	f.nextID++
+	a.ID = f.nextID
+	f.allocations = append(f.allocations, a)
+	return nil
+}
+func (f *fakeAllocationStore) SetJournals(ctx context.Context, a *models.ExpenseAllocation) error {
+	return nil
+}
+func (f *fakeAllocationStore) GetAllocation(ctx context.Context, id int64) (*models.ExpenseAllocation, error) {
+	for _, a := range f.allocations {
+		if a.ID == id {
+			return a, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+func (f *fakeAllocationStore) GetActiveAllocation(ctx context.Context, expenseID string) (*models.ExpenseAllocation, error) {
+	for _, a := range f.allocations {
+		if a.ExpenseID == expenseID && a.ReversedAt == nil {
+			return a, nil
+		}
+	}
+	return nil, nil
+}
+func (f *fakeAllocationStore) ListAllocations(ctx context.Context, expenseID string, limit, offset int) ([]models.ExpenseAllocation, error) {
+	var out []models.ExpenseAllocation
+	for _, a := range f.allocations {
+		if expenseID == "" || a.ExpenseID == expenseID {
+			out = append(out, *a)
+		}
+	}
+	return out, nil
+}
+func (f *fakeAllocationStore) MarkReversed(ctx context.Context, id int64, at time.Time) error {
+	for _, a := range f.allocations {
+		if a.ID == id {
+			a.ReversedAt = &at
+		}
+	}
+	return nil
+}
+func (f *fakeAllocationStore) ListUnallocatedExpenses(ctx context.Context, from, to time.Time) ([]models.Expense, error) {
+	var out []models.Expense
+	for _, e := range f.expenses {
+		active, _ := f.GetActiveAllocation(ctx, e.ID)
+		if e.Store == "" && active == nil {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeAllocationStore) ListUnallocatedJournals(ctx context.Context, from, to time.Time) ([]repository.UnallocatedJournal, error) {
+	var out []repository.UnallocatedJournal
+	for _, j := range f.journals {
+		allocated := false
+		for _, a := range f.allocations {
+			allocated = allocated || (a.SourceJournalID != nil && *a.SourceJournalID == j.JournalID && a.ReversedAt == nil)
+		}
+		if !allocated {
+			out = append(out, j)
+		}
+	}
+	return out, nil
+}
+func (f *fakeAllocationStore) ExpenseAccounts(ctx context.Context, ids []int64) (map[int64]bool, error) {
+	out := map[int64]bool{}
+	for _, id := range ids {
+		out[id] = !f.balanceSheet[id]
+	}
+	return out, nil
+}
+
+// storeBalances sums debits minus credits per store and account.
+func storeBalances(jr *fakeIntegrityJournal) map[string]map[int64]money.Amount {
+	out := map[string]map[int64]money.Amount{}
+	for _, l := range jr.lines {
+		store := jr.entries[l.JournalID].ShopUsername
+		if out[store] == nil {
+			out[store] = map[int64]money.Amount{}
+		}
+		if l.IsDebit {
+			out[store][l.AccountID] += l.Amount
+		} else {
+			out[store][l.AccountID] -= l.Amount
+		}
+	}
+	return out
+}
+
+func TestExpenseAllocationRuleValidation(t *testing.T) {
+	svc := NewExpenseAllocationService(nil, newFakeAllocationStore(), &fakeIntegrityJournal{entries: map[int64]*models.JournalEntry{}})
+	bad := []models.ExpenseAllocationRule{
+		{Name: "", Method: models.AllocateRevenue},
+		{Name: "x", Method: "headcount"},
+		{Name: "x", Method: models.AllocateFixed},
+		{Name: "x", Method: models.AllocateFixed, Stores: []models.ExpenseAllocationRuleStore{{Store: "A", Weight: 1}, {Store: "A", Weight: 1}}},
+		{Name: "x", Method: models.AllocateOrders, Stores: []models.ExpenseAllocationRuleStore{{Store: "A", Weight: -1}}},
+	}
+	for i := range bad {
+		if err := svc.CreateRule(context.Background(), &bad[i]); !errors.Is(err, ErrInvalidAllocation) {
+			t.Errorf("rule %d: expected ErrInvalidAllocation, got %v", i, err)
+		}
+	}
+	ok := &models.ExpenseAllocationRule{Name: " Revenue ", Method: models.AllocateRevenue}
+	if err := svc.CreateRule(context.Background(), ok); err != nil || ok.Name != "Revenue" {
+		t.Fatalf("create: %v %q", err, ok.Name)
+	}
+}
+
+func TestExpenseAllocationShares(t *testing.T) {
+	store := newFakeAllocationStore()
+	store.revenue = []repository.StoreAmount{{Store: "B", Amount: 300}, {Store: "A", Amount: 100}, {Store: "C", Amount: -5}}
+	store.orders = []repository.StoreAmount{{Store: "A", Amount: 1}, {Store: "B", Amount: 3}, {Store: "C", Amount: 4}}
+	svc := NewExpenseAllocationService(nil, store, &fakeIntegrityJournal{entries: map[int64]*models.JournalEntry{}})
+	ctx := context.Background()
+	day := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	rev := &models.ExpenseAllocationRule{Name: "rev", Method: models.AllocateRevenue}
+	orders := &models.ExpenseAllocationRule{Name: "orders", Method: models.AllocateOrders,
+		Stores: []models.ExpenseAllocationRuleStore{{Store: "A"}, {Store: "B"}}}
+	empty := &models.ExpenseAllocationRule{Name: "none", Method: models.AllocateRevenue,
+		Stores: []models.ExpenseAllocationRuleStore{{Store: "C"}}}
+	for _, r := range []*models.ExpenseAllocationRule{rev, orders, empty} {
+		if err := svc.CreateRule(ctx, r); err != nil {
+			t.Fatalf("create %s: %v", r.Name, err)
+		}
+	}
+
+	shares, err := svc.Shares(ctx, rev.ID, day, day)
+	if err != nil {
+		t.Fatalf("revenue shares: %v", err)
+	}
+	if len(shares) != 2 || shares[0].Store != "A" || shares[0].Share != 0.25 || shares[1].Share != 0.75 {
+		t.Fatalf("unexpected revenue shares %+v", shares)
+	}
+	shares, err = svc.Shares(ctx, orders.ID, day, day)
+	if err != nil {
+		t.Fatalf("order shares: %v", err)
+	}
+	if len(shares) != 2 || shares[1].Store != "B" || shares[1].Share != 0.75 {
+		t.Fatalf("unexpected order shares %+v", shares)
+	}
+	if _, err := svc.Shares(ctx, empty.ID, day, day); !errors.Is(err, ErrInvalidAllocation) {
+		t.Fatalf("expected ErrInvalidAllocation without revenue, got %v", err)
+	}
+}
+
+func TestExpenseAllocationRunPeriodAndReverse(t *testing.T) {
+	store := newFakeAllocationStore()
+	jr := &fakeIntegrityJournal{entries: map[int64]*models.JournalEntry{}}
+	svc := NewExpenseAllocationService(nil, store, jr)
+	ctx := context.Background()
+
+	rule := &models.ExpenseAllocationRule{Name: "split", Method: models.AllocateFixed,
+		Stores: []models.ExpenseAllocationRuleStore{{Store: "A", Weight: 1}, {Store: "B", Weight: 2}}}
+	if err := svc.CreateRule(ctx, rule); err != nil {
+		t.Fatalf("create rule: %v", err)
+	}
+	store.expenses = []models.Expense{
+		{ID: "e1", Description: "Sewa", Lines: []models.ExpenseLine{
+			{AccountID: 65001, Amount: money.New(100)},
+			{AccountID: 65002, Amount: money.New(50)},
+		}},
+		{ID: "e2", Store: "A", Lines: []models.ExpenseLine{{AccountID: 65001, Amount: money.New(10)}}},
+	}
+
+	from := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	allocs, err := svc.RunPeriod(ctx, rule.ID, from, from.AddDate(0, 1, -1))
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(allocs) != 1 || allocs[0].ExpenseID != "e1" || allocs[0].Total != money.New(150) {
+		t.Fatalf("unexpected allocations %+v", allocs)
+	}
+
+	bal := storeBalances(jr)
+	// 100 split 1:2 leaves the extra sen with the larger remainder.
+	if bal["A"][65001] != money.FromSen(3333) || bal["B"][65001] != money.FromSen(6667) {
+		t.Errorf("unexpected 65001 split A=%s B=%s", bal["A"][65001], bal["B"][65001])
+	}
+	if bal["A"][65002]+bal["B"][65002] != money.New(50) {
+		t.Errorf("65002 not fully allocated: %v", bal)
+	}
+	if bal[""][65001] != -money.New(100) || bal[""][65002] != -money.New(50) {
+		t.Errorf("pool should credit the expense accounts: %v", bal[""])
+	}
+	var clearing money.Amount
+	for _, accounts := range bal {
+		clearing += accounts[allocationClearingAccountID]
+	}
+	if clearing != 0 {
+		t.Errorf("clearing account should net to zero, got %s", clearing)
+	}
+
+	again, err := svc.RunPeriod(ctx, rule.ID, from, from.AddDate(0, 1, -1))
+	if err != nil || len(again) != 0 {
+		t.Fatalf("second run should allocate nothing: %v %+v", err, again)
+	}
+
+	if err := svc.Reverse(ctx, allocs[0].ID); err != nil {
+		t.Fatalf("reverse: %v", err)
+	}
+	for store, accounts := range storeBalances(jr) {
+		for acc, amt := range accounts {
+			if amt != 0 {
+				t.Errorf("store %q account %d left with %s after reversal", store, acc, amt)
+			}
+		}
+	}
+	if err := svc.Reverse(ctx, allocs[0].ID); !errors.Is(err, ErrInvalidAllocation) {
+		t.Errorf("expected second reversal to fail, got %v", err)
+	}
+}
+
+func TestExpenseAllocationRunPeriodSplitsOnlyExpenseAccounts(t *testing.T) {
+	store := newFakeAllocationStore()
+	store.balanceSheet = map[int64]bool{11501: true, 21501: true}
+	jr := &fakeIntegrityJournal{entries: map[int64]*models.JournalEntry{}}
+	svc := NewExpenseAllocationService(nil, store, jr)
+	ctx := context.Background()
+
+	rule := &models.ExpenseAllocationRule{Name: "split", Method: models.AllocateFixed,
+		Stores: []models.ExpenseAllocationRuleStore{{Store: "A", Weight: 1}, {Store: "B", Weight: 1}}}
+	if err := svc.CreateRule(ctx, rule); err != nil {
+		t.Fatalf("create rule: %v", err)
+	}
+	// A prepaid payment moves cash to the prepaid asset; its cost is
+	// recognised by the amortisation journal, which has no expense row.
+	store.expenses = []models.Expense{
+		{ID: "prepaid", Lines: []models.ExpenseLine{{AccountID: 11501, Amount: money.New(1200)}}},
+		{ID: "mixed", Lines: []models.ExpenseLine{
+			{AccountID: 65001, Amount: money.New(40)},
+			{AccountID: 21501, Amount: money.New(60)},
+		}},
+	}
+	store.journals = []repository.UnallocatedJournal{
+		{JournalID: 900, Description: "Amortisasi Sewa (1/12)", Lines: []models.ExpenseLine{{AccountID: 65001, Amount: money.New(100)}}},
+	}
+
+	from := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	_, err := svc.RunPeriod(ctx, rule.ID, from, from.AddDate(0, 1, -1))
+	if !errors.Is(err, ErrInvalidAllocation) {
+		t.Fatalf("expected the prepaid payment to be refused, got %v", err)
+	}
+
+	store.expenses = store.expenses[1:]
+	allocs, err := svc.RunPeriod(ctx, rule.ID, from, from.AddDate(0, 1, -1))
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(allocs) != 2 {
+		t.Fatalf("expected the expense and the journal to be allocated, got %+v", allocs)
+	}
+	if allocs[0].ExpenseID != "mixed" || allocs[0].Total != money.New(40) {
+		t.Errorf("only the expense account line should be split: %+v", allocs[0])
+	}
+	if allocs[1].SourceJournalID == nil || *allocs[1].SourceJournalID != 900 || allocs[1].Total != money.New(100) {
+		t.Errorf("unexpected journal allocation %+v", allocs[1])
+	}
+	bal := storeBalances(jr)
+	if bal["A"][65001] != money.New(70) || bal["B"][65001] != money.New(70) {
+		t.Errorf("unexpected store split %v", bal)
+	}
+	for store, accounts := range bal {
+		if accounts[21501] != 0 || accounts[11501] != 0 {
+			t.Errorf("store %q should not carry balance sheet accounts: %v", store, accounts)
+		}
+	}
+
+	again, err := svc.RunPeriod(ctx, rule.ID, from, from.AddDate(0, 1, -1))
+	if err != nil || len(again) != 0 {
+		t.Fatalf("second run should allocate nothing: %v %+v", err, again)
+	}
+}
+
+func TestAllocationBasis(t *testing.T) {
+	from, to := AllocationBasis(time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC))
+	if !from.Equal(time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)) || !to.Equal(time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected basis %s..%s", from, to)
+	}
+}
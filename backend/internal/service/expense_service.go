@@ -2,13 +2,14 @@ package service
 
 import (
 	"context"
-	"log"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	"github.com/ramadhan22/dropship-erp/backend/internal/logutil"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
 )
 
@@ -16,22 +17,52 @@ type ExpenseService struct {
 	db          *sqlx.DB
 	expenseRepo *repository.ExpenseRepo
 	journalRepo *repository.JournalRepo
+	allocator   *ExpenseAllocationService
+	cache       Cache
 }
 
 func NewExpenseService(db *sqlx.DB, er *repository.ExpenseRepo, jr *repository.JournalRepo) *ExpenseService {
 	return &ExpenseService{db: db, expenseRepo: er, journalRepo: jr}
 }
 
+// SetCache enables invalidation of cached reports after expense journals
+// are posted.
+func (s *ExpenseService) SetCache(c Cache) {
+	s.cache = c
+}
+
+// SetAllocator enables allocation of expenses that name an allocation rule.
+func (s *ExpenseService) SetAllocator(a *ExpenseAllocationService) {
+	s.allocator = a
+}
+
+// allocationPlan resolves the allocation of e when it names a rule. The
+// shares are read outside the transaction that posts the expense.
+func (s *ExpenseService) allocationPlan(ctx context.Context, e *models.Expense) (*allocationPlan, error) {
+	if e.AllocationRuleID == nil {
+		return nil, nil
+	}
+	if s.allocator == nil {
+		return nil, fmt.Errorf("%w: expense allocation is not enabled", ErrInvalidAllocation)
+	}
+	return s.allocator.plan(ctx, e)
+}
+
 func (s *ExpenseService) CreateExpense(ctx context.Context, e *models.Expense) error {
-	log.Printf("CreateExpense %s", e.ID)
+	logutil.Debug(ctx, "CreateExpense", "Creating expense", map[string]interface{}{
+		"expense_id": e.ID,
+	})
 	var tx *sqlx.Tx
 	expRepo := s.expenseRepo
 	jRepo := s.journalRepo
 	if e.ID == "" {
 		e.ID = uuid.NewString()
 	}
+	plan, err := s.allocationPlan(ctx, e)
+	if err != nil {
+		return err
+	}
 	if s.db != nil {
-		var err error
 		tx, err = s.db.BeginTxx(ctx, nil)
 		if err != nil {
 			return err
@@ -40,12 +71,48 @@ func (s *ExpenseService) CreateExpense(ctx context.Context, e *models.Expense) e
 		expRepo = repository.NewExpenseRepo(tx)
 		jRepo = repository.NewJournalRepo(tx)
 	}
-	var total float64
+	if err := postExpense(ctx, expRepo, jRepo, e); err != nil {
+		return err
+	}
+	if plan != nil {
+		if _, err := postAllocation(ctx, s.allocator.store(tx), jRepo, e, plan); err != nil {
+			logutil.Errorf("CreateExpense allocation error: %v", err)
+			return err
+		}
+	}
+	if tx != nil {
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	logutil.Info(ctx, "CreateExpense", "Expense created", map[string]interface{}{
+		"expense_id": e.ID,
+	})
+	invalidateCache(ctx, s.cache, journalWriteTags...)
+	return nil
+}
+
+// ExpenseWriter stores new expenses. ExpenseRepo implements it.
+type ExpenseWriter interface {
+	Create(ctx context.Context, e *models.Expense) error
+}
+
+// ExpenseJournalRepo posts the journal of an expense. JournalRepo
+// implements it.
+type ExpenseJournalRepo interface {
+	CreateJournalEntry(ctx context.Context, e *models.JournalEntry) (int64, error)
+	InsertJournalLines(ctx context.Context, lines []models.JournalLine) error
+}
+
+// postExpense stores e and posts its journal: each line is debited and the
+// total is credited to the asset account. Callers provide the transaction.
+func postExpense(ctx context.Context, er ExpenseWriter, jr ExpenseJournalRepo, e *models.Expense) error {
+	var total money.Amount
 	for _, l := range e.Lines {
 		total += l.Amount
 	}
 	e.Amount = total
-	if err := expRepo.Create(ctx, e); err != nil {
+	if err := er.Create(ctx, e); err != nil {
 		logutil.Errorf("CreateExpense error: %v", err)
 		return err
 	}
@@ -54,19 +121,26 @@ func (s *ExpenseService) CreateExpense(ctx context.Context, e *models.Expense) e
 		Description:  &e.Description,
 		SourceType:   "expense",
 		SourceID:     e.ID,
-		ShopUsername: "", // optional shop not tracked
-		Store:        "",
+		ShopUsername: e.Store,
+		Store:        e.Store,
 		CreatedAt:    time.Now(),
 	}
-	jid, err := jRepo.CreateJournalEntry(ctx, je)
+	jid, err := jr.CreateJournalEntry(ctx, je)
 	if err != nil {
 		logutil.Errorf("CreateExpense journal error: %v", err)
 		return err
 	}
-	// Prepare all journal lines for bulk insert
+	if err := jr.InsertJournalLines(ctx, expenseJournalLines(jid, e)); err != nil {
+		logutil.Errorf("CreateExpense lines error: %v", err)
+		return err
+	}
+	return nil
+}
+
+// expenseJournalLines debits every line of e and credits its asset account.
+func expenseJournalLines(jid int64, e *models.Expense) []models.JournalLine {
 	lines := make([]models.JournalLine, 0, len(e.Lines)+1)
-	for i := range e.Lines {
-		l := e.Lines[i]
+	for _, l := range e.Lines {
 		lines = append(lines, models.JournalLine{
 			JournalID: jid,
 			AccountID: l.AccountID,
@@ -75,42 +149,73 @@ func (s *ExpenseService) CreateExpense(ctx context.Context, e *models.Expense) e
 			Memo:      &e.Description,
 		})
 	}
-	// Add asset line
-	lines = append(lines, models.JournalLine{
+	return append(lines, models.JournalLine{
 		JournalID: jid,
 		AccountID: e.AssetAccountID,
 		IsDebit:   false,
-		Amount:    total,
+		Amount:    e.Amount,
 		Memo:      &e.Description,
 	})
-
-	// Use bulk insert for all lines
-	if err := jRepo.InsertJournalLines(ctx, lines); err != nil {
-		logutil.Errorf("CreateExpense lines error: %v", err)
-		return err
-	}
-	if tx != nil {
-		if err := tx.Commit(); err != nil {
-			return err
-		}
-		log.Printf("CreateExpense committed %s", e.ID)
-		return nil
-	}
-	log.Printf("CreateExpense done %s", e.ID)
-	return nil
 }
 
 func (s *ExpenseService) ListExpenses(ctx context.Context, accountID int64, sortBy, dir string, limit, offset int) ([]models.Expense, int, error) {
 	return s.expenseRepo.List(ctx, accountID, sortBy, dir, limit, offset)
 }
 
+// ListExpensesFiltered uses the filtering framework to fetch expenses.
+func (s *ExpenseService) ListExpensesFiltered(ctx context.Context, params *models.FilterParams) (*models.QueryResult, error) {
+	return s.expenseRepo.ListFiltered(ctx, params)
+}
+
+// DeleteExpense deletes an expense and reverses its active allocation, so
+// the stores it was split across no longer carry a share of it.
 func (s *ExpenseService) DeleteExpense(ctx context.Context, id string) error {
-	log.Printf("DeleteExpense %s", id)
-	err := s.expenseRepo.Delete(ctx, id)
-	if err != nil {
+	logutil.Info(ctx, "DeleteExpense", "Deleting expense", map[string]interface{}{
+		"expense_id": id,
+	})
+	var tx *sqlx.Tx
+	expRepo := s.expenseRepo
+	jRepo := s.journalRepo
+	if s.db != nil {
+		var err error
+		tx, err = s.db.BeginTxx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+		expRepo = repository.NewExpenseRepo(tx)
+		jRepo = repository.NewJournalRepo(tx)
+	}
+
+	reversed := false
+	if s.allocator != nil {
+		allocRepo := s.allocator.store(tx)
+		active, err := allocRepo.GetActiveAllocation(ctx, id)
+		if err != nil {
+			return err
+		}
+		if active != nil {
+			if err := reverseAllocation(ctx, allocRepo, jRepo, active, time.Now()); err != nil {
+				logutil.Errorf("DeleteExpense allocation reverse error: %v", err)
+				return err
+			}
+			reversed = true
+		}
+	}
+
+	if err := expRepo.Delete(ctx, id); err != nil {
 		logutil.Errorf("DeleteExpense error: %v", err)
+		return err
+	}
+	if tx != nil {
+		if err := tx.Commit(); err != nil {
+			return err
+		}
 	}
-	return err
+	if reversed {
+		invalidateCache(ctx, s.cache, journalWriteTags...)
+	}
+	return nil
 }
 
 func (s *ExpenseService) GetExpense(ctx context.Context, id string) (*models.Expense, error) {
@@ -118,12 +223,17 @@ func (s *ExpenseService) GetExpense(ctx context.Context, id string) (*models.Exp
 }
 
 func (s *ExpenseService) UpdateExpense(ctx context.Context, e *models.Expense) error {
-	log.Printf("UpdateExpense %s", e.ID)
+	logutil.Debug(ctx, "UpdateExpense", "Updating expense", map[string]interface{}{
+		"expense_id": e.ID,
+	})
+	plan, err := s.allocationPlan(ctx, e)
+	if err != nil {
+		return err
+	}
 	var tx *sqlx.Tx
 	expRepo := s.expenseRepo
 	jRepo := s.journalRepo
 	if s.db != nil {
-		var err error
 		tx, err = s.db.BeginTxx(ctx, nil)
 		if err != nil {
 			return err
@@ -133,6 +243,20 @@ func (s *ExpenseService) UpdateExpense(ctx context.Context, e *models.Expense) e
 		jRepo = repository.NewJournalRepo(tx)
 	}
 
+	if s.allocator != nil {
+		allocRepo := s.allocator.store(tx)
+		active, err := allocRepo.GetActiveAllocation(ctx, e.ID)
+		if err != nil {
+			return err
+		}
+		if active != nil {
+			if err := reverseAllocation(ctx, allocRepo, jRepo, active, time.Now()); err != nil {
+				logutil.Errorf("UpdateExpense allocation reverse error: %v", err)
+				return err
+			}
+		}
+	}
+
 	oldEntry, err := jRepo.GetJournalEntryBySource(ctx, "expense", e.ID)
 	if err == nil && oldEntry != nil {
 		lines, _ := jRepo.GetLinesByJournalID(ctx, oldEntry.JournalID)
@@ -141,8 +265,8 @@ func (s *ExpenseService) UpdateExpense(ctx context.Context, e *models.Expense) e
 			Description:  expPtrString("Reverse " + e.Description),
 			SourceType:   "expense_reverse",
 			SourceID:     e.ID + "-rev-" + time.Now().Format("20060102150405"),
-			ShopUsername: "",
-			Store:        "",
+			ShopUsername: oldEntry.ShopUsername,
+			Store:        oldEntry.Store,
 			CreatedAt:    time.Now(),
 		}
 		jid, err := jRepo.CreateJournalEntry(ctx, rev)
@@ -166,7 +290,7 @@ func (s *ExpenseService) UpdateExpense(ctx context.Context, e *models.Expense) e
 		}
 	}
 
-	var total float64
+	var total money.Amount
 	for _, l := range e.Lines {
 		total += l.Amount
 	}
@@ -180,8 +304,8 @@ func (s *ExpenseService) UpdateExpense(ctx context.Context, e *models.Expense) e
 		Description:  &e.Description,
 		SourceType:   "expense",
 		SourceID:     e.ID,
-		ShopUsername: "",
-		Store:        "",
+		ShopUsername: e.Store,
+		Store:        e.Store,
 		CreatedAt:    time.Now(),
 	}
 	jid, err := jRepo.CreateJournalEntry(ctx, je)
@@ -189,40 +313,25 @@ func (s *ExpenseService) UpdateExpense(ctx context.Context, e *models.Expense) e
 		logutil.Errorf("UpdateExpense journal error: %v", err)
 		return err
 	}
-	// Prepare all journal lines for bulk insert
-	lines := make([]models.JournalLine, 0, len(e.Lines)+1)
-	for i := range e.Lines {
-		l := e.Lines[i]
-		lines = append(lines, models.JournalLine{
-			JournalID: jid,
-			AccountID: l.AccountID,
-			IsDebit:   true,
-			Amount:    l.Amount,
-			Memo:      &e.Description,
-		})
-	}
-	// Add asset line
-	lines = append(lines, models.JournalLine{
-		JournalID: jid,
-		AccountID: e.AssetAccountID,
-		IsDebit:   false,
-		Amount:    total,
-		Memo:      &e.Description,
-	})
-
-	// Use bulk insert for all lines
-	if err := jRepo.InsertJournalLines(ctx, lines); err != nil {
+	if err := jRepo.InsertJournalLines(ctx, expenseJournalLines(jid, e)); err != nil {
 		logutil.Errorf("UpdateExpense lines error: %v", err)
 		return err
 	}
+	if plan != nil {
+		if _, err := postAllocation(ctx, s.allocator.store(tx), jRepo, e, plan); err != nil {
+			logutil.Errorf("UpdateExpense allocation error: %v", err)
+			return err
+		}
+	}
 	if tx != nil {
 		if err := tx.Commit(); err != nil {
 			return err
 		}
-		log.Printf("UpdateExpense committed %s", e.ID)
-		return nil
 	}
-	log.Printf("UpdateExpense done %s", e.ID)
+	logutil.Info(ctx, "UpdateExpense", "Expense updated", map[string]interface{}{
+		"expense_id": e.ID,
+	})
+	invalidateCache(ctx, s.cache, journalWriteTags...)
 	return nil
 }
 
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+)
+
+var (
+	// ErrFilterViewExists is returned when the owner already has a view
+	// with the same name for the resource.
+	ErrFilterViewExists = errors.New("filter view already exists")
+	// ErrFilterViewForbidden is returned when someone other than the owner
+	// changes or deletes a view.
+	ErrFilterViewForbidden = errors.New("filter view belongs to another user")
+)
+
+// FilterViewRepo is implemented by repository.SavedFilterViewRepo.
+type FilterViewRepo interface {
+	Create(ctx context.Context, v *models.SavedFilterView) error
+	Update(ctx context.Context, v *models.SavedFilterView) error
+	Delete(ctx context.Context, id int64) error
+	Get(ctx context.Context, id int64) (*models.SavedFilterView, error)
+	GetByName(ctx context.Context, resource, name, user string) (*models.SavedFilterView, error)
+	ListVisible(ctx context.Context, resource, user string) ([]models.SavedFilterView, error)
+}
+
+// FilterViewService manages saved filter views. Users are identified by
+// the X-User-ID header; a view is visible to its owner and, when shared,
+// to everyone, but only the owner may change it.
+type FilterViewService struct {
+	repo FilterViewRepo
+}
+
+// NewFilterViewService constructs a FilterViewService.
+func NewFilterViewService(repo FilterViewRepo) *FilterViewService {
+	return &FilterViewService{repo: repo}
+}
+
+// Create saves v as a new view owned by user.
+func (s *FilterViewService) Create(ctx context.Context, v *models.SavedFilterView, user string) error {
+	v.Owner = user
+	if err := s.validate(v); err != nil {
+		return err
+	}
+	return uniqueViewName(s.repo.Create(ctx, v))
+}
+
+// Update replaces the name, sharing, filters, sort and page size of the
+// view v.ID. Only its owner may update it.
+func (s *FilterViewService) Update(ctx context.Context, v *models.SavedFilterView, user string) error {
+	existing, err := s.owned(ctx, v.ID, user)
+	if err != nil {
+		return err
+	}
+	v.Owner = existing.Owner
+	v.Resource = existing.Resource
+	v.CreatedAt = existing.CreatedAt
+	if err := s.validate(v); err != nil {
+		return err
+	}
+	return uniqueViewName(s.repo.Update(ctx, v))
+}
+
+// Delete removes the view id. Only its owner may delete it.
+func (s *FilterViewService) Delete(ctx context.Context, id int64, user string) error {
+	if _, err := s.owned(ctx, id, user); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// Get returns the view id when user can see it, and sql.ErrNoRows otherwise.
+func (s *FilterViewService) Get(ctx context.Context, id int64, user string) (*models.SavedFilterView, error) {
+	v, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if v.Owner != user && !v.Shared {
+		return nil, sql.ErrNoRows
+	}
+	return v, nil
+}
+
+// GetByName returns the view called name for resource that user can see,
+// preferring the user's own view over a shared one.
+func (s *FilterViewService) GetByName(ctx context.Context, resource, name, user string) (*models.SavedFilterView, error) {
+	return s.repo.GetByName(ctx, resource, name, user)
+}
+
+// List returns the views user owns plus shared views, optionally for one
+// resource.
+func (s *FilterViewService) List(ctx context.Context, resource, user string) ([]models.SavedFilterView, error) {
+	return s.repo.ListVisible(ctx, resource, user)
+}
+
+// ResolveView finds the view ref for resource, where ref is a view ID or
+// name. It implements middleware.ViewResolver.
+func (s *FilterViewService) ResolveView(ctx context.Context, resource, ref, user string) (*models.SavedFilterView, error) {
+	if id, err := strconv.ParseInt(ref, 10, 64); err == nil {
+		v, err := s.Get(ctx, id, user)
+		if err != nil {
+			return nil, err
+		}
+		if resource != "" && v.Resource != resource {
+			return nil, fmt.Errorf("%w: view %d is for %s", models.ErrInvalidFilterView, id, v.Resource)
+		}
+		return v, nil
+	}
+	if resource == "" {
+		return nil, fmt.Errorf("%w: refer to the view by id on this endpoint", models.ErrInvalidFilterView)
+	}
+	return s.GetByName(ctx, resource, ref, user)
+}
+
+func (s *FilterViewService) owned(ctx context.Context, id int64, user string) (*models.SavedFilterView, error) {
+	v, err := s.Get(ctx, id, user)
+	if err != nil {
+		return nil, err
+	}
+	if v.Owner != user {
+		return nil, ErrFilterViewForbidden
+	}
+	return v, nil
+}
+
+func (s *FilterViewService) validate(v *models.SavedFilterView) error {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s", models.ErrInvalidFilterView, fmt.Sprintf(format, args...))
+	}
+	v.Name = strings.TrimSpace(v.Name)
+	switch {
+	case v.Owner == "":
+		return invalid("X-User-ID header is required")
+	case v.Name == "":
+		return invalid("name is required")
+	case !models.FilterResources[v.Resource]:
+		return invalid("unknown resource %q", v.Resource)
+	case v.PageSize < 0 || v.PageSize > 1000:
+		return invalid("page_size must be between 0 and 1000")
+	}
+	if err := v.Validate(); err != nil {
+		return invalid("%v", err)
+	}
+	return nil
+}
+
+// uniqueViewName maps a unique violation on (owner, resource, name) to
+// ErrFilterViewExists.
+func uniqueViewName(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrFilterViewExists
+	}
+	return err
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/lib/pq"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+)
+
+type fakeFilterViewRepo struct {
+	views  map[int64]*models.SavedFilterView
+	nextID int64
+}
+
+func newFakeFilterViewRepo() *fakeFilterViewRepo {
+	return &fakeFilterViewRepo{views: map[int64]*models.SavedFilterView{}}
+}
+
+func (f *fakeFilterViewRepo) Create(ctx context.Context, v *models.SavedFilterView) error {
+	for _, e := range f.views {
+		if e.Owner == v.Owner && e.Resource == v.Resource && e.Name == v.Name {
+			return &pq.Error{Code: "23505"}
+		}
+	}
+	f.nextID++
+	v.ID = f.nextID
+	cp := *v
+	f.views[v.ID] = &cp
+	return nil
+}
+
+func (f *fakeFilterViewRepo) Update(ctx context.Context, v *models.SavedFilterView) error {
+	cp := *v
+	f.views[v.ID] = &cp
+	return nil
+}
+
+func (f *fakeFilterViewRepo) Delete(ctx context.Context, id int64) error {
+	delete(f.views, id)
+	return nil
+}
+
+func (f *fakeFilterViewRepo) Get(ctx context.Context, id int64) (*models.SavedFilterView, error) {
+	v, ok := f.views[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	cp := *v
+	return &cp, nil
+}
+
+func (f *fakeFilterViewRepo) GetByName(ctx context.Context, resource, name, user string) (*models.SavedFilterView, error) {
+	var found *models.SavedFilterView
+	for id := int64(1); id <= f.nextID; id++ {
+		v, ok := f.views[id]
+		if !ok || v.Resource != resource || v.Name != name || (v.Owner != user && !v.Shared) {
+			continue
+		}
+		if found == nil || (v.Owner == user && found.Owner != user) {
+			found = v
+		}
+	}
+	if found == nil {
+		return nil, sql.ErrNoRows
+	}
+	return found, nil
+}
+
+func (f *fakeFilterViewRepo) ListVisible(ctx context.Context, resource, user string) ([]models.SavedFilterView, error) {
+	list := []models.SavedFilterView{}
+	for id := int64(1); id <= f.nextID; id++ {
+		if v, ok := f.views[id]; ok && (v.Owner == user || v.Shared) && (resource == "" || v.Resource == resource) {
+			list = append(list, *v)
+		}
+	}
+	return list, nil
+}
+
+func mismatchedView(name string, shared bool) *models.SavedFilterView {
+	return &models.SavedFilterView{
+		Name:     name,
+		Resource: models.ResourceShopeeSettled,
+		Shared:   shared,
+		Filters: &models.FilterGroup{Logic: "AND", Conditions: []models.FilterCondition{
+			{Field: "is_data_mismatch", Operator: models.FilterOpEquals, Value: true},
+		}},
+		Sort: models.SortList{{Field: "waktu_pesanan_dibuat", Direction: "desc"}},
+	}
+}
+
+func TestFilterViewOwnership(t *testing.T) {
+	ctx := context.Background()
+	svc := NewFilterViewService(newFakeFilterViewRepo())
+
+	shared := mismatchedView("Mismatched", true)
+	if err := svc.Create(ctx, shared, "ana"); err != nil {
+		t.Fatal(err)
+	}
+	private := mismatchedView("Mine", false)
+	if err := svc.Create(ctx, private, "ana"); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Create(ctx, mismatchedView("Mismatched", true), "ana"); !errors.Is(err, ErrFilterViewExists) {
+		t.Errorf("duplicate name: got %v", err)
+	}
+	if err := svc.Create(ctx, mismatchedView("Anon", true), ""); !errors.Is(err, models.ErrInvalidFilterView) {
+		t.Errorf("missing user: got %v", err)
+	}
+
+	if _, err := svc.Get(ctx, shared.ID, "budi"); err != nil {
+		t.Errorf("shared view should be visible: %v", err)
+	}
+	if _, err := svc.Get(ctx, private.ID, "budi"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("private view should be hidden, got %v", err)
+	}
+	shared.Name = "Renamed"
+	if err := svc.Update(ctx, shared, "budi"); !errors.Is(err, ErrFilterViewForbidden) {
+		t.Errorf("non-owner update: got %v", err)
+	}
+	if err := svc.Delete(ctx, shared.ID, "budi"); !errors.Is(err, ErrFilterViewForbidden) {
+		t.Errorf("non-owner delete: got %v", err)
+	}
+	list, _ := svc.List(ctx, models.ResourceShopeeSettled, "budi")
+	if len(list) != 1 {
+		t.Errorf("budi should see only the shared view, got %d", len(list))
+	}
+}
+
+func TestFilterViewResolvePrefersOwnView(t *testing.T) {
+	ctx := context.Background()
+	svc := NewFilterViewService(newFakeFilterViewRepo())
+	team := mismatchedView("Mismatched", true)
+	svc.Create(ctx, team, "ana")
+	own := mismatchedView("Mismatched", false)
+	svc.Create(ctx, own, "budi")
+
+	v, err := svc.ResolveView(ctx, models.ResourceShopeeSettled, "Mismatched", "budi")
+	if err != nil || v.ID != own.ID {
+		t.Fatalf("budi should get their own view, got %+v, %v", v, err)
+	}
+	v, err = svc.ResolveView(ctx, models.ResourceShopeeSettled, "Mismatched", "citra")
+	if err != nil || v.ID != team.ID {
+		t.Fatalf("citra should get the shared view, got %+v, %v", v, err)
+	}
+	if _, err := svc.ResolveView(ctx, models.ResourceJournalEntries, "1", "ana"); !errors.Is(err, models.ErrInvalidFilterView) {
+		t.Errorf("view for another resource: got %v", err)
+	}
+}
@@ -0,0 +1,498 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/cache"
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
+	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
+)
+
+// retainedEarningsCodes are the equity accounts profit is closed into
+// (Laba Ditahan and Laba/Rugi Tahun Berjalan). Movements on them are treated
+// as profit for the period rather than as owner transactions.
+var retainedEarningsCodes = []string{"3.2", "3.3"}
+
+// FinancialStatementJournalRepo defines the journal queries the statements need.
+type FinancialStatementJournalRepo interface {
+	GetAccountBalancesAsOf(ctx context.Context, shop string, asOfDate time.Time) ([]repository.AccountBalance, error)
+	GetAccountBalancesBetween(ctx context.Context, shop string, from, to time.Time) ([]repository.AccountBalance, error)
+	GetAccountActivityBetween(ctx context.Context, shop string, from, to time.Time) ([]repository.AccountActivity, error)
+}
+
+// CashAccountSource lists the cash and bank accounts shown on the Kas page.
+type CashAccountSource interface {
+	List(ctx context.Context) ([]models.AssetAccount, error)
+}
+
+// TrialBalanceRow is one account in the trial balance. Amounts are debit
+// positive. Group rows include the totals of their child accounts.
+type TrialBalanceRow struct {
+	AccountID   int64        `json:"account_id"`
+	AccountCode string       `json:"account_code"`
+	AccountName string       `json:"account_name"`
+	AccountType string       `json:"account_type"`
+	ParentID    *int64       `json:"parent_id"`
+	Level       int          `json:"level"`
+	Group       bool         `json:"group,omitempty"`
+	Opening     money.Amount `json:"opening"`
+	Debit       money.Amount `json:"debit"`
+	Credit      money.Amount `json:"credit"`
+	Closing     money.Amount `json:"closing"`
+}
+
+// TrialBalance lists opening balance, turnover and closing balance per
+// account for a period. Totals count every account once, so in balanced
+// books TotalDebit equals TotalCredit and the opening and closing totals are zero.
+type TrialBalance struct {
+	Store        string            `json:"store"`
+	From         time.Time         `json:"from"`
+	To           time.Time         `json:"to"`
+	Rows         []TrialBalanceRow `json:"rows"`
+	TotalOpening money.Amount      `json:"total_opening"`
+	TotalDebit   money.Amount      `json:"total_debit"`
+	TotalCredit  money.Amount      `json:"total_credit"`
+	TotalClosing money.Amount      `json:"total_closing"`
+	Balanced     bool              `json:"balanced"`
+	Previous     *TrialBalance     `json:"previous,omitempty"`
+}
+
+// CashFlowLine is one line of a cash-flow section. Positive amounts are
+// cash inflows.
+type CashFlowLine struct {
+	Label       string       `json:"label"`
+	AccountCode string       `json:"account_code,omitempty"`
+	Amount      money.Amount `json:"amount"`
+}
+
+// CashFlowSection groups the lines of operating, investing or financing
+// activities.
+type CashFlowSection struct {
+	Title string         `json:"title"`
+	Lines []CashFlowLine `json:"lines"`
+	Total money.Amount   `json:"total"`
+}
+
+// CashFlowStatement is an indirect-method cash-flow statement. Unexplained
+// is the difference between the actual change in cash and the change
+// explained by the sections; it is zero when every journal balances.
+type CashFlowStatement struct {
+	Store       string             `json:"store"`
+	From        time.Time          `json:"from"`
+	To          time.Time          `json:"to"`
+	NetIncome   money.Amount       `json:"net_income"`
+	Operating   CashFlowSection    `json:"operating"`
+	Investing   CashFlowSection    `json:"investing"`
+	Financing   CashFlowSection    `json:"financing"`
+	NetChange   money.Amount       `json:"net_change"`
+	OpeningCash money.Amount       `json:"opening_cash"`
+	ClosingCash money.Amount       `json:"closing_cash"`
+	Unexplained money.Amount       `json:"unexplained"`
+	Previous    *CashFlowStatement `json:"previous,omitempty"`
+}
+
+// EquityComponent is the movement of one equity account. Amounts are
+// credit positive. The component without an account code holds profit that
+// has not yet been closed into equity.
+type EquityComponent struct {
+	AccountID   int64        `json:"account_id,omitempty"`
+	AccountCode string       `json:"account_code,omitempty"`
+	AccountName string       `json:"account_name"`
+	Opening     money.Amount `json:"opening"`
+	Movement    money.Amount `json:"movement"`
+	Closing     money.Amount `json:"closing"`
+}
+
+// EquityStatement is the statement of changes in equity for a period.
+type EquityStatement struct {
+	Store         string            `json:"store"`
+	From          time.Time         `json:"from"`
+	To            time.Time         `json:"to"`
+	Components    []EquityComponent `json:"components"`
+	OpeningEquity money.Amount      `json:"opening_equity"`
+	NetIncome     money.Amount      `json:"net_income"`
+	Contributions money.Amount      `json:"contributions"`
+	Withdrawals   money.Amount      `json:"withdrawals"`
+	ClosingEquity money.Amount      `json:"closing_equity"`
+	Previous      *EquityStatement  `json:"previous,omitempty"`
+}
+
+// FinancialStatementService builds the trial balance, cash-flow statement
+// and statement of changes in equity from the daily balance snapshots.
+type FinancialStatementService struct {
+	jr    FinancialStatementJournalRepo
+	cash  CashAccountSource
+	cache Cache
+}
+
+// NewFinancialStatementService constructs a FinancialStatementService.
+func NewFinancialStatementService(jr FinancialStatementJournalRepo, cash CashAccountSource) *FinancialStatementService {
+	return &FinancialStatementService{jr: jr, cash: cash}
+}
+
+// SetCache enables caching of computed statements. Entries are tagged with
+// cache.TagJournals so any journal write invalidates them.
+func (s *FinancialStatementService) SetCache(c Cache) {
+	s.cache = c
+}
+
+// PreviousPeriod returns the period of the same length immediately before
+// from..to. Whole calendar months map to the same number of months before.
+func PreviousPeriod(from, to time.Time) (time.Time, time.Time) {
+	prevTo := from.Add(-time.Second)
+	if from.Day() == 1 && to.AddDate(0, 0, 1).Day() == 1 {
+		months := (to.Year()-from.Year())*12 + int(to.Month()-from.Month()) + 1
+		return from.AddDate(0, -months, 0), prevTo
+	}
+	days := int(to.Sub(from).Hours()/24) + 1
+	return from.AddDate(0, 0, -days), prevTo
+}
+
+func statementKey(name, store string, from, to time.Time, comparison bool) string {
+	return fmt.Sprintf("report:%s:%s:%s:%s:%t", name, store, from.Format(time.RFC3339), to.Format(time.RFC3339), comparison)
+}
+
+// GetTrialBalance returns the trial balance for store (all stores when
+// empty) between from and to, optionally with the previous period.
+func (s *FinancialStatementService) GetTrialBalance(ctx context.Context, store string, from, to time.Time, comparison bool) (*TrialBalance, error) {
+	tags := []string{cache.TagJournals, cache.TagReports}
+	return cachedJSON(ctx, s.cache, statementKey("trialbalance", store, from, to, comparison), reportCacheTTL, tags, func() (*TrialBalance, error) {
+		tb, err := s.computeTrialBalance(ctx, store, from, to)
+		if err != nil || !comparison {
+			return tb, err
+		}
+		pf, pt := PreviousPeriod(from, to)
+		if tb.Previous, err = s.computeTrialBalance(ctx, store, pf, pt); err != nil {
+			return nil, err
+		}
+		return tb, nil
+	})
+}
+
+func (s *FinancialStatementService) computeTrialBalance(ctx context.Context, store string, from, to time.Time) (*TrialBalance, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("to must not be before from")
+	}
+	opening, err := s.jr.GetAccountBalancesAsOf(ctx, store, from.Add(-time.Second))
+	if err != nil {
+		return nil, err
+	}
+	activity, err := s.jr.GetAccountActivityBetween(ctx, store, from, to)
+	if err != nil {
+		return nil, err
+	}
+	openByID := make(map[int64]money.Amount, len(opening))
+	for _, ab := range opening {
+		openByID[ab.AccountID] = ab.Balance
+	}
+
+	tb := &TrialBalance{Store: store, From: from, To: to, Rows: []TrialBalanceRow{}}
+	own := make(map[int64]*TrialBalanceRow, len(activity))
+	children := make(map[int64][]int64)
+	var roots []int64
+	for _, a := range activity {
+		row := &TrialBalanceRow{
+			AccountID: a.AccountID, AccountCode: a.AccountCode, AccountName: a.AccountName,
+			AccountType: a.AccountType, ParentID: a.ParentID,
+			Opening: openByID[a.AccountID], Debit: a.Debit, Credit: a.Credit,
+		}
+		row.Closing = row.Opening + row.Debit - row.Credit
+		own[a.AccountID] = row
+		tb.TotalOpening += row.Opening
+		tb.TotalDebit += row.Debit
+		tb.TotalCredit += row.Credit
+		tb.TotalClosing += row.Closing
+	}
+	for _, a := range activity {
+		if a.ParentID != nil && own[*a.ParentID] != nil {
+			children[*a.ParentID] = append(children[*a.ParentID], a.AccountID)
+		} else {
+			roots = append(roots, a.AccountID)
+		}
+	}
+	byCode := func(ids []int64) {
+		sort.Slice(ids, func(i, j int) bool {
+			return compareAccountCodes(own[ids[i]].AccountCode, own[ids[j]].AccountCode) < 0
+		})
+	}
+
+	// Depth-first walk emitting parents before children, rolling child
+	// totals up into their parent rows.
+	var walk func(id int64, level int) TrialBalanceRow
+	walk = func(id int64, level int) TrialBalanceRow {
+		row := *own[id]
+		row.Level = level
+		idx := len(tb.Rows)
+		tb.Rows = append(tb.Rows, row)
+		kids := children[id]
+		byCode(kids)
+		for _, k := range kids {
+			c := walk(k, level+1)
+			row.Opening += c.Opening
+			row.Debit += c.Debit
+			row.Credit += c.Credit
+			row.Closing += c.Closing
+		}
+		row.Group = len(kids) > 0
+		tb.Rows[idx] = row
+		return row
+	}
+	byCode(roots)
+	for _, id := range roots {
+		walk(id, 0)
+	}
+
+	// Drop accounts without balances or activity.
+	rows := tb.Rows[:0]
+	for _, r := range tb.Rows {
+		if !r.Opening.IsZero() || !r.Debit.IsZero() || !r.Credit.IsZero() || !r.Closing.IsZero() {
+			rows = append(rows, r)
+		}
+	}
+	tb.Rows = rows
+	tb.Balanced = tb.TotalDebit == tb.TotalCredit && tb.TotalClosing.IsZero()
+	return tb, nil
+}
+
+// GetCashFlow returns the indirect-method cash-flow statement for store
+// between from and to, optionally with the previous period.
+func (s *FinancialStatementService) GetCashFlow(ctx context.Context, store string, from, to time.Time, comparison bool) (*CashFlowStatement, error) {
+	tags := []string{cache.TagJournals, cache.TagReports}
+	return cachedJSON(ctx, s.cache, statementKey("cashflow", store, from, to, comparison), reportCacheTTL, tags, func() (*CashFlowStatement, error) {
+		cf, err := s.computeCashFlow(ctx, store, from, to)
+		if err != nil || !comparison {
+			return cf, err
+		}
+		pf, pt := PreviousPeriod(from, to)
+		if cf.Previous, err = s.computeCashFlow(ctx, store, pf, pt); err != nil {
+			return nil, err
+		}
+		return cf, nil
+	})
+}
+
+func (s *FinancialStatementService) computeCashFlow(ctx context.Context, store string, from, to time.Time) (*CashFlowStatement, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("to must not be before from")
+	}
+	cashIDs, err := s.cashAccountIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	opening, err := s.jr.GetAccountBalancesAsOf(ctx, store, from.Add(-time.Second))
+	if err != nil {
+		return nil, err
+	}
+	changes, err := s.jr.GetAccountBalancesBetween(ctx, store, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	cf := &CashFlowStatement{
+		Store: store, From: from, To: to,
+		Operating: CashFlowSection{Title: "Operating activities", Lines: []CashFlowLine{}},
+		Investing: CashFlowSection{Title: "Investing activities", Lines: []CashFlowLine{}},
+		Financing: CashFlowSection{Title: "Financing activities", Lines: []CashFlowLine{}},
+	}
+	for _, ab := range opening {
+		if isCashAccount(ab, cashIDs) {
+			cf.OpeningCash += ab.Balance
+		}
+	}
+
+	var cashChange money.Amount
+	var adjustments, investing, financing []CashFlowLine
+	for _, ab := range changes {
+		if ab.Balance.IsZero() {
+			continue
+		}
+		if isCashAccount(ab, cashIDs) {
+			cashChange += ab.Balance
+			continue
+		}
+		// An increase in a non-cash debit balance uses cash, so flip the sign.
+		line := CashFlowLine{Label: ab.AccountName, AccountCode: ab.AccountCode, Amount: -ab.Balance}
+		code := ab.AccountCode
+		switch {
+		case isIncomeAccount(ab), isRetainedEarnings(code):
+			// Profit closed into retained earnings is still profit, as
+			// in the equity statement.
+			cf.NetIncome += line.Amount
+		case ab.AccountType == "ContraAsset":
+			line.Label = "Depreciation and amortisation: " + ab.AccountName
+			adjustments = append(adjustments, line)
+		case codeUnder(code, "1.1"), codeUnder(code, "2.1"):
+			line.Label = "Change in " + ab.AccountName
+			adjustments = append(adjustments, line)
+		case codeUnder(code, "1"):
+			investing = append(investing, line)
+		case codeUnder(code, "2"), codeUnder(code, "3"):
+			financing = append(financing, line)
+		default:
+			adjustments = append(adjustments, line)
+		}
+	}
+
+	cf.Operating.Lines = append(cf.Operating.Lines, CashFlowLine{Label: "Net income", Amount: cf.NetIncome})
+	cf.Operating.Lines = append(cf.Operating.Lines, adjustments...)
+	cf.Investing.Lines = append(cf.Investing.Lines, investing...)
+	cf.Financing.Lines = append(cf.Financing.Lines, financing...)
+	for _, sec := range []*CashFlowSection{&cf.Operating, &cf.Investing, &cf.Financing} {
+		for _, l := range sec.Lines {
+			sec.Total += l.Amount
+		}
+		cf.NetChange += sec.Total
+	}
+	cf.ClosingCash = cf.OpeningCash + cashChange
+	cf.Unexplained = cashChange - cf.NetChange
+	return cf, nil
+}
+
+// GetEquityChanges returns the statement of changes in equity for store
+// between from and to, optionally with the previous period.
+func (s *FinancialStatementService) GetEquityChanges(ctx context.Context, store string, from, to time.Time, comparison bool) (*EquityStatement, error) {
+	tags := []string{cache.TagJournals, cache.TagReports}
+	return cachedJSON(ctx, s.cache, statementKey("equitychanges", store, from, to, comparison), reportCacheTTL, tags, func() (*EquityStatement, error) {
+		es, err := s.computeEquityChanges(ctx, store, from, to)
+		if err != nil || !comparison {
+			return es, err
+		}
+		pf, pt := PreviousPeriod(from, to)
+		if es.Previous, err = s.computeEquityChanges(ctx, store, pf, pt); err != nil {
+			return nil, err
+		}
+		return es, nil
+	})
+}
+
+func (s *FinancialStatementService) computeEquityChanges(ctx context.Context, store string, from, to time.Time) (*EquityStatement, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("to must not be before from")
+	}
+	opening, err := s.jr.GetAccountBalancesAsOf(ctx, store, from.Add(-time.Second))
+	if err != nil {
+		return nil, err
+	}
+	changes, err := s.jr.GetAccountBalancesBetween(ctx, store, from, to)
+	if err != nil {
+		return nil, err
+	}
+	movement := make(map[int64]money.Amount, len(changes))
+	for _, ab := range changes {
+		movement[ab.AccountID] = -ab.Balance
+	}
+
+	es := &EquityStatement{Store: store, From: from, To: to, Components: []EquityComponent{}}
+	unclosed := EquityComponent{AccountName: "Current period profit (unclosed)"}
+	for _, ab := range opening {
+		mv := movement[ab.AccountID]
+		switch {
+		case isIncomeAccount(ab):
+			unclosed.Opening -= ab.Balance
+			unclosed.Movement += mv
+			es.NetIncome += mv
+			continue
+		case !isEquityAccount(ab):
+			continue
+		case isRetainedEarnings(ab.AccountCode):
+			es.NetIncome += mv
+		case ab.AccountType == "ContraEquity":
+			es.Withdrawals += mv
+		default:
+			es.Contributions += mv
+		}
+		if ab.Balance.IsZero() && mv.IsZero() {
+			continue
+		}
+		es.Components = append(es.Components, EquityComponent{
+			AccountID: ab.AccountID, AccountCode: ab.AccountCode, AccountName: ab.AccountName,
+			Opening: -ab.Balance, Movement: mv, Closing: -ab.Balance + mv,
+		})
+	}
+	unclosed.Closing = unclosed.Opening + unclosed.Movement
+	es.Components = append(es.Components, unclosed)
+	for _, c := range es.Components {
+		es.OpeningEquity += c.Opening
+		es.ClosingEquity += c.Closing
+	}
+	return es, nil
+}
+
+func (s *FinancialStatementService) cashAccountIDs(ctx context.Context) (map[int64]bool, error) {
+	ids := make(map[int64]bool)
+	if s.cash == nil {
+		return ids, nil
+	}
+	list, err := s.cash.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range list {
+		ids[a.AccountID] = true
+	}
+	return ids, nil
+}
+
+func isCashAccount(ab repository.AccountBalance, ids map[int64]bool) bool {
+	return ids[ab.AccountID] || ab.AccountType == "Kas"
+}
+
+// isIncomeAccount reports whether the account belongs to the profit and
+// loss. The equity accounts profit is closed into are not included; see
+// isRetainedEarnings.
+func isIncomeAccount(ab repository.AccountBalance) bool {
+	switch ab.AccountType {
+	case "Revenue", "Expense":
+		return true
+	}
+	return codeUnder(ab.AccountCode, "4") || codeUnder(ab.AccountCode, "5")
+}
+
+func isEquityAccount(ab repository.AccountBalance) bool {
+	switch ab.AccountType {
+	case "Equity", "ContraEquity":
+		return true
+	}
+	return codeUnder(ab.AccountCode, "3")
+}
+
+func isRetainedEarnings(code string) bool {
+	for _, c := range retainedEarningsCodes {
+		if codeUnder(code, c) {
+			return true
+		}
+	}
+	return false
+}
+
+// codeUnder reports whether code is prefix or one of its sub-accounts.
+func codeUnder(code, prefix string) bool {
+	return code == prefix || strings.HasPrefix(code, prefix+".")
+}
+
+// compareAccountCodes orders dotted account codes numerically, so 1.1.2
+// sorts before 1.1.10.
+func compareAccountCodes(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if as[i] == bs[i] {
+			continue
+		}
+		ai, aerr := strconv.Atoi(as[i])
+		bi, berr := strconv.Atoi(bs[i])
+		if aerr == nil && berr == nil {
+			if ai < bi {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(as[i], bs[i])
+	}
+	return len(as) - len(bs)
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
+	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
+)
+
+type fsAccount struct {
+	id                     int64
+	code, name, typ        string
+	parent                 int64
+	opening, debit, credit money.Amount
+}
+
+// fakeStatementRepo returns the same opening balances and period activity
+// for every store and date range.
+type fakeStatementRepo struct{ accounts []fsAccount }
+
+func (f *fakeStatementRepo) balances(val func(a fsAccount) money.Amount) []repository.AccountBalance {
+	var out []repository.AccountBalance
+	for _, a := range f.accounts {
+		ab := repository.AccountBalance{AccountID: a.id, AccountCode: a.code, AccountName: a.name, AccountType: a.typ, Balance: val(a)}
+		if a.parent != 0 {
+			p := a.parent
+			ab.ParentID = &p
+		}
+		out = append(out, ab)
+	}
+	return out
+}
+
+func (f *fakeStatementRepo) GetAccountBalancesAsOf(ctx context.Context, shop string, asOf time.Time) ([]repository.AccountBalance, error) {
+	return f.balances(func(a fsAccount) money.Amount { return a.opening }), nil
+}
+
+func (f *fakeStatementRepo) GetAccountBalancesBetween(ctx context.Context, shop string, from, to time.Time) ([]repository.AccountBalance, error) {
+	return f.balances(func(a fsAccount) money.Amount { return a.debit - a.credit }), nil
+}
+
+func (f *fakeStatementRepo) GetAccountActivityBetween(ctx context.Context, shop string, from, to time.Time) ([]repository.AccountActivity, error) {
+	var out []repository.AccountActivity
+	for _, ab := range f.balances(func(fsAccount) money.Amount { return 0 }) {
+		a := f.find(ab.AccountID)
+		out = append(out, repository.AccountActivity{
+			AccountID: ab.AccountID, AccountCode: ab.AccountCode, AccountName: ab.AccountName,
+			AccountType: ab.AccountType, ParentID: ab.ParentID, Debit: a.debit, Credit: a.credit,
+		})
+	}
+	return out, nil
+}
+
+func (f *fakeStatementRepo) find(id int64) fsAccount {
+	for _, a := range f.accounts {
+		if a.id == id {
+			return a
+		}
+	}
+	return fsAccount{}
+}
+
+type fakeCashAccounts []int64
+
+func (f fakeCashAccounts) List(ctx context.Context) ([]models.AssetAccount, error) {
+	var out []models.AssetAccount
+	for _, id := range f {
+		out = append(out, models.AssetAccount{AccountID: id})
+	}
+	return out, nil
+}
+
+// A month of trading: opening capital 1400 held as cash and stock, a cash
+// sale of 500 costing 300, a vehicle bought for 200 and depreciated by 20,
+// stock of 100 bought on credit and a withdrawal of 50.
+func newStatementFixture() *FinancialStatementService {
+	repo := &fakeStatementRepo{accounts: []fsAccount{
+		{id: 1, code: "1", name: "Aset", typ: "Asset"},
+		{id: 11, code: "1.1", name: "Aset Lancar", typ: "Asset", parent: 1},
+		{id: 1001, code: "1.1.1", name: "Kas", typ: "Kas", parent: 11, opening: money.New(1000), debit: money.New(500), credit: money.New(250)},
+		{id: 1005, code: "1.1.5", name: "Persediaan", typ: "Asset", parent: 11, opening: money.New(400), debit: money.New(100), credit: money.New(300)},
+		{id: 11014, code: "1.1.14", name: "Seabank", typ: "Asset", parent: 11},
+		{id: 12, code: "1.2", name: "Aset Tetap", typ: "Asset", parent: 1},
+		{id: 1204, code: "1.2.4", name: "Kendaraan", typ: "Asset", parent: 12, debit: money.New(200)},
+		{id: 1205, code: "1.2.5", name: "Akumulasi Penyusutan Kendaraan", typ: "ContraAsset", parent: 12, credit: money.New(20)},
+		{id: 2101, code: "2.1.1", name: "Utang Dagang", typ: "Liability", credit: money.New(100)},
+		{id: 31, code: "3.1", name: "Modal Disetor", typ: "Equity", opening: money.New(-1400)},
+		{id: 34, code: "3.4", name: "Prive", typ: "ContraEquity", debit: money.New(50)},
+		{id: 4001, code: "4.1", name: "Pendapatan Operasional", typ: "Revenue", credit: money.New(500)},
+		{id: 5001, code: "5.1", name: "Harga Pokok Penjualan", typ: "Expense", debit: money.New(320)},
+	}}
+	return NewFinancialStatementService(repo, fakeCashAccounts{11014})
+}
+
+var fsFrom = time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+var fsTo = time.Date(2024, 5, 31, 23, 59, 59, 0, time.UTC)
+
+func TestTrialBalanceRollsUpParents(t *testing.T) {
+	svc := newStatementFixture()
+	tb, err := svc.GetTrialBalance(context.Background(), "", fsFrom, fsTo, true)
+	if err != nil {
+		t.Fatalf("GetTrialBalance: %v", err)
+	}
+	if !tb.Balanced || tb.TotalDebit != money.New(1170) || tb.TotalCredit != money.New(1170) {
+		t.Errorf("unexpected totals: debit %s credit %s balanced %v", tb.TotalDebit, tb.TotalCredit, tb.Balanced)
+	}
+	if tb.Previous == nil {
+		t.Fatal("expected previous period")
+	}
+	var codes []string
+	for _, r := range tb.Rows {
+		codes = append(codes, r.AccountCode)
+		if r.AccountCode == "1.1" {
+			if !r.Group || r.Level != 1 || r.Opening != money.New(1400) || r.Closing != money.New(1450) {
+				t.Errorf("unexpected current assets row %+v", r)
+			}
+		}
+	}
+	// Seabank has no balance and is dropped; 1.1.5 sorts before 1.2.
+	want := []string{"1", "1.1", "1.1.1", "1.1.5", "1.2", "1.2.4", "1.2.5", "2.1.1", "3.1", "3.4", "4.1", "5.1"}
+	if len(codes) != len(want) {
+		t.Fatalf("rows = %v, want %v", codes, want)
+	}
+	for i := range want {
+		if codes[i] != want[i] {
+			t.Fatalf("rows = %v, want %v", codes, want)
+		}
+	}
+}
+
+func TestCashFlowIndirect(t *testing.T) {
+	svc := newStatementFixture()
+	cf, err := svc.GetCashFlow(context.Background(), "", fsFrom, fsTo, false)
+	if err != nil {
+		t.Fatalf("GetCashFlow: %v", err)
+	}
+	checks := []struct {
+		name      string
+		got, want money.Amount
+	}{
+		{"net income", cf.NetIncome, money.New(180)},
+		{"operating", cf.Operating.Total, money.New(500)},
+		{"investing", cf.Investing.Total, money.New(-200)},
+		{"financing", cf.Financing.Total, money.New(-50)},
+		{"net change", cf.NetChange, money.New(250)},
+		{"opening cash", cf.OpeningCash, money.New(1000)},
+		{"closing cash", cf.ClosingCash, money.New(1250)},
+		{"unexplained", cf.Unexplained, money.Zero},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("%s = %s, want %s", c.name, c.got, c.want)
+		}
+	}
+	if cf.Operating.Lines[0].Label != "Net income" {
+		t.Errorf("operating section should start with net income: %+v", cf.Operating.Lines)
+	}
+}
+
+func TestEquityChanges(t *testing.T) {
+	svc := newStatementFixture()
+	es, err := svc.GetEquityChanges(context.Background(), "", fsFrom, fsTo, false)
+	if err != nil {
+		t.Fatalf("GetEquityChanges: %v", err)
+	}
+	if es.OpeningEquity != money.New(1400) || es.NetIncome != money.New(180) ||
+		es.Withdrawals != money.New(-50) || es.Contributions != money.Zero || es.ClosingEquity != money.New(1530) {
+		t.Errorf("unexpected equity statement %+v", es)
+	}
+}
+
+func TestClosedProfitCountsAsNetIncomeInBothStatements(t *testing.T) {
+	svc := newStatementFixture()
+	repo := svc.jr.(*fakeStatementRepo)
+	// Close the period's profit into Laba/Rugi Tahun Berjalan.
+	for i, a := range repo.accounts {
+		switch a.id {
+		case 4001:
+			repo.accounts[i].debit = money.New(500)
+		case 5001:
+			repo.accounts[i].credit = money.New(320)
+		}
+	}
+	repo.accounts = append(repo.accounts, fsAccount{id: 33, code: "3.3", name: "Laba/Rugi Tahun Berjalan", typ: "Equity", credit: money.New(180)})
+
+	cf, err := svc.GetCashFlow(context.Background(), "", fsFrom, fsTo, false)
+	if err != nil {
+		t.Fatalf("GetCashFlow: %v", err)
+	}
+	if cf.NetIncome != money.New(180) || cf.Financing.Total != money.New(-50) || cf.Unexplained != money.Zero {
+		t.Errorf("cash flow: net income %s financing %s unexplained %s", cf.NetIncome, cf.Financing.Total, cf.Unexplained)
+	}
+	es, err := svc.GetEquityChanges(context.Background(), "", fsFrom, fsTo, false)
+	if err != nil {
+		t.Fatalf("GetEquityChanges: %v", err)
+	}
+	if es.NetIncome != cf.NetIncome || es.Contributions != money.Zero {
+		t.Errorf("equity statement: net income %s contributions %s", es.NetIncome, es.Contributions)
+	}
+}
+
+func TestPreviousPeriod(t *testing.T) {
+	from, to := PreviousPeriod(fsFrom, fsTo)
+	if !from.Equal(time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)) || to.Format("2006-01-02") != "2024-04-30" {
+		t.Errorf("month: got %s .. %s", from, to)
+	}
+	from, to = PreviousPeriod(time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC), time.Date(2024, 5, 16, 23, 59, 59, 0, time.UTC))
+	if from.Format("2006-01-02") != "2024-05-03" || to.Format("2006-01-02") != "2024-05-09" {
+		t.Errorf("week: got %s .. %s", from, to)
+	}
+}
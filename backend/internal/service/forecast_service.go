@@ -3,9 +3,10 @@ package service
 import (
 	"context"
 	"fmt"
-	"log"
 	"math"
 	"time"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/logutil"
 )
 
 // ForecastDataPoint represents a single data point in time series
@@ -64,20 +65,24 @@ func NewForecastService(
 
 // GenerateForecast creates forecasts for sales, expenses, and profit
 func (fs *ForecastService) GenerateForecast(ctx context.Context, req ForecastRequest) (*ForecastResponse, error) {
-	log.Printf("Generating forecast for shop=%s, period=%s, from=%s to=%s", 
-		req.Shop, req.Period, req.StartDate.Format("2006-01-02"), req.ForecastTo.Format("2006-01-02"))
+	ctx = logutil.WithShop(ctx, req.Shop)
+	logutil.Info(ctx, "GenerateForecast", "Generating forecast", map[string]interface{}{
+		"period": req.Period,
+		"from":   req.StartDate.Format("2006-01-02"),
+		"to":     req.ForecastTo.Format("2006-01-02"),
+	})
 
 	// Generate sales forecast
 	salesForecast, err := fs.forecastSales(ctx, req)
 	if err != nil {
-		log.Printf("Failed to forecast sales: %v", err)
+		logutil.Error(ctx, "GenerateForecast", "Failed to forecast sales", err)
 		return nil, fmt.Errorf("failed to forecast sales: %w", err)
 	}
 
 	// Generate expenses forecast
 	expensesForecast, err := fs.forecastExpenses(ctx, req)
 	if err != nil {
-		log.Printf("Failed to forecast expenses: %v", err)
+		logutil.Error(ctx, "GenerateForecast", "Failed to forecast expenses", err)
 		return nil, fmt.Errorf("failed to forecast expenses: %w", err)
 	}
 
@@ -92,8 +97,11 @@ func (fs *ForecastService) GenerateForecast(ctx context.Context, req ForecastReq
 		Generated: time.Now(),
 	}
 
-	log.Printf("Forecast generated: Sales=%.2f, Expenses=%.2f, Profit=%.2f", 
-		salesForecast.TotalForecast, expensesForecast.TotalForecast, profitForecast.TotalForecast)
+	logutil.Info(ctx, "GenerateForecast", "Forecast generated", map[string]interface{}{
+		"sales":    salesForecast.TotalForecast,
+		"expenses": expensesForecast.TotalForecast,
+		"profit":   profitForecast.TotalForecast,
+	})
 
 	return response, nil
 }
@@ -309,7 +317,7 @@ func (fs *ForecastService) getDropshipSalesForPeriod(ctx context.Context, shop s
 
 	var total float64
 	for _, purchase := range purchases {
-		total += purchase.TotalTransaksi
+		total += purchase.TotalTransaksi.Float64()
 	}
 
 	return total, nil
@@ -327,7 +335,7 @@ func (fs *ForecastService) getExpensesForPeriod(ctx context.Context, shop string
 	for _, balance := range balances {
 		// Sum expense accounts (codes starting with 5)
 		if len(balance.AccountCode) > 0 && balance.AccountCode[0] == '5' {
-			total += balance.Balance
+			total += balance.Balance.Float64()
 		}
 	}
 
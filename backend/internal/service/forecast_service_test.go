@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
 )
 
@@ -18,12 +19,12 @@ func (m *mockForecastDropshipRepo) ListDropshipPurchasesByShopAndDate(ctx contex
 	return []models.DropshipPurchase{
 		{
 			KodePesanan:           "ORDER1",
-			TotalTransaksi:        1000000, // 1M IDR
+			TotalTransaksi:        money.New(1000000), // 1M IDR
 			WaktuPesananTerbuat:   time.Now().AddDate(0, -1, 0), // 1 month ago
 		},
 		{
 			KodePesanan:           "ORDER2", 
-			TotalTransaksi:        1200000, // 1.2M IDR
+			TotalTransaksi:        money.New(1200000), // 1.2M IDR
 			WaktuPesananTerbuat:   time.Now().AddDate(0, 0, -15), // 15 days ago
 		},
 	}, nil
@@ -36,11 +37,11 @@ func (m *mockForecastJournalRepo) GetAccountBalancesAsOf(ctx context.Context, sh
 	return []repository.AccountBalance{
 		{
 			AccountCode: "5000", // Expense account
-			Balance:     500000,  // 500K IDR
+			Balance:     money.New(500000),  // 500K IDR
 		},
 		{
 			AccountCode: "5100", // Another expense account
-			Balance:     300000,  // 300K IDR
+			Balance:     money.New(300000),  // 300K IDR
 		},
 	}, nil
 }
@@ -3,11 +3,12 @@ package service
 import (
 	"context"
 	"fmt"
-	"log"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/ramadhan22/dropship-erp/backend/internal/apperr"
 	"github.com/ramadhan22/dropship-erp/backend/internal/logutil"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
 )
 
@@ -16,6 +17,7 @@ type JournalRepoInterface interface {
 	InsertJournalLine(ctx context.Context, l *models.JournalLine) error
 	InsertJournalLines(ctx context.Context, lines []models.JournalLine) error
 	ListJournalEntries(ctx context.Context, from, to, desc string) ([]models.JournalEntry, error)
+	ListJournalEntriesFiltered(ctx context.Context, params *models.FilterParams) (*models.QueryResult, error)
 	GetJournalEntry(ctx context.Context, id int64) (*models.JournalEntry, error)
 	GetLinesByJournalID(ctx context.Context, id int64) ([]repository.JournalLineDetail, error)
 	ListEntriesBySourceID(ctx context.Context, sourceID string) ([]models.JournalEntry, error)
@@ -23,24 +25,40 @@ type JournalRepoInterface interface {
 }
 
 type JournalService struct {
-	db   *sqlx.DB
-	repo JournalRepoInterface
+	db    *sqlx.DB
+	repo  JournalRepoInterface
+	cache Cache
 }
 
 func NewJournalService(db *sqlx.DB, r JournalRepoInterface) *JournalService {
 	return &JournalService{db: db, repo: r}
 }
 
+// SetCache enables invalidation of cached balances and reports after
+// journal writes.
+func (s *JournalService) SetCache(c Cache) {
+	s.cache = c
+}
+
 func (s *JournalService) List(ctx context.Context, from, to, desc string) ([]models.JournalEntry, error) {
 	return s.repo.ListJournalEntries(ctx, from, to, desc)
 }
 
+// ListFiltered uses the filtering framework to fetch journal entries.
+func (s *JournalService) ListFiltered(ctx context.Context, params *models.FilterParams) (*models.QueryResult, error) {
+	return s.repo.ListJournalEntriesFiltered(ctx, params)
+}
+
 func (s *JournalService) Get(ctx context.Context, id int64) (*models.JournalEntry, error) {
 	return s.repo.GetJournalEntry(ctx, id)
 }
 
 func (s *JournalService) Delete(ctx context.Context, id int64) error {
-	return s.repo.DeleteJournalEntry(ctx, id)
+	if err := s.repo.DeleteJournalEntry(ctx, id); err != nil {
+		return err
+	}
+	invalidateCache(ctx, s.cache, journalWriteTags...)
+	return nil
 }
 
 func (s *JournalService) Lines(ctx context.Context, id int64) ([]repository.JournalLineDetail, error) {
@@ -83,8 +101,11 @@ func (s *JournalService) Create(
 	e *models.JournalEntry,
 	lines []models.JournalLine,
 ) (int64, error) {
-	log.Printf("JournalService.Create %s", e.SourceID)
-	var debit, credit float64
+	logutil.Debug(ctx, "JournalService.Create", "Creating journal entry", map[string]interface{}{
+		"source_type": e.SourceType,
+		"source_id":   e.SourceID,
+	})
+	var debit, credit money.Amount
 	for _, l := range lines {
 		if l.IsDebit {
 			debit += l.Amount
@@ -93,8 +114,8 @@ func (s *JournalService) Create(
 		}
 	}
 	if debit != credit {
-		logutil.Errorf("JournalService.Create imbalance debit %.2f credit %.2f", debit, credit)
-		return 0, fmt.Errorf("debits %.2f do not equal credits %.2f", debit, credit)
+		logutil.Errorf("JournalService.Create imbalance debit %s credit %s", debit, credit)
+		return 0, fmt.Errorf("%w: debits %s do not equal credits %s", apperr.ErrUnbalancedJournal, debit, credit)
 	}
 	if s.db == nil {
 		id, err := s.repo.CreateJournalEntry(ctx, e)
@@ -109,7 +130,10 @@ func (s *JournalService) Create(
 			logutil.Errorf("JournalService.Create lines error: %v", err)
 			return 0, err
 		}
-		log.Printf("JournalService.Create done id=%d", id)
+		invalidateCache(ctx, s.cache, journalWriteTags...)
+		logutil.Debug(ctx, "JournalService.Create", "Journal entry created", map[string]interface{}{
+			"journal_id": id,
+		})
 		return id, nil
 	}
 
@@ -136,7 +160,10 @@ func (s *JournalService) Create(
 		logutil.Errorf("JournalService.Create commit error: %v", err)
 		return 0, err
 	}
-	log.Printf("JournalService.Create done id=%d", id)
+	invalidateCache(ctx, s.cache, journalWriteTags...)
+	logutil.Debug(ctx, "JournalService.Create", "Journal entry created", map[string]interface{}{
+		"journal_id": id,
+	})
 	return id, nil
 }
 
@@ -148,7 +175,9 @@ func (s *JournalService) BulkCreateJournalEntries(ctx context.Context, entries [
 		return nil, nil
 	}
 
-	log.Printf("BulkCreateJournalEntries: processing %d entries", len(entries))
+	logutil.Debug(ctx, "BulkCreateJournalEntries", "Creating journal entries", map[string]interface{}{
+		"entries": len(entries),
+	})
 
 	tx, err := s.db.BeginTxx(ctx, nil)
 	if err != nil {
@@ -189,7 +218,10 @@ func (s *JournalService) BulkCreateJournalEntries(ctx context.Context, entries [
 		return nil, err
 	}
 
-	log.Printf("BulkCreateJournalEntries completed: created %d entries", len(entryIDs))
+	invalidateCache(ctx, s.cache, journalWriteTags...)
+	logutil.Info(ctx, "BulkCreateJournalEntries", "Journal entries created", map[string]interface{}{
+		"created": len(entryIDs),
+	})
 	return entryIDs, nil
 }
 
@@ -204,10 +236,10 @@ func (s *JournalService) validateJournalEntry(entry *models.JournalEntry, lines
 	}
 
 	// Calculate total debits and credits
-	var totalDebits, totalCredits float64
+	var totalDebits, totalCredits money.Amount
 	for _, line := range lines {
 		if line.Amount <= 0 {
-			return fmt.Errorf("line amount must be positive, got: %f", line.Amount)
+			return fmt.Errorf("line amount must be positive, got: %s", line.Amount)
 		}
 
 		if line.IsDebit {
@@ -217,60 +249,23 @@ func (s *JournalService) validateJournalEntry(entry *models.JournalEntry, lines
 		}
 	}
 
-	// Check if debits equal credits (within a small tolerance for floating point precision)
-	const tolerance = 0.01
-	if absFloat(totalDebits-totalCredits) > tolerance {
-		return fmt.Errorf("journal entry not balanced: debits=%.2f, credits=%.2f", totalDebits, totalCredits)
+	// Amounts are exact, so debits must equal credits to the sen
+	if totalDebits != totalCredits {
+		return fmt.Errorf("%w: debits=%s, credits=%s", apperr.ErrUnbalancedJournal, totalDebits, totalCredits)
 	}
 
 	return nil
 }
 
-// AutoBalanceJournalEntry automatically balances a journal entry by adjusting the last line
-func (s *JournalService) AutoBalanceJournalEntry(entry *models.JournalEntry, lines []models.JournalLine) []models.JournalLine {
-	if len(lines) == 0 {
-		return lines
-	}
-
-	var totalDebits, totalCredits float64
-	for i, line := range lines[:len(lines)-1] { // Exclude last line from calculation
-		if line.IsDebit {
-			totalDebits += line.Amount
-		} else {
-			totalCredits += line.Amount
-		}
-		_ = i // Suppress unused variable warning
-	}
-
-	// Adjust the last line to balance
-	lastLine := &lines[len(lines)-1]
-	difference := totalDebits - totalCredits
-
-	if difference > 0 {
-		// Need more credits
-		lastLine.IsDebit = false
-		lastLine.Amount = difference
-	} else if difference < 0 {
-		// Need more debits
-		lastLine.IsDebit = true
-		lastLine.Amount = -difference
-	} else {
-		// Already balanced, keep last line as is
-		if lastLine.Amount == 0 {
-			lastLine.Amount = 0.01 // Minimum amount
-		}
-	}
-
-	return lines
-}
-
 // BatchDeleteJournalEntries deletes multiple journal entries by source IDs in a single transaction
 func (s *JournalService) BatchDeleteJournalEntries(ctx context.Context, sourceIDs []string) error {
 	if len(sourceIDs) == 0 {
 		return nil
 	}
 
-	log.Printf("BatchDeleteJournalEntries: deleting entries for %d sources", len(sourceIDs))
+	logutil.Debug(ctx, "BatchDeleteJournalEntries", "Deleting journal entries", map[string]interface{}{
+		"sources": len(sourceIDs),
+	})
 
 	tx, err := s.db.BeginTxx(ctx, nil)
 	if err != nil {
@@ -301,14 +296,10 @@ func (s *JournalService) BatchDeleteJournalEntries(ctx context.Context, sourceID
 		return fmt.Errorf("failed to commit batch delete: %w", err)
 	}
 
-	log.Printf("BatchDeleteJournalEntries completed: deleted %d entries", deletedCount)
+	invalidateCache(ctx, s.cache, journalWriteTags...)
+	logutil.Info(ctx, "BatchDeleteJournalEntries", "Journal entries deleted", map[string]interface{}{
+		"sources": len(sourceIDs),
+		"deleted": deletedCount,
+	})
 	return nil
 }
-
-// absFloat returns the absolute value of a float64
-func absFloat(x float64) float64 {
-	if x < 0 {
-		return -x
-	}
-	return x
-}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
 )
 
@@ -38,6 +39,10 @@ func (f *fakeJournalRepo) ListJournalEntries(ctx context.Context, from, to, desc
 	return nil, nil
 }
 
+func (f *fakeJournalRepo) ListJournalEntriesFiltered(ctx context.Context, params *models.FilterParams) (*models.QueryResult, error) {
+	return &models.QueryResult{}, nil
+}
+
 func (f *fakeJournalRepo) GetJournalEntry(ctx context.Context, id int64) (*models.JournalEntry, error) {
 	return nil, nil
 }
@@ -60,8 +65,8 @@ func TestJournalServiceCreate_Balance(t *testing.T) {
 
 	entry := &models.JournalEntry{SourceType: "manual", SourceID: "1"}
 	lines := []models.JournalLine{
-		{AccountID: 1, IsDebit: true, Amount: 50},
-		{AccountID: 2, IsDebit: false, Amount: 50},
+		{AccountID: 1, IsDebit: true, Amount: money.New(50)},
+		{AccountID: 2, IsDebit: false, Amount: money.New(50)},
 	}
 	id, err := svc.Create(context.Background(), entry, lines)
 	if err != nil {
@@ -78,8 +83,8 @@ func TestJournalServiceCreate_Unbalanced(t *testing.T) {
 
 	entry := &models.JournalEntry{SourceType: "manual", SourceID: "1"}
 	lines := []models.JournalLine{
-		{AccountID: 1, IsDebit: true, Amount: 50},
-		{AccountID: 2, IsDebit: false, Amount: 40},
+		{AccountID: 1, IsDebit: true, Amount: money.New(50)},
+		{AccountID: 2, IsDebit: false, Amount: money.New(40)},
 	}
 	if _, err := svc.Create(context.Background(), entry, lines); err == nil {
 		t.Fatalf("expected error for unbalanced entry")
@@ -0,0 +1,315 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/cache"
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
+)
+
+// suspenseAccountID absorbs the difference of repaired unbalanced journals
+// (2.1.9 Suspense Selisih Jurnal).
+const suspenseAccountID = 21009
+
+var (
+	// ErrUnknownCheck is returned for a check name the checker does not know.
+	ErrUnknownCheck = errors.New("unknown integrity check")
+	// ErrNotRepairable is returned for checks that need a person to decide
+	// the fix, such as lines posted to a deleted account.
+	ErrNotRepairable = errors.New("integrity issue cannot be repaired automatically")
+	// ErrIssueResolved is returned when the issue to repair is no longer
+	// present, for example because it was repaired already.
+	ErrIssueResolved = errors.New("integrity issue no longer present")
+)
+
+// IntegrityChecks lists every check in the order they are reported.
+var IntegrityChecks = []string{
+	models.CheckUnbalancedJournal,
+	models.CheckDuplicateSource,
+	models.CheckSettledWithoutJournal,
+	models.CheckMissingAccount,
+	models.CheckStatusMismatch,
+}
+
+// repairableChecks maps the checks RepairIssue can fix to the action it
+// records in ledger_repairs.
+var repairableChecks = map[string]string{
+	models.CheckUnbalancedJournal:     "post_suspense_line",
+	models.CheckDuplicateSource:       "reverse_journal",
+	models.CheckSettledWithoutJournal: "repost_escrow",
+}
+
+// LedgerIntegrityRepo is the storage used by LedgerIntegrityService.
+type LedgerIntegrityRepo interface {
+	FindIssues(ctx context.Context, check string) ([]models.IntegrityIssue, error)
+	FindIssue(ctx context.Context, check, target string) (*models.IntegrityIssue, error)
+	JournalLines(ctx context.Context, journalID int64) ([]models.JournalLine, error)
+	InsertRepair(ctx context.Context, rep *models.LedgerRepair) error
+	ListRepairs(ctx context.Context, limit, offset int) ([]models.LedgerRepair, int, error)
+}
+
+// LedgerIntegrityJournalRepo posts the journals made by repairs.
+type LedgerIntegrityJournalRepo interface {
+	CreateJournalEntry(ctx context.Context, e *models.JournalEntry) (int64, error)
+	InsertJournalLine(ctx context.Context, l *models.JournalLine) error
+	InsertJournalLines(ctx context.Context, lines []models.JournalLine) error
+	GetJournalEntry(ctx context.Context, id int64) (*models.JournalEntry, error)
+}
+
+// EscrowReposter posts the escrow settlement of an invoice from Shopee.
+// ReconcileService implements it.
+type EscrowReposter interface {
+	UpdateShopeeStatus(ctx context.Context, invoice string) error
+}
+
+// LedgerIntegrityService scans the ledger for inconsistencies left by older
+// code and applies audited repairs. Repairs never delete journals: duplicates
+// are reversed and unbalanced journals get a suspense line.
+type LedgerIntegrityService struct {
+	db          *sqlx.DB
+	repo        LedgerIntegrityRepo
+	journalRepo LedgerIntegrityJournalRepo
+	escrow      EscrowReposter
+	cache       Cache
+}
+
+// NewLedgerIntegrityService constructs a LedgerIntegrityService. escrow may
+// be nil, in which case settled purchases without journals are only reported.
+func NewLedgerIntegrityService(db *sqlx.DB, repo LedgerIntegrityRepo, jr LedgerIntegrityJournalRepo, escrow EscrowReposter) *LedgerIntegrityService {
+	return &LedgerIntegrityService{db: db, repo: repo, journalRepo: jr, escrow: escrow}
+}
+
+// SetCache enables invalidation of cached reports after a repair.
+func (s *LedgerIntegrityService) SetCache(c Cache) {
+	s.cache = c
+}
+
+// Check runs the named checks, or all of them when none are given.
+func (s *LedgerIntegrityService) Check(ctx context.Context, checks ...string) (*models.IntegrityReport, error) {
+	if len(checks) == 0 {
+		checks = IntegrityChecks
+	}
+	report := &models.IntegrityReport{CheckedAt: time.Now(), Counts: map[string]int{}, Issues: []models.IntegrityIssue{}}
+	for _, check := range checks {
+		if !knownCheck(check) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownCheck, check)
+		}
+		issues, err := s.repo.FindIssues(ctx, check)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", check, err)
+		}
+		for i := range issues {
+			issues[i].Repairable = s.canRepair(check) && issueRepairable(&issues[i])
+		}
+		report.Counts[check] = len(issues)
+		report.Issues = append(report.Issues, issues...)
+	}
+	return report, nil
+}
+
+// ListRepairs returns the audit trail of repairs.
+func (s *LedgerIntegrityService) ListRepairs(ctx context.Context, limit, offset int) ([]models.LedgerRepair, int, error) {
+	return s.repo.ListRepairs(ctx, limit, offset)
+}
+
+// RepairIssue re-checks the issue of check at target and repairs it,
+// recording who did it in ledger_repairs.
+func (s *LedgerIntegrityService) RepairIssue(ctx context.Context, check, target, performedBy string) (*models.LedgerRepair, error) {
+	if !knownCheck(check) {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCheck, check)
+	}
+	if !s.canRepair(check) {
+		return nil, fmt.Errorf("%w: %s", ErrNotRepairable, check)
+	}
+	if performedBy == "" {
+		performedBy = "system"
+	}
+	rep := &models.LedgerRepair{Check: check, Target: target, Action: repairableChecks[check], PerformedBy: performedBy}
+
+	// Reposting escrow calls Shopee and commits on its own.
+	if check == models.CheckSettledWithoutJournal {
+		if _, err := s.findIssue(ctx, s.repo, check, target); err != nil {
+			return nil, err
+		}
+		if err := s.escrow.UpdateShopeeStatus(ctx, target); err != nil {
+			return nil, fmt.Errorf("repost escrow %s: %w", target, err)
+		}
+		rep.Detail = "escrow settlement reposted from Shopee"
+		if err := s.repo.InsertRepair(ctx, rep); err != nil {
+			return nil, err
+		}
+		invalidateCache(ctx, s.cache, cache.TagJournals, cache.TagReports)
+		return rep, nil
+	}
+
+	var tx *sqlx.Tx
+	repo, jr := s.repo, s.journalRepo
+	if s.db != nil {
+		var err error
+		tx, err = s.db.BeginTxx(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		defer tx.Rollback()
+		repo = repository.NewLedgerIntegrityRepo(tx)
+		jr = repository.NewJournalRepo(tx)
+	}
+
+	issue, err := s.findIssue(ctx, repo, check, target)
+	if err != nil {
+		return nil, err
+	}
+	if !issueRepairable(issue) {
+		return nil, fmt.Errorf("%w: %s %s is only a possible duplicate", ErrNotRepairable, check, target)
+	}
+	switch check {
+	case models.CheckUnbalancedJournal:
+		err = postSuspenseLine(ctx, jr, issue, rep)
+	case models.CheckDuplicateSource:
+		err = reverseJournal(ctx, repo, jr, issue, rep)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.InsertRepair(ctx, rep); err != nil {
+		return nil, err
+	}
+	if tx != nil {
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+	}
+	invalidateCache(ctx, s.cache, cache.TagJournals, cache.TagReports)
+	return rep, nil
+}
+
+// RepairAll repairs every repairable issue of check and returns the repairs
+// made and the first error per failed target. Report-only issues are left
+// untouched.
+func (s *LedgerIntegrityService) RepairAll(ctx context.Context, check, performedBy string) ([]models.LedgerRepair, map[string]error, error) {
+	report, err := s.Check(ctx, check)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !s.canRepair(check) {
+		return nil, nil, fmt.Errorf("%w: %s", ErrNotRepairable, check)
+	}
+	done := []models.LedgerRepair{}
+	failed := map[string]error{}
+	for _, issue := range report.Issues {
+		if !issue.Repairable {
+			continue
+		}
+		rep, err := s.RepairIssue(ctx, check, issue.Target, performedBy)
+		if err != nil {
+			failed[issue.Target] = err
+			continue
+		}
+		done = append(done, *rep)
+	}
+	return done, failed, nil
+}
+
+func (s *LedgerIntegrityService) canRepair(check string) bool {
+	if _, ok := repairableChecks[check]; !ok {
+		return false
+	}
+	return check != models.CheckSettledWithoutJournal || s.escrow != nil
+}
+
+// issueRepairable reports whether issue may be repaired without a person
+// deciding. Duplicate source journals found only after normalising their
+// source ids may be distinct documents, so they are only reported.
+func issueRepairable(issue *models.IntegrityIssue) bool {
+	return issue.Check != models.CheckDuplicateSource || issue.Exact
+}
+
+func (s *LedgerIntegrityService) findIssue(ctx context.Context, repo LedgerIntegrityRepo, check, target string) (*models.IntegrityIssue, error) {
+	issue, err := repo.FindIssue(ctx, check, target)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s %s", ErrIssueResolved, check, target)
+	}
+	return issue, err
+}
+
+func knownCheck(check string) bool {
+	for _, c := range IntegrityChecks {
+		if c == check {
+			return true
+		}
+	}
+	return false
+}
+
+// postSuspenseLine balances a journal with one line on the suspense account
+// for the difference between its debits and credits.
+func postSuspenseLine(ctx context.Context, jr LedgerIntegrityJournalRepo, issue *models.IntegrityIssue, rep *models.LedgerRepair) error {
+	debit, credit := *issue.Debit, *issue.Credit
+	line := &models.JournalLine{
+		JournalID: *issue.JournalID,
+		AccountID: suspenseAccountID,
+		IsDebit:   credit > debit,
+		Amount:    (debit - credit).Abs(),
+		Memo:      ptrString("Integrity repair: balance journal"),
+	}
+	if err := jr.InsertJournalLine(ctx, line); err != nil {
+		return err
+	}
+	side := "credit"
+	if line.IsDebit {
+		side = "debit"
+	}
+	rep.JournalID = issue.JournalID
+	rep.Detail = fmt.Sprintf("debits %s, credits %s: posted %s %s to suspense", debit, credit, side, line.Amount)
+	return nil
+}
+
+// reverseJournal posts a journal with the lines of a duplicate swapped so
+// the duplicate no longer affects balances.
+func reverseJournal(ctx context.Context, repo LedgerIntegrityRepo, jr LedgerIntegrityJournalRepo, issue *models.IntegrityIssue, rep *models.LedgerRepair) error {
+	orig, err := jr.GetJournalEntry(ctx, *issue.JournalID)
+	if err != nil {
+		return err
+	}
+	lines, err := repo.JournalLines(ctx, orig.JournalID)
+	if err != nil {
+		return err
+	}
+	je := &models.JournalEntry{
+		EntryDate:    orig.EntryDate,
+		Description:  ptrString(fmt.Sprintf("Reversal of duplicate journal %d (%s %s)", orig.JournalID, orig.SourceType, orig.SourceID)),
+		SourceType:   "integrity_reversal",
+		SourceID:     strconv.FormatInt(orig.JournalID, 10),
+		ShopUsername: orig.ShopUsername,
+		Store:        orig.Store,
+		CreatedAt:    time.Now(),
+	}
+	id, err := jr.CreateJournalEntry(ctx, je)
+	if err != nil {
+		return err
+	}
+	reversed := make([]models.JournalLine, 0, len(lines))
+	for _, l := range lines {
+		reversed = append(reversed, models.JournalLine{
+			JournalID: id,
+			AccountID: l.AccountID,
+			IsDebit:   !l.IsDebit,
+			Amount:    l.Amount,
+			Memo:      ptrString(fmt.Sprintf("Reverse line %d", l.LineID)),
+		})
+	}
+	if err := jr.InsertJournalLines(ctx, reversed); err != nil {
+		return fmt.Errorf("reverse journal %d: %w", orig.JournalID, err)
+	}
+	rep.JournalID = &id
+	rep.Detail = fmt.Sprintf("reversed journal %d, %s", orig.JournalID, issue.Detail)
+	return nil
+}
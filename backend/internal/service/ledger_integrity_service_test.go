@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
+)
+
+type fakeIntegrityRepo struct {
+	issues  map[string][]models.IntegrityIssue
+	lines   map[int64][]models.JournalLine
+	repairs []models.LedgerRepair
+}
+
+func (f *fakeIntegrityRepo) FindIssues(ctx context.Context, check string) ([]models.IntegrityIssue, error) {
+	return append([]models.IntegrityIssue{}, f.issues[check]...), nil
+}
+
+func (f *fakeIntegrityRepo) FindIssue(ctx context.Context, check, target string) (*models.IntegrityIssue, error) {
+	for _, is := range f.issues[check] {
+		if is.Target == target {
+			cp := is
+			return &cp, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakeIntegrityRepo) JournalLines(ctx context.Context, id int64) ([]models.JournalLine, error) {
+	return f.lines[id], nil
+}
+
+func (f *fakeIntegrityRepo) InsertRepair(ctx context.Context, rep *models.LedgerRepair) error {
+	rep.ID = int64(len(f.repairs) + 1)
+	f.repairs = append(f.repairs, *rep)
+	return nil
+}
+
+func (f *fakeIntegrityRepo) ListRepairs(ctx context.Context, limit, offset int) ([]models.LedgerRepair, int, error) {
+	return f.repairs, len(f.repairs), nil
+}
+
+type fakeIntegrityJournal struct {
+	entries map[int64]*models.JournalEntry
+	lines   []models.JournalLine
+	nextID  int64
+}
+
+func (f *fakeIntegrityJournal) CreateJournalEntry(ctx context.Context, e *models.JournalEntry) (int64, error) {
+	f.nextID++
+	e.JournalID = f.nextID
+	f.entries[e.JournalID] = e
+	return e.JournalID, nil
+}
+
+func (f *fakeIntegrityJournal) InsertJournalLine(ctx context.Context, l *models.JournalLine) error {
+	f.lines = append(f.lines, *l)
+	return nil
+}
+
+func (f *fakeIntegrityJournal) InsertJournalLines(ctx context.Context, lines []models.JournalLine) error {
+	f.lines = append(f.lines, lines...)
+	return nil
+}
+
+func (f *fakeIntegrityJournal) GetJournalEntry(ctx context.Context, id int64) (*models.JournalEntry, error) {
+	if e, ok := f.entries[id]; ok {
+		return e, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func int64Ptr(v int64) *int64 { return &v }
+
+func TestLedgerIntegrityCheckMarksRepairable(t *testing.T) {
+	repo := &fakeIntegrityRepo{issues: map[string][]models.IntegrityIssue{
+		models.CheckSettledWithoutJournal: {{Check: models.CheckSettledWithoutJournal, Target: "INV-1"}},
+		models.CheckMissingAccount:        {{Check: models.CheckMissingAccount, Target: "7"}},
+	}}
+	svc := NewLedgerIntegrityService(nil, repo, &fakeIntegrityJournal{}, nil)
+
+	report, err := svc.Check(context.Background())
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	if len(report.Issues) != 2 || report.Counts[models.CheckUnbalancedJournal] != 0 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	for _, is := range report.Issues {
+		if is.Repairable {
+			t.Errorf("%s should not be repairable without an escrow reposter", is.Check)
+		}
+	}
+	if _, err := svc.Check(context.Background(), "bogus"); !errors.Is(err, ErrUnknownCheck) {
+		t.Errorf("expected ErrUnknownCheck, got %v", err)
+	}
+	if _, err := svc.RepairIssue(context.Background(), models.CheckMissingAccount, "7", "ana"); !errors.Is(err, ErrNotRepairable) {
+		t.Errorf("expected ErrNotRepairable, got %v", err)
+	}
+}
+
+func TestRepairUnbalancedJournalPostsSuspenseLine(t *testing.T) {
+	debit, credit := money.New(1000), money.New(900)
+	repo := &fakeIntegrityRepo{issues: map[string][]models.IntegrityIssue{
+		models.CheckUnbalancedJournal: {{Check: models.CheckUnbalancedJournal, Target: "42", JournalID: int64Ptr(42), Debit: &debit, Credit: &credit}},
+	}}
+	jr := &fakeIntegrityJournal{entries: map[int64]*models.JournalEntry{}}
+	svc := NewLedgerIntegrityService(nil, repo, jr, nil)
+
+	rep, err := svc.RepairIssue(context.Background(), models.CheckUnbalancedJournal, "42", "ana")
+	if err != nil {
+		t.Fatalf("repair: %v", err)
+	}
+	if len(jr.lines) != 1 {
+		t.Fatalf("expected one suspense line, got %+v", jr.lines)
+	}
+	l := jr.lines[0]
+	if l.JournalID != 42 || l.AccountID != suspenseAccountID || l.IsDebit || l.Amount != money.New(100) {
+		t.Errorf("unexpected suspense line %+v", l)
+	}
+	if len(repo.repairs) != 1 || rep.PerformedBy != "ana" || *rep.JournalID != 42 {
+		t.Errorf("repair not audited: %+v", repo.repairs)
+	}
+	if _, err := svc.RepairIssue(context.Background(), models.CheckUnbalancedJournal, "43", "ana"); !errors.Is(err, ErrIssueResolved) {
+		t.Errorf("expected ErrIssueResolved, got %v", err)
+	}
+}
+
+func TestRepairDuplicateReversesJournal(t *testing.T) {
+	repo := &fakeIntegrityRepo{
+		issues: map[string][]models.IntegrityIssue{
+			models.CheckDuplicateSource: {{Check: models.CheckDuplicateSource, Target: "9", JournalID: int64Ptr(9), Exact: true, Detail: "duplicate of journal 3"}},
+		},
+		lines: map[int64][]models.JournalLine{9: {
+			{LineID: 1, JournalID: 9, AccountID: 11010, IsDebit: true, Amount: money.New(500)},
+			{LineID: 2, JournalID: 9, AccountID: 41001, IsDebit: false, Amount: money.New(500)},
+		}},
+	}
+	jr := &fakeIntegrityJournal{
+		entries: map[int64]*models.JournalEntry{9: {JournalID: 9, SourceType: "shopee_escrow", SourceID: "inv-1"}},
+		nextID:  100,
+	}
+	svc := NewLedgerIntegrityService(nil, repo, jr, nil)
+
+	rep, err := svc.RepairIssue(context.Background(), models.CheckDuplicateSource, "9", "")
+	if err != nil {
+		t.Fatalf("repair: %v", err)
+	}
+	rev := jr.entries[101]
+	if rev == nil || rev.SourceType != "integrity_reversal" || rev.SourceID != "9" {
+		t.Fatalf("unexpected reversal entry %+v", rev)
+	}
+	if len(jr.lines) != 2 || jr.lines[0].IsDebit || !jr.lines[1].IsDebit || jr.lines[0].JournalID != 101 {
+		t.Errorf("lines not reversed: %+v", jr.lines)
+	}
+	if rep.PerformedBy != "system" || *rep.JournalID != 101 || rep.Action != "reverse_journal" {
+		t.Errorf("unexpected repair %+v", rep)
+	}
+}
+
+func TestRepairAllSkipsNormalizedDuplicates(t *testing.T) {
+	repo := &fakeIntegrityRepo{
+		issues: map[string][]models.IntegrityIssue{
+			models.CheckDuplicateSource: {
+				{Check: models.CheckDuplicateSource, Target: "9", JournalID: int64Ptr(9), Exact: true, Detail: "duplicate of journal 3"},
+				{Check: models.CheckDuplicateSource, Target: "12", JournalID: int64Ptr(12), Detail: "possible duplicate of journal 4"},
+			},
+		},
+		lines: map[int64][]models.JournalLine{9: {
+			{LineID: 1, JournalID: 9, AccountID: 11010, IsDebit: true, Amount: money.New(500)},
+			{LineID: 2, JournalID: 9, AccountID: 41001, IsDebit: false, Amount: money.New(500)},
+		}},
+	}
+	jr := &fakeIntegrityJournal{
+		entries: map[int64]*models.JournalEntry{
+			9:  {JournalID: 9, SourceType: "shopee_escrow", SourceID: "INV-1"},
+			12: {JournalID: 12, SourceType: "shopee_escrow", SourceID: "'INV-2"},
+		},
+		nextID: 100,
+	}
+	svc := NewLedgerIntegrityService(nil, repo, jr, nil)
+
+	report, err := svc.Check(context.Background(), models.CheckDuplicateSource)
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	if !report.Issues[0].Repairable || report.Issues[1].Repairable {
+		t.Fatalf("only the exact duplicate should be repairable, got %+v", report.Issues)
+	}
+
+	done, failed, err := svc.RepairAll(context.Background(), models.CheckDuplicateSource, "ana")
+	if err != nil {
+		t.Fatalf("repair all: %v", err)
+	}
+	if len(done) != 1 || done[0].Target != "9" || len(failed) != 0 {
+		t.Fatalf("expected only journal 9 reversed, got %+v / %+v", done, failed)
+	}
+	if _, err := svc.RepairIssue(context.Background(), models.CheckDuplicateSource, "12", "ana"); !errors.Is(err, ErrNotRepairable) {
+		t.Errorf("expected ErrNotRepairable for a normalized match, got %v", err)
+	}
+}
@@ -0,0 +1,301 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
+)
+
+// ErrSuggestionDecided is returned when accepting or rejecting a suggestion
+// that was already accepted or rejected.
+var ErrSuggestionDecided = errors.New("match suggestion already decided")
+
+// Weights of the match scores in a suggestion's confidence. The invoice
+// dominates; a manual order with a different invoice needs a matching
+// store, day and amount to reach the default minimum.
+const (
+	invoiceWeight = 0.4
+	amountWeight  = 0.3
+	dateWeight    = 0.2
+	storeWeight   = 0.1
+
+	// matchDateWindow is the largest gap between purchase and order creation
+	// still considered; the date score falls linearly to 0 across it.
+	matchDateWindow = 7 * 24 * time.Hour
+
+	defaultMinConfidence   = 0.6
+	maxSuggestionPurchases = 500
+)
+
+// MatchSuggestionRepo is the storage used by MatchSuggestionService.
+type MatchSuggestionRepo interface {
+	ListUnmatchedPurchases(ctx context.Context, store string, from, to time.Time, limit int) ([]models.MatchPurchase, error)
+	ListUnmatchedOrders(ctx context.Context, from, to time.Time) ([]models.MatchOrder, error)
+	DeletePending(ctx context.Context, store string) error
+	Upsert(ctx context.Context, s *models.MatchSuggestion) (bool, error)
+	List(ctx context.Context, store, status string, limit, offset int) ([]models.MatchSuggestion, int, error)
+	Get(ctx context.Context, id int64) (*models.MatchSuggestion, error)
+	Decide(ctx context.Context, s *models.MatchSuggestion) error
+}
+
+// Matcher posts the reconciliation of an accepted pair. ReconcileService
+// implements it.
+type Matcher interface {
+	MatchAndJournal(ctx context.Context, purchaseID, orderID, shop string) error
+}
+
+// MatchSuggestionService proposes pairings for purchases and settlements the
+// exact invoice join leaves unmatched: typos, apostrophe prefixes and manual
+// orders. Accepted suggestions are reconciled through MatchAndJournal.
+type MatchSuggestionService struct {
+	repo    MatchSuggestionRepo
+	matcher Matcher
+}
+
+// NewMatchSuggestionService constructs a MatchSuggestionService.
+func NewMatchSuggestionService(repo MatchSuggestionRepo, matcher Matcher) *MatchSuggestionService {
+	return &MatchSuggestionService{repo: repo, matcher: matcher}
+}
+
+// SuggestionRequest selects the purchases to find matches for.
+type SuggestionRequest struct {
+	Store         string    `json:"store"`
+	From          time.Time `json:"from"`
+	To            time.Time `json:"to"`
+	MinConfidence float64   `json:"min_confidence"`
+}
+
+// SuggestionResult summarises a GenerateSuggestions run.
+type SuggestionResult struct {
+	Purchases   int `json:"purchases"`
+	Orders      int `json:"orders"`
+	Suggestions int `json:"suggestions"`
+}
+
+// GenerateSuggestions scores unmatched purchases of req.Store created in
+// [From, To) against unmatched settlements around the same dates and stores
+// the best one-to-one pairs reaching MinConfidence. Pending suggestions of
+// the store are replaced; rejected pairs are never proposed again.
+func (s *MatchSuggestionService) GenerateSuggestions(ctx context.Context, req SuggestionRequest) (*SuggestionResult, error) {
+	if req.To.IsZero() {
+		req.To = time.Now()
+	}
+	if req.From.IsZero() {
+		req.From = req.To.AddDate(0, 0, -30)
+	}
+	if req.MinConfidence <= 0 {
+		req.MinConfidence = defaultMinConfidence
+	}
+	purchases, err := s.repo.ListUnmatchedPurchases(ctx, req.Store, req.From, req.To, maxSuggestionPurchases)
+	if err != nil {
+		return nil, fmt.Errorf("list unmatched purchases: %w", err)
+	}
+	orders, err := s.repo.ListUnmatchedOrders(ctx, req.From.Add(-matchDateWindow), req.To.Add(matchDateWindow))
+	if err != nil {
+		return nil, fmt.Errorf("list unmatched orders: %w", err)
+	}
+	if err := s.repo.DeletePending(ctx, req.Store); err != nil {
+		return nil, err
+	}
+
+	res := &SuggestionResult{Purchases: len(purchases), Orders: len(orders)}
+	for _, sg := range suggestMatches(purchases, orders, req.MinConfidence) {
+		stored, err := s.repo.Upsert(ctx, &sg)
+		if err != nil {
+			return nil, err
+		}
+		if stored {
+			res.Suggestions++
+		}
+	}
+	return res, nil
+}
+
+// ListSuggestions lists suggestions by store and status.
+func (s *MatchSuggestionService) ListSuggestions(ctx context.Context, store, status string, limit, offset int) ([]models.MatchSuggestion, int, error) {
+	return s.repo.List(ctx, store, status, limit, offset)
+}
+
+// AcceptSuggestion reconciles the suggested pair with MatchAndJournal and
+// marks the suggestion accepted. A failed match leaves it pending.
+func (s *MatchSuggestionService) AcceptSuggestion(ctx context.Context, id int64) (*models.MatchSuggestion, error) {
+	sg, err := s.pending(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.matcher.MatchAndJournal(ctx, sg.KodeInvoiceChannel, sg.NoPesanan, sg.Store); err != nil {
+		return nil, err
+	}
+	return sg, s.decide(ctx, sg, models.SuggestionAccepted)
+}
+
+// RejectSuggestion marks a suggestion rejected so the pair is not proposed
+// again.
+func (s *MatchSuggestionService) RejectSuggestion(ctx context.Context, id int64) (*models.MatchSuggestion, error) {
+	sg, err := s.pending(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return sg, s.decide(ctx, sg, models.SuggestionRejected)
+}
+
+func (s *MatchSuggestionService) pending(ctx context.Context, id int64) (*models.MatchSuggestion, error) {
+	sg, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sg.Status != models.SuggestionPending {
+		return nil, fmt.Errorf("%w: suggestion %d is %s", ErrSuggestionDecided, id, sg.Status)
+	}
+	return sg, nil
+}
+
+func (s *MatchSuggestionService) decide(ctx context.Context, sg *models.MatchSuggestion, status string) error {
+	now := time.Now()
+	sg.Status = status
+	sg.DecidedAt = &now
+	return s.repo.Decide(ctx, sg)
+}
+
+// suggestMatches scores every purchase against the orders created within
+// matchDateWindow of it and keeps the most confident pairs, each purchase
+// and order used at most once.
+func suggestMatches(purchases []models.MatchPurchase, orders []models.MatchOrder, minConfidence float64) []models.MatchSuggestion {
+	sort.Slice(orders, func(i, j int) bool { return orders[i].WaktuPesananDibuat.Before(orders[j].WaktuPesananDibuat) })
+
+	var candidates []models.MatchSuggestion
+	for _, p := range purchases {
+		lo := p.WaktuPesananTerbuat.Add(-matchDateWindow)
+		start := sort.Search(len(orders), func(i int) bool { return !orders[i].WaktuPesananDibuat.Before(lo) })
+		for _, o := range orders[start:] {
+			if o.WaktuPesananDibuat.Sub(p.WaktuPesananTerbuat) > matchDateWindow {
+				break
+			}
+			sg := scoreMatch(p, o)
+			if sg.Confidence >= minConfidence {
+				candidates = append(candidates, sg)
+			}
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Confidence != candidates[j].Confidence {
+			return candidates[i].Confidence > candidates[j].Confidence
+		}
+		return candidates[i].KodePesanan < candidates[j].KodePesanan
+	})
+	usedPurchase := map[string]bool{}
+	usedOrder := map[string]bool{}
+	var out []models.MatchSuggestion
+	for _, c := range candidates {
+		if usedPurchase[c.KodePesanan] || usedOrder[c.NoPesanan] {
+			continue
+		}
+		usedPurchase[c.KodePesanan] = true
+		usedOrder[c.NoPesanan] = true
+		out = append(out, c)
+	}
+	return out
+}
+
+// scoreMatch scores how likely order o is the marketplace side of p.
+func scoreMatch(p models.MatchPurchase, o models.MatchOrder) models.MatchSuggestion {
+	sg := models.MatchSuggestion{
+		Store:              p.NamaToko,
+		KodePesanan:        p.KodePesanan,
+		KodeInvoiceChannel: p.KodeInvoiceChannel,
+		NoPesanan:          o.NoPesanan,
+		InvoiceScore:       invoiceSimilarity(p.KodeInvoiceChannel, o.NoPesanan),
+		DateScore:          dateProximity(p.WaktuPesananTerbuat, o.WaktuPesananDibuat),
+		AmountScore:        amountSimilarity(p.ChannelTotal, o.ProductTotal),
+		Status:             models.SuggestionPending,
+	}
+	if strings.EqualFold(strings.TrimSpace(p.NamaToko), strings.TrimSpace(o.NamaToko)) {
+		sg.StoreScore = 1
+	}
+	sg.Confidence = round4(invoiceWeight*sg.InvoiceScore + amountWeight*sg.AmountScore +
+		dateWeight*sg.DateScore + storeWeight*sg.StoreScore)
+	return sg
+}
+
+// normalizeInvoice upper-cases an invoice number and drops everything but
+// letters and digits, so "'240101ABC", "240101abc " and "240101-ABC" agree.
+func normalizeInvoice(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(s) {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// invoiceSimilarity is 1 for invoices equal after normalizing and otherwise
+// one minus their edit distance relative to the longer one.
+func invoiceSimilarity(a, b string) float64 {
+	a, b = normalizeInvoice(a), normalizeInvoice(b)
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+	longest := len(a)
+	if len(b) > longest {
+		longest = len(b)
+	}
+	return round4(1 - float64(levenshtein(a, b))/float64(longest))
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// dateProximity falls linearly from 1 for the same moment to 0 at
+// matchDateWindow apart.
+func dateProximity(a, b time.Time) float64 {
+	gap := a.Sub(b)
+	if gap < 0 {
+		gap = -gap
+	}
+	if gap >= matchDateWindow {
+		return 0
+	}
+	return round4(1 - float64(gap)/float64(matchDateWindow))
+}
+
+// amountSimilarity is the ratio of the smaller to the larger amount.
+func amountSimilarity(a, b money.Amount) float64 {
+	if a <= 0 || b <= 0 {
+		return 0
+	}
+	if a > b {
+		a, b = b, a
+	}
+	return round4(float64(a.Sen()) / float64(b.Sen()))
+}
+
+func round4(f float64) float64 { return math.Round(f*10000) / 10000 }
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
+)
+
+type fakeSuggestionRepo struct {
+	purchases []models.MatchPurchase
+	orders    []models.MatchOrder
+	saved     []models.MatchSuggestion
+	decided   []models.MatchSuggestion
+}
+
+func (f *fakeSuggestionRepo) ListUnmatchedPurchases(ctx context.Context, store string, from, to time.Time, limit int) ([]models.MatchPurchase, error) {
+	return f.purchases, nil
+}
+
+func (f *fakeSuggestionRepo) ListUnmatchedOrders(ctx context.Context, from, to time.Time) ([]models.MatchOrder, error) {
+	return f.orders, nil
+}
+
+func (f *fakeSuggestionRepo) DeletePending(ctx context.Context, store string) error { return nil }
+
+func (f *fakeSuggestionRepo) Upsert(ctx context.Context, s *models.MatchSuggestion) (bool, error) {
+	s.ID = int64(len(f.saved) + 1)
+	f.saved = append(f.saved, *s)
+	return true, nil
+}
+
+func (f *fakeSuggestionRepo) List(ctx context.Context, store, status string, limit, offset int) ([]models.MatchSuggestion, int, error) {
+	return f.saved, len(f.saved), nil
+}
+
+func (f *fakeSuggestionRepo) Get(ctx context.Context, id int64) (*models.MatchSuggestion, error) {
+	cp := f.saved[id-1]
+	return &cp, nil
+}
+
+func (f *fakeSuggestionRepo) Decide(ctx context.Context, s *models.MatchSuggestion) error {
+	f.saved[s.ID-1] = *s
+	f.decided = append(f.decided, *s)
+	return nil
+}
+
+type fakeMatcher struct {
+	pairs [][3]string
+	err   error
+}
+
+func (m *fakeMatcher) MatchAndJournal(ctx context.Context, purchaseID, orderID, shop string) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.pairs = append(m.pairs, [3]string{purchaseID, orderID, shop})
+	return nil
+}
+
+func TestInvoiceSimilarity(t *testing.T) {
+	if got := invoiceSimilarity("'240101ABCDEF", "240101abcdef"); got != 1 {
+		t.Errorf("apostrophe and case should normalize away, got %v", got)
+	}
+	if got := invoiceSimilarity("240101ABCDEF", "240101ABCDEE"); got < 0.9 || got >= 1 {
+		t.Errorf("one typo should score high, got %v", got)
+	}
+	if got := invoiceSimilarity("", "240101ABCDEF"); got != 0 {
+		t.Errorf("empty invoice should score 0, got %v", got)
+	}
+}
+
+func TestSuggestMatchesPairsOneToOne(t *testing.T) {
+	day := time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)
+	purchases := []models.MatchPurchase{
+		{KodePesanan: "DP-1", KodeInvoiceChannel: "'250310AAA111", NamaToko: "ShopA", WaktuPesananTerbuat: day, ChannelTotal: money.New(100000)},
+		{KodePesanan: "DP-2", KodeInvoiceChannel: "MANUAL-7", NamaToko: "ShopA", WaktuPesananTerbuat: day, ChannelTotal: money.New(55000)},
+		{KodePesanan: "DP-3", KodeInvoiceChannel: "250301ZZZ999", NamaToko: "ShopB", WaktuPesananTerbuat: day.AddDate(0, 0, -9), ChannelTotal: money.New(70000)},
+	}
+	orders := []models.MatchOrder{
+		{NoPesanan: "250310AAA111", NamaToko: "ShopA", WaktuPesananDibuat: day.Add(time.Hour), ProductTotal: money.New(98000)},
+		{NoPesanan: "250310QRS222", NamaToko: "ShopA", WaktuPesananDibuat: day, ProductTotal: money.New(55000)},
+		{NoPesanan: "250320ZZZ999", NamaToko: "ShopB", WaktuPesananDibuat: day.AddDate(0, 0, 10), ProductTotal: money.New(70000)},
+	}
+
+	got := suggestMatches(purchases, orders, defaultMinConfidence)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 suggestions, got %+v", got)
+	}
+	if got[0].KodePesanan != "DP-1" || got[0].NoPesanan != "250310AAA111" || got[0].InvoiceScore != 1 {
+		t.Errorf("unexpected best suggestion %+v", got[0])
+	}
+	if got[1].KodePesanan != "DP-2" || got[1].NoPesanan != "250310QRS222" {
+		t.Errorf("manual order should match by store, day and amount, got %+v", got[1])
+	}
+}
+
+func TestAcceptSuggestionMatchesAndJournals(t *testing.T) {
+	ctx := context.Background()
+	day := time.Now().Add(-24 * time.Hour)
+	repo := &fakeSuggestionRepo{
+		purchases: []models.MatchPurchase{{KodePesanan: "DP-1", KodeInvoiceChannel: "'250310AAA111", NamaToko: "ShopA", WaktuPesananTerbuat: day, ChannelTotal: money.New(100)}},
+		orders:    []models.MatchOrder{{NoPesanan: "250310AAA111", NamaToko: "ShopA", WaktuPesananDibuat: day, ProductTotal: money.New(100)}},
+	}
+	m := &fakeMatcher{}
+	svc := NewMatchSuggestionService(repo, m)
+
+	res, err := svc.GenerateSuggestions(ctx, SuggestionRequest{Store: "ShopA"})
+	if err != nil || res.Suggestions != 1 {
+		t.Fatalf("generate: %+v %v", res, err)
+	}
+
+	sg, err := svc.AcceptSuggestion(ctx, 1)
+	if err != nil {
+		t.Fatalf("accept: %v", err)
+	}
+	if sg.Status != models.SuggestionAccepted || sg.DecidedAt == nil {
+		t.Errorf("unexpected suggestion %+v", sg)
+	}
+	if len(m.pairs) != 1 || m.pairs[0] != [3]string{"'250310AAA111", "250310AAA111", "ShopA"} {
+		t.Errorf("unexpected MatchAndJournal calls %+v", m.pairs)
+	}
+	if _, err := svc.RejectSuggestion(ctx, 1); !errors.Is(err, ErrSuggestionDecided) {
+		t.Errorf("expected ErrSuggestionDecided, got %v", err)
+	}
+}
+
+func TestAcceptSuggestionLeavesPendingWhenMatchFails(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeSuggestionRepo{saved: []models.MatchSuggestion{{ID: 1, KodePesanan: "DP-1", NoPesanan: "SO-1", Status: models.SuggestionPending}}}
+	svc := NewMatchSuggestionService(repo, &fakeMatcher{err: errors.New("boom")})
+
+	if _, err := svc.AcceptSuggestion(ctx, 1); err == nil {
+		t.Fatal("expected error")
+	}
+	if repo.saved[0].Status != models.SuggestionPending || len(repo.decided) != 0 {
+		t.Errorf("suggestion should stay pending, got %+v", repo.saved[0])
+	}
+}
+
+func TestAcceptSuggestionReconcilesThroughMatchAndJournal(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeSuggestionRepo{saved: []models.MatchSuggestion{{
+		ID: 1, KodePesanan: "DP-1", KodeInvoiceChannel: "'250310AAA111", NoPesanan: "250310AAA111",
+		Store: "ShopA", Status: models.SuggestionPending,
+	}}}
+	fDrop := &fakeDropRepoRec{data: map[string]*models.DropshipPurchase{
+		"'250310AAA111": {KodePesanan: "DP-1", KodeInvoiceChannel: "'250310AAA111", TotalTransaksi: money.New(90)},
+	}}
+	// The order is keyed by the shopee_settled no_pesanan the suggestion
+	// was generated from.
+	fShopee := &fakeShopeeRepoRec{data: map[string]*models.ShopeeSettledOrder{
+		"250310AAA111": {OrderID: "250310AAA111", NetIncome: money.New(100), SettledDate: time.Now()},
+	}}
+	jr := &fakeJournalRepoRec{}
+	rec := &fakeRecRepoRec{}
+	recon := NewReconcileService(nil, fDrop, fShopee, jr, rec, nil, &fakeDetailRepo{}, nil, nil, nil, &fakeFailedRecRepo{}, nil, 5, nil)
+	svc := NewMatchSuggestionService(repo, recon)
+
+	sg, err := svc.AcceptSuggestion(ctx, 1)
+	if err != nil {
+		t.Fatalf("accept: %v", err)
+	}
+	if sg.Status != models.SuggestionAccepted {
+		t.Errorf("unexpected suggestion %+v", sg)
+	}
+	if len(jr.entries) != 1 || jr.entries[0].SourceID != "250310AAA111" {
+		t.Fatalf("expected a reconcile journal for the settled order, got %+v", jr.entries)
+	}
+}
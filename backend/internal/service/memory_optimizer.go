@@ -6,8 +6,12 @@ import (
 	"runtime"
 	"sync"
 	"time"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/logutil"
 )
 
+var memoryLog = logutil.NewLogger("memory-optimizer", logutil.INFO)
+
 // MemoryOptimizer manages memory usage during large import operations
 type MemoryOptimizer struct {
 	maxMemoryUsage   int64         // Maximum memory usage in bytes
@@ -141,8 +145,11 @@ func (m *MemoryOptimizer) forceGarbageCollection() {
 	m.memoryStats.LastGCTime = m.lastGCTime
 	m.mu.Unlock()
 
-	fmt.Printf("Forced GC: memory reduced from %d MB to %d MB (saved %d MB)\n",
-		beforeGC/1024/1024, afterGC/1024/1024, (beforeGC-afterGC)/1024/1024)
+	memoryLog.Info(context.Background(), "ForceGC", "Forced garbage collection", map[string]interface{}{
+		"before_mb": beforeGC / 1024 / 1024,
+		"after_mb":  afterGC / 1024 / 1024,
+		"saved_mb":  (beforeGC - afterGC) / 1024 / 1024,
+	})
 }
 
 // getCurrentMemoryUsage returns current memory usage
@@ -218,7 +225,7 @@ func (m *MemoryOptimizer) WaitForMemoryAvailable(ctx context.Context, maxWait ti
 		return nil
 	}
 
-	fmt.Println("Memory pressure high, waiting for memory to become available...")
+	memoryLog.Warn(ctx, "WaitForMemoryAvailable", "Memory pressure high, waiting for memory to become available")
 
 	timeout := time.After(maxWait)
 	ticker := time.NewTicker(5 * time.Second)
@@ -232,7 +239,7 @@ func (m *MemoryOptimizer) WaitForMemoryAvailable(ctx context.Context, maxWait ti
 			return fmt.Errorf("timeout waiting for memory to become available")
 		case <-ticker.C:
 			if !m.ShouldPauseProcessing() {
-				fmt.Println("Memory pressure reduced, resuming processing...")
+				memoryLog.Info(ctx, "WaitForMemoryAvailable", "Memory pressure reduced, resuming processing")
 				return nil
 			}
 			// Force GC to help free memory
@@ -272,9 +279,10 @@ func (m *MemoryOptimizer) GetMemoryRecommendations() []string {
 // LogMemoryStats logs current memory statistics
 func (m *MemoryOptimizer) LogMemoryStats() {
 	stats := m.GetMemoryStats()
-	fmt.Printf("Memory Stats: Allocated=%dMB, System=%dMB, Pressure=%.2f%%, GC Count=%d\n",
-		stats.AllocatedMemory/1024/1024,
-		stats.SystemMemory/1024/1024,
-		stats.MemoryPressure*100,
-		stats.GCCount)
+	memoryLog.Info(context.Background(), "LogMemoryStats", "Memory stats", map[string]interface{}{
+		"allocated_mb": stats.AllocatedMemory / 1024 / 1024,
+		"system_mb":    stats.SystemMemory / 1024 / 1024,
+		"pressure_pct": stats.MemoryPressure * 100,
+		"gc_count":     stats.GCCount,
+	})
 }
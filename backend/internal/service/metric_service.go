@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
 )
 
@@ -76,7 +77,7 @@ func (s *MetricService) CalculateAndCacheMetrics(
 	if err != nil {
 		return fmt.Errorf("list shopee orders: %w", err)
 	}
-	var sumRevenue, sumFees float64
+	var sumRevenue, sumFees money.Amount
 	for _, o := range orders {
 		sumRevenue += o.NetIncome
 		sumFees += o.ServiceFee + o.CampaignFee + o.CreditCardFee + o.ShippingSubsidy + o.TaxImportFee
@@ -87,7 +88,7 @@ func (s *MetricService) CalculateAndCacheMetrics(
 	if err != nil {
 		return fmt.Errorf("list dropship purchases: %w", err)
 	}
-	var sumCOGS float64
+	var sumCOGS money.Amount
 	for _, dp := range purchases {
 		sumCOGS += dp.TotalTransaksi
 	}
@@ -100,7 +101,7 @@ func (s *MetricService) CalculateAndCacheMetrics(
 	if err != nil {
 		return fmt.Errorf("get account balances: %w", err)
 	}
-	var endingCash float64
+	var endingCash money.Amount
 	for _, ab := range balances {
 		if ab.AccountID == 1001 {
 			endingCash = ab.Balance
@@ -132,7 +133,7 @@ func (s *MetricService) MetricRepo() MetricRepoInterface {
 
 // GetRevenue sums net income for a store over the given period.
 // periodType is "monthly" (YYYY-MM) or "yearly" (YYYY).
-func (s *MetricService) GetRevenue(ctx context.Context, store, periodType, periodValue string) (float64, error) {
+func (s *MetricService) GetRevenue(ctx context.Context, store, periodType, periodValue string) (money.Amount, error) {
 	var start time.Time
 	var err error
 	switch periodType {
@@ -147,7 +148,7 @@ func (s *MetricService) GetRevenue(ctx context.Context, store, periodType, perio
 		if err != nil {
 			return 0, err
 		}
-		var sum float64
+		var sum money.Amount
 		for _, o := range orders {
 			sum += o.NetIncome
 		}
@@ -163,7 +164,7 @@ func (s *MetricService) GetRevenue(ctx context.Context, store, periodType, perio
 		if err != nil {
 			return 0, err
 		}
-		var sum float64
+		var sum money.Amount
 		for _, o := range orders {
 			sum += o.NetIncome
 		}
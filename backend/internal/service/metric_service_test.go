@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
 )
 
@@ -83,7 +84,7 @@ func TestCalculateAndCacheMetrics(t *testing.T) {
 				{
 					KodePesanan:         "DP-1",
 					NamaToko:            shop,
-					TotalTransaksi:      52.00,
+					TotalTransaksi:      money.New(52),
 					WaktuPesananTerbuat: start.AddDate(0, 0, 5),
 				},
 			},
@@ -94,12 +95,12 @@ func TestCalculateAndCacheMetrics(t *testing.T) {
 			shop: {
 				{
 					OrderID:         "SO-1",
-					NetIncome:       100.00,
-					ServiceFee:      3.00,
-					CampaignFee:     0.00,
-					CreditCardFee:   1.50,
-					ShippingSubsidy: 0.00,
-					TaxImportFee:    0.00,
+					NetIncome:       money.New(100),
+					ServiceFee:      money.New(3),
+					CampaignFee:     money.Zero,
+					CreditCardFee:   money.MustParse("1.50"),
+					ShippingSubsidy: money.Zero,
+					TaxImportFee:    money.Zero,
 					SettledDate:     start.AddDate(0, 0, 10),
 				},
 			},
@@ -113,7 +114,7 @@ func TestCalculateAndCacheMetrics(t *testing.T) {
 					AccountCode: "1001",
 					AccountName: "Cash",
 					AccountType: "Asset",
-					Balance:     200.00,
+					Balance:     money.New(200),
 				},
 			},
 		},
@@ -131,10 +132,10 @@ func TestCalculateAndCacheMetrics(t *testing.T) {
 	}
 	cm := fMetric.saved[0]
 	// sumRevenue=100.00, sumFees=3+1.5=4.5, sumCOGS=52, netProfit=100-4.5-52=43.5
-	if cm.NetProfit != 43.5 {
-		t.Errorf("unexpected NetProfit: %f", cm.NetProfit)
+	if cm.NetProfit != money.MustParse("43.5") {
+		t.Errorf("unexpected NetProfit: %s", cm.NetProfit)
 	}
-	if cm.EndingCashBalance != 200.00 {
-		t.Errorf("unexpected EndingCashBalance: %f", cm.EndingCashBalance)
+	if cm.EndingCashBalance != money.New(200) {
+		t.Errorf("unexpected EndingCashBalance: %s", cm.EndingCashBalance)
 	}
 }
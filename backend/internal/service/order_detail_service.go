@@ -9,6 +9,7 @@ import (
 // OrderDetailRepo defines the subset of repository methods used by the service.
 type OrderDetailRepo interface {
 	ListOrderDetails(ctx context.Context, store, order string, limit, offset int) ([]models.ShopeeOrderDetailRow, int, error)
+	ListOrderDetailsFiltered(ctx context.Context, params *models.FilterParams) (*models.QueryResult, error)
 	GetOrderDetail(ctx context.Context, sn string) (*models.ShopeeOrderDetailRow, []models.ShopeeOrderItemRow, []models.ShopeeOrderPackageRow, error)
 }
 
@@ -25,6 +26,11 @@ func (s *OrderDetailService) ListOrderDetails(ctx context.Context, store, order
 	return s.repo.ListOrderDetails(ctx, store, order, limit, offset)
 }
 
+// ListOrderDetailsFiltered uses the filtering framework to fetch detail rows.
+func (s *OrderDetailService) ListOrderDetailsFiltered(ctx context.Context, params *models.FilterParams) (*models.QueryResult, error) {
+	return s.repo.ListOrderDetailsFiltered(ctx, params)
+}
+
 func (s *OrderDetailService) GetOrderDetail(ctx context.Context, sn string) (*models.ShopeeOrderDetailRow, []models.ShopeeOrderItemRow, []models.ShopeeOrderPackageRow, error) {
 	return s.repo.GetOrderDetail(ctx, sn)
 }
@@ -2,10 +2,11 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
-	"strings"
 
+	"github.com/ramadhan22/dropship-erp/backend/internal/apperr"
+	"github.com/ramadhan22/dropship-erp/backend/internal/logutil"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
 	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
 )
@@ -34,7 +35,10 @@ func (s *PendingBalanceService) GetPendingBalance(ctx context.Context, store str
 	if s.storeRepo != nil {
 		st, err := s.storeRepo.GetStoreByName(ctx, store)
 		if err != nil || st == nil {
-			log.Printf("Warning: could not fetch store %s for token validation: %v", store, err)
+			logutil.Warn(ctx, "GetPendingBalance", "Could not fetch store for token validation", map[string]interface{}{
+				"store": store,
+				"error": err,
+			})
 			// Continue without validation as fallback
 		} else {
 			// Ensure token is valid before making API call
@@ -54,7 +58,7 @@ func (s *PendingBalanceService) GetPendingBalance(ctx context.Context, store str
 
 	// Call the API with potentially refreshed token
 	balance, err := s.client.GetPendingBalance(ctx, store)
-	if err != nil && strings.Contains(err.Error(), "invalid_access_token") && s.storeRepo != nil {
+	if errors.Is(err, apperr.ErrTokenExpired) && s.storeRepo != nil {
 		// Fallback: try to refresh token and retry if we get token error
 		if st, stErr := s.storeRepo.GetStoreByName(ctx, store); stErr == nil && st != nil {
 			if refreshErr := s.ensureTokenValid(ctx, st); refreshErr == nil {
@@ -6,46 +6,48 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ramadhan22/dropship-erp/backend/internal/cache"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
 )
 
 // ProfitLossRow represents a line in the profit loss statement.
 type ProfitLossRow struct {
-	Label          string  `json:"label"`
-	Amount         float64 `json:"amount"`
-	Percent        float64 `json:"percent,omitempty"`
-	PreviousAmount float64 `json:"previousAmount,omitempty"`
-	Change         float64 `json:"change,omitempty"`
-	ChangePercent  float64 `json:"changePercent,omitempty"`
-	Manual         bool    `json:"manual,omitempty"`
-	Indent         int     `json:"indent,omitempty"`
-	Group          bool    `json:"group,omitempty"`
+	Label          string       `json:"label"`
+	Amount         money.Amount `json:"amount"`
+	Percent        float64      `json:"percent,omitempty"`
+	PreviousAmount money.Amount `json:"previousAmount,omitempty"`
+	Change         money.Amount `json:"change,omitempty"`
+	ChangePercent  float64      `json:"changePercent,omitempty"`
+	Manual         bool         `json:"manual,omitempty"`
+	Indent         int          `json:"indent,omitempty"`
+	Group          bool         `json:"group,omitempty"`
 }
 
 // ProfitLoss aggregates profit and loss data for a period.
 type ProfitLoss struct {
 	PendapatanUsaha              []ProfitLossRow `json:"pendapatanUsaha"`
-	TotalPendapatanUsaha         float64         `json:"totalPendapatanUsaha"`
-	PrevTotalPendapatanUsaha     float64         `json:"prevTotalPendapatanUsaha,omitempty"`
+	TotalPendapatanUsaha         money.Amount    `json:"totalPendapatanUsaha"`
+	PrevTotalPendapatanUsaha     money.Amount    `json:"prevTotalPendapatanUsaha,omitempty"`
 	HargaPokokPenjualan          []ProfitLossRow `json:"hargaPokokPenjualan"`
-	TotalHargaPokokPenjualan     float64         `json:"totalHargaPokokPenjualan"`
-	PrevTotalHargaPokokPenjualan float64         `json:"prevTotalHargaPokokPenjualan,omitempty"`
+	TotalHargaPokokPenjualan     money.Amount    `json:"totalHargaPokokPenjualan"`
+	PrevTotalHargaPokokPenjualan money.Amount    `json:"prevTotalHargaPokokPenjualan,omitempty"`
 	LabaKotor                    ProfitLossRow   `json:"labaKotor"`
 	BebanOperasional             []ProfitLossRow `json:"bebanOperasional"`
-	TotalBebanOperasional        float64         `json:"totalBebanOperasional"`
-	PrevTotalBebanOperasional    float64         `json:"prevTotalBebanOperasional,omitempty"`
+	TotalBebanOperasional        money.Amount    `json:"totalBebanOperasional"`
+	PrevTotalBebanOperasional    money.Amount    `json:"prevTotalBebanOperasional,omitempty"`
 	BebanPemasaran               []ProfitLossRow `json:"bebanPemasaran"`
-	TotalBebanPemasaran          float64         `json:"totalBebanPemasaran"`
-	PrevTotalBebanPemasaran      float64         `json:"prevTotalBebanPemasaran,omitempty"`
+	TotalBebanPemasaran          money.Amount    `json:"totalBebanPemasaran"`
+	PrevTotalBebanPemasaran      money.Amount    `json:"prevTotalBebanPemasaran,omitempty"`
 	BebanAdministrasi            []ProfitLossRow `json:"bebanAdministrasi"`
-	TotalBebanAdministrasi       float64         `json:"totalBebanAdministrasi"`
-	PrevTotalBebanAdministrasi   float64         `json:"prevTotalBebanAdministrasi,omitempty"`
+	TotalBebanAdministrasi       money.Amount    `json:"totalBebanAdministrasi"`
+	PrevTotalBebanAdministrasi   money.Amount    `json:"prevTotalBebanAdministrasi,omitempty"`
 	TotalBebanUsaha              ProfitLossRow   `json:"totalBebanUsaha"`
-	LabaSebelumPajak             float64         `json:"labaSebelumPajak"`
-	PrevLabaSebelumPajak         float64         `json:"prevLabaSebelumPajak,omitempty"`
+	LabaSebelumPajak             money.Amount    `json:"labaSebelumPajak"`
+	PrevLabaSebelumPajak         money.Amount    `json:"prevLabaSebelumPajak,omitempty"`
 	PajakPenghasilan             []ProfitLossRow `json:"pajakPenghasilan"`
-	TotalPajakPenghasilan        float64         `json:"totalPajakPenghasilan"`
-	PrevTotalPajakPenghasilan    float64         `json:"prevTotalPajakPenghasilan,omitempty"`
+	TotalPajakPenghasilan        money.Amount    `json:"totalPajakPenghasilan"`
+	PrevTotalPajakPenghasilan    money.Amount    `json:"prevTotalPajakPenghasilan,omitempty"`
 	LabaRugiBersih               ProfitLossRow   `json:"labaRugiBersih"`
 }
 
@@ -56,7 +58,8 @@ type ProfitLossJournalRepo interface {
 
 // ProfitLossReportService computes profit and loss data using journal entries.
 type ProfitLossReportService struct {
-	jr ProfitLossJournalRepo
+	jr    ProfitLossJournalRepo
+	cache Cache
 }
 
 // NewProfitLossReportService constructs a ProfitLossReportService.
@@ -64,11 +67,25 @@ func NewProfitLossReportService(jr ProfitLossJournalRepo) *ProfitLossReportServi
 	return &ProfitLossReportService{jr: jr}
 }
 
+// SetCache enables caching of computed profit and loss statements. Entries
+// are tagged with cache.TagJournals so any journal write invalidates them.
+func (s *ProfitLossReportService) SetCache(c Cache) {
+	s.cache = c
+}
+
 // GetProfitLoss returns profit and loss information for the given period.
 // typ should be "Monthly" or "Yearly". The returned range always spans the
 // entire calendar month or year specified by the arguments.
 // If comparison is true, it also fetches previous period data for comparison.
 func (s *ProfitLossReportService) GetProfitLoss(ctx context.Context, typ string, month, year int, store string, comparison bool) (*ProfitLoss, error) {
+	key := fmt.Sprintf("report:profitloss:%s:%d:%d:%s:%t", typ, month, year, store, comparison)
+	tags := []string{cache.TagJournals, cache.TagReports}
+	return cachedJSON(ctx, s.cache, key, reportCacheTTL, tags, func() (*ProfitLoss, error) {
+		return s.computeProfitLoss(ctx, typ, month, year, store, comparison)
+	})
+}
+
+func (s *ProfitLossReportService) computeProfitLoss(ctx context.Context, typ string, month, year int, store string, comparison bool) (*ProfitLoss, error) {
 	var start, end time.Time
 	var prevStart, prevEnd time.Time
 
@@ -111,11 +128,8 @@ func (s *ProfitLossReportService) GetProfitLoss(ctx context.Context, typ string,
 	return s.buildProfitLoss(balances, prevBalances, comparison)
 }
 
-func pct(v, base float64) float64 {
-	if base == 0 {
-		return 0
-	}
-	return v / base * 100
+func pct(v, base money.Amount) float64 {
+	return v.Ratio(base) * 100
 }
 
 // buildProfitLoss constructs a ProfitLoss struct from current and previous period balances.
@@ -123,17 +137,17 @@ func (s *ProfitLossReportService) buildProfitLoss(balances, prevBalances []repos
 	var revRows, hppRows, opRows []ProfitLossRow
 	var adminRows, taxRows []ProfitLossRow
 	var marketingRows []ProfitLossRow
-	var totalRev, totalHPP, totalOp, totalMarketing, totalAdmin, totalTax float64
+	var totalRev, totalHPP, totalOp, totalMarketing, totalAdmin, totalTax money.Amount
 
 	// Build maps for previous period data
-	prevRevMap := make(map[string]float64)
-	prevHppMap := make(map[string]float64)
-	prevOpMap := make(map[string]float64)
-	prevMarketingMap := make(map[string]float64)
-	prevAdminMap := make(map[string]float64)
-	prevTaxMap := make(map[string]float64)
+	prevRevMap := make(map[string]money.Amount)
+	prevHppMap := make(map[string]money.Amount)
+	prevOpMap := make(map[string]money.Amount)
+	prevMarketingMap := make(map[string]money.Amount)
+	prevAdminMap := make(map[string]money.Amount)
+	prevTaxMap := make(map[string]money.Amount)
 
-	var prevTotalRev, prevTotalHPP, prevTotalOp, prevTotalMarketing, prevTotalAdmin, prevTotalTax float64
+	var prevTotalRev, prevTotalHPP, prevTotalOp, prevTotalMarketing, prevTotalAdmin, prevTotalTax money.Amount
 
 	if comparison {
 		for _, ab := range prevBalances {
@@ -295,12 +309,12 @@ func (s *ProfitLossReportService) buildProfitLoss(balances, prevBalances []repos
 }
 
 // changePct calculates percentage change between current and previous values
-func changePct(current, previous float64) float64 {
+func changePct(current, previous money.Amount) float64 {
 	if previous == 0 {
 		if current == 0 {
 			return 0
 		}
 		return 100 // or could return a special value to indicate new item
 	}
-	return ((current - previous) / previous) * 100
+	return (current - previous).Ratio(previous) * 100
 }
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
 )
 
@@ -22,9 +23,9 @@ func (f *fakeJournalRepoPL) GetAccountBalancesBetween(ctx context.Context, shop
 
 func TestProfitLossReportService_GetProfitLoss(t *testing.T) {
 	repo := &fakeJournalRepoPL{balances: []repository.AccountBalance{
-		{AccountCode: "4.1", AccountName: "Penjualan", Balance: -200},
-		{AccountCode: "5.1", AccountName: "HPP", Balance: 100},
-		{AccountCode: "5.5.1", AccountName: "Voucher", Balance: 5},
+		{AccountCode: "4.1", AccountName: "Penjualan", Balance: money.New(-200)},
+		{AccountCode: "5.1", AccountName: "HPP", Balance: money.New(100)},
+		{AccountCode: "5.5.1", AccountName: "Voucher", Balance: money.New(5)},
 	}}
 	svc := NewProfitLossReportService(repo)
 
@@ -32,11 +33,11 @@ func TestProfitLossReportService_GetProfitLoss(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if pl.TotalPendapatanUsaha != 200 {
-		t.Errorf("got %f want 200", pl.TotalPendapatanUsaha)
+	if pl.TotalPendapatanUsaha != money.New(200) {
+		t.Errorf("got %s want 200", pl.TotalPendapatanUsaha)
 	}
-	if pl.LabaRugiBersih.Amount != 95 {
-		t.Errorf("got %f want 95", pl.LabaRugiBersih.Amount)
+	if pl.LabaRugiBersih.Amount != money.New(95) {
+		t.Errorf("got %s want 95", pl.LabaRugiBersih.Amount)
 	}
 	if len(pl.BebanPemasaran) != 1 {
 		t.Errorf("expected 1 pemasaran row, got %d", len(pl.BebanPemasaran))
@@ -51,10 +52,10 @@ func TestProfitLossReportService_GetProfitLoss(t *testing.T) {
 
 func TestProfitLossReportService_SkipMarketingParentAccount(t *testing.T) {
 	repo := &fakeJournalRepoPL{balances: []repository.AccountBalance{
-		{AccountCode: "4.1", AccountName: "Penjualan", Balance: -100},
-		{AccountCode: "5.1", AccountName: "HPP", Balance: 50},
-		{AccountCode: "5.5", AccountName: "Beban Pemasaran", Balance: 10},
-		{AccountCode: "5.5.1", AccountName: "Voucher", Balance: 5},
+		{AccountCode: "4.1", AccountName: "Penjualan", Balance: money.New(-100)},
+		{AccountCode: "5.1", AccountName: "HPP", Balance: money.New(50)},
+		{AccountCode: "5.5", AccountName: "Beban Pemasaran", Balance: money.New(10)},
+		{AccountCode: "5.5.1", AccountName: "Voucher", Balance: money.New(5)},
 	}}
 	svc := NewProfitLossReportService(repo)
 
@@ -128,12 +129,12 @@ func TestProfitLossReportService_DifferentPeriods(t *testing.T) {
 
 	repo := &fakeJournalRepoPLMap{data: map[string][]repository.AccountBalance{
 		startMay.Format("2006-01-02") + "_" + endMay.Format("2006-01-02"): {
-			{AccountCode: "4.1", AccountName: "Penjualan", Balance: -200},
-			{AccountCode: "5.1", AccountName: "HPP", Balance: 100},
+			{AccountCode: "4.1", AccountName: "Penjualan", Balance: money.New(-200)},
+			{AccountCode: "5.1", AccountName: "HPP", Balance: money.New(100)},
 		},
 		startJun.Format("2006-01-02") + "_" + endJun.Format("2006-01-02"): {
-			{AccountCode: "4.1", AccountName: "Penjualan", Balance: -100},
-			{AccountCode: "5.1", AccountName: "HPP", Balance: 50},
+			{AccountCode: "4.1", AccountName: "Penjualan", Balance: money.New(-100)},
+			{AccountCode: "5.1", AccountName: "HPP", Balance: money.New(50)},
 		},
 	}}
 
@@ -162,12 +163,12 @@ func TestProfitLossReportService_WithComparison(t *testing.T) {
 
 	repo := &fakeJournalRepoPLMap{data: map[string][]repository.AccountBalance{
 		startMay.Format("2006-01-02") + "_" + endMay.Format("2006-01-02"): {
-			{AccountCode: "4.1", AccountName: "Penjualan", Balance: -200},
-			{AccountCode: "5.1", AccountName: "HPP", Balance: 100},
+			{AccountCode: "4.1", AccountName: "Penjualan", Balance: money.New(-200)},
+			{AccountCode: "5.1", AccountName: "HPP", Balance: money.New(100)},
 		},
 		startApr.Format("2006-01-02") + "_" + endApr.Format("2006-01-02"): {
-			{AccountCode: "4.1", AccountName: "Penjualan", Balance: -100},
-			{AccountCode: "5.1", AccountName: "HPP", Balance: 50},
+			{AccountCode: "4.1", AccountName: "Penjualan", Balance: money.New(-100)},
+			{AccountCode: "5.1", AccountName: "HPP", Balance: money.New(50)},
 		},
 	}}
 
@@ -178,10 +179,10 @@ func TestProfitLossReportService_WithComparison(t *testing.T) {
 	}
 
 	// Check current period data
-	if may.TotalPendapatanUsaha != 200 {
+	if may.TotalPendapatanUsaha != money.New(200) {
 		t.Errorf("expected current revenue 200, got %v", may.TotalPendapatanUsaha)
 	}
-	if may.PrevTotalPendapatanUsaha != 100 {
+	if may.PrevTotalPendapatanUsaha != money.New(100) {
 		t.Errorf("expected previous revenue 100, got %v", may.PrevTotalPendapatanUsaha)
 	}
 
@@ -191,13 +192,13 @@ func TestProfitLossReportService_WithComparison(t *testing.T) {
 	}
 
 	revRow := may.PendapatanUsaha[0]
-	if revRow.Amount != 200 {
+	if revRow.Amount != money.New(200) {
 		t.Errorf("expected current amount 200, got %v", revRow.Amount)
 	}
-	if revRow.PreviousAmount != 100 {
+	if revRow.PreviousAmount != money.New(100) {
 		t.Errorf("expected previous amount 100, got %v", revRow.PreviousAmount)
 	}
-	if revRow.Change != 100 {
+	if revRow.Change != money.New(100) {
 		t.Errorf("expected change 100, got %v", revRow.Change)
 	}
 	if revRow.ChangePercent != 100 {
@@ -6,6 +6,9 @@ import (
 	"context"
 	"sync"
 	"time"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/metrics"
+	"github.com/ramadhan22/dropship-erp/backend/internal/tracing"
 )
 
 // RateLimiter implements a minute-based rate limiter for Shopee API
@@ -51,11 +54,22 @@ func (rl *RateLimiter) Allow(ctx context.Context) bool {
 
 // Wait blocks until a request can proceed or context is cancelled
 // If rate limit is reached, waits until the next minute
+// Time spent blocked is recorded in the rate limiter wait metrics.
 func (rl *RateLimiter) Wait(ctx context.Context) error {
+	var start time.Time
 	for {
 		if rl.Allow(ctx) {
+			if !start.IsZero() {
+				waited := time.Since(start)
+				metrics.RateLimiterWaitDuration.Observe(waited.Seconds())
+				tracing.FromContext(ctx).SetAttr("shopee.rate_limit_wait_ms", waited.Milliseconds())
+			}
 			return nil
 		}
+		if start.IsZero() {
+			start = time.Now()
+			metrics.RateLimiterWaits.Inc()
+		}
 
 		// Calculate how long to wait until the next minute
 		now := time.Now()
@@ -64,6 +78,7 @@ func (rl *RateLimiter) Wait(ctx context.Context) error {
 
 		select {
 		case <-ctx.Done():
+			metrics.RateLimiterWaitDuration.Observe(time.Since(start).Seconds())
 			return ctx.Err()
 		case <-time.After(waitDuration):
 			// Continue the loop to check again in the new minute
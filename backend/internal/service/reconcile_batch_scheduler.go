@@ -15,6 +15,7 @@ type ReconcileBatchScheduler struct {
 	svc      *ReconcileService
 	interval time.Duration
 	logger   *logutil.Logger
+	tickerLoop
 }
 
 // NewReconcileBatchScheduler creates a scheduler with the given interval.
@@ -41,19 +42,7 @@ func (s *ReconcileBatchScheduler) Start(ctx context.Context) {
 		"interval": s.interval,
 	})
 
-	go func() {
-		ticker := time.NewTicker(s.interval)
-		defer ticker.Stop()
-		for {
-			select {
-			case <-ctx.Done():
-				s.logger.Info(ctx, "Start", "Reconcile batch scheduler stopped due to context cancellation")
-				return
-			case <-ticker.C:
-				s.run(ctx)
-			}
-		}
-	}()
+	s.tickerLoop.run(ctx, s.interval, s.run)
 }
 
 func (s *ReconcileBatchScheduler) run(ctx context.Context) {
@@ -84,6 +73,9 @@ func (s *ReconcileBatchScheduler) run(ctx context.Context) {
 	var wg sync.WaitGroup
 
 	for _, b := range list {
+		if s.stopping() {
+			break
+		}
 		batch := b
 		wg.Add(1)
 		sem <- struct{}{}
@@ -3,10 +3,17 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net"
 	"testing"
 	"time"
 
+	"github.com/lib/pq"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/apperr"
+	"github.com/ramadhan22/dropship-erp/backend/internal/lifecycle"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 )
 
 // Test the new error handling functionality
@@ -17,14 +24,14 @@ func TestBulkReconcileWithErrorHandling_Success(t *testing.T) {
 	// Prepare fake repos with preloaded data
 	fDrop := &fakeDropRepoRec{
 		data: map[string]*models.DropshipPurchase{
-			"DP-111": {KodePesanan: "DP-111", TotalTransaksi: 50.00},
-			"DP-222": {KodePesanan: "DP-222", TotalTransaksi: 75.00},
+			"DP-111": {KodePesanan: "DP-111", TotalTransaksi: money.New(50)},
+			"DP-222": {KodePesanan: "DP-222", TotalTransaksi: money.New(75)},
 		},
 	}
 	fShopee := &fakeShopeeRepoRec{
 		data: map[string]*models.ShopeeSettledOrder{
-			"SO-111": {OrderID: "SO-111", NetIncome: 45.00, SettledDate: time.Now()},
-			"SO-222": {OrderID: "SO-222", NetIncome: 70.00, SettledDate: time.Now()},
+			"SO-111": {OrderID: "SO-111", NetIncome: money.New(45), SettledDate: time.Now()},
+			"SO-222": {OrderID: "SO-222", NetIncome: money.New(70), SettledDate: time.Now()},
 		},
 	}
 	fJournal := &fakeJournalRepoRec{nextID: 0}
@@ -65,14 +72,14 @@ func TestBulkReconcileWithErrorHandling_WithFailures(t *testing.T) {
 	// Prepare fake repos with missing data to cause failures
 	fDrop := &fakeDropRepoRec{
 		data: map[string]*models.DropshipPurchase{
-			"DP-111": {KodePesanan: "DP-111", TotalTransaksi: 50.00},
+			"DP-111": {KodePesanan: "DP-111", TotalTransaksi: money.New(50)},
 			// DP-222 is missing - will cause error
 		},
 	}
 	fShopee := &fakeShopeeRepoRec{
 		data: map[string]*models.ShopeeSettledOrder{
-			"SO-111": {OrderID: "SO-111", NetIncome: 45.00, SettledDate: time.Now()},
-			"SO-222": {OrderID: "SO-222", NetIncome: 70.00, SettledDate: time.Now()},
+			"SO-111": {OrderID: "SO-111", NetIncome: money.New(45), SettledDate: time.Now()},
+			"SO-222": {OrderID: "SO-222", NetIncome: money.New(70), SettledDate: time.Now()},
 		},
 	}
 	fJournal := &fakeJournalRepoRec{nextID: 0}
@@ -130,73 +137,217 @@ func TestCategorizeError(t *testing.T) {
 
 	tests := []struct {
 		name         string
-		errorMessage string
+		err          error
 		expectedType string
 	}{
 		{
 			name:         "purchase not found",
-			errorMessage: "fetch DropshipPurchase DP-123: not found",
+			err:          fmt.Errorf("fetch DropshipPurchase DP-123: %w", &apperr.NotFoundError{Entity: apperr.EntityPurchase, Key: "DP-123"}),
 			expectedType: "purchase_not_found",
 		},
 		{
 			name:         "shopee order not found",
-			errorMessage: "fetch ShopeeOrder SO-456: not found",
+			err:          fmt.Errorf("fetch ShopeeOrder SO-456: %w", &apperr.NotFoundError{Entity: apperr.EntityShopeeOrder, Key: "SO-456"}),
 			expectedType: "shopee_order_not_found",
 		},
 		{
 			name:         "database error",
-			errorMessage: "database error: connection failed",
+			err:          fmt.Errorf("insert: %w", &pq.Error{Code: "23505"}),
 			expectedType: "database_error",
 		},
 		{
 			name:         "network error",
-			errorMessage: "connection refused",
+			err:          &net.OpError{Op: "dial", Err: errors.New("connection refused")},
 			expectedType: "network_error",
 		},
 		{
 			name:         "timeout error",
-			errorMessage: "context deadline exceeded",
+			err:          fmt.Errorf("fetch escrow: %w", context.DeadlineExceeded),
 			expectedType: "timeout_error",
 		},
 		{
 			name:         "journal creation error",
-			errorMessage: "create JournalEntry: failed",
+			err:          apperr.Wrap(apperr.JournalCreation, errors.New("create JournalEntry: failed")),
 			expectedType: "journal_creation_error",
 		},
 		{
 			name:         "journal balance error",
-			errorMessage: "unbalanced journal: debit 100 credit 90",
+			err:          fmt.Errorf("%w: debit 100 credit 90", apperr.ErrUnbalancedJournal),
 			expectedType: "journal_balance_error",
 		},
 		{
 			name:         "authentication error",
-			errorMessage: "invalid access_token",
+			err:          &apperr.ShopeeAPIError{Op: "GetEscrowDetail", HTTPStatus: 200, Code: "error_auth"},
 			expectedType: "authentication_error",
 		},
+		{
+			name:         "rate limited",
+			err:          &apperr.ShopeeAPIError{Op: "GetEscrowDetail", HTTPStatus: 429},
+			expectedType: "rate_limited",
+		},
+		{
+			name:         "shopee server error",
+			err:          fmt.Errorf("request failed after 3 attempts: %w", &apperr.ShopeeAPIError{Op: "request", HTTPStatus: 502}),
+			expectedType: "shopee_server_error",
+		},
+		{
+			name:         "shopee request error",
+			err:          &apperr.ShopeeAPIError{Op: "GetEscrowDetail", HTTPStatus: 200, Code: "error_param"},
+			expectedType: "shopee_request_error",
+		},
+		{
+			name:         "invalid transition",
+			err:          fmt.Errorf("DP-1: %w", lifecycle.ErrInvalidTransition),
+			expectedType: "invalid_transition",
+		},
 		{
 			name:         "unknown error",
-			errorMessage: "some unexpected error",
+			err:          errors.New("some unexpected error"),
 			expectedType: "unknown_error",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := svc.categorizeError(errors.New(tt.errorMessage))
+			result := svc.categorizeError(tt.err)
 			if result != tt.expectedType {
-				t.Errorf("categorizeError(%q) = %q, want %q", tt.errorMessage, result, tt.expectedType)
+				t.Errorf("categorizeError(%v) = %q, want %q", tt.err, result, tt.expectedType)
 			}
 		})
 	}
 }
 
+func TestRetryFailedReconciliationsSkipsPermanentFailures(t *testing.T) {
+	ctx := context.Background()
+	fDrop := &fakeDropRepoRec{data: map[string]*models.DropshipPurchase{
+		"DP-111": {KodePesanan: "DP-111", TotalTransaksi: money.New(50)},
+	}}
+	fShopee := &fakeShopeeRepoRec{data: map[string]*models.ShopeeSettledOrder{
+		"SO-111": {OrderID: "SO-111", NetIncome: money.New(45), SettledDate: time.Now()},
+	}}
+	order := "SO-111"
+	fFailed := &fakeFailedRecRepo{failures: []models.FailedReconciliation{
+		{ID: 1, PurchaseID: "DP-111", OrderID: &order, Shop: "ShopA", ErrorType: "purchase_not_found"},
+		{ID: 2, PurchaseID: "DP-111", OrderID: &order, Shop: "ShopA", ErrorType: "journal_balance_error"},
+	}}
+	fJournal := &fakeJournalRepoRec{}
+	svc := NewReconcileService(nil, fDrop, fShopee, fJournal, &fakeRecRepoRec{}, nil, &fakeDetailRepo{}, nil, nil, nil, fFailed, nil, 5, nil)
+
+	report, err := svc.RetryFailedReconciliations(ctx, "ShopA", 10)
+	if err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if report.SuccessfulTransactions != 1 || report.SkippedTransactions != 1 || report.FailedTransactions != 0 {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if len(fJournal.entries) != 1 {
+		t.Errorf("expected only the retryable failure to be reprocessed, got %d journals", len(fJournal.entries))
+	}
+}
+
+func TestRetryDueFailuresBacksOffUntilNeedsAttention(t *testing.T) {
+	ctx := context.Background()
+	order := "SO-404"
+	due := time.Now().Add(-time.Minute)
+	fFailed := &fakeFailedRecRepo{failures: []models.FailedReconciliation{
+		{ID: 7, PurchaseID: "DP-404", OrderID: &order, Shop: "ShopA", ErrorType: "purchase_not_found",
+			Status: models.FailedReconPending, MaxAttempts: 2, NextRetryAt: &due},
+	}}
+	cfg := DefaultReconciliationConfig()
+	cfg.RetryFailedTransactions = true
+	svc := NewReconcileService(nil, &fakeDropRepoRec{data: map[string]*models.DropshipPurchase{}}, &fakeShopeeRepoRec{data: map[string]*models.ShopeeSettledOrder{}},
+		&fakeJournalRepoRec{}, &fakeRecRepoRec{}, nil, &fakeDetailRepo{}, nil, nil, nil, fFailed, nil, 5, cfg)
+	svc.SetRetryPolicy(0, time.Hour)
+
+	report, err := svc.RetryDueFailures(ctx, 10)
+	if err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if report.FailedTransactions != 1 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	f := fFailed.failures[0]
+	if f.Status != models.FailedReconPending || f.Attempts != 1 || f.NextRetryAt == nil || f.NextRetryAt.Before(time.Now().Add(59*time.Minute)) {
+		t.Fatalf("expected first retry to back off by the base delay, got %+v", f)
+	}
+
+	// Not due yet: nothing is retried.
+	if report, _ := svc.RetryDueFailures(ctx, 10); report.TotalTransactions != 0 {
+		t.Fatalf("expected no due retries, got %+v", report)
+	}
+
+	// The second failure uses up the row's attempts.
+	fFailed.failures[0].NextRetryAt = &due
+	if _, err := svc.RetryDueFailures(ctx, 10); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	f = fFailed.failures[0]
+	if f.Status != models.FailedReconNeedsAttention || f.Attempts != 2 || f.NextRetryAt != nil {
+		t.Fatalf("expected needs_attention after max attempts, got %+v", f)
+	}
+	if len(fFailed.attempts) != 2 || fFailed.attempts[1].Attempt != 2 || fFailed.attempts[1].Succeeded {
+		t.Fatalf("unexpected attempt history %+v", fFailed.attempts)
+	}
+
+	// A manual retry after the data is fixed resolves it.
+	svc.dropRepo.(*fakeDropRepoRec).data["DP-404"] = &models.DropshipPurchase{KodePesanan: "DP-404", TotalTransaksi: money.New(10)}
+	svc.shopeeRepo.(*fakeShopeeRepoRec).data["SO-404"] = &models.ShopeeSettledOrder{OrderID: "SO-404", NetIncome: money.New(12), SettledDate: time.Now()}
+	got, err := svc.RetryFailure(ctx, 7)
+	if err != nil {
+		t.Fatalf("manual retry: %v", err)
+	}
+	if got.Status != models.FailedReconResolved || got.ResolvedAt == nil || got.Attempts != 3 {
+		t.Fatalf("expected resolved failure, got %+v", got)
+	}
+	if _, err := svc.RetryFailure(ctx, 7); !errors.Is(err, ErrFailureResolved) {
+		t.Fatalf("expected ErrFailureResolved, got %v", err)
+	}
+}
+
+func TestRetryDueFailuresSkipsDisabledStoresInClaim(t *testing.T) {
+	ctx := context.Background()
+	order := "SO-404"
+	due := time.Now().Add(-time.Hour)
+	later := time.Now().Add(-time.Minute)
+	fFailed := &fakeFailedRecRepo{
+		failures: []models.FailedReconciliation{
+			{ID: 1, PurchaseID: "DP-1", OrderID: &order, Shop: "ShopOff", ErrorType: "purchase_not_found",
+				Status: models.FailedReconPending, MaxAttempts: 5, NextRetryAt: &due},
+			{ID: 2, PurchaseID: "DP-2", OrderID: &order, Shop: "ShopOff", ErrorType: "purchase_not_found",
+				Status: models.FailedReconPending, MaxAttempts: 5, NextRetryAt: &due},
+			{ID: 3, PurchaseID: "DP-3", OrderID: &order, Shop: "ShopOn", ErrorType: "purchase_not_found",
+				Status: models.FailedReconPending, MaxAttempts: 5, NextRetryAt: &later},
+		},
+		storeRetry: map[string]bool{"ShopOn": true},
+	}
+	svc := NewReconcileService(nil, &fakeDropRepoRec{data: map[string]*models.DropshipPurchase{}}, &fakeShopeeRepoRec{data: map[string]*models.ShopeeSettledOrder{}},
+		&fakeJournalRepoRec{}, &fakeRecRepoRec{}, nil, &fakeDetailRepo{}, nil, nil, nil, fFailed, nil, 5, nil)
+
+	// The older rows of the disabled store must not fill the batch.
+	report, err := svc.RetryDueFailures(ctx, 1)
+	if err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if report.TotalTransactions != 1 || len(fFailed.attempts) != 1 || fFailed.attempts[0].FailedReconciliationID != 3 {
+		t.Fatalf("expected only the enabled store's row to be retried, got %+v / %+v", report, fFailed.attempts)
+	}
+}
+func TestRetryBackoffIsCapped(t *testing.T) {
+	svc := &ReconcileService{retryDelay: time.Minute}
+	if d := svc.retryBackoff(3); d != 4*time.Minute {
+		t.Errorf("backoff(3) = %v, want 4m", d)
+	}
+	if d := svc.retryBackoff(40); d != maxReconcileRetryDelay {
+		t.Errorf("backoff(40) = %v, want %v", d, maxReconcileRetryDelay)
+	}
+}
+
 func TestShouldHaltProcessing(t *testing.T) {
 	config := &models.ReconciliationConfig{
 		MaxAllowedFailures:      5,
 		FailureThresholdPercent: 10.0,
 		CriticalErrorTypes:      []string{"database_error", "critical_system_error"},
 	}
-	svc := &ReconcileService{config: config}
 
 	tests := []struct {
 		name                   string
@@ -250,7 +401,7 @@ func TestShouldHaltProcessing(t *testing.T) {
 				FailedTransactions:     tt.failedTransactions,
 			}
 
-			result := svc.shouldHaltProcessing(report, tt.errorType)
+			result := shouldHaltProcessing(config, report, tt.errorType)
 			if result != tt.expectedHalt {
 				t.Errorf("shouldHaltProcessing() = %v, want %v", result, tt.expectedHalt)
 			}
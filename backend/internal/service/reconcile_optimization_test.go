@@ -4,7 +4,9 @@ import (
 	"context"
 	"testing"
 
+	"github.com/ramadhan22/dropship-erp/backend/internal/lifecycle"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 )
 
 // MockDropshipRepoOptimized is a mock that tracks whether bulk methods are called
@@ -39,16 +41,16 @@ func (m *MockDropshipRepoOptimized) GetDropshipPurchasesByInvoices(ctx context.C
 	return result, nil
 }
 
-func (m *MockDropshipRepoOptimized) UpdatePurchaseStatus(ctx context.Context, kodePesanan, status string) error {
+func (m *MockDropshipRepoOptimized) TransitionPurchaseStatus(ctx context.Context, kodePesanan string, c lifecycle.Change) error {
 	return nil
 }
 
-func (m *MockDropshipRepoOptimized) SumDetailByInvoice(ctx context.Context, kodeInvoice string) (float64, error) {
-	return 100.0, nil
+func (m *MockDropshipRepoOptimized) SumDetailByInvoice(ctx context.Context, kodeInvoice string) (money.Amount, error) {
+	return money.New(100), nil
 }
 
-func (m *MockDropshipRepoOptimized) SumProductCostByInvoice(ctx context.Context, kodeInvoice string) (float64, error) {
-	return 80.0, nil
+func (m *MockDropshipRepoOptimized) SumProductCostByInvoice(ctx context.Context, kodeInvoice string) (money.Amount, error) {
+	return money.New(80), nil
 }
 
 // Test that the bulk optimization is working
@@ -131,14 +133,14 @@ func (m *BasicMockDropshipRepo) GetDropshipPurchaseByID(ctx context.Context, kod
 	return nil, nil
 }
 
-func (m *BasicMockDropshipRepo) UpdatePurchaseStatus(ctx context.Context, kodePesanan, status string) error {
+func (m *BasicMockDropshipRepo) TransitionPurchaseStatus(ctx context.Context, kodePesanan string, c lifecycle.Change) error {
 	return nil
 }
 
-func (m *BasicMockDropshipRepo) SumDetailByInvoice(ctx context.Context, kodeInvoice string) (float64, error) {
-	return 100.0, nil
+func (m *BasicMockDropshipRepo) SumDetailByInvoice(ctx context.Context, kodeInvoice string) (money.Amount, error) {
+	return money.New(100), nil
 }
 
-func (m *BasicMockDropshipRepo) SumProductCostByInvoice(ctx context.Context, kodeInvoice string) (float64, error) {
-	return 80.0, nil
+func (m *BasicMockDropshipRepo) SumProductCostByInvoice(ctx context.Context, kodeInvoice string) (money.Amount, error) {
+	return money.New(80), nil
 }
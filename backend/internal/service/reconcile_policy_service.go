@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/logutil"
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
+)
+
+// ErrInvalidPolicy is returned when a reconciliation policy fails validation.
+var ErrInvalidPolicy = errors.New("invalid reconciliation policy")
+
+// DefaultReconciliationConfig is the policy of stores without their own row
+// in reconciliation_policies.
+func DefaultReconciliationConfig() *models.ReconciliationConfig {
+	return &models.ReconciliationConfig{
+		MaxAllowedFailures:           100,
+		FailureThresholdPercent:      5.0,
+		CriticalErrorTypes:           []string{"database_error", "critical_system_error"},
+		RetryFailedTransactions:      false,
+		GenerateDetailedReport:       true,
+		AmountTolerance:              money.Zero,
+		ShippingDiscrepancyThreshold: money.FromSen(1),
+	}
+}
+
+// ReconcilePolicyRepo is the storage used by ReconcilePolicyService.
+type ReconcilePolicyRepo interface {
+	Get(ctx context.Context, store string) (*models.ReconciliationPolicy, error)
+	List(ctx context.Context) ([]models.ReconciliationPolicy, error)
+	Upsert(ctx context.Context, p *models.ReconciliationPolicy) error
+	Delete(ctx context.Context, store string) error
+}
+
+// ReconcilePolicyService stores per-store reconciliation policies and
+// resolves the policy in effect for a store.
+type ReconcilePolicyService struct {
+	repo     ReconcilePolicyRepo
+	defaults models.ReconciliationConfig
+}
+
+// NewReconcilePolicyService constructs a ReconcilePolicyService. A nil
+// defaults uses DefaultReconciliationConfig.
+func NewReconcilePolicyService(repo ReconcilePolicyRepo, defaults *models.ReconciliationConfig) *ReconcilePolicyService {
+	if defaults == nil {
+		defaults = DefaultReconciliationConfig()
+	}
+	return &ReconcilePolicyService{repo: repo, defaults: *defaults}
+}
+
+// PolicyFor returns the policy in effect for store. Lookup errors fall back
+// to the defaults so a broken policy table never stops reconciliation.
+func (s *ReconcilePolicyService) PolicyFor(ctx context.Context, store string) *models.ReconciliationConfig {
+	cfg := s.defaults
+	if s.repo == nil || store == "" {
+		return &cfg
+	}
+	p, err := s.repo.Get(ctx, store)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			logutil.Error(ctx, "PolicyFor", "Failed to load reconciliation policy, using defaults", err, map[string]interface{}{
+				"store": store,
+			})
+		}
+		return &cfg
+	}
+	return &p.ReconciliationConfig
+}
+
+// Defaults returns the policy used for stores without their own row.
+func (s *ReconcilePolicyService) Defaults() models.ReconciliationConfig {
+	return s.defaults
+}
+
+// ListPolicies returns every store with its own policy.
+func (s *ReconcilePolicyService) ListPolicies(ctx context.Context) ([]models.ReconciliationPolicy, error) {
+	return s.repo.List(ctx)
+}
+
+// GetPolicy returns the policy in effect for store; IsDefault tells whether
+// the store has no row of its own.
+func (s *ReconcilePolicyService) GetPolicy(ctx context.Context, store string) (*models.ReconciliationPolicy, error) {
+	p, err := s.repo.Get(ctx, store)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &models.ReconciliationPolicy{Store: store, ReconciliationConfig: s.defaults, IsDefault: true}, nil
+	}
+	return p, err
+}
+
+// SavePolicy validates and stores the policy of p.Store.
+func (s *ReconcilePolicyService) SavePolicy(ctx context.Context, p *models.ReconciliationPolicy) error {
+	if err := validatePolicy(p); err != nil {
+		return err
+	}
+	if p.CriticalErrorTypes == nil {
+		p.CriticalErrorTypes = pq.StringArray{}
+	}
+	p.IsDefault = false
+	return s.repo.Upsert(ctx, p)
+}
+
+// DeletePolicy removes the policy of store so the defaults apply again.
+func (s *ReconcilePolicyService) DeletePolicy(ctx context.Context, store string) error {
+	return s.repo.Delete(ctx, store)
+}
+
+func validatePolicy(p *models.ReconciliationPolicy) error {
+	switch {
+	case p.Store == "":
+		return fmt.Errorf("%w: store is required", ErrInvalidPolicy)
+	case p.MaxAllowedFailures < 0:
+		return fmt.Errorf("%w: max_allowed_failures must not be negative", ErrInvalidPolicy)
+	case p.FailureThresholdPercent < 0 || p.FailureThresholdPercent > 100:
+		return fmt.Errorf("%w: failure_threshold_percent must be between 0 and 100", ErrInvalidPolicy)
+	case p.AmountTolerance < 0:
+		return fmt.Errorf("%w: amount_tolerance must not be negative", ErrInvalidPolicy)
+	case p.ShippingDiscrepancyThreshold < 0:
+		return fmt.Errorf("%w: shipping_discrepancy_threshold must not be negative", ErrInvalidPolicy)
+	}
+	return nil
+}
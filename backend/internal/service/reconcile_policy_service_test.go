@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
+)
+
+type fakePolicyRepo struct {
+	data map[string]*models.ReconciliationPolicy
+}
+
+func (f *fakePolicyRepo) Get(ctx context.Context, store string) (*models.ReconciliationPolicy, error) {
+	if p, ok := f.data[store]; ok {
+		return p, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakePolicyRepo) List(ctx context.Context) ([]models.ReconciliationPolicy, error) {
+	var list []models.ReconciliationPolicy
+	for _, p := range f.data {
+		list = append(list, *p)
+	}
+	return list, nil
+}
+
+func (f *fakePolicyRepo) Upsert(ctx context.Context, p *models.ReconciliationPolicy) error {
+	f.data[p.Store] = p
+	return nil
+}
+
+func (f *fakePolicyRepo) Delete(ctx context.Context, store string) error {
+	delete(f.data, store)
+	return nil
+}
+
+func TestReconcilePolicyServiceResolvesPerStore(t *testing.T) {
+	ctx := context.Background()
+	svc := NewReconcilePolicyService(&fakePolicyRepo{data: map[string]*models.ReconciliationPolicy{}}, nil)
+
+	p, err := svc.GetPolicy(ctx, "ShopA")
+	if err != nil || !p.IsDefault || p.MaxAllowedFailures != 100 {
+		t.Fatalf("expected defaults for ShopA, got %+v (%v)", p, err)
+	}
+
+	custom := &models.ReconciliationPolicy{Store: "ShopA"}
+	custom.MaxAllowedFailures = 2
+	custom.FailureThresholdPercent = 50
+	custom.AmountTolerance = money.New(100)
+	if err := svc.SavePolicy(ctx, custom); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if got := svc.PolicyFor(ctx, "ShopA"); got.MaxAllowedFailures != 2 || got.AmountTolerance != money.New(100) {
+		t.Errorf("unexpected ShopA policy %+v", got)
+	}
+	if got := svc.PolicyFor(ctx, "ShopB"); got.MaxAllowedFailures != 100 {
+		t.Errorf("ShopB should use the defaults, got %+v", got)
+	}
+
+	bad := &models.ReconciliationPolicy{Store: "ShopA"}
+	bad.FailureThresholdPercent = 150
+	if err := svc.SavePolicy(ctx, bad); !errors.Is(err, ErrInvalidPolicy) {
+		t.Errorf("expected ErrInvalidPolicy, got %v", err)
+	}
+}
+
+func TestBulkReconcileHaltsOnStorePolicy(t *testing.T) {
+	ctx := context.Background()
+	fShopee := &fakeShopeeRepoRec{data: map[string]*models.ShopeeSettledOrder{
+		"SO-1": {OrderID: "SO-1", NetIncome: money.New(45), SettledDate: time.Now()},
+	}}
+	policies := &fakePolicyRepo{data: map[string]*models.ReconciliationPolicy{}}
+	strict := &models.ReconciliationPolicy{Store: "ShopA"}
+	strict.MaxAllowedFailures = 1
+	policies.data["ShopA"] = strict
+
+	svc := NewReconcileService(nil, &fakeDropRepoRec{data: map[string]*models.DropshipPurchase{}}, fShopee, &fakeJournalRepoRec{}, &fakeRecRepoRec{}, nil, &fakeDetailRepo{}, nil, nil, nil, &fakeFailedRecRepo{}, nil, 5, nil)
+	svc.SetPolicySource(NewReconcilePolicyService(policies, nil))
+
+	pairs := [][2]string{{"DP-1", "SO-1"}, {"DP-2", "SO-1"}, {"DP-3", "SO-1"}}
+	report, err := svc.BulkReconcileWithErrorHandling(ctx, pairs, "ShopA", nil)
+	if err != nil {
+		t.Fatalf("bulk: %v", err)
+	}
+	if !report.Halted || report.FailedTransactions != 1 {
+		t.Errorf("ShopA should halt after one failure, got %+v", report)
+	}
+
+	report, err = svc.BulkReconcileWithErrorHandling(ctx, pairs, "ShopB", nil)
+	if err != nil {
+		t.Fatalf("bulk: %v", err)
+	}
+	if report.Halted || report.FailedTransactions != 3 {
+		t.Errorf("ShopB uses the defaults and should not halt, got %+v", report)
+	}
+}
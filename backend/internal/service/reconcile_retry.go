@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/apperr"
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+)
+
+const (
+	defaultReconcileRetryMaxAttempts = 5
+	defaultReconcileRetryDelay       = 5 * time.Minute
+	// maxReconcileRetryDelay caps the exponential backoff.
+	maxReconcileRetryDelay = 24 * time.Hour
+	// reconcileRetryClaimTimeout is how long a claimed retry stays hidden
+	// from other workers before it is considered abandoned.
+	reconcileRetryClaimTimeout = 30 * time.Minute
+)
+
+// ErrFailureResolved is returned when retrying a failed reconciliation that
+// a previous retry already resolved.
+var ErrFailureResolved = errors.New("failed reconciliation already resolved")
+
+// SetRetryPolicy sets the attempt budget given to new failed reconciliations
+// and the delay before their first retry, doubled after every failed one.
+// Non-positive values keep the defaults.
+func (s *ReconcileService) SetRetryPolicy(maxAttempts int, delay time.Duration) {
+	if maxAttempts > 0 {
+		s.retryMaxAttempts = maxAttempts
+	}
+	if delay > 0 {
+		s.retryDelay = delay
+	}
+}
+
+// insertFailure records a new failed reconciliation. Retryable failures are
+// scheduled for their first retry; permanent ones go straight to the
+// needs_attention queue.
+func (s *ReconcileService) insertFailure(ctx context.Context, f *models.FailedReconciliation) error {
+	if f.MaxAttempts == 0 {
+		f.MaxAttempts = s.retryMaxAttempts
+	}
+	if f.Status == "" {
+		if apperr.Category(f.ErrorType).Retryable() {
+			f.Status = models.FailedReconPending
+			next := f.FailedAt.Add(s.retryDelay)
+			f.NextRetryAt = &next
+		} else {
+			f.Status = models.FailedReconNeedsAttention
+		}
+	}
+	return s.failedRepo.InsertFailedReconciliation(ctx, f)
+}
+
+// retryBackoff returns the delay after the given number of failed attempts.
+func (s *ReconcileService) retryBackoff(attempts int) time.Duration {
+	d := s.retryDelay
+	for i := 1; i < attempts && d < maxReconcileRetryDelay; i++ {
+		d *= 2
+	}
+	if d > maxReconcileRetryDelay {
+		d = maxReconcileRetryDelay
+	}
+	return d
+}
+
+// attemptFailure reprocesses one failed reconciliation and records the
+// outcome on f and in its attempt history. A retry that fails again is not
+// an error; only failing to save the outcome is.
+func (s *ReconcileService) attemptFailure(ctx context.Context, f *models.FailedReconciliation) error {
+	var err error
+	if f.OrderID != nil {
+		err = s.MatchAndJournal(ctx, f.PurchaseID, *f.OrderID, f.Shop)
+	} else {
+		// For transactions without order ID, try to complete the purchase
+		err = s.CheckAndMarkComplete(ctx, f.PurchaseID)
+	}
+
+	now := time.Now()
+	f.Attempts++
+	f.LastAttemptAt = &now
+	a := &models.FailedReconciliationAttempt{
+		FailedReconciliationID: f.ID,
+		Attempt:                f.Attempts,
+		Succeeded:              err == nil,
+		AttemptedAt:            now,
+	}
+	if err == nil {
+		f.Status = models.FailedReconResolved
+		f.NextRetryAt = nil
+		f.ResolvedAt = &now
+	} else {
+		errorType, msg := s.categorizeError(err), err.Error()
+		f.ErrorType, f.ErrorMsg = errorType, msg
+		a.ErrorType, a.ErrorMsg = &errorType, &msg
+		if !apperr.Category(errorType).Retryable() || f.Attempts >= f.MaxAttempts {
+			f.Status = models.FailedReconNeedsAttention
+			f.NextRetryAt = nil
+		} else {
+			f.Status = models.FailedReconPending
+			next := now.Add(s.retryBackoff(f.Attempts))
+			f.NextRetryAt = &next
+		}
+	}
+	return s.failedRepo.RecordAttempt(ctx, f, a)
+}
+
+// retryFailures retries each failed reconciliation in list whose category
+// allows it and reports the outcome. Permanent failures need the data fixed
+// first, so they are moved to needs_attention and counted as skipped.
+func (s *ReconcileService) retryFailures(ctx context.Context, list []models.FailedReconciliation) *models.ReconciliationReport {
+	startTime := time.Now()
+	report := &models.ReconciliationReport{
+		TotalTransactions:     len(list),
+		ProcessingStartTime:   startTime,
+		FailureCategories:     make(map[string]int),
+		FailedTransactionList: []models.FailedReconciliation{},
+	}
+
+	for i := range list {
+		f := &list[i]
+		if !apperr.Category(f.ErrorType).Retryable() {
+			report.SkippedTransactions++
+			if err := s.failedRepo.MarkNeedsAttention(ctx, f.ID); err != nil {
+				reconcileLog.Error(ctx, "retryFailures", "Failed to move failed reconciliation to needs attention", err, map[string]interface{}{
+					"failure_id": f.ID,
+				})
+			}
+			continue
+		}
+		if err := s.attemptFailure(ctx, f); err != nil {
+			reconcileLog.Error(ctx, "retryFailures", "Failed to record retry of failed reconciliation", err, map[string]interface{}{
+				"failure_id": f.ID,
+			})
+		}
+		if f.Status == models.FailedReconResolved {
+			report.SuccessfulTransactions++
+			continue
+		}
+		report.FailedTransactions++
+		report.FailureCategories[f.ErrorType]++
+		report.FailedTransactionList = append(report.FailedTransactionList, *f)
+	}
+
+	endTime := time.Now()
+	report.ProcessingEndTime = endTime
+	report.Duration = endTime.Sub(startTime).String()
+	if report.TotalTransactions > 0 {
+		report.FailureRate = float64(report.FailedTransactions) / float64(report.TotalTransactions) * 100
+	}
+	return report
+}
+
+// RetryDueFailures retries up to limit failed reconciliations whose backoff
+// has elapsed. Stores whose policy disables automatic retries are filtered
+// out by the claim, so they never take up the batch.
+func (s *ReconcileService) RetryDueFailures(ctx context.Context, limit int) (*models.ReconciliationReport, error) {
+	if s.failedRepo == nil {
+		return &models.ReconciliationReport{FailureCategories: map[string]int{}}, nil
+	}
+	now := time.Now()
+	retryByDefault := s.policyFor(ctx, "").RetryFailedTransactions
+	list, err := s.failedRepo.ClaimDueRetries(ctx, now, now.Add(reconcileRetryClaimTimeout), retryByDefault, limit)
+	if err != nil {
+		return nil, err
+	}
+	return s.retryFailures(ctx, list), nil
+}
+
+// RetryFailure retries one failed reconciliation right away, including one
+// in the needs_attention queue after its data has been fixed.
+func (s *ReconcileService) RetryFailure(ctx context.Context, id int64) (*models.FailedReconciliation, error) {
+	f, err := s.failedRepo.GetFailedReconciliation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if f.Status == models.FailedReconResolved {
+		return nil, ErrFailureResolved
+	}
+	if err := s.attemptFailure(ctx, f); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// ListFailedReconciliations lists failed reconciliations by status and shop.
+// Use models.FailedReconNeedsAttention to read the needs-attention queue.
+func (s *ReconcileService) ListFailedReconciliations(ctx context.Context, status, shop string, limit, offset int) ([]models.FailedReconciliation, int, error) {
+	return s.failedRepo.ListFailedReconciliations(ctx, status, shop, limit, offset)
+}
+
+// ListFailedReconciliationsFiltered uses the filtering framework to fetch
+// failed reconciliations.
+func (s *ReconcileService) ListFailedReconciliationsFiltered(ctx context.Context, params *models.FilterParams) (*models.QueryResult, error) {
+	return s.failedRepo.ListFailedReconciliationsFiltered(ctx, params)
+}
+
+// GetFailedReconciliation returns a failed reconciliation with its attempts.
+func (s *ReconcileService) GetFailedReconciliation(ctx context.Context, id int64) (*models.FailedReconciliation, []models.FailedReconciliationAttempt, error) {
+	f, err := s.failedRepo.GetFailedReconciliation(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	attempts, err := s.failedRepo.ListAttempts(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, attempts, nil
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/logutil"
+)
+
+// ReconcileRetryScheduler retries failed reconciliations whose backoff has
+// elapsed in the background.
+type ReconcileRetryScheduler struct {
+	svc       *ReconcileService
+	interval  time.Duration
+	batchSize int
+	logger    *logutil.Logger
+	tickerLoop
+}
+
+// NewReconcileRetryScheduler creates a scheduler that retries up to
+// batchSize due failures every interval.
+func NewReconcileRetryScheduler(svc *ReconcileService, interval time.Duration, batchSize int) *ReconcileRetryScheduler {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	return &ReconcileRetryScheduler{
+		svc:       svc,
+		interval:  interval,
+		batchSize: batchSize,
+		logger:    logutil.NewLogger("reconcile-retry-scheduler", logutil.INFO),
+	}
+}
+
+// Start launches the scheduler loop.
+func (s *ReconcileRetryScheduler) Start(ctx context.Context) {
+	if s == nil {
+		return
+	}
+
+	ctx = logutil.WithNewCorrelationID(ctx)
+	s.logger.Info(ctx, "Start", "Starting reconcile retry scheduler", map[string]interface{}{
+		"interval":   s.interval,
+		"batch_size": s.batchSize,
+	})
+
+	s.tickerLoop.run(ctx, s.interval, s.run)
+}
+
+func (s *ReconcileRetryScheduler) run(ctx context.Context) {
+	runCtx := logutil.WithNewCorrelationID(ctx)
+
+	report, err := s.svc.RetryDueFailures(runCtx, s.batchSize)
+	if err != nil {
+		s.logger.Error(runCtx, "RetryDueFailures", "Failed to retry failed reconciliations", err)
+		return
+	}
+	if report.TotalTransactions > 0 {
+		s.logger.Info(runCtx, "Run", "Retried failed reconciliations", map[string]interface{}{
+			"retried":   report.TotalTransactions,
+			"resolved":  report.SuccessfulTransactions,
+			"failed":    report.FailedTransactions,
+			"permanent": report.SkippedTransactions,
+		})
+	}
+}
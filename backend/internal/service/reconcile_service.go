@@ -4,8 +4,8 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
 	"math"
 	"strconv"
 	"strings"
@@ -15,18 +15,24 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/ramadhan22/dropship-erp/backend/internal/apperr"
+	"github.com/ramadhan22/dropship-erp/backend/internal/cache"
+	"github.com/ramadhan22/dropship-erp/backend/internal/lifecycle"
 	"github.com/ramadhan22/dropship-erp/backend/internal/logutil"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
 )
 
+var reconcileLog = logutil.NewLogger("reconcile-service", logutil.INFO)
+
 // ReconcileRepoInterface defines just the methods needed from each repo.
 type ReconcileServiceDropshipRepo interface {
 	GetDropshipPurchaseByInvoice(ctx context.Context, kodeInvoice string) (*models.DropshipPurchase, error)
 	GetDropshipPurchaseByID(ctx context.Context, kodePesanan string) (*models.DropshipPurchase, error)
-	UpdatePurchaseStatus(ctx context.Context, kodePesanan, status string) error
-	SumDetailByInvoice(ctx context.Context, kodeInvoice string) (float64, error)
-	SumProductCostByInvoice(ctx context.Context, kodeInvoice string) (float64, error)
+	TransitionPurchaseStatus(ctx context.Context, kodePesanan string, c lifecycle.Change) error
+	SumDetailByInvoice(ctx context.Context, kodeInvoice string) (money.Amount, error)
+	SumProductCostByInvoice(ctx context.Context, kodeInvoice string) (money.Amount, error)
 }
 type ReconcileServiceShopeeRepo interface {
 	// We only need to fetch the settled order.
@@ -60,6 +66,7 @@ type ReconcileServiceBatchSvc interface {
 	ListDetails(ctx context.Context, batchID int64) ([]models.BatchHistoryDetail, error)
 	UpdateDetailStatus(ctx context.Context, id int64, status, msg string) error
 	ListPendingByType(ctx context.Context, typ string) ([]models.BatchHistory, error)
+	Claim(ctx context.Context, id int64) (bool, error)
 	GetByID(ctx context.Context, id int64) (*models.BatchHistory, error)
 	UpdateBatchData(ctx context.Context, id int64, total, done int) error
 }
@@ -69,8 +76,14 @@ type ReconcileServiceFailedRepo interface {
 	GetFailedReconciliationsByShop(ctx context.Context, shop string, limit, offset int) ([]models.FailedReconciliation, error)
 	GetFailedReconciliationsByBatch(ctx context.Context, batchID int64) ([]models.FailedReconciliation, error)
 	CountFailedReconciliationsByErrorType(ctx context.Context, shop string, since time.Time) (map[string]int, error)
-	MarkAsRetried(ctx context.Context, id int64) error
 	GetUnretriedFailedReconciliations(ctx context.Context, shop string, limit int) ([]models.FailedReconciliation, error)
+	GetFailedReconciliation(ctx context.Context, id int64) (*models.FailedReconciliation, error)
+	ClaimDueRetries(ctx context.Context, now, leaseUntil time.Time, retryByDefault bool, limit int) ([]models.FailedReconciliation, error)
+	ListFailedReconciliations(ctx context.Context, status, shop string, limit, offset int) ([]models.FailedReconciliation, int, error)
+	ListFailedReconciliationsFiltered(ctx context.Context, params *models.FilterParams) (*models.QueryResult, error)
+	RecordAttempt(ctx context.Context, failed *models.FailedReconciliation, a *models.FailedReconciliationAttempt) error
+	MarkNeedsAttention(ctx context.Context, id int64) error
+	ListAttempts(ctx context.Context, id int64) ([]models.FailedReconciliationAttempt, error)
 }
 
 // ReconcileService orchestrates matching Dropship + Shopee, creating journal entries + lines, and recording reconciliation.
@@ -90,6 +103,18 @@ type ReconcileService struct {
 	backgroundSvc *ShopeeDetailBackgroundService
 	maxThreads    int
 	config        *models.ReconciliationConfig
+	policies      ReconcilePolicySource
+	cache         Cache
+	// retryMaxAttempts and retryDelay drive the automatic retry of failed
+	// reconciliations; see SetRetryPolicy.
+	retryMaxAttempts int
+	retryDelay       time.Duration
+}
+
+// ReconcilePolicySource resolves the reconciliation policy of a store.
+// ReconcilePolicyService implements it.
+type ReconcilePolicySource interface {
+	PolicyFor(ctx context.Context, store string) *models.ReconciliationConfig
 }
 
 // NewReconcileService constructs a ReconcileService.
@@ -111,13 +136,7 @@ func NewReconcileService(
 ) *ReconcileService {
 	// Set default config if not provided
 	if config == nil {
-		config = &models.ReconciliationConfig{
-			MaxAllowedFailures:      100,
-			FailureThresholdPercent: 5.0,
-			CriticalErrorTypes:      []string{"database_error", "critical_system_error"},
-			RetryFailedTransactions: false,
-			GenerateDetailedReport:  true,
-		}
+		config = DefaultReconciliationConfig()
 	}
 	rs := &ReconcileService{
 		db:           db,
@@ -134,6 +153,9 @@ func NewReconcileService(
 		shipDiscRepo: sdr,
 		maxThreads:   maxThreads,
 		config:       config,
+
+		retryMaxAttempts: defaultReconcileRetryMaxAttempts,
+		retryDelay:       defaultReconcileRetryDelay,
 	}
 
 	// Initialize background service for Shopee detail fetching
@@ -142,6 +164,29 @@ func NewReconcileService(
 	return rs
 }
 
+// SetCache enables invalidation of cached summaries and reports whenever
+// reconciliation posts journals or changes purchase status.
+func (s *ReconcileService) SetCache(c Cache) {
+	s.cache = c
+}
+
+// SetPolicySource makes reconciliation use per-store policies instead of
+// the single config passed to NewReconcileService.
+func (s *ReconcileService) SetPolicySource(p ReconcilePolicySource) {
+	s.policies = p
+}
+
+// policyFor returns the reconciliation policy in effect for store.
+func (s *ReconcileService) policyFor(ctx context.Context, store string) *models.ReconciliationConfig {
+	if s.policies != nil {
+		return s.policies.PolicyFor(ctx, store)
+	}
+	return s.config
+}
+
+// reconcileWriteTags are invalidated after reconciliation writes.
+var reconcileWriteTags = []string{cache.TagPurchases, cache.TagJournals, cache.TagReports}
+
 // StreamReconcileAll creates a streaming processor for handling millions of records
 func (s *ReconcileService) StreamReconcileAll(ctx context.Context, shop string, config *ReconcileStreamConfig) (*ReconcileStreamResult, error) {
 	processor := NewReconcileStreamProcessor(s, config)
@@ -228,7 +273,7 @@ func (s *ReconcileService) MatchAndJournal(
 		logger.Error(ctx, "MatchAndJournal", "Failed to create JournalEntry", err, map[string]interface{}{
 			"journal_entry": je,
 		})
-		return fmt.Errorf("create JournalEntry: %w", err)
+		return apperr.Wrap(apperr.JournalCreation, fmt.Errorf("create JournalEntry: %w", err))
 	}
 
 	logger.Debug(ctx, "MatchAndJournal", "Created journal entry", map[string]interface{}{
@@ -249,7 +294,7 @@ func (s *ReconcileService) MatchAndJournal(
 		logger.Error(ctx, "MatchAndJournal", "Failed to insert JournalLine 1", err, map[string]interface{}{
 			"journal_line": jl1,
 		})
-		return fmt.Errorf("insert JournalLine 1: %w", err)
+		return apperr.Wrap(apperr.JournalCreation, fmt.Errorf("insert JournalLine 1: %w", err))
 	}
 	jl2 := &models.JournalLine{
 		JournalID: journalID,
@@ -262,7 +307,7 @@ func (s *ReconcileService) MatchAndJournal(
 		logger.Error(ctx, "MatchAndJournal", "Failed to insert JournalLine 2", err, map[string]interface{}{
 			"journal_line": jl2,
 		})
-		return fmt.Errorf("insert JournalLine 2: %w", err)
+		return apperr.Wrap(apperr.JournalCreation, fmt.Errorf("insert JournalLine 2: %w", err))
 	}
 
 	// 5. Insert into reconciled_transactions
@@ -286,6 +331,7 @@ func (s *ReconcileService) MatchAndJournal(
 			return err
 		}
 	}
+	invalidateCache(ctx, s.cache, reconcileWriteTags...)
 
 	logger.Info(ctx, "MatchAndJournal", "Reconciliation completed successfully", map[string]interface{}{
 		"purchase_id": purchaseID,
@@ -315,7 +361,9 @@ func (s *ReconcileService) bulkGetDropshipPurchasesByInvoices(ctx context.Contex
 	for _, inv := range invoices {
 		dp, err := s.dropRepo.GetDropshipPurchaseByInvoice(ctx, inv)
 		if err != nil {
-			log.Printf("fetch purchase %s: %v", inv, err)
+			reconcileLog.Error(ctx, "bulkGetDropshipPurchasesByInvoices", "Failed to fetch purchase", err, map[string]interface{}{
+				"invoice": inv,
+			})
 			continue
 		}
 		if dp != nil {
@@ -445,6 +493,7 @@ func (s *ReconcileService) updateCandidatesOrderStatus(ctx context.Context, cand
 
 // processCandidateBatchForStore processes candidates for a specific store in batches of 49
 func (s *ReconcileService) processCandidateBatchForStore(ctx context.Context, candidates []models.ReconcileCandidate, storeName string, indices []int) error {
+	ctx = logutil.WithShop(ctx, storeName)
 	st, err := s.storeRepo.GetStoreByName(ctx, storeName)
 	if err != nil || st == nil || st.AccessToken == nil || st.ShopID == nil {
 		logutil.Errorf("fetch store %s: %v", storeName, err)
@@ -471,10 +520,12 @@ func (s *ReconcileService) processCandidateBatchForStore(ctx context.Context, ca
 			orderSNs[j] = candidates[idx].KodeInvoiceChannel
 		}
 
-		log.Printf("Fetching Shopee order details in batch for store %s: %v", storeName, orderSNs)
+		reconcileLog.Debug(ctx, "processCandidateBatchForStore", "Fetching order details", map[string]interface{}{
+			"orders": orderSNs,
+		})
 
 		details, err := s.client.FetchShopeeOrderDetails(ctx, *st.AccessToken, *st.ShopID, orderSNs)
-		if err != nil && strings.Contains(err.Error(), "invalid_access_token") {
+		if errors.Is(err, apperr.ErrTokenExpired) {
 			if e := s.ensureStoreTokenValid(ctx, st); e == nil {
 				details, err = s.client.FetchShopeeOrderDetails(ctx, *st.AccessToken, *st.ShopID, orderSNs)
 			}
@@ -515,7 +566,9 @@ func (s *ReconcileService) processCandidateBatchForStore(ctx context.Context, ca
 				if s.detailRepo != nil {
 					row, items, packages := normalizeOrderDetail(orderSN, storeName, detail)
 					if err := s.detailRepo.SaveOrderDetail(ctx, row, items, packages); err != nil {
-						log.Printf("save order detail %s: %v", orderSN, err)
+						reconcileLog.Error(ctx, "processCandidateBatchForStore", "Failed to save order detail", err, map[string]interface{}{
+							"order_sn": orderSN,
+						})
 					}
 				}
 			} else {
@@ -534,7 +587,9 @@ func (s *ReconcileService) updateCandidatesOrderStatusIndividual(ctx context.Con
 			continue // Already has status
 		}
 
-		log.Printf("Fetching Shopee order detail for %s", candidates[i].KodeInvoiceChannel)
+		reconcileLog.Debug(ctx, "updateCandidatesOrderStatusIndividual", "Fetching order detail", map[string]interface{}{
+			"invoice": candidates[i].KodeInvoiceChannel,
+		})
 
 		var statusStr string
 		if s.detailRepo != nil {
@@ -578,7 +633,9 @@ func (s *ReconcileService) updateCandidatesOrderStatusIndividualForStore(ctx con
 			continue // Already has status
 		}
 
-		log.Printf("Fetching Shopee order detail for %s", candidates[idx].KodeInvoiceChannel)
+		reconcileLog.Debug(ctx, "updateCandidatesOrderStatusIndividualForStore", "Fetching order detail", map[string]interface{}{
+			"invoice": candidates[idx].KodeInvoiceChannel,
+		})
 
 		detail, err := s.GetShopeeOrderDetail(ctx, candidates[idx].KodeInvoiceChannel)
 		if err != nil {
@@ -606,7 +663,9 @@ func (s *ReconcileService) updateCandidatesOrderStatusIndividualForStore(ctx con
 
 // BulkReconcile simply loops MatchAndJournal over pairs.
 func (s *ReconcileService) BulkReconcile(ctx context.Context, pairs [][2]string, shop string) error {
-	log.Printf("BulkReconcile %d pairs for shop %s", len(pairs), shop)
+	reconcileLog.Info(ctx, "BulkReconcile", "Bulk reconcile started", map[string]interface{}{
+		"pairs": len(pairs),
+	})
 	for _, p := range pairs {
 		if err := s.MatchAndJournal(ctx, p[0], p[1], shop); err != nil {
 			return err
@@ -619,7 +678,10 @@ func (s *ReconcileService) BulkReconcile(ctx context.Context, pairs [][2]string,
 // It continues processing even when individual transactions fail and provides a detailed report.
 func (s *ReconcileService) BulkReconcileWithErrorHandling(ctx context.Context, pairs [][2]string, shop string, batchID *int64) (*models.ReconciliationReport, error) {
 	startTime := time.Now()
-	log.Printf("BulkReconcileWithErrorHandling %d pairs for shop %s", len(pairs), shop)
+	reconcileLog.Info(ctx, "BulkReconcileWithErrorHandling", "Bulk reconcile started", map[string]interface{}{
+		"pairs": len(pairs),
+	})
+	policy := s.policyFor(ctx, shop)
 
 	report := &models.ReconciliationReport{
 		TotalTransactions:      len(pairs),
@@ -635,7 +697,9 @@ func (s *ReconcileService) BulkReconcileWithErrorHandling(ctx context.Context, p
 		if err != nil {
 			// Handle the error gracefully
 			if failErr := s.recordFailedReconciliation(ctx, p[0], &p[1], shop, err, batchID); failErr != nil {
-				log.Printf("Failed to record failure for %s: %v", p[0], failErr)
+				reconcileLog.Error(ctx, "BulkReconcileWithErrorHandling", "Failed to record reconcile failure", failErr, map[string]interface{}{
+					"invoice": p[0],
+				})
 			}
 
 			// Update report
@@ -644,8 +708,9 @@ func (s *ReconcileService) BulkReconcileWithErrorHandling(ctx context.Context, p
 			report.FailureCategories[errorType]++
 
 			// Check if we should halt processing
-			if s.shouldHaltProcessing(report, errorType) {
-				log.Printf("Halting reconciliation due to critical error or failure threshold")
+			if shouldHaltProcessing(policy, report, errorType) {
+				reconcileLog.Warn(ctx, "BulkReconcileWithErrorHandling", "Halting on critical error or failure threshold")
+				report.Halted = true
 				break
 			}
 
@@ -664,14 +729,17 @@ func (s *ReconcileService) BulkReconcileWithErrorHandling(ctx context.Context, p
 	}
 
 	// Add failed transaction details if configured
-	if s.config.GenerateDetailedReport && report.FailedTransactions > 0 && batchID != nil {
+	if policy.GenerateDetailedReport && report.FailedTransactions > 0 && batchID != nil {
 		if failedList, err := s.failedRepo.GetFailedReconciliationsByBatch(ctx, *batchID); err == nil {
 			report.FailedTransactionList = failedList
 		}
 	}
 
-	log.Printf("BulkReconcileWithErrorHandling completed: %d successful, %d failed, %.2f%% failure rate",
-		report.SuccessfulTransactions, report.FailedTransactions, report.FailureRate)
+	reconcileLog.Info(ctx, "BulkReconcileWithErrorHandling", "Bulk reconcile completed", map[string]interface{}{
+		"successful":   report.SuccessfulTransactions,
+		"failed":       report.FailedTransactions,
+		"failure_rate": report.FailureRate,
+	})
 
 	return report, nil
 }
@@ -692,52 +760,27 @@ func (s *ReconcileService) recordFailedReconciliation(ctx context.Context, purch
 		BatchID:    batchID,
 	}
 
-	return s.failedRepo.InsertFailedReconciliation(ctx, failed)
+	return s.insertFailure(ctx, failed)
 }
 
-// categorizeError classifies errors into categories for reporting and handling.
+// categorizeError returns the failure category stored in
+// failed_reconciliations.error_type. See apperr.Classify.
 func (s *ReconcileService) categorizeError(err error) string {
-	errStr := strings.ToLower(err.Error())
-
-	if strings.Contains(errStr, "context canceled") || strings.Contains(errStr, "context deadline") {
-		return "timeout_error"
-	}
-	if strings.Contains(errStr, "database") || strings.Contains(errStr, "sql") {
-		return "database_error"
-	}
-	if strings.Contains(errStr, "connection") || strings.Contains(errStr, "network") {
-		return "network_error"
-	}
-	if strings.Contains(errStr, "fetch dropshippurchase") {
-		return "purchase_not_found"
-	}
-	if strings.Contains(errStr, "fetch shopeeorder") {
-		return "shopee_order_not_found"
-	}
-	if strings.Contains(errStr, "create journalentry") {
-		return "journal_creation_error"
-	}
-	if strings.Contains(errStr, "unbalanced journal") {
-		return "journal_balance_error"
-	}
-	if strings.Contains(errStr, "access_token") || strings.Contains(errStr, "authentication") {
-		return "authentication_error"
-	}
-
-	return "unknown_error"
+	return string(apperr.Classify(err))
 }
 
-// shouldHaltProcessing determines if the reconciliation process should stop based on error conditions.
-func (s *ReconcileService) shouldHaltProcessing(report *models.ReconciliationReport, errorType string) bool {
+// shouldHaltProcessing determines if the reconciliation process should stop
+// based on error conditions and the store's policy.
+func shouldHaltProcessing(policy *models.ReconciliationConfig, report *models.ReconciliationReport, errorType string) bool {
 	// Check for critical error types
-	for _, criticalType := range s.config.CriticalErrorTypes {
+	for _, criticalType := range policy.CriticalErrorTypes {
 		if errorType == criticalType {
 			return true
 		}
 	}
 
 	// Check failure count threshold
-	if s.config.MaxAllowedFailures > 0 && report.FailedTransactions >= s.config.MaxAllowedFailures {
+	if policy.MaxAllowedFailures > 0 && report.FailedTransactions >= policy.MaxAllowedFailures {
 		return true
 	}
 
@@ -746,7 +789,7 @@ func (s *ReconcileService) shouldHaltProcessing(report *models.ReconciliationRep
 		processed := report.SuccessfulTransactions + report.FailedTransactions
 		if processed > 0 {
 			currentFailureRate := float64(report.FailedTransactions) / float64(processed) * 100
-			if currentFailureRate > s.config.FailureThresholdPercent {
+			if currentFailureRate > policy.FailureThresholdPercent {
 				return true
 			}
 		}
@@ -759,7 +802,9 @@ func (s *ReconcileService) shouldHaltProcessing(report *models.ReconciliationRep
 // its status accordingly. This method checks if an order is complete by looking
 // for escrow settlement journals or existing completion status.
 func (s *ReconcileService) CheckAndMarkComplete(ctx context.Context, kodePesanan string) error {
-	log.Printf("CheckAndMarkComplete: %s", kodePesanan)
+	reconcileLog.Debug(ctx, "CheckAndMarkComplete", "Checking purchase completion", map[string]interface{}{
+		"kode_pesanan": kodePesanan,
+	})
 	var tx *sqlx.Tx
 	dropRepo := s.dropRepo
 	if s.db != nil {
@@ -778,8 +823,10 @@ func (s *ReconcileService) CheckAndMarkComplete(ctx context.Context, kodePesanan
 	}
 
 	// If already marked as complete, no need to check further
-	if dp.StatusPesananTerakhir == "Pesanan selesai" {
-		log.Printf("CheckAndMarkComplete: %s already marked as complete", kodePesanan)
+	if dp.LifecycleStatus == lifecycle.Settled {
+		reconcileLog.Debug(ctx, "CheckAndMarkComplete", "Purchase already complete", map[string]interface{}{
+			"kode_pesanan": kodePesanan,
+		})
 		return nil
 	}
 
@@ -790,7 +837,9 @@ func (s *ReconcileService) CheckAndMarkComplete(ctx context.Context, kodePesanan
 		return fmt.Errorf("order not yet settled - no escrow settlement journal found")
 	}
 
-	if err := dropRepo.UpdatePurchaseStatus(ctx, kodePesanan, "Pesanan selesai"); err != nil {
+	if err := dropRepo.TransitionPurchaseStatus(ctx, kodePesanan, lifecycle.Change{
+		To: lifecycle.Settled, Cause: lifecycle.CauseCompletionCheck,
+	}); err != nil {
 		return fmt.Errorf("update status: %w", err)
 	}
 	if tx != nil {
@@ -798,7 +847,9 @@ func (s *ReconcileService) CheckAndMarkComplete(ctx context.Context, kodePesanan
 			return err
 		}
 	}
-	log.Printf("CheckAndMarkComplete done: %s", kodePesanan)
+	reconcileLog.Info(ctx, "CheckAndMarkComplete", "Purchase marked complete", map[string]interface{}{
+		"kode_pesanan": kodePesanan,
+	})
 	return nil
 }
 
@@ -809,7 +860,9 @@ func (s *ReconcileService) hasEscrowSettlement(ctx context.Context, invoice stri
 	}); ok {
 		exists, err := journalRepo.ExistsBySourceTypeAndID(ctx, "shopee_escrow", invoice)
 		if err != nil {
-			log.Printf("check escrow journal for %s: %v", invoice, err)
+			reconcileLog.Error(ctx, "hasEscrowSettlement", "Failed to check escrow journal", err, map[string]interface{}{
+				"invoice": invoice,
+			})
 			return false
 		}
 		return exists
@@ -820,13 +873,20 @@ func (s *ReconcileService) hasEscrowSettlement(ctx context.Context, invoice stri
 // CancelPurchase reverses pending sales journals for the given purchase except
 // for the Biaya Mitra amount which remains recorded.
 func (s *ReconcileService) CancelPurchase(ctx context.Context, kodePesanan string) error {
-	return s.CancelPurchaseAt(ctx, kodePesanan, time.Now(), "Pesanan dibatalkan")
+	return s.CancelPurchaseAt(ctx, kodePesanan, time.Now(), lifecycle.CauseManualCancel)
 }
 
+// shopeeCancelledLabel is the display status of orders cancelled on Shopee;
+// the cancellation summary counts purchases with this label.
+const shopeeCancelledLabel = "Cancelled Shopee"
+
 // CancelPurchaseAt performs the cancel journal reversal at the given entry date
-// and updates the purchase status to the provided status string.
-func (s *ReconcileService) CancelPurchaseAt(ctx context.Context, kodePesanan string, entryDate time.Time, status string) error {
-	log.Printf("CancelPurchase started: %s", kodePesanan)
+// and moves the purchase to the cancelled state. Purchases that are already
+// cancelled are left untouched so the reversal is never posted twice.
+func (s *ReconcileService) CancelPurchaseAt(ctx context.Context, kodePesanan string, entryDate time.Time, cause lifecycle.Cause) error {
+	reconcileLog.Debug(ctx, "CancelPurchaseAt", "Cancelling purchase", map[string]interface{}{
+		"kode_pesanan": kodePesanan,
+	})
 	var tx *sqlx.Tx
 	dropRepo := s.dropRepo
 	jrRepo := s.journalRepo
@@ -845,6 +905,20 @@ func (s *ReconcileService) CancelPurchaseAt(ctx context.Context, kodePesanan str
 	if err != nil || dp == nil {
 		return fmt.Errorf("fetch DropshipPurchase %s: %w", kodePesanan, err)
 	}
+	if dp.LifecycleStatus == lifecycle.Cancelled {
+		reconcileLog.Debug(ctx, "CancelPurchaseAt", "Purchase already cancelled", map[string]interface{}{
+			"kode_pesanan": kodePesanan,
+		})
+		return nil
+	}
+
+	change := lifecycle.Change{To: lifecycle.Cancelled, Cause: cause, At: entryDate}
+	if cause == lifecycle.CauseShopeeCancelled {
+		change.Label = shopeeCancelledLabel
+	}
+	if err := dropRepo.TransitionPurchaseStatus(ctx, kodePesanan, change); err != nil {
+		return fmt.Errorf("update status: %w", err)
+	}
 
 	prodCh, _ := dropRepo.SumDetailByInvoice(ctx, dp.KodeInvoiceChannel)
 	prod, _ := dropRepo.SumProductCostByInvoice(ctx, dp.KodeInvoiceChannel)
@@ -882,15 +956,15 @@ func (s *ReconcileService) CancelPurchaseAt(ctx context.Context, kodePesanan str
 		}
 	}
 
-	if err := dropRepo.UpdatePurchaseStatus(ctx, kodePesanan, status); err != nil {
-		return fmt.Errorf("update status: %w", err)
-	}
 	if tx != nil {
 		if err := tx.Commit(); err != nil {
 			return err
 		}
 	}
-	log.Printf("CancelPurchase completed: %s", kodePesanan)
+	invalidateCache(ctx, s.cache, reconcileWriteTags...)
+	reconcileLog.Info(ctx, "CancelPurchaseAt", "Purchase cancelled", map[string]interface{}{
+		"kode_pesanan": kodePesanan,
+	})
 	return nil
 }
 
@@ -917,7 +991,7 @@ func (s *ReconcileService) GetShopeeOrderStatus(ctx context.Context, invoice str
 
 				// Make API call with validated token
 				status, err := s.client.GetOrderDetail(ctx, invoice)
-				if err != nil && strings.Contains(err.Error(), "invalid_access_token") {
+				if errors.Is(err, apperr.ErrTokenExpired) {
 					// Fallback: refresh token and retry
 					if refreshErr := s.ensureStoreTokenValid(ctx, st); refreshErr == nil {
 						s.client.AccessToken = *st.AccessToken
@@ -931,7 +1005,9 @@ func (s *ReconcileService) GetShopeeOrderStatus(ctx context.Context, invoice str
 	}
 
 	// Fallback: call without token validation if we can't get store info
-	log.Printf("Warning: calling GetOrderDetail without token validation for invoice %s", invoice)
+	reconcileLog.Warn(ctx, "GetShopeeOrderStatus", "Fetching order detail without token validation", map[string]interface{}{
+		"invoice": invoice,
+	})
 	return s.client.GetOrderDetail(ctx, invoice)
 }
 
@@ -949,7 +1025,7 @@ func (s *ReconcileService) GetShopeeOrderDetail(ctx context.Context, invoice str
 		return nil, fmt.Errorf("fetch store %s: %w", dp.NamaToko, err)
 	}
 	if st.AccessToken == nil || st.ShopID == nil {
-		return nil, fmt.Errorf("missing access token or shop id")
+		return nil, fmt.Errorf("%w: access token or shop id", apperr.ErrMissingCredentials)
 	}
 	if s.client == nil {
 		return nil, fmt.Errorf("shopee client not configured")
@@ -960,7 +1036,7 @@ func (s *ReconcileService) GetShopeeOrderDetail(ctx context.Context, invoice str
 	}
 
 	detail, err := s.client.FetchShopeeOrderDetail(ctx, *st.AccessToken, *st.ShopID, dp.KodeInvoiceChannel)
-	if err != nil && strings.Contains(err.Error(), "invalid_access_token") {
+	if errors.Is(err, apperr.ErrTokenExpired) {
 		if err := s.ensureStoreTokenValid(ctx, st); err != nil {
 			return nil, err
 		}
@@ -969,7 +1045,9 @@ func (s *ReconcileService) GetShopeeOrderDetail(ctx context.Context, invoice str
 	if err == nil && s.detailRepo != nil {
 		row, items, packages := normalizeOrderDetail(invoice, dp.NamaToko, *detail)
 		if err := s.detailRepo.SaveOrderDetail(ctx, row, items, packages); err != nil {
-			log.Printf("save order detail %s: %v", invoice, err)
+			reconcileLog.Error(ctx, "GetShopeeOrderDetail", "Failed to save order detail", err, map[string]interface{}{
+				"invoice": invoice,
+			})
 		}
 	}
 	return detail, err
@@ -990,7 +1068,7 @@ func (s *ReconcileService) GetShopeeEscrowDetail(ctx context.Context, invoice st
 		return nil, fmt.Errorf("fetch store %s: %w", dp.NamaToko, err)
 	}
 	if st.AccessToken == nil || st.ShopID == nil {
-		return nil, fmt.Errorf("missing access token or shop id")
+		return nil, fmt.Errorf("%w: access token or shop id", apperr.ErrMissingCredentials)
 	}
 	if s.client == nil {
 		return nil, fmt.Errorf("shopee client not configured")
@@ -1001,19 +1079,25 @@ func (s *ReconcileService) GetShopeeEscrowDetail(ctx context.Context, invoice st
 	}
 
 	detail, err := s.client.GetEscrowDetail(ctx, *st.AccessToken, *st.ShopID, dp.KodeInvoiceChannel)
-	if err != nil && strings.Contains(err.Error(), "invalid_access_token") {
+	if errors.Is(err, apperr.ErrTokenExpired) {
 		if err := s.ensureStoreTokenValid(ctx, st); err != nil {
 			return nil, err
 		}
 		detail, err = s.client.GetEscrowDetail(ctx, *st.AccessToken, *st.ShopID, dp.KodeInvoiceChannel)
 	}
-	log.Printf("GetShopeeEscrowDetail: detail: %+v, err: %v", detail, err)
+	reconcileLog.Debug(ctx, "GetShopeeEscrowDetail", "Escrow detail fetched", map[string]interface{}{
+		"invoice": invoice,
+		"detail":  detail,
+		"error":   err,
+	})
 	return detail, err
 }
 
 // GetShopeeOrderDetailCached returns cached order detail from database, or queues a background job if not available
 func (s *ReconcileService) GetShopeeOrderDetailCached(ctx context.Context, invoice string) (*ShopeeOrderDetail, *int64, error) {
-	log.Printf("GetShopeeOrderDetailCached: invoice=%s", invoice)
+	reconcileLog.Debug(ctx, "GetShopeeOrderDetailCached", "Looking up cached order detail", map[string]interface{}{
+		"invoice": invoice,
+	})
 
 	// First, try to get from cache (database)
 	if s.detailRepo != nil {
@@ -1024,18 +1108,24 @@ func (s *ReconcileService) GetShopeeOrderDetailCached(ctx context.Context, invoi
 			if err == nil && detail != nil {
 				// Convert back to ShopeeOrderDetail format
 				shopeeDetail := s.convertDatabaseToShopeeDetail(detail, items, packages)
-				log.Printf("GetShopeeOrderDetailCached: found cached data for %s", invoice)
+				reconcileLog.Debug(ctx, "GetShopeeOrderDetailCached", "Cached order detail found", map[string]interface{}{
+					"invoice": invoice,
+				})
 				return &shopeeDetail, nil, nil
 			}
 		}
 	}
 
 	// Data not in cache, queue background job
-	log.Printf("GetShopeeOrderDetailCached: no cached data for %s, queueing background job", invoice)
+	reconcileLog.Debug(ctx, "GetShopeeOrderDetailCached", "No cached order detail, queueing background job", map[string]interface{}{
+		"invoice": invoice,
+	})
 	if s.backgroundSvc != nil {
 		batchID, err := s.backgroundSvc.QueueOrderDetailFetch(ctx, invoice)
 		if err != nil {
-			log.Printf("GetShopeeOrderDetailCached: failed to queue background job: %v", err)
+			reconcileLog.Error(ctx, "GetShopeeOrderDetailCached", "Failed to queue background job", err, map[string]interface{}{
+				"invoice": invoice,
+			})
 			// Fall back to immediate fetch if queueing fails
 			detail, err := s.GetShopeeOrderDetail(ctx, invoice)
 			return detail, nil, err
@@ -1050,7 +1140,9 @@ func (s *ReconcileService) GetShopeeOrderDetailCached(ctx context.Context, invoi
 
 // GetShopeeEscrowDetailCached returns cached escrow detail, with fallback to immediate fetch
 func (s *ReconcileService) GetShopeeEscrowDetailCached(ctx context.Context, invoice string) (*ShopeeEscrowDetail, error) {
-	log.Printf("GetShopeeEscrowDetailCached: invoice=%s", invoice)
+	reconcileLog.Debug(ctx, "GetShopeeEscrowDetailCached", "Looking up cached escrow detail", map[string]interface{}{
+		"invoice": invoice,
+	})
 
 	// For escrow details, we don't cache them as they change frequently
 	// But we can still make this non-blocking by using the background service pattern if needed
@@ -1145,7 +1237,9 @@ func (s *ReconcileService) GetBackgroundJobStatus(ctx context.Context, batchID i
 }
 
 func (s *ReconcileService) ensureStoreTokenValid(ctx context.Context, st *models.Store) error {
-	log.Printf("ensureStoreTokenValid store=%d", st.StoreID)
+	reconcileLog.Debug(ctx, "ensureStoreTokenValid", "Checking store token", map[string]interface{}{
+		"store_id": st.StoreID,
+	})
 	// New location (e.g., Asia/Jakarta)
 	loc, _ := time.LoadLocation("Asia/Jakarta")
 
@@ -1157,21 +1251,30 @@ func (s *ReconcileService) ensureStoreTokenValid(ctx context.Context, st *models
 	)
 	exp := reinterpreted.Add(time.Duration(*st.ExpireIn) * time.Second)
 	if st.RefreshToken == nil {
-		log.Fatalf("ensureStoreTokenValid: missing refresh token for store %d", st.StoreID)
-		return fmt.Errorf("missing refresh token")
+		reconcileLog.Error(ctx, "ensureStoreTokenValid", "Store has no refresh token", apperr.ErrMissingCredentials, map[string]interface{}{
+			"store_id": st.StoreID,
+		})
+		return fmt.Errorf("%w: refresh token", apperr.ErrMissingCredentials)
 	}
 	if st.ShopID == nil || *st.ShopID == "" {
-		log.Fatalf("ensureStoreTokenValid: missing shop id for store %d", st.StoreID)
-		return fmt.Errorf("missing shop id")
+		reconcileLog.Error(ctx, "ensureStoreTokenValid", "Store has no shop id", apperr.ErrMissingCredentials, map[string]interface{}{
+			"store_id": st.StoreID,
+		})
+		return fmt.Errorf("%w: shop id", apperr.ErrMissingCredentials)
 	}
 	if st.ExpireIn != nil && st.LastUpdated != nil {
 		if time.Now().Before(exp.Local()) {
-			log.Printf("Token for store %d is still valid until %v and current time is %v", st.StoreID, exp, time.Now())
-			log.Printf("current time: %v", time.Now())
+			reconcileLog.Debug(ctx, "ensureStoreTokenValid", "Store token still valid", map[string]interface{}{
+				"store_id":   st.StoreID,
+				"expires_at": exp,
+			})
 			return nil
 		}
 	}
-	log.Printf("exp: %v", exp)
+	reconcileLog.Info(ctx, "ensureStoreTokenValid", "Store token expired, refreshing", map[string]interface{}{
+		"store_id":   st.StoreID,
+		"expired_at": exp,
+	})
 	s.client.ShopID = *st.ShopID
 	s.client.RefreshToken = *st.RefreshToken
 	resp, err := s.client.RefreshAccessToken(ctx)
@@ -1187,7 +1290,9 @@ func (s *ReconcileService) ensureStoreTokenValid(ctx context.Context, st *models
 	now := time.Now()
 	st.LastUpdated = &now
 	if uerr := s.storeRepo.UpdateStore(ctx, st); uerr != nil {
-		log.Printf("update store token: %v", uerr)
+		reconcileLog.Error(ctx, "ensureStoreTokenValid", "Failed to save refreshed token", uerr, map[string]interface{}{
+			"store_id": st.StoreID,
+		})
 	}
 	return nil
 }
@@ -1253,6 +1358,7 @@ func (s *ReconcileService) UpdateShopeeStatus(ctx context.Context, invoice strin
 		if err := s.createEscrowSettlementJournal(ctx, invoice, statusStr, updateTime, nil); err != nil {
 			return err
 		}
+		invalidateCache(ctx, s.cache, reconcileWriteTags...)
 		return nil
 	}
 
@@ -1260,7 +1366,9 @@ func (s *ReconcileService) UpdateShopeeStatus(ctx context.Context, invoice strin
 	if status == "returned" || status == "partial_return" || strings.Contains(strings.ToLower(statusStr), "return") {
 		// Check if return journal already exists to avoid duplicates
 		if s.HasReturnJournal(ctx, invoice) {
-			log.Printf("Return journal already exists for %s, skipping", invoice)
+			reconcileLog.Debug(ctx, "UpdateShopeeStatus", "Return journal already exists, skipping", map[string]interface{}{
+				"invoice": invoice,
+			})
 			return nil
 		}
 
@@ -1306,6 +1414,7 @@ func (s *ReconcileService) UpdateShopeeStatus(ctx context.Context, invoice strin
 		if err := s.createReturnedOrderJournal(ctx, invoice, statusStr, updateTime, escDetail, isPartialReturn, returnAmount); err != nil {
 			return err
 		}
+		invalidateCache(ctx, s.cache, reconcileWriteTags...)
 		return nil
 	}
 
@@ -1316,13 +1425,15 @@ func (s *ReconcileService) UpdateShopeeStatus(ctx context.Context, invoice strin
 	if err != nil || dp == nil {
 		return fmt.Errorf("fetch purchase %s: %w", invoice, err)
 	}
-	return s.CancelPurchaseAt(ctx, dp.KodePesanan, updateTime, "Cancelled Shopee")
+	return s.CancelPurchaseAt(ctx, dp.KodePesanan, updateTime, lifecycle.CauseShopeeCancelled)
 }
 
 // createEscrowSettlementJournal posts journal entries based on escrow detail and
 // marks the purchase as complete.
 func (s *ReconcileService) createEscrowSettlementJournal(ctx context.Context, invoice, status string, updateTime time.Time, escDetail *ShopeeEscrowDetail) error {
-	log.Printf("createEscrowSettlementJournal %s", invoice)
+	reconcileLog.Debug(ctx, "createEscrowSettlementJournal", "Creating escrow settlement journal", map[string]interface{}{
+		"invoice": invoice,
+	})
 
 	var tx *sqlx.Tx
 	dropRepo := s.dropRepo
@@ -1338,11 +1449,14 @@ func (s *ReconcileService) createEscrowSettlementJournal(ctx context.Context, in
 		jrRepo = repository.NewJournalRepo(tx)
 	}
 
-	log.Printf("Fetching DropshipPurchase for invoice %s", invoice)
+	reconcileLog.Debug(ctx, "createEscrowSettlementJournal", "Fetching purchase", map[string]interface{}{
+		"invoice": invoice,
+	})
 	dp, err := dropRepo.GetDropshipPurchaseByInvoice(ctx, invoice)
 	if err != nil || dp == nil {
 		return fmt.Errorf("fetch purchase %s: %w", invoice, err)
 	}
+	policy := s.policyFor(ctx, dp.NamaToko)
 
 	if escDetail == nil {
 		escDetail, err = s.GetShopeeEscrowDetail(ctx, invoice)
@@ -1425,7 +1539,7 @@ func (s *ReconcileService) createEscrowSettlementJournal(ctx context.Context, in
 	diff := actShip - buyerShip - shopeeRebate - shipDisc
 
 	// Track shipping discrepancy if it exists
-	if s.shipDiscRepo != nil && math.Abs(diff) > 0.01 {
+	if s.shipDiscRepo != nil && money.FromFloat(diff).Abs() > policy.ShippingDiscrepancyThreshold {
 		orderDate, _ := time.Parse(time.RFC3339, dp.WaktuPesananTerbuat.Format(time.RFC3339))
 		// Try to get order_sn from escrow detail
 		var orderSN *string
@@ -1445,7 +1559,9 @@ func (s *ReconcileService) createEscrowSettlementJournal(ctx context.Context, in
 			StoreName:              &dp.NamaToko,
 		}
 		if err := s.shipDiscRepo.InsertShippingDiscrepancy(ctx, discrepancy); err != nil {
-			log.Printf("Failed to insert shipping discrepancy for %s: %v", invoice, err)
+			reconcileLog.Error(ctx, "createEscrowSettlementJournal", "Failed to insert shipping discrepancy", err, map[string]interface{}{
+				"invoice": invoice,
+			})
 		}
 	}
 
@@ -1471,7 +1587,9 @@ func (s *ReconcileService) createEscrowSettlementJournal(ctx context.Context, in
 					StoreName:          &dp.NamaToko,
 				}
 				if err := s.shipDiscRepo.InsertShippingDiscrepancy(ctx, discrepancy); err != nil {
-					log.Printf("Failed to insert reverse shipping fee discrepancy for %s: %v", invoice, err)
+					reconcileLog.Error(ctx, "createEscrowSettlementJournal", "Failed to insert reverse shipping fee discrepancy", err, map[string]interface{}{
+						"invoice": invoice,
+					})
 				}
 			}
 		}
@@ -1487,14 +1605,46 @@ func (s *ReconcileService) createEscrowSettlementJournal(ctx context.Context, in
 
 	// escrowAmt = escrowAmt - shipDisc
 
-	debitTotal := commission + service + voucher + discount + shipDisc + affiliate + diff + escrowAmt
-	if !logistikCase && math.Abs(debitTotal-orderPrice) > 0.01 {
-		log.Printf("unbalanced journal for %s: debit %.2f credit %.2f", invoice, debitTotal, orderPrice)
-		log.Printf("  commission: %.2f, service: %.2f, voucher: %.2f, discount: %.2f, shipDisc: %.2f, affiliate: %.2f, escrowAmt: %.2f, diff: %.2f",
-			commission, service, voucher, discount, shipDisc, affiliate, escrowAmt, diff)
-		log.Printf("  actShip: %.2f, buyerShip: %.2f, rebate: %.2f", actShip, buyerShip, shopeeRebate)
-		log.Printf("  orderPrice: %.2f", orderPrice)
-		return fmt.Errorf("unbalanced journal: debit %.2f credit %.2f", debitTotal, orderPrice)
+	// The escrow API reports floats; post exact amounts from here on.
+	orderAmt := money.FromFloat(orderPrice)
+	commissionAmt := money.FromFloat(commission)
+	serviceAmt := money.FromFloat(service)
+	voucherAmt := money.FromFloat(voucher)
+	discountAmt := money.FromFloat(discount)
+	shipDiscAmt := money.FromFloat(shipDisc)
+	affiliateAmt := money.FromFloat(affiliate)
+	escrowAmount := money.FromFloat(escrowAmt)
+	diffAmt := money.FromFloat(diff)
+
+	debitTotal := commissionAmt + serviceAmt + voucherAmt + discountAmt + shipDiscAmt + affiliateAmt + diffAmt + escrowAmount
+	// Differences within the store's tolerance are booked as selisih ongkir.
+	if gap := orderAmt - debitTotal; !logistikCase && gap != 0 && gap.Abs() <= policy.AmountTolerance {
+		reconcileLog.Info(ctx, "createEscrowSettlementJournal", "Escrow gap within tolerance", map[string]interface{}{
+			"invoice":   invoice,
+			"gap":       gap,
+			"tolerance": policy.AmountTolerance,
+		})
+		diffAmt += gap
+		debitTotal = orderAmt
+	}
+	if !logistikCase && debitTotal != orderAmt {
+		reconcileLog.Error(ctx, "createEscrowSettlementJournal", "Escrow journal is unbalanced", apperr.ErrUnbalancedJournal, map[string]interface{}{
+			"invoice":         invoice,
+			"debit":           debitTotal,
+			"credit":          orderAmt,
+			"commission":      commissionAmt,
+			"service":         serviceAmt,
+			"voucher":         voucherAmt,
+			"discount":        discountAmt,
+			"ship_discount":   shipDiscAmt,
+			"affiliate":       affiliateAmt,
+			"escrow":          escrowAmount,
+			"diff":            diffAmt,
+			"actual_shipping": actShip,
+			"buyer_shipping":  buyerShip,
+			"rebate":          shopeeRebate,
+		})
+		return fmt.Errorf("%w: debit %s credit %s", apperr.ErrUnbalancedJournal, debitTotal, orderAmt)
 	}
 
 	je := &models.JournalEntry{
@@ -1513,34 +1663,33 @@ func (s *ReconcileService) createEscrowSettlementJournal(ctx context.Context, in
 	var lines []models.JournalLine
 	if logistikCase {
 		lines = []models.JournalLine{
-			{JournalID: jid, AccountID: pendingAccountID(dp.NamaToko), IsDebit: false, Amount: escrowAmt, Memo: ptrString("Pending " + invoice)},
-			{JournalID: jid, AccountID: saldoShopeeAccountID(dp.NamaToko), IsDebit: true, Amount: escrowAmt, Memo: ptrString("Saldo Shopee " + invoice)},
+			{JournalID: jid, AccountID: pendingAccountID(dp.NamaToko), IsDebit: false, Amount: escrowAmount, Memo: ptrString("Pending " + invoice)},
+			{JournalID: jid, AccountID: saldoShopeeAccountID(dp.NamaToko), IsDebit: true, Amount: escrowAmount, Memo: ptrString("Saldo Shopee " + invoice)},
 		}
 	} else {
 		lines = []models.JournalLine{
-			{JournalID: jid, AccountID: pendingAccountID(dp.NamaToko), IsDebit: false, Amount: orderPrice, Memo: ptrString("Pending " + invoice)},
-			{JournalID: jid, AccountID: 52006, IsDebit: true, Amount: commission, Memo: ptrString("Biaya Administrasi " + invoice)},
-			{JournalID: jid, AccountID: 52004, IsDebit: true, Amount: service, Memo: ptrString("Biaya Layanan " + invoice)},
-			{JournalID: jid, AccountID: 55001, IsDebit: true, Amount: voucher, Memo: ptrString("Voucher " + invoice)},
-			{JournalID: jid, AccountID: 55004, IsDebit: true, Amount: discount, Memo: ptrString("Discount " + invoice)},
-			{JournalID: jid, AccountID: 55006, IsDebit: true, Amount: shipDisc, Memo: ptrString("Diskon Ongkir " + invoice)},
-			{JournalID: jid, AccountID: 55002, IsDebit: true, Amount: affiliate, Memo: ptrString("Biaya Affiliate " + invoice)},
-			{JournalID: jid, AccountID: saldoShopeeAccountID(dp.NamaToko), IsDebit: true, Amount: escrowAmt, Memo: ptrString("Saldo Shopee " + invoice)},
-		}
-	}
-	if diff < 0 {
-		aamt := -diff
+			{JournalID: jid, AccountID: pendingAccountID(dp.NamaToko), IsDebit: false, Amount: orderAmt, Memo: ptrString("Pending " + invoice)},
+			{JournalID: jid, AccountID: 52006, IsDebit: true, Amount: commissionAmt, Memo: ptrString("Biaya Administrasi " + invoice)},
+			{JournalID: jid, AccountID: 52004, IsDebit: true, Amount: serviceAmt, Memo: ptrString("Biaya Layanan " + invoice)},
+			{JournalID: jid, AccountID: 55001, IsDebit: true, Amount: voucherAmt, Memo: ptrString("Voucher " + invoice)},
+			{JournalID: jid, AccountID: 55004, IsDebit: true, Amount: discountAmt, Memo: ptrString("Discount " + invoice)},
+			{JournalID: jid, AccountID: 55006, IsDebit: true, Amount: shipDiscAmt, Memo: ptrString("Diskon Ongkir " + invoice)},
+			{JournalID: jid, AccountID: 55002, IsDebit: true, Amount: affiliateAmt, Memo: ptrString("Biaya Affiliate " + invoice)},
+			{JournalID: jid, AccountID: saldoShopeeAccountID(dp.NamaToko), IsDebit: true, Amount: escrowAmount, Memo: ptrString("Saldo Shopee " + invoice)},
+		}
+	}
+	if diffAmt < 0 {
+		aamt := -diffAmt
 		lines = append(lines,
 			models.JournalLine{JournalID: jid, AccountID: 4001, IsDebit: false, Amount: aamt, Memo: ptrString("Selisih Ongkir Lebih" + invoice)},
 		)
-	} else if diff > 0 {
+	} else if diffAmt > 0 {
 		lines = append(lines,
-			models.JournalLine{JournalID: jid, AccountID: 52010, IsDebit: true, Amount: diff, Memo: ptrString("Selisih Ongkir Kurang" + invoice)},
+			models.JournalLine{JournalID: jid, AccountID: 52010, IsDebit: true, Amount: diffAmt, Memo: ptrString("Selisih Ongkir Kurang" + invoice)},
 		)
 	}
 	// Calculate total debits and credits to ensure the journal is balanced
-	totalDebits := 0.0
-	totalCredits := 0.0
+	var totalDebits, totalCredits money.Amount
 	for _, line := range lines {
 		if line.Amount == 0 {
 			continue
@@ -1553,8 +1702,12 @@ func (s *ReconcileService) createEscrowSettlementJournal(ctx context.Context, in
 	}
 
 	// Check if journal is balanced
-	if math.Abs(totalDebits-totalCredits) > 0.01 {
-		log.Printf("unbalanced escrow settlement journal for %s: debits %.2f != credits %.2f", invoice, totalDebits, totalCredits)
+	if totalDebits != totalCredits {
+		reconcileLog.Error(ctx, "createEscrowSettlementJournal", "Escrow settlement journal is unbalanced", apperr.ErrUnbalancedJournal, map[string]interface{}{
+			"invoice": invoice,
+			"debit":   totalDebits,
+			"credit":  totalCredits,
+		})
 		for i, line := range lines {
 			if line.Amount == 0 {
 				continue
@@ -1563,12 +1716,18 @@ func (s *ReconcileService) createEscrowSettlementJournal(ctx context.Context, in
 			if line.IsDebit {
 				debitStr = "debit"
 			}
-			log.Printf("  line %d: account %d, %s %.2f", i, line.AccountID, debitStr, line.Amount)
+			reconcileLog.Debug(ctx, "createEscrowSettlementJournal", "Unbalanced journal line", map[string]interface{}{
+				"invoice":    invoice,
+				"line":       i,
+				"account_id": line.AccountID,
+				"side":       debitStr,
+				"amount":     line.Amount,
+			})
 		}
 		if tx != nil {
 			tx.Rollback()
 		}
-		return fmt.Errorf("unbalanced journal entries: debits %.2f != credits %.2f", totalDebits, totalCredits)
+		return fmt.Errorf("%w: debits %s != credits %s", apperr.ErrUnbalancedJournal, totalDebits, totalCredits)
 	}
 
 	// Filter out lines with zero amounts and use bulk insert
@@ -1583,13 +1742,13 @@ func (s *ReconcileService) createEscrowSettlementJournal(ctx context.Context, in
 			return err
 		}
 	}
-	if math.Abs(diff) > 0.01 && s.adjRepo != nil {
+	if !diffAmt.IsZero() && s.adjRepo != nil {
 		adj := &models.ShopeeAdjustment{
 			NamaToko:           dp.NamaToko,
 			TanggalPenyesuaian: updateTime,
 			TipePenyesuaian:    "Shipping Fee Discrepancy",
 			AlasanPenyesuaian:  "Auto from escrow",
-			BiayaPenyesuaian:   diff,
+			BiayaPenyesuaian:   diffAmt,
 			NoPesanan:          invoice,
 			CreatedAt:          time.Now(),
 		}
@@ -1607,11 +1766,15 @@ func (s *ReconcileService) createEscrowSettlementJournal(ctx context.Context, in
 
 	if s.detailRepo != nil {
 		if err := s.detailRepo.UpdateOrderDetailStatus(ctx, dp.KodeInvoiceChannel, status, status, updateTime); err != nil {
-			log.Printf("update order detail %s: %v", invoice, err)
+			reconcileLog.Error(ctx, "createEscrowSettlementJournal", "Failed to update order detail status", err, map[string]interface{}{
+				"invoice": invoice,
+			})
 		}
 	}
 
-	if err := dropRepo.UpdatePurchaseStatus(ctx, dp.KodePesanan, "Pesanan selesai"); err != nil {
+	if err := dropRepo.TransitionPurchaseStatus(ctx, dp.KodePesanan, lifecycle.Change{
+		To: lifecycle.Settled, Cause: lifecycle.CauseEscrowSettlement, At: updateTime,
+	}); err != nil {
 		return fmt.Errorf("update status: %w", err)
 	}
 	if tx != nil {
@@ -1625,7 +1788,11 @@ func (s *ReconcileService) createEscrowSettlementJournal(ctx context.Context, in
 // createReturnedOrderJournal handles journal entries for returned orders in escrow settlements.
 // It reverses the original escrow settlement and records appropriate refund entries.
 func (s *ReconcileService) createReturnedOrderJournal(ctx context.Context, invoice, status string, updateTime time.Time, escDetail *ShopeeEscrowDetail, isPartialReturn bool, returnAmount float64) error {
-	log.Printf("createReturnedOrderJournal %s (partial: %t, amount: %.2f)", invoice, isPartialReturn, returnAmount)
+	reconcileLog.Debug(ctx, "createReturnedOrderJournal", "Creating returned order journal", map[string]interface{}{
+		"invoice": invoice,
+		"partial": isPartialReturn,
+		"amount":  returnAmount,
+	})
 
 	var tx *sqlx.Tx
 	dropRepo := s.dropRepo
@@ -1641,7 +1808,9 @@ func (s *ReconcileService) createReturnedOrderJournal(ctx context.Context, invoi
 		jrRepo = repository.NewJournalRepo(tx)
 	}
 
-	log.Printf("Fetching DropshipPurchase for returned invoice %s", invoice)
+	reconcileLog.Debug(ctx, "createReturnedOrderJournal", "Fetching purchase", map[string]interface{}{
+		"invoice": invoice,
+	})
 	dp, err := dropRepo.GetDropshipPurchaseByInvoice(ctx, invoice)
 	if err != nil || dp == nil {
 		return fmt.Errorf("fetch purchase %s: %w", invoice, err)
@@ -1714,19 +1883,19 @@ func (s *ReconcileService) createReturnedOrderJournal(ctx context.Context, invoi
 	}
 
 	// Scale amounts proportionally for partial returns
-	returnCommission := commission * returnProportion
-	returnService := service * returnProportion
-	returnVoucher := voucher * returnProportion
-	returnDiscount := discount * returnProportion
-	returnShipDisc := shipDisc * returnProportion
-	returnAffiliate := affiliate * returnProportion
-	actualReturnAmount := orderPrice * returnProportion
+	returnCommission := money.FromFloat(commission * returnProportion)
+	returnService := money.FromFloat(service * returnProportion)
+	returnVoucher := money.FromFloat(voucher * returnProportion)
+	returnDiscount := money.FromFloat(discount * returnProportion)
+	returnShipDisc := money.FromFloat(shipDisc * returnProportion)
+	returnAffiliate := money.FromFloat(affiliate * returnProportion)
+	actualReturnAmount := money.FromFloat(orderPrice * returnProportion)
 
 	je := &models.JournalEntry{
 		EntryDate: updateTime,
 		Description: ptrString(fmt.Sprintf("Shopee return %s%s", invoice, func() string {
 			if isPartialReturn {
-				return fmt.Sprintf(" (partial %.2f)", actualReturnAmount)
+				return fmt.Sprintf(" (partial %s)", actualReturnAmount)
 			}
 			return ""
 		}())),
@@ -1781,16 +1950,20 @@ func (s *ReconcileService) createReturnedOrderJournal(ctx context.Context, invoi
 			returnStatus = "partial_return"
 		}
 		if err := s.detailRepo.UpdateOrderDetailStatus(ctx, dp.KodeInvoiceChannel, returnStatus, returnStatus, updateTime); err != nil {
-			log.Printf("update order detail status %s: %v", invoice, err)
+			reconcileLog.Error(ctx, "createReturnedOrderJournal", "Failed to update order detail status", err, map[string]interface{}{
+				"invoice": invoice,
+			})
 		}
 	}
 
 	// Update purchase status
-	purchaseStatus := "Pesanan dikembalikan"
+	purchaseStatus := lifecycle.Returned
 	if isPartialReturn {
-		purchaseStatus = "Sebagian dikembalikan"
+		purchaseStatus = lifecycle.PartiallyReturned
 	}
-	if err := dropRepo.UpdatePurchaseStatus(ctx, dp.KodePesanan, purchaseStatus); err != nil {
+	if err := dropRepo.TransitionPurchaseStatus(ctx, dp.KodePesanan, lifecycle.Change{
+		To: purchaseStatus, Cause: lifecycle.CauseShopeeReturn, Note: status, At: updateTime,
+	}); err != nil {
 		return fmt.Errorf("update purchase status: %w", err)
 	}
 
@@ -1800,7 +1973,9 @@ func (s *ReconcileService) createReturnedOrderJournal(ctx context.Context, invoi
 		}
 	}
 
-	log.Printf("createReturnedOrderJournal completed: %s", invoice)
+	reconcileLog.Info(ctx, "createReturnedOrderJournal", "Returned order journal created", map[string]interface{}{
+		"invoice": invoice,
+	})
 	return nil
 }
 
@@ -1814,7 +1989,9 @@ func (s *ReconcileService) HasReturnJournal(ctx context.Context, invoice string)
 	}); ok {
 		exists, err := journalRepo.ExistsBySourceTypeAndID(ctx, "shopee_return", invoice)
 		if err != nil {
-			log.Printf("check return journal for %s: %v", invoice, err)
+			reconcileLog.Error(ctx, "HasReturnJournal", "Failed to check return journal", err, map[string]interface{}{
+				"invoice": invoice,
+			})
 			return false
 		}
 		return exists
@@ -1829,14 +2006,21 @@ func (s *ReconcileService) UpdateShopeeStatuses(ctx context.Context, invoices []
 
 	start := time.Now()
 	// Optimize: Bulk fetch all DropshipPurchases instead of individual calls
-	log.Printf("UpdateShopeeStatuses: fetching %d purchases in bulk", len(invoices))
+	reconcileLog.Debug(ctx, "UpdateShopeeStatuses", "Fetching purchases", map[string]interface{}{
+		"invoices": len(invoices),
+	})
 	purchases, err := s.bulkGetDropshipPurchasesByInvoices(ctx, invoices)
 	if err != nil {
-		log.Printf("bulk fetch purchases: %v", err)
+		reconcileLog.Error(ctx, "UpdateShopeeStatuses", "Failed to fetch purchases", err, map[string]interface{}{
+			"invoices": len(invoices),
+		})
 		return err
 	}
 	fetchDuration := time.Since(start)
-	log.Printf("UpdateShopeeStatuses: bulk fetch completed in %v for %d purchases", fetchDuration, len(purchases))
+	reconcileLog.Debug(ctx, "UpdateShopeeStatuses", "Purchases fetched", map[string]interface{}{
+		"purchases":   len(purchases),
+		"duration_ms": fetchDuration.Milliseconds(),
+	})
 
 	batches := make(map[string][]*models.DropshipPurchase)
 	for _, dp := range purchases {
@@ -1853,7 +2037,9 @@ func (s *ReconcileService) UpdateShopeeStatuses(ctx context.Context, invoices []
 			var err error
 			batchID, err = s.batchSvc.Create(ctx, bh)
 			if err != nil {
-				log.Printf("create batch history %s: %v", store, err)
+				reconcileLog.Error(ctx, "UpdateShopeeStatuses", "Failed to create batch history", err, map[string]interface{}{
+					"store": store,
+				})
 			}
 		}
 		g.Go(func() error {
@@ -1865,17 +2051,20 @@ func (s *ReconcileService) UpdateShopeeStatuses(ctx context.Context, invoices []
 			return nil
 		})
 	}
-	return g.Wait()
+	err = g.Wait()
+	invalidateCache(context.WithoutCancel(ctx), s.cache, reconcileWriteTags...)
+	return err
 }
 
 func (s *ReconcileService) processShopeeStatusBatch(ctx context.Context, store string, list []*models.DropshipPurchase) {
+	ctx = logutil.WithShop(ctx, store)
 	st, err := s.storeRepo.GetStoreByName(ctx, store)
 	if err != nil || st == nil || st.AccessToken == nil || st.ShopID == nil {
-		log.Printf("fetch store %s: %v", store, err)
+		reconcileLog.Error(ctx, "processShopeeStatusBatch", "Failed to fetch store", err)
 		return
 	}
 	if err := s.ensureStoreTokenValid(ctx, st); err != nil {
-		log.Printf("ensure token %s: %v", store, err)
+		reconcileLog.Error(ctx, "processShopeeStatusBatch", "Failed to validate store token", err)
 		return
 	}
 	sns := make([]string, len(list))
@@ -1886,14 +2075,14 @@ func (s *ReconcileService) processShopeeStatusBatch(ctx context.Context, store s
 		dpMap[dp.KodePesanan] = dp
 	}
 	details, err := s.client.FetchShopeeOrderDetails(ctx, *st.AccessToken, *st.ShopID, sns)
-	if err != nil && strings.Contains(err.Error(), "invalid_access_token") {
+	if errors.Is(err, apperr.ErrTokenExpired) {
 		if e := s.ensureStoreTokenValid(ctx, st); e == nil {
 			details, err = s.client.FetchShopeeOrderDetails(ctx, *st.AccessToken, *st.ShopID, sns)
 		}
 	}
 
 	if err != nil {
-		log.Printf("batch fetch detail %s: %v", store, err)
+		reconcileLog.Error(ctx, "processShopeeStatusBatch", "Failed to fetch order details", err)
 		return
 	}
 	completed := []string{}
@@ -1952,20 +2141,34 @@ func (s *ReconcileService) processShopeeStatusBatch(ctx context.Context, store s
 		}
 
 		if status == "cancelled" {
-			if err := s.CancelPurchaseAt(ctx, dp.KodePesanan, updateTime, "Cancelled Shopee"); err != nil {
-				log.Printf("cancel purchase %s: %v", dp.KodePesanan, err)
+			if err := s.CancelPurchaseAt(ctx, dp.KodePesanan, updateTime, lifecycle.CauseShopeeCancelled); err != nil {
+				reconcileLog.Error(ctx, "processShopeeStatusBatch", "Failed to cancel purchase", err, map[string]interface{}{
+					"kode_pesanan": dp.KodePesanan,
+				})
+			}
+			continue
+		}
+
+		if status == "shipped" || status == "to_confirm_receive" {
+			err := s.dropRepo.TransitionPurchaseStatus(ctx, dp.KodePesanan, lifecycle.Change{
+				To: lifecycle.Shipped, Cause: lifecycle.CauseShopeeShipped, Note: statusStr, At: updateTime,
+			})
+			if err != nil && !errors.Is(err, lifecycle.ErrInvalidTransition) {
+				reconcileLog.Error(ctx, "processShopeeStatusBatch", "Failed to mark purchase shipped", err, map[string]interface{}{
+					"kode_pesanan": dp.KodePesanan,
+				})
 			}
 		}
 	}
 	if len(completed) > 0 {
 		escMap, err := s.client.FetchShopeeEscrowDetails(ctx, *st.AccessToken, *st.ShopID, completed)
-		if err != nil && strings.Contains(err.Error(), "invalid_access_token") {
+		if errors.Is(err, apperr.ErrTokenExpired) {
 			if e := s.ensureStoreTokenValid(ctx, st); e == nil {
 				escMap, err = s.client.FetchShopeeEscrowDetails(ctx, *st.AccessToken, *st.ShopID, completed)
 			}
 		}
 		if err != nil {
-			log.Printf("batch escrow detail %s: %v", store, err)
+			reconcileLog.Error(ctx, "processShopeeStatusBatch", "Failed to fetch escrow details", err)
 		} else {
 			var wg sync.WaitGroup
 			limit := s.maxThreads
@@ -1974,14 +2177,18 @@ func (s *ReconcileService) processShopeeStatusBatch(ctx context.Context, store s
 			}
 			sem := make(chan struct{}, limit)
 
-			log.Printf("processing %d escrow settlements", len(escMap))
+			reconcileLog.Debug(ctx, "processShopeeStatusBatch", "Processing escrow settlements", map[string]interface{}{
+				"settlements": len(escMap),
+			})
 
 			for sn, esc := range escMap {
 				wg.Add(1)
 				sem <- struct{}{}
 				go func(sn string, esc ShopeeEscrowDetail) {
 					defer func() { <-sem; wg.Done() }()
-					log.Printf("processing escrow for order %s", sn)
+					reconcileLog.Debug(ctx, "processShopeeStatusBatch", "Processing escrow", map[string]interface{}{
+						"order_sn": sn,
+					})
 					inv := sn
 					dp, ok := dpMap[sn]
 					if !ok && s.dropRepo != nil {
@@ -1992,12 +2199,12 @@ func (s *ReconcileService) processShopeeStatusBatch(ctx context.Context, store s
 						}
 					}
 					if dp != nil {
-						log.Printf("found DropshipPurchase invoice %s", dp.KodeInvoiceChannel)
 						inv = dp.KodeInvoiceChannel
 					}
-					log.Printf("creating escrow settlement journal for %s", inv)
 					if err := s.createEscrowSettlementJournal(ctx, inv, "completed", timeMap[sn], &esc); err != nil {
-						log.Printf("escrow settlement %s: %v", sn, err)
+						reconcileLog.Error(ctx, "processShopeeStatusBatch", "Failed to create escrow settlement journal", err, map[string]interface{}{
+							"order_sn": sn,
+						})
 					}
 				}(sn, esc)
 			}
@@ -2007,15 +2214,17 @@ func (s *ReconcileService) processShopeeStatusBatch(ctx context.Context, store s
 
 	// Process returned orders if any
 	if len(returned) > 0 {
-		log.Printf("processing %d returned orders", len(returned))
+		reconcileLog.Debug(ctx, "processShopeeStatusBatch", "Processing returned orders", map[string]interface{}{
+			"orders": len(returned),
+		})
 		escMap, err := s.client.FetchShopeeEscrowDetails(ctx, *st.AccessToken, *st.ShopID, returned)
-		if err != nil && strings.Contains(err.Error(), "invalid_access_token") {
+		if errors.Is(err, apperr.ErrTokenExpired) {
 			if e := s.ensureStoreTokenValid(ctx, st); e == nil {
 				escMap, err = s.client.FetchShopeeEscrowDetails(ctx, *st.AccessToken, *st.ShopID, returned)
 			}
 		}
 		if err != nil {
-			log.Printf("batch escrow detail for returns %s: %v", store, err)
+			reconcileLog.Error(ctx, "processShopeeStatusBatch", "Failed to fetch escrow details for returns", err)
 		} else {
 			var wg sync.WaitGroup
 			limit := s.maxThreads
@@ -2024,14 +2233,14 @@ func (s *ReconcileService) processShopeeStatusBatch(ctx context.Context, store s
 			}
 			sem := make(chan struct{}, limit)
 
-			log.Printf("processing %d returned order escrow details", len(escMap))
-
 			for sn, esc := range escMap {
 				wg.Add(1)
 				sem <- struct{}{}
 				go func(sn string, esc ShopeeEscrowDetail) {
 					defer func() { <-sem; wg.Done() }()
-					log.Printf("processing return escrow for order %s", sn)
+					reconcileLog.Debug(ctx, "processShopeeStatusBatch", "Processing return escrow", map[string]interface{}{
+						"order_sn": sn,
+					})
 					inv := sn
 					dp, ok := dpMap[sn]
 					if !ok && s.dropRepo != nil {
@@ -2042,7 +2251,6 @@ func (s *ReconcileService) processShopeeStatusBatch(ctx context.Context, store s
 						}
 					}
 					if dp != nil {
-						log.Printf("found DropshipPurchase invoice %s for return", dp.KodeInvoiceChannel)
 						inv = dp.KodeInvoiceChannel
 					}
 
@@ -2062,9 +2270,10 @@ func (s *ReconcileService) processShopeeStatusBatch(ctx context.Context, store s
 						}
 					}
 
-					log.Printf("creating returned order journal for %s (partial: %t, amount: %.2f)", inv, isPartialReturn, returnAmount)
 					if err := s.createReturnedOrderJournal(ctx, inv, statusStr, timeMap[sn], &esc, isPartialReturn, returnAmount); err != nil {
-						log.Printf("returned order journal %s: %v", sn, err)
+						reconcileLog.Error(ctx, "processShopeeStatusBatch", "Failed to create returned order journal", err, map[string]interface{}{
+							"order_sn": sn,
+						})
 					}
 				}(sn, esc)
 			}
@@ -2080,7 +2289,13 @@ func (s *ReconcileService) CreateReconcileBatchesAsync(ctx context.Context, shop
 		return nil, fmt.Errorf("batch service not configured")
 	}
 
-	log.Printf("CreateReconcileBatchesAsync: creating master batch for shop=%s, order=%s, status=%s, from=%s, to=%s", shop, order, status, from, to)
+	reconcileLog.Debug(ctx, "CreateReconcileBatchesAsync", "Creating master batch", map[string]interface{}{
+		"shop":   shop,
+		"order":  order,
+		"status": status,
+		"from":   from,
+		"to":     to,
+	})
 
 	// Create a master batch immediately to track the batch creation process
 	// Store parameters in ErrorMessage field temporarily (will be cleared when processing starts)
@@ -2098,7 +2313,9 @@ func (s *ReconcileService) CreateReconcileBatchesAsync(ctx context.Context, shop
 		return nil, fmt.Errorf("failed to create master batch: %w", err)
 	}
 
-	log.Printf("CreateReconcileBatchesAsync: created master batch %d, work will be processed in background", masterBatchID)
+	reconcileLog.Info(ctx, "CreateReconcileBatchesAsync", "Master batch created", map[string]interface{}{
+		"batch_id": masterBatchID,
+	})
 
 	result := &models.ReconcileBatchInfo{
 		BatchCount:        1, // Master batch
@@ -2118,7 +2335,13 @@ func (s *ReconcileService) CreateReconcileBatches(ctx context.Context, shop, ord
 		return nil, fmt.Errorf("batch service not configured")
 	}
 
-	log.Printf("CreateReconcileBatches: fetching candidates for shop=%s, order=%s, status=%s, from=%s, to=%s", shop, order, status, from, to)
+	reconcileLog.Debug(ctx, "CreateReconcileBatches", "Fetching candidates", map[string]interface{}{
+		"shop":   shop,
+		"order":  order,
+		"status": status,
+		"from":   from,
+		"to":     to,
+	})
 	pageSize := 1000
 	batchSize := 50 // Process in batches of 50 orders
 	offset := 0
@@ -2135,7 +2358,9 @@ func (s *ReconcileService) CreateReconcileBatches(ctx context.Context, shop, ord
 		offset += pageSize
 	}
 
-	log.Printf("CreateReconcileBatches: found %d total candidates", len(all))
+	reconcileLog.Debug(ctx, "CreateReconcileBatches", "Candidates loaded", map[string]interface{}{
+		"candidates": len(all),
+	})
 
 	batches := make(map[string][]models.ReconcileCandidate)
 	for _, c := range all {
@@ -2143,8 +2368,12 @@ func (s *ReconcileService) CreateReconcileBatches(ctx context.Context, shop, ord
 	}
 
 	batchCount := 0
+	var batchIDs []int64
 	for store, list := range batches {
-		log.Printf("CreateReconcileBatches: processing %d candidates for store %s", len(list), store)
+		reconcileLog.Debug(ctx, "CreateReconcileBatches", "Batching candidates for store", map[string]interface{}{
+			"store":      store,
+			"candidates": len(list),
+		})
 		for i := 0; i < len(list); i += batchSize {
 			end := i + batchSize
 			if end > len(list) {
@@ -2157,11 +2386,15 @@ func (s *ReconcileService) CreateReconcileBatches(ctx context.Context, shop, ord
 				return nil, err
 			}
 			batchCount++
+			batchIDs = append(batchIDs, batchID)
 
 			for _, cand := range subset {
 				d := &models.BatchHistoryDetail{BatchID: batchID, Reference: cand.KodeInvoiceChannel, Store: store, Status: "pending"}
 				if err := s.batchSvc.CreateDetail(ctx, d); err != nil {
-					log.Printf("create batch detail %s: %v", store, err)
+					reconcileLog.Error(ctx, "CreateReconcileBatches", "Failed to create batch detail", err, map[string]interface{}{
+						"store":    store,
+						"batch_id": batchID,
+					})
 				}
 			}
 		}
@@ -2170,9 +2403,13 @@ func (s *ReconcileService) CreateReconcileBatches(ctx context.Context, shop, ord
 	result := &models.ReconcileBatchInfo{
 		BatchCount:        batchCount,
 		TotalTransactions: len(all),
+		BatchIDs:          batchIDs,
 	}
 
-	log.Printf("CreateReconcileBatches: created %d batches for %d total transactions", result.BatchCount, result.TotalTransactions)
+	reconcileLog.Info(ctx, "CreateReconcileBatches", "Reconcile batches created", map[string]interface{}{
+		"batches":      result.BatchCount,
+		"transactions": result.TotalTransactions,
+	})
 	return result, nil
 }
 
@@ -2180,17 +2417,25 @@ func (s *ReconcileService) CreateReconcileBatches(ctx context.Context, shop, ord
 // fetching and creating reconcile batches in the background.
 func (s *ReconcileService) ProcessReconcileBatchCreation(ctx context.Context, masterBatchID int64) {
 	if s.batchSvc == nil {
-		log.Printf("ProcessReconcileBatchCreation %d: batch service not configured", masterBatchID)
+		reconcileLog.Warn(ctx, "ProcessReconcileBatchCreation", "Batch service not configured", map[string]interface{}{
+			"batch_id": masterBatchID,
+		})
 		return
 	}
+	ctx, span := startBatchSpan(ctx, "reconcile_batch_creation", masterBatchID)
+	defer span.End()
 
 	start := time.Now()
-	log.Printf("ProcessReconcileBatchCreation %d: starting batch creation processing", masterBatchID)
+	reconcileLog.Debug(ctx, "ProcessReconcileBatchCreation", "Creating reconcile batches", map[string]interface{}{
+		"batch_id": masterBatchID,
+	})
 
 	// Get the master batch to extract parameters
 	masterBatch, err := s.batchSvc.GetByID(ctx, masterBatchID)
 	if err != nil {
-		log.Printf("ProcessReconcileBatchCreation %d: failed to get master batch: %v", masterBatchID, err)
+		reconcileLog.Error(ctx, "ProcessReconcileBatchCreation", "Failed to get master batch", err, map[string]interface{}{
+			"batch_id": masterBatchID,
+		})
 		s.batchSvc.UpdateStatusWithEndTime(ctx, masterBatchID, "failed", fmt.Sprintf("Failed to get master batch: %v", err))
 		return
 	}
@@ -2204,8 +2449,14 @@ func (s *ReconcileService) ProcessReconcileBatchCreation(ctx context.Context, ma
 	from := params["from"]
 	to := params["to"]
 
-	log.Printf("ProcessReconcileBatchCreation %d: parsed parameters shop=%s, order=%s, status=%s, from=%s, to=%s",
-		masterBatchID, shop, order, status, from, to)
+	reconcileLog.Debug(ctx, "ProcessReconcileBatchCreation", "Parsed batch parameters", map[string]interface{}{
+		"batch_id": masterBatchID,
+		"shop":     shop,
+		"order":    order,
+		"status":   status,
+		"from":     from,
+		"to":       to,
+	})
 
 	// Update status to processing and clear the metadata from error_message
 	s.batchSvc.UpdateStatus(ctx, masterBatchID, "processing", "")
@@ -2213,7 +2464,9 @@ func (s *ReconcileService) ProcessReconcileBatchCreation(ctx context.Context, ma
 	// Do the actual work (same as the synchronous CreateReconcileBatches)
 	result, err := s.CreateReconcileBatches(ctx, shop, order, status, from, to)
 	if err != nil {
-		log.Printf("ProcessReconcileBatchCreation %d: failed to create batches: %v", masterBatchID, err)
+		reconcileLog.Error(ctx, "ProcessReconcileBatchCreation", "Failed to create batches", err, map[string]interface{}{
+			"batch_id": masterBatchID,
+		})
 		s.batchSvc.UpdateStatusWithEndTime(ctx, masterBatchID, "failed", fmt.Sprintf("Failed to create batches: %v", err))
 		return
 	}
@@ -2226,7 +2479,12 @@ func (s *ReconcileService) ProcessReconcileBatchCreation(ctx context.Context, ma
 	s.batchSvc.UpdateBatchData(ctx, masterBatchID, result.TotalTransactions, result.TotalTransactions)
 	s.batchSvc.UpdateStatusWithEndTime(ctx, masterBatchID, "completed", completionMsg)
 
-	log.Printf("ProcessReconcileBatchCreation %d: completed successfully - %s", masterBatchID, completionMsg)
+	reconcileLog.Info(ctx, "ProcessReconcileBatchCreation", "Reconcile batches created", map[string]interface{}{
+		"batch_id":     masterBatchID,
+		"batches":      result.BatchCount,
+		"transactions": result.TotalTransactions,
+		"duration_ms":  duration.Milliseconds(),
+	})
 }
 
 // parseMetadata parses metadata string in format "key1=value1,key2=value2,..."
@@ -2251,17 +2509,36 @@ func (s *ReconcileService) ProcessReconcileBatch(ctx context.Context, id int64)
 	if s.batchSvc == nil {
 		return
 	}
+	ctx, span := startBatchSpan(ctx, "reconcile_batch", id)
+	defer span.End()
+
+	claimed, err := s.batchSvc.Claim(ctx, id)
+	if err != nil {
+		reconcileLog.Error(ctx, "ProcessReconcileBatch", "Failed to claim batch", err, map[string]interface{}{
+			"batch_id": id,
+		})
+		return
+	}
+	if !claimed {
+		reconcileLog.Info(ctx, "ProcessReconcileBatch", "Batch is no longer pending, skipping", map[string]interface{}{
+			"batch_id": id,
+		})
+		return
+	}
 
 	start := time.Now()
-	log.Printf("ProcessReconcileBatch %d: starting batch processing", id)
+	reconcileLog.Debug(ctx, "ProcessReconcileBatch", "Processing batch", map[string]interface{}{
+		"batch_id": id,
+	})
 
 	details, err := s.batchSvc.ListDetails(ctx, id)
 	if err != nil {
-		log.Printf("list batch details %d: %v", id, err)
+		reconcileLog.Error(ctx, "ProcessReconcileBatch", "Failed to list batch details", err, map[string]interface{}{
+			"batch_id": id,
+		})
 		s.batchSvc.UpdateStatusWithEndTime(ctx, id, "failed", err.Error())
 		return
 	}
-	s.batchSvc.UpdateStatus(ctx, id, "processing", "")
 
 	invoices := make([]string, len(details))
 	for i, d := range details {
@@ -2269,25 +2546,38 @@ func (s *ReconcileService) ProcessReconcileBatch(ctx context.Context, id int64)
 	}
 
 	// Update Shopee statuses in bulk first
-	log.Printf("ProcessReconcileBatch %d: updating %d statuses", id, len(invoices))
+	reconcileLog.Debug(ctx, "ProcessReconcileBatch", "Updating statuses", map[string]interface{}{
+		"batch_id": id,
+		"invoices": len(invoices),
+	})
 	statusStart := time.Now()
 	if err := s.UpdateShopeeStatuses(ctx, invoices); err != nil {
-		log.Printf("update statuses batch %d: %v", id, err)
+		reconcileLog.Error(ctx, "ProcessReconcileBatch", "Failed to update statuses", err, map[string]interface{}{
+			"batch_id": id,
+		})
 	}
 	statusDuration := time.Since(statusStart)
-	log.Printf("ProcessReconcileBatch %d: status update completed in %v", id, statusDuration)
+	reconcileLog.Debug(ctx, "ProcessReconcileBatch", "Statuses updated", map[string]interface{}{
+		"batch_id":    id,
+		"duration_ms": statusDuration.Milliseconds(),
+	})
 
 	// Bulk fetch purchases to reduce database calls
-	log.Printf("ProcessReconcileBatch %d: bulk fetching purchases", id)
 	fetchStart := time.Now()
 	purchases, err := s.bulkGetDropshipPurchasesByInvoices(ctx, invoices)
 	if err != nil {
-		log.Printf("bulk fetch purchases batch %d: %v", id, err)
+		reconcileLog.Error(ctx, "ProcessReconcileBatch", "Failed to fetch purchases", err, map[string]interface{}{
+			"batch_id": id,
+		})
 		s.batchSvc.UpdateStatusWithEndTime(ctx, id, "failed", err.Error())
 		return
 	}
 	fetchDuration := time.Since(fetchStart)
-	log.Printf("ProcessReconcileBatch %d: bulk fetch completed in %v for %d purchases", id, fetchDuration, len(purchases))
+	reconcileLog.Debug(ctx, "ProcessReconcileBatch", "Purchases fetched", map[string]interface{}{
+		"batch_id":    id,
+		"purchases":   len(purchases),
+		"duration_ms": fetchDuration.Milliseconds(),
+	})
 
 	// Create lookup map for faster access
 	purchaseMap := make(map[string]*models.DropshipPurchase)
@@ -2300,56 +2590,89 @@ func (s *ReconcileService) ProcessReconcileBatch(ctx context.Context, id int64)
 	failed := 0
 	processStart := time.Now()
 
+	// Batches are created per store, so the first detail's store decides the policy.
+	batchStore := ""
+	if len(details) > 0 {
+		batchStore = details[0].Store
+	}
+	policy := s.policyFor(ctx, batchStore)
+	progress := &models.ReconciliationReport{TotalTransactions: len(details)}
+	haltedOn := ""
+
 	for _, d := range details {
 		dp, exists := purchaseMap[d.Reference]
 		if !exists {
 			msg := fmt.Sprintf("purchase not found for invoice %s", d.Reference)
-			log.Printf("ProcessReconcileBatch %d: %s", id, msg)
+			reconcileLog.Warn(ctx, "ProcessReconcileBatch", "Purchase not found", map[string]interface{}{
+				"batch_id": id,
+				"invoice":  d.Reference,
+			})
 
 			// Record the failure
 			if s.failedRepo != nil {
 				failedRec := &models.FailedReconciliation{
 					PurchaseID: d.Reference,
 					Shop:       d.Store,
-					ErrorType:  "purchase_not_found",
+					ErrorType:  string(apperr.PurchaseNotFound),
 					ErrorMsg:   msg,
 					FailedAt:   time.Now(),
 					BatchID:    &id,
 				}
-				if err := s.failedRepo.InsertFailedReconciliation(ctx, failedRec); err != nil {
-					log.Printf("Failed to record failure for %s: %v", d.Reference, err)
+				if err := s.insertFailure(ctx, failedRec); err != nil {
+					reconcileLog.Error(ctx, "ProcessReconcileBatch", "Failed to record reconcile failure", err, map[string]interface{}{
+						"batch_id": id,
+						"invoice":  d.Reference,
+					})
 				}
 			}
 
 			s.batchSvc.UpdateDetailStatus(ctx, d.ID, "failed", msg)
 			failed++
+			progress.FailedTransactions = failed
+			if shouldHaltProcessing(policy, progress, string(apperr.PurchaseNotFound)) {
+				haltedOn = string(apperr.PurchaseNotFound)
+				break
+			}
 			continue
 		}
 
 		if err := s.CheckAndMarkComplete(ctx, dp.KodePesanan); err != nil {
-			log.Printf("ProcessReconcileBatch %d: CheckAndMarkComplete failed for %s: %v", id, dp.KodePesanan, err)
+			reconcileLog.Error(ctx, "ProcessReconcileBatch", "Failed to mark purchase complete", err, map[string]interface{}{
+				"batch_id":     id,
+				"kode_pesanan": dp.KodePesanan,
+			})
 
 			// Record the failure
+			errorType := s.categorizeError(err)
 			if s.failedRepo != nil {
 				failedRec := &models.FailedReconciliation{
 					PurchaseID: dp.KodePesanan,
 					Shop:       d.Store,
-					ErrorType:  s.categorizeError(err),
+					ErrorType:  errorType,
 					ErrorMsg:   err.Error(),
 					FailedAt:   time.Now(),
 					BatchID:    &id,
 				}
-				if err := s.failedRepo.InsertFailedReconciliation(ctx, failedRec); err != nil {
-					log.Printf("Failed to record failure for %s: %v", dp.KodePesanan, err)
+				if err := s.insertFailure(ctx, failedRec); err != nil {
+					reconcileLog.Error(ctx, "ProcessReconcileBatch", "Failed to record reconcile failure", err, map[string]interface{}{
+						"batch_id":     id,
+						"kode_pesanan": dp.KodePesanan,
+					})
 				}
 			}
 
 			s.batchSvc.UpdateDetailStatus(ctx, d.ID, "failed", err.Error())
 			failed++
+			progress.FailedTransactions = failed
+			if shouldHaltProcessing(policy, progress, errorType) {
+				haltedOn = errorType
+				break
+			}
 			continue
 		}
 
 		done++
+		progress.SuccessfulTransactions = done
 		s.batchSvc.UpdateDone(ctx, id, done)
 		s.batchSvc.UpdateDetailStatus(ctx, d.ID, "success", "")
 	}
@@ -2368,14 +2691,28 @@ func (s *ReconcileService) ProcessReconcileBatch(ctx context.Context, id int64)
 	statusMsg := ""
 
 	// Check if we should mark the batch as failed due to high failure rate
-	if s.config != nil && failureRate > s.config.FailureThresholdPercent && total >= 10 {
+	if haltedOn != "" {
+		status = "completed_with_warnings"
+		statusMsg = fmt.Sprintf("Halted after %d failures (last: %s)", failed, haltedOn)
+	} else if failureRate > policy.FailureThresholdPercent && total >= 10 {
 		status = "completed_with_warnings"
 		statusMsg = fmt.Sprintf("High failure rate: %.2f%%", failureRate)
 	}
 
 	s.batchSvc.UpdateStatusWithEndTime(ctx, id, status, statusMsg)
-	log.Printf("ProcessReconcileBatch %d completed in %v: %d successful, %d failed, %.2f%% failure rate (status: %v, fetch: %v, process: %v)",
-		id, totalDuration, done, failed, failureRate, statusDuration, fetchDuration, processDuration)
+	if done > 0 {
+		invalidateCache(ctx, s.cache, reconcileWriteTags...)
+	}
+	reconcileLog.Info(ctx, "ProcessReconcileBatch", "Batch processed", map[string]interface{}{
+		"batch_id":     id,
+		"successful":   done,
+		"failed":       failed,
+		"failure_rate": failureRate,
+		"duration_ms":  totalDuration.Milliseconds(),
+		"status_ms":    statusDuration.Milliseconds(),
+		"fetch_ms":     fetchDuration.Milliseconds(),
+		"process_ms":   processDuration.Milliseconds(),
+	})
 }
 
 // GenerateReconciliationReport creates a comprehensive report for a given shop and time period.
@@ -2398,10 +2735,12 @@ func (s *ReconcileService) GenerateReconciliationReport(ctx context.Context, sho
 
 	// Get detailed failed transaction list if configured
 	var failedList []models.FailedReconciliation
-	if s.config.GenerateDetailedReport {
+	if s.policyFor(ctx, shop).GenerateDetailedReport {
 		failedList, err = s.failedRepo.GetFailedReconciliationsByShop(ctx, shop, 100, 0) // Get last 100 failures
 		if err != nil {
-			log.Printf("Failed to get detailed failed transactions: %v", err)
+			reconcileLog.Error(ctx, "GenerateReconciliationReport", "Failed to list failed transactions", err, map[string]interface{}{
+				"shop": shop,
+			})
 		}
 	}
 
@@ -2420,79 +2759,34 @@ func (s *ReconcileService) GenerateReconciliationReport(ctx context.Context, sho
 	return report, nil
 }
 
-// RetryFailedReconciliations attempts to reprocess failed reconciliation transactions.
+// RetryFailedReconciliations retries the pending failed reconciliations of a
+// shop right away, whether or not their backoff has elapsed. Each retry is
+// recorded in the row's attempt history; see retryFailures.
 func (s *ReconcileService) RetryFailedReconciliations(ctx context.Context, shop string, maxRetries int) (*models.ReconciliationReport, error) {
 	if s.failedRepo == nil {
 		return nil, fmt.Errorf("failed reconciliation repository not configured")
 	}
 
-	log.Printf("RetryFailedReconciliations: starting retry for shop %s, max %d retries", shop, maxRetries)
-	startTime := time.Now()
+	reconcileLog.Info(ctx, "RetryFailedReconciliations", "Retrying failed reconciliations", map[string]interface{}{
+		"shop":        shop,
+		"max_retries": maxRetries,
+	})
 
-	// Get unretried failed reconciliations
 	failedList, err := s.failedRepo.GetUnretriedFailedReconciliations(ctx, shop, maxRetries)
 	if err != nil {
 		return nil, fmt.Errorf("get unretried failed reconciliations: %w", err)
 	}
 
-	report := &models.ReconciliationReport{
-		TotalTransactions:      len(failedList),
-		SuccessfulTransactions: 0,
-		FailedTransactions:     0,
-		ProcessingStartTime:    startTime,
-		FailureCategories:      make(map[string]int),
-		FailedTransactionList:  []models.FailedReconciliation{},
-	}
-
-	// Retry each failed transaction
-	for _, failed := range failedList {
-		var err error
-		if failed.OrderID != nil {
-			err = s.MatchAndJournal(ctx, failed.PurchaseID, *failed.OrderID, failed.Shop)
-		} else {
-			// For transactions without order ID, try to complete the purchase
-			err = s.CheckAndMarkComplete(ctx, failed.PurchaseID)
-		}
+	report := s.retryFailures(ctx, failedList)
 
-		// Mark as retried regardless of outcome
-		if markErr := s.failedRepo.MarkAsRetried(ctx, failed.ID); markErr != nil {
-			log.Printf("Failed to mark transaction %d as retried: %v", failed.ID, markErr)
-		}
-
-		if err != nil {
-			// Still failed after retry
-			report.FailedTransactions++
-			errorType := s.categorizeError(err)
-			report.FailureCategories[errorType]++
-
-			// Record the new failure
-			newFailed := &models.FailedReconciliation{
-				PurchaseID: failed.PurchaseID,
-				OrderID:    failed.OrderID,
-				Shop:       failed.Shop,
-				ErrorType:  errorType,
-				ErrorMsg:   err.Error(),
-				FailedAt:   time.Now(),
-			}
-			if recordErr := s.failedRepo.InsertFailedReconciliation(ctx, newFailed); recordErr != nil {
-				log.Printf("Failed to record retry failure: %v", recordErr)
-			}
-		} else {
-			// Retry succeeded
-			report.SuccessfulTransactions++
-		}
-	}
-
-	// Finalize report
-	endTime := time.Now()
-	report.ProcessingEndTime = endTime
-	report.Duration = endTime.Sub(startTime).String()
-	if report.TotalTransactions > 0 {
-		report.FailureRate = float64(report.FailedTransactions) / float64(report.TotalTransactions) * 100
-	}
-
-	log.Printf("RetryFailedReconciliations completed: %d retried, %d successful, %d still failed, %.2f%% failure rate",
-		report.TotalTransactions, report.SuccessfulTransactions, report.FailedTransactions, report.FailureRate)
+	reconcileLog.Info(ctx, "RetryFailedReconciliations", "Retry completed", map[string]interface{}{
+		"shop":         shop,
+		"retried":      report.TotalTransactions,
+		"successful":   report.SuccessfulTransactions,
+		"failed":       report.FailedTransactions,
+		"skipped":      report.SkippedTransactions,
+		"failure_rate": report.FailureRate,
+	})
 
 	return report, nil
 }
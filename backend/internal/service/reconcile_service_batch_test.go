@@ -10,7 +10,9 @@ import (
 	"time"
 
 	"github.com/ramadhan22/dropship-erp/backend/internal/config"
+	"github.com/ramadhan22/dropship-erp/backend/internal/lifecycle"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 )
 
 type fakeDropRepoBatch struct {
@@ -33,13 +35,13 @@ func (f *fakeDropRepoBatch) GetDropshipPurchaseByID(ctx context.Context, kode st
 	return nil, fmt.Errorf("not found")
 }
 
-func (f *fakeDropRepoBatch) UpdatePurchaseStatus(ctx context.Context, kode, status string) error {
+func (f *fakeDropRepoBatch) TransitionPurchaseStatus(ctx context.Context, kode string, c lifecycle.Change) error {
 	return nil
 }
-func (f *fakeDropRepoBatch) SumDetailByInvoice(ctx context.Context, inv string) (float64, error) {
+func (f *fakeDropRepoBatch) SumDetailByInvoice(ctx context.Context, inv string) (money.Amount, error) {
 	return 0, nil
 }
-func (f *fakeDropRepoBatch) SumProductCostByInvoice(ctx context.Context, inv string) (float64, error) {
+func (f *fakeDropRepoBatch) SumProductCostByInvoice(ctx context.Context, inv string) (money.Amount, error) {
 	return 0, nil
 }
 
@@ -82,6 +84,8 @@ func (f *fakeJournalRepoBatch) InsertJournalLines(ctx context.Context, lines []m
 type fakeBatchSvc struct {
 	created []*models.BatchHistory
 	updated []int64
+	claimed map[int64]bool
+	listed  []int64
 }
 
 func (f *fakeBatchSvc) Create(ctx context.Context, b *models.BatchHistory) (int64, error) {
@@ -104,6 +108,7 @@ func (f *fakeBatchSvc) CreateDetail(ctx context.Context, d *models.BatchHistoryD
 	return nil
 }
 func (f *fakeBatchSvc) ListDetails(ctx context.Context, id int64) ([]models.BatchHistoryDetail, error) {
+	f.listed = append(f.listed, id)
 	return []models.BatchHistoryDetail{}, nil
 }
 func (f *fakeBatchSvc) UpdateDetailStatus(ctx context.Context, id int64, status, msg string) error {
@@ -113,6 +118,17 @@ func (f *fakeBatchSvc) ListPendingByType(ctx context.Context, typ string) ([]mod
 	return []models.BatchHistory{}, nil
 }
 
+func (f *fakeBatchSvc) Claim(ctx context.Context, id int64) (bool, error) {
+	if f.claimed[id] {
+		return false, nil
+	}
+	if f.claimed == nil {
+		f.claimed = map[int64]bool{}
+	}
+	f.claimed[id] = true
+	return true, nil
+}
+
 func (f *fakeBatchSvc) GetByID(ctx context.Context, id int64) (*models.BatchHistory, error) {
 	// Return a mock batch for testing
 	return &models.BatchHistory{
@@ -265,3 +281,14 @@ func TestUpdateShopeeStatuses_BatchHistory(t *testing.T) {
 		t.Fatalf("expected UpdateDone to be called once, got %d", len(batchSvc.updated))
 	}
 }
+
+func TestProcessReconcileBatchSkipsClaimedBatch(t *testing.T) {
+	batchSvc := &fakeBatchSvc{claimed: map[int64]bool{7: true}}
+	svc := NewReconcileService(nil, &fakeDropRepoBatch{}, nil, &fakeJournalRepoBatch{}, nil, nil, nil, nil, nil, batchSvc, nil, nil, 5, nil)
+
+	svc.ProcessReconcileBatch(context.Background(), 7)
+
+	if len(batchSvc.listed) != 0 {
+		t.Fatalf("batch claimed elsewhere was processed: listed %v", batchSvc.listed)
+	}
+}
@@ -4,11 +4,15 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"testing"
 	"time"
 
+	"github.com/ramadhan22/dropship-erp/backend/internal/apperr"
+	"github.com/ramadhan22/dropship-erp/backend/internal/lifecycle"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 )
 
 // fake implementations for each repo interface:
@@ -21,32 +25,42 @@ func (f *fakeDropRepoRec) GetDropshipPurchaseByInvoice(ctx context.Context, invo
 	if dp, ok := f.data[invoice]; ok {
 		return dp, nil
 	}
-	return nil, errors.New("not found")
+	return nil, &apperr.NotFoundError{Entity: apperr.EntityPurchase, Key: invoice}
 }
 
 func (f *fakeDropRepoRec) GetDropshipPurchaseByID(ctx context.Context, kode string) (*models.DropshipPurchase, error) {
 	if dp, ok := f.data[kode]; ok {
 		return dp, nil
 	}
-	return nil, errors.New("not found")
+	return nil, &apperr.NotFoundError{Entity: apperr.EntityPurchase, Key: kode}
 }
 
-func (f *fakeDropRepoRec) UpdatePurchaseStatus(ctx context.Context, kode, status string) error {
+func (f *fakeDropRepoRec) TransitionPurchaseStatus(ctx context.Context, kode string, c lifecycle.Change) error {
 	if dp, ok := f.data[kode]; ok {
-		dp.StatusPesananTerakhir = status
+		from := dp.LifecycleStatus
+		if from == "" {
+			from = lifecycle.Imported
+		}
+		if err := lifecycle.Check(from, c.To); err != nil {
+			return err
+		}
+		dp.LifecycleStatus = c.To
+		if l := c.DisplayLabel(); l != "" {
+			dp.StatusPesananTerakhir = l
+		}
 		return nil
 	}
 	return errors.New("not found")
 }
 
-func (f *fakeDropRepoRec) SumDetailByInvoice(ctx context.Context, inv string) (float64, error) {
+func (f *fakeDropRepoRec) SumDetailByInvoice(ctx context.Context, inv string) (money.Amount, error) {
 	if dp, ok := f.data[inv]; ok {
 		return dp.TotalTransaksi, nil
 	}
 	return 0, nil
 }
 
-func (f *fakeDropRepoRec) SumProductCostByInvoice(ctx context.Context, inv string) (float64, error) {
+func (f *fakeDropRepoRec) SumProductCostByInvoice(ctx context.Context, inv string) (money.Amount, error) {
 	if dp, ok := f.data[inv]; ok {
 		return dp.TotalTransaksi, nil
 	}
@@ -61,7 +75,7 @@ func (f *fakeShopeeRepoRec) GetShopeeOrderByID(ctx context.Context, orderID stri
 	if so, ok := f.data[orderID]; ok {
 		return so, nil
 	}
-	return nil, errors.New("not found")
+	return nil, &apperr.NotFoundError{Entity: apperr.EntityShopeeOrder, Key: orderID}
 }
 
 func (f *fakeShopeeRepoRec) ExistsShopeeSettled(ctx context.Context, no string) (bool, error) {
@@ -120,6 +134,10 @@ func (f *fakeDetailRepo) GetOrderDetail(ctx context.Context, sn string) (*models
 
 type fakeFailedRecRepo struct {
 	failures []models.FailedReconciliation
+	attempts []models.FailedReconciliationAttempt
+	// storeRetry overrides the retry default per shop, like a row in
+	// reconciliation_policies.
+	storeRetry map[string]bool
 }
 
 func (f *fakeFailedRecRepo) InsertFailedReconciliation(ctx context.Context, failed *models.FailedReconciliation) error {
@@ -145,12 +163,69 @@ func (f *fakeFailedRecRepo) CountFailedReconciliationsByErrorType(ctx context.Co
 	return counts, nil
 }
 
-func (f *fakeFailedRecRepo) MarkAsRetried(ctx context.Context, id int64) error {
+func (f *fakeFailedRecRepo) GetUnretriedFailedReconciliations(ctx context.Context, shop string, limit int) ([]models.FailedReconciliation, error) {
+	return f.failures, nil
+}
+
+func (f *fakeFailedRecRepo) GetFailedReconciliation(ctx context.Context, id int64) (*models.FailedReconciliation, error) {
+	for i := range f.failures {
+		if f.failures[i].ID == id {
+			cp := f.failures[i]
+			return &cp, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakeFailedRecRepo) ClaimDueRetries(ctx context.Context, now, leaseUntil time.Time, retryByDefault bool, limit int) ([]models.FailedReconciliation, error) {
+	var list []models.FailedReconciliation
+	for i := range f.failures {
+		fail := &f.failures[i]
+		if len(list) == limit {
+			break
+		}
+		retry, ok := f.storeRetry[fail.Shop]
+		if !ok {
+			retry = retryByDefault
+		}
+		if retry && fail.Status == models.FailedReconPending && fail.NextRetryAt != nil && !fail.NextRetryAt.After(now) {
+			lease := leaseUntil
+			fail.NextRetryAt = &lease
+			list = append(list, *fail)
+		}
+	}
+	return list, nil
+}
+
+func (f *fakeFailedRecRepo) ListFailedReconciliations(ctx context.Context, status, shop string, limit, offset int) ([]models.FailedReconciliation, int, error) {
+	return f.failures, len(f.failures), nil
+}
+
+func (f *fakeFailedRecRepo) ListFailedReconciliationsFiltered(ctx context.Context, params *models.FilterParams) (*models.QueryResult, error) {
+	return &models.QueryResult{}, nil
+}
+
+func (f *fakeFailedRecRepo) RecordAttempt(ctx context.Context, failed *models.FailedReconciliation, a *models.FailedReconciliationAttempt) error {
+	f.attempts = append(f.attempts, *a)
+	for i := range f.failures {
+		if f.failures[i].ID == failed.ID {
+			f.failures[i] = *failed
+		}
+	}
 	return nil
 }
 
-func (f *fakeFailedRecRepo) GetUnretriedFailedReconciliations(ctx context.Context, shop string, limit int) ([]models.FailedReconciliation, error) {
-	return f.failures, nil
+func (f *fakeFailedRecRepo) MarkNeedsAttention(ctx context.Context, id int64) error {
+	for i := range f.failures {
+		if f.failures[i].ID == id {
+			f.failures[i].Status = models.FailedReconNeedsAttention
+		}
+	}
+	return nil
+}
+
+func (f *fakeFailedRecRepo) ListAttempts(ctx context.Context, id int64) ([]models.FailedReconciliationAttempt, error) {
+	return f.attempts, nil
 }
 
 func TestMatchAndJournal_Success(t *testing.T) {
@@ -159,12 +234,12 @@ func TestMatchAndJournal_Success(t *testing.T) {
 	// Prepare fake repos with preloaded data
 	fDrop := &fakeDropRepoRec{
 		data: map[string]*models.DropshipPurchase{
-			"DP-111": {KodePesanan: "DP-111", TotalTransaksi: 50.00},
+			"DP-111": {KodePesanan: "DP-111", TotalTransaksi: money.New(50)},
 		},
 	}
 	fShopee := &fakeShopeeRepoRec{
 		data: map[string]*models.ShopeeSettledOrder{
-			"SO-222": {OrderID: "SO-222", NetIncome: 80.00, SettledDate: time.Now()},
+			"SO-222": {OrderID: "SO-222", NetIncome: money.New(80), SettledDate: time.Now()},
 		},
 	}
 	fJournal := &fakeJournalRepoRec{nextID: 0}
@@ -194,10 +269,10 @@ func TestMatchAndJournal_Success(t *testing.T) {
 	// Check debit (COGS) and credit (Cash)
 	var foundDebit, foundCredit bool
 	for _, l := range fJournal.lines {
-		if l.AccountID == 5001 && l.IsDebit && l.Amount == 50.00 {
+		if l.AccountID == 5001 && l.IsDebit && l.Amount == money.New(50) {
 			foundDebit = true
 		}
-		if l.AccountID == 1001 && !l.IsDebit && l.Amount == 80.00 {
+		if l.AccountID == 1001 && !l.IsDebit && l.Amount == money.New(80) {
 			foundCredit = true
 		}
 	}
@@ -214,3 +289,40 @@ func TestMatchAndJournal_Success(t *testing.T) {
 		t.Errorf("unexpected ReconciledTransaction: %+v", rt)
 	}
 }
+
+func TestCancelPurchaseFollowsLifecycle(t *testing.T) {
+	ctx := context.Background()
+	fDrop := &fakeDropRepoRec{data: map[string]*models.DropshipPurchase{
+		"DP-1": {KodePesanan: "DP-1", KodeInvoiceChannel: "INV-1", NamaToko: "ShopA", TotalTransaksi: money.New(50), LifecycleStatus: lifecycle.PendingSale},
+		"DP-2": {KodePesanan: "DP-2", KodeInvoiceChannel: "INV-2", NamaToko: "ShopA", TotalTransaksi: money.New(50), LifecycleStatus: lifecycle.Settled},
+	}}
+	fJournal := &fakeJournalRepoRec{}
+	svc := NewReconcileService(nil, fDrop, nil, fJournal, nil, nil, nil, nil, nil, nil, nil, nil, 5, nil)
+
+	if err := svc.CancelPurchaseAt(ctx, "DP-1", time.Now(), lifecycle.CauseShopeeCancelled); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if dp := fDrop.data["DP-1"]; dp.LifecycleStatus != lifecycle.Cancelled || dp.StatusPesananTerakhir != shopeeCancelledLabel {
+		t.Errorf("unexpected status %q / %q", dp.LifecycleStatus, dp.StatusPesananTerakhir)
+	}
+	if len(fJournal.entries) != 1 {
+		t.Fatalf("expected 1 cancel journal, got %d", len(fJournal.entries))
+	}
+
+	// Cancelling again must not post a second reversal.
+	if err := svc.CancelPurchaseAt(ctx, "DP-1", time.Now(), lifecycle.CauseShopeeCancelled); err != nil {
+		t.Fatalf("second cancel: %v", err)
+	}
+	if len(fJournal.entries) != 1 {
+		t.Errorf("expected cancel to be idempotent, got %d journals", len(fJournal.entries))
+	}
+
+	// A settled order cannot be cancelled.
+	err := svc.CancelPurchase(ctx, "DP-2")
+	if !errors.Is(err, lifecycle.ErrInvalidTransition) {
+		t.Errorf("expected ErrInvalidTransition, got %v", err)
+	}
+	if len(fJournal.entries) != 1 {
+		t.Errorf("no journal should be posted for a rejected cancel, got %d", len(fJournal.entries))
+	}
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"sync"
@@ -12,6 +13,10 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
+// ErrReconcileHalted is returned when a store's reconciliation policy stops
+// a run early because of critical errors or too many failures.
+var ErrReconcileHalted = errors.New("reconciliation halted by store policy")
+
 // ReconcileStreamConfig defines configuration for streaming reconciliation
 type ReconcileStreamConfig struct {
 	// ChunkSize is the number of records to process in each chunk
@@ -309,6 +314,9 @@ func (p *ReconcileStreamProcessor) processChunk(ctx context.Context, shop string
 			result.SuccessRows = report.SuccessfulTransactions
 			result.FailedRows = report.FailedTransactions
 			result.FailedRecords = report.FailedTransactionList
+			if report.Halted {
+				result.Error = fmt.Errorf("chunk %d: %w", chunkIndex, ErrReconcileHalted)
+			}
 		}
 	}
 
@@ -347,6 +355,10 @@ func (p *ReconcileStreamProcessor) processChunk(ctx context.Context, shop string
 	})
 
 	p.resultChan <- result
+	// A halted chunk stops the remaining chunks of the run.
+	if errors.Is(result.Error, ErrReconcileHalted) {
+		return result.Error
+	}
 	return nil
 }
 
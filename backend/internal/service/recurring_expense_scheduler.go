@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/logutil"
+)
+
+// RecurringExpenseScheduler posts due recurring expenses and releases due
+// prepaid slices in the background.
+type RecurringExpenseScheduler struct {
+	svc      *RecurringExpenseService
+	interval time.Duration
+	logger   *logutil.Logger
+	tickerLoop
+}
+
+// NewRecurringExpenseScheduler creates a scheduler with the given interval.
+func NewRecurringExpenseScheduler(svc *RecurringExpenseService, interval time.Duration) *RecurringExpenseScheduler {
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+	return &RecurringExpenseScheduler{
+		svc:      svc,
+		interval: interval,
+		logger:   logutil.NewLogger("recurring-expense-scheduler", logutil.INFO),
+	}
+}
+
+// Start launches the scheduler loop.
+func (s *RecurringExpenseScheduler) Start(ctx context.Context) {
+	if s == nil {
+		return
+	}
+
+	ctx = logutil.WithNewCorrelationID(ctx)
+	s.logger.Info(ctx, "Start", "Starting recurring expense scheduler", map[string]interface{}{
+		"interval": s.interval,
+	})
+
+	s.tickerLoop.run(ctx, s.interval, s.run)
+}
+
+func (s *RecurringExpenseScheduler) run(ctx context.Context) {
+	runCtx := logutil.WithNewCorrelationID(ctx)
+
+	posted, err := s.svc.RunDue(runCtx)
+	if err != nil {
+		s.logger.Error(runCtx, "RunDue", "Failed to post recurring expenses", err)
+	}
+	released, err := s.svc.PostDueRecognitions(runCtx)
+	if err != nil {
+		s.logger.Error(runCtx, "PostDueRecognitions", "Failed to release prepaid expenses", err)
+	}
+	if posted > 0 || released > 0 {
+		s.logger.Info(runCtx, "Run", "Processed recurring expenses", map[string]interface{}{
+			"posted":   posted,
+			"released": released,
+		})
+	}
+}
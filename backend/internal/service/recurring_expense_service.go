@@ -0,0 +1,538 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/logutil"
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
+	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
+	"github.com/ramadhan22/dropship-erp/backend/internal/schedule"
+)
+
+var recurringLog = logutil.NewLogger("recurring-expense-service", logutil.INFO)
+
+var (
+	// ErrInvalidRecurringExpense is wrapped by validation errors so callers
+	// can tell bad input apart from storage failures.
+	ErrInvalidRecurringExpense = errors.New("invalid recurring expense")
+	// ErrNothingToSettle is returned when an accrued template has no unpaid
+	// runs.
+	ErrNothingToSettle = errors.New("no accrued runs to settle")
+	// ErrRecurringExpenseOpen is returned when deleting a template whose
+	// prepaid or accrued balance has not been cleared yet.
+	ErrRecurringExpenseOpen = errors.New("recurring expense has an open balance")
+)
+
+// maxCatchUpRuns bounds how many missed occurrences of one template a
+// single RunDue call posts; the rest follow on the next tick.
+const maxCatchUpRuns = 100
+
+// RecurringExpenseStore persists recurring expense templates, their runs and
+// prepaid recognitions.
+type RecurringExpenseStore interface {
+	Create(ctx context.Context, e *models.RecurringExpense) error
+	Update(ctx context.Context, e *models.RecurringExpense) error
+	Delete(ctx context.Context, id int64) error
+	Get(ctx context.Context, id int64) (*models.RecurringExpense, error)
+	List(ctx context.Context) ([]models.RecurringExpense, error)
+	ListDue(ctx context.Context, now time.Time) ([]models.RecurringExpense, error)
+	MarkRun(ctx context.Context, id int64, ranAt time.Time, next *time.Time) error
+	InsertRun(ctx context.Context, run *models.RecurringExpenseRun) (bool, error)
+	SetRunPosting(ctx context.Context, run *models.RecurringExpenseRun) error
+	ListRuns(ctx context.Context, id int64, limit, offset int) ([]models.RecurringExpenseRun, error)
+	ListUnsettledRuns(ctx context.Context, id int64) ([]models.RecurringExpenseRun, error)
+	SettleRuns(ctx context.Context, runIDs []int64, expenseID string) error
+	InsertRecognitions(ctx context.Context, recs []models.RecurringExpenseRecognition) error
+	ListDueRecognitions(ctx context.Context, now time.Time) ([]models.RecurringExpenseRecognition, error)
+	CountUnpostedRecognitions(ctx context.Context, id int64) (int, error)
+	MarkRecognitionsPosted(ctx context.Context, ids []int64, journalID int64) error
+}
+
+// RecurringExpenseService manages recurring expense templates and turns due
+// runs into expenses and journals.
+//
+// In cash mode every run is an ordinary expense. In prepaid mode the
+// payment is booked to the deferral (prepaid) account and released to the
+// expense accounts in SpreadMonths monthly slices. In accrued mode every run
+// debits the expense accounts against the deferral (accrued liability)
+// account, and SettleAccruals books the payment that clears it.
+type RecurringExpenseService struct {
+	db          *sqlx.DB
+	repo        RecurringExpenseStore
+	expenseRepo ExpenseWriter
+	journalRepo ExpenseJournalRepo
+	cache       Cache
+	now         func() time.Time
+}
+
+// NewRecurringExpenseService constructs a RecurringExpenseService. When db
+// is set each run is posted in its own transaction.
+func NewRecurringExpenseService(db *sqlx.DB, repo RecurringExpenseStore, er ExpenseWriter, jr ExpenseJournalRepo) *RecurringExpenseService {
+	return &RecurringExpenseService{db: db, repo: repo, expenseRepo: er, journalRepo: jr, now: time.Now}
+}
+
+// SetCache enables invalidation of cached reports after journals are posted.
+func (s *RecurringExpenseService) SetCache(c Cache) {
+	s.cache = c
+}
+
+// CreateTemplate validates t, schedules its first run and stores it.
+func (s *RecurringExpenseService) CreateTemplate(ctx context.Context, t *models.RecurringExpense) error {
+	if err := s.prepare(t, time.Time{}); err != nil {
+		return err
+	}
+	return s.repo.Create(ctx, t)
+}
+
+// UpdateTemplate validates t and reschedules it. A template that has run
+// before is never rescheduled into the past, so editing it does not repost
+// earlier periods.
+func (s *RecurringExpenseService) UpdateTemplate(ctx context.Context, t *models.RecurringExpense) error {
+	old, err := s.repo.Get(ctx, t.ID)
+	if err != nil {
+		return err
+	}
+	var after time.Time
+	if old.LastRunAt != nil {
+		after = s.now()
+	}
+	t.LastRunAt = old.LastRunAt
+	if err := s.prepare(t, after); err != nil {
+		return err
+	}
+	return s.repo.Update(ctx, t)
+}
+
+// DeleteTemplate removes a template and its run history. Expenses and
+// journals it posted are kept. Deleting the runs would lose track of the
+// prepaid slices still to be released and the accruals still to be paid, so
+// a template with either is refused with ErrRecurringExpenseOpen; disable it
+// instead.
+func (s *RecurringExpenseService) DeleteTemplate(ctx context.Context, id int64) error {
+	return s.inTx(ctx, func(repo RecurringExpenseStore, _ ExpenseWriter, _ ExpenseJournalRepo) error {
+		unsettled, err := repo.ListUnsettledRuns(ctx, id)
+		if err != nil {
+			return err
+		}
+		unposted, err := repo.CountUnpostedRecognitions(ctx, id)
+		if err != nil {
+			return err
+		}
+		if len(unsettled) > 0 || unposted > 0 {
+			return fmt.Errorf("%w: %d unsettled accrued runs and %d unreleased prepaid slices, disable it instead",
+				ErrRecurringExpenseOpen, len(unsettled), unposted)
+		}
+		return repo.Delete(ctx, id)
+	})
+}
+
+// GetTemplate returns a template, or nil when it does not exist.
+func (s *RecurringExpenseService) GetTemplate(ctx context.Context, id int64) (*models.RecurringExpense, error) {
+	t, err := s.repo.Get(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return t, err
+}
+
+// ListTemplates returns all templates.
+func (s *RecurringExpenseService) ListTemplates(ctx context.Context) ([]models.RecurringExpense, error) {
+	return s.repo.List(ctx)
+}
+
+// ListRuns returns the run history of a template, newest first.
+func (s *RecurringExpenseService) ListRuns(ctx context.Context, id int64, limit, offset int) ([]models.RecurringExpenseRun, error) {
+	return s.repo.ListRuns(ctx, id, limit, offset)
+}
+
+// prepare validates t and sets its next run to the first occurrence at or
+// after its start date and strictly after after.
+func (s *RecurringExpenseService) prepare(t *models.RecurringExpense, after time.Time) error {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s", ErrInvalidRecurringExpense, fmt.Sprintf(format, args...))
+	}
+	t.Name = strings.TrimSpace(t.Name)
+	if t.Name == "" {
+		return invalid("name is required")
+	}
+	sched, err := schedule.Parse(t.Schedule)
+	if err != nil {
+		return invalid("%v", err)
+	}
+	if t.AssetAccountID == 0 {
+		return invalid("asset account is required")
+	}
+	if len(t.Lines) == 0 {
+		return invalid("at least one line is required")
+	}
+	for _, l := range t.Lines {
+		if l.AccountID == 0 || l.Amount <= 0 {
+			return invalid("every line needs an account and a positive amount")
+		}
+	}
+	if t.Mode == "" {
+		t.Mode = models.RecurringCash
+	}
+	switch t.Mode {
+	case models.RecurringCash:
+		t.DeferralAccountID = nil
+		t.SpreadMonths = 0
+	case models.RecurringPrepaid, models.RecurringAccrued:
+		if t.DeferralAccountID == nil || *t.DeferralAccountID == 0 {
+			return invalid("%s mode needs a deferral account", t.Mode)
+		}
+		if *t.DeferralAccountID == t.AssetAccountID {
+			return invalid("deferral account must differ from the asset account")
+		}
+		if t.Mode == models.RecurringAccrued {
+			t.SpreadMonths = 0
+		} else if t.SpreadMonths == 0 {
+			t.SpreadMonths = 12
+		}
+		if t.SpreadMonths < 0 || t.SpreadMonths > 120 {
+			return invalid("spread months must be between 1 and 120")
+		}
+	default:
+		return invalid("unknown mode %q", t.Mode)
+	}
+	if t.StartDate.IsZero() {
+		t.StartDate = s.now()
+	}
+	if t.EndDate != nil && t.EndDate.Before(t.StartDate) {
+		return invalid("end date is before start date")
+	}
+
+	t.NextRunAt = nil
+	if t.Enabled {
+		from := t.StartDate.Add(-time.Minute)
+		if after.After(from) {
+			from = after
+		}
+		t.NextRunAt = nextOccurrence(sched, t, from)
+	}
+	return nil
+}
+
+// nextOccurrence returns the first scheduled time after from, or nil once
+// the template has ended.
+func nextOccurrence(sched *schedule.Schedule, t *models.RecurringExpense, from time.Time) *time.Time {
+	next := sched.Next(from)
+	if next.IsZero() || (t.EndDate != nil && next.After(*t.EndDate)) {
+		return nil
+	}
+	return &next
+}
+
+// RunDue posts every occurrence that has come due, including ones missed
+// while the server was down, and returns how many were posted. A template
+// that fails is logged and skipped; its error is returned along with the
+// others once every template has been tried.
+func (s *RecurringExpenseService) RunDue(ctx context.Context) (int, error) {
+	now := s.now()
+	due, err := s.repo.ListDue(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	var errs []error
+	for i := range due {
+		t := &due[i]
+		posted, err := s.runTemplate(ctx, t, now)
+		n += posted
+		if err != nil {
+			recurringLog.Error(logutil.WithShop(ctx, t.Store), "RunDue", "Failed to post recurring expense", err, map[string]interface{}{
+				"recurring_expense_id": t.ID,
+			})
+			errs = append(errs, err)
+		}
+	}
+	if n > 0 {
+		invalidateCache(ctx, s.cache, journalWriteTags...)
+	}
+	return n, errors.Join(errs...)
+}
+
+// runTemplate posts the missed occurrences of t up to now and returns how
+// many it posted.
+func (s *RecurringExpenseService) runTemplate(ctx context.Context, t *models.RecurringExpense, now time.Time) (int, error) {
+	sched, err := schedule.Parse(t.Schedule)
+	if err != nil {
+		return 0, fmt.Errorf("recurring expense %d: %w", t.ID, err)
+	}
+	n := 0
+	for runs := 0; t.NextRunAt != nil && !t.NextRunAt.After(now) && runs < maxCatchUpRuns; runs++ {
+		at := *t.NextRunAt
+		next := nextOccurrence(sched, t, at)
+		posted := false
+		err := s.inTx(ctx, func(repo RecurringExpenseStore, er ExpenseWriter, jr ExpenseJournalRepo) error {
+			var err error
+			if posted, err = s.materialise(ctx, repo, er, jr, t, at); err != nil {
+				return err
+			}
+			return repo.MarkRun(ctx, t.ID, now, next)
+		})
+		if err != nil {
+			return n, fmt.Errorf("recurring expense %d at %s: %w", t.ID, at.Format(time.RFC3339), err)
+		}
+		if posted {
+			n++
+		}
+		t.NextRunAt = next
+	}
+	return n, nil
+}
+
+// materialise posts the occurrence of t at at. It returns false when the
+// occurrence had been posted already.
+func (s *RecurringExpenseService) materialise(ctx context.Context, repo RecurringExpenseStore, er ExpenseWriter, jr ExpenseJournalRepo, t *models.RecurringExpense, at time.Time) (bool, error) {
+	run := &models.RecurringExpenseRun{RecurringExpenseID: t.ID, ScheduledFor: at, Mode: t.Mode, Amount: t.Total()}
+	inserted, err := repo.InsertRun(ctx, run)
+	if err != nil || !inserted {
+		return false, err
+	}
+	desc := fmt.Sprintf("%s (%s)", templateLabel(t), at.Format("2006-01"))
+
+	switch t.Mode {
+	case models.RecurringCash, models.RecurringPrepaid:
+		e := &models.Expense{
+			ID:             uuid.NewString(),
+			Date:           at,
+			Description:    desc,
+			AssetAccountID: t.AssetAccountID,
+			Store:          t.Store,
+		}
+		if t.Mode == models.RecurringCash {
+			for _, l := range t.Lines {
+				e.Lines = append(e.Lines, models.ExpenseLine{AccountID: l.AccountID, Amount: l.Amount})
+			}
+		} else {
+			e.Lines = []models.ExpenseLine{{AccountID: *t.DeferralAccountID, Amount: run.Amount}}
+		}
+		if err := postExpense(ctx, er, jr, e); err != nil {
+			return false, err
+		}
+		run.ExpenseID = &e.ID
+		if t.Mode == models.RecurringPrepaid {
+			if err := repo.InsertRecognitions(ctx, prepaidRecognitions(t, run)); err != nil {
+				return false, err
+			}
+		}
+	case models.RecurringAccrued:
+		je := &models.JournalEntry{
+			EntryDate:    at,
+			Description:  &desc,
+			SourceType:   "expense_accrual",
+			SourceID:     fmt.Sprintf("%d", run.ID),
+			ShopUsername: t.Store,
+			Store:        t.Store,
+			CreatedAt:    time.Now(),
+		}
+		jid, err := jr.CreateJournalEntry(ctx, je)
+		if err != nil {
+			return false, err
+		}
+		lines := make([]models.JournalLine, 0, len(t.Lines)+1)
+		for _, l := range t.Lines {
+			lines = append(lines, models.JournalLine{JournalID: jid, AccountID: l.AccountID, IsDebit: true, Amount: l.Amount, Memo: &desc})
+		}
+		lines = append(lines, models.JournalLine{JournalID: jid, AccountID: *t.DeferralAccountID, IsDebit: false, Amount: run.Amount, Memo: &desc})
+		if err := jr.InsertJournalLines(ctx, lines); err != nil {
+			return false, err
+		}
+		run.JournalID = &jid
+	}
+	return true, repo.SetRunPosting(ctx, run)
+}
+
+// prepaidRecognitions splits every line of t into SpreadMonths monthly
+// slices starting at the run date.
+func prepaidRecognitions(t *models.RecurringExpense, run *models.RecurringExpenseRun) []models.RecurringExpenseRecognition {
+	var recs []models.RecurringExpenseRecognition
+	n := t.SpreadMonths
+	for _, l := range t.Lines {
+		for k, part := range l.Amount.Split(n) {
+			if part == 0 {
+				continue
+			}
+			recs = append(recs, models.RecurringExpenseRecognition{
+				RunID:             run.ID,
+				DueDate:           addMonths(run.ScheduledFor, k),
+				AccountID:         l.AccountID,
+				DeferralAccountID: *t.DeferralAccountID,
+				Store:             t.Store,
+				Description:       fmt.Sprintf("Amortisasi %s (%d/%d)", templateLabel(t), k+1, n),
+				Amount:            part,
+			})
+		}
+	}
+	return recs
+}
+
+// PostDueRecognitions releases prepaid slices that have come due, one
+// journal per run and month, and returns how many journals were posted. A
+// group that fails is logged and skipped; its error is returned along with
+// the others once every group has been tried.
+func (s *RecurringExpenseService) PostDueRecognitions(ctx context.Context) (int, error) {
+	due, err := s.repo.ListDueRecognitions(ctx, s.now())
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	var errs []error
+	for start := 0; start < len(due); {
+		end := start + 1
+		for end < len(due) && due[end].RunID == due[start].RunID && due[end].DueDate.Equal(due[start].DueDate) {
+			end++
+		}
+		group := due[start:end]
+		err := s.inTx(ctx, func(repo RecurringExpenseStore, _ ExpenseWriter, jr ExpenseJournalRepo) error {
+			return postRecognitions(ctx, repo, jr, group)
+		})
+		start = end
+		if err != nil {
+			recurringLog.Error(logutil.WithShop(ctx, group[0].Store), "PostDueRecognitions", "Failed to release prepaid expense", err, map[string]interface{}{
+				"run_id":   group[0].RunID,
+				"due_date": group[0].DueDate,
+			})
+			errs = append(errs, fmt.Errorf("recurring expense run %d: %w", group[0].RunID, err))
+			continue
+		}
+		n++
+	}
+	if n > 0 {
+		invalidateCache(ctx, s.cache, journalWriteTags...)
+	}
+	return n, errors.Join(errs...)
+}
+
+// postRecognitions posts one journal debiting the expense accounts of group
+// and crediting the prepaid accounts they were deferred to.
+func postRecognitions(ctx context.Context, repo RecurringExpenseStore, jr ExpenseJournalRepo, group []models.RecurringExpenseRecognition) error {
+	first := group[0]
+	je := &models.JournalEntry{
+		EntryDate:    first.DueDate,
+		Description:  &first.Description,
+		SourceType:   "expense_amortization",
+		SourceID:     fmt.Sprintf("%d-%s", first.RunID, first.DueDate.Format("2006-01-02")),
+		ShopUsername: first.Store,
+		Store:        first.Store,
+		CreatedAt:    time.Now(),
+	}
+	jid, err := jr.CreateJournalEntry(ctx, je)
+	if err != nil {
+		return err
+	}
+	credits := map[int64]money.Amount{}
+	var order []int64
+	lines := make([]models.JournalLine, 0, len(group)+1)
+	ids := make([]int64, 0, len(group))
+	for _, r := range group {
+		lines = append(lines, models.JournalLine{JournalID: jid, AccountID: r.AccountID, IsDebit: true, Amount: r.Amount, Memo: &first.Description})
+		if _, ok := credits[r.DeferralAccountID]; !ok {
+			order = append(order, r.DeferralAccountID)
+		}
+		credits[r.DeferralAccountID] += r.Amount
+		ids = append(ids, r.ID)
+	}
+	for _, acc := range order {
+		lines = append(lines, models.JournalLine{JournalID: jid, AccountID: acc, IsDebit: false, Amount: credits[acc], Memo: &first.Description})
+	}
+	if err := jr.InsertJournalLines(ctx, lines); err != nil {
+		return err
+	}
+	return repo.MarkRecognitionsPosted(ctx, ids, jid)
+}
+
+// SettleAccruals books the payment of every unpaid run of an accrued
+// template: the accrued liability is debited and the asset account
+// credited. A zero date uses today.
+func (s *RecurringExpenseService) SettleAccruals(ctx context.Context, id int64, date time.Time) (*models.Expense, error) {
+	t, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if t.Mode != models.RecurringAccrued {
+		return nil, fmt.Errorf("%w: only accrued templates can be settled", ErrInvalidRecurringExpense)
+	}
+	if date.IsZero() {
+		date = s.now()
+	}
+	var e *models.Expense
+	err = s.inTx(ctx, func(repo RecurringExpenseStore, er ExpenseWriter, jr ExpenseJournalRepo) error {
+		runs, err := repo.ListUnsettledRuns(ctx, id)
+		if err != nil {
+			return err
+		}
+		if len(runs) == 0 {
+			return ErrNothingToSettle
+		}
+		var total money.Amount
+		ids := make([]int64, len(runs))
+		for i, r := range runs {
+			total += r.Amount
+			ids[i] = r.ID
+		}
+		e = &models.Expense{
+			ID:             uuid.NewString(),
+			Date:           date,
+			Description:    fmt.Sprintf("Pembayaran %s (%d periode)", templateLabel(t), len(runs)),
+			AssetAccountID: t.AssetAccountID,
+			Store:          t.Store,
+			Lines:          []models.ExpenseLine{{AccountID: *t.DeferralAccountID, Amount: total}},
+		}
+		if err := postExpense(ctx, er, jr, e); err != nil {
+			return err
+		}
+		return repo.SettleRuns(ctx, ids, e.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	invalidateCache(ctx, s.cache, journalWriteTags...)
+	return e, nil
+}
+
+// inTx runs fn with repositories bound to a new transaction, or to the
+// service's own repositories when it has no database.
+func (s *RecurringExpenseService) inTx(ctx context.Context, fn func(RecurringExpenseStore, ExpenseWriter, ExpenseJournalRepo) error) error {
+	if s.db == nil {
+		return fn(s.repo, s.expenseRepo, s.journalRepo)
+	}
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := fn(repository.NewRecurringExpenseRepo(tx), repository.NewExpenseRepo(tx), repository.NewJournalRepo(tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func templateLabel(t *models.RecurringExpense) string {
+	if d := strings.TrimSpace(t.Description); d != "" {
+		return d
+	}
+	return t.Name
+}
+
+// addMonths adds n calendar months to t, clamping to the last day of the
+// target month so a schedule on the 31st stays in its month.
+func addMonths(t time.Time, n int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(n), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	last := first.AddDate(0, 1, -1).Day()
+	day := t.Day()
+	if day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
@@ -0,0 +1,390 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
+)
+
+type fakeRecurringStore struct {
+	templates map[int64]*models.RecurringExpense
+	runs      []*models.RecurringExpenseRun
+	recs      []*models.RecurringExpenseRecognition
+	nextID    int64
+	failRun   int64 // run whose recognitions fail to post
+}
+
+func newFakeRecurringStore() *fakeRecurringStore {
+	return &fakeRecurringStore{templates: map[int64]*models.RecurringExpense{}}
+}
+
+func (f *fakeRecurringStore) Create(ctx context.Context, e *models.RecurringExpense) error {
+	f.nextID++
+	e.ID = f.nextID
+	cp := *e
+	f.templates[e.ID] = &cp
+	return nil
+}
+func (f *fakeRecurringStore) Update(ctx context.Context, e *models.RecurringExpense) error {
+	cp := *e
+	f.templates[e.ID] = &cp
+	return nil
+}
+func (f *fakeRecurringStore) Delete(ctx context.Context, id int64) error {
+	delete(f.templates, id)
+	return nil
+}
+func (f *fakeRecurringStore) Get(ctx context.Context, id int64) (*models.RecurringExpense, error) {
+	t, ok := f.templates[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	cp := *t
+	return &cp, nil
+}
+func (f *fakeRecurringStore) List(ctx context.Context) ([]models.RecurringExpense, error) {
+	var out []models.RecurringExpense
+	for _, t := range f.templates {
+		out = append(out, *t)
+	}
+	return out, nil
+}
+func (f *fakeRecurringStore) ListDue(ctx context.Context, now time.Time) ([]models.RecurringExpense, error) {
+	var out []models.RecurringExpense
+	for _, t := range f.templates {
+		if t.Enabled && t.NextRunAt != nil && !t.NextRunAt.After(now) {
+			out = append(out, *t)
+		}
+	}
+	return out, nil
+}
+func (f *fakeRecurringStore) MarkRun(ctx context.Context, id int64, ranAt time.Time, next *time.Time) error {
+	f.templates[id].LastRunAt = &ranAt
+	f.templates[id].NextRunAt = next
+	return nil
+}
+func (f *fakeRecurringStore) InsertRun(ctx context.Context, run *models.RecurringExpenseRun) (bool, error) {
+	for _, r := range f.runs {
+		if r.RecurringExpenseID == run.RecurringExpenseID && r.ScheduledFor.Equal(run.ScheduledFor) {
+			return false, nil
+		}
+	}
+	f.nextID++
+	run.ID = f.nextID
+	cp := *run
+	f.runs = append(f.runs, &cp)
+	return true, nil
+}
+func (f *fakeRecurringStore) SetRunPosting(ctx context.Context, run *models.RecurringExpenseRun) error {
+	for _, r := range f.runs {
+		if r.ID == run.ID {
+			r.ExpenseID, r.JournalID = run.ExpenseID, run.JournalID
+		}
+	}
+	return nil
+}
+func (f *fakeRecurringStore) ListRuns(ctx context.Context, id int64, limit, offset int) ([]models.RecurringExpenseRun, error) {
+	var out []models.RecurringExpenseRun
+	for _, r := range f.runs {
+		if r.RecurringExpenseID == id {
+			out = append(out, *r)
+		}
+	}
+	return out, nil
+}
+func (f *fakeRecurringStore) ListUnsettledRuns(ctx context.Context, id int64) ([]models.RecurringExpenseRun, error) {
+	var out []models.RecurringExpenseRun
+	for _, r := range f.runs {
+		if r.RecurringExpenseID == id && r.Mode == models.RecurringAccrued && r.SettledExpenseID == nil {
+			out = append(out, *r)
+		}
+	}
+	return out, nil
+}
+func (f *fakeRecurringStore) SettleRuns(ctx context.Context, runIDs []int64, expenseID string) error {
+	for _, id := range runIDs {
+		for _, r := range f.runs {
+			if r.ID == id {
+				r.SettledExpenseID = &expenseID
+			}
+		}
+	}
+	return nil
+}
+func (f *fakeRecurringStore) InsertRecognitions(ctx context.Context, recs []models.RecurringExpenseRecognition) error {
+	for i := range recs {
+		f.nextID++
+		recs[i].ID = f.nextID
+		cp := recs[i]
+		f.recs = append(f.recs, &cp)
+	}
+	return nil
+}
+func (f *fakeRecurringStore) ListDueRecognitions(ctx context.Context, now time.Time) ([]models.RecurringExpenseRecognition, error) {
+	var out []models.RecurringExpenseRecognition
+	for _, r := range f.recs {
+		if r.JournalID == nil && !r.DueDate.After(now) {
+			out = append(out, *r)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].DueDate.Before(out[j].DueDate) })
+	return out, nil
+}
+func (f *fakeRecurringStore) CountUnpostedRecognitions(ctx context.Context, id int64) (int, error) {
+	n := 0
+	for _, c := range f.recs {
+		for _, r := range f.runs {
+			if r.ID == c.RunID && r.RecurringExpenseID == id && c.JournalID == nil {
+				n++
+			}
+		}
+	}
+	return n, nil
+}
+func (f *fakeRecurringStore) MarkRecognitionsPosted(ctx context.Context, ids []int64, journalID int64) error {
+	for _, id := range ids {
+		for _, r := range f.recs {
+			if r.ID == id && r.RunID == f.failRun {
+				return errors.New("mark posted failed")
+			}
+		}
+	}
+	for _, id := range ids {
+		for _, r := range f.recs {
+			if r.ID == id {
+				jid := journalID
+				r.JournalID = &jid
+			}
+		}
+	}
+	return nil
+}
+
+type fakeExpenseWriter struct{ expenses []models.Expense }
+
+func (f *fakeExpenseWriter) Create(ctx context.Context, e *models.Expense) error {
+	f.expenses = append(f.expenses, *e)
+	return nil
+}
+
+func newRecurringTestService(now time.Time) (*RecurringExpenseService, *fakeRecurringStore, *fakeExpenseWriter, *fakeIntegrityJournal) {
+	store := newFakeRecurringStore()
+	ew := &fakeExpenseWriter{}
+	jr := &fakeIntegrityJournal{entries: map[int64]*models.JournalEntry{}}
+	svc := NewRecurringExpenseService(nil, store, ew, jr)
+	svc.now = func() time.Time { return now }
+	return svc, store, ew, jr
+}
+
+func TestRecurringCashCatchesUpMissedMonthsOnce(t *testing.T) {
+	now := time.Date(2026, 3, 15, 9, 0, 0, 0, time.UTC)
+	svc, store, ew, jr := newRecurringTestService(now)
+	tmpl := &models.RecurringExpense{
+		Name: "Sewa gudang", Schedule: "0 0 1 * *", AssetAccountID: 11001, Store: "TokoA", Enabled: true,
+		StartDate: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		Lines:     []models.RecurringExpenseLine{{AccountID: 52001, Amount: money.New(3000000)}},
+	}
+	if err := svc.CreateTemplate(context.Background(), tmpl); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if !tmpl.NextRunAt.Equal(tmpl.StartDate) {
+		t.Fatalf("first run should be the start date, got %v", tmpl.NextRunAt)
+	}
+
+	n, err := svc.RunDue(context.Background())
+	if err != nil || n != 3 {
+		t.Fatalf("RunDue = %d, %v; want 3 runs", n, err)
+	}
+	if len(ew.expenses) != 3 || ew.expenses[0].Description != "Sewa gudang (2026-01)" || ew.expenses[2].Store != "TokoA" {
+		t.Fatalf("unexpected expenses %+v", ew.expenses)
+	}
+	for _, e := range jr.entries {
+		if e.ShopUsername != "TokoA" || e.SourceType != "expense" {
+			t.Fatalf("journal not booked to the store: %+v", e)
+		}
+	}
+	if next := store.templates[tmpl.ID].NextRunAt; !next.Equal(time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("next run = %v", next)
+	}
+
+	if n, _ := svc.RunDue(context.Background()); n != 0 || len(ew.expenses) != 3 {
+		t.Fatalf("second RunDue posted %d more", n)
+	}
+}
+
+func TestRecurringRunDueContinuesPastFailingTemplate(t *testing.T) {
+	now := time.Date(2026, 3, 15, 9, 0, 0, 0, time.UTC)
+	svc, store, ew, _ := newRecurringTestService(now)
+	for _, name := range []string{"Sewa gudang", "Internet"} {
+		tmpl := &models.RecurringExpense{
+			Name: name, Schedule: "0 0 1 * *", AssetAccountID: 11001, Store: "TokoA", Enabled: true,
+			StartDate: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+			Lines:     []models.RecurringExpenseLine{{AccountID: 52001, Amount: money.New(500000)}},
+		}
+		if err := svc.CreateTemplate(context.Background(), tmpl); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+	store.templates[1].Schedule = "not a schedule"
+
+	n, err := svc.RunDue(context.Background())
+	if err == nil || n != 1 {
+		t.Fatalf("RunDue = %d, %v; want the good template posted and an error", n, err)
+	}
+	if len(ew.expenses) != 1 || ew.expenses[0].Description != "Internet (2026-03)" {
+		t.Fatalf("unexpected expenses %+v", ew.expenses)
+	}
+}
+
+func TestRecurringPrepaidSpreadsPaymentMonthly(t *testing.T) {
+	now := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	svc, store, ew, jr := newRecurringTestService(now)
+	prepaid := int64(11401)
+	tmpl := &models.RecurringExpense{
+		Name: "Langganan SaaS", Schedule: "@yearly", AssetAccountID: 11001, Mode: models.RecurringPrepaid,
+		DeferralAccountID: &prepaid, Enabled: true, StartDate: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		Lines: []models.RecurringExpenseLine{{AccountID: 52010, Amount: money.New(1000000)}},
+	}
+	if err := svc.CreateTemplate(context.Background(), tmpl); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if tmpl.SpreadMonths != 12 {
+		t.Fatalf("prepaid should default to 12 months, got %d", tmpl.SpreadMonths)
+	}
+	if n, err := svc.RunDue(context.Background()); err != nil || n != 1 {
+		t.Fatalf("RunDue = %d, %v", n, err)
+	}
+	if l := ew.expenses[0].Lines; len(l) != 1 || l[0].AccountID != prepaid || l[0].Amount != money.New(1000000) {
+		t.Fatalf("payment should go to the prepaid account: %+v", l)
+	}
+	if len(store.recs) != 12 || store.recs[0].Amount != money.MustParse("83333.34") || store.recs[11].Amount != money.MustParse("83333.33") {
+		t.Fatalf("unexpected slices %+v", store.recs)
+	}
+
+	jr.lines = nil
+	n, err := svc.PostDueRecognitions(context.Background())
+	if err != nil || n != 3 {
+		t.Fatalf("PostDueRecognitions = %d, %v; want Jan-Mar", n, err)
+	}
+	var debit, credit money.Amount
+	for _, l := range jr.lines {
+		if l.IsDebit {
+			debit += l.Amount
+		} else if l.AccountID == prepaid {
+			credit += l.Amount
+		}
+	}
+	if debit != credit || debit != money.MustParse("250000.02") {
+		t.Fatalf("released debit %s credit %s", debit, credit)
+	}
+	if n, _ := svc.PostDueRecognitions(context.Background()); n != 0 {
+		t.Fatalf("slices posted twice")
+	}
+	if err := svc.DeleteTemplate(context.Background(), tmpl.ID); !errors.Is(err, ErrRecurringExpenseOpen) {
+		t.Fatalf("deleting with Apr-Dec unreleased should be refused, got %v", err)
+	}
+}
+
+func TestRecurringPostDueRecognitionsContinuesPastFailingRun(t *testing.T) {
+	now := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	svc, store, _, _ := newRecurringTestService(now)
+	prepaid := int64(11401)
+	for _, name := range []string{"Langganan SaaS", "Asuransi"} {
+		tmpl := &models.RecurringExpense{
+			Name: name, Schedule: "@yearly", AssetAccountID: 11001, Mode: models.RecurringPrepaid,
+			DeferralAccountID: &prepaid, Enabled: true, StartDate: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			Lines: []models.RecurringExpenseLine{{AccountID: 52010, Amount: money.New(1200000)}},
+		}
+		if err := svc.CreateTemplate(context.Background(), tmpl); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+	if n, err := svc.RunDue(context.Background()); err != nil || n != 2 {
+		t.Fatalf("RunDue = %d, %v", n, err)
+	}
+	store.failRun = store.runs[0].ID
+
+	n, err := svc.PostDueRecognitions(context.Background())
+	if err == nil || n != 3 {
+		t.Fatalf("PostDueRecognitions = %d, %v; want the other run's Jan-Mar posted and an error", n, err)
+	}
+}
+
+func TestRecurringAccruedSettlesUnpaidRuns(t *testing.T) {
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	svc, store, ew, jr := newRecurringTestService(now)
+	accrued := int64(21005)
+	tmpl := &models.RecurringExpense{
+		Name: "Gaji staf", Schedule: "0 0 28 * *", AssetAccountID: 11001, Mode: models.RecurringAccrued,
+		DeferralAccountID: &accrued, SpreadMonths: 6, Enabled: true,
+		StartDate: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		Lines:     []models.RecurringExpenseLine{{AccountID: 53001, Amount: money.New(5000000)}},
+	}
+	if err := svc.CreateTemplate(context.Background(), tmpl); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if n, err := svc.RunDue(context.Background()); err != nil || n != 3 {
+		t.Fatalf("RunDue = %d, %v", n, err)
+	}
+	if len(ew.expenses) != 0 || len(jr.entries) != 3 || store.recs != nil {
+		t.Fatalf("accrued runs should only post accrual journals")
+	}
+
+	e, err := svc.SettleAccruals(context.Background(), tmpl.ID, time.Time{})
+	if err != nil {
+		t.Fatalf("settle: %v", err)
+	}
+	if e.Amount != money.New(15000000) || e.Lines[0].AccountID != accrued || !e.Date.Equal(now) {
+		t.Fatalf("unexpected settlement %+v", e)
+	}
+	if _, err := svc.SettleAccruals(context.Background(), tmpl.ID, time.Time{}); !errors.Is(err, ErrNothingToSettle) {
+		t.Fatalf("second settle err = %v", err)
+	}
+}
+
+func TestRecurringTemplateValidationAndReschedule(t *testing.T) {
+	now := time.Date(2026, 5, 20, 0, 0, 0, 0, time.UTC)
+	svc, store, _, _ := newRecurringTestService(now)
+	bad := &models.RecurringExpense{
+		Name: "x", Schedule: "@monthly", AssetAccountID: 1, Mode: models.RecurringPrepaid,
+		Lines: []models.RecurringExpenseLine{{AccountID: 2, Amount: money.New(1)}},
+	}
+	if err := svc.CreateTemplate(context.Background(), bad); !errors.Is(err, ErrInvalidRecurringExpense) {
+		t.Fatalf("prepaid without deferral account: %v", err)
+	}
+
+	tmpl := &models.RecurringExpense{
+		Name: "Internet", Schedule: "0 0 5 * *", AssetAccountID: 1, Enabled: true,
+		StartDate: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		Lines:     []models.RecurringExpenseLine{{AccountID: 2, Amount: money.New(500000)}},
+	}
+	if err := svc.CreateTemplate(context.Background(), tmpl); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.RunDue(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	tmpl.Schedule = "0 0 10 * *"
+	if err := svc.UpdateTemplate(context.Background(), tmpl); err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, 6, 10, 0, 0, 0, 0, time.UTC); !store.templates[tmpl.ID].NextRunAt.Equal(want) {
+		t.Fatalf("edited template rescheduled to %v, want %v", store.templates[tmpl.ID].NextRunAt, want)
+	}
+}
+
+func TestAddMonthsClampsToMonthEnd(t *testing.T) {
+	jan31 := time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)
+	if got := addMonths(jan31, 1); !got.Equal(time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("Jan 31 + 1 month = %v", got)
+	}
+	if got := addMonths(jan31, 14); !got.Equal(time.Date(2027, 3, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("Jan 31 + 14 months = %v", got)
+	}
+}
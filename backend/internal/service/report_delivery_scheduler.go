@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/logutil"
+)
+
+// ReportDeliveryScheduler runs due report subscriptions and pending retries
+// in the background.
+type ReportDeliveryScheduler struct {
+	svc      *ReportDeliveryService
+	interval time.Duration
+	logger   *logutil.Logger
+	tickerLoop
+}
+
+// NewReportDeliveryScheduler creates a scheduler with the given interval.
+func NewReportDeliveryScheduler(svc *ReportDeliveryService, interval time.Duration) *ReportDeliveryScheduler {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	return &ReportDeliveryScheduler{
+		svc:      svc,
+		interval: interval,
+		logger:   logutil.NewLogger("report-delivery-scheduler", logutil.INFO),
+	}
+}
+
+// Start launches the scheduler loop.
+func (s *ReportDeliveryScheduler) Start(ctx context.Context) {
+	if s == nil {
+		return
+	}
+
+	ctx = logutil.WithNewCorrelationID(ctx)
+	s.logger.Info(ctx, "Start", "Starting report delivery scheduler", map[string]interface{}{
+		"interval": s.interval,
+	})
+
+	s.tickerLoop.run(ctx, s.interval, s.run)
+}
+
+func (s *ReportDeliveryScheduler) run(ctx context.Context) {
+	runCtx := logutil.WithNewCorrelationID(ctx)
+
+	sent, err := s.svc.RunDue(runCtx)
+	if err != nil {
+		s.logger.Error(runCtx, "RunDue", "Failed to run due report subscriptions", err)
+	}
+	retried, err := s.svc.RetryDue(runCtx)
+	if err != nil {
+		s.logger.Error(runCtx, "RetryDue", "Failed to retry report deliveries", err)
+	}
+	if sent > 0 || retried > 0 {
+		s.logger.Info(runCtx, "Run", "Processed report deliveries", map[string]interface{}{
+			"scheduled": sent,
+			"retried":   retried,
+		})
+	}
+}
@@ -0,0 +1,364 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/export"
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/schedule"
+)
+
+// Relative periods a subscription can report on. They are resolved against
+// the time the delivery was scheduled for, so retries cover the same range.
+const (
+	PeriodPreviousDay   = "previous_day"
+	PeriodPreviousWeek  = "previous_week"
+	PeriodPreviousMonth = "previous_month"
+	PeriodCurrentMonth  = "current_month"
+	PeriodPreviousYear  = "previous_year"
+	PeriodYearToDate    = "year_to_date"
+)
+
+// ErrInvalidSubscription is wrapped by validation errors so callers can tell
+// bad input apart from storage failures.
+var ErrInvalidSubscription = errors.New("invalid report subscription")
+
+// ReportSubscriptionStore persists subscriptions and their delivery history.
+type ReportSubscriptionStore interface {
+	Create(ctx context.Context, s *models.ReportSubscription) error
+	Update(ctx context.Context, s *models.ReportSubscription) error
+	Delete(ctx context.Context, id int64) error
+	Get(ctx context.Context, id int64) (*models.ReportSubscription, error)
+	List(ctx context.Context) ([]models.ReportSubscription, error)
+	ClaimDue(ctx context.Context, now, claimedUntil time.Time) ([]models.ReportSubscription, error)
+	MarkRun(ctx context.Context, id int64, ranAt time.Time, next *time.Time) error
+	InsertDelivery(ctx context.Context, d *models.ReportDelivery) error
+	UpdateDelivery(ctx context.Context, d *models.ReportDelivery) error
+	GetDelivery(ctx context.Context, id int64) (*models.ReportDelivery, error)
+	ListDeliveries(ctx context.Context, subscriptionID int64, limit, offset int) ([]models.ReportDelivery, error)
+	ClaimRetryableDeliveries(ctx context.Context, now, staleBefore time.Time) ([]models.ReportDelivery, error)
+}
+
+// ReportRenderer renders a report into a file.
+type ReportRenderer interface {
+	Export(ctx context.Context, req ReportRequest, format export.Format) ([]byte, string, error)
+}
+
+// deliveryClaimTimeout is how long a run may hold a due subscription or a
+// pending delivery. After it, another run picks them up, which covers runs
+// that crashed mid-delivery.
+const deliveryClaimTimeout = 30 * time.Minute
+
+// ReportDeliveryService runs report subscriptions: it renders each due
+// report, hands it to the subscription's sink and records the outcome.
+// Failed deliveries are retried with exponential backoff until maxAttempts.
+type ReportDeliveryService struct {
+	repo        ReportSubscriptionStore
+	renderer    ReportRenderer
+	sinks       map[string]ReportSink
+	maxAttempts int
+	retryDelay  time.Duration
+	now         func() time.Time
+}
+
+// NewReportDeliveryService constructs a ReportDeliveryService. sinks maps a
+// sink type (SinkEmail, SinkWebhook, SinkDirectory) to its implementation;
+// subscriptions may only use the types present.
+func NewReportDeliveryService(repo ReportSubscriptionStore, renderer ReportRenderer, sinks map[string]ReportSink, maxAttempts int, retryDelay time.Duration) *ReportDeliveryService {
+	if maxAttempts <= 0 {
+		maxAttempts = 5
+	}
+	if retryDelay <= 0 {
+		retryDelay = 5 * time.Minute
+	}
+	return &ReportDeliveryService{
+		repo:        repo,
+		renderer:    renderer,
+		sinks:       sinks,
+		maxAttempts: maxAttempts,
+		retryDelay:  retryDelay,
+		now:         time.Now,
+	}
+}
+
+// CreateSubscription validates s, schedules its first run and stores it.
+func (s *ReportDeliveryService) CreateSubscription(ctx context.Context, sub *models.ReportSubscription) error {
+	if err := s.prepare(sub); err != nil {
+		return err
+	}
+	return s.repo.Create(ctx, sub)
+}
+
+// UpdateSubscription validates s and reschedules its next run.
+func (s *ReportDeliveryService) UpdateSubscription(ctx context.Context, sub *models.ReportSubscription) error {
+	if err := s.prepare(sub); err != nil {
+		return err
+	}
+	return s.repo.Update(ctx, sub)
+}
+
+// DeleteSubscription removes a subscription and its history.
+func (s *ReportDeliveryService) DeleteSubscription(ctx context.Context, id int64) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// GetSubscription returns a subscription, or nil when it does not exist.
+func (s *ReportDeliveryService) GetSubscription(ctx context.Context, id int64) (*models.ReportSubscription, error) {
+	sub, err := s.repo.Get(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return sub, err
+}
+
+// ListSubscriptions returns all subscriptions.
+func (s *ReportDeliveryService) ListSubscriptions(ctx context.Context) ([]models.ReportSubscription, error) {
+	return s.repo.List(ctx)
+}
+
+// ListDeliveries returns the delivery history of a subscription.
+func (s *ReportDeliveryService) ListDeliveries(ctx context.Context, subscriptionID int64, limit, offset int) ([]models.ReportDelivery, error) {
+	return s.repo.ListDeliveries(ctx, subscriptionID, limit, offset)
+}
+
+func (s *ReportDeliveryService) prepare(sub *models.ReportSubscription) error {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s", ErrInvalidSubscription, fmt.Sprintf(format, args...))
+	}
+	sub.Name = strings.TrimSpace(sub.Name)
+	if sub.Name == "" {
+		return invalid("name is required")
+	}
+	if !isExportableReport(sub.Report) {
+		return invalid("unknown report %q", sub.Report)
+	}
+	if sub.Format == "" {
+		sub.Format = string(export.FormatXLSX)
+	}
+	f, err := export.ParseFormat(sub.Format)
+	if err != nil {
+		return invalid("%v", err)
+	}
+	sub.Format = string(f)
+	if sub.Period == "" {
+		sub.Period = PeriodPreviousMonth
+	}
+	if _, _, err := resolvePeriod(sub.Period, s.now()); err != nil {
+		return invalid("%v", err)
+	}
+	sched, err := schedule.Parse(sub.Schedule)
+	if err != nil {
+		return invalid("%v", err)
+	}
+	if _, ok := s.sinks[sub.SinkType]; !ok {
+		return invalid("sink %q is not available", sub.SinkType)
+	}
+	if strings.TrimSpace(sub.SinkTarget) == "" && sub.SinkType != SinkDirectory {
+		return invalid("sink target is required")
+	}
+	sub.NextRunAt = nil
+	if sub.Enabled {
+		if next := sched.Next(s.now()); !next.IsZero() {
+			sub.NextRunAt = &next
+		}
+	}
+	return nil
+}
+
+// RunDue delivers every subscription whose next run has passed and returns
+// how many deliveries were attempted.
+func (s *ReportDeliveryService) RunDue(ctx context.Context) (int, error) {
+	now := s.now()
+	subs, err := s.repo.ClaimDue(ctx, now, now.Add(deliveryClaimTimeout))
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for i := range subs {
+		sub := &subs[i]
+		scheduledFor := *sub.NextRunAt
+		// Advance the schedule before delivering so a crash mid-delivery
+		// does not send the same report twice.
+		var next *time.Time
+		if sched, err := schedule.Parse(sub.Schedule); err == nil {
+			if t := sched.Next(now); !t.IsZero() {
+				next = &t
+			}
+		}
+		if err := s.repo.MarkRun(ctx, sub.ID, now, next); err != nil {
+			return n, err
+		}
+		d := &models.ReportDelivery{SubscriptionID: sub.ID, ScheduledFor: scheduledFor, Status: models.DeliveryPending}
+		if err := s.repo.InsertDelivery(ctx, d); err != nil {
+			return n, err
+		}
+		if err := s.attempt(ctx, sub, d); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// RetryDue re-attempts deliveries whose retry time has passed, and pending
+// deliveries abandoned for deliveryClaimTimeout, and returns how many were
+// attempted.
+func (s *ReportDeliveryService) RetryDue(ctx context.Context) (int, error) {
+	now := s.now()
+	list, err := s.repo.ClaimRetryableDeliveries(ctx, now, now.Add(-deliveryClaimTimeout))
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for i := range list {
+		d := &list[i]
+		sub, err := s.repo.Get(ctx, d.SubscriptionID)
+		if err != nil {
+			return n, err
+		}
+		if err := s.attempt(ctx, sub, d); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// RunNow delivers a subscription immediately without touching its schedule.
+func (s *ReportDeliveryService) RunNow(ctx context.Context, id int64) (*models.ReportDelivery, error) {
+	sub, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	d := &models.ReportDelivery{SubscriptionID: sub.ID, ScheduledFor: s.now(), Status: models.DeliveryPending}
+	if err := s.repo.InsertDelivery(ctx, d); err != nil {
+		return nil, err
+	}
+	if err := s.attempt(ctx, sub, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// RetryDelivery re-attempts a delivery right away, including one that has
+// already used up its automatic retries.
+func (s *ReportDeliveryService) RetryDelivery(ctx context.Context, id int64) (*models.ReportDelivery, error) {
+	d, err := s.repo.GetDelivery(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if d.Status == models.DeliverySent {
+		return nil, fmt.Errorf("delivery %d was already sent", id)
+	}
+	sub, err := s.repo.Get(ctx, d.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.attempt(ctx, sub, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// attempt renders and delivers one report and records the outcome on d. A
+// failed delivery is not an error; only failing to save the outcome is.
+func (s *ReportDeliveryService) attempt(ctx context.Context, sub *models.ReportSubscription, d *models.ReportDelivery) error {
+	d.Attempts++
+	name, err := s.deliver(ctx, sub, d.ScheduledFor)
+	now := s.now()
+	if name != "" {
+		d.FileName = &name
+	}
+	if err == nil {
+		d.Status = models.DeliverySent
+		d.LastError = nil
+		d.NextRetryAt = nil
+		d.DeliveredAt = &now
+	} else {
+		msg := err.Error()
+		d.LastError = &msg
+		if d.Attempts >= s.maxAttempts {
+			d.Status = models.DeliveryFailed
+			d.NextRetryAt = nil
+		} else {
+			d.Status = models.DeliveryRetrying
+			next := now.Add(s.retryDelay << (d.Attempts - 1))
+			d.NextRetryAt = &next
+		}
+	}
+	return s.repo.UpdateDelivery(ctx, d)
+}
+
+func (s *ReportDeliveryService) deliver(ctx context.Context, sub *models.ReportSubscription, at time.Time) (string, error) {
+	sink, ok := s.sinks[sub.SinkType]
+	if !ok {
+		return "", fmt.Errorf("sink %q is not available", sub.SinkType)
+	}
+	format, err := export.ParseFormat(sub.Format)
+	if err != nil {
+		return "", err
+	}
+	from, to, err := resolvePeriod(sub.Period, at)
+	if err != nil {
+		return "", err
+	}
+	req := ReportRequest{
+		Report:     sub.Report,
+		Store:      sub.Store,
+		Channel:    sub.Channel,
+		From:       from,
+		To:         to,
+		PeriodType: "Monthly",
+		Comparison: sub.Comparison,
+	}
+	if sub.Period == PeriodPreviousYear || sub.Period == PeriodYearToDate {
+		req.PeriodType = "Yearly"
+	}
+	data, name, err := s.renderer.Export(ctx, req, format)
+	if err != nil {
+		return "", fmt.Errorf("render: %w", err)
+	}
+	f := ReportFile{
+		Name:        name,
+		ContentType: format.ContentType(),
+		Subject:     fmt.Sprintf("%s (%s)", sub.Name, periodLabel(from, to)),
+		Data:        data,
+	}
+	if err := sink.Deliver(ctx, sub.SinkTarget, f); err != nil {
+		return name, fmt.Errorf("deliver via %s: %w", sub.SinkType, err)
+	}
+	return name, nil
+}
+
+// resolvePeriod turns a relative period into a date range ending at the last
+// second of its final day.
+func resolvePeriod(period string, at time.Time) (time.Time, time.Time, error) {
+	day := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, at.Location())
+	month := time.Date(at.Year(), at.Month(), 1, 0, 0, 0, 0, at.Location())
+	year := time.Date(at.Year(), 1, 1, 0, 0, 0, 0, at.Location())
+	var from, end time.Time
+	switch period {
+	case PeriodPreviousDay:
+		from, end = day.AddDate(0, 0, -1), day
+	case PeriodPreviousWeek:
+		// Weeks start on Monday.
+		monday := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		from, end = monday.AddDate(0, 0, -7), monday
+	case PeriodPreviousMonth:
+		from, end = month.AddDate(0, -1, 0), month
+	case PeriodCurrentMonth:
+		from, end = month, day.AddDate(0, 0, 1)
+	case PeriodPreviousYear:
+		from, end = year.AddDate(-1, 0, 0), year
+	case PeriodYearToDate:
+		from, end = year, day.AddDate(0, 0, 1)
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("unknown period %q", period)
+	}
+	return from, end.Add(-time.Second), nil
+}
@@ -0,0 +1,301 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/export"
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+)
+
+type fakeSubscriptionStore struct {
+	subs       map[int64]*models.ReportSubscription
+	deliveries map[int64]*models.ReportDelivery
+	nextID     int64
+}
+
+func newFakeSubscriptionStore(subs ...models.ReportSubscription) *fakeSubscriptionStore {
+	f := &fakeSubscriptionStore{subs: map[int64]*models.ReportSubscription{}, deliveries: map[int64]*models.ReportDelivery{}}
+	for i := range subs {
+		s := subs[i]
+		f.subs[s.ID] = &s
+	}
+	return f
+}
+
+func (f *fakeSubscriptionStore) Create(ctx context.Context, s *models.ReportSubscription) error {
+	f.nextID++
+	s.ID = f.nextID
+	cp := *s
+	f.subs[s.ID] = &cp
+	return nil
+}
+func (f *fakeSubscriptionStore) Update(ctx context.Context, s *models.ReportSubscription) error {
+	cp := *s
+	f.subs[s.ID] = &cp
+	return nil
+}
+func (f *fakeSubscriptionStore) Delete(ctx context.Context, id int64) error {
+	delete(f.subs, id)
+	return nil
+}
+func (f *fakeSubscriptionStore) Get(ctx context.Context, id int64) (*models.ReportSubscription, error) {
+	s, ok := f.subs[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	cp := *s
+	return &cp, nil
+}
+func (f *fakeSubscriptionStore) List(ctx context.Context) ([]models.ReportSubscription, error) {
+	var out []models.ReportSubscription
+	for _, s := range f.subs {
+		out = append(out, *s)
+	}
+	return out, nil
+}
+func (f *fakeSubscriptionStore) ClaimDue(ctx context.Context, now, claimedUntil time.Time) ([]models.ReportSubscription, error) {
+	var out []models.ReportSubscription
+	for _, s := range f.subs {
+		if s.Enabled && s.NextRunAt != nil && !s.NextRunAt.After(now) &&
+			(s.ClaimedUntil == nil || !s.ClaimedUntil.After(now)) {
+			until := claimedUntil
+			s.ClaimedUntil = &until
+			out = append(out, *s)
+		}
+	}
+	return out, nil
+}
+func (f *fakeSubscriptionStore) MarkRun(ctx context.Context, id int64, ranAt time.Time, next *time.Time) error {
+	f.subs[id].LastRunAt = &ranAt
+	f.subs[id].NextRunAt = next
+	f.subs[id].ClaimedUntil = nil
+	return nil
+}
+func (f *fakeSubscriptionStore) InsertDelivery(ctx context.Context, d *models.ReportDelivery) error {
+	f.nextID++
+	d.ID = f.nextID
+	d.UpdatedAt = time.Now()
+	cp := *d
+	f.deliveries[d.ID] = &cp
+	return nil
+}
+func (f *fakeSubscriptionStore) UpdateDelivery(ctx context.Context, d *models.ReportDelivery) error {
+	d.UpdatedAt = time.Now()
+	cp := *d
+	f.deliveries[d.ID] = &cp
+	return nil
+}
+func (f *fakeSubscriptionStore) GetDelivery(ctx context.Context, id int64) (*models.ReportDelivery, error) {
+	cp := *f.deliveries[id]
+	return &cp, nil
+}
+func (f *fakeSubscriptionStore) ListDeliveries(ctx context.Context, subscriptionID int64, limit, offset int) ([]models.ReportDelivery, error) {
+	var out []models.ReportDelivery
+	for _, d := range f.deliveries {
+		if d.SubscriptionID == subscriptionID {
+			out = append(out, *d)
+		}
+	}
+	return out, nil
+}
+func (f *fakeSubscriptionStore) ClaimRetryableDeliveries(ctx context.Context, now, staleBefore time.Time) ([]models.ReportDelivery, error) {
+	var out []models.ReportDelivery
+	for _, d := range f.deliveries {
+		due := d.Status == models.DeliveryRetrying && d.NextRetryAt != nil && !d.NextRetryAt.After(now)
+		stale := d.Status == models.DeliveryPending && d.UpdatedAt.Before(staleBefore)
+		if due || stale {
+			d.Status = models.DeliveryPending
+			d.UpdatedAt = now
+			out = append(out, *d)
+		}
+	}
+	return out, nil
+}
+
+type fakeRenderer struct{ reqs []ReportRequest }
+
+func (r *fakeRenderer) Export(ctx context.Context, req ReportRequest, f export.Format) ([]byte, string, error) {
+	r.reqs = append(r.reqs, req)
+	return []byte("report"), req.Report + "." + string(f), nil
+}
+
+type fakeSink struct {
+	fail  bool
+	files []ReportFile
+}
+
+func (s *fakeSink) Deliver(ctx context.Context, target string, f ReportFile) error {
+	if s.fail {
+		return errors.New("sink down")
+	}
+	s.files = append(s.files, f)
+	return nil
+}
+
+func TestReportDeliveryRunDue(t *testing.T) {
+	now := time.Date(2024, 4, 1, 7, 0, 0, 0, time.UTC)
+	due := now.Add(-time.Minute)
+	store := newFakeSubscriptionStore(models.ReportSubscription{
+		ID: 1, Name: "Monthly P&L", Report: ReportProfitLoss, Format: "pdf", Store: "ShopA",
+		Period: PeriodPreviousMonth, Schedule: "0 7 1 * *", SinkType: SinkWebhook,
+		SinkTarget: "http://example.test", Enabled: true, NextRunAt: &due,
+	})
+	store.nextID = 1
+	rend := &fakeRenderer{}
+	sink := &fakeSink{}
+	svc := NewReportDeliveryService(store, rend, map[string]ReportSink{SinkWebhook: sink}, 3, time.Minute)
+	svc.now = func() time.Time { return now }
+
+	n, err := svc.RunDue(context.Background())
+	if err != nil || n != 1 {
+		t.Fatalf("RunDue = %d, %v", n, err)
+	}
+	if len(sink.files) != 1 || sink.files[0].Name != "profit-loss.pdf" {
+		t.Fatalf("unexpected delivered files: %+v", sink.files)
+	}
+	req := rend.reqs[0]
+	if !req.From.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) || req.To.Day() != 31 || req.Store != "ShopA" {
+		t.Errorf("unexpected request %+v", req)
+	}
+	want := time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC)
+	if got := store.subs[1].NextRunAt; got == nil || !got.Equal(want) {
+		t.Errorf("next run = %v, want %v", got, want)
+	}
+	for _, d := range store.deliveries {
+		if d.Status != models.DeliverySent || d.Attempts != 1 || d.DeliveredAt == nil {
+			t.Errorf("unexpected delivery %+v", d)
+		}
+	}
+}
+
+func TestReportDeliveryClaimsAndRecoversStalePending(t *testing.T) {
+	now := time.Date(2024, 4, 1, 7, 0, 0, 0, time.UTC)
+	due := now.Add(-time.Minute)
+	store := newFakeSubscriptionStore(models.ReportSubscription{
+		ID: 1, Name: "Daily sales", Report: ReportSalesSummary, Format: "csv",
+		Period: PeriodPreviousDay, Schedule: "@daily", SinkType: SinkWebhook, SinkTarget: "x",
+		Enabled: true, NextRunAt: &due,
+	})
+	sink := &fakeSink{}
+	svc := NewReportDeliveryService(store, &fakeRenderer{}, map[string]ReportSink{SinkWebhook: sink}, 3, time.Minute)
+	svc.now = func() time.Time { return now }
+
+	// Another instance claimed the subscription first.
+	if _, err := store.ClaimDue(context.Background(), now, now.Add(deliveryClaimTimeout)); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := svc.RunDue(context.Background()); err != nil || n != 0 {
+		t.Fatalf("RunDue on a claimed subscription = %d, %v", n, err)
+	}
+
+	// A delivery left pending by a crashed run is retried once it is stale.
+	store.deliveries[7] = &models.ReportDelivery{ID: 7, SubscriptionID: 1, ScheduledFor: due,
+		Status: models.DeliveryPending, Attempts: 1, UpdatedAt: now.Add(-time.Minute)}
+	if n, err := svc.RetryDue(context.Background()); err != nil || n != 0 {
+		t.Fatalf("RetryDue on a fresh pending delivery = %d, %v", n, err)
+	}
+	store.deliveries[7].UpdatedAt = now.Add(-deliveryClaimTimeout - time.Minute)
+	if n, err := svc.RetryDue(context.Background()); err != nil || n != 1 {
+		t.Fatalf("RetryDue on a stale pending delivery = %d, %v", n, err)
+	}
+	if d := store.deliveries[7]; d.Status != models.DeliverySent || d.Attempts != 2 || len(sink.files) != 1 {
+		t.Errorf("stale delivery not re-sent: %+v", d)
+	}
+}
+
+func TestReportDeliveryRetriesWithBackoff(t *testing.T) {
+	now := time.Date(2024, 4, 1, 7, 0, 0, 0, time.UTC)
+	store := newFakeSubscriptionStore(models.ReportSubscription{
+		ID: 1, Name: "Daily sales", Report: ReportSalesSummary, Format: "csv",
+		Period: PeriodPreviousDay, Schedule: "@daily", SinkType: SinkWebhook, SinkTarget: "x", Enabled: true,
+	})
+	store.nextID = 1
+	sink := &fakeSink{fail: true}
+	svc := NewReportDeliveryService(store, &fakeRenderer{}, map[string]ReportSink{SinkWebhook: sink}, 3, time.Minute)
+	svc.now = func() time.Time { return now }
+
+	d, err := svc.RunNow(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("RunNow: %v", err)
+	}
+	if d.Status != models.DeliveryRetrying || d.NextRetryAt == nil || !d.NextRetryAt.Equal(now.Add(time.Minute)) {
+		t.Fatalf("after first failure: %+v", d)
+	}
+
+	now = now.Add(time.Minute)
+	if n, err := svc.RetryDue(context.Background()); err != nil || n != 1 {
+		t.Fatalf("RetryDue = %d, %v", n, err)
+	}
+	d = store.deliveries[d.ID]
+	if d.Attempts != 2 || !d.NextRetryAt.Equal(now.Add(2*time.Minute)) {
+		t.Fatalf("after second failure: %+v", d)
+	}
+
+	now = now.Add(2 * time.Minute)
+	svc.RetryDue(context.Background())
+	d = store.deliveries[d.ID]
+	if d.Status != models.DeliveryFailed || d.NextRetryAt != nil || d.LastError == nil {
+		t.Fatalf("expected failed delivery, got %+v", d)
+	}
+
+	sink.fail = false
+	if d, err = svc.RetryDelivery(context.Background(), d.ID); err != nil || d.Status != models.DeliverySent {
+		t.Fatalf("manual retry = %+v, %v", d, err)
+	}
+}
+
+func TestReportSubscriptionValidation(t *testing.T) {
+	svc := NewReportDeliveryService(newFakeSubscriptionStore(), &fakeRenderer{}, map[string]ReportSink{SinkDirectory: &fakeSink{}}, 0, 0)
+	svc.now = func() time.Time { return time.Date(2024, 4, 1, 7, 30, 0, 0, time.UTC) }
+
+	bad := []models.ReportSubscription{
+		{Name: "x", Report: "nope", Schedule: "@daily", SinkType: SinkDirectory},
+		{Name: "x", Report: ReportBalanceSheet, Schedule: "61 * * * *", SinkType: SinkDirectory},
+		{Name: "x", Report: ReportBalanceSheet, Schedule: "@daily", SinkType: SinkEmail, SinkTarget: "a@b.c"},
+		{Name: "x", Report: ReportBalanceSheet, Schedule: "@daily", SinkType: SinkDirectory, Period: "someday"},
+	}
+	for _, s := range bad {
+		if err := svc.CreateSubscription(context.Background(), &s); !errors.Is(err, ErrInvalidSubscription) {
+			t.Errorf("expected validation error for %+v, got %v", s, err)
+		}
+	}
+
+	ok := models.ReportSubscription{Name: "BS", Report: ReportBalanceSheet, Schedule: "0 8 * * 1", SinkType: SinkDirectory, Enabled: true}
+	if err := svc.CreateSubscription(context.Background(), &ok); err != nil {
+		t.Fatalf("CreateSubscription: %v", err)
+	}
+	if ok.Format != "xlsx" || ok.Period != PeriodPreviousMonth {
+		t.Errorf("defaults not applied: %+v", ok)
+	}
+	if want := time.Date(2024, 4, 1, 8, 0, 0, 0, time.UTC); ok.NextRunAt == nil || !ok.NextRunAt.Equal(want) {
+		t.Errorf("next run = %v, want %v", ok.NextRunAt, want)
+	}
+}
+
+func TestResolvePeriodPreviousWeek(t *testing.T) {
+	// Wednesday 2024-04-03 -> Monday 2024-03-25 .. Sunday 2024-03-31.
+	from, to, err := resolvePeriod(PeriodPreviousWeek, time.Date(2024, 4, 3, 9, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if from.Format("2006-01-02") != "2024-03-25" || to.Format("2006-01-02 15:04:05") != "2024-03-31 23:59:59" {
+		t.Errorf("got %s .. %s", from, to)
+	}
+}
+
+func TestDirectorySinkStaysInBase(t *testing.T) {
+	base := t.TempDir()
+	sink := NewDirectorySink(base)
+	f := ReportFile{Name: "../../report.csv", Data: []byte("a,b")}
+	if err := sink.Deliver(context.Background(), "../../finance", f); err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(base, "finance", "report.csv")); err != nil {
+		t.Errorf("report not written inside base: %v", err)
+	}
+}
@@ -0,0 +1,853 @@
+// File: backend/internal/service/report_export_service.go
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/export"
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
+	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
+)
+
+// Report names accepted by ReportExportService.
+const (
+	ReportBalanceSheet          = "balance-sheet"
+	ReportProfitLoss            = "profit-loss"
+	ReportGeneralLedger         = "general-ledger"
+	ReportSalesProfit           = "sales-profit"
+	ReportShippingDiscrepancies = "shipping-discrepancies"
+	ReportReconciliation        = "reconciliation"
+	ReportSalesSummary          = "sales-summary"
+	ReportFailedReconciliations = "failed-reconciliations"
+	ReportTrialBalance          = "trial-balance"
+	ReportCashFlow              = "cash-flow"
+	ReportEquityChanges         = "equity-changes"
+)
+
+// exportableReports lists every report BuildDocument understands.
+var exportableReports = []string{
+	ReportBalanceSheet, ReportProfitLoss, ReportGeneralLedger, ReportSalesProfit,
+	ReportShippingDiscrepancies, ReportReconciliation, ReportSalesSummary,
+	ReportFailedReconciliations, ReportTrialBalance, ReportCashFlow, ReportEquityChanges,
+}
+
+func isExportableReport(name string) bool {
+	for _, r := range exportableReports {
+		if r == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrInvalidReportRequest is wrapped by errors caused by the request itself,
+// such as an unknown report name or a missing store, so callers can tell
+// them apart from storage failures.
+var ErrInvalidReportRequest = errors.New("invalid report request")
+
+// exportPageSize is how many rows are fetched per call when a report has to
+// page through a list endpoint.
+const exportPageSize = 1000
+
+// ReportRequest selects a report and its filters. The balance sheet is taken
+// as of To; P&L uses PeriodType ("Monthly" or "Yearly") with the month and
+// year of From.
+type ReportRequest struct {
+	Report          string    `json:"report"`
+	Store           string    `json:"store,omitempty"`
+	Channel         string    `json:"channel,omitempty"`
+	From            time.Time `json:"from"`
+	To              time.Time `json:"to"`
+	PeriodType      string    `json:"period_type,omitempty"`
+	Comparison      bool      `json:"comparison,omitempty"`
+	DiscrepancyType string    `json:"discrepancy_type,omitempty"`
+}
+
+// ExportBalanceSource provides balance sheet data.
+type ExportBalanceSource interface {
+	GetBalanceSheet(ctx context.Context, shop string, asOfDate time.Time) ([]CategoryBalance, error)
+}
+
+// ExportProfitLossSource provides profit and loss statements.
+type ExportProfitLossSource interface {
+	GetProfitLoss(ctx context.Context, typ string, month, year int, store string, comparison bool) (*ProfitLoss, error)
+}
+
+// ExportLedgerSource provides general ledger balances.
+type ExportLedgerSource interface {
+	FetchGeneralLedger(ctx context.Context, shop string, from, to time.Time) ([]repository.AccountBalance, error)
+}
+
+// ExportSalesSource provides sales profit rows.
+type ExportSalesSource interface {
+	ListSalesProfit(ctx context.Context, channel, store, from, to, orderNo, sortBy, dir string, limit, offset int) ([]models.SalesProfit, int, error)
+}
+
+// ExportDiscrepancySource provides shipping discrepancies.
+type ExportDiscrepancySource interface {
+	ListShippingDiscrepanciesForReport(ctx context.Context, q repository.ShippingDiscrepancyQuery, limit, offset int) ([]models.ShippingDiscrepancy, error)
+	SumShippingDiscrepanciesForReport(ctx context.Context, q repository.ShippingDiscrepancyQuery) (map[string]money.Amount, error)
+}
+
+// ExportReconcileSource provides reconciliation reports.
+type ExportReconcileSource interface {
+	GenerateReconciliationReport(ctx context.Context, shop string, since time.Time) (*models.ReconciliationReport, error)
+	GetFailedReconciliationsSummary(ctx context.Context, shop string, days int) (map[string]interface{}, error)
+}
+
+// ExportDashboardSource provides the sales summary shown on the dashboard.
+type ExportDashboardSource interface {
+	GetDashboardData(ctx context.Context, f DashboardFilters) (*DashboardData, error)
+}
+
+// ExportStatementSource provides the trial balance, cash-flow and equity
+// statements.
+type ExportStatementSource interface {
+	GetTrialBalance(ctx context.Context, store string, from, to time.Time, comparison bool) (*TrialBalance, error)
+	GetCashFlow(ctx context.Context, store string, from, to time.Time, comparison bool) (*CashFlowStatement, error)
+	GetEquityChanges(ctx context.Context, store string, from, to time.Time, comparison bool) (*EquityStatement, error)
+}
+
+// ReportExportService turns the JSON reports into export.Documents and
+// renders them as XLSX, CSV or PDF files.
+type ReportExportService struct {
+	company     export.CompanyInfo
+	balance     ExportBalanceSource
+	profitLoss  ExportProfitLossSource
+	ledger      ExportLedgerSource
+	sales       ExportSalesSource
+	discrepancy ExportDiscrepancySource
+	reconcile   ExportReconcileSource
+	dashboard   ExportDashboardSource
+	statements  ExportStatementSource
+	now         func() time.Time
+}
+
+// NewReportExportService constructs a ReportExportService. Any source may be
+// nil, in which case its report cannot be exported.
+func NewReportExportService(
+	company export.CompanyInfo,
+	bs ExportBalanceSource,
+	pl ExportProfitLossSource,
+	gl ExportLedgerSource,
+	sales ExportSalesSource,
+	disc ExportDiscrepancySource,
+	recon ExportReconcileSource,
+	dash ExportDashboardSource,
+	fs ExportStatementSource,
+) *ReportExportService {
+	return &ReportExportService{
+		company:     company,
+		balance:     bs,
+		profitLoss:  pl,
+		ledger:      gl,
+		sales:       sales,
+		discrepancy: disc,
+		reconcile:   recon,
+		dashboard:   dash,
+		statements:  fs,
+		now:         time.Now,
+	}
+}
+
+// Export renders the requested report and returns the file contents together
+// with a suggested file name.
+func (s *ReportExportService) Export(ctx context.Context, req ReportRequest, format export.Format) ([]byte, string, error) {
+	doc, err := s.BuildDocument(ctx, req)
+	if err != nil {
+		return nil, "", err
+	}
+	var buf bytes.Buffer
+	if err := export.Render(&buf, doc, format); err != nil {
+		return nil, "", fmt.Errorf("render %s: %w", format, err)
+	}
+	return buf.Bytes(), doc.Filename(req.Report, format), nil
+}
+
+// BuildDocument loads the report data and lays it out as a Document.
+func (s *ReportExportService) BuildDocument(ctx context.Context, req ReportRequest) (*export.Document, error) {
+	doc := &export.Document{
+		Company:     s.company,
+		Store:       req.Store,
+		GeneratedAt: s.now(),
+	}
+	var err error
+	switch req.Report {
+	case ReportBalanceSheet:
+		err = s.buildBalanceSheet(ctx, req, doc)
+	case ReportProfitLoss:
+		err = s.buildProfitLoss(ctx, req, doc)
+	case ReportGeneralLedger:
+		err = s.buildGeneralLedger(ctx, req, doc)
+	case ReportSalesProfit:
+		err = s.buildSalesProfit(ctx, req, doc)
+	case ReportShippingDiscrepancies:
+		err = s.buildShippingDiscrepancies(ctx, req, doc)
+	case ReportReconciliation:
+		err = s.buildReconciliation(ctx, req, doc)
+	case ReportSalesSummary:
+		err = s.buildSalesSummary(ctx, req, doc)
+	case ReportFailedReconciliations:
+		err = s.buildFailedReconciliations(ctx, req, doc)
+	case ReportTrialBalance:
+		err = s.buildTrialBalance(ctx, req, doc)
+	case ReportCashFlow:
+		err = s.buildCashFlow(ctx, req, doc)
+	case ReportEquityChanges:
+		err = s.buildEquityChanges(ctx, req, doc)
+	default:
+		return nil, fmt.Errorf("%w: unknown report %q", ErrInvalidReportRequest, req.Report)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func periodLabel(from, to time.Time) string {
+	switch {
+	case from.IsZero() && to.IsZero():
+		return ""
+	case from.IsZero():
+		return "until " + to.Format("2006-01-02")
+	case to.IsZero():
+		return "from " + from.Format("2006-01-02")
+	}
+	return from.Format("2006-01-02") + " to " + to.Format("2006-01-02")
+}
+
+func (s *ReportExportService) buildBalanceSheet(ctx context.Context, req ReportRequest, doc *export.Document) error {
+	if s.balance == nil {
+		return fmt.Errorf("balance sheet export not configured")
+	}
+	asOf := req.To
+	if asOf.IsZero() {
+		asOf = s.now()
+	}
+	cats, err := s.balance.GetBalanceSheet(ctx, req.Store, asOf)
+	if err != nil {
+		return err
+	}
+	doc.Title = "Balance Sheet"
+	doc.Period = "as of " + asOf.Format("2006-01-02")
+	for _, cat := range cats {
+		sec := export.Section{
+			Title: cat.Category,
+			Columns: []export.Column{
+				{Header: "Code", Kind: export.KindText},
+				{Header: "Account", Kind: export.KindText},
+				{Header: "Balance", Kind: export.KindMoney},
+			},
+		}
+		for _, a := range cat.Accounts {
+			sec.Rows = append(sec.Rows, export.Row{Cells: []interface{}{a.AccountCode, a.AccountName, a.Balance}})
+		}
+		sec.Rows = append(sec.Rows, export.Row{Cells: []interface{}{"", "Total " + cat.Category, cat.Total}, Bold: true})
+		doc.Sections = append(doc.Sections, sec)
+	}
+	return nil
+}
+
+func (s *ReportExportService) buildProfitLoss(ctx context.Context, req ReportRequest, doc *export.Document) error {
+	if s.profitLoss == nil {
+		return fmt.Errorf("profit and loss export not configured")
+	}
+	typ := req.PeriodType
+	if typ == "" {
+		typ = "Monthly"
+	}
+	ref := req.From
+	if ref.IsZero() {
+		ref = s.now()
+	}
+	pl, err := s.profitLoss.GetProfitLoss(ctx, typ, int(ref.Month()), ref.Year(), req.Store, req.Comparison)
+	if err != nil {
+		return err
+	}
+	doc.Title = "Profit and Loss"
+	if typ == "Yearly" {
+		doc.Period = ref.Format("2006")
+	} else {
+		doc.Period = ref.Format("2006-01")
+	}
+
+	cols := []export.Column{
+		{Header: "Description", Kind: export.KindText},
+		{Header: "Amount", Kind: export.KindMoney},
+		{Header: "% of Revenue", Kind: export.KindPercent},
+	}
+	if req.Comparison {
+		cols = append(cols,
+			export.Column{Header: "Previous", Kind: export.KindMoney},
+			export.Column{Header: "Change", Kind: export.KindMoney},
+			export.Column{Header: "Change %", Kind: export.KindPercent},
+		)
+	}
+	sec := export.Section{Columns: cols}
+	line := func(r ProfitLossRow, bold bool) {
+		cells := []interface{}{r.Label, r.Amount, r.Percent}
+		if req.Comparison {
+			cells = append(cells, r.PreviousAmount, r.Change, r.ChangePercent)
+		}
+		sec.Rows = append(sec.Rows, export.Row{Cells: cells, Bold: bold || r.Group, Indent: r.Indent})
+	}
+	group := func(title string, rows []ProfitLossRow, total, prevTotal money.Amount) {
+		sec.Rows = append(sec.Rows, export.Row{Cells: []interface{}{title}, Bold: true})
+		for _, r := range rows {
+			if r.Indent == 0 {
+				r.Indent = 1
+			}
+			line(r, false)
+		}
+		line(ProfitLossRow{
+			Label:          "Total " + title,
+			Amount:         total,
+			Percent:        pct(total, pl.TotalPendapatanUsaha),
+			PreviousAmount: prevTotal,
+			Change:         total - prevTotal,
+			ChangePercent:  changePct(total, prevTotal),
+		}, true)
+	}
+
+	group("Pendapatan Usaha", pl.PendapatanUsaha, pl.TotalPendapatanUsaha, pl.PrevTotalPendapatanUsaha)
+	group("Harga Pokok Penjualan", pl.HargaPokokPenjualan, pl.TotalHargaPokokPenjualan, pl.PrevTotalHargaPokokPenjualan)
+	line(pl.LabaKotor, true)
+	group("Beban Operasional", pl.BebanOperasional, pl.TotalBebanOperasional, pl.PrevTotalBebanOperasional)
+	group("Beban Pemasaran", pl.BebanPemasaran, pl.TotalBebanPemasaran, pl.PrevTotalBebanPemasaran)
+	group("Beban Administrasi", pl.BebanAdministrasi, pl.TotalBebanAdministrasi, pl.PrevTotalBebanAdministrasi)
+	line(pl.TotalBebanUsaha, true)
+	line(ProfitLossRow{
+		Label:          "Laba Sebelum Pajak",
+		Amount:         pl.LabaSebelumPajak,
+		Percent:        pct(pl.LabaSebelumPajak, pl.TotalPendapatanUsaha),
+		PreviousAmount: pl.PrevLabaSebelumPajak,
+		Change:         pl.LabaSebelumPajak - pl.PrevLabaSebelumPajak,
+		ChangePercent:  changePct(pl.LabaSebelumPajak, pl.PrevLabaSebelumPajak),
+	}, true)
+	group("Pajak Penghasilan", pl.PajakPenghasilan, pl.TotalPajakPenghasilan, pl.PrevTotalPajakPenghasilan)
+	line(pl.LabaRugiBersih, true)
+
+	doc.Sections = []export.Section{sec}
+	return nil
+}
+
+func (s *ReportExportService) buildGeneralLedger(ctx context.Context, req ReportRequest, doc *export.Document) error {
+	if s.ledger == nil {
+		return fmt.Errorf("general ledger export not configured")
+	}
+	rows, err := s.ledger.FetchGeneralLedger(ctx, req.Store, req.From, req.To)
+	if err != nil {
+		return err
+	}
+	doc.Title = "General Ledger"
+	doc.Period = periodLabel(req.From, req.To)
+	sec := export.Section{Columns: []export.Column{
+		{Header: "Code", Kind: export.KindText},
+		{Header: "Account", Kind: export.KindText},
+		{Header: "Type", Kind: export.KindText},
+		{Header: "Debit", Kind: export.KindMoney},
+		{Header: "Credit", Kind: export.KindMoney},
+	}}
+	var totalDebit, totalCredit money.Amount
+	for _, a := range rows {
+		if a.Balance == 0 {
+			continue
+		}
+		var debit, credit interface{}
+		if a.Balance > 0 {
+			debit = a.Balance
+			totalDebit += a.Balance
+		} else {
+			credit = -a.Balance
+			totalCredit += -a.Balance
+		}
+		sec.Rows = append(sec.Rows, export.Row{Cells: []interface{}{a.AccountCode, a.AccountName, a.AccountType, debit, credit}})
+	}
+	sec.Rows = append(sec.Rows, export.Row{Cells: []interface{}{"", "Total", "", totalDebit, totalCredit}, Bold: true})
+	doc.Sections = []export.Section{sec}
+	return nil
+}
+
+func (s *ReportExportService) buildSalesProfit(ctx context.Context, req ReportRequest, doc *export.Document) error {
+	if s.sales == nil {
+		return fmt.Errorf("sales profit export not configured")
+	}
+	from, to := "", ""
+	if !req.From.IsZero() {
+		from = req.From.Format("2006-01-02")
+	}
+	if !req.To.IsZero() {
+		to = req.To.Format("2006-01-02")
+	}
+	var all []models.SalesProfit
+	for offset := 0; ; offset += exportPageSize {
+		page, total, err := s.sales.ListSalesProfit(ctx, req.Channel, req.Store, from, to, "", "tanggal_pesanan", "asc", exportPageSize, offset)
+		if err != nil {
+			return err
+		}
+		all = append(all, page...)
+		if len(page) < exportPageSize || len(all) >= total {
+			break
+		}
+	}
+
+	doc.Title = "Sales Profit"
+	doc.Period = periodLabel(req.From, req.To)
+	sec := export.Section{Columns: []export.Column{
+		{Header: "Order", Kind: export.KindText},
+		{Header: "Date", Kind: export.KindDate},
+		{Header: "Sales", Kind: export.KindMoney},
+		{Header: "Purchase", Kind: export.KindMoney},
+		{Header: "Mitra", Kind: export.KindMoney},
+		{Header: "Admin", Kind: export.KindMoney},
+		{Header: "Service", Kind: export.KindMoney},
+		{Header: "Voucher", Kind: export.KindMoney},
+		{Header: "Transaction", Kind: export.KindMoney},
+		{Header: "Shipping", Kind: export.KindMoney},
+		{Header: "Affiliate", Kind: export.KindMoney},
+		{Header: "Refund", Kind: export.KindMoney},
+		{Header: "Profit", Kind: export.KindMoney},
+		{Header: "Profit %", Kind: export.KindPercent},
+	}}
+	var sales, profit money.Amount
+	for _, p := range all {
+		sec.Rows = append(sec.Rows, export.Row{Cells: []interface{}{
+			p.KodePesanan, p.TanggalPesanan, p.AmountSales, p.ModalPurchase,
+			p.BiayaMitraJakmall, p.BiayaAdministrasi, p.BiayaLayanan, p.BiayaVoucher,
+			p.BiayaTransaksi, p.DiskonOngkir + p.SelisihOngkir, p.BiayaAffiliate,
+			p.BiayaRefund, p.Profit, p.ProfitPercent,
+		}})
+		sales += money.FromFloat(p.AmountSales)
+		profit += money.FromFloat(p.Profit)
+	}
+	sec.Rows = append(sec.Rows, export.Row{
+		Cells: []interface{}{"Total", nil, sales, nil, nil, nil, nil, nil, nil, nil, nil, nil, profit, pct(profit, sales)},
+		Bold:  true,
+	})
+	doc.Sections = []export.Section{sec}
+	return nil
+}
+
+func (s *ReportExportService) buildShippingDiscrepancies(ctx context.Context, req ReportRequest, doc *export.Document) error {
+	if s.discrepancy == nil {
+		return fmt.Errorf("shipping discrepancy export not configured")
+	}
+	q := repository.ShippingDiscrepancyQuery{Store: req.Store, Type: req.DiscrepancyType, From: req.From, To: req.To}
+	var rows []models.ShippingDiscrepancy
+	for offset := 0; ; offset += exportPageSize {
+		page, err := s.discrepancy.ListShippingDiscrepanciesForReport(ctx, q, exportPageSize, offset)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, page...)
+		if len(page) < exportPageSize {
+			break
+		}
+	}
+
+	doc.Title = "Shipping Discrepancies"
+	doc.Period = periodLabel(req.From, req.To)
+
+	if !req.From.IsZero() && !req.To.IsZero() {
+		sums, err := s.discrepancy.SumShippingDiscrepanciesForReport(ctx, q)
+		if err != nil {
+			return err
+		}
+		types := make([]string, 0, len(sums))
+		for t := range sums {
+			types = append(types, t)
+		}
+		sort.Strings(types)
+		title := "Summary (all stores)"
+		if req.Store != "" {
+			title = "Summary (" + req.Store + ")"
+		}
+		summary := export.Section{
+			Title:   title,
+			Columns: []export.Column{{Header: "Type", Kind: export.KindText}, {Header: "Amount", Kind: export.KindMoney}},
+		}
+		for _, t := range types {
+			summary.Rows = append(summary.Rows, export.Row{Cells: []interface{}{t, sums[t]}})
+		}
+		doc.Sections = append(doc.Sections, summary)
+	}
+
+	detail := export.Section{
+		Title: "Details",
+		Columns: []export.Column{
+			{Header: "Invoice", Kind: export.KindText},
+			{Header: "Store", Kind: export.KindText},
+			{Header: "Order Date", Kind: export.KindDate},
+			{Header: "Type", Kind: export.KindText},
+			{Header: "Amount", Kind: export.KindMoney},
+			{Header: "Actual Fee", Kind: export.KindMoney},
+			{Header: "Buyer Paid", Kind: export.KindMoney},
+			{Header: "Shopee Rebate", Kind: export.KindMoney},
+			{Header: "Reverse Fee", Kind: export.KindMoney},
+		},
+	}
+	var total money.Amount
+	for _, d := range rows {
+		detail.Rows = append(detail.Rows, export.Row{Cells: []interface{}{
+			d.InvoiceNumber, d.StoreName, d.OrderDate, d.DiscrepancyType, d.DiscrepancyAmount,
+			d.ActualShippingFee, d.BuyerPaidShippingFee, d.ShopeeShippingRebate, d.ReverseShippingFee,
+		}})
+		total += money.FromFloat(d.DiscrepancyAmount)
+	}
+	detail.Rows = append(detail.Rows, export.Row{Cells: []interface{}{"Total", nil, nil, nil, total}, Bold: true})
+	doc.Sections = append(doc.Sections, detail)
+	return nil
+}
+
+func (s *ReportExportService) buildReconciliation(ctx context.Context, req ReportRequest, doc *export.Document) error {
+	if s.reconcile == nil {
+		return fmt.Errorf("reconciliation export not configured")
+	}
+	if req.Store == "" {
+		return fmt.Errorf("%w: store is required for the reconciliation report", ErrInvalidReportRequest)
+	}
+	since := req.From
+	if since.IsZero() {
+		since = s.now().AddDate(0, 0, -30)
+	}
+	rep, err := s.reconcile.GenerateReconciliationReport(ctx, req.Store, since)
+	if err != nil {
+		return err
+	}
+	doc.Title = "Reconciliation Report"
+	doc.Period = periodLabel(since, rep.ProcessingEndTime)
+
+	summary := export.Section{
+		Title:   "Summary",
+		Columns: []export.Column{{Header: "Metric", Kind: export.KindText}, {Header: "Value", Kind: export.KindNumber}},
+		Rows: []export.Row{
+			{Cells: []interface{}{"Failed transactions", rep.FailedTransactions}},
+		},
+	}
+	cats := make([]string, 0, len(rep.FailureCategories))
+	for c := range rep.FailureCategories {
+		cats = append(cats, c)
+	}
+	sort.Strings(cats)
+	for _, c := range cats {
+		summary.Rows = append(summary.Rows, export.Row{Cells: []interface{}{c, rep.FailureCategories[c]}, Indent: 1})
+	}
+	doc.Sections = append(doc.Sections, summary)
+
+	if len(rep.FailedTransactionList) > 0 {
+		detail := export.Section{
+			Title: "Failed Transactions",
+			Columns: []export.Column{
+				{Header: "Purchase", Kind: export.KindText},
+				{Header: "Order", Kind: export.KindText},
+				{Header: "Error Type", Kind: export.KindText},
+				{Header: "Message", Kind: export.KindText},
+				{Header: "Failed At", Kind: export.KindDate},
+				{Header: "Status", Kind: export.KindText},
+				{Header: "Attempts", Kind: export.KindNumber},
+			},
+		}
+		for _, f := range rep.FailedTransactionList {
+			detail.Rows = append(detail.Rows, export.Row{Cells: []interface{}{
+				f.PurchaseID, f.OrderID, f.ErrorType, f.ErrorMsg, f.FailedAt, f.Status, f.Attempts,
+			}})
+		}
+		doc.Sections = append(doc.Sections, detail)
+	}
+	return nil
+}
+
+// salesSummaryLabels orders and names the dashboard summary metrics.
+var salesSummaryLabels = []struct{ key, label string }{
+	{"total_orders", "Total orders"},
+	{"total_price", "Total purchases"},
+	{"avg_order_value", "Average order value"},
+	{"total_customers", "Customers"},
+	{"total_cancelled", "Cancelled orders"},
+	{"total_net_profit", "Net profit"},
+	{"outstanding_amount", "Outstanding amount"},
+}
+
+func (s *ReportExportService) buildSalesSummary(ctx context.Context, req ReportRequest, doc *export.Document) error {
+	if s.dashboard == nil {
+		return fmt.Errorf("sales summary export not configured")
+	}
+	typ := req.PeriodType
+	if typ == "" {
+		typ = "Monthly"
+	}
+	ref := req.From
+	if ref.IsZero() {
+		ref = s.now()
+	}
+	data, err := s.dashboard.GetDashboardData(ctx, DashboardFilters{
+		Channel: req.Channel,
+		Store:   req.Store,
+		Period:  typ,
+		Month:   int(ref.Month()),
+		Year:    ref.Year(),
+	})
+	if err != nil {
+		return err
+	}
+	doc.Title = "Sales Summary"
+	if typ == "Yearly" {
+		doc.Period = ref.Format("2006")
+	} else {
+		doc.Period = ref.Format("2006-01")
+	}
+
+	summary := export.Section{
+		Title:   "Summary",
+		Columns: []export.Column{{Header: "Metric", Kind: export.KindText}, {Header: "Value", Kind: export.KindMoney}},
+	}
+	for _, l := range salesSummaryLabels {
+		if item, ok := data.Summary[l.key]; ok {
+			summary.Rows = append(summary.Rows, export.Row{Cells: []interface{}{l.label, item.Value}})
+		}
+	}
+	doc.Sections = append(doc.Sections, summary)
+
+	daily := export.Section{
+		Title: "Sales by Date",
+		Columns: []export.Column{
+			{Header: "Date", Kind: export.KindText},
+			{Header: "Orders", Kind: export.KindNumber},
+			{Header: "Total Sales", Kind: export.KindMoney},
+			{Header: "Average Order", Kind: export.KindMoney},
+		},
+	}
+	orders := data.Charts["number_of_orders"]
+	avg := data.Charts["avg_order_value"]
+	for i, p := range data.Charts["total_sales"] {
+		row := []interface{}{p.Date, nil, p.Value, nil}
+		if i < len(orders) {
+			row[1] = orders[i].Value
+		}
+		if i < len(avg) {
+			row[3] = avg[i].Value
+		}
+		daily.Rows = append(daily.Rows, export.Row{Cells: row})
+	}
+	doc.Sections = append(doc.Sections, daily)
+	return nil
+}
+
+func (s *ReportExportService) buildFailedReconciliations(ctx context.Context, req ReportRequest, doc *export.Document) error {
+	if s.reconcile == nil {
+		return fmt.Errorf("failed reconciliation export not configured")
+	}
+	if req.Store == "" {
+		return fmt.Errorf("%w: store is required for the failed reconciliation summary", ErrInvalidReportRequest)
+	}
+	days := 30
+	if !req.From.IsZero() {
+		days = int(s.now().Sub(req.From).Hours()/24 + 0.5)
+		if days < 1 {
+			days = 1
+		}
+	}
+	summary, err := s.reconcile.GetFailedReconciliationsSummary(ctx, req.Store, days)
+	if err != nil {
+		return err
+	}
+	doc.Title = "Failed Reconciliation Summary"
+	doc.Period = fmt.Sprintf("last %d days", days)
+
+	sec := export.Section{
+		Columns: []export.Column{{Header: "Error Type", Kind: export.KindText}, {Header: "Count", Kind: export.KindNumber}},
+	}
+	cats, _ := summary["failure_categories"].(map[string]int)
+	names := make([]string, 0, len(cats))
+	for c := range cats {
+		names = append(names, c)
+	}
+	sort.Strings(names)
+	for _, c := range names {
+		sec.Rows = append(sec.Rows, export.Row{Cells: []interface{}{c, cats[c]}})
+	}
+	total, _ := summary["total_failed"].(int)
+	sec.Rows = append(sec.Rows, export.Row{Cells: []interface{}{"Total", total}, Bold: true})
+	doc.Sections = []export.Section{sec}
+	return nil
+}
+
+// statementRange returns the requested period, defaulting to the current
+// month when From or To is missing.
+func (s *ReportExportService) statementRange(req ReportRequest) (time.Time, time.Time) {
+	from, to := req.From, req.To
+	if from.IsZero() {
+		now := s.now()
+		from = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	if to.IsZero() || to.Before(from) {
+		to = from.AddDate(0, 1, 0).Add(-time.Second)
+	}
+	return from, to
+}
+
+func (s *ReportExportService) buildTrialBalance(ctx context.Context, req ReportRequest, doc *export.Document) error {
+	if s.statements == nil {
+		return fmt.Errorf("trial balance export not configured")
+	}
+	from, to := s.statementRange(req)
+	tb, err := s.statements.GetTrialBalance(ctx, req.Store, from, to, req.Comparison)
+	if err != nil {
+		return err
+	}
+	doc.Title = "Trial Balance"
+	doc.Period = periodLabel(from, to)
+	cols := []export.Column{
+		{Header: "Code", Kind: export.KindText},
+		{Header: "Account", Kind: export.KindText},
+		{Header: "Opening", Kind: export.KindMoney},
+		{Header: "Debit", Kind: export.KindMoney},
+		{Header: "Credit", Kind: export.KindMoney},
+		{Header: "Closing", Kind: export.KindMoney},
+	}
+	prev := map[int64]money.Amount{}
+	if tb.Previous != nil {
+		cols = append(cols, export.Column{Header: "Previous Closing", Kind: export.KindMoney})
+		for _, r := range tb.Previous.Rows {
+			prev[r.AccountID] = r.Closing
+		}
+	}
+	sec := export.Section{Columns: cols}
+	for _, r := range tb.Rows {
+		cells := []interface{}{r.AccountCode, r.AccountName, r.Opening, r.Debit, r.Credit, r.Closing}
+		if tb.Previous != nil {
+			cells = append(cells, prev[r.AccountID])
+		}
+		sec.Rows = append(sec.Rows, export.Row{Cells: cells, Bold: r.Group, Indent: r.Level})
+	}
+	total := []interface{}{"", "Total", tb.TotalOpening, tb.TotalDebit, tb.TotalCredit, tb.TotalClosing}
+	if tb.Previous != nil {
+		total = append(total, tb.Previous.TotalClosing)
+	}
+	sec.Rows = append(sec.Rows, export.Row{Cells: total, Bold: true})
+	doc.Sections = []export.Section{sec}
+	return nil
+}
+
+func (s *ReportExportService) buildCashFlow(ctx context.Context, req ReportRequest, doc *export.Document) error {
+	if s.statements == nil {
+		return fmt.Errorf("cash flow export not configured")
+	}
+	from, to := s.statementRange(req)
+	cf, err := s.statements.GetCashFlow(ctx, req.Store, from, to, req.Comparison)
+	if err != nil {
+		return err
+	}
+	doc.Title = "Cash Flow Statement"
+	doc.Period = periodLabel(from, to)
+	cols := []export.Column{
+		{Header: "Description", Kind: export.KindText},
+		{Header: "Amount", Kind: export.KindMoney},
+	}
+	if cf.Previous != nil {
+		cols = append(cols, export.Column{Header: "Previous", Kind: export.KindMoney})
+	}
+	sec := export.Section{Columns: cols}
+	line := func(label string, amount, previous money.Amount, bold bool, indent int) {
+		cells := []interface{}{label, amount}
+		if cf.Previous != nil {
+			cells = append(cells, previous)
+		}
+		sec.Rows = append(sec.Rows, export.Row{Cells: cells, Bold: bold, Indent: indent})
+	}
+	section := func(cur CashFlowSection, prev *CashFlowSection) {
+		sec.Rows = append(sec.Rows, export.Row{Cells: []interface{}{cur.Title}, Bold: true})
+		prevByLabel := map[string]money.Amount{}
+		var prevTotal money.Amount
+		if prev != nil {
+			for _, l := range prev.Lines {
+				prevByLabel[l.Label] += l.Amount
+			}
+			prevTotal = prev.Total
+		}
+		for _, l := range cur.Lines {
+			line(l.Label, l.Amount, prevByLabel[l.Label], false, 1)
+		}
+		line("Net cash from "+strings.ToLower(cur.Title), cur.Total, prevTotal, true, 0)
+	}
+	var p CashFlowStatement
+	if cf.Previous != nil {
+		p = *cf.Previous
+	}
+	section(cf.Operating, &p.Operating)
+	section(cf.Investing, &p.Investing)
+	section(cf.Financing, &p.Financing)
+	line("Net change in cash", cf.NetChange, p.NetChange, true, 0)
+	line("Cash at beginning of period", cf.OpeningCash, p.OpeningCash, false, 0)
+	line("Cash at end of period", cf.ClosingCash, p.ClosingCash, true, 0)
+	if cf.Unexplained != 0 {
+		line("Unexplained difference", cf.Unexplained, p.Unexplained, false, 0)
+	}
+	doc.Sections = []export.Section{sec}
+	return nil
+}
+
+func (s *ReportExportService) buildEquityChanges(ctx context.Context, req ReportRequest, doc *export.Document) error {
+	if s.statements == nil {
+		return fmt.Errorf("equity changes export not configured")
+	}
+	from, to := s.statementRange(req)
+	es, err := s.statements.GetEquityChanges(ctx, req.Store, from, to, req.Comparison)
+	if err != nil {
+		return err
+	}
+	doc.Title = "Statement of Changes in Equity"
+	doc.Period = periodLabel(from, to)
+
+	cols := []export.Column{
+		{Header: "Description", Kind: export.KindText},
+		{Header: "Amount", Kind: export.KindMoney},
+	}
+	if es.Previous != nil {
+		cols = append(cols, export.Column{Header: "Previous", Kind: export.KindMoney})
+	}
+	summary := export.Section{Title: "Summary", Columns: cols}
+	var p EquityStatement
+	if es.Previous != nil {
+		p = *es.Previous
+	}
+	for _, l := range []struct {
+		label     string
+		cur, prev money.Amount
+		bold      bool
+	}{
+		{"Opening equity", es.OpeningEquity, p.OpeningEquity, true},
+		{"Net income", es.NetIncome, p.NetIncome, false},
+		{"Capital contributions", es.Contributions, p.Contributions, false},
+		{"Withdrawals", es.Withdrawals, p.Withdrawals, false},
+		{"Closing equity", es.ClosingEquity, p.ClosingEquity, true},
+	} {
+		cells := []interface{}{l.label, l.cur}
+		if es.Previous != nil {
+			cells = append(cells, l.prev)
+		}
+		summary.Rows = append(summary.Rows, export.Row{Cells: cells, Bold: l.bold})
+	}
+
+	detail := export.Section{Title: "By Account", Columns: []export.Column{
+		{Header: "Code", Kind: export.KindText},
+		{Header: "Account", Kind: export.KindText},
+		{Header: "Opening", Kind: export.KindMoney},
+		{Header: "Movement", Kind: export.KindMoney},
+		{Header: "Closing", Kind: export.KindMoney},
+	}}
+	for _, c := range es.Components {
+		detail.Rows = append(detail.Rows, export.Row{Cells: []interface{}{c.AccountCode, c.AccountName, c.Opening, c.Movement, c.Closing}})
+	}
+	detail.Rows = append(detail.Rows, export.Row{Cells: []interface{}{"", "Total", es.OpeningEquity, es.ClosingEquity - es.OpeningEquity, es.ClosingEquity}, Bold: true})
+	doc.Sections = []export.Section{summary, detail}
+	return nil
+}
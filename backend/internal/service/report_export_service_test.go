@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/export"
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
+	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
+)
+
+type fakeExportBalance struct{ asOf time.Time }
+
+func (f *fakeExportBalance) GetBalanceSheet(ctx context.Context, shop string, asOf time.Time) ([]CategoryBalance, error) {
+	f.asOf = asOf
+	return []CategoryBalance{{
+		Category: "Assets",
+		Accounts: []repository.AccountBalance{{AccountCode: "1.1", AccountName: "Kas", Balance: money.New(1500)}},
+		Total:    money.New(1500),
+	}}, nil
+}
+
+type fakeExportSales struct{ calls int }
+
+func (f *fakeExportSales) ListSalesProfit(ctx context.Context, channel, store, from, to, orderNo, sortBy, dir string, limit, offset int) ([]models.SalesProfit, int, error) {
+	f.calls++
+	total := limit + 1
+	n := limit
+	if offset >= limit {
+		n = total - offset
+	}
+	list := make([]models.SalesProfit, n)
+	for i := range list {
+		list[i] = models.SalesProfit{KodePesanan: "INV", AmountSales: 10, Profit: 2}
+	}
+	return list, total, nil
+}
+
+func TestReportExportBalanceSheetCSV(t *testing.T) {
+	bs := &fakeExportBalance{}
+	svc := NewReportExportService(export.CompanyInfo{Name: "PT Contoh"}, bs, nil, nil, nil, nil, nil, nil, nil)
+	asOf := time.Date(2025, 5, 31, 23, 59, 59, 0, time.UTC)
+
+	data, name, err := svc.Export(context.Background(), ReportRequest{Report: ReportBalanceSheet, Store: "ShopA", To: asOf}, export.FormatCSV)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if !bs.asOf.Equal(asOf) {
+		t.Errorf("balance sheet taken as of %v", bs.asOf)
+	}
+	if name != "balance-sheet_as-of-2025-05-31_ShopA.csv" {
+		t.Errorf("unexpected file name %q", name)
+	}
+	out := string(data)
+	for _, want := range []string{"PT Contoh", "Balance Sheet", "1.1,Kas,1500.00", ",Total Assets,1500.00"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("csv missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestReportExportSalesProfitPagesThroughAllRows(t *testing.T) {
+	sales := &fakeExportSales{}
+	svc := NewReportExportService(export.CompanyInfo{}, nil, nil, nil, sales, nil, nil, nil, nil)
+
+	doc, err := svc.BuildDocument(context.Background(), ReportRequest{Report: ReportSalesProfit})
+	if err != nil {
+		t.Fatalf("BuildDocument: %v", err)
+	}
+	if sales.calls != 2 {
+		t.Errorf("expected 2 page fetches, got %d", sales.calls)
+	}
+	rows := doc.Sections[0].Rows
+	if len(rows) != exportPageSize+2 {
+		t.Fatalf("expected %d rows including total, got %d", exportPageSize+2, len(rows))
+	}
+	total := rows[len(rows)-1]
+	if !total.Bold || total.Cells[2] != money.New(int64(10*(exportPageSize+1))) {
+		t.Errorf("unexpected total row %+v", total)
+	}
+}
+
+type fakeExportDiscrepancy struct {
+	listed, summed []repository.ShippingDiscrepancyQuery
+}
+
+func (f *fakeExportDiscrepancy) ListShippingDiscrepanciesForReport(ctx context.Context, q repository.ShippingDiscrepancyQuery, limit, offset int) ([]models.ShippingDiscrepancy, error) {
+	f.listed = append(f.listed, q)
+	return []models.ShippingDiscrepancy{{InvoiceNumber: "INV1", DiscrepancyType: "selisih_ongkir"}}, nil
+}
+
+func (f *fakeExportDiscrepancy) SumShippingDiscrepanciesForReport(ctx context.Context, q repository.ShippingDiscrepancyQuery) (map[string]money.Amount, error) {
+	f.summed = append(f.summed, q)
+	return map[string]money.Amount{"selisih_ongkir": money.New(1500)}, nil
+}
+
+func TestReportExportShippingDiscrepanciesFilterInQuery(t *testing.T) {
+	disc := &fakeExportDiscrepancy{}
+	svc := NewReportExportService(export.CompanyInfo{}, nil, nil, nil, nil, disc, nil, nil, nil)
+	from := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 5, 31, 23, 59, 59, 0, time.UTC)
+
+	doc, err := svc.BuildDocument(context.Background(), ReportRequest{Report: ReportShippingDiscrepancies, Store: "ShopA", From: from, To: to})
+	if err != nil {
+		t.Fatalf("BuildDocument: %v", err)
+	}
+	want := repository.ShippingDiscrepancyQuery{Store: "ShopA", From: from, To: to}
+	if len(disc.listed) != 1 || disc.listed[0] != want {
+		t.Errorf("rows queried with %+v, want %+v", disc.listed, want)
+	}
+	if len(disc.summed) != 1 || disc.summed[0] != want {
+		t.Errorf("summary queried with %+v, want %+v", disc.summed, want)
+	}
+	if doc.Sections[0].Title != "Summary (ShopA)" {
+		t.Errorf("unexpected summary title %q", doc.Sections[0].Title)
+	}
+}
+
+func TestReportExportUnknownOrUnconfigured(t *testing.T) {
+	svc := NewReportExportService(export.CompanyInfo{}, nil, nil, nil, nil, nil, nil, nil, nil)
+	if _, err := svc.BuildDocument(context.Background(), ReportRequest{Report: "nope"}); !errors.Is(err, ErrInvalidReportRequest) {
+		t.Errorf("expected ErrInvalidReportRequest for unknown report, got %v", err)
+	}
+	if _, err := svc.BuildDocument(context.Background(), ReportRequest{Report: ReportProfitLoss}); err == nil {
+		t.Error("expected error for unconfigured report")
+	}
+}
+
+func TestReportExportCashFlowWithComparison(t *testing.T) {
+	svc := NewReportExportService(export.CompanyInfo{}, nil, nil, nil, nil, nil, nil, nil, newStatementFixture())
+
+	doc, err := svc.BuildDocument(context.Background(), ReportRequest{
+		Report: ReportCashFlow, From: fsFrom, To: fsTo, Comparison: true,
+	})
+	if err != nil {
+		t.Fatalf("BuildDocument: %v", err)
+	}
+	sec := doc.Sections[0]
+	if len(sec.Columns) != 3 {
+		t.Fatalf("expected a previous-period column, got %d columns", len(sec.Columns))
+	}
+	last := sec.Rows[len(sec.Rows)-1]
+	if last.Cells[0] != "Cash at end of period" || last.Cells[1] != money.New(1250) {
+		t.Errorf("unexpected closing row %+v", last)
+	}
+}
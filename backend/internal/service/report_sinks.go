@@ -0,0 +1,176 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Report sink types accepted by subscriptions.
+const (
+	SinkEmail     = "email"
+	SinkWebhook   = "webhook"
+	SinkDirectory = "directory"
+)
+
+// ReportFile is a rendered report ready to be delivered.
+type ReportFile struct {
+	Name        string
+	ContentType string
+	Subject     string
+	Data        []byte
+}
+
+// ReportSink delivers a rendered report to target, whose meaning depends on
+// the sink: a comma separated address list, a URL or a sub-directory.
+type ReportSink interface {
+	Deliver(ctx context.Context, target string, f ReportFile) error
+}
+
+// SMTPSink mails reports as attachments.
+type SMTPSink struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	// send is swapped out in tests.
+	send func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewSMTPSink constructs an SMTPSink.
+func NewSMTPSink(host string, port int, username, password, from string) *SMTPSink {
+	return &SMTPSink{Host: host, Port: port, Username: username, Password: password, From: from, send: smtp.SendMail}
+}
+
+// Deliver sends f to every address in target.
+func (s *SMTPSink) Deliver(ctx context.Context, target string, f ReportFile) error {
+	if s.Host == "" {
+		return fmt.Errorf("smtp host not configured")
+	}
+	var to []string
+	for _, addr := range strings.Split(target, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			to = append(to, addr)
+		}
+	}
+	if len(to) == 0 {
+		return fmt.Errorf("no recipients")
+	}
+	msg, err := buildReportMail(s.From, to, f)
+	if err != nil {
+		return err
+	}
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	}
+	return s.send(fmt.Sprintf("%s:%d", s.Host, s.Port), auth, s.From, to, msg)
+}
+
+func buildReportMail(from string, to []string, f ReportFile) ([]byte, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+
+	text, err := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/plain; charset=utf-8"}})
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(text, "%s\r\n\r\nThe report is attached as %s.\r\n", f.Subject, f.Name)
+
+	att, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {fmt.Sprintf("%s; name=%q", f.ContentType, f.Name)},
+		"Content-Transfer-Encoding": {"base64"},
+		"Content-Disposition":       {fmt.Sprintf("attachment; filename=%q", f.Name)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	enc := base64.StdEncoding.EncodeToString(f.Data)
+	for len(enc) > 76 {
+		fmt.Fprintf(att, "%s\r\n", enc[:76])
+		enc = enc[76:]
+	}
+	fmt.Fprintf(att, "%s\r\n", enc)
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", f.Subject)
+	fmt.Fprintf(&msg, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", mw.Boundary())
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
+
+// WebhookSink POSTs the report body to a URL.
+type WebhookSink struct {
+	client *http.Client
+}
+
+// NewWebhookSink constructs a WebhookSink with the given request timeout.
+func NewWebhookSink(timeout time.Duration) *WebhookSink {
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	return &WebhookSink{client: &http.Client{Timeout: timeout}}
+}
+
+// Deliver posts f to the target URL. Any non-2xx response is an error.
+func (s *WebhookSink) Deliver(ctx context.Context, target string, f ReportFile) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(f.Data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", f.ContentType)
+	req.Header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", f.Name))
+	req.Header.Set("X-Report-Subject", f.Subject)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return nil
+}
+
+// DirectorySink writes reports below a base directory.
+type DirectorySink struct {
+	base string
+}
+
+// NewDirectorySink constructs a DirectorySink rooted at base.
+func NewDirectorySink(base string) *DirectorySink {
+	return &DirectorySink{base: base}
+}
+
+// Deliver writes f into the target sub-directory of the base directory.
+// Targets that would escape the base directory are rejected.
+func (s *DirectorySink) Deliver(ctx context.Context, target string, f ReportFile) error {
+	base, err := filepath.Abs(s.base)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Join(base, filepath.Clean("/"+target))
+	if dir != base && !strings.HasPrefix(dir, base+string(filepath.Separator)) {
+		return fmt.Errorf("target %q is outside the report directory", target)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, filepath.Base(f.Name)), f.Data, 0o644)
+}
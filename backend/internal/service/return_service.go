@@ -0,0 +1,359 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/cache"
+	"github.com/ramadhan22/dropship-erp/backend/internal/config"
+	"github.com/ramadhan22/dropship-erp/backend/internal/logutil"
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
+	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
+)
+
+var returnLog = logutil.NewLogger("return-service", logutil.INFO)
+
+// ErrInvalidReturnRefund is returned when a supplier refund update is not
+// allowed, e.g. an unknown status or a second refund for the same return.
+var ErrInvalidReturnRefund = errors.New("invalid supplier refund")
+
+// returnSyncWindow is the widest create_time range get_return_list accepts.
+const returnSyncWindow = 15 * 24 * time.Hour
+
+// returnWriteTags are invalidated after a supplier refund journal is posted.
+var returnWriteTags = []string{cache.TagJournals, cache.TagReports}
+
+// ReturnRepoInterface is the storage used by ReturnService.
+type ReturnRepoInterface interface {
+	Upsert(ctx context.Context, ret *models.OrderReturn) error
+	ReplaceItems(ctx context.Context, returnID int64, items []models.OrderReturnItem) error
+	Get(ctx context.Context, id int64) (*models.OrderReturn, error)
+	List(ctx context.Context, f models.ReturnFilter, limit, offset int) ([]models.OrderReturn, int, error)
+	SetSupplierRefund(ctx context.Context, id int64, status string, amount money.Amount, at *time.Time, note *string, journalID *int64) (bool, error)
+	Losses(ctx context.Context, store string, from, to *time.Time) ([]models.ReturnLoss, error)
+}
+
+// ReturnPurchaseRepo links returns to dropship purchases.
+type ReturnPurchaseRepo interface {
+	GetDropshipPurchaseByInvoice(ctx context.Context, invoice string) (*models.DropshipPurchase, error)
+}
+
+// ReturnJournalRepo posts the supplier refund journal.
+type ReturnJournalRepo interface {
+	CreateJournalEntry(ctx context.Context, e *models.JournalEntry) (int64, error)
+	InsertJournalLines(ctx context.Context, lines []models.JournalLine) error
+}
+
+// ReturnSource fetches marketplace returns created in [from, to), keyed by
+// store name. An empty store means every connected store.
+type ReturnSource interface {
+	FetchReturns(ctx context.Context, store string, from, to time.Time) (map[string][]models.ShopeeOrderReturn, error)
+}
+
+// ReturnSyncResult summarises one SyncReturns run.
+type ReturnSyncResult struct {
+	Fetched int `json:"fetched"`
+	Linked  int `json:"linked"`
+}
+
+// ReturnService persists marketplace returns and tracks whether the supplier
+// refunded the product cost of the returned goods.
+type ReturnService struct {
+	db          *sqlx.DB
+	repo        ReturnRepoInterface
+	dropRepo    ReturnPurchaseRepo
+	journalRepo ReturnJournalRepo
+	source      ReturnSource
+	cache       Cache
+}
+
+// NewReturnService constructs a ReturnService.
+func NewReturnService(db *sqlx.DB, repo ReturnRepoInterface, dropRepo ReturnPurchaseRepo, journalRepo ReturnJournalRepo, source ReturnSource) *ReturnService {
+	return &ReturnService{db: db, repo: repo, dropRepo: dropRepo, journalRepo: journalRepo, source: source}
+}
+
+// SetCache enables invalidation of cached reports after refunds are journaled.
+func (s *ReturnService) SetCache(c Cache) {
+	s.cache = c
+}
+
+// SyncReturns fetches returns created in [from, to) and upserts them with
+// their items. Returns are linked to the dropship purchase whose channel
+// invoice equals the order_sn.
+func (s *ReturnService) SyncReturns(ctx context.Context, store string, from, to time.Time) (*ReturnSyncResult, error) {
+	if s.source == nil {
+		return nil, fmt.Errorf("return source not configured")
+	}
+	if !to.After(from) {
+		return nil, fmt.Errorf("invalid date range")
+	}
+	byStore, err := s.source.FetchReturns(ctx, store, from, to)
+	if err != nil {
+		return nil, err
+	}
+	res := &ReturnSyncResult{}
+	for name, list := range byStore {
+		for _, r := range list {
+			ret := returnFromShopee(name, r)
+			if s.dropRepo != nil {
+				if dp, err := s.dropRepo.GetDropshipPurchaseByInvoice(ctx, r.OrderSN); err == nil && dp != nil {
+					ret.KodePesanan = &dp.KodePesanan
+					res.Linked++
+				}
+			}
+			if err := s.saveReturn(ctx, ret); err != nil {
+				return res, fmt.Errorf("save return %s: %w", r.ReturnSN, err)
+			}
+			res.Fetched++
+		}
+	}
+	return res, nil
+}
+
+func (s *ReturnService) saveReturn(ctx context.Context, ret *models.OrderReturn) error {
+	var tx *sqlx.Tx
+	repo := s.repo
+	if s.db != nil {
+		var err error
+		tx, err = s.db.BeginTxx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+		repo = repository.NewOrderReturnRepo(tx)
+	}
+	if err := repo.Upsert(ctx, ret); err != nil {
+		return err
+	}
+	if err := repo.ReplaceItems(ctx, ret.ID, ret.Items); err != nil {
+		return err
+	}
+	if tx != nil {
+		return tx.Commit()
+	}
+	return nil
+}
+
+func returnFromShopee(store string, r models.ShopeeOrderReturn) *models.OrderReturn {
+	ret := &models.OrderReturn{
+		ReturnSN:                 r.ReturnSN,
+		OrderSN:                  r.OrderSN,
+		Store:                    store,
+		Status:                   r.Status,
+		NegotiationStatus:        r.NegotiationStatus,
+		SellerProofStatus:        r.SellerProofStatus,
+		SellerCompensationStatus: r.SellerCompensationStatus,
+		Reason:                   r.Reason,
+		RefundAmount:             money.FromFloat(r.RefundAmount),
+		Currency:                 r.Currency,
+		BuyerUsername:            r.User.Username,
+		ImageURLs:                pq.StringArray{},
+		SellerProofURLs:          pq.StringArray{},
+		ReturnCreatedAt:          time.Unix(r.CreateTime, 0),
+		ReturnUpdatedAt:          time.Unix(r.UpdateTime, 0),
+	}
+	if r.DueDate > 0 {
+		due := time.Unix(r.DueDate, 0)
+		ret.DueDate = &due
+	}
+	for _, img := range r.ImageInfo {
+		ret.ImageURLs = append(ret.ImageURLs, img.ImageURL)
+	}
+	for _, p := range r.SellerProof {
+		ret.SellerProofURLs = append(ret.SellerProofURLs, p.ImageURL)
+	}
+	for _, it := range r.Item {
+		ret.Items = append(ret.Items, models.OrderReturnItem{
+			ItemID:     it.ItemID,
+			ModelID:    it.ModelID,
+			ItemName:   it.ItemName,
+			ItemSKU:    it.ItemSku,
+			ModelName:  it.ModelName,
+			ModelSKU:   it.ModelSku,
+			Qty:        it.Amount,
+			ItemPrice:  money.FromFloat(it.ItemPrice),
+			IsMainItem: it.IsMainItem,
+		})
+	}
+	return ret
+}
+
+// ListReturns returns stored returns matching f with the total match count.
+func (s *ReturnService) ListReturns(ctx context.Context, f models.ReturnFilter, limit, offset int) ([]models.OrderReturn, int, error) {
+	return s.repo.List(ctx, f, limit, offset)
+}
+
+// GetReturn fetches a stored return with its items.
+func (s *ReturnService) GetReturn(ctx context.Context, id int64) (*models.OrderReturn, error) {
+	return s.repo.Get(ctx, id)
+}
+
+// ReturnLosses reports returned-goods losses per store and SKU.
+func (s *ReturnService) ReturnLosses(ctx context.Context, store string, from, to *time.Time) ([]models.ReturnLoss, error) {
+	return s.repo.Losses(ctx, store, from, to)
+}
+
+// RecordSupplierRefund stores the supplier's answer to a return. A refund
+// posts Dr Saldo Jakmall / Cr HPP for the refunded amount so the product
+// cost of the returned goods is recovered. A refunded return is final.
+func (s *ReturnService) RecordSupplierRefund(ctx context.Context, id int64, in models.SupplierRefundInput) (*models.OrderReturn, error) {
+	switch in.Status {
+	case models.SupplierRefundPending, models.SupplierRefundRejected:
+		in.Amount = money.Zero
+	case models.SupplierRefundRefunded:
+		if in.Amount.Sen() <= 0 {
+			return nil, fmt.Errorf("%w: amount must be positive", ErrInvalidReturnRefund)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidReturnRefund, in.Status)
+	}
+
+	var tx *sqlx.Tx
+	repo := s.repo
+	jrRepo := s.journalRepo
+	if s.db != nil {
+		var err error
+		tx, err = s.db.BeginTxx(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		defer tx.Rollback()
+		repo = repository.NewOrderReturnRepo(tx)
+		jrRepo = repository.NewJournalRepo(tx)
+	}
+
+	ret, err := repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if ret.SupplierRefundStatus == models.SupplierRefundRefunded {
+		return nil, fmt.Errorf("%w: return %s already refunded", ErrInvalidReturnRefund, ret.ReturnSN)
+	}
+
+	var at *time.Time
+	var journalID *int64
+	if in.Status == models.SupplierRefundRefunded {
+		date := time.Now()
+		if in.Date != nil {
+			date = *in.Date
+		}
+		at = &date
+		jid, err := postSupplierRefundJournal(ctx, jrRepo, ret, in.Amount, date)
+		if err != nil {
+			return nil, err
+		}
+		journalID = &jid
+	}
+	// The guarded update makes a concurrent refund of the same return fail
+	// here, rolling back its journal, instead of posting it twice.
+	ok, err := repo.SetSupplierRefund(ctx, id, in.Status, in.Amount, at, in.Note, journalID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: return %s already refunded", ErrInvalidReturnRefund, ret.ReturnSN)
+	}
+	if tx != nil {
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+	}
+	if journalID != nil {
+		invalidateCache(ctx, s.cache, returnWriteTags...)
+	}
+
+	ret.SupplierRefundStatus = in.Status
+	ret.SupplierRefundAmount = in.Amount
+	ret.SupplierRefundedAt = at
+	ret.SupplierRefundNote = in.Note
+	ret.SupplierJournalID = journalID
+	return ret, nil
+}
+
+func postSupplierRefundJournal(ctx context.Context, jr ReturnJournalRepo, ret *models.OrderReturn, amount money.Amount, date time.Time) (int64, error) {
+	desc := fmt.Sprintf("Refund supplier retur %s", ret.OrderSN)
+	je := &models.JournalEntry{
+		EntryDate:    date,
+		Description:  &desc,
+		SourceType:   "supplier_refund",
+		SourceID:     ret.ReturnSN,
+		ShopUsername: ret.Store,
+		Store:        ret.Store,
+		CreatedAt:    time.Now(),
+	}
+	jid, err := jr.CreateJournalEntry(ctx, je)
+	if err != nil {
+		return 0, err
+	}
+	lines := []models.JournalLine{
+		{JournalID: jid, AccountID: 11009, IsDebit: true, Amount: amount},
+		{JournalID: jid, AccountID: 5001, IsDebit: false, Amount: amount},
+	}
+	if err := jr.InsertJournalLines(ctx, lines); err != nil {
+		return 0, err
+	}
+	return jid, nil
+}
+
+// shopeeReturnSource reads returns from get_return_list for stores with
+// Shopee tokens.
+type shopeeReturnSource struct {
+	client      *ShopeeClient
+	channelRepo *repository.ChannelRepo
+}
+
+// NewShopeeReturnSource returns a ReturnSource backed by the Shopee API.
+func NewShopeeReturnSource(cfg config.ShopeeAPIConfig, channelRepo *repository.ChannelRepo) ReturnSource {
+	return &shopeeReturnSource{client: NewShopeeClient(cfg), channelRepo: channelRepo}
+}
+
+func (s *shopeeReturnSource) FetchReturns(ctx context.Context, store string, from, to time.Time) (map[string][]models.ShopeeOrderReturn, error) {
+	stores, err := s.channelRepo.GetStoresWithTokens(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stores: %w", err)
+	}
+	out := make(map[string][]models.ShopeeOrderReturn)
+	for i := range stores {
+		st := &stores[i]
+		if store != "" && st.NamaToko != store {
+			continue
+		}
+		if err := ensureTokenValid(ctx, st, s.client, s.channelRepo); err != nil {
+			returnLog.Warn(ctx, "FetchReturns", "Skipping store with invalid token", map[string]interface{}{
+				"store": st.NamaToko,
+				"error": err,
+			})
+			continue
+		}
+		for start := from; start.Before(to); start = start.Add(returnSyncWindow) {
+			end := start.Add(returnSyncWindow)
+			if end.After(to) {
+				end = to
+			}
+			for page := 0; ; page++ {
+				params := map[string]string{
+					"page_no":          strconv.Itoa(page),
+					"page_size":        "50",
+					"create_time_from": strconv.FormatInt(start.Unix(), 10),
+					"create_time_to":   strconv.FormatInt(end.Unix(), 10),
+				}
+				resp, err := s.client.GetReturnList(ctx, *st.AccessToken, *st.ShopID, params)
+				if err != nil {
+					return out, fmt.Errorf("get returns for %s: %w", st.NamaToko, err)
+				}
+				out[st.NamaToko] = append(out[st.NamaToko], resp.Response.Return...)
+				if !resp.Response.More {
+					break
+				}
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
+)
+
+type fakeReturnRepo struct {
+	byID  map[int64]*models.OrderReturn
+	bySN  map[string]int64
+	items map[int64][]models.OrderReturnItem
+	next  int64
+}
+
+func newFakeReturnRepo() *fakeReturnRepo {
+	return &fakeReturnRepo{byID: map[int64]*models.OrderReturn{}, bySN: map[string]int64{}, items: map[int64][]models.OrderReturnItem{}}
+}
+
+func (f *fakeReturnRepo) Upsert(ctx context.Context, ret *models.OrderReturn) error {
+	if id, ok := f.bySN[ret.ReturnSN]; ok {
+		old := f.byID[id]
+		ret.ID = id
+		if ret.KodePesanan == nil {
+			ret.KodePesanan = old.KodePesanan
+		}
+		ret.SupplierRefundStatus = old.SupplierRefundStatus
+		ret.SupplierRefundAmount = old.SupplierRefundAmount
+	} else {
+		f.next++
+		ret.ID = f.next
+		ret.SupplierRefundStatus = models.SupplierRefundPending
+		f.bySN[ret.ReturnSN] = ret.ID
+	}
+	cp := *ret
+	cp.Items = nil
+	f.byID[ret.ID] = &cp
+	return nil
+}
+
+func (f *fakeReturnRepo) ReplaceItems(ctx context.Context, id int64, items []models.OrderReturnItem) error {
+	f.items[id] = items
+	return nil
+}
+
+func (f *fakeReturnRepo) Get(ctx context.Context, id int64) (*models.OrderReturn, error) {
+	r, ok := f.byID[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	cp := *r
+	cp.Items = f.items[id]
+	return &cp, nil
+}
+
+func (f *fakeReturnRepo) List(ctx context.Context, flt models.ReturnFilter, limit, offset int) ([]models.OrderReturn, int, error) {
+	return nil, 0, nil
+}
+
+func (f *fakeReturnRepo) SetSupplierRefund(ctx context.Context, id int64, status string, amount money.Amount, at *time.Time, note *string, journalID *int64) (bool, error) {
+	r := f.byID[id]
+	if r.SupplierRefundStatus == models.SupplierRefundRefunded {
+		return false, nil
+	}
+	r.SupplierRefundStatus = status
+	r.SupplierRefundAmount = amount
+	r.SupplierRefundedAt = at
+	r.SupplierJournalID = journalID
+	return true, nil
+}
+
+func (f *fakeReturnRepo) Losses(ctx context.Context, store string, from, to *time.Time) ([]models.ReturnLoss, error) {
+	return nil, nil
+}
+
+type fakeReturnSource struct {
+	returns map[string][]models.ShopeeOrderReturn
+}
+
+func (f *fakeReturnSource) FetchReturns(ctx context.Context, store string, from, to time.Time) (map[string][]models.ShopeeOrderReturn, error) {
+	return f.returns, nil
+}
+
+func TestSyncReturnsLinksPurchase(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeReturnRepo()
+	drop := &fakeDropRepoRec{data: map[string]*models.DropshipPurchase{
+		"INV-1": {KodePesanan: "DP-1", KodeInvoiceChannel: "INV-1"},
+	}}
+	src := &fakeReturnSource{returns: map[string][]models.ShopeeOrderReturn{
+		"ShopA": {
+			{ReturnSN: "R-1", OrderSN: "INV-1", Status: "ACCEPTED", RefundAmount: 125000.5, CreateTime: 1700000000,
+				Item: []models.ShopeeReturnItem{{ItemName: "Kaos", ModelSku: "SKU-1", Amount: 2, ItemPrice: 62500.25}}},
+			{ReturnSN: "R-2", OrderSN: "INV-X", Status: "REQUESTED", CreateTime: 1700000000},
+		},
+	}}
+	svc := NewReturnService(nil, repo, drop, nil, src)
+
+	res, err := svc.SyncReturns(ctx, "", time.Unix(1690000000, 0), time.Unix(1710000000, 0))
+	if err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if res.Fetched != 2 || res.Linked != 1 {
+		t.Errorf("unexpected result %+v", res)
+	}
+	r1, _ := repo.Get(ctx, repo.bySN["R-1"])
+	if r1.KodePesanan == nil || *r1.KodePesanan != "DP-1" || r1.Store != "ShopA" {
+		t.Errorf("return not linked: %+v", r1)
+	}
+	if r1.RefundAmount != money.MustParse("125000.50") {
+		t.Errorf("refund amount %s", r1.RefundAmount)
+	}
+	if len(r1.Items) != 1 || r1.Items[0].Qty != 2 || r1.Items[0].ItemPrice != money.MustParse("62500.25") {
+		t.Errorf("unexpected items %+v", r1.Items)
+	}
+}
+
+func TestRecordSupplierRefund(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeReturnRepo()
+	ret := &models.OrderReturn{ReturnSN: "R-1", OrderSN: "INV-1", Store: "ShopA"}
+	_ = repo.Upsert(ctx, ret)
+	jr := &fakeJournalRepoRec{}
+	svc := NewReturnService(nil, repo, nil, jr, nil)
+
+	if _, err := svc.RecordSupplierRefund(ctx, ret.ID, models.SupplierRefundInput{Status: models.SupplierRefundRefunded}); !errors.Is(err, ErrInvalidReturnRefund) {
+		t.Fatalf("expected ErrInvalidReturnRefund for zero amount, got %v", err)
+	}
+
+	got, err := svc.RecordSupplierRefund(ctx, ret.ID, models.SupplierRefundInput{Status: models.SupplierRefundRefunded, Amount: money.New(40000)})
+	if err != nil {
+		t.Fatalf("refund: %v", err)
+	}
+	if got.SupplierJournalID == nil || got.SupplierRefundStatus != models.SupplierRefundRefunded {
+		t.Errorf("unexpected return %+v", got)
+	}
+	if len(jr.entries) != 1 || jr.entries[0].SourceType != "supplier_refund" || jr.entries[0].SourceID != "R-1" {
+		t.Fatalf("unexpected journal %+v", jr.entries)
+	}
+	var debit, credit bool
+	for _, l := range jr.lines {
+		if l.AccountID == 11009 && l.IsDebit && l.Amount == money.New(40000) {
+			debit = true
+		}
+		if l.AccountID == 5001 && !l.IsDebit && l.Amount == money.New(40000) {
+			credit = true
+		}
+	}
+	if !debit || !credit {
+		t.Errorf("unexpected lines %+v", jr.lines)
+	}
+
+	if _, err := svc.RecordSupplierRefund(ctx, ret.ID, models.SupplierRefundInput{Status: models.SupplierRefundRefunded, Amount: money.New(40000)}); !errors.Is(err, ErrInvalidReturnRefund) {
+		t.Errorf("expected second refund to be rejected, got %v", err)
+	}
+	if len(jr.entries) != 1 {
+		t.Errorf("expected a single refund journal, got %d", len(jr.entries))
+	}
+}
+
+// staleReturnRepo returns the return as it was before a concurrent refund
+// committed, as a second request that read it first would see it.
+type staleReturnRepo struct {
+	*fakeReturnRepo
+	stale models.OrderReturn
+}
+
+func (f *staleReturnRepo) Get(ctx context.Context, id int64) (*models.OrderReturn, error) {
+	cp := f.stale
+	return &cp, nil
+}
+
+func TestRecordSupplierRefundRejectsConcurrentRefund(t *testing.T) {
+	ctx := context.Background()
+	inner := newFakeReturnRepo()
+	ret := &models.OrderReturn{ReturnSN: "R-2", OrderSN: "INV-2", Store: "ShopA"}
+	_ = inner.Upsert(ctx, ret)
+	repo := &staleReturnRepo{fakeReturnRepo: inner, stale: *inner.byID[ret.ID]}
+	inner.byID[ret.ID].SupplierRefundStatus = models.SupplierRefundRefunded
+
+	svc := NewReturnService(nil, repo, nil, &fakeJournalRepoRec{}, nil)
+	_, err := svc.RecordSupplierRefund(ctx, ret.ID, models.SupplierRefundInput{Status: models.SupplierRefundRefunded, Amount: money.New(40000)})
+	if !errors.Is(err, ErrInvalidReturnRefund) {
+		t.Fatalf("expected concurrent refund to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// tickerLoop runs a scheduler's tick in one goroutine. Stop ends intake
+// without cancelling the tick in progress, so a supervisor can let running
+// work finish; cancelling the context passed to run aborts it.
+// Schedulers embed it to get Stop and Done.
+type tickerLoop struct {
+	initOnce sync.Once
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+func (l *tickerLoop) init() {
+	l.initOnce.Do(func() {
+		l.stop = make(chan struct{})
+		l.done = make(chan struct{})
+	})
+}
+
+// run calls tick every interval until Stop is called or ctx is done.
+func (l *tickerLoop) run(ctx context.Context, interval time.Duration, tick func(context.Context)) {
+	l.init()
+	go func() {
+		defer close(l.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-l.stop:
+				return
+			case <-ticker.C:
+				if l.stopping() {
+					return
+				}
+				tick(ctx)
+			}
+		}
+	}()
+}
+
+// stopping reports whether Stop was called. Ticks that work through a list
+// check it between items.
+func (l *tickerLoop) stopping() bool {
+	l.init()
+	select {
+	case <-l.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// Stop stops the loop from starting new ticks. It is safe to call more than once.
+func (l *tickerLoop) Stop() {
+	l.init()
+	l.stopOnce.Do(func() { close(l.stop) })
+}
+
+// Done is closed once the loop goroutine has returned.
+func (l *tickerLoop) Done() <-chan struct{} {
+	l.init()
+	return l.done
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/jmoiron/sqlx"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
 )
 
@@ -14,10 +15,13 @@ type ShippingDiscrepancyRepoInterface interface {
 	InsertShippingDiscrepancy(ctx context.Context, discrepancy *models.ShippingDiscrepancy) error
 	GetShippingDiscrepanciesByStore(ctx context.Context, storeName string, limit, offset int) ([]models.ShippingDiscrepancy, error)
 	GetAllShippingDiscrepancies(ctx context.Context, limit, offset int) ([]models.ShippingDiscrepancy, error)
+	ListShippingDiscrepanciesFiltered(ctx context.Context, params *models.FilterParams) (*models.QueryResult, error)
 	GetShippingDiscrepanciesByType(ctx context.Context, discrepancyType string, limit, offset int) ([]models.ShippingDiscrepancy, error)
 	CountShippingDiscrepanciesByDateRange(ctx context.Context, startDate, endDate time.Time) (map[string]int, error)
 	GetShippingDiscrepancySumsByDateRange(ctx context.Context, startDate, endDate time.Time) (map[string]float64, error)
 	GetShippingDiscrepancyByInvoice(ctx context.Context, invoiceNumber string) (*models.ShippingDiscrepancy, error)
+	ListShippingDiscrepanciesForReport(ctx context.Context, q repository.ShippingDiscrepancyQuery, limit, offset int) ([]models.ShippingDiscrepancy, error)
+	SumShippingDiscrepanciesForReport(ctx context.Context, q repository.ShippingDiscrepancyQuery) (map[string]money.Amount, error)
 }
 
 type ShippingDiscrepancyService struct {
@@ -45,6 +49,11 @@ func (s *ShippingDiscrepancyService) GetShippingDiscrepancies(
 	return s.discRepo.GetAllShippingDiscrepancies(ctx, limit, offset)
 }
 
+// ListShippingDiscrepanciesFiltered uses the filtering framework to fetch discrepancies.
+func (s *ShippingDiscrepancyService) ListShippingDiscrepanciesFiltered(ctx context.Context, params *models.FilterParams) (*models.QueryResult, error) {
+	return s.discRepo.ListShippingDiscrepanciesFiltered(ctx, params)
+}
+
 // GetShippingDiscrepancyStats retrieves shipping discrepancy statistics for a date range
 func (s *ShippingDiscrepancyService) GetShippingDiscrepancyStats(
 	ctx context.Context,
@@ -61,6 +70,25 @@ func (s *ShippingDiscrepancyService) GetShippingDiscrepancySums(
 	return s.discRepo.GetShippingDiscrepancySumsByDateRange(ctx, startDate, endDate)
 }
 
+// ListShippingDiscrepanciesForReport returns one page of the discrepancies
+// matching q.
+func (s *ShippingDiscrepancyService) ListShippingDiscrepanciesForReport(
+	ctx context.Context,
+	q repository.ShippingDiscrepancyQuery,
+	limit, offset int,
+) ([]models.ShippingDiscrepancy, error) {
+	return s.discRepo.ListShippingDiscrepanciesForReport(ctx, q, limit, offset)
+}
+
+// SumShippingDiscrepanciesForReport totals the discrepancies matching q by
+// type.
+func (s *ShippingDiscrepancyService) SumShippingDiscrepanciesForReport(
+	ctx context.Context,
+	q repository.ShippingDiscrepancyQuery,
+) (map[string]money.Amount, error) {
+	return s.discRepo.SumShippingDiscrepanciesForReport(ctx, q)
+}
+
 // GetShippingDiscrepancyByInvoice retrieves a shipping discrepancy by invoice number
 func (s *ShippingDiscrepancyService) GetShippingDiscrepancyByInvoice(
 	ctx context.Context,
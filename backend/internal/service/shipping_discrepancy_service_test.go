@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
+	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
 )
 
 // mockShippingDiscrepancyRepo is a mock repository for testing shipping discrepancy service
@@ -30,6 +32,10 @@ func (m *mockShippingDiscrepancyRepo) GetAllShippingDiscrepancies(ctx context.Co
 	return m.discrepancies, nil
 }
 
+func (m *mockShippingDiscrepancyRepo) ListShippingDiscrepanciesFiltered(ctx context.Context, params *models.FilterParams) (*models.QueryResult, error) {
+	return models.NewQueryResult(m.discrepancies, len(m.discrepancies), 1, 20), nil
+}
+
 func (m *mockShippingDiscrepancyRepo) GetShippingDiscrepanciesByType(ctx context.Context, discrepancyType string, limit, offset int) ([]models.ShippingDiscrepancy, error) {
 	return m.discrepancies, nil
 }
@@ -42,6 +48,18 @@ func (m *mockShippingDiscrepancyRepo) GetShippingDiscrepancySumsByDateRange(ctx
 	return m.sumsResult, nil
 }
 
+func (m *mockShippingDiscrepancyRepo) ListShippingDiscrepanciesForReport(ctx context.Context, q repository.ShippingDiscrepancyQuery, limit, offset int) ([]models.ShippingDiscrepancy, error) {
+	return m.discrepancies, nil
+}
+
+func (m *mockShippingDiscrepancyRepo) SumShippingDiscrepanciesForReport(ctx context.Context, q repository.ShippingDiscrepancyQuery) (map[string]money.Amount, error) {
+	sums := make(map[string]money.Amount, len(m.sumsResult))
+	for t, v := range m.sumsResult {
+		sums[t] = money.FromFloat(v)
+	}
+	return sums, nil
+}
+
 func (m *mockShippingDiscrepancyRepo) GetShippingDiscrepancyByInvoice(ctx context.Context, invoiceNumber string) (*models.ShippingDiscrepancy, error) {
 	for _, disc := range m.discrepancies {
 		if disc.InvoiceNumber == invoiceNumber {
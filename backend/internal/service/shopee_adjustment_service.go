@@ -9,6 +9,7 @@ import (
 
 	"github.com/jmoiron/sqlx"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 	"github.com/xuri/excelize/v2"
 )
 
@@ -17,6 +18,7 @@ type AdjustmentRepo interface {
 	Insert(ctx context.Context, a *models.ShopeeAdjustment) error
 	Delete(ctx context.Context, order string, t time.Time, typ string) error
 	List(ctx context.Context, from, to string) ([]models.ShopeeAdjustment, error)
+	ListFiltered(ctx context.Context, params *models.FilterParams) (*models.QueryResult, error)
 	ListByOrder(ctx context.Context, order string) ([]models.ShopeeAdjustment, error)
 	Get(ctx context.Context, id int64) (*models.ShopeeAdjustment, error)
 	Update(ctx context.Context, a *models.ShopeeAdjustment) error
@@ -27,16 +29,26 @@ type ShopeeAdjustmentService struct {
 	db          *sqlx.DB
 	repo        AdjustmentRepo
 	journalRepo ShopeeJournalRepo
+	cache       Cache
 }
 
 func NewShopeeAdjustmentService(db *sqlx.DB, r AdjustmentRepo, jr ShopeeJournalRepo) *ShopeeAdjustmentService {
 	return &ShopeeAdjustmentService{db: db, repo: r, journalRepo: jr}
 }
 
+// SetCache enables invalidation of cached reports after adjustment journals
+// change.
+func (s *ShopeeAdjustmentService) SetCache(c Cache) { s.cache = c }
+
 func (s *ShopeeAdjustmentService) List(ctx context.Context, from, to string) ([]models.ShopeeAdjustment, error) {
 	return s.repo.List(ctx, from, to)
 }
 
+// ListFiltered uses the filtering framework to fetch adjustments.
+func (s *ShopeeAdjustmentService) ListFiltered(ctx context.Context, params *models.FilterParams) (*models.QueryResult, error) {
+	return s.repo.ListFiltered(ctx, params)
+}
+
 func (s *ShopeeAdjustmentService) ImportXLSX(ctx context.Context, r io.Reader) (int, error) {
 	f, err := excelize.OpenReader(r)
 	if err != nil {
@@ -71,6 +83,9 @@ func (s *ShopeeAdjustmentService) ImportXLSX(ctx context.Context, r io.Reader) (
 		}
 	}
 
+	// Rows are posted one by one, so a failed import may still have
+	// posted some.
+	defer invalidateCache(ctx, s.cache, journalWriteTags...)
 	inserted := 0
 	if adjSheet != "" {
 		n, err := s.importAdjustmentSheet(ctx, f, adjSheet, store)
@@ -117,7 +132,7 @@ func (s *ShopeeAdjustmentService) importAdjustmentSheet(ctx context.Context, f *
 		if err != nil {
 			continue
 		}
-		amt, err := parseFloat(row[4])
+		amt, err := money.Parse(row[4])
 		if err != nil {
 			continue
 		}
@@ -182,8 +197,8 @@ func (s *ShopeeAdjustmentService) importSFDsheet(ctx context.Context, f *exceliz
 		if order == "" {
 			continue
 		}
-		est, err1 := parseFloat(fmt.Sprint(row[1]))
-		act, err2 := parseFloat(fmt.Sprint(row[2]))
+		est, err1 := money.Parse(fmt.Sprint(row[1]))
+		act, err2 := money.Parse(fmt.Sprint(row[2]))
 		if err1 != nil || err2 != nil {
 			continue
 		}
@@ -281,6 +296,7 @@ func (s *ShopeeAdjustmentService) Delete(ctx context.Context, id int64) error {
 				return err
 			}
 		}
+		invalidateCache(ctx, s.cache, journalWriteTags...)
 	}
 	return nil
 }
@@ -303,6 +319,7 @@ func (s *ShopeeAdjustmentService) Update(ctx context.Context, a *models.ShopeeAd
 		if err := s.createJournal(ctx, s.journalRepo, a); err != nil {
 			return err
 		}
+		invalidateCache(ctx, s.cache, journalWriteTags...)
 	}
 	return nil
 }
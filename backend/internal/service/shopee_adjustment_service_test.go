@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
 	"github.com/xuri/excelize/v2"
 )
@@ -31,6 +32,10 @@ func (f *fakeAdjRepo) List(ctx context.Context, from, to string) ([]models.Shope
 	return nil, nil
 }
 
+func (f *fakeAdjRepo) ListFiltered(ctx context.Context, params *models.FilterParams) (*models.QueryResult, error) {
+	return &models.QueryResult{}, nil
+}
+
 func (f *fakeAdjRepo) ListByOrder(ctx context.Context, order string) ([]models.ShopeeAdjustment, error) {
 	return nil, nil
 }
@@ -79,7 +84,7 @@ func (f *fakeJournalRepoA) GetLinesByJournalID(ctx context.Context, id int64) ([
 	return nil, nil
 }
 
-func (f *fakeJournalRepoA) UpdateJournalLineAmount(ctx context.Context, lineID int64, amount float64) error {
+func (f *fakeJournalRepoA) UpdateJournalLineAmount(ctx context.Context, lineID int64, amount money.Amount) error {
 	return nil
 }
 
@@ -9,19 +9,23 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/ramadhan22/dropship-erp/backend/internal/apperr"
 	"github.com/ramadhan22/dropship-erp/backend/internal/config"
 	"github.com/ramadhan22/dropship-erp/backend/internal/logutil"
+	"github.com/ramadhan22/dropship-erp/backend/internal/metrics"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/tracing"
 	shopeego "github.com/teacat/shopeego"
 )
 
+var shopeeLog = logutil.NewLogger("shopee-client", logutil.INFO)
+
 // ShopeeClient handles calls to Shopee partner API.
 type ShopeeClient struct {
 	BaseURL      string
@@ -63,12 +67,48 @@ func NewShopeeClient(cfg config.ShopeeAPIConfig) *ShopeeClient {
 		ShopID:       cfg.ShopID,
 		AccessToken:  cfg.AccessToken,
 		RefreshToken: cfg.RefreshToken,
-		httpClient:   &http.Client{Timeout: 15 * time.Second},
+		httpClient:   &http.Client{Timeout: 15 * time.Second, Transport: shopeeTransport{next: http.DefaultTransport}},
 		rateLimiter:  rateLimiter,
 		retryConfig:  retryConfig,
 	}
 }
 
+// shopeeTransport records the count, latency and failures of every Shopee
+// API call, labelled by the endpoint path, and a client span per attempt.
+// Query strings carry the access token and signature, so only the path is
+// recorded.
+type shopeeTransport struct {
+	next http.RoundTripper
+}
+
+func (t shopeeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	endpoint := req.URL.Path
+	ctx, span := tracing.Start(req.Context(), "shopee "+endpoint, tracing.KindClient)
+	defer span.End()
+	span.SetAttr("http.method", req.Method)
+	span.SetAttr("http.host", req.URL.Host)
+	span.SetAttr("shopee.endpoint", endpoint)
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	metrics.ShopeeRequestDuration.Observe(time.Since(start).Seconds(), endpoint)
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+		span.SetAttr("http.status_code", resp.StatusCode)
+	}
+	metrics.ShopeeRequests.Inc(endpoint, code)
+	switch {
+	case err != nil:
+		metrics.ShopeeErrors.Inc(endpoint)
+		span.RecordError(err)
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		metrics.ShopeeErrors.Inc(endpoint)
+		span.RecordError(fmt.Errorf("HTTP %d", resp.StatusCode))
+	}
+	return resp, err
+}
+
 // NewShopeeClientWithConfig constructs a ShopeeClient with custom rate limiting and retry configuration
 func NewShopeeClientWithConfig(cfg config.ShopeeAPIConfig, rateLimit int, maxAttempts int, baseDelay time.Duration) *ShopeeClient {
 	client := NewShopeeClient(cfg)
@@ -109,8 +149,27 @@ func (c *ShopeeClient) signSimple(path string, ts int64) string {
 
 // ========== Optimized HTTP Request Methods ==========
 
-// makeRequestWithRetry executes HTTP requests with rate limiting and retry logic
-func (c *ShopeeClient) makeRequestWithRetry(ctx context.Context, method, url string, body io.Reader, headers map[string]string) (*http.Response, error) {
+// makeRequestWithRetry executes HTTP requests with rate limiting and retry
+// logic inside one span; each attempt gets a child span from shopeeTransport.
+func (c *ShopeeClient) makeRequestWithRetry(ctx context.Context, method, rawURL string, body io.Reader, headers map[string]string) (*http.Response, error) {
+	endpoint := rawURL
+	if u, err := url.Parse(rawURL); err == nil {
+		endpoint = u.Path
+	}
+	ctx, span := tracing.Start(ctx, "shopee.request "+endpoint, tracing.KindInternal)
+	defer span.End()
+	span.SetAttr("shopee.endpoint", endpoint)
+	span.SetAttr("shopee.max_attempts", c.retryConfig.MaxAttempts)
+
+	resp, err := c.doWithRetry(ctx, method, rawURL, body, headers)
+	if resp != nil {
+		span.SetAttr("http.status_code", resp.StatusCode)
+	}
+	span.RecordError(err)
+	return resp, err
+}
+
+func (c *ShopeeClient) doWithRetry(ctx context.Context, method, url string, body io.Reader, headers map[string]string) (*http.Response, error) {
 	// Apply rate limiting
 	if err := c.rateLimiter.Wait(ctx); err != nil {
 		return nil, fmt.Errorf("rate limit timeout: %w", err)
@@ -132,7 +191,12 @@ func (c *ShopeeClient) makeRequestWithRetry(ctx context.Context, method, url str
 		}
 
 		// Log request
-		log.Printf("ShopeeClient request (attempt %d/%d): %s %s", attempt, c.retryConfig.MaxAttempts, method, url)
+		shopeeLog.Debug(ctx, "ShopeeRequest", "Shopee request", map[string]interface{}{
+			"method":       method,
+			"url":          url,
+			"attempt":      attempt,
+			"max_attempts": c.retryConfig.MaxAttempts,
+		})
 
 		// Execute request
 		resp, err = c.httpClient.Do(req)
@@ -150,8 +214,14 @@ func (c *ShopeeClient) makeRequestWithRetry(ctx context.Context, method, url str
 		if attempt < c.retryConfig.MaxAttempts {
 			// Exponential backoff: baseDelay * 2^(attempt-1)
 			delay := c.retryConfig.BaseDelay * time.Duration(1<<(attempt-1))
-			log.Printf("Request failed (attempt %d/%d), retrying in %v. Error: %v",
-				attempt, c.retryConfig.MaxAttempts, delay, err)
+			shopeeLog.Warn(ctx, "ShopeeRequest", "Shopee request failed, retrying", map[string]interface{}{
+				"method":       method,
+				"url":          url,
+				"attempt":      attempt,
+				"max_attempts": c.retryConfig.MaxAttempts,
+				"delay":        delay.String(),
+				"error":        err,
+			})
 
 			select {
 			case <-ctx.Done():
@@ -162,6 +232,9 @@ func (c *ShopeeClient) makeRequestWithRetry(ctx context.Context, method, url str
 		}
 	}
 
+	if err == nil {
+		err = &apperr.ShopeeAPIError{Op: "request", HTTPStatus: resp.StatusCode}
+	}
 	return resp, fmt.Errorf("request failed after %d attempts: %w", c.retryConfig.MaxAttempts, err)
 }
 
@@ -181,7 +254,9 @@ func (c *ShopeeClient) ensureTokenValidForStore(ctx context.Context, store *mode
 	if repo == nil {
 		return fmt.Errorf("missing store repository")
 	}
-	log.Printf("ensureTokenValidForStore for store %s", store.NamaToko)
+	shopeeLog.Debug(ctx, "EnsureTokenValid", "Checking store token", map[string]interface{}{
+		"store": store.NamaToko,
+	})
 
 	// Parse timezone for proper token expiration calculation
 	loc, _ := time.LoadLocation("Asia/Jakarta")
@@ -194,22 +269,27 @@ func (c *ShopeeClient) ensureTokenValidForStore(ctx context.Context, store *mode
 
 	// Check if required fields are available
 	if store.RefreshToken == nil {
-		return fmt.Errorf("missing refresh token for store %s", store.NamaToko)
+		return fmt.Errorf("%w: refresh token for store %s", apperr.ErrMissingCredentials, store.NamaToko)
 	}
 	if store.ShopID == nil || *store.ShopID == "" {
-		return fmt.Errorf("missing shop id for store %s", store.NamaToko)
+		return fmt.Errorf("%w: shop id for store %s", apperr.ErrMissingCredentials, store.NamaToko)
 	}
 
 	// Check if token is still valid (not expired)
 	if store.ExpireIn != nil && store.LastUpdated != nil {
 		if time.Now().Before(exp.Local()) {
-			log.Printf("Token for store %s is still valid until %v", store.NamaToko, exp)
+			shopeeLog.Debug(ctx, "EnsureTokenValid", "Store token still valid", map[string]interface{}{
+				"store":      store.NamaToko,
+				"expires_at": exp,
+			})
 			return nil
 		}
 	}
 
 	// Token is expired, refresh it
-	log.Printf("Token for store %s is expired, refreshing", store.NamaToko)
+	shopeeLog.Info(ctx, "EnsureTokenValid", "Store token expired, refreshing", map[string]interface{}{
+		"store": store.NamaToko,
+	})
 	oldShopID := c.ShopID
 	oldRefreshToken := c.RefreshToken
 
@@ -237,7 +317,9 @@ func (c *ShopeeClient) ensureTokenValidForStore(ctx context.Context, store *mode
 
 	// Save updated store
 	if err := repo.UpdateStore(ctx, store); err != nil {
-		log.Printf("Warning: failed to update store token in database: %v", err)
+		shopeeLog.Error(ctx, "EnsureTokenValid", "Failed to save refreshed store token", err, map[string]interface{}{
+			"store": store.NamaToko,
+		})
 		// Don't fail the operation, just log the warning
 	}
 
@@ -245,7 +327,9 @@ func (c *ShopeeClient) ensureTokenValidForStore(ctx context.Context, store *mode
 	c.ShopID = oldShopID
 	c.RefreshToken = oldRefreshToken
 
-	log.Printf("Successfully refreshed token for store %s", store.NamaToko)
+	shopeeLog.Info(ctx, "EnsureTokenValid", "Store token refreshed", map[string]interface{}{
+		"store": store.NamaToko,
+	})
 	return nil
 }
 
@@ -311,7 +395,9 @@ type tokenResp struct {
 
 // RefreshAccessToken fetches a new access token using the refresh token.
 func (c *ShopeeClient) RefreshAccessToken(ctx context.Context) (*refreshResp, error) {
-	log.Printf("Refreshing access token for shop %s", c.ShopID)
+	shopeeLog.Info(ctx, "RefreshAccessToken", "Refreshing access token", map[string]interface{}{
+		"shop_id": c.ShopID,
+	})
 	if c.ShopID == "" {
 		return nil, fmt.Errorf("shop_id is empty")
 	}
@@ -338,7 +424,11 @@ func (c *ShopeeClient) RefreshAccessToken(ctx context.Context) (*refreshResp, er
 	}
 	body, _ := json.Marshal(reqData)
 	urlStr := c.BaseURL + "/api/v2/auth/access_token/get"
-	log.Printf("ShopeeClient request: POST %s body=%s", urlStr, string(body))
+	shopeeLog.Debug(ctx, "ShopeeRequest", "Shopee request", map[string]interface{}{
+		"method": "POST",
+		"url":    urlStr,
+		"body":   string(body),
+	})
 
 	opts := &shopeego.ClientOptions{
 		Secret:    c.PartnerKey,
@@ -398,7 +488,11 @@ func (c *ShopeeClient) GetAccessToken(ctx context.Context, code, shopID string)
 		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
-	log.Printf("ShopeeClient request: POST %s body=%s", urlStr, string(body))
+	shopeeLog.Debug(ctx, "ShopeeRequest", "Shopee request", map[string]interface{}{
+		"method": "POST",
+		"url":    urlStr,
+		"body":   string(body),
+	})
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		logutil.Errorf("GetAccessToken request error: %v", err)
@@ -408,7 +502,7 @@ func (c *ShopeeClient) GetAccessToken(ctx context.Context, code, shopID string)
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		logutil.Errorf("GetAccessToken unexpected status %d: %s", resp.StatusCode, string(body))
-		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+		return nil, &apperr.ShopeeAPIError{Op: "GetAccessToken", HTTPStatus: resp.StatusCode}
 	}
 	var out tokenResp
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -416,7 +510,7 @@ func (c *ShopeeClient) GetAccessToken(ctx context.Context, code, shopID string)
 	}
 	if out.Error != "" {
 		logutil.Errorf("GetAccessToken API error: %s", out.Error)
-		return nil, fmt.Errorf("shopee error: %s", out.Error)
+		return nil, &apperr.ShopeeAPIError{Op: "GetAccessToken", HTTPStatus: resp.StatusCode, Code: out.Error, Message: out.Message}
 	}
 	return &out, nil
 }
@@ -444,7 +538,10 @@ func (c *ShopeeClient) FetchShopeeOrderDetail(ctx context.Context, accessToken,
 	q.Set("response_optional_fields", "buyer_user_id,buyer_username,estimated_shipping_fee,recipient_address,actual_shipping_fee ,goods_to_declare,note,note_update_time,item_list,pay_time,dropshipper, dropshipper_phone,split_up,buyer_cancel_reason,cancel_by,cancel_reason,actual_shipping_fee_confirmed,buyer_cpf_id,fulfillment_flag,pickup_done_time,package_list,shipping_carrier,payment_method,total_amount,buyer_username,invoice_data,order_chargeable_weight_gram,return_request_due_date,edt")
 
 	urlStr := c.BaseURL + path + "?" + q.Encode()
-	log.Printf("ShopeeClient request: GET %s", urlStr)
+	shopeeLog.Debug(ctx, "ShopeeRequest", "Shopee request", map[string]interface{}{
+		"method": "GET",
+		"url":    urlStr,
+	})
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlStr, nil)
 	if err != nil {
 		return nil, err
@@ -460,7 +557,7 @@ func (c *ShopeeClient) FetchShopeeOrderDetail(ctx context.Context, accessToken,
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		logutil.Errorf("FetchShopeeOrderDetail unexpected status %d: %s", resp.StatusCode, string(body))
-		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+		return nil, &apperr.ShopeeAPIError{Op: "FetchShopeeOrderDetail", HTTPStatus: resp.StatusCode}
 	}
 
 	var out orderDetailAPIResponse
@@ -469,7 +566,7 @@ func (c *ShopeeClient) FetchShopeeOrderDetail(ctx context.Context, accessToken,
 	}
 	if out.Error != "" {
 		logutil.Errorf("FetchShopeeOrderDetail API error: %s", out.Error)
-		return nil, fmt.Errorf("shopee error: %s", out.Error)
+		return nil, &apperr.ShopeeAPIError{Op: "FetchShopeeOrderDetail", HTTPStatus: resp.StatusCode, Code: out.Error, Message: out.Message}
 	}
 	if len(out.Response.OrderList) == 0 {
 		return nil, fmt.Errorf("empty response")
@@ -504,7 +601,10 @@ func (c *ShopeeClient) FetchShopeeOrderDetails(ctx context.Context, accessToken,
 	q.Set("response_optional_fields", "buyer_user_id,buyer_username,estimated_shipping_fee,recipient_address,actual_shipping_fee ,goods_to_declare,note,note_update_time,item_list,pay_time,dropshipper, dropshipper_phone,split_up,buyer_cancel_reason,cancel_by,cancel_reason,actual_shipping_fee_confirmed,buyer_cpf_id,fulfillment_flag,pickup_done_time,package_list,shipping_carrier,payment_method,total_amount,buyer_username,invoice_data,order_chargeable_weight_gram,return_request_due_date,edt")
 
 	urlStr := c.BaseURL + path + "?" + q.Encode()
-	log.Printf("ShopeeClient request: GET %s", urlStr)
+	shopeeLog.Debug(ctx, "ShopeeRequest", "Shopee request", map[string]interface{}{
+		"method": "GET",
+		"url":    urlStr,
+	})
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlStr, nil)
 	if err != nil {
 		return nil, err
@@ -520,7 +620,7 @@ func (c *ShopeeClient) FetchShopeeOrderDetails(ctx context.Context, accessToken,
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		logutil.Errorf("FetchShopeeOrderDetails unexpected status %d: %s", resp.StatusCode, string(body))
-		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+		return nil, &apperr.ShopeeAPIError{Op: "FetchShopeeOrderDetails", HTTPStatus: resp.StatusCode}
 	}
 
 	var out orderDetailAPIResponse
@@ -529,7 +629,7 @@ func (c *ShopeeClient) FetchShopeeOrderDetails(ctx context.Context, accessToken,
 	}
 	if out.Error != "" {
 		logutil.Errorf("FetchShopeeOrderDetails API error: %s", out.Error)
-		return nil, fmt.Errorf("shopee error: %s", out.Error)
+		return nil, &apperr.ShopeeAPIError{Op: "FetchShopeeOrderDetails", HTTPStatus: resp.StatusCode, Code: out.Error, Message: out.Message}
 	}
 	if len(out.Response.OrderList) == 0 {
 		return nil, fmt.Errorf("empty response")
@@ -541,7 +641,10 @@ func (c *ShopeeClient) FetchShopeeOrderDetails(ctx context.Context, accessToken,
 // call. The accessToken and shopID parameters should belong to the store that
 // owns the order.
 func (c *ShopeeClient) GetEscrowDetail(ctx context.Context, accessToken, shopID, orderSN string) (*ShopeeEscrowDetail, error) {
-	log.Printf("ShopeeClient GetEscrowDetail order=%s shop=%s", orderSN, shopID)
+	shopeeLog.Debug(ctx, "GetEscrowDetail", "Fetching escrow detail", map[string]interface{}{
+		"order_sn": orderSN,
+		"shop_id":  shopID,
+	})
 
 	// Apply rate limiting
 	if err := c.rateLimiter.Wait(ctx); err != nil {
@@ -561,7 +664,10 @@ func (c *ShopeeClient) GetEscrowDetail(ctx context.Context, accessToken, shopID,
 	q.Set("order_sn", orderSN)
 
 	urlStr := c.BaseURL + path + "?" + q.Encode()
-	log.Printf("ShopeeClient request: GET %s", urlStr)
+	shopeeLog.Debug(ctx, "ShopeeRequest", "Shopee request", map[string]interface{}{
+		"method": "GET",
+		"url":    urlStr,
+	})
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlStr, nil)
 	if err != nil {
 		return nil, err
@@ -573,16 +679,18 @@ func (c *ShopeeClient) GetEscrowDetail(ctx context.Context, accessToken, shopID,
 		return nil, err
 	}
 	defer resp.Body.Close()
-	log.Printf("GetEscrowDetail response status: %d", resp.StatusCode)
-	log.Printf("GetEscrowDetail response headers: %v", resp.Header)
 	bodyBytes, _ := io.ReadAll(resp.Body)
-	log.Printf("GetEscrowDetail response body: %s", string(bodyBytes))
+	shopeeLog.Debug(ctx, "GetEscrowDetail", "Escrow detail response", map[string]interface{}{
+		"status":  resp.StatusCode,
+		"headers": resp.Header,
+		"body":    string(bodyBytes),
+	})
 	resp.Body = io.NopCloser(bytes.NewBuffer(bodyBytes)) // Reset body for decoder
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		logutil.Errorf("GetEscrowDetail unexpected status %d: %s", resp.StatusCode, string(body))
-		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+		return nil, &apperr.ShopeeAPIError{Op: "GetEscrowDetail", HTTPStatus: resp.StatusCode}
 	}
 
 	var out struct {
@@ -595,9 +703,8 @@ func (c *ShopeeClient) GetEscrowDetail(ctx context.Context, accessToken, shopID,
 	}
 	if out.Error != "" {
 		logutil.Errorf("GetEscrowDetail API error: %s", out.Error)
-		return nil, fmt.Errorf("shopee error: %s", out.Error)
+		return nil, &apperr.ShopeeAPIError{Op: "GetEscrowDetail", HTTPStatus: resp.StatusCode, Code: out.Error, Message: out.Message}
 	}
-	log.Printf("GetEscrowDetail response: %+v", out.Response)
 	return &out.Response, nil
 }
 
@@ -631,8 +738,11 @@ func (c *ShopeeClient) FetchShopeeEscrowDetails(ctx context.Context, accessToken
 	}
 
 	urlStr := c.BaseURL + path + "?" + q.Encode()
-	log.Printf("ShopeeClient request: POST %s", urlStr)
-	log.Printf("ShopeeClient request body: %s", buf.String())
+	shopeeLog.Debug(ctx, "ShopeeRequest", "Shopee request", map[string]interface{}{
+		"method": "POST",
+		"url":    urlStr,
+		"body":   buf.String(),
+	})
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, urlStr, &buf)
 	if err != nil {
@@ -650,7 +760,7 @@ func (c *ShopeeClient) FetchShopeeEscrowDetails(ctx context.Context, accessToken
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		logutil.Errorf("FetchShopeeEscrowDetails unexpected status %d: %s", resp.StatusCode, string(body))
-		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+		return nil, &apperr.ShopeeAPIError{Op: "FetchShopeeEscrowDetails", HTTPStatus: resp.StatusCode}
 	}
 
 	var out struct {
@@ -666,12 +776,14 @@ func (c *ShopeeClient) FetchShopeeEscrowDetails(ctx context.Context, accessToken
 	}
 	if out.Error != "" {
 		logutil.Errorf("FetchShopeeEscrowDetails API error: %s", out.Error)
-		return nil, fmt.Errorf("shopee error: %s", out.Error)
+		return nil, &apperr.ShopeeAPIError{Op: "FetchShopeeEscrowDetails", HTTPStatus: resp.StatusCode, Code: out.Error, Message: out.Message}
 	}
 
-	log.Printf("FetchShopeeEscrowDetails response: %+v", out)
-	log.Printf("FetchShopeeEscrowDetails response count: %d", len(out.Response))
-	log.Printf("FetchShopeeEscrowDetails response order_sns: %v", orderSNs)
+	shopeeLog.Debug(ctx, "FetchShopeeEscrowDetails", "Escrow details response", map[string]interface{}{
+		"requested": len(orderSNs),
+		"returned":  len(out.Response),
+		"order_sns": orderSNs,
+	})
 
 	res := make(map[string]ShopeeEscrowDetail, len(out.Response))
 	for _, item := range out.Response {
@@ -692,12 +804,14 @@ func (c *ShopeeClient) FetchShopeeEscrowDetails(ctx context.Context, accessToken
 			}
 		}
 		if sn == "" {
-			log.Printf("WARNING: EscrowDetail with empty order_sn: %+v", item)
+			shopeeLog.Warn(ctx, "FetchShopeeEscrowDetails", "Escrow detail without order_sn, skipping", map[string]interface{}{
+				"shop_id":       shopID,
+				"escrow_detail": item.EscrowDetail,
+			})
 			continue
 		}
 		res[sn] = item.EscrowDetail
 	}
-	log.Printf("FetchShopeeEscrowDetails mapped response: %v", res)
 	return res, nil
 }
 
@@ -729,7 +843,10 @@ func (c *ShopeeClient) GetOrderDetail(ctx context.Context, orderSn string) (stri
 	if err != nil {
 		return "", err
 	}
-	log.Printf("ShopeeClient request: GET %s", urlStr)
+	shopeeLog.Debug(ctx, "ShopeeRequest", "Shopee request", map[string]interface{}{
+		"method": "GET",
+		"url":    urlStr,
+	})
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		logutil.Errorf("GetOrderDetail request error: %v", err)
@@ -739,7 +856,7 @@ func (c *ShopeeClient) GetOrderDetail(ctx context.Context, orderSn string) (stri
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		logutil.Errorf("GetOrderDetail unexpected status %d: %s", resp.StatusCode, string(body))
-		return "", fmt.Errorf("unexpected status %d", resp.StatusCode)
+		return "", &apperr.ShopeeAPIError{Op: "GetOrderDetail", HTTPStatus: resp.StatusCode}
 	}
 	var out orderDetailResp
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -747,7 +864,7 @@ func (c *ShopeeClient) GetOrderDetail(ctx context.Context, orderSn string) (stri
 	}
 	if out.Error != "" {
 		logutil.Errorf("GetOrderDetail API error: %s", out.Error)
-		return "", fmt.Errorf("shopee error: %s", out.Error)
+		return "", &apperr.ShopeeAPIError{Op: "GetOrderDetail", HTTPStatus: resp.StatusCode, Code: out.Error, Message: out.Message}
 	}
 	return out.Response.OrderStatus, nil
 }
@@ -780,7 +897,10 @@ func (c *ShopeeClient) getOrderDetailExt(ctx context.Context, orderSn string) (*
 	if err != nil {
 		return nil, err
 	}
-	log.Printf("ShopeeClient request: GET %s", urlStr)
+	shopeeLog.Debug(ctx, "ShopeeRequest", "Shopee request", map[string]interface{}{
+		"method": "GET",
+		"url":    urlStr,
+	})
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		logutil.Errorf("getOrderDetailExt request error: %v", err)
@@ -790,7 +910,7 @@ func (c *ShopeeClient) getOrderDetailExt(ctx context.Context, orderSn string) (*
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		logutil.Errorf("getOrderDetailExt unexpected status %d: %s", resp.StatusCode, string(body))
-		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+		return nil, &apperr.ShopeeAPIError{Op: "getOrderDetailExt", HTTPStatus: resp.StatusCode}
 	}
 	var out orderDetailExtResp
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -798,7 +918,7 @@ func (c *ShopeeClient) getOrderDetailExt(ctx context.Context, orderSn string) (*
 	}
 	if out.Error != "" {
 		logutil.Errorf("getOrderDetailExt API error: %s", out.Error)
-		return nil, fmt.Errorf("shopee error: %s", out.Error)
+		return nil, &apperr.ShopeeAPIError{Op: "getOrderDetailExt", HTTPStatus: resp.StatusCode, Code: out.Error, Message: out.Message}
 	}
 	if len(out.Response.OrderList) == 0 {
 		return nil, fmt.Errorf("empty response")
@@ -842,7 +962,10 @@ func (c *ShopeeClient) GetPendingBalance(ctx context.Context, store string) (flo
 		if err != nil {
 			return 0, err
 		}
-		log.Printf("ShopeeClient request: GET %s", urlStr)
+		shopeeLog.Debug(ctx, "ShopeeRequest", "Shopee request", map[string]interface{}{
+			"method": "GET",
+			"url":    urlStr,
+		})
 		resp, err := c.httpClient.Do(req)
 		if err != nil {
 			logutil.Errorf("GetOrderList request error: %v", err)
@@ -854,7 +977,7 @@ func (c *ShopeeClient) GetPendingBalance(ctx context.Context, store string) (flo
 			if resp.StatusCode != http.StatusOK {
 				body, _ := io.ReadAll(resp.Body)
 				logutil.Errorf("GetOrderList unexpected status %d: %s", resp.StatusCode, string(body))
-				err = fmt.Errorf("unexpected status %d", resp.StatusCode)
+				err = &apperr.ShopeeAPIError{Op: "GetPendingBalance", HTTPStatus: resp.StatusCode}
 				return
 			}
 			var out orderListResp
@@ -863,7 +986,7 @@ func (c *ShopeeClient) GetPendingBalance(ctx context.Context, store string) (flo
 				return
 			}
 			if out.Error != "" {
-				err = fmt.Errorf("shopee error: %s", out.Error)
+				err = &apperr.ShopeeAPIError{Op: "GetPendingBalance", HTTPStatus: resp.StatusCode, Code: out.Error, Message: out.Message}
 				return
 			}
 			for _, o := range out.Response.OrderList {
@@ -935,7 +1058,10 @@ func (c *ShopeeClient) GetWalletTransactionList(ctx context.Context, accessToken
 	}
 
 	urlStr := c.BaseURL + path + "?" + q.Encode()
-	log.Printf("ShopeeClient request: GET %s", urlStr)
+	shopeeLog.Debug(ctx, "ShopeeRequest", "Shopee request", map[string]interface{}{
+		"method": "GET",
+		"url":    urlStr,
+	})
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlStr, nil)
 	if err != nil {
 		return nil, err
@@ -949,7 +1075,7 @@ func (c *ShopeeClient) GetWalletTransactionList(ctx context.Context, accessToken
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		logutil.Errorf("GetWalletTransactionList unexpected status %d: %s", resp.StatusCode, string(body))
-		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+		return nil, &apperr.ShopeeAPIError{Op: "GetWalletTransactionList", HTTPStatus: resp.StatusCode}
 	}
 	var out struct {
 		Response struct {
@@ -964,7 +1090,7 @@ func (c *ShopeeClient) GetWalletTransactionList(ctx context.Context, accessToken
 	}
 	if out.Error != "" {
 		logutil.Errorf("GetWalletTransactionList API error: %s", out.Error)
-		return nil, fmt.Errorf("shopee error: %s", out.Error)
+		return nil, &apperr.ShopeeAPIError{Op: "GetWalletTransactionList", HTTPStatus: resp.StatusCode, Code: out.Error, Message: out.Message}
 	}
 	return &WalletTransactionList{Transactions: out.Response.TransactionList, more: out.Response.More}, nil
 }
@@ -995,7 +1121,10 @@ func (c *ShopeeClient) GetReturnList(ctx context.Context, accessToken, shopID st
 	}
 
 	urlStr := c.BaseURL + path + "?" + q.Encode()
-	log.Printf("ShopeeClient request: GET %s", urlStr)
+	shopeeLog.Debug(ctx, "ShopeeRequest", "Shopee request", map[string]interface{}{
+		"method": "GET",
+		"url":    urlStr,
+	})
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlStr, nil)
 	if err != nil {
@@ -1012,7 +1141,7 @@ func (c *ShopeeClient) GetReturnList(ctx context.Context, accessToken, shopID st
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		logutil.Errorf("GetReturnList unexpected status %d: %s", resp.StatusCode, string(body))
-		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+		return nil, &apperr.ShopeeAPIError{Op: "GetReturnList", HTTPStatus: resp.StatusCode}
 	}
 
 	var result models.ShopeeReturnResponse
@@ -1022,9 +1151,12 @@ func (c *ShopeeClient) GetReturnList(ctx context.Context, accessToken, shopID st
 
 	if result.Error != "" {
 		logutil.Errorf("GetReturnList API error: %s", result.Error)
-		return nil, fmt.Errorf("shopee error: %s", result.Error)
+		return nil, &apperr.ShopeeAPIError{Op: "GetReturnList", HTTPStatus: resp.StatusCode, Code: result.Error, Message: result.Message}
 	}
 
-	log.Printf("GetReturnList response: found %d returns", len(result.Response.Return))
+	shopeeLog.Debug(ctx, "GetReturnList", "Fetched returns", map[string]interface{}{
+		"shop_id": shopID,
+		"returns": len(result.Response.Return),
+	})
 	return &result, nil
 }
@@ -3,7 +3,6 @@ package service
 import (
 	"context"
 	"fmt"
-	"log"
 	"time"
 
 	"github.com/ramadhan22/dropship-erp/backend/internal/logutil"
@@ -41,7 +40,9 @@ func NewShopeeDetailBackgroundService(
 
 // QueueOrderDetailFetch queues a background job to fetch Shopee order detail for an invoice
 func (s *ShopeeDetailBackgroundService) QueueOrderDetailFetch(ctx context.Context, invoice string) (int64, error) {
-	log.Printf("Queueing background fetch for order detail: %s", invoice)
+	logutil.Debug(ctx, "QueueOrderDetailFetch", "Queueing order detail fetch", map[string]interface{}{
+		"invoice": invoice,
+	})
 
 	// Create a batch job for this fetch operation
 	batch := &models.BatchHistory{
@@ -73,13 +74,16 @@ func (s *ShopeeDetailBackgroundService) QueueOrderDetailFetch(ctx context.Contex
 		return 0, fmt.Errorf("create batch detail: %w", err)
 	}
 
-	log.Printf("Queued background fetch job %d for invoice: %s", batchID, invoice)
+	logutil.Info(ctx, "QueueOrderDetailFetch", "Queued order detail fetch", map[string]interface{}{
+		"batch_id": batchID,
+		"invoice":  invoice,
+	})
 	return batchID, nil
 }
 
 // ProcessPendingOrderDetailFetches processes pending order detail fetch jobs
 func (s *ShopeeDetailBackgroundService) ProcessPendingOrderDetailFetches(ctx context.Context) error {
-	log.Printf("Processing pending order detail fetch jobs...")
+	logutil.Debug(ctx, "ProcessPendingOrderDetailFetches", "Processing pending order detail fetches")
 
 	// Get pending batches
 	pendingBatches, err := s.batchService.ListPendingByType(ctx, "shopee_order_detail_fetch")
@@ -99,7 +103,11 @@ func (s *ShopeeDetailBackgroundService) ProcessPendingOrderDetailFetches(ctx con
 
 // processBatch processes a single batch of order detail fetches
 func (s *ShopeeDetailBackgroundService) processBatch(ctx context.Context, batchID int64) error {
-	log.Printf("Processing order detail fetch batch: %d", batchID)
+	ctx, span := startBatchSpan(ctx, "shopee_order_detail_fetch", batchID)
+	defer span.End()
+	logutil.Info(ctx, "ProcessOrderDetailBatch", "Processing order detail fetch batch", map[string]interface{}{
+		"batch_id": batchID,
+	})
 
 	// Update batch status to processing
 	if err := s.batchService.UpdateStatus(ctx, batchID, "processing", ""); err != nil {
@@ -119,7 +127,10 @@ func (s *ShopeeDetailBackgroundService) processBatch(ctx context.Context, batchI
 		}
 
 		invoice := detail.Reference
-		log.Printf("Fetching order detail for invoice: %s", invoice)
+		logutil.Debug(ctx, "ProcessOrderDetailBatch", "Fetching order detail", map[string]interface{}{
+			"batch_id": batchID,
+			"invoice":  invoice,
+		})
 
 		// Attempt to fetch order detail from Shopee
 		if err := s.fetchAndStoreOrderDetail(ctx, invoice, detail.ID); err != nil {
@@ -148,7 +159,11 @@ func (s *ShopeeDetailBackgroundService) processBatch(ctx context.Context, batchI
 		logutil.Errorf("Failed to update batch final status: %v", err)
 	}
 
-	log.Printf("Completed processing batch %d: %d/%d successful", batchID, successCount, len(details))
+	logutil.Info(ctx, "ProcessOrderDetailBatch", "Order detail fetch batch completed", map[string]interface{}{
+		"batch_id":   batchID,
+		"successful": successCount,
+		"total":      len(details),
+	})
 	return nil
 }
 
@@ -186,7 +201,9 @@ func (s *ShopeeDetailBackgroundService) fetchAndStoreOrderDetail(ctx context.Con
 		return fmt.Errorf("save order detail to database: %w", err)
 	}
 
-	log.Printf("Successfully fetched and stored order detail for invoice: %s", invoice)
+	logutil.Debug(ctx, "FetchAndStoreOrderDetail", "Stored order detail", map[string]interface{}{
+		"invoice": invoice,
+	})
 	return nil
 }
 
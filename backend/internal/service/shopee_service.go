@@ -4,10 +4,9 @@ import (
 	"context"
 	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
-	"math"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -17,10 +16,14 @@ import (
 
 	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
+	"github.com/ramadhan22/dropship-erp/backend/internal/cache"
 	"github.com/ramadhan22/dropship-erp/backend/internal/config"
 	"github.com/xuri/excelize/v2"
 
+	"github.com/ramadhan22/dropship-erp/backend/internal/lifecycle"
+	"github.com/ramadhan22/dropship-erp/backend/internal/logutil"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
 )
 
@@ -116,11 +119,13 @@ type ShopeeRepoInterface interface {
 	InsertShopeeSettled(ctx context.Context, s *models.ShopeeSettled) error
 	InsertShopeeAffiliateSale(ctx context.Context, s *models.ShopeeAffiliateSale) error
 	ListShopeeSettled(ctx context.Context, channel, store, from, to, orderNo, sortBy, dir string, limit, offset int) ([]models.ShopeeSettled, int, error)
+	ListShopeeSettledFiltered(ctx context.Context, params *models.FilterParams) (*models.QueryResult, error)
 	SumShopeeSettled(ctx context.Context, channel, store, from, to string) (*models.ShopeeSummary, error)
 	ExistsShopeeSettled(ctx context.Context, noPesanan string) (bool, error)
 	ExistsShopeeAffiliateSale(ctx context.Context, orderID, productCode, komisiID string) (bool, error)
 	DeleteShopeeAffiliateSale(ctx context.Context, orderID, productCode, komisiID string) error
 	ListShopeeAffiliateSales(ctx context.Context, noPesanan, from, to string, limit, offset int) ([]models.ShopeeAffiliateSale, int, error)
+	ListShopeeAffiliateSalesFiltered(ctx context.Context, params *models.FilterParams) (*models.QueryResult, error)
 	SumShopeeAffiliateSales(ctx context.Context, noPesanan, from, to string) (*models.ShopeeAffiliateSummary, error)
 	GetAffiliateExpenseByOrder(ctx context.Context, kodePesanan string) (float64, error)
 	MarkMismatch(ctx context.Context, orderSN string, mismatch bool) error
@@ -133,8 +138,8 @@ type ShopeeDropshipRepo interface {
 	GetDropshipPurchaseByInvoice(ctx context.Context, kodeInvoice string) (*models.DropshipPurchase, error)
 	GetDropshipPurchaseByID(ctx context.Context, kodePesanan string) (*models.DropshipPurchase, error)
 	GetDropshipPurchaseByTransaction(ctx context.Context, kodeTransaksi string) (*models.DropshipPurchase, error)
-	SumDetailByInvoice(ctx context.Context, kodeInvoice string) (float64, error)
-	UpdateDropshipStatus(ctx context.Context, kodePesanan, status string) error
+	SumDetailByInvoice(ctx context.Context, kodeInvoice string) (money.Amount, error)
+	TransitionPurchaseStatus(ctx context.Context, kodePesanan string, c lifecycle.Change) error
 }
 
 // ShopeeService handles import of settled Shopee orders from XLSX files.
@@ -144,7 +149,7 @@ type ShopeeJournalRepo interface {
 	InsertJournalLines(ctx context.Context, lines []models.JournalLine) error
 	GetJournalEntryBySource(ctx context.Context, sourceType, sourceID string) (*models.JournalEntry, error)
 	GetLinesByJournalID(ctx context.Context, id int64) ([]repository.JournalLineDetail, error)
-	UpdateJournalLineAmount(ctx context.Context, lineID int64, amount float64) error
+	UpdateJournalLineAmount(ctx context.Context, lineID int64, amount money.Amount) error
 	DeleteJournalEntry(ctx context.Context, id int64) error
 }
 
@@ -157,6 +162,7 @@ type ShopeeService struct {
 	adjRepo      *repository.ShopeeAdjustmentRepo
 	channelRepo  *repository.ChannelRepo
 	cfg          config.ShopeeAPIConfig
+	cache        Cache
 }
 
 // NewShopeeService constructs a ShopeeService.
@@ -164,6 +170,12 @@ func NewShopeeService(db *sqlx.DB, r ShopeeRepoInterface, dr ShopeeDropshipRepo,
 	return &ShopeeService{db: db, repo: r, dropshipRepo: dr, journalRepo: jr, adjRepo: ar, channelRepo: cr, cfg: cfg}
 }
 
+// SetCache enables invalidation of cached summaries and reports after
+// imports and settlements.
+func (s *ShopeeService) SetCache(c Cache) {
+	s.cache = c
+}
+
 // ImportSettledOrdersXLSX reads an XLSX file and inserts rows into shopee_settled.
 // It returns the count of successfully inserted rows.
 func (s *ShopeeService) ImportSettledOrdersXLSX(ctx context.Context, r io.Reader) (int, []string, error) {
@@ -225,6 +237,11 @@ func (s *ShopeeService) ImportSettledOrdersXLSX(ctx context.Context, r io.Reader
 
 	inserted := 0
 	mismatches := []string{}
+	defer func() {
+		if inserted > 0 {
+			invalidateCache(ctx, s.cache, cache.TagSettlements, cache.TagPurchases, cache.TagJournals, cache.TagReports)
+		}
+	}()
 	for _, entry := range entries {
 		if existing[entry.NoPesanan] {
 			continue
@@ -232,7 +249,7 @@ func (s *ShopeeService) ImportSettledOrdersXLSX(ctx context.Context, r io.Reader
 		if err := s.repo.InsertShopeeSettled(ctx, entry); err != nil {
 			return inserted, mismatches, fmt.Errorf("insert %s: %w", entry.NoPesanan, err)
 		}
-		var sum float64
+		var sum money.Amount
 		if s.db != nil {
 			_ = s.db.GetContext(ctx, &sum,
 				`SELECT COALESCE(SUM(d.total_harga_produk_channel),0)
@@ -257,8 +274,13 @@ func (s *ShopeeService) ImportSettledOrdersXLSX(ctx context.Context, r io.Reader
 		// Update related dropship purchase status if applicable
 		if s.dropshipRepo != nil && entry.NoPengajuan != "" {
 			if dp, _ := s.dropshipRepo.GetDropshipPurchaseByTransaction(ctx, entry.NoPengajuan); dp != nil {
-				if dp.StatusPesananTerakhir != "Pesanan selesai" && dp.StatusPesananTerakhir != "Pesanan dibatalkan" {
-					_ = s.dropshipRepo.UpdateDropshipStatus(ctx, dp.KodePesanan, "Pesanan selesai")
+				err := s.dropshipRepo.TransitionPurchaseStatus(ctx, dp.KodePesanan, lifecycle.Change{
+					To: lifecycle.Settled, Cause: lifecycle.CauseSettledImport, At: entry.TanggalDanaDilepaskan,
+				})
+				if err != nil && !errors.Is(err, lifecycle.ErrInvalidTransition) {
+					logutil.Error(ctx, "ImportSettledOrdersXLSX", "Failed to mark purchase settled", err, map[string]interface{}{
+						"kode_pesanan": dp.KodePesanan,
+					})
 				}
 			}
 		}
@@ -414,95 +436,95 @@ func parseShopeeRow(row []string, header map[string]int, namaToko string) (*mode
 	if res.TanggalDanaDilepaskan, err = parseDate(get("Tanggal Dana Dilepaskan")); err != nil {
 		return nil, err
 	}
-	if res.HargaAsliProduk, err = parseFloat(get("Harga Asli Produk")); err != nil {
+	if res.HargaAsliProduk, err = money.Parse(get("Harga Asli Produk")); err != nil {
 		return nil, err
 	}
-	if res.TotalDiskonProduk, err = parseFloat(get("Total Diskon Produk")); err != nil {
+	if res.TotalDiskonProduk, err = money.Parse(get("Total Diskon Produk")); err != nil {
 		return nil, err
 	}
-	if res.JumlahPengembalianDanaKePembeli, err = parseFloat(get("Jumlah Pengembalian Dana ke Pembeli")); err != nil {
+	if res.JumlahPengembalianDanaKePembeli, err = money.Parse(get("Jumlah Pengembalian Dana ke Pembeli")); err != nil {
 		return nil, err
 	}
-	if res.KomisiShopee, err = parseFloat(get("Diskon Produk dari Shopee")); err != nil {
+	if res.KomisiShopee, err = money.Parse(get("Diskon Produk dari Shopee")); err != nil {
 		return nil, err
 	}
-	if res.BiayaAdminShopee, err = parseFloat(get("Diskon Voucher Ditanggung Penjual")); err != nil {
+	if res.BiayaAdminShopee, err = money.Parse(get("Diskon Voucher Ditanggung Penjual")); err != nil {
 		return nil, err
 	}
-	if res.BiayaLayanan, err = parseFloat(get("Cashback Koin yang Ditanggung Penjual")); err != nil {
+	if res.BiayaLayanan, err = money.Parse(get("Cashback Koin yang Ditanggung Penjual")); err != nil {
 		return nil, err
 	}
-	if res.BiayaLayananEkstra, err = parseFloat(get("Ongkir Dibayar Pembeli")); err != nil {
+	if res.BiayaLayananEkstra, err = money.Parse(get("Ongkir Dibayar Pembeli")); err != nil {
 		return nil, err
 	}
-	if res.BiayaPenyediaPembayaran, err = parseFloat(get("Diskon Ongkir Ditanggung Jasa Kirim")); err != nil {
+	if res.BiayaPenyediaPembayaran, err = money.Parse(get("Diskon Ongkir Ditanggung Jasa Kirim")); err != nil {
 		return nil, err
 	}
-	if res.Asuransi, err = parseFloat(get("Gratis Ongkir dari Shopee")); err != nil {
+	if res.Asuransi, err = money.Parse(get("Gratis Ongkir dari Shopee")); err != nil {
 		return nil, err
 	}
-	if res.TotalBiayaTransaksi, err = parseFloat(get("Ongkir yang Diteruskan oleh Shopee ke Jasa Kirim")); err != nil {
+	if res.TotalBiayaTransaksi, err = money.Parse(get("Ongkir yang Diteruskan oleh Shopee ke Jasa Kirim")); err != nil {
 		return nil, err
 	}
-	if res.BiayaPengiriman, err = parseFloat(get("Ongkos Kirim Pengembalian Barang")); err != nil {
+	if res.BiayaPengiriman, err = money.Parse(get("Ongkos Kirim Pengembalian Barang")); err != nil {
 		return nil, err
 	}
-	if res.TotalDiskonPengiriman, err = parseFloat(get("Pengembalian Biaya Kirim")); err != nil {
+	if res.TotalDiskonPengiriman, err = money.Parse(get("Pengembalian Biaya Kirim")); err != nil {
 		return nil, err
 	}
-	if res.PromoGratisOngkirShopee, err = parseFloat(get("Biaya Komisi AMS")); err != nil {
+	if res.PromoGratisOngkirShopee, err = money.Parse(get("Biaya Komisi AMS")); err != nil {
 		return nil, err
 	}
-	if res.PromoGratisOngkirPenjual, err = parseFloat(get("Biaya Administrasi")); err != nil {
+	if res.PromoGratisOngkirPenjual, err = money.Parse(get("Biaya Administrasi")); err != nil {
 		return nil, err
 	}
-	if res.PromoDiskonShopee, err = parseFloat(get("Biaya Layanan (termasuk PPN 11%)")); err != nil {
+	if res.PromoDiskonShopee, err = money.Parse(get("Biaya Layanan (termasuk PPN 11%)")); err != nil {
 		return nil, err
 	}
-	if res.PromoDiskonPenjual, err = parseFloat(get("Premi")); err != nil {
+	if res.PromoDiskonPenjual, err = money.Parse(get("Premi")); err != nil {
 		return nil, err
 	}
-	if res.CashbackShopee, err = parseFloat(get("Biaya Program")); err != nil {
+	if res.CashbackShopee, err = money.Parse(get("Biaya Program")); err != nil {
 		return nil, err
 	}
-	if res.CashbackPenjual, err = parseFloat(get("Biaya Kartu Kredit")); err != nil {
+	if res.CashbackPenjual, err = money.Parse(get("Biaya Kartu Kredit")); err != nil {
 		return nil, err
 	}
 	if idx, ok := header["Biaya Transaksi"]; ok && idx < len(row) {
-		if res.BiayaTransaksi, err = parseFloat(row[idx]); err != nil {
+		if res.BiayaTransaksi, err = money.Parse(row[idx]); err != nil {
 			return nil, err
 		}
 	}
-	if res.KoinShopee, err = parseFloat(get("Biaya Kampanye")); err != nil {
+	if res.KoinShopee, err = money.Parse(get("Biaya Kampanye")); err != nil {
 		return nil, err
 	}
-	if res.PotonganLainnya, err = parseFloat(get("Bea Masuk, PPN & PPh")); err != nil {
+	if res.PotonganLainnya, err = money.Parse(get("Bea Masuk, PPN & PPh")); err != nil {
 		return nil, err
 	}
-	if res.TotalPenerimaan, err = parseFloat(get("Total Penghasilan")); err != nil {
+	if res.TotalPenerimaan, err = money.Parse(get("Total Penghasilan")); err != nil {
 		return nil, err
 	}
-	if res.Kompensasi, err = parseFloat(get("Kompensasi")); err != nil {
+	if res.Kompensasi, err = money.Parse(get("Kompensasi")); err != nil {
 		return nil, err
 	}
-	if res.PromoGratisOngkirDariPenjual, err = parseFloat(get("Promo Gratis Ongkir dari Penjual")); err != nil {
+	if res.PromoGratisOngkirDariPenjual, err = money.Parse(get("Promo Gratis Ongkir dari Penjual")); err != nil {
 		return nil, err
 	}
 	res.JasaKirim = get("Jasa Kirim")
 	res.NamaKurir = get("Nama Kurir")
-	if res.PengembalianDanaKePembeli, err = parseFloat(get("Pengembalian Dana ke Pembeli")); err != nil {
+	if res.PengembalianDanaKePembeli, err = money.Parse(get("Pengembalian Dana ke Pembeli")); err != nil {
 		return nil, err
 	}
-	if res.ProRataKoinYangDitukarkanUntukPengembalianBarang, err = parseFloat(get("Pro-rata Koin yang Ditukarkan untuk Pengembalian Barang")); err != nil {
+	if res.ProRataKoinYangDitukarkanUntukPengembalianBarang, err = money.Parse(get("Pro-rata Koin yang Ditukarkan untuk Pengembalian Barang")); err != nil {
 		return nil, err
 	}
-	if res.ProRataVoucherShopeeUntukPengembalianBarang, err = parseFloat(get("Pro-rata Voucher Shopee untuk Pengembalian Barang")); err != nil {
+	if res.ProRataVoucherShopeeUntukPengembalianBarang, err = money.Parse(get("Pro-rata Voucher Shopee untuk Pengembalian Barang")); err != nil {
 		return nil, err
 	}
-	if res.ProRatedBankPaymentChannelPromotionForReturns, err = parseFloat(get("Pro-rated Bank Payment Channel Promotion  for return refund Items")); err != nil {
+	if res.ProRatedBankPaymentChannelPromotionForReturns, err = money.Parse(get("Pro-rated Bank Payment Channel Promotion  for return refund Items")); err != nil {
 		return nil, err
 	}
-	if res.ProRatedShopeePaymentChannelPromotionForReturns, err = parseFloat(get("Pro-rated Shopee Payment Channel Promotion  for return refund Items")); err != nil {
+	if res.ProRatedShopeePaymentChannelPromotionForReturns, err = money.Parse(get("Pro-rated Shopee Payment Channel Promotion  for return refund Items")); err != nil {
 		return nil, err
 	}
 	return res, nil
@@ -638,6 +660,11 @@ func (s *ShopeeService) ListSettled(
 	return s.repo.ListShopeeSettled(ctx, channel, store, from, to, orderNo, sortBy, dir, limit, offset)
 }
 
+// ListSettledFiltered uses the filtering framework to fetch settled orders.
+func (s *ShopeeService) ListSettledFiltered(ctx context.Context, params *models.FilterParams) (*models.QueryResult, error) {
+	return s.repo.ListShopeeSettledFiltered(ctx, params)
+}
+
 func (s *ShopeeService) SumShopeeSettled(
 	ctx context.Context,
 	channel, store, from, to string,
@@ -654,6 +681,11 @@ func (s *ShopeeService) ListAffiliate(
 	return s.repo.ListShopeeAffiliateSales(ctx, noPesanan, from, to, limit, offset)
 }
 
+// ListAffiliateFiltered uses the filtering framework to fetch affiliate sales.
+func (s *ShopeeService) ListAffiliateFiltered(ctx context.Context, params *models.FilterParams) (*models.QueryResult, error) {
+	return s.repo.ListShopeeAffiliateSalesFiltered(ctx, params)
+}
+
 func (s *ShopeeService) SumAffiliate(
 	ctx context.Context,
 	noPesanan, from, to string,
@@ -686,12 +718,12 @@ func (s *ShopeeService) ListSalesProfit(
 	return list, total, nil
 }
 
-func (s *ShopeeService) GetSettleDetail(ctx context.Context, orderSN string) (*models.ShopeeSettled, float64, error) {
+func (s *ShopeeService) GetSettleDetail(ctx context.Context, orderSN string) (*models.ShopeeSettled, money.Amount, error) {
 	o, err := s.repo.GetBySN(ctx, orderSN)
 	if err != nil {
 		return nil, 0, err
 	}
-	var sum float64
+	var sum money.Amount
 	if s.dropshipRepo != nil {
 		sum, _ = s.dropshipRepo.SumDetailByInvoice(ctx, orderSN)
 	}
@@ -704,15 +736,15 @@ func (s *ShopeeService) createSettlementJournal(ctx context.Context, jr ShopeeJo
 	}
 	affiliate, _ := repo.GetAffiliateExpenseByOrder(ctx, entry.NoPesanan)
 	netSale := entry.HargaAsliProduk + entry.TotalDiskonProduk
-	disc := abs(entry.TotalDiskonProduk)
+	disc := entry.TotalDiskonProduk.Abs()
 	if je, err := jr.GetJournalEntryBySource(ctx, "shopee_discount", entry.NoPesanan+"-discount"); err == nil && je != nil {
 		disc = 0
 	}
-	voucher := abs(entry.BiayaAdminShopee)
-	admin := abs(entry.PromoGratisOngkirPenjual)
-	layanan := abs(entry.PromoDiskonShopee)
-	affiliateAmt := abs(affiliate)
-	transFee := abs(entry.BiayaTransaksi)
+	voucher := entry.BiayaAdminShopee.Abs()
+	admin := entry.PromoGratisOngkirPenjual.Abs()
+	layanan := entry.PromoDiskonShopee.Abs()
+	affiliateAmt := money.FromFloat(affiliate).Abs()
+	transFee := entry.BiayaTransaksi.Abs()
 	saldo := netSale - disc - voucher - admin - layanan - affiliateAmt - transFee
 
 	je := &models.JournalEntry{
@@ -753,13 +785,6 @@ func (s *ShopeeService) createSettlementJournal(ctx context.Context, jr ShopeeJo
 	return nil
 }
 
-func abs(f float64) float64 {
-	if f < 0 {
-		return -f
-	}
-	return f
-}
-
 // addAffiliateToJournal creates a new journal entry for the given affiliate
 // sale. The entry debits Biaya Affiliate and credits the Saldo Shopee account
 // for the related store.
@@ -785,9 +810,10 @@ func (s *ShopeeService) addAffiliateToJournal(ctx context.Context, sale *models.
 	if err != nil {
 		return err
 	}
+	amt := money.FromFloat(sale.Pengeluaran)
 	lines := []models.JournalLine{
-		{JournalID: jid, AccountID: 55002, IsDebit: true, Amount: sale.Pengeluaran, Memo: ptrString("Biaya Affiliate " + sale.KodePesanan)},
-		{JournalID: jid, AccountID: saldoShopeeAccountID(sale.NamaToko), IsDebit: false, Amount: sale.Pengeluaran, Memo: ptrString("Saldo Shopee " + sale.KodePesanan)},
+		{JournalID: jid, AccountID: 55002, IsDebit: true, Amount: amt, Memo: ptrString("Biaya Affiliate " + sale.KodePesanan)},
+		{JournalID: jid, AccountID: saldoShopeeAccountID(sale.NamaToko), IsDebit: false, Amount: amt, Memo: ptrString("Saldo Shopee " + sale.KodePesanan)},
 	}
 	// Filter out lines with zero amounts and use bulk insert
 	validLines := make([]models.JournalLine, 0, len(lines))
@@ -812,11 +838,11 @@ func (s *ShopeeService) handleMismatch(ctx context.Context, jr ShopeeJournalRepo
 	if err != nil {
 		return err
 	}
-	if math.Abs((o.HargaAsliProduk+o.TotalDiskonProduk)-sum) > 0.01 {
+	if o.HargaAsliProduk+o.TotalDiskonProduk != sum {
 		return fmt.Errorf("amount mismatch")
 	}
 	diff := o.HargaAsliProduk - sum
-	disc := abs(o.TotalDiskonProduk)
+	disc := o.TotalDiskonProduk.Abs()
 	if diff == 0 && disc == 0 {
 		return nil
 	}
@@ -833,7 +859,7 @@ func (s *ShopeeService) handleMismatch(ctx context.Context, jr ShopeeJournalRepo
 	return nil
 }
 
-func (s *ShopeeService) createGrossUpJournal(ctx context.Context, jr ShopeeJournalRepo, o *models.ShopeeSettled, diff float64) error {
+func (s *ShopeeService) createGrossUpJournal(ctx context.Context, jr ShopeeJournalRepo, o *models.ShopeeSettled, diff money.Amount) error {
 	je := &models.JournalEntry{
 		EntryDate:    o.TanggalDanaDilepaskan,
 		Description:  ptrString("Gross Up " + o.NoPesanan),
@@ -858,7 +884,7 @@ func (s *ShopeeService) createGrossUpJournal(ctx context.Context, jr ShopeeJourn
 	return nil
 }
 
-func (s *ShopeeService) createDiscountJournal(ctx context.Context, jr ShopeeJournalRepo, o *models.ShopeeSettled, disc float64) error {
+func (s *ShopeeService) createDiscountJournal(ctx context.Context, jr ShopeeJournalRepo, o *models.ShopeeSettled, disc money.Amount) error {
 	je := &models.JournalEntry{
 		EntryDate:    o.TanggalDanaDilepaskan,
 		Description:  ptrString("Discount " + o.NoPesanan),
@@ -914,7 +940,11 @@ func (s *ShopeeService) ConfirmSettle(ctx context.Context, orderSN string) error
 		if err := s.repo.ConfirmSettle(ctx, orderSN); err != nil {
 			return err
 		}
-		return tx.Commit()
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+		invalidateCache(ctx, s.cache, cache.TagSettlements, cache.TagJournals, cache.TagReports)
+		return nil
 	}
 
 	if jr != nil {
@@ -927,7 +957,11 @@ func (s *ShopeeService) ConfirmSettle(ctx context.Context, orderSN string) error
 			return err
 		}
 	}
-	return s.repo.ConfirmSettle(ctx, orderSN)
+	if err := s.repo.ConfirmSettle(ctx, orderSN); err != nil {
+		return err
+	}
+	invalidateCache(ctx, s.cache, cache.TagSettlements, cache.TagJournals, cache.TagReports)
+	return nil
 }
 
 func CapitalizeWords(s string) string {
@@ -949,11 +983,11 @@ func CapitalizeWords(s string) string {
 
 // withdrawResp models the payout API response.
 type withdrawResp struct {
-	Fee float64 `json:"fee"`
+	Fee money.Amount `json:"fee"`
 }
 
 // WithdrawShopeeBalance moves funds from Shopee balance to bank account.
-func (s *ShopeeService) WithdrawShopeeBalance(ctx context.Context, store string, amount float64) error {
+func (s *ShopeeService) WithdrawShopeeBalance(ctx context.Context, store string, amount money.Amount) error {
 	cfg := s.cfg
 
 	// Create a Shopee client with rate limiting
@@ -966,11 +1000,14 @@ func (s *ShopeeService) WithdrawShopeeBalance(ctx context.Context, store string,
 
 	form := url.Values{}
 	form.Set("shopid", cfg.ShopID)
-	form.Set("amount", fmt.Sprintf("%.2f", amount))
+	form.Set("amount", amount.String())
 	form.Set("access_token", cfg.AccessToken)
 
 	urlStr := cfg.BaseURL + "/api/v2/shop/withdraw"
-	log.Printf("Shopee withdraw request: POST %s body=%s", urlStr, form.Encode())
+	logutil.Info(ctx, "WithdrawShopeeBalance", "Requesting Shopee withdrawal", map[string]interface{}{
+		"store":  store,
+		"amount": amount,
+	})
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, urlStr, strings.NewReader(form.Encode()))
 	if err != nil {
 		return err
@@ -1008,7 +1045,7 @@ func (s *ShopeeService) WithdrawShopeeBalance(ctx context.Context, store string,
 	return createWithdrawJournal(ctx, jr, store, amount, out.Fee)
 }
 
-func createWithdrawJournal(ctx context.Context, jr ShopeeJournalRepo, store string, amount, fee float64) error {
+func createWithdrawJournal(ctx context.Context, jr ShopeeJournalRepo, store string, amount, fee money.Amount) error {
 	je := &models.JournalEntry{
 		EntryDate:    time.Now(),
 		Description:  ptrString("Withdraw Shopee Balance"),
@@ -1126,7 +1163,7 @@ func (s *ShopeeService) parseAdjustmentRows(ctx context.Context, f *excelize.Fil
 		if err != nil {
 			continue
 		}
-		amt, err := parseFloat(row[4])
+		amt, err := money.Parse(row[4])
 		if err != nil {
 			continue
 		}
@@ -1191,8 +1228,8 @@ func (s *ShopeeService) parseSFDRows(ctx context.Context, f *excelize.File, shee
 		if order == "" {
 			continue
 		}
-		est, err1 := parseFloat(fmt.Sprint(row[1]))
-		act, err2 := parseFloat(fmt.Sprint(row[2]))
+		est, err1 := money.Parse(fmt.Sprint(row[1]))
+		act, err2 := money.Parse(fmt.Sprint(row[2]))
 		if err1 != nil || err2 != nil {
 			continue
 		}
@@ -1303,7 +1340,9 @@ func (s *ShopeeService) GetReturnList(ctx context.Context, storeFilter, pageNo,
 
 	for _, store := range targetStores {
 		if store.AccessToken == nil || store.ShopID == nil {
-			log.Printf("Skipping store %s: missing access token or shop ID", store.NamaToko)
+			logutil.Warn(ctx, "GetReturnList", "Skipping store without access token or shop ID", map[string]interface{}{
+				"store": store.NamaToko,
+			})
 			continue
 		}
 
@@ -1342,7 +1381,9 @@ func (s *ShopeeService) GetReturnList(ctx context.Context, storeFilter, pageNo,
 
 		response, err := client.GetReturnList(ctx, *store.AccessToken, *store.ShopID, params)
 		if err != nil {
-			log.Printf("Failed to get returns for store %s: %v", store.NamaToko, err)
+			logutil.Error(ctx, "GetReturnList", "Failed to get returns", err, map[string]interface{}{
+				"store": store.NamaToko,
+			})
 			continue
 		}
 
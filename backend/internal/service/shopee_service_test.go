@@ -11,7 +11,9 @@ import (
 	"github.com/xuri/excelize/v2"
 
 	"github.com/ramadhan22/dropship-erp/backend/internal/config"
+	"github.com/ramadhan22/dropship-erp/backend/internal/lifecycle"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
 )
 
@@ -40,7 +42,7 @@ func (f *fakeShopeeRepo) GetBySN(ctx context.Context, orderSN string) (*models.S
 	if f.order != nil {
 		return f.order, nil
 	}
-	return &models.ShopeeSettled{NamaToko: "TOKO", NoPesanan: orderSN, HargaAsliProduk: 1}, nil
+	return &models.ShopeeSettled{NamaToko: "TOKO", NoPesanan: orderSN, HargaAsliProduk: money.New(1)}, nil
 }
 
 type fakeJournalRepoS struct {
@@ -52,7 +54,7 @@ type fakeJournalRepoS struct {
 type fakeDropRepoA struct {
 	byInvoice map[string]*models.DropshipPurchase
 	byTrans   map[string]*models.DropshipPurchase
-	updated   map[string]string
+	updated   map[string]lifecycle.Change
 }
 
 func (f *fakeDropRepoA) GetDropshipPurchaseByInvoice(ctx context.Context, inv string) (*models.DropshipPurchase, error) {
@@ -76,18 +78,18 @@ func (f *fakeDropRepoA) GetDropshipPurchaseByTransaction(ctx context.Context, tr
 	return nil, nil
 }
 
-func (f *fakeDropRepoA) SumDetailByInvoice(ctx context.Context, inv string) (float64, error) {
+func (f *fakeDropRepoA) SumDetailByInvoice(ctx context.Context, inv string) (money.Amount, error) {
 	if dp, ok := f.byInvoice[inv]; ok {
 		return dp.TotalTransaksi, nil
 	}
 	return 0, nil
 }
 
-func (f *fakeDropRepoA) UpdateDropshipStatus(ctx context.Context, kode, status string) error {
+func (f *fakeDropRepoA) TransitionPurchaseStatus(ctx context.Context, kode string, c lifecycle.Change) error {
 	if f.updated == nil {
-		f.updated = map[string]string{}
+		f.updated = map[string]lifecycle.Change{}
 	}
-	f.updated[kode] = status
+	f.updated[kode] = c
 	return nil
 }
 
@@ -124,7 +126,7 @@ func (f *fakeJournalRepoS) GetLinesByJournalID(ctx context.Context, id int64) ([
 	}
 	return res, nil
 }
-func (f *fakeJournalRepoS) UpdateJournalLineAmount(ctx context.Context, lineID int64, amount float64) error {
+func (f *fakeJournalRepoS) UpdateJournalLineAmount(ctx context.Context, lineID int64, amount money.Amount) error {
 	for _, l := range f.lines {
 		if l.LineID == lineID {
 			l.Amount = amount
@@ -197,6 +199,14 @@ func (f *fakeShopeeRepo) ListShopeeAffiliateSales(ctx context.Context, date, mon
 	return nil, 0, nil
 }
 
+func (f *fakeShopeeRepo) ListShopeeSettledFiltered(ctx context.Context, params *models.FilterParams) (*models.QueryResult, error) {
+	return &models.QueryResult{}, nil
+}
+
+func (f *fakeShopeeRepo) ListShopeeAffiliateSalesFiltered(ctx context.Context, params *models.FilterParams) (*models.QueryResult, error) {
+	return &models.QueryResult{}, nil
+}
+
 func (f *fakeShopeeRepo) SumShopeeAffiliateSales(ctx context.Context, date, month, year string) (*models.ShopeeAffiliateSummary, error) {
 	return &models.ShopeeAffiliateSummary{}, nil
 }
@@ -346,7 +356,7 @@ func TestImportSettledOrdersXLSX_UpdateDropshipStatus(t *testing.T) {
 	if inserted != 1 || repo.count != 1 {
 		t.Fatalf("expected 1 insert, got svc %d repo %d", inserted, repo.count)
 	}
-	if drop.updated["DP1"] != "Pesanan selesai" {
+	if c := drop.updated["DP1"]; c.To != lifecycle.Settled || c.Cause != lifecycle.CauseSettledImport {
 		t.Fatalf("expected status update, got %v", drop.updated)
 	}
 }
@@ -470,11 +480,11 @@ func TestConfirmSettleCreatesFeeLines(t *testing.T) {
 			NoPesanan:                "SO1",
 			WaktuPesananDibuat:       time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
 			TanggalDanaDilepaskan:    time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC),
-			HargaAsliProduk:          100,
-			TotalDiskonProduk:        -10,
-			BiayaAdminShopee:         -5,
-			PromoGratisOngkirPenjual: -2,
-			PromoDiskonShopee:        -3,
+			HargaAsliProduk:          money.New(100),
+			TotalDiskonProduk:        money.New(-10),
+			BiayaAdminShopee:         money.New(-5),
+			PromoGratisOngkirPenjual: money.New(-2),
+			PromoDiskonShopee:        money.New(-3),
 		},
 		affExpense: 1,
 	}
@@ -504,13 +514,13 @@ func TestConfirmSettleMismatchCreatesAdjustment(t *testing.T) {
 			NamaToko:              "TOKO",
 			NoPesanan:             "SO2",
 			TanggalDanaDilepaskan: time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC),
-			HargaAsliProduk:       110,
-			TotalDiskonProduk:     -10,
+			HargaAsliProduk:       money.New(110),
+			TotalDiskonProduk:     money.New(-10),
 			IsDataMismatch:        true,
 		},
 	}
 	drop := &fakeDropRepoA{byInvoice: map[string]*models.DropshipPurchase{
-		"SO2": {TotalTransaksi: 100},
+		"SO2": {TotalTransaksi: money.New(100)},
 	}}
 	jr := &fakeJournalRepoS{}
 	svc := NewShopeeService(nil, repo, drop, jr, nil, nil, config.ShopeeAPIConfig{})
@@ -562,7 +572,7 @@ func TestImportSettledOrdersXLSX_AutoSettle(t *testing.T) {
 
 	repo := &fakeShopeeRepo{}
 	drop := &fakeDropRepoA{byInvoice: map[string]*models.DropshipPurchase{
-		"SO-3": {TotalTransaksi: 1},
+		"SO-3": {TotalTransaksi: money.New(1)},
 	}}
 	jr := &fakeJournalRepoS{}
 	svc := NewShopeeService(nil, repo, drop, jr, nil, nil, config.ShopeeAPIConfig{})
@@ -613,12 +623,12 @@ func TestImportSettledOrdersXLSX_AutoAdjustMismatch(t *testing.T) {
 		NamaToko:              "TOKO",
 		NoPesanan:             "SO-4",
 		TanggalDanaDilepaskan: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC),
-		HargaAsliProduk:       110,
-		TotalDiskonProduk:     -10,
+		HargaAsliProduk:       money.New(110),
+		TotalDiskonProduk:     money.New(-10),
 		IsDataMismatch:        true,
 	}}
 	drop := &fakeDropRepoA{byInvoice: map[string]*models.DropshipPurchase{
-		"SO-4": {TotalTransaksi: 100},
+		"SO-4": {TotalTransaksi: money.New(100)},
 	}}
 	jr := &fakeJournalRepoS{}
 	svc := NewShopeeService(nil, repo, drop, jr, nil, nil, config.ShopeeAPIConfig{})
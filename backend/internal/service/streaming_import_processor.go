@@ -5,7 +5,6 @@ import (
 	"encoding/csv"
 	"fmt"
 	"io"
-	"log"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -13,10 +12,14 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ramadhan22/dropship-erp/backend/internal/lifecycle"
+	"github.com/ramadhan22/dropship-erp/backend/internal/logutil"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
 	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
 )
 
+var importLog = logutil.NewLogger("streaming-import-processor", logutil.INFO)
+
 const (
 	DefaultChunkSize              = 1000              // Process 1000 rows at a time
 	DefaultMaxConcurrentFiles     = 5                 // Process 5 files concurrently
@@ -89,7 +92,10 @@ func (p *StreamingImportProcessor) ProcessMultipleFiles(ctx context.Context, fil
 	var globalErr error
 	var errMu sync.Mutex
 
-	log.Printf("Starting streaming import of %d files with %d concurrent workers", len(filePaths), p.config.MaxConcurrentFiles)
+	importLog.Info(ctx, "ProcessMultipleFiles", "Starting streaming import", map[string]interface{}{
+		"files":   len(filePaths),
+		"workers": p.config.MaxConcurrentFiles,
+	})
 
 	for _, filePath := range filePaths {
 		wg.Add(1)
@@ -105,12 +111,16 @@ func (p *StreamingImportProcessor) ProcessMultipleFiles(ctx context.Context, fil
 				}
 				p.stats.FailedFiles++
 				errMu.Unlock()
-				log.Printf("Failed to process file %s: %v", path, err)
+				importLog.Error(ctx, "ProcessMultipleFiles", "Failed to process file", err, map[string]interface{}{
+					"file": path,
+				})
 			} else {
 				p.mu.Lock()
 				p.stats.ProcessedFiles++
 				p.mu.Unlock()
-				log.Printf("Successfully processed file %s", path)
+				importLog.Info(ctx, "ProcessMultipleFiles", "Processed file", map[string]interface{}{
+					"file": path,
+				})
 			}
 		}(filePath)
 	}
@@ -121,8 +131,11 @@ func (p *StreamingImportProcessor) ProcessMultipleFiles(ctx context.Context, fil
 	duration := time.Since(p.stats.StartTime)
 	p.mu.Unlock()
 
-	log.Printf("Streaming import completed. Files: %d processed, %d failed. Duration: %v",
-		p.stats.ProcessedFiles, p.stats.FailedFiles, duration)
+	importLog.Info(ctx, "ProcessMultipleFiles", "Streaming import completed", map[string]interface{}{
+		"processed":   p.stats.ProcessedFiles,
+		"failed":      p.stats.FailedFiles,
+		"duration_ms": duration.Milliseconds(),
+	})
 
 	return globalErr
 }
@@ -207,7 +220,10 @@ func (p *StreamingImportProcessor) processFileInChunks(ctx context.Context, file
 	// Count total rows for progress tracking
 	totalRows, err := p.countTotalRows(filePath)
 	if err != nil {
-		log.Printf("Warning: could not count total rows: %v", err)
+		importLog.Warn(ctx, "ProcessFile", "Could not count total rows", map[string]interface{}{
+			"file":  filePath,
+			"error": err,
+		})
 	} else if p.service.batchSvc != nil && batchID != 0 {
 		p.service.batchSvc.UpdateTotal(ctx, batchID, totalRows)
 	}
@@ -230,12 +246,19 @@ func (p *StreamingImportProcessor) processFileInChunks(ctx context.Context, file
 		}
 
 		chunkNum++
-		log.Printf("Processing chunk %d with %d rows", chunkNum, len(chunk))
+		importLog.Debug(ctx, "ProcessFile", "Processing chunk", map[string]interface{}{
+			"file":  filePath,
+			"chunk": chunkNum,
+			"rows":  len(chunk),
+		})
 
 		// Process chunk with transaction
 		rowsProcessed, err := p.processChunk(ctx, chunk, channel, batchID)
 		if err != nil {
-			log.Printf("Error processing chunk %d: %v", chunkNum, err)
+			importLog.Error(ctx, "ProcessFile", "Failed to process chunk", err, map[string]interface{}{
+				"file":  filePath,
+				"chunk": chunkNum,
+			})
 			// Continue processing other chunks rather than failing entirely
 		}
 
@@ -259,7 +282,11 @@ func (p *StreamingImportProcessor) processFileInChunks(ctx context.Context, file
 		}
 	}
 
-	log.Printf("Completed processing file %s: %d rows in %d chunks", filePath, processedRows, chunkNum)
+	importLog.Info(ctx, "ProcessFile", "Completed processing file", map[string]interface{}{
+		"file":   filePath,
+		"rows":   processedRows,
+		"chunks": chunkNum,
+	})
 	return nil
 }
 
@@ -305,7 +332,10 @@ func (p *StreamingImportProcessor) processChunk(ctx context.Context, chunk [][]s
 	processedCount := 0
 	for _, record := range chunk {
 		if err := p.processRecord(ctx, repoTx, jrTx, record, channel, batchID); err != nil {
-			log.Printf("Error processing record: %v", err)
+			importLog.Error(ctx, "ProcessChunk", "Failed to process record", err, map[string]interface{}{
+				"batch_id": batchID,
+			})
+			p.recordFailure(ctx, batchID, record, err)
 			// Continue processing other records in the chunk
 			continue
 		}
@@ -320,6 +350,20 @@ func (p *StreamingImportProcessor) processChunk(ctx context.Context, chunk [][]s
 	return processedCount, nil
 }
 
+// recordFailure records a row that could not be imported as a failed
+// detail of the batch, as ImportFromCSV does.
+func (p *StreamingImportProcessor) recordFailure(ctx context.Context, batchID int64, record []string, err error) {
+	if p.service.batchSvc == nil || batchID == 0 {
+		return
+	}
+	d := &models.BatchHistoryDetail{BatchID: batchID, Status: "failed", ErrorMsg: err.Error()}
+	if len(record) >= 20 {
+		d.Reference = record[19]
+		d.Store = record[18]
+	}
+	_ = p.service.batchSvc.CreateDetail(ctx, d)
+}
+
 // processRecord processes a single CSV record
 func (p *StreamingImportProcessor) processRecord(ctx context.Context, repoTx DropshipRepoInterface, jrTx DropshipJournalRepo, record []string, channel string, batchID int64) error {
 	// Validate record length
@@ -333,14 +377,18 @@ func (p *StreamingImportProcessor) processRecord(ctx context.Context, repoTx Dro
 		return fmt.Errorf("parse qty: %w", err)
 	}
 
-	hargaProduk, _ := strconv.ParseFloat(record[7], 64)
-	totalHargaProduk, _ := strconv.ParseFloat(record[9], 64)
-	biayaLain, _ := strconv.ParseFloat(record[10], 64)
-	biayaMitra, _ := strconv.ParseFloat(record[11], 64)
-	totalTransaksi, _ := strconv.ParseFloat(record[12], 64)
-	hargaChannel, _ := strconv.ParseFloat(record[13], 64)
-	totalHargaChannel, _ := strconv.ParseFloat(record[14], 64)
-	potensi, _ := strconv.ParseFloat(record[15], 64)
+	amounts, err := parseDropshipAmounts(record)
+	if err != nil {
+		return err
+	}
+	hargaProduk := amounts[7]
+	totalHargaProduk := amounts[9]
+	biayaLain := amounts[10]
+	biayaMitra := amounts[11]
+	totalTransaksi := amounts[12]
+	hargaChannel := amounts[13]
+	totalHargaChannel := amounts[14]
+	potensi := amounts[15]
 
 	waktuPesanan, err := time.Parse("02 January 2006, 15:04:05", record[1])
 	if err != nil {
@@ -402,6 +450,9 @@ func (p *StreamingImportProcessor) processRecord(ctx context.Context, repoTx Dro
 		if err := p.service.createPendingSalesJournal(ctx, jrTx, purchase, totalHargaProduk, pending); err != nil {
 			return fmt.Errorf("create journal entry: %w", err)
 		}
+		if err := transitionPurchase(ctx, repoTx, purchase.KodePesanan, lifecycle.Change{To: lifecycle.PendingSale, Cause: lifecycle.CausePendingJournal}); err != nil {
+			return err
+		}
 	}
 
 	return nil
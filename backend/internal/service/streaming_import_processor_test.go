@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
 )
 
@@ -15,7 +16,7 @@ type mockDropshipService struct {
 	details   []models.DropshipPurchaseDetail
 }
 
-func (m *mockDropshipService) createPendingSalesJournal(ctx context.Context, jr DropshipJournalRepo, purchase *models.DropshipPurchase, prodTotal, pending float64) error {
+func (m *mockDropshipService) createPendingSalesJournal(ctx context.Context, jr DropshipJournalRepo, purchase *models.DropshipPurchase, prodTotal, pending money.Amount) error {
 	return nil
 }
 
@@ -48,7 +49,7 @@ func (m *mockDropshipRepo) ListDropshipPurchasesFiltered(ctx context.Context, pa
 	return &models.QueryResult{}, nil
 }
 
-func (m *mockDropshipRepo) SumDropshipPurchases(ctx context.Context, channel, store, from, to string) (float64, error) {
+func (m *mockDropshipRepo) SumDropshipPurchases(ctx context.Context, channel, store, from, to string) (money.Amount, error) {
 	return 0, nil
 }
 
@@ -72,6 +73,10 @@ func (m *mockDropshipRepo) MonthlyTotals(ctx context.Context, channel, store, fr
 	return []repository.MonthlyPurchaseTotal{}, nil
 }
 
+func (m *mockDropshipRepo) ListPurchaseStatusHistory(ctx context.Context, kodePesanan string) ([]models.PurchaseStatusEvent, error) {
+	return nil, nil
+}
+
 func (m *mockDropshipRepo) CancelledSummary(ctx context.Context, channel, store, from, to string) (repository.CancelledSummary, error) {
 	return repository.CancelledSummary{}, nil
 }
@@ -192,3 +197,22 @@ func TestStreamingImportProcessor_ValidateHeader(t *testing.T) {
 		t.Error("Expected error for invalid header")
 	}
 }
+
+func TestStreamingImportProcessor_RejectsMalformedAmount(t *testing.T) {
+	processor := NewStreamingImportProcessor(nil, DefaultStreamingImportConfig())
+	repo := &mockDropshipRepo{purchases: map[string]bool{}}
+
+	record := make([]string, 20)
+	for i := 7; i <= 15; i++ {
+		record[i] = "1000"
+	}
+	record[3] = "PS-1"
+	record[10] = "12,34,5"
+	err := processor.processRecord(context.Background(), repo, nil, record, "", 0)
+	if err == nil {
+		t.Fatal("expected malformed biaya_lainnya to fail the row")
+	}
+	if repo.purchases["PS-1"] {
+		t.Error("purchase with a malformed amount was imported")
+	}
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/logutil"
+)
+
+// BackgroundComponent is a scheduler or worker owned by the Supervisor.
+type BackgroundComponent interface {
+	// Start launches the component. Work it runs uses ctx, which the
+	// supervisor cancels only when draining exceeds its deadline.
+	Start(ctx context.Context)
+	// Stop stops the component from taking new work.
+	Stop()
+	// Done is closed once the component's goroutines have returned.
+	Done() <-chan struct{}
+}
+
+// BatchResumer resets the batches this instance left processing when a
+// shutdown aborted its jobs.
+type BatchResumer interface {
+	ResumeInterrupted(ctx context.Context) (resumed, failed int64, err error)
+}
+
+// abortGrace is how long components get to return after their work
+// context is cancelled.
+const abortGrace = 5 * time.Second
+
+type supervised struct {
+	name string
+	c    BackgroundComponent
+}
+
+type shutdownHook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// Supervisor owns the API's background components. On Shutdown it stops
+// intake, runs the shutdown hooks (such as stopping the HTTP server) while
+// running jobs drain, and when the deadline passes aborts whatever is still
+// running and marks its unfinished batches so they resume on the next start.
+type Supervisor struct {
+	ctx        context.Context
+	cancel     context.CancelFunc
+	mu         sync.Mutex
+	components []supervised
+	hooks      []shutdownHook
+	resumer    BatchResumer
+	logger     *logutil.Logger
+}
+
+// NewSupervisor creates a Supervisor whose components run under a context
+// derived from parent.
+func NewSupervisor(parent context.Context) *Supervisor {
+	ctx, cancel := context.WithCancel(parent)
+	return &Supervisor{
+		ctx:    ctx,
+		cancel: cancel,
+		logger: logutil.NewLogger("supervisor", logutil.INFO),
+	}
+}
+
+// Context returns the context background work runs under. It is cancelled
+// when draining times out.
+func (s *Supervisor) Context() context.Context {
+	return s.ctx
+}
+
+// SetBatchResumer sets what resets interrupted batches after draining.
+func (s *Supervisor) SetBatchResumer(r BatchResumer) {
+	s.resumer = r
+}
+
+// Start starts c and takes ownership of it.
+func (s *Supervisor) Start(name string, c BackgroundComponent) {
+	s.mu.Lock()
+	s.components = append(s.components, supervised{name: name, c: c})
+	s.mu.Unlock()
+	c.Start(s.ctx)
+}
+
+// OnShutdown registers fn to run once intake has stopped, while jobs drain.
+// Hooks run in registration order with the drain deadline as their context.
+func (s *Supervisor) OnShutdown(name string, fn func(ctx context.Context) error) {
+	s.mu.Lock()
+	s.hooks = append(s.hooks, shutdownHook{name: name, fn: fn})
+	s.mu.Unlock()
+}
+
+// Shutdown stops all components and waits for running jobs until ctx is
+// done. Jobs still running then are aborted and their batches reset. It
+// returns context.DeadlineExceeded when draining did not finish in time,
+// joined with any hook errors.
+func (s *Supervisor) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	components := append([]supervised(nil), s.components...)
+	hooks := append([]shutdownHook(nil), s.hooks...)
+	s.mu.Unlock()
+
+	s.logger.Info(ctx, "Shutdown", "Stopping intake", map[string]interface{}{
+		"components": len(components),
+	})
+	for _, sc := range components {
+		sc.c.Stop()
+	}
+
+	var errs []error
+	for _, h := range hooks {
+		if err := h.fn(ctx); err != nil {
+			s.logger.Error(ctx, "Shutdown", "Shutdown hook "+h.name+" failed", err)
+			errs = append(errs, err)
+		}
+	}
+
+	pending := s.wait(ctx, components)
+	aborted := len(pending) > 0
+	if aborted {
+		s.logger.Warn(ctx, "Shutdown", "Drain deadline passed, aborting running jobs", map[string]interface{}{
+			"pending": pending,
+		})
+		errs = append(errs, context.DeadlineExceeded)
+		s.cancel()
+		graceCtx, cancel := context.WithTimeout(context.Background(), abortGrace)
+		if still := s.wait(graceCtx, components); len(still) > 0 {
+			s.logger.Warn(ctx, "Shutdown", "Components did not return after abort", map[string]interface{}{
+				"pending": still,
+			})
+		}
+		cancel()
+	}
+	s.cancel()
+
+	// Jobs that drained finished their batches; only aborted ones need a reset.
+	if aborted && s.resumer != nil {
+		rctx, cancel := context.WithTimeout(context.Background(), abortGrace)
+		resumed, failed, err := s.resumer.ResumeInterrupted(rctx)
+		cancel()
+		if err != nil {
+			s.logger.Error(ctx, "Shutdown", "Failed to reset interrupted batches", err)
+			errs = append(errs, err)
+		} else if resumed > 0 || failed > 0 {
+			s.logger.Info(ctx, "Shutdown", "Reset interrupted batches", map[string]interface{}{
+				"resumable": resumed,
+				"failed":    failed,
+			})
+		}
+	}
+	s.logger.Info(ctx, "Shutdown", "Shutdown complete")
+	return errors.Join(errs...)
+}
+
+// wait blocks until every component is done or ctx ends and returns the
+// names of the components still running.
+func (s *Supervisor) wait(ctx context.Context, components []supervised) []string {
+	var pending []string
+	for _, sc := range components {
+		select {
+		case <-sc.c.Done():
+		case <-ctx.Done():
+			pending = append(pending, sc.name)
+		}
+	}
+	return pending
+}
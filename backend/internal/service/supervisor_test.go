@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeJobComponent runs one job that takes jobTime unless its context is
+// cancelled first.
+type fakeJobComponent struct {
+	tickerLoop
+	jobTime  time.Duration
+	finished atomic.Bool
+	aborted  atomic.Bool
+}
+
+func (c *fakeJobComponent) Start(ctx context.Context) {
+	c.tickerLoop.run(ctx, time.Millisecond, func(ctx context.Context) {
+		select {
+		case <-time.After(c.jobTime):
+			c.finished.Store(true)
+		case <-ctx.Done():
+			c.aborted.Store(true)
+		}
+		c.Stop()
+	})
+}
+
+type fakeResumer struct{ calls int }
+
+func (r *fakeResumer) ResumeInterrupted(ctx context.Context) (int64, int64, error) {
+	r.calls++
+	return 1, 0, nil
+}
+
+func TestSupervisorDrainsRunningJobs(t *testing.T) {
+	sup := NewSupervisor(context.Background())
+	comp := &fakeJobComponent{jobTime: 50 * time.Millisecond}
+	res := &fakeResumer{}
+	sup.SetBatchResumer(res)
+	var hookRan bool
+	sup.OnShutdown("http", func(ctx context.Context) error { hookRan = true; return nil })
+
+	sup.Start("job", comp)
+	time.Sleep(10 * time.Millisecond) // let the job start
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := sup.Shutdown(ctx); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	if !comp.finished.Load() || comp.aborted.Load() {
+		t.Errorf("running job should finish, finished=%v aborted=%v", comp.finished.Load(), comp.aborted.Load())
+	}
+	if !hookRan || res.calls != 0 {
+		t.Errorf("drained jobs need no reset: hook ran=%v resumer calls=%d", hookRan, res.calls)
+	}
+}
+
+func TestSupervisorAbortsJobsAfterDeadline(t *testing.T) {
+	sup := NewSupervisor(context.Background())
+	comp := &fakeJobComponent{jobTime: time.Minute}
+	res := &fakeResumer{}
+	sup.SetBatchResumer(res)
+
+	sup.Start("job", comp)
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := sup.Shutdown(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected DeadlineExceeded, got %v", err)
+	}
+	if !comp.aborted.Load() {
+		t.Error("job should be aborted after the deadline")
+	}
+	if res.calls != 1 {
+		t.Errorf("interrupted batches should be reset, resumer calls=%d", res.calls)
+	}
+	select {
+	case <-comp.Done():
+	default:
+		t.Error("component should have returned")
+	}
+}
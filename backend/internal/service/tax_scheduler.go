@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ramadhan22/dropship-erp/backend/internal/logutil"
+)
+
+// TaxScheduler records last month's tax obligations and sends due-date
+// reminders in the background.
+type TaxScheduler struct {
+	svc      *TaxService
+	interval time.Duration
+	logger   *logutil.Logger
+	tickerLoop
+}
+
+// NewTaxScheduler creates a scheduler with the given interval.
+func NewTaxScheduler(svc *TaxService, interval time.Duration) *TaxScheduler {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	return &TaxScheduler{
+		svc:      svc,
+		interval: interval,
+		logger:   logutil.NewLogger("tax-scheduler", logutil.INFO),
+	}
+}
+
+// Start launches the scheduler loop.
+func (s *TaxScheduler) Start(ctx context.Context) {
+	if s == nil {
+		return
+	}
+
+	ctx = logutil.WithNewCorrelationID(ctx)
+	s.logger.Info(ctx, "Start", "Starting tax scheduler", map[string]interface{}{
+		"interval": s.interval,
+	})
+
+	s.tickerLoop.run(ctx, s.interval, s.run)
+}
+
+func (s *TaxScheduler) run(ctx context.Context) {
+	runCtx := logutil.WithNewCorrelationID(ctx)
+
+	recorded, err := s.svc.RecordDue(runCtx)
+	if err != nil {
+		s.logger.Error(runCtx, "RecordDue", "Failed to record tax obligations", err)
+	}
+	reminded, err := s.svc.SendReminders(runCtx)
+	if err != nil {
+		s.logger.Error(runCtx, "SendReminders", "Failed to send tax reminders", err)
+	}
+	if reminded > 0 {
+		s.logger.Info(runCtx, "Run", "Sent tax reminders", map[string]interface{}{
+			"recorded": recorded,
+			"reminded": reminded,
+		})
+	}
+}
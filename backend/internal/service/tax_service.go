@@ -1,109 +1,944 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/ramadhan22/dropship-erp/backend/internal/export"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
 )
 
 const (
 	taxExpenseAcctID int64 = 54001 // PPh Final UMKM expense account id
+	pph25AcctID      int64 = 54002 // PPh Pasal 25/29 expense account id
+	ppnOutputAcctID  int64 = 4004  // PPN Keluaran contra revenue account id
+	ppnPayableAcctID int64 = 21010 // Utang PPN liability account id
+	ppnInputAcctID   int64 = 11016 // PPN Masukan asset account id
 	bankAcctID       int64 = 11002 // Bank account id
 )
 
-// TaxService handles UMKM tax payments.
+// Default rates used when a regime does not override them.
+const (
+	umkmRate       = 0.005
+	corporateRate  = 0.22
+	defaultPPNRate = 0.11
+)
+
+var (
+	// umkmExemptThreshold is the yearly revenue an individual UMKM pays no
+	// PPh Final on.
+	umkmExemptThreshold = money.New(500_000_000)
+	// umkmRevenueLimit is the yearly revenue above which a business no
+	// longer qualifies for PPh Final UMKM from the next year.
+	umkmRevenueLimit = money.New(4_800_000_000)
+)
+
+// pasal17Brackets are the progressive individual income tax rates; the
+// last bracket has no upper bound.
+var pasal17Brackets = []struct {
+	upTo money.Amount
+	rate float64
+}{
+	{money.New(60_000_000), 0.05},
+	{money.New(250_000_000), 0.15},
+	{money.New(500_000_000), 0.25},
+	{money.New(5_000_000_000), 0.30},
+	{0, 0.35},
+}
+
+var (
+	// ErrInvalidTaxRegime is returned for tax regimes that fail validation.
+	ErrInvalidTaxRegime = errors.New("invalid tax regime")
+	// ErrTaxAlreadyPaid is returned when paying an obligation twice.
+	ErrTaxAlreadyPaid = errors.New("tax already paid")
+)
+
+// TaxRepoInterface is the part of the tax repository needed to pay taxes.
 type TaxRepoInterface interface {
 	Get(ctx context.Context, store, periodType, periodValue string) (*models.TaxPayment, error)
+	GetByID(ctx context.Context, id string) (*models.TaxPayment, error)
 	Create(ctx context.Context, tp *models.TaxPayment) error
 	MarkPaid(ctx context.Context, id string, paidAt time.Time) error
+	SetOutputBooked(ctx context.Context, id string, amount money.Amount) error
+}
+
+// TaxStore is the rest of the tax repository: obligations, reminders and
+// regimes.
+type TaxStore interface {
+	GetObligation(ctx context.Context, store, periodType, periodValue, taxType string) (*models.TaxPayment, error)
+	GetByID(ctx context.Context, id string) (*models.TaxPayment, error)
+	Create(ctx context.Context, tp *models.TaxPayment) error
+	UpdateUnpaid(ctx context.Context, tp *models.TaxPayment) error
+	ListPayments(ctx context.Context, store, periodPrefix string, unpaidOnly bool) ([]models.TaxPayment, error)
+	ListReminders(ctx context.Context, until, now time.Time) ([]models.TaxPayment, error)
+	MarkReminded(ctx context.Context, ids []string, at time.Time) error
+	CreateRegime(ctx context.Context, g *models.TaxRegime) error
+	UpdateRegime(ctx context.Context, g *models.TaxRegime) error
+	DeleteRegime(ctx context.Context, id int64) error
+	ListRegimes(ctx context.Context, store string) ([]models.TaxRegime, error)
+	ListTaxStores(ctx context.Context) ([]string, error)
+	NetProfit(ctx context.Context, store string, from, to time.Time) (money.Amount, error)
+	InputVAT(ctx context.Context, store string, from, to time.Time) (money.Amount, error)
 }
 
 type RevenueFetcher interface {
-	GetRevenue(ctx context.Context, store, periodType, periodValue string) (float64, error)
+	GetRevenue(ctx context.Context, store, periodType, periodValue string) (money.Amount, error)
 }
 
+// TaxService computes tax obligations from each store's tax regime, tracks
+// them until paid and reminds about upcoming due dates.
 type TaxService struct {
 	db          *sqlx.DB
 	repo        TaxRepoInterface
 	journalRepo JournalRepoInterface
 	metricSvc   RevenueFetcher
+
+	store          TaxStore
+	company        export.CompanyInfo
+	reminderSink   ReportSink
+	reminderTarget string
+	reminderDays   int
+	cache          Cache
+	now            func() time.Time
 }
 
 func NewTaxService(db *sqlx.DB, repo TaxRepoInterface, jr JournalRepoInterface, metricSvc RevenueFetcher) *TaxService {
-	return &TaxService{db: db, repo: repo, journalRepo: jr, metricSvc: metricSvc}
+	return &TaxService{db: db, repo: repo, journalRepo: jr, metricSvc: metricSvc, now: time.Now}
 }
 
+// SetCache enables invalidation of cached reports after tax payments are
+// posted.
+func (s *TaxService) SetCache(c Cache) { s.cache = c }
+
+// SetStore enables regimes, recorded obligations and reminders. Without it
+// every store is taxed as a corporate UMKM at 0.5% of revenue.
+func (s *TaxService) SetStore(st TaxStore) { s.store = st }
+
+// SetCompany sets the header printed on exported summaries.
+func (s *TaxService) SetCompany(c export.CompanyInfo) { s.company = c }
+
+// SetReminders sends reminders through sink to target for obligations due
+// within days.
+func (s *TaxService) SetReminders(sink ReportSink, target string, days int) {
+	s.reminderSink = sink
+	s.reminderTarget = target
+	s.reminderDays = days
+}
+
+// taxMonth is the computed tax position of one month.
+type taxMonth struct {
+	start       time.Time
+	regime      models.TaxRegime
+	note        string
+	revenue     money.Amount
+	cumulative  money.Amount
+	exempt      money.Amount
+	profit      money.Amount
+	obligations []models.TaxPayment
+}
+
+// ComputeTax returns the income tax of a store for a month ("2025-06") or a
+// year ("2025"). Yearly figures sum the months.
 func (s *TaxService) ComputeTax(ctx context.Context, store, periodType, periodValue string) (*models.TaxPayment, error) {
-	rev, err := s.metricSvc.GetRevenue(ctx, store, periodType, periodValue)
+	if periodType == "yearly" {
+		year, err := strconv.Atoi(periodValue)
+		if err != nil {
+			return nil, fmt.Errorf("invalid year %q", periodValue)
+		}
+		months, err := s.computeYear(ctx, store, year, 12)
+		if err != nil {
+			return nil, err
+		}
+		tp := &models.TaxPayment{Store: store, PeriodType: periodType, PeriodValue: periodValue}
+		for _, m := range months {
+			it := m.obligations[0]
+			tp.TaxType, tp.Regime = it.TaxType, it.Regime
+			tp.Revenue += it.Revenue
+			tp.ExemptAmount += it.ExemptAmount
+			tp.TaxBase += it.TaxBase
+			tp.TaxAmount += it.TaxAmount
+			tp.CumulativeRevenue = m.cumulative
+		}
+		tp.TaxRate = tp.TaxAmount.Ratio(tp.TaxBase)
+		return tp, nil
+	}
+	m, err := s.computeMonth(ctx, store, periodValue)
 	if err != nil {
 		return nil, err
 	}
-	rate := 0.005
-	amt := rev * rate
-	tp := &models.TaxPayment{
-		Store:       store,
-		PeriodType:  periodType,
-		PeriodValue: periodValue,
-		Revenue:     rev,
-		TaxRate:     rate,
-		TaxAmount:   amt,
+	tp := m.obligations[0]
+	tp.PeriodType = periodType
+	return &tp, nil
+}
+
+// ComputeObligations returns every tax a store owes for a month: the
+// income tax first, then PPN when the store is PKP.
+func (s *TaxService) ComputeObligations(ctx context.Context, store, period string) ([]models.TaxPayment, error) {
+	m, err := s.computeMonth(ctx, store, period)
+	if err != nil {
+		return nil, err
 	}
-	return tp, nil
+	return m.obligations, nil
 }
 
-func (s *TaxService) PayTax(ctx context.Context, tp *models.TaxPayment) error {
-	var tx *sqlx.Tx
-	repo := s.repo
-	jr := s.journalRepo
-	if s.db != nil {
+func (s *TaxService) computeMonth(ctx context.Context, store, period string) (*taxMonth, error) {
+	start, err := time.Parse("2006-01", period)
+	if err != nil {
+		return nil, fmt.Errorf("invalid period %q", period)
+	}
+	months, err := s.computeYear(ctx, store, start.Year(), int(start.Month()))
+	if err != nil {
+		return nil, err
+	}
+	return &months[len(months)-1], nil
+}
+
+// computeYear works through January up to month through, keeping the
+// running revenue and profit the thresholds and progressive rates need.
+func (s *TaxService) computeYear(ctx context.Context, store string, year, through int) ([]taxMonth, error) {
+	var regimes []models.TaxRegime
+	if s.store != nil {
 		var err error
-		tx, err = s.db.BeginTxx(ctx, nil)
+		if regimes, err = s.store.ListRegimes(ctx, store); err != nil {
+			return nil, err
+		}
+	}
+	prevYear, err := s.metricSvc.GetRevenue(ctx, store, "yearly", strconv.Itoa(year-1))
+	if err != nil {
+		return nil, err
+	}
+	lapsed := prevYear > umkmRevenueLimit
+
+	// vatCredit is input VAT not yet set off, carried to later months.
+	var cum, ytdProfit, vatCredit money.Amount
+	months := make([]taxMonth, 0, through)
+	for mo := 1; mo <= through; mo++ {
+		start := time.Date(year, time.Month(mo), 1, 0, 0, 0, 0, time.UTC)
+		end := start.AddDate(0, 1, -1)
+		period := start.Format("2006-01")
+		rev, err := s.metricSvc.GetRevenue(ctx, store, "monthly", period)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		var profit money.Amount
+		if s.store != nil {
+			if profit, err = s.store.NetProfit(ctx, store, start, end); err != nil {
+				return nil, err
+			}
+		}
+
+		m := taxMonth{start: start, regime: regimeAt(regimes, store, start), revenue: rev, profit: profit}
+		if m.regime.Regime == models.TaxRegimeUMKM && lapsed {
+			m.regime.Regime = models.TaxRegimeNormal
+			m.note = fmt.Sprintf("revenue of %d exceeded the UMKM limit, normal PPh applies", year-1)
+		}
+		base := models.TaxPayment{
+			Store:             store,
+			PeriodType:        "monthly",
+			PeriodValue:       period,
+			Regime:            m.regime.Regime,
+			Revenue:           rev,
+			CumulativeRevenue: cum + rev,
+			Note:              m.note,
+		}
+
+		var ppn *models.TaxPayment
+		if m.regime.PKP {
+			ppnRate := m.regime.PPNRate
+			if ppnRate <= 0 {
+				ppnRate = defaultPPNRate
+			}
+			p := base
+			p.TaxType = models.TaxPPN
+			p.TaxRate = ppnRate
+			// Marketplace prices include VAT, so the base is backed out of
+			// revenue.
+			p.TaxBase = rev.Mul(1 / (1 + ppnRate))
+			p.OutputTax = rev - p.TaxBase
+			var input money.Amount
+			if s.store != nil {
+				if input, err = s.store.InputVAT(ctx, store, start, end); err != nil {
+					return nil, err
+				}
+			}
+			credit := vatCredit + input
+			p.InputTax = minAmount(positive(credit), p.OutputTax)
+			vatCredit = credit - p.InputTax
+			p.TaxAmount = p.OutputTax - p.InputTax
+			p.DueDate = taxDueDate(models.TaxPPN, start)
+			ppn = &p
+			// The output VAT is owed to the state, not earned.
+			profit -= p.OutputTax
+			m.profit = profit
+		}
+
+		income := base
+		switch m.regime.Regime {
+		case models.TaxRegimeNormal:
+			income.TaxType = models.TaxPPh25
+			income.TaxBase = profit
+			switch {
+			case m.regime.Rate != nil:
+				income.TaxRate = *m.regime.Rate
+				income.TaxAmount = flatYearToDate(ytdProfit, profit, income.TaxRate)
+			case m.regime.TaxpayerType == models.TaxpayerIndividual:
+				income.TaxAmount = positive(pasal17(ytdProfit+profit) - pasal17(ytdProfit))
+				income.TaxRate = income.TaxAmount.Ratio(positive(profit))
+			default:
+				income.TaxRate = corporateRate
+				income.TaxAmount = flatYearToDate(ytdProfit, profit, corporateRate)
+			}
+		default:
+			income.TaxType = models.TaxPPhFinal
+			income.TaxRate = rateOr(m.regime.Rate, umkmRate)
+			if m.regime.TaxpayerType == models.TaxpayerIndividual && cum < umkmExemptThreshold {
+				m.exempt = minAmount(rev, umkmExemptThreshold-cum)
+			}
+			income.ExemptAmount = m.exempt
+			income.TaxBase = rev - m.exempt
+			income.TaxAmount = income.TaxBase.Mul(income.TaxRate)
+		}
+		income.DueDate = taxDueDate(income.TaxType, start)
+		m.obligations = append(m.obligations, income)
+		if ppn != nil {
+			m.obligations = append(m.obligations, *ppn)
+		}
+
+		cum += rev
+		ytdProfit += profit
+		m.cumulative = cum
+		months = append(months, m)
+	}
+	return months, nil
+}
+
+// regimeAt returns the regime in force on day, defaulting to a corporate
+// UMKM when none is configured.
+func regimeAt(regimes []models.TaxRegime, store string, day time.Time) models.TaxRegime {
+	found := models.TaxRegime{Store: store, Regime: models.TaxRegimeUMKM, TaxpayerType: models.TaxpayerCorporate}
+	for _, g := range regimes {
+		if g.EffectiveFrom.After(day) || (g.EffectiveTo != nil && g.EffectiveTo.Before(day)) {
+			continue
 		}
-		defer tx.Rollback()
-		repo = repository.NewTaxRepo(tx)
-		jr = repository.NewJournalRepo(tx)
+		// regimes are ordered by effective_from, so the latest start wins
+		found = g
 	}
+	return found
+}
+
+// pasal17 is the individual income tax on a year's taxable profit.
+func pasal17(profit money.Amount) money.Amount {
+	var tax, lower money.Amount
+	for _, b := range pasal17Brackets {
+		if profit <= lower {
+			break
+		}
+		upper := profit
+		if b.upTo != 0 && b.upTo < profit {
+			upper = b.upTo
+		}
+		tax += (upper - lower).Mul(b.rate)
+		lower = b.upTo
+		if b.upTo == 0 {
+			break
+		}
+	}
+	return tax
+}
+
+func rateOr(rate *float64, def float64) float64 {
+	if rate != nil {
+		return *rate
+	}
+	return def
+}
+
+// flatYearToDate is a month's tax at a flat rate on the year's profit so
+// far, so losses earlier in the year are offset before tax is due again.
+func flatYearToDate(ytd, profit money.Amount, rate float64) money.Amount {
+	return positive(positive(ytd+profit).Mul(rate) - positive(ytd).Mul(rate))
+}
+
+func positive(a money.Amount) money.Amount {
+	if a < 0 {
+		return 0
+	}
+	return a
+}
+
+func minAmount(a, b money.Amount) money.Amount {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// taxDueDate returns when a month's tax must be paid: the 15th of the next
+// month for income tax and the end of the next month for PPN, moved past
+// weekends.
+func taxDueDate(taxType string, month time.Time) *time.Time {
+	next := time.Date(month.Year(), month.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+	due := next.AddDate(0, 0, 14)
+	if taxType == models.TaxPPN {
+		due = next.AddDate(0, 1, -1)
+	}
+	due = nextWorkday(due)
+	return &due
+}
+
+func nextWorkday(d time.Time) time.Time {
+	for d.Weekday() == time.Saturday || d.Weekday() == time.Sunday {
+		d = d.AddDate(0, 0, 1)
+	}
+	return d
+}
+
+// RecordObligations computes a store's taxes for a month and saves them,
+// refreshing obligations that are still unpaid. Paid ones are returned as
+// they were paid. The output VAT of an unpaid PPN obligation is booked to
+// Utang PPN at the end of its month.
+func (s *TaxService) RecordObligations(ctx context.Context, store, period string) ([]models.TaxPayment, error) {
+	if s.store == nil {
+		return nil, errors.New("tax store not configured")
+	}
+	list, err := s.ComputeObligations(ctx, store, period)
+	if err != nil {
+		return nil, err
+	}
+	booked := false
+	for i := range list {
+		tp := &list[i]
+		existing, err := s.store.GetObligation(ctx, store, tp.PeriodType, tp.PeriodValue, tp.TaxType)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			if err := s.store.Create(ctx, tp); err != nil {
+				return nil, err
+			}
+		case err != nil:
+			return nil, err
+		case existing.IsPaid:
+			*tp = *existing
+			continue
+		default:
+			tp.ID = existing.ID
+			tp.RemindedAt = existing.RemindedAt
+			if err := s.store.UpdateUnpaid(ctx, tp); err != nil {
+				return nil, err
+			}
+		}
+		if tp.TaxType == models.TaxPPN {
+			err := s.inTx(ctx, func(repo TaxRepoInterface, jr JournalRepoInterface) error {
+				return bookOutputVAT(ctx, repo, jr, tp)
+			})
+			if err != nil {
+				return nil, err
+			}
+			booked = true
+		}
+	}
+	if booked {
+		invalidateCache(ctx, s.cache, journalWriteTags...)
+	}
+	return list, nil
+}
+
+// bookOutputVAT moves the part of a PPN obligation's output VAT that has not
+// been booked yet from the PPN contra revenue account to Utang PPN, dated the
+// last day of its month so the sale month carries it. When a refresh lowers
+// the output VAT the difference is booked back.
+func bookOutputVAT(ctx context.Context, repo TaxRepoInterface, jr JournalRepoInterface, tp *models.TaxPayment) error {
+	stored, err := repo.GetByID(ctx, tp.ID)
+	if err != nil {
+		return err
+	}
+	delta := tp.OutputTax - stored.OutputBooked
+	if delta == 0 {
+		return nil
+	}
+	start, err := time.Parse("2006-01", tp.PeriodValue)
+	if err != nil {
+		return fmt.Errorf("invalid period %q", tp.PeriodValue)
+	}
+	je := &models.JournalEntry{
+		EntryDate:    start.AddDate(0, 1, -1),
+		Description:  ptrString("PPN Keluaran " + tp.PeriodValue),
+		SourceType:   "tax_ppn_output",
+		SourceID:     tp.ID,
+		ShopUsername: tp.Store,
+		Store:        tp.Store,
+		CreatedAt:    time.Now(),
+	}
+	jid, err := jr.CreateJournalEntry(ctx, je)
+	if err != nil {
+		return err
+	}
+	amount, debit := delta, true
+	if delta < 0 {
+		amount, debit = -delta, false
+	}
+	lines := []models.JournalLine{
+		{JournalID: jid, AccountID: ppnOutputAcctID, IsDebit: debit, Amount: amount},
+		{JournalID: jid, AccountID: ppnPayableAcctID, IsDebit: !debit, Amount: amount},
+	}
+	if err := jr.InsertJournalLines(ctx, lines); err != nil {
+		return err
+	}
+	tp.OutputBooked = tp.OutputTax
+	return repo.SetOutputBooked(ctx, tp.ID, tp.OutputTax)
+}
+
+// ListObligations returns recorded obligations, optionally for one store and
+// periods starting with periodPrefix.
+func (s *TaxService) ListObligations(ctx context.Context, store, periodPrefix string, unpaidOnly bool) ([]models.TaxPayment, error) {
+	if s.store == nil {
+		return []models.TaxPayment{}, nil
+	}
+	return s.store.ListPayments(ctx, store, periodPrefix, unpaidOnly)
+}
 
+// PayObligation pays a recorded obligation.
+func (s *TaxService) PayObligation(ctx context.Context, id string) (*models.TaxPayment, error) {
+	if s.store == nil {
+		return nil, errors.New("tax store not configured")
+	}
+	tp, err := s.store.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if tp.IsPaid {
+		return nil, fmt.Errorf("%w: %s %s %s", ErrTaxAlreadyPaid, tp.Store, tp.TaxType, tp.PeriodValue)
+	}
+	if err := s.PayTax(ctx, tp); err != nil {
+		return nil, err
+	}
+	return tp, nil
+}
+
+// PayTax pays tp from the bank, creating its obligation first when it has
+// no ID. Income tax is debited to its expense account. PPN clears Utang PPN,
+// booking the output VAT first if it was not booked yet, and uses up the
+// input VAT set off against it.
+func (s *TaxService) PayTax(ctx context.Context, tp *models.TaxPayment) error {
+	if tp.TaxType == models.TaxPPN && tp.OutputTax == 0 && tp.InputTax == 0 {
+		// Obligations recorded before input VAT was tracked.
+		tp.OutputTax = tp.TaxAmount
+	}
+	if tp.TaxType == models.TaxPPN && tp.OutputTax-tp.InputTax != tp.TaxAmount {
+		return fmt.Errorf("PPN %s: output %s less input %s is not %s", tp.PeriodValue, tp.OutputTax, tp.InputTax, tp.TaxAmount)
+	}
+	err := s.inTx(ctx, func(repo TaxRepoInterface, jr JournalRepoInterface) error {
+		return payTax(ctx, repo, jr, tp)
+	})
+	if err != nil {
+		return err
+	}
+	invalidateCache(ctx, s.cache, journalWriteTags...)
+	return nil
+}
+
+func payTax(ctx context.Context, repo TaxRepoInterface, jr JournalRepoInterface, tp *models.TaxPayment) error {
 	if tp.ID == "" {
 		if err := repo.Create(ctx, tp); err != nil {
 			return err
 		}
+		if tp.IsPaid {
+			return fmt.Errorf("%w: %s %s %s", ErrTaxAlreadyPaid, tp.Store, tp.TaxType, tp.PeriodValue)
+		}
+	}
+	if tp.TaxType == models.TaxPPN {
+		if err := bookOutputVAT(ctx, repo, jr, tp); err != nil {
+			return err
+		}
 	}
 
+	now := time.Now()
 	tp.IsPaid = true
-	tp.PaidAt = time.Now()
+	tp.PaidAt = &now
 
 	je := &models.JournalEntry{
-		EntryDate:    tp.PaidAt,
-		Description:  ptrString("UMKM Tax " + tp.PeriodValue),
+		EntryDate:    now,
+		Description:  ptrString(taxLabel(tp.TaxType) + " " + tp.PeriodValue),
 		SourceType:   "tax_payment",
 		SourceID:     tp.ID,
 		ShopUsername: tp.Store,
 		Store:        tp.Store,
-		CreatedAt:    time.Now(),
+		CreatedAt:    now,
 	}
 	jid, err := jr.CreateJournalEntry(ctx, je)
 	if err != nil {
 		return err
 	}
 	lines := []models.JournalLine{
-		{JournalID: jid, AccountID: taxExpenseAcctID, IsDebit: true, Amount: tp.TaxAmount},
+		{JournalID: jid, AccountID: taxAccountID(tp.TaxType), IsDebit: true, Amount: tp.TaxAmount},
 		{JournalID: jid, AccountID: bankAcctID, IsDebit: false, Amount: tp.TaxAmount},
 	}
+	if tp.TaxType == models.TaxPPN {
+		lines = []models.JournalLine{
+			{JournalID: jid, AccountID: ppnPayableAcctID, IsDebit: true, Amount: tp.OutputTax},
+			{JournalID: jid, AccountID: bankAcctID, IsDebit: false, Amount: tp.TaxAmount},
+		}
+		if tp.InputTax != 0 {
+			lines = append(lines, models.JournalLine{JournalID: jid, AccountID: ppnInputAcctID, IsDebit: false, Amount: tp.InputTax})
+		}
+	}
 	// Use bulk insert for lines
 	if err := jr.InsertJournalLines(ctx, lines); err != nil {
 		return err
 	}
-	if err := repo.MarkPaid(ctx, tp.ID, tp.PaidAt); err != nil {
+	return repo.MarkPaid(ctx, tp.ID, now)
+}
+
+// inTx runs fn with repositories bound to a new transaction, or to the
+// service's own repositories when it has no database.
+func (s *TaxService) inTx(ctx context.Context, fn func(TaxRepoInterface, JournalRepoInterface) error) error {
+	if s.db == nil {
+		return fn(s.repo, s.journalRepo)
+	}
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
 		return err
 	}
-	if tx != nil {
-		return tx.Commit()
+	defer tx.Rollback()
+	if err := fn(repository.NewTaxRepo(tx), repository.NewJournalRepo(tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// taxAccountID is the account an income tax payment is debited to.
+func taxAccountID(taxType string) int64 {
+	if taxType == models.TaxPPh25 {
+		return pph25AcctID
+	}
+	return taxExpenseAcctID
+}
+
+func taxLabel(taxType string) string {
+	switch taxType {
+	case models.TaxPPh25:
+		return "PPh 25"
+	case models.TaxPPN:
+		return "PPN"
+	}
+	return "UMKM Tax"
+}
+
+// CumulativeRevenue returns the months of a year up to the current month
+// with their running revenue and the tax computed on them.
+func (s *TaxService) CumulativeRevenue(ctx context.Context, store string, year int) ([]models.TaxMonth, error) {
+	months, err := s.computeYear(ctx, store, year, s.lastMonth(year))
+	if err != nil {
+		return nil, err
+	}
+	return toTaxMonths(months), nil
+}
+
+// toTaxMonths turns computed months into their report form.
+func toTaxMonths(months []taxMonth) []models.TaxMonth {
+	out := make([]models.TaxMonth, 0, len(months))
+	for _, m := range months {
+		tm := models.TaxMonth{
+			Period:            m.start.Format("2006-01"),
+			Regime:            m.regime.Regime,
+			Revenue:           m.revenue,
+			CumulativeRevenue: m.cumulative,
+			ExemptAmount:      m.exempt,
+			NetProfit:         m.profit,
+		}
+		for _, tp := range m.obligations {
+			switch tp.TaxType {
+			case models.TaxPPhFinal:
+				tm.PPhFinal = tp.TaxAmount
+			case models.TaxPPh25:
+				tm.PPh25 = tp.TaxAmount
+			case models.TaxPPN:
+				tm.PPN = tp.TaxAmount
+			}
+		}
+		out = append(out, tm)
+	}
+	return out
+}
+
+// lastMonth is 12 for past years and the current month for this year.
+func (s *TaxService) lastMonth(year int) int {
+	now := s.now()
+	if year == now.Year() {
+		return int(now.Month())
+	}
+	return 12
+}
+
+// AnnualSummary collects a store's year for the annual SPT: revenue and
+// profit, the taxes of each month, what was paid and the PPh 29 still due.
+func (s *TaxService) AnnualSummary(ctx context.Context, store string, year int) (*models.TaxAnnualSummary, error) {
+	months, err := s.computeYear(ctx, store, year, s.lastMonth(year))
+	if err != nil {
+		return nil, err
+	}
+	tms := toTaxMonths(months)
+	if s.store != nil {
+		paid, err := s.store.ListPayments(ctx, store, strconv.Itoa(year), false)
+		if err != nil {
+			return nil, err
+		}
+		for _, tp := range paid {
+			if !tp.IsPaid || tp.PeriodType != "monthly" {
+				continue
+			}
+			// Duplicate payments kept by migration 0085 are "2025-01#dup2".
+			period, _, _ := strings.Cut(tp.PeriodValue, "#")
+			for i := range tms {
+				if tms[i].Period == period {
+					tms[i].Paid += tp.TaxAmount
+				}
+			}
+		}
+	}
+
+	sum := &models.TaxAnnualSummary{Store: store, Year: year, Months: tms, TaxpayerType: models.TaxpayerCorporate}
+	var normalProfit money.Amount
+	var normal *models.TaxRegime
+	for i, tm := range tms {
+		sum.Revenue += tm.Revenue
+		sum.NetProfit += tm.NetProfit
+		sum.ExemptRevenue += tm.ExemptAmount
+		sum.PPhFinal += tm.PPhFinal
+		sum.PPh25 += tm.PPh25
+		sum.PPN += tm.PPN
+		sum.Paid += tm.Paid
+		if sum.ThresholdMonth == "" && tm.CumulativeRevenue > umkmExemptThreshold {
+			sum.ThresholdMonth = tm.Period
+		}
+		if tm.CumulativeRevenue > umkmRevenueLimit {
+			sum.UMKMLimitExceeded = true
+		}
+		if months[i].regime.Regime == models.TaxRegimeNormal {
+			normalProfit += tm.NetProfit
+			normal = &months[i].regime
+		}
+		sum.TaxpayerType = months[i].regime.TaxpayerType
+	}
+	if normal != nil {
+		switch {
+		case normal.Rate != nil:
+			sum.AnnualPPh = positive(normalProfit).Mul(*normal.Rate)
+		case normal.TaxpayerType == models.TaxpayerIndividual:
+			sum.AnnualPPh = pasal17(normalProfit)
+		default:
+			sum.AnnualPPh = positive(normalProfit).Mul(corporateRate)
+		}
+		sum.PPh29 = sum.AnnualPPh - sum.PPh25
+	}
+	if sum.TaxpayerType == models.TaxpayerIndividual {
+		sum.SPTDue = time.Date(year+1, time.March, 31, 0, 0, 0, 0, time.UTC)
+	} else {
+		sum.SPTDue = time.Date(year+1, time.April, 30, 0, 0, 0, 0, time.UTC)
+	}
+	return sum, nil
+}
+
+// ExportAnnualSummary renders the annual summary in the given format and
+// returns the file with its name.
+func (s *TaxService) ExportAnnualSummary(ctx context.Context, store string, year int, format export.Format) ([]byte, string, error) {
+	sum, err := s.AnnualSummary(ctx, store, year)
+	if err != nil {
+		return nil, "", err
+	}
+	doc := &export.Document{
+		Company:     s.company,
+		Title:       "Annual Tax Summary",
+		Period:      strconv.Itoa(year),
+		Store:       store,
+		GeneratedAt: s.now(),
+	}
+	monthly := export.Section{
+		Title: "Monthly",
+		Columns: []export.Column{
+			{Header: "Period", Kind: export.KindText},
+			{Header: "Regime", Kind: export.KindText},
+			{Header: "Revenue", Kind: export.KindMoney},
+			{Header: "Cumulative Revenue", Kind: export.KindMoney},
+			{Header: "Exempt", Kind: export.KindMoney},
+			{Header: "Net Profit", Kind: export.KindMoney},
+			{Header: "PPh Final", Kind: export.KindMoney},
+			{Header: "PPh 25", Kind: export.KindMoney},
+			{Header: "PPN", Kind: export.KindMoney},
+			{Header: "Paid", Kind: export.KindMoney},
+		},
+	}
+	for _, m := range sum.Months {
+		monthly.Rows = append(monthly.Rows, export.Row{Cells: []interface{}{
+			m.Period, m.Regime, m.Revenue, m.CumulativeRevenue, m.ExemptAmount,
+			m.NetProfit, m.PPhFinal, m.PPh25, m.PPN, m.Paid,
+		}})
+	}
+	monthly.Rows = append(monthly.Rows, export.Row{Bold: true, Cells: []interface{}{
+		"Total", "", sum.Revenue, nil, sum.ExemptRevenue,
+		sum.NetProfit, sum.PPhFinal, sum.PPh25, sum.PPN, sum.Paid,
+	}})
+	totals := export.Section{
+		Title:   "SPT",
+		Columns: []export.Column{{Header: "Item", Kind: export.KindText}, {Header: "Amount", Kind: export.KindMoney}},
+		Rows: []export.Row{
+			{Cells: []interface{}{"Gross revenue", sum.Revenue}},
+			{Cells: []interface{}{"Revenue under UMKM threshold", sum.ExemptRevenue}},
+			{Cells: []interface{}{"PPh Final UMKM", sum.PPhFinal}},
+			{Cells: []interface{}{"Annual PPh (normal regime)", sum.AnnualPPh}},
+			{Cells: []interface{}{"PPh 25 instalments", sum.PPh25}},
+			{Cells: []interface{}{"PPh 29 due", sum.PPh29}, Bold: true},
+			{Cells: []interface{}{"PPN", sum.PPN}},
+			{Cells: []interface{}{"Paid", sum.Paid}},
+		},
+	}
+	notes := export.Section{
+		Title:   "Notes",
+		Columns: []export.Column{{Header: "Item", Kind: export.KindText}, {Header: "Value", Kind: export.KindText}},
+		Rows: []export.Row{
+			{Cells: []interface{}{"Taxpayer type", sum.TaxpayerType}},
+			{Cells: []interface{}{"UMKM threshold passed in", sum.ThresholdMonth}},
+			{Cells: []interface{}{"UMKM limit exceeded", strconv.FormatBool(sum.UMKMLimitExceeded)}},
+			{Cells: []interface{}{"SPT due", sum.SPTDue.Format("2006-01-02")}},
+		},
+	}
+	doc.Sections = []export.Section{monthly, totals, notes}
+
+	var buf bytes.Buffer
+	if err := export.Render(&buf, doc, format); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), doc.Filename("tax-summary", format), nil
+}
+
+// RecordDue records last month's obligations for every store.
+func (s *TaxService) RecordDue(ctx context.Context) (int, error) {
+	if s.store == nil {
+		return 0, nil
+	}
+	stores, err := s.store.ListTaxStores(ctx)
+	if err != nil {
+		return 0, err
+	}
+	now := s.now()
+	period := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0).Format("2006-01")
+	n := 0
+	for _, st := range stores {
+		list, err := s.RecordObligations(ctx, st, period)
+		if err != nil {
+			return n, fmt.Errorf("record %s %s: %w", st, period, err)
+		}
+		n += len(list)
+	}
+	return n, nil
+}
+
+// SendReminders sends one reminder listing unpaid obligations due within
+// the configured number of days, plus overdue ones not reminded since they
+// fell due. It returns how many obligations were included.
+func (s *TaxService) SendReminders(ctx context.Context) (int, error) {
+	if s.store == nil || s.reminderSink == nil {
+		return 0, nil
+	}
+	now := s.now()
+	list, err := s.store.ListReminders(ctx, now.AddDate(0, 0, s.reminderDays), now)
+	if err != nil || len(list) == 0 {
+		return 0, err
+	}
+	var b strings.Builder
+	ids := make([]string, 0, len(list))
+	var total money.Amount
+	for _, tp := range list {
+		status := "due"
+		if tp.DueDate.Before(now.Truncate(24 * time.Hour)) {
+			status = "OVERDUE"
+		}
+		fmt.Fprintf(&b, "%s  %-7s  %-10s %-8s %s  Rp %s\n",
+			tp.DueDate.Format("2006-01-02"), status, tp.Store, taxLabel(tp.TaxType), tp.PeriodValue, tp.TaxAmount)
+		ids = append(ids, tp.ID)
+		total += tp.TaxAmount
+	}
+	fmt.Fprintf(&b, "\nTotal: Rp %s\n", total)
+	f := ReportFile{
+		Name:        "tax-reminder_" + now.Format("2006-01-02") + ".txt",
+		ContentType: "text/plain; charset=utf-8",
+		Subject:     fmt.Sprintf("Tax reminder: %d payment(s) due", len(list)),
+		Data:        []byte(b.String()),
+	}
+	if err := s.reminderSink.Deliver(ctx, s.reminderTarget, f); err != nil {
+		return 0, err
+	}
+	if err := s.store.MarkReminded(ctx, ids, now); err != nil {
+		return 0, err
+	}
+	return len(list), nil
+}
+
+// ListRegimes returns configured regimes, for one store or all.
+func (s *TaxService) ListRegimes(ctx context.Context, store string) ([]models.TaxRegime, error) {
+	if s.store == nil {
+		return []models.TaxRegime{}, nil
+	}
+	return s.store.ListRegimes(ctx, store)
+}
+
+// CreateRegime validates and saves a new regime.
+func (s *TaxService) CreateRegime(ctx context.Context, g *models.TaxRegime) error {
+	if err := validateTaxRegime(g); err != nil {
+		return err
+	}
+	return s.store.CreateRegime(ctx, g)
+}
+
+// UpdateRegime validates and saves an existing regime.
+func (s *TaxService) UpdateRegime(ctx context.Context, g *models.TaxRegime) error {
+	if err := validateTaxRegime(g); err != nil {
+		return err
+	}
+	return s.store.UpdateRegime(ctx, g)
+}
+
+// DeleteRegime removes a regime.
+func (s *TaxService) DeleteRegime(ctx context.Context, id int64) error {
+	return s.store.DeleteRegime(ctx, id)
+}
+
+func validateTaxRegime(g *models.TaxRegime) error {
+	g.Store = strings.TrimSpace(g.Store)
+	if g.Store == "" {
+		return fmt.Errorf("%w: store is required", ErrInvalidTaxRegime)
+	}
+	if g.Regime != models.TaxRegimeUMKM && g.Regime != models.TaxRegimeNormal {
+		return fmt.Errorf("%w: unknown regime %q", ErrInvalidTaxRegime, g.Regime)
+	}
+	if g.TaxpayerType == "" {
+		g.TaxpayerType = models.TaxpayerIndividual
+	}
+	if g.TaxpayerType != models.TaxpayerIndividual && g.TaxpayerType != models.TaxpayerCorporate {
+		return fmt.Errorf("%w: unknown taxpayer type %q", ErrInvalidTaxRegime, g.TaxpayerType)
+	}
+	if g.Rate != nil && (*g.Rate < 0 || *g.Rate >= 1) {
+		return fmt.Errorf("%w: rate must be between 0 and 1", ErrInvalidTaxRegime)
+	}
+	if g.PPNRate == 0 {
+		g.PPNRate = defaultPPNRate
+	}
+	if g.PPNRate < 0 || g.PPNRate >= 1 {
+		return fmt.Errorf("%w: ppn_rate must be between 0 and 1", ErrInvalidTaxRegime)
+	}
+	if g.EffectiveFrom.IsZero() {
+		return fmt.Errorf("%w: effective_from is required", ErrInvalidTaxRegime)
+	}
+	if g.EffectiveTo != nil && g.EffectiveTo.Before(g.EffectiveFrom) {
+		return fmt.Errorf("%w: effective_to is before effective_from", ErrInvalidTaxRegime)
 	}
 	return nil
 }
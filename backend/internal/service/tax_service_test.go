@@ -2,16 +2,21 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
 )
 
-type fakeMetricSvc struct{ rev float64 }
+type fakeMetricSvc struct{ rev money.Amount }
 
-func (f *fakeMetricSvc) GetRevenue(ctx context.Context, store, pt, pv string) (float64, error) {
+func (f *fakeMetricSvc) GetRevenue(ctx context.Context, store, pt, pv string) (money.Amount, error) {
 	return f.rev, nil
 }
 
@@ -27,7 +32,13 @@ type fakeTaxRepo struct {
 func (f *fakeTaxRepo) Get(ctx context.Context, store, pt, pv string) (*models.TaxPayment, error) {
 	return nil, nil
 }
+func (f *fakeTaxRepo) GetByID(ctx context.Context, id string) (*models.TaxPayment, error) {
+	return &models.TaxPayment{ID: id}, nil
+}
 func (f *fakeTaxRepo) Create(ctx context.Context, tp *models.TaxPayment) error { return nil }
+func (f *fakeTaxRepo) SetOutputBooked(ctx context.Context, id string, amount money.Amount) error {
+	return nil
+}
 func (f *fakeTaxRepo) MarkPaid(ctx context.Context, id string, paidAt time.Time) error {
 	if f.paid == nil {
 		f.paid = make(map[string]time.Time)
@@ -59,6 +70,9 @@ func (f *fakeJournalRepoT) InsertJournalLines(ctx context.Context, lines []model
 func (f *fakeJournalRepoT) ListJournalEntries(ctx context.Context, from, to, desc string) ([]models.JournalEntry, error) {
 	return nil, nil
 }
+func (f *fakeJournalRepoT) ListJournalEntriesFiltered(ctx context.Context, params *models.FilterParams) (*models.QueryResult, error) {
+	return &models.QueryResult{}, nil
+}
 func (f *fakeJournalRepoT) GetJournalEntry(ctx context.Context, id int64) (*models.JournalEntry, error) {
 	return nil, nil
 }
@@ -71,12 +85,12 @@ func (f *fakeJournalRepoT) ListEntriesBySourceID(ctx context.Context, sourceID s
 func (f *fakeJournalRepoT) DeleteJournalEntry(ctx context.Context, id int64) error { return nil }
 
 func TestComputeTax(t *testing.T) {
-	svc := NewTaxService(nil, &fakeTaxRepo{}, &fakeJournalRepoT{}, &fakeMetricSvc{rev: 1000})
+	svc := NewTaxService(nil, &fakeTaxRepo{}, &fakeJournalRepoT{}, &fakeMetricSvc{rev: money.New(1000)})
 	tp, err := svc.ComputeTax(context.Background(), "Store", "monthly", "2025-06")
 	if err != nil {
 		t.Fatalf("err %v", err)
 	}
-	if tp.TaxAmount != 5 {
+	if tp.TaxAmount != money.New(5) {
 		t.Errorf("expected 5 got %v", tp.TaxAmount)
 	}
 }
@@ -85,7 +99,7 @@ func TestPayTax(t *testing.T) {
 	repo := &fakeTaxRepo{}
 	jr := &fakeJournalRepoT{}
 	svc := NewTaxService(nil, repo, jr, &fakeMetricSvc{})
-	tp := &models.TaxPayment{ID: "1", TaxAmount: 5, Store: "S"}
+	tp := &models.TaxPayment{ID: "1", TaxAmount: money.New(5), Store: "S"}
 	if err := svc.PayTax(context.Background(), tp); err != nil {
 		t.Fatalf("err %v", err)
 	}
@@ -96,3 +110,331 @@ func TestPayTax(t *testing.T) {
 		t.Errorf("journal not created")
 	}
 }
+
+type fakeRevenue map[string]money.Amount
+
+func (f fakeRevenue) GetRevenue(ctx context.Context, store, pt, pv string) (money.Amount, error) {
+	return f[pv], nil
+}
+
+type fakeTaxStore struct {
+	fakeTaxRepo
+	regimes  []models.TaxRegime
+	profit   money.Amount
+	profits  map[string]money.Amount // by month, overriding profit
+	input    map[string]money.Amount // input VAT by month
+	rows     map[string]*models.TaxPayment
+	due      []models.TaxPayment
+	reminded []string
+}
+
+func (f *fakeTaxStore) GetObligation(ctx context.Context, store, pt, pv, taxType string) (*models.TaxPayment, error) {
+	tp, ok := f.rows[store+pv+taxType]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	cp := *tp
+	return &cp, nil
+}
+func (f *fakeTaxStore) GetByID(ctx context.Context, id string) (*models.TaxPayment, error) {
+	for _, tp := range f.rows {
+		if tp.ID == id {
+			cp := *tp
+			return &cp, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+func (f *fakeTaxStore) Create(ctx context.Context, tp *models.TaxPayment) error {
+	if f.rows == nil {
+		f.rows = map[string]*models.TaxPayment{}
+	}
+	tp.ID = strconv.Itoa(len(f.rows) + 1)
+	cp := *tp
+	f.rows[tp.Store+tp.PeriodValue+tp.TaxType] = &cp
+	return nil
+}
+func (f *fakeTaxStore) UpdateUnpaid(ctx context.Context, tp *models.TaxPayment) error {
+	cp := *tp
+	cp.OutputBooked = f.rows[tp.Store+tp.PeriodValue+tp.TaxType].OutputBooked
+	f.rows[tp.Store+tp.PeriodValue+tp.TaxType] = &cp
+	return nil
+}
+func (f *fakeTaxStore) SetOutputBooked(ctx context.Context, id string, amount money.Amount) error {
+	for _, tp := range f.rows {
+		if tp.ID == id {
+			tp.OutputBooked = amount
+		}
+	}
+	return nil
+}
+func (f *fakeTaxStore) MarkPaid(ctx context.Context, id string, paidAt time.Time) error {
+	for _, tp := range f.rows {
+		if tp.ID == id {
+			tp.IsPaid = true
+			tp.PaidAt = &paidAt
+		}
+	}
+	return nil
+}
+func (f *fakeTaxStore) ListPayments(ctx context.Context, store, prefix string, unpaid bool) ([]models.TaxPayment, error) {
+	return nil, nil
+}
+func (f *fakeTaxStore) ListReminders(ctx context.Context, until, now time.Time) ([]models.TaxPayment, error) {
+	return f.due, nil
+}
+func (f *fakeTaxStore) MarkReminded(ctx context.Context, ids []string, at time.Time) error {
+	f.reminded = append(f.reminded, ids...)
+	return nil
+}
+func (f *fakeTaxStore) CreateRegime(ctx context.Context, g *models.TaxRegime) error { return nil }
+func (f *fakeTaxStore) UpdateRegime(ctx context.Context, g *models.TaxRegime) error { return nil }
+func (f *fakeTaxStore) DeleteRegime(ctx context.Context, id int64) error            { return nil }
+func (f *fakeTaxStore) ListRegimes(ctx context.Context, store string) ([]models.TaxRegime, error) {
+	return f.regimes, nil
+}
+func (f *fakeTaxStore) ListTaxStores(ctx context.Context) ([]string, error) {
+	return []string{"S"}, nil
+}
+func (f *fakeTaxStore) NetProfit(ctx context.Context, store string, from, to time.Time) (money.Amount, error) {
+	if p, ok := f.profits[from.Format("2006-01")]; ok {
+		return p, nil
+	}
+	return f.profit, nil
+}
+
+func (f *fakeTaxStore) InputVAT(ctx context.Context, store string, from, to time.Time) (money.Amount, error) {
+	return f.input[from.Format("2006-01")], nil
+}
+
+func newTaxTestService(rev fakeRevenue, st *fakeTaxStore) *TaxService {
+	svc := NewTaxService(nil, st, &fakeJournalRepoT{}, rev)
+	svc.SetStore(st)
+	return svc
+}
+
+func TestComputeTaxIndividualThreshold(t *testing.T) {
+	st := &fakeTaxStore{regimes: []models.TaxRegime{{
+		Store: "S", Regime: models.TaxRegimeUMKM, TaxpayerType: models.TaxpayerIndividual,
+		EffectiveFrom: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	}}}
+	rev := fakeRevenue{"2025-01": money.New(300_000_000), "2025-02": money.New(300_000_000)}
+	svc := newTaxTestService(rev, st)
+
+	jan, err := svc.ComputeTax(context.Background(), "S", "monthly", "2025-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jan.TaxAmount != 0 || jan.ExemptAmount != money.New(300_000_000) {
+		t.Errorf("january should be exempt, got %+v", jan)
+	}
+	feb, err := svc.ComputeTax(context.Background(), "S", "monthly", "2025-02")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 200M of February is under the threshold, 0.5% of the other 100M.
+	if feb.ExemptAmount != money.New(200_000_000) || feb.TaxAmount != money.New(500_000) {
+		t.Errorf("unexpected february %+v", feb)
+	}
+	if feb.CumulativeRevenue != money.New(600_000_000) {
+		t.Errorf("cumulative %v", feb.CumulativeRevenue)
+	}
+	if want := time.Date(2025, 3, 17, 0, 0, 0, 0, time.UTC); !feb.DueDate.Equal(want) {
+		t.Errorf("due %v, want the Monday after 15 March", feb.DueDate)
+	}
+}
+
+func TestComputeObligationsPKPAndLapsedUMKM(t *testing.T) {
+	st := &fakeTaxStore{
+		// The ledger's revenue still includes the 11M of output VAT.
+		profit: money.New(21_000_000),
+		regimes: []models.TaxRegime{{
+			Store: "S", Regime: models.TaxRegimeUMKM, TaxpayerType: models.TaxpayerCorporate, PKP: true,
+			EffectiveFrom: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		}},
+	}
+	rev := fakeRevenue{"2024": money.New(5_000_000_000), "2025-01": money.New(111_000_000)}
+	svc := newTaxTestService(rev, st)
+
+	list, err := svc.ComputeObligations(context.Background(), "S", "2025-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("expected income tax and PPN, got %+v", list)
+	}
+	if list[0].TaxType != models.TaxPPh25 || list[0].TaxAmount != money.New(2_200_000) || list[0].Note == "" {
+		t.Errorf("unexpected income tax %+v", list[0])
+	}
+	if list[1].TaxType != models.TaxPPN || list[1].TaxAmount != money.New(11_000_000) {
+		t.Errorf("unexpected PPN %+v", list[1])
+	}
+}
+
+func TestComputeTaxCorporateOffsetsEarlierLosses(t *testing.T) {
+	st := &fakeTaxStore{
+		profits: map[string]money.Amount{"2025-01": money.New(-10_000_000), "2025-02": money.New(15_000_000)},
+		regimes: []models.TaxRegime{{
+			Store: "S", Regime: models.TaxRegimeNormal, TaxpayerType: models.TaxpayerCorporate,
+			EffectiveFrom: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		}},
+	}
+	svc := newTaxTestService(fakeRevenue{}, st)
+
+	jan, err := svc.ComputeTax(context.Background(), "S", "monthly", "2025-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jan.TaxAmount != 0 {
+		t.Errorf("a loss month owes nothing, got %v", jan.TaxAmount)
+	}
+	feb, err := svc.ComputeTax(context.Background(), "S", "monthly", "2025-02")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Only the 5M of profit left after January's loss is taxed.
+	if feb.TaxAmount != money.New(1_100_000) {
+		t.Errorf("february tax %v, want 22%% of 5M", feb.TaxAmount)
+	}
+}
+
+func TestRecordObligationsKeepsPaidRows(t *testing.T) {
+	st := &fakeTaxStore{}
+	svc := newTaxTestService(fakeRevenue{"2025-01": money.New(1000)}, st)
+	ctx := context.Background()
+
+	first, err := svc.RecordObligations(ctx, "S", "2025-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.PayObligation(ctx, first[0].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	svc.metricSvc = fakeRevenue{"2025-01": money.New(2000)}
+	again, err := svc.RecordObligations(ctx, "S", "2025-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(st.rows) != 1 || again[0].TaxAmount != money.New(5) {
+		t.Errorf("paid obligation changed: %+v", again)
+	}
+	if _, err := svc.PayObligation(ctx, first[0].ID); !errors.Is(err, ErrTaxAlreadyPaid) {
+		t.Errorf("expected ErrTaxAlreadyPaid, got %v", err)
+	}
+}
+
+func TestComputeObligationsCarriesInputVAT(t *testing.T) {
+	st := &fakeTaxStore{
+		input: map[string]money.Amount{"2025-01": money.New(15_000_000)},
+		regimes: []models.TaxRegime{{
+			Store: "S", Regime: models.TaxRegimeUMKM, TaxpayerType: models.TaxpayerCorporate, PKP: true,
+			EffectiveFrom: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		}},
+	}
+	rev := fakeRevenue{"2025-01": money.New(111_000_000), "2025-02": money.New(11_100_000)}
+	svc := newTaxTestService(rev, st)
+
+	jan, err := svc.ComputeObligations(context.Background(), "S", "2025-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ppn := jan[1]; ppn.OutputTax != money.New(11_000_000) || ppn.InputTax != money.New(11_000_000) || ppn.TaxAmount != 0 {
+		t.Errorf("january input VAT should cover the output VAT: %+v", ppn)
+	}
+	feb, err := svc.ComputeObligations(context.Background(), "S", "2025-02")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 4M of January's input VAT is left over for February.
+	if ppn := feb[1]; ppn.OutputTax != money.New(1_100_000) || ppn.InputTax != money.New(1_100_000) || ppn.TaxAmount != 0 {
+		t.Errorf("february should use the carried input VAT: %+v", ppn)
+	}
+}
+
+func TestPPNBookedWhenRecordedAndClearedWhenPaid(t *testing.T) {
+	st := &fakeTaxStore{
+		input: map[string]money.Amount{"2025-01": money.New(4_000_000)},
+		regimes: []models.TaxRegime{{
+			Store: "S", Regime: models.TaxRegimeUMKM, TaxpayerType: models.TaxpayerCorporate, PKP: true,
+			EffectiveFrom: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		}},
+	}
+	svc := newTaxTestService(fakeRevenue{"2025-01": money.New(111_000_000)}, st)
+	jr := svc.journalRepo.(*fakeJournalRepoT)
+	ctx := context.Background()
+
+	list, err := svc.RecordObligations(ctx, "S", "2025-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ppn := list[1]; ppn.TaxAmount != money.New(7_000_000) {
+		t.Fatalf("unexpected PPN %+v", ppn)
+	}
+	if len(jr.entries) != 1 || !jr.entries[0].EntryDate.Equal(time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("output VAT should be booked at the end of the sale month: %+v", jr.entries)
+	}
+
+	// A lower refresh books the difference back; an unchanged one books
+	// nothing.
+	svc.metricSvc = fakeRevenue{"2025-01": money.New(55_500_000)}
+	if list, err = svc.RecordObligations(ctx, "S", "2025-01"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.RecordObligations(ctx, "S", "2025-01"); err != nil {
+		t.Fatal(err)
+	}
+	if len(jr.entries) != 2 {
+		t.Fatalf("expected one adjustment, got %d journals", len(jr.entries))
+	}
+
+	if _, err := svc.PayObligation(ctx, list[1].ID); err != nil {
+		t.Fatal(err)
+	}
+	bal := map[int64]money.Amount{}
+	for _, l := range jr.lines {
+		if l.IsDebit {
+			bal[l.AccountID] += l.Amount
+		} else {
+			bal[l.AccountID] -= l.Amount
+		}
+	}
+	want := map[int64]money.Amount{
+		ppnOutputAcctID:  money.New(5_500_000),
+		ppnPayableAcctID: 0,
+		ppnInputAcctID:   -money.New(4_000_000),
+		bankAcctID:       -money.New(1_500_000),
+	}
+	for acc, amt := range want {
+		if bal[acc] != amt {
+			t.Errorf("account %d = %s, want %s", acc, bal[acc], amt)
+		}
+	}
+}
+
+type fakeReportSink struct{ files []ReportFile }
+
+func (f *fakeReportSink) Deliver(ctx context.Context, target string, rf ReportFile) error {
+	f.files = append(f.files, rf)
+	return nil
+}
+
+func TestSendTaxReminders(t *testing.T) {
+	due := time.Date(2025, 2, 17, 0, 0, 0, 0, time.UTC)
+	st := &fakeTaxStore{due: []models.TaxPayment{{ID: "9", Store: "S", TaxType: models.TaxPPhFinal, PeriodValue: "2025-01", TaxAmount: money.New(5), DueDate: &due}}}
+	svc := newTaxTestService(fakeRevenue{}, st)
+	sink := &fakeReportSink{}
+	svc.SetReminders(sink, "finance@example.com", 7)
+	svc.now = func() time.Time { return time.Date(2025, 2, 12, 8, 0, 0, 0, time.UTC) }
+
+	n, err := svc.SendReminders(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 || len(sink.files) != 1 || len(st.reminded) != 1 || st.reminded[0] != "9" {
+		t.Fatalf("reminder not sent: n=%d files=%d reminded=%v", n, len(sink.files), st.reminded)
+	}
+	if !strings.Contains(string(sink.files[0].Data), "2025-02-17") {
+		t.Errorf("reminder body %q", sink.files[0].Data)
+	}
+}
@@ -2,11 +2,12 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
-	"strings"
 	"time"
 
+	"github.com/ramadhan22/dropship-erp/backend/internal/apperr"
+	"github.com/ramadhan22/dropship-erp/backend/internal/logutil"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
 	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
 )
@@ -71,13 +72,13 @@ func (s *WalletTransactionService) ListWalletTransactions(ctx context.Context, s
 		return nil, false, fmt.Errorf("fetch store %s: %w", store, err)
 	}
 	if st.AccessToken == nil || st.ShopID == nil {
-		return nil, false, fmt.Errorf("missing access token or shop id")
+		return nil, false, fmt.Errorf("%w: access token or shop id", apperr.ErrMissingCredentials)
 	}
 	if err := ensureTokenValid(ctx, st, s.client, s.storeRepo); err != nil {
 		return nil, false, err
 	}
 	resp, err := s.client.GetWalletTransactionList(ctx, *st.AccessToken, *st.ShopID, p)
-	if err != nil && strings.Contains(err.Error(), "invalid_access_token") {
+	if errors.Is(err, apperr.ErrTokenExpired) {
 		if err2 := ensureTokenValid(ctx, st, s.client, s.storeRepo); err2 == nil {
 			resp, err = s.client.GetWalletTransactionList(ctx, *st.AccessToken, *st.ShopID, p)
 		}
@@ -85,8 +86,11 @@ func (s *WalletTransactionService) ListWalletTransactions(ctx context.Context, s
 	if err != nil {
 		return nil, false, err
 	}
-	log.Printf("Fetched %d wallet transactions for store %s", len(resp.Transactions), store)
-	log.Printf("More transactions available: %t", resp.more)
+	logutil.Debug(ctx, "ListWalletTransactions", "Fetched wallet transactions", map[string]interface{}{
+		"store": store,
+		"count": len(resp.Transactions),
+		"more":  resp.more,
+	})
 	return resp.Transactions, resp.more, nil
 }
 
@@ -103,10 +107,10 @@ func ensureTokenValid(ctx context.Context, st *models.Store, c *ShopeeClient, re
 	)
 	exp := reinterpreted.Add(time.Duration(*st.ExpireIn) * time.Second)
 	if st.RefreshToken == nil {
-		return fmt.Errorf("missing refresh token")
+		return fmt.Errorf("%w: refresh token", apperr.ErrMissingCredentials)
 	}
 	if st.ShopID == nil || *st.ShopID == "" {
-		return fmt.Errorf("missing shop id")
+		return fmt.Errorf("%w: shop id", apperr.ErrMissingCredentials)
 	}
 	if st.ExpireIn != nil && st.LastUpdated != nil {
 		if time.Now().Before(exp.Local()) {
@@ -3,10 +3,11 @@ package service
 import (
 	"context"
 	"fmt"
-	"log"
 	"time"
 
+	"github.com/ramadhan22/dropship-erp/backend/internal/logutil"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
 )
 
@@ -15,6 +16,7 @@ import (
 type WalletWithdrawalService struct {
 	walletSvc   *WalletTransactionService
 	journalRepo *repository.JournalRepo
+	cache       Cache
 }
 
 // NewWalletWithdrawalService creates a new WalletWithdrawalService.
@@ -22,6 +24,10 @@ func NewWalletWithdrawalService(w *WalletTransactionService, jr *repository.Jour
 	return &WalletWithdrawalService{walletSvc: w, journalRepo: jr}
 }
 
+// SetCache enables invalidation of cached reports after withdrawal journals
+// are posted.
+func (s *WalletWithdrawalService) SetCache(c Cache) { s.cache = c }
+
 // List returns withdrawal transactions for the given store.
 func (s *WalletWithdrawalService) List(ctx context.Context, store string, p WalletTransactionParams) ([]WalletTransaction, bool, error) {
 	if s.walletSvc == nil {
@@ -41,7 +47,11 @@ func (s *WalletWithdrawalService) List(ctx context.Context, store string, p Wall
 		disburseAddAmount, err := s.findSpmDisburseAddAmount(ctx, store, txs[i].CreateTime)
 		if err != nil {
 			// Log the error but don't fail the entire request
-			log.Printf("Warning: failed to check SPM_DISBURSE_ADD for transaction %d: %v", txs[i].TransactionID, err)
+			logutil.Warn(ctx, "FindSpmDisburseAdd", "Failed to check SPM_DISBURSE_ADD, using the withdrawal amount as is", map[string]interface{}{
+				"store":          store,
+				"transaction_id": txs[i].TransactionID,
+				"error":          err,
+			})
 		} else if disburseAddAmount > 0 {
 			txs[i].Amount = txs[i].Amount + disburseAddAmount
 		}
@@ -115,12 +125,22 @@ func findSpmDisburseAddAmountWithService(ctx context.Context, walletSvc WalletTr
 
 // CreateJournal posts a journal entry for the given transaction.
 func (s *WalletWithdrawalService) CreateJournal(ctx context.Context, store string, t WalletTransaction) error {
+	posted, err := s.createJournal(ctx, store, t)
+	if posted {
+		invalidateCache(ctx, s.cache, journalWriteTags...)
+	}
+	return err
+}
+
+// createJournal posts the journal of t unless it was posted before and
+// reports whether it did.
+func (s *WalletWithdrawalService) createJournal(ctx context.Context, store string, t WalletTransaction) (bool, error) {
 	if s.journalRepo == nil {
-		return fmt.Errorf("journal repo nil")
+		return false, fmt.Errorf("journal repo nil")
 	}
 	sid := fmt.Sprintf("%d", t.TransactionID)
 	if je, _ := s.journalRepo.GetJournalEntryBySource(ctx, "wallet_withdrawal", sid); je != nil {
-		return nil
+		return false, nil
 	}
 
 	// Create description that reflects whether amount was adjusted
@@ -137,17 +157,18 @@ func (s *WalletWithdrawalService) CreateJournal(ctx context.Context, store strin
 	}
 	jid, err := s.journalRepo.CreateJournalEntry(ctx, je)
 	if err != nil {
-		return err
+		return false, err
 	}
+	amt := money.FromFloat(-t.Amount)
 	lines := []models.JournalLine{
-		{JournalID: jid, AccountID: 11014, IsDebit: true, Amount: -t.Amount},
-		{JournalID: jid, AccountID: saldoShopeeAccountID(store), IsDebit: false, Amount: -t.Amount},
+		{JournalID: jid, AccountID: 11014, IsDebit: true, Amount: amt},
+		{JournalID: jid, AccountID: saldoShopeeAccountID(store), IsDebit: false, Amount: amt},
 	}
 	// Use bulk insert for lines
 	if err := s.journalRepo.InsertJournalLines(ctx, lines); err != nil {
-		return err
+		return true, err
 	}
-	return nil
+	return true, nil
 }
 
 // CreateAllJournal fetches withdrawal transactions backwards in time and posts
@@ -156,6 +177,12 @@ func (s *WalletWithdrawalService) CreateAllJournal(ctx context.Context, store st
 	if s.walletSvc == nil {
 		return fmt.Errorf("wallet service nil")
 	}
+	var posted bool
+	defer func() {
+		if posted {
+			invalidateCache(ctx, s.cache, journalWriteTags...)
+		}
+	}()
 	var empty int
 	to := time.Now()
 	for empty < 2 {
@@ -178,7 +205,11 @@ func (s *WalletWithdrawalService) CreateAllJournal(ctx context.Context, store st
 				disburseAddAmount, err := s.findSpmDisburseAddAmount(ctx, store, txs[i].CreateTime)
 				if err != nil {
 					// Log the error but don't fail the entire request
-					log.Printf("Warning: failed to check SPM_DISBURSE_ADD for transaction %d: %v", txs[i].TransactionID, err)
+					logutil.Warn(ctx, "FindSpmDisburseAdd", "Failed to check SPM_DISBURSE_ADD, using the withdrawal amount as is", map[string]interface{}{
+						"store":          store,
+						"transaction_id": txs[i].TransactionID,
+						"error":          err,
+					})
 				} else if disburseAddAmount > 0 {
 					txs[i].Amount = txs[i].Amount + disburseAddAmount
 				}
@@ -187,7 +218,9 @@ func (s *WalletWithdrawalService) CreateAllJournal(ctx context.Context, store st
 				return err
 			}
 			for _, tx := range txs {
-				if err := s.CreateJournal(ctx, store, tx); err != nil {
+				ok, err := s.createJournal(ctx, store, tx)
+				posted = posted || ok
+				if err != nil {
 					return err
 				}
 			}
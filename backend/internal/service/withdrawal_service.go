@@ -10,6 +10,7 @@ import (
 
 	"github.com/jmoiron/sqlx"
 	"github.com/ramadhan22/dropship-erp/backend/internal/models"
+	"github.com/ramadhan22/dropship-erp/backend/internal/money"
 	"github.com/ramadhan22/dropship-erp/backend/internal/repository"
 	"github.com/xuri/excelize/v2"
 )
@@ -19,17 +20,31 @@ type WithdrawalService struct {
 	db          *sqlx.DB
 	repo        *repository.WithdrawalRepo
 	journalRepo *repository.JournalRepo
+	cache       Cache
 }
 
 func NewWithdrawalService(db *sqlx.DB, r *repository.WithdrawalRepo, jr *repository.JournalRepo) *WithdrawalService {
 	return &WithdrawalService{db: db, repo: r, journalRepo: jr}
 }
 
+// SetCache enables invalidation of cached reports after withdrawal journals
+// are posted.
+func (s *WithdrawalService) SetCache(c Cache) { s.cache = c }
+
 func (s *WithdrawalService) List(ctx context.Context) ([]models.Withdrawal, error) {
 	return s.repo.List(ctx, "date", "desc")
 }
 
 func (s *WithdrawalService) Create(ctx context.Context, w *models.Withdrawal) error {
+	if err := s.create(ctx, w); err != nil {
+		return err
+	}
+	invalidateCache(ctx, s.cache, journalWriteTags...)
+	return nil
+}
+
+// create stores w and posts its journal in one transaction.
+func (s *WithdrawalService) create(ctx context.Context, w *models.Withdrawal) error {
 	var tx *sqlx.Tx
 	repo := s.repo
 	jr := s.journalRepo
@@ -74,9 +89,10 @@ func createWithdrawalJournal(ctx context.Context, jr *repository.JournalRepo, w
 	if err != nil {
 		return err
 	}
+	amt := money.FromFloat(w.Amount)
 	lines := []models.JournalLine{
-		{JournalID: jid, AccountID: 11014, IsDebit: true, Amount: w.Amount},
-		{JournalID: jid, AccountID: saldoShopeeAccountID(w.Store), IsDebit: false, Amount: w.Amount},
+		{JournalID: jid, AccountID: 11014, IsDebit: true, Amount: amt},
+		{JournalID: jid, AccountID: saldoShopeeAccountID(w.Store), IsDebit: false, Amount: amt},
 	}
 	// Use bulk insert for lines
 	if err := jr.InsertJournalLines(ctx, lines); err != nil {
@@ -99,6 +115,11 @@ func (s *WithdrawalService) ImportXLSX(ctx context.Context, r io.Reader) (int, e
 	}
 	headerRow := 17
 	inserted := 0
+	defer func() {
+		if inserted > 0 {
+			invalidateCache(ctx, s.cache, journalWriteTags...)
+		}
+	}()
 	for i := headerRow; i < len(rows); i++ {
 		row := rows[i]
 		if len(row) < 6 || row[0] == "Tanggal Transaksi" {
@@ -124,7 +145,7 @@ func (s *WithdrawalService) ImportXLSX(ctx context.Context, r io.Reader) (int, e
 				}
 			}
 		}
-		if err := s.Create(ctx, w); err != nil {
+		if err := s.create(ctx, w); err != nil {
 			return inserted, err
 		}
 		inserted++
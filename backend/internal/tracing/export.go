@@ -0,0 +1,343 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Exporter sends finished spans somewhere.
+type Exporter interface {
+	Export(ctx context.Context, spans []*Span) error
+}
+
+// Config controls span export. Endpoint is the base URL of an OTLP/HTTP
+// receiver such as a local collector, e.g. http://localhost:4318; spans are
+// posted to Endpoint + "/v1/traces".
+type Config struct {
+	Endpoint    string
+	ServiceName string
+	// SampleRatio is the share of new traces that are exported, from 0 to 1.
+	// Traces continued from a traceparent header follow the caller's choice.
+	SampleRatio   float64
+	BatchSize     int
+	FlushInterval time.Duration
+	Timeout       time.Duration
+	// Exporter replaces the OTLP exporter, mainly for tests.
+	Exporter Exporter
+	// OnExportError is called when a batch of spans cannot be exported.
+	// The package cannot import logutil, so the caller supplies the logger.
+	OnExportError func(spans int, err error)
+}
+
+// provider batches finished spans for one exporter.
+type provider struct {
+	ratio     float64
+	exp       Exporter
+	batchSize int
+	interval  time.Duration
+	timeout   time.Duration
+	onError   func(spans int, err error)
+
+	mu     sync.RWMutex
+	closed bool
+	queue  chan *Span
+	done   chan struct{}
+}
+
+var (
+	activeMu sync.RWMutex
+	// active starts without an exporter: spans get IDs but are dropped.
+	active = &provider{ratio: 1}
+)
+
+func current() *provider {
+	activeMu.RLock()
+	defer activeMu.RUnlock()
+	return active
+}
+
+func (p *provider) sample(t TraceID) bool {
+	return sampledByRatio(t, p.ratio)
+}
+
+func (p *provider) enqueue(s *Span) {
+	if p.queue == nil {
+		return
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return
+	}
+	select {
+	case p.queue <- s:
+	default:
+		// The exporter is behind; dropping spans beats blocking requests.
+	}
+}
+
+func (p *provider) run() {
+	defer close(p.done)
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	batch := make([]*Span, 0, p.batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+		if err := p.exp.Export(ctx, batch); err != nil {
+			if p.onError != nil {
+				p.onError(len(batch), err)
+			}
+		}
+		cancel()
+		batch = make([]*Span, 0, p.batchSize)
+	}
+	for {
+		select {
+		case s, ok := <-p.queue:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, s)
+			if len(batch) >= p.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// Configure starts exporting sampled spans in the background and returns a
+// func that flushes what is queued and stops exporting. When cfg has
+// neither an Endpoint nor an Exporter, spans are only used for log
+// correlation.
+func Configure(cfg Config) func(ctx context.Context) error {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 512
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = 5 * time.Second
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.ServiceName == "" {
+		cfg.ServiceName = "dropship-erp"
+	}
+	exp := cfg.Exporter
+	if exp == nil && cfg.Endpoint != "" {
+		exp = NewOTLPExporter(cfg.Endpoint, cfg.ServiceName)
+	}
+	p := &provider{ratio: cfg.SampleRatio}
+	if exp != nil {
+		p.exp = exp
+		p.batchSize = cfg.BatchSize
+		p.interval = cfg.FlushInterval
+		p.timeout = cfg.Timeout
+		p.onError = cfg.OnExportError
+		p.queue = make(chan *Span, 4*cfg.BatchSize)
+		p.done = make(chan struct{})
+		go p.run()
+	}
+
+	activeMu.Lock()
+	active = p
+	activeMu.Unlock()
+
+	return func(ctx context.Context) error {
+		activeMu.Lock()
+		if active == p {
+			active = &provider{ratio: p.ratio}
+		}
+		activeMu.Unlock()
+		if p.queue == nil {
+			return nil
+		}
+		p.mu.Lock()
+		if !p.closed {
+			p.closed = true
+			close(p.queue)
+		}
+		p.mu.Unlock()
+		select {
+		case <-p.done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// OTLPExporter posts spans to an OTLP/HTTP receiver using the JSON
+// encoding of ExportTraceServiceRequest.
+type OTLPExporter struct {
+	url     string
+	service string
+	client  *http.Client
+}
+
+// NewOTLPExporter returns an exporter for the receiver at endpoint.
+func NewOTLPExporter(endpoint, service string) *OTLPExporter {
+	return &OTLPExporter{
+		url:     strings.TrimRight(endpoint, "/") + "/v1/traces",
+		service: service,
+		client:  &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// Export implements Exporter.
+func (e *OTLPExporter) Export(ctx context.Context, spans []*Span) error {
+	body, err := json.Marshal(encodeRequest(e.service, spans))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("collector returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// OTLP JSON shapes. IDs are hex and 64-bit integers are strings, as the
+// OTLP/JSON mapping requires.
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              SpanKind       `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+func encodeRequest(service string, spans []*Span) otlpRequest {
+	out := make([]otlpSpan, 0, len(spans))
+	for _, s := range spans {
+		s.mu.Lock()
+		o := otlpSpan{
+			TraceID:           s.sc.TraceID.String(),
+			SpanID:            s.sc.SpanID.String(),
+			Name:              s.name,
+			Kind:              s.kind,
+			StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+			Attributes:        encodeAttrs(s.attrs),
+			Status:            otlpStatus{Code: s.statusCode, Message: s.statusMsg},
+		}
+		if s.parent.IsValid() {
+			o.ParentSpanID = s.parent.String()
+		}
+		s.mu.Unlock()
+		out = append(out, o)
+	}
+	return otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource: otlpResource{Attributes: encodeAttrs(map[string]interface{}{"service.name": service})},
+		ScopeSpans: []otlpScopeSpans{{
+			Scope: otlpScope{Name: "github.com/ramadhan22/dropship-erp/backend/internal/tracing"},
+			Spans: out,
+		}},
+	}}}
+}
+
+func encodeAttrs(attrs map[string]interface{}) []otlpKeyValue {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	kvs := make([]otlpKeyValue, 0, len(keys))
+	for _, k := range keys {
+		kvs = append(kvs, otlpKeyValue{Key: k, Value: encodeValue(attrs[k])})
+	}
+	return kvs
+}
+
+func encodeValue(v interface{}) otlpValue {
+	intValue := func(i int64) otlpValue {
+		s := strconv.FormatInt(i, 10)
+		return otlpValue{IntValue: &s}
+	}
+	switch x := v.(type) {
+	case string:
+		return otlpValue{StringValue: &x}
+	case bool:
+		return otlpValue{BoolValue: &x}
+	case int:
+		return intValue(int64(x))
+	case int32:
+		return intValue(int64(x))
+	case int64:
+		return intValue(x)
+	case float64:
+		return otlpValue{DoubleValue: &x}
+	case time.Duration:
+		return intValue(int64(x))
+	default:
+		s := fmt.Sprint(v)
+		return otlpValue{StringValue: &s}
+	}
+}
@@ -0,0 +1,131 @@
+package tracing
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+)
+
+// maxStatementLength caps the db.statement attribute so bulk inserts don't
+// bloat spans.
+const maxStatementLength = 2000
+
+// WrapConnector returns a connector whose connections record a client span
+// for every query and exec made while the context carries a span. Open the
+// pool with sql.OpenDB and wrap it with sqlx.NewDb(db, "postgres") so sqlx
+// keeps using $n bind variables.
+func WrapConnector(c driver.Connector) driver.Connector {
+	return &tracedConnector{next: c}
+}
+
+type tracedConnector struct {
+	next driver.Connector
+}
+
+func (c *tracedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.next.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &tracedConn{Conn: conn}, nil
+}
+
+func (c *tracedConnector) Driver() driver.Driver { return c.next.Driver() }
+
+// tracedConn forwards to the driver's connection. Optional interfaces the
+// driver lacks fall back to what database/sql would do without them.
+type tracedConn struct {
+	driver.Conn
+}
+
+func (c *tracedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	q, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	ctx, span := startQuery(ctx, "query", query)
+	rows, err := q.QueryContext(ctx, query, args)
+	endQuery(span, err)
+	return rows, err
+}
+
+func (c *tracedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	e, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	ctx, span := startQuery(ctx, "exec", query)
+	res, err := e.ExecContext(ctx, query, args)
+	if span != nil && err == nil {
+		if n, rerr := res.RowsAffected(); rerr == nil {
+			span.SetAttr("db.rows_affected", n)
+		}
+	}
+	endQuery(span, err)
+	return res, err
+}
+
+func (c *tracedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return p.PrepareContext(ctx, query)
+	}
+	return c.Conn.Prepare(query)
+}
+
+func (c *tracedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c *tracedConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *tracedConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *tracedConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+func startQuery(ctx context.Context, op, query string) (context.Context, *Span) {
+	ctx, span := StartChild(ctx, "db."+op+" "+statementVerb(query), KindClient)
+	if span != nil {
+		stmt := strings.TrimSpace(query)
+		if len(stmt) > maxStatementLength {
+			stmt = stmt[:maxStatementLength] + "..."
+		}
+		span.SetAttr("db.system", "postgresql")
+		span.SetAttr("db.statement", stmt)
+	}
+	return ctx, span
+}
+
+func endQuery(span *Span, err error) {
+	if err != nil && err != driver.ErrSkip {
+		span.RecordError(err)
+	}
+	span.End()
+}
+
+// statementVerb returns the first keyword of query, e.g. SELECT, for the
+// span name.
+func statementVerb(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToUpper(fields[0])
+}
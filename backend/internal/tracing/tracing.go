@@ -0,0 +1,282 @@
+// Package tracing records spans for HTTP requests, database queries,
+// Shopee API calls and batch jobs and exports them to an OpenTelemetry
+// collector over OTLP/HTTP (JSON encoding).
+//
+// A span is started with Start and must be ended with End. Spans started
+// from a context that already carries a span become its children, so a
+// reconcile batch shows up as one trace with every query and Shopee call it
+// made beneath it. Incoming W3C traceparent headers continue a caller's
+// trace.
+//
+// Until Configure installs an exporter, spans still get trace IDs, which
+// the structured logger prints, but nothing is sent anywhere.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TraceID identifies a trace.
+type TraceID [16]byte
+
+// SpanID identifies a span within a trace.
+type SpanID [8]byte
+
+// String returns the lowercase hex form used by OTLP and traceparent.
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+
+// IsValid reports whether t is not all zeros.
+func (t TraceID) IsValid() bool { return t != TraceID{} }
+
+// String returns the lowercase hex form used by OTLP and traceparent.
+func (s SpanID) String() string { return hex.EncodeToString(s[:]) }
+
+// IsValid reports whether s is not all zeros.
+func (s SpanID) IsValid() bool { return s != SpanID{} }
+
+// SpanContext is the part of a span that crosses process boundaries.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// IsValid reports whether both IDs are set.
+func (sc SpanContext) IsValid() bool { return sc.TraceID.IsValid() && sc.SpanID.IsValid() }
+
+// Traceparent formats sc as a W3C traceparent header value.
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return fmt.Sprintf("00-%s-%s-%s", sc.TraceID, sc.SpanID, flags)
+}
+
+// ParseTraceparent reads a W3C traceparent header value.
+func ParseTraceparent(h string) (SpanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(h), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" ||
+		len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return SpanContext{}, false
+	}
+	var sc SpanContext
+	if _, err := hex.Decode(sc.TraceID[:], []byte(parts[1])); err != nil {
+		return SpanContext{}, false
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil {
+		return SpanContext{}, false
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil {
+		return SpanContext{}, false
+	}
+	sc.Sampled = flags[0]&1 == 1
+	return sc, sc.IsValid()
+}
+
+// SpanKind follows the OTLP enum.
+type SpanKind int
+
+const (
+	KindInternal SpanKind = 1
+	KindServer   SpanKind = 2
+	KindClient   SpanKind = 3
+)
+
+// Status codes follow the OTLP enum.
+const (
+	statusUnset = 0
+	statusOK    = 1
+	statusError = 2
+)
+
+// Span is one timed operation. All methods are safe on a nil *Span.
+type Span struct {
+	mu         sync.Mutex
+	name       string
+	kind       SpanKind
+	sc         SpanContext
+	parent     SpanID
+	start, end time.Time
+	attrs      map[string]interface{}
+	statusCode int
+	statusMsg  string
+	ended      bool
+}
+
+// SpanContext returns the IDs of s.
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.sc
+}
+
+// SetAttr records an attribute. Values are strings, bools, integers or
+// floats; anything else is formatted with %v.
+func (s *Span) SetAttr(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.attrs[key] = value
+	s.mu.Unlock()
+}
+
+// RecordError marks the span failed with err's message. A nil err is
+// ignored.
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	s.statusCode = statusError
+	s.statusMsg = err.Error()
+	s.mu.Unlock()
+}
+
+// SetOK marks the span successful, overriding an earlier error, e.g. after
+// a retry succeeded.
+func (s *Span) SetOK() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.statusCode = statusOK
+	s.statusMsg = ""
+	s.mu.Unlock()
+}
+
+// End finishes the span and hands it to the exporter. Calls after the first
+// are ignored.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = time.Now()
+	s.mu.Unlock()
+	if s.sc.Sampled {
+		current().enqueue(s)
+	}
+}
+
+type spanKey struct{}
+type remoteKey struct{}
+
+// ContextWithSpan returns ctx carrying s as the current span.
+func ContextWithSpan(ctx context.Context, s *Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, s)
+}
+
+// ContextWithRemote returns ctx carrying a parent received from another
+// process, e.g. through a traceparent header.
+func ContextWithRemote(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, remoteKey{}, sc)
+}
+
+// FromContext returns the current span, or nil.
+func FromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	s, _ := ctx.Value(spanKey{}).(*Span)
+	return s
+}
+
+// TraceIDFromContext returns the hex trace ID of the current span, or ""
+// when ctx carries none. The structured logger uses it to link log lines to
+// traces.
+func TraceIDFromContext(ctx context.Context) string {
+	if s := FromContext(ctx); s != nil {
+		return s.sc.TraceID.String()
+	}
+	return ""
+}
+
+// SpanIDFromContext returns the hex ID of the current span, or "".
+func SpanIDFromContext(ctx context.Context) string {
+	if s := FromContext(ctx); s != nil {
+		return s.sc.SpanID.String()
+	}
+	return ""
+}
+
+// Start begins a span named name as a child of the span in ctx, or of a
+// remote parent, or as the root of a new trace. The returned context
+// carries the new span.
+func Start(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	s := &Span{name: name, kind: kind, start: time.Now(), attrs: make(map[string]interface{})}
+	if parent := FromContext(ctx); parent != nil {
+		s.sc.TraceID = parent.sc.TraceID
+		s.sc.Sampled = parent.sc.Sampled
+		s.parent = parent.sc.SpanID
+	} else if remote, ok := ctx.Value(remoteKey{}).(SpanContext); ok && remote.IsValid() {
+		s.sc.TraceID = remote.TraceID
+		s.sc.Sampled = remote.Sampled
+		s.parent = remote.SpanID
+	} else {
+		s.sc.TraceID = newTraceID()
+		s.sc.Sampled = current().sample(s.sc.TraceID)
+	}
+	s.sc.SpanID = newSpanID()
+	return ContextWithSpan(ctx, s), s
+}
+
+// StartChild is like Start but only records a span when ctx already
+// carries one. It is meant for very frequent operations such as SQL
+// queries, which are only interesting as part of a larger trace.
+func StartChild(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	if FromContext(ctx) == nil {
+		return ctx, nil
+	}
+	return Start(ctx, name, kind)
+}
+
+func newTraceID() TraceID {
+	var t TraceID
+	for !t.IsValid() {
+		_, _ = rand.Read(t[:])
+	}
+	return t
+}
+
+func newSpanID() SpanID {
+	var s SpanID
+	for !s.IsValid() {
+		_, _ = rand.Read(s[:])
+	}
+	return s
+}
+
+// sampledByRatio decides deterministically from the trace ID, so every
+// process sampling at the same ratio keeps the same traces.
+func sampledByRatio(t TraceID, ratio float64) bool {
+	switch {
+	case ratio >= 1:
+		return true
+	case ratio <= 0:
+		return false
+	}
+	var v uint64
+	for _, b := range t[8:] {
+		v = v<<8 | uint64(b)
+	}
+	return float64(v>>1) < ratio*float64(math.MaxUint64>>1)
+}
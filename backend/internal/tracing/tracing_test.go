@@ -0,0 +1,184 @@
+package tracing
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingExporter struct {
+	mu    sync.Mutex
+	spans []*Span
+}
+
+func (e *recordingExporter) Export(_ context.Context, spans []*Span) error {
+	e.mu.Lock()
+	e.spans = append(e.spans, spans...)
+	e.mu.Unlock()
+	return nil
+}
+
+func (e *recordingExporter) names() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var out []string
+	for _, s := range e.spans {
+		out = append(out, s.name)
+	}
+	return out
+}
+
+func configureForTest(t *testing.T, ratio float64) (*recordingExporter, func()) {
+	t.Helper()
+	exp := &recordingExporter{}
+	shutdown := Configure(Config{Exporter: exp, SampleRatio: ratio, FlushInterval: time.Hour})
+	return exp, func() { require.NoError(t, shutdown(context.Background())) }
+}
+
+type failingExporter struct{}
+
+func (failingExporter) Export(context.Context, []*Span) error {
+	return errors.New("collector down")
+}
+
+func TestExportErrorsGoToHandler(t *testing.T) {
+	var gotSpans int
+	var gotErr error
+	shutdown := Configure(Config{
+		Exporter:      failingExporter{},
+		SampleRatio:   1,
+		FlushInterval: time.Hour,
+		OnExportError: func(spans int, err error) { gotSpans, gotErr = spans, err },
+	})
+	_, s := Start(context.Background(), "op", KindInternal)
+	s.End()
+	require.NoError(t, shutdown(context.Background()))
+
+	assert.Equal(t, 1, gotSpans)
+	assert.EqualError(t, gotErr, "collector down")
+}
+
+func TestChildSpansShareTraceAndExportOnShutdown(t *testing.T) {
+	exp, flush := configureForTest(t, 1)
+
+	ctx, root := Start(context.Background(), "batch reconcile_batch", KindInternal)
+	_, child := Start(ctx, "shopee /api/v2/payment/get_escrow_detail", KindClient)
+	child.RecordError(errors.New("timeout"))
+	child.End()
+	root.End()
+	root.End() // second End is ignored
+
+	assert.Equal(t, root.SpanContext().TraceID, child.SpanContext().TraceID)
+	assert.Equal(t, root.SpanContext().SpanID, child.parent)
+	assert.Equal(t, root.SpanContext().TraceID.String(), TraceIDFromContext(ctx))
+
+	flush()
+	assert.Equal(t, []string{"shopee /api/v2/payment/get_escrow_detail", "batch reconcile_batch"}, exp.names())
+	assert.Equal(t, statusError, child.statusCode)
+}
+
+func TestStartChildNeedsParent(t *testing.T) {
+	ctx, span := StartChild(context.Background(), "db.query SELECT", KindClient)
+	assert.Nil(t, span)
+	assert.Nil(t, FromContext(ctx))
+	span.SetAttr("ignored", 1) // nil spans are safe
+	span.End()
+}
+
+func TestSampleRatioZeroDropsNewTraces(t *testing.T) {
+	exp, flush := configureForTest(t, 0)
+	_, span := Start(context.Background(), "GET /api/journal", KindServer)
+	span.End()
+	flush()
+	assert.Empty(t, exp.names())
+	assert.True(t, span.SpanContext().TraceID.IsValid(), "unsampled spans still get IDs for log correlation")
+}
+
+func TestTraceparentRoundTrip(t *testing.T) {
+	sc, ok := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	require.True(t, ok)
+	assert.True(t, sc.Sampled)
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", sc.Traceparent())
+
+	ctx, span := Start(ContextWithRemote(context.Background(), sc), "GET /api/x", KindServer)
+	assert.Equal(t, sc.TraceID, span.SpanContext().TraceID)
+	assert.Equal(t, sc.SpanID, FromContext(ctx).parent)
+
+	for _, bad := range []string{"", "00-xyz-00f067aa0ba902b7-01", "00-00000000000000000000000000000000-00f067aa0ba902b7-01"} {
+		_, ok := ParseTraceparent(bad)
+		assert.False(t, ok, bad)
+	}
+}
+
+func TestOTLPExporterPostsJSON(t *testing.T) {
+	var body map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/traces", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		b, _ := io.ReadAll(r.Body)
+		require.NoError(t, json.Unmarshal(b, &body))
+	}))
+	defer srv.Close()
+
+	ctx, parent := Start(context.Background(), "parent", KindInternal)
+	_, s := Start(ctx, "child", KindClient)
+	s.SetAttr("http.status_code", 502)
+	s.SetAttr("shopee.endpoint", "/api/v2/order/get_order_detail")
+	s.End()
+
+	require.NoError(t, NewOTLPExporter(srv.URL+"/", "erp-test").Export(context.Background(), []*Span{s}))
+
+	rs := body["resourceSpans"].([]interface{})[0].(map[string]interface{})
+	attr := rs["resource"].(map[string]interface{})["attributes"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "service.name", attr["key"])
+	span := rs["scopeSpans"].([]interface{})[0].(map[string]interface{})["spans"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, s.SpanContext().TraceID.String(), span["traceId"])
+	assert.Equal(t, parent.SpanContext().SpanID.String(), span["parentSpanId"])
+	assert.Equal(t, float64(KindClient), span["kind"])
+	attrs := span["attributes"].([]interface{})
+	assert.Equal(t, map[string]interface{}{"key": "http.status_code", "value": map[string]interface{}{"intValue": "502"}}, attrs[0])
+}
+
+type fakeConn struct{ queries []string }
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+func (c *fakeConn) ExecContext(_ context.Context, q string, _ []driver.NamedValue) (driver.Result, error) {
+	c.queries = append(c.queries, q)
+	return driver.RowsAffected(3), nil
+}
+
+func TestTracedConnRecordsQueriesInsideTraces(t *testing.T) {
+	exp, flush := configureForTest(t, 1)
+	inner := &fakeConn{}
+	conn := &tracedConn{Conn: inner}
+
+	_, err := conn.ExecContext(context.Background(), "UPDATE batch_history SET status=$1", nil)
+	require.NoError(t, err)
+
+	ctx, root := Start(context.Background(), "batch reconcile_batch", KindInternal)
+	_, err = conn.ExecContext(ctx, "  update batch_history SET done_data=$1", nil)
+	require.NoError(t, err)
+	root.End()
+
+	_, err = conn.QueryContext(ctx, "SELECT 1", nil)
+	assert.Equal(t, driver.ErrSkip, err, "drivers without QueryerContext fall back to prepare")
+
+	flush()
+	assert.Len(t, inner.queries, 2)
+	assert.Equal(t, []string{"db.exec UPDATE", "batch reconcile_batch"}, exp.names())
+	dbSpan := exp.spans[0]
+	assert.Equal(t, int64(3), dbSpan.attrs["db.rows_affected"])
+	assert.Equal(t, "update batch_history SET done_data=$1", dbSpan.attrs["db.statement"])
+}
@@ -112,12 +112,12 @@ export default function BalanceSheetPage() {
 
     setData((bsRes as any).data);
     const net = currentResults.reduce(
-      (sum, r) => sum + r.data.labaRugiBersih.amount,
+      (sum, r) => sum + Number(r.data.labaRugiBersih.amount),
       0,
     );
     setNetProfit(net);
     const retained = prevResults.reduce(
-      (sum, r) => sum + r.data.labaRugiBersih.amount,
+      (sum, r) => sum + Number(r.data.labaRugiBersih.amount),
       0,
     );
     setRetainedEarnings(retained);
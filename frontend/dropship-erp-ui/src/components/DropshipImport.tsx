@@ -173,7 +173,7 @@ export default function DropshipImport() {
       setPage(pages);
     }
     const sum = res.data.data.reduce(
-      (acc, cur) => acc + cur.total_transaksi,
+      (acc, cur) => acc + Number(cur.total_transaksi),
       0,
     );
     setPageTotal(sum);
@@ -183,7 +183,7 @@ export default function DropshipImport() {
       from,
       to,
     });
-    setAllTotal(totalRes.data.total);
+    setAllTotal(Number(totalRes.data.total));
   }, [channel, store, from, to, order, sortKey, sortDir, page, pageSize]);
 
   useEffect(() => {
@@ -331,7 +331,7 @@ export default function DropshipImport() {
               </div>
               <div>
                 <strong>Total Transaksi:</strong>{" "}
-                {selected.total_transaksi.toLocaleString("id-ID", {
+                {Number(selected.total_transaksi).toLocaleString("id-ID", {
                   style: "currency",
                   currency: "IDR",
                 })}
@@ -355,13 +355,13 @@ export default function DropshipImport() {
                 <TableCell>{dt.nama_produk}</TableCell>
                 <TableCell>{dt.qty}</TableCell>
                 <TableCell>
-                  {dt.total_harga_produk.toLocaleString("id-ID", {
+                  {Number(dt.total_harga_produk).toLocaleString("id-ID", {
                     style: "currency",
                     currency: "IDR",
                   })}
                 </TableCell>
                 <TableCell>
-                  {dt.total_harga_produk_channel.toLocaleString("id-ID", {
+                  {Number(dt.total_harga_produk_channel).toLocaleString("id-ID", {
                     style: "currency",
                     currency: "IDR",
                   })}
@@ -374,7 +374,7 @@ export default function DropshipImport() {
               </TableCell>
               <TableCell sx={{ fontWeight: "bold" }}>
                 {details
-                  .reduce((acc, cur) => acc + cur.total_harga_produk, 0)
+                  .reduce((acc, cur) => acc + Number(cur.total_harga_produk), 0)
                   .toLocaleString("id-ID", {
                     style: "currency",
                     currency: "IDR",
@@ -382,7 +382,7 @@ export default function DropshipImport() {
               </TableCell>
               <TableCell sx={{ fontWeight: "bold" }}>
                 {details
-                  .reduce((acc, cur) => acc + cur.total_harga_produk_channel, 0)
+                  .reduce((acc, cur) => acc + Number(cur.total_harga_produk_channel), 0)
                   .toLocaleString("id-ID", {
                     style: "currency",
                     currency: "IDR",
@@ -40,11 +40,11 @@ export default function GLPage() {
   const [data, setData] = useState<Account[]>([]);
 
   const totalDebit = data
-    .filter((a) => a.balance > 0)
-    .reduce((sum, a) => sum + a.balance, 0);
+    .filter((a) => Number(a.balance) > 0)
+    .reduce((sum, a) => sum + Number(a.balance), 0);
   const totalCredit = data
-    .filter((a) => a.balance < 0)
-    .reduce((sum, a) => sum + -a.balance, 0);
+    .filter((a) => Number(a.balance) < 0)
+    .reduce((sum, a) => sum - Number(a.balance), 0);
 
   useEffect(() => {
     listAllStores().then((s) => setStores(s));
@@ -123,16 +123,16 @@ export default function GLPage() {
                   <TableCell>{a.account_code}</TableCell>
                   <TableCell>{a.account_name}</TableCell>
                   <TableCell align="right">
-                    {a.balance > 0
-                      ? a.balance.toLocaleString("id-ID", {
+                    {Number(a.balance) > 0
+                      ? Number(a.balance).toLocaleString("id-ID", {
                           style: "currency",
                           currency: "IDR",
                         })
                       : ""}
                   </TableCell>
                   <TableCell align="right">
-                    {a.balance < 0
-                      ? (-a.balance).toLocaleString("id-ID", {
+                    {Number(a.balance) < 0
+                      ? (-Number(a.balance)).toLocaleString("id-ID", {
                           style: "currency",
                           currency: "IDR",
                         })
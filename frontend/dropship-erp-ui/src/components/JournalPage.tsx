@@ -47,11 +47,11 @@ export default function JournalPage() {
     usePagination(detailLines);
   const [detailEntry, setDetailEntry] = useState<JournalEntry | null>(null);
   const totalDebit = detailLines.reduce(
-    (sum, l) => (l.is_debit ? sum + l.amount : sum),
+    (sum, l) => (l.is_debit ? sum + Number(l.amount) : sum),
     0,
   );
   const totalCredit = detailLines.reduce(
-    (sum, l) => (!l.is_debit ? sum + l.amount : sum),
+    (sum, l) => (!l.is_debit ? sum + Number(l.amount) : sum),
     0,
   );
   const lineColumns: Column<JournalLineDetail>[] = [
@@ -62,7 +62,7 @@ export default function JournalPage() {
       align: "right",
       render: (_, row) =>
         row.is_debit
-          ? row.amount.toLocaleString("id-ID", {
+          ? Number(row.amount).toLocaleString("id-ID", {
               style: "currency",
               currency: "IDR",
             })
@@ -74,7 +74,7 @@ export default function JournalPage() {
       align: "right",
       render: (_, row) =>
         !row.is_debit
-          ? row.amount.toLocaleString("id-ID", {
+          ? Number(row.amount).toLocaleString("id-ID", {
               style: "currency",
               currency: "IDR",
             })
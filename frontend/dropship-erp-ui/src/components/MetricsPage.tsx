@@ -18,6 +18,15 @@ import type { Store } from "../types";
 import type { Metric } from "../types";
 import usePagination from "../usePagination";
 
+// MONEY_KEYS are the metric fields the API sends as decimal strings.
+const MONEY_KEYS = new Set([
+  "sum_revenue",
+  "sum_cogs",
+  "sum_fees",
+  "net_profit",
+  "ending_cash_balance",
+]);
+
 export default function MetricsPage() {
   const [shop, setShop] = useState("");
   const [stores, setStores] = useState<Store[]>([]);
@@ -112,8 +121,8 @@ export default function MetricsPage() {
               <TableRow key={key}>
                 <TableCell>{key}</TableCell>
                 <TableCell align="right">
-                  {typeof val === "number"
-                    ? val.toLocaleString("id-ID", {
+                  {MONEY_KEYS.has(key)
+                    ? Number(val).toLocaleString("id-ID", {
                         style: "currency",
                         currency: "IDR",
                       })
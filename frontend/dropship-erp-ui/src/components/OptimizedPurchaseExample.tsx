@@ -37,7 +37,7 @@ const purchaseColumns: Column<DropshipPurchase>[] = [
     label: 'Total',
     key: 'total_transaksi',
     align: 'right',
-    render: (value: number) => `Rp ${value?.toLocaleString() || 0}`,
+    render: (value: number) => `Rp ${Number(value || 0).toLocaleString()}`,
   },
   {
     label: 'Channel',
@@ -155,7 +155,7 @@ export function PurchaseStatsComponent() {
         Total Purchases: <strong>{allPurchases.length}</strong>
       </Typography>
       <Typography variant="body2">
-        Total Value: <strong>Rp {allPurchases.reduce((sum, p) => sum + (p.total_transaksi || 0), 0).toLocaleString()}</strong>
+        Total Value: <strong>Rp {allPurchases.reduce((sum, p) => sum + Number(p.total_transaksi || 0), 0).toLocaleString()}</strong>
       </Typography>
       <Typography variant="body2">
         Unique Stores: <strong>{new Set(allPurchases.map(p => p.nama_toko)).size}</strong>
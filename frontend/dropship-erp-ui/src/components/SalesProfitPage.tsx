@@ -320,7 +320,7 @@ export default function SalesProfitPage() {
                       align: "right",
                       render: (_v, r: JournalLineDetail) =>
                         r.is_debit
-                          ? r.amount.toLocaleString("id-ID", {
+                          ? Number(r.amount).toLocaleString("id-ID", {
                               style: "currency",
                               currency: "IDR",
                             })
@@ -332,7 +332,7 @@ export default function SalesProfitPage() {
                       align: "right",
                       render: (_v, r: JournalLineDetail) =>
                         !r.is_debit
-                          ? r.amount.toLocaleString("id-ID", {
+                          ? Number(r.amount).toLocaleString("id-ID", {
                               style: "currency",
                               currency: "IDR",
                             })
@@ -534,7 +534,7 @@ export default function SalesSummaryPage() {
                   <td>{a.tanggal_penyesuaian}</td>
                   <td>{a.tipe_penyesuaian}</td>
                   <td style={{ textAlign: "right" }}>
-                    {a.biaya_penyesuaian.toLocaleString("id-ID", {
+                    {Number(a.biaya_penyesuaian).toLocaleString("id-ID", {
                       style: "currency",
                       currency: "IDR",
                     })}
@@ -565,14 +565,14 @@ export default function SalesSummaryPage() {
               </div>
               <div>
                 <strong>Harga Asli:</strong>{" "}
-                {detail.data.harga_asli_produk.toLocaleString("id-ID", {
+                {Number(detail.data.harga_asli_produk).toLocaleString("id-ID", {
                   style: "currency",
                   currency: "IDR",
                 })}
               </div>
               <div>
                 <strong>Diskon Produk:</strong>{" "}
-                {detail.data.total_diskon_produk.toLocaleString("id-ID", {
+                {Number(detail.data.total_diskon_produk).toLocaleString("id-ID", {
                   style: "currency",
                   currency: "IDR",
                 })}
@@ -601,7 +601,7 @@ export default function SalesSummaryPage() {
                   <td>{l.account_name}</td>
                   <td style={{ textAlign: "right" }}>
                     {l.is_debit
-                      ? l.amount.toLocaleString("id-ID", {
+                      ? Number(l.amount).toLocaleString("id-ID", {
                           style: "currency",
                           currency: "IDR",
                         })
@@ -609,7 +609,7 @@ export default function SalesSummaryPage() {
                   </td>
                   <td style={{ textAlign: "right" }}>
                     {!l.is_debit
-                      ? l.amount.toLocaleString("id-ID", {
+                      ? Number(l.amount).toLocaleString("id-ID", {
                           style: "currency",
                           currency: "IDR",
                         })
@@ -446,7 +446,7 @@ export default function ShopeeSalesPage() {
         setPage(pages);
       }
       const sum = res.data.data.reduce(
-        (acc, cur) => acc + cur.total_penghasilan,
+        (acc, cur) => acc + Number(cur.total_penghasilan),
         0,
       );
       setPageTotal(sum);
@@ -460,13 +460,15 @@ export default function ShopeeSalesPage() {
         total_penghasilan: sum,
       };
       res.data.data.forEach((cur) => {
-        pageSum.harga_asli_produk += cur.harga_asli_produk;
-        pageSum.total_diskon_produk += cur.total_diskon_produk;
-        pageSum.diskon_voucher_ditanggung_penjual +=
-          cur.diskon_voucher_ditanggung_penjual;
-        pageSum.biaya_administrasi += cur.biaya_administrasi;
-        pageSum.biaya_layanan_termasuk_ppn_11 +=
-          cur.biaya_layanan_termasuk_ppn_11;
+        pageSum.harga_asli_produk += Number(cur.harga_asli_produk);
+        pageSum.total_diskon_produk += Number(cur.total_diskon_produk);
+        pageSum.diskon_voucher_ditanggung_penjual += Number(
+          cur.diskon_voucher_ditanggung_penjual,
+        );
+        pageSum.biaya_administrasi += Number(cur.biaya_administrasi);
+        pageSum.biaya_layanan_termasuk_ppn_11 += Number(
+          cur.biaya_layanan_termasuk_ppn_11,
+        );
       });
       pageSum.gmv = pageSum.harga_asli_produk - pageSum.total_diskon_produk;
       setPageSummary(pageSum);
@@ -476,7 +478,7 @@ export default function ShopeeSalesPage() {
         from,
         to,
       });
-      setAllTotal(totalRes.data.total_penghasilan);
+      setAllTotal(Number(totalRes.data.total_penghasilan));
       setAllSummary(totalRes.data);
       setMsg(null);
     } catch (e: any) {
@@ -715,7 +717,7 @@ export default function ShopeeSalesPage() {
             <TableRow>
               <TableCell>Harga Asli Produk</TableCell>
               <TableCell align="right">
-                {allSummary.harga_asli_produk.toLocaleString("id-ID", {
+                {Number(allSummary.harga_asli_produk).toLocaleString("id-ID", {
                   style: "currency",
                   currency: "IDR",
                 })}
@@ -724,7 +726,7 @@ export default function ShopeeSalesPage() {
             <TableRow>
               <TableCell>Total Diskon Produk</TableCell>
               <TableCell align="right">
-                {allSummary.total_diskon_produk.toLocaleString("id-ID", {
+                {Number(allSummary.total_diskon_produk).toLocaleString("id-ID", {
                   style: "currency",
                   currency: "IDR",
                 })}
@@ -733,7 +735,7 @@ export default function ShopeeSalesPage() {
             <TableRow>
               <TableCell>GMV</TableCell>
               <TableCell align="right">
-                {allSummary.gmv.toLocaleString("id-ID", {
+                {Number(allSummary.gmv).toLocaleString("id-ID", {
                   style: "currency",
                   currency: "IDR",
                 })}
@@ -742,7 +744,7 @@ export default function ShopeeSalesPage() {
             <TableRow>
               <TableCell>Diskon Voucher Penjual</TableCell>
               <TableCell align="right">
-                {allSummary.diskon_voucher_ditanggung_penjual.toLocaleString(
+                {Number(allSummary.diskon_voucher_ditanggung_penjual).toLocaleString(
                   "id-ID",
                   {
                     style: "currency",
@@ -754,7 +756,7 @@ export default function ShopeeSalesPage() {
             <TableRow>
               <TableCell>Biaya Administrasi</TableCell>
               <TableCell align="right">
-                {allSummary.biaya_administrasi.toLocaleString("id-ID", {
+                {Number(allSummary.biaya_administrasi).toLocaleString("id-ID", {
                   style: "currency",
                   currency: "IDR",
                 })}
@@ -763,7 +765,7 @@ export default function ShopeeSalesPage() {
             <TableRow>
               <TableCell>Biaya Layanan (+PPN)</TableCell>
               <TableCell align="right">
-                {allSummary.biaya_layanan_termasuk_ppn_11.toLocaleString(
+                {Number(allSummary.biaya_layanan_termasuk_ppn_11).toLocaleString(
                   "id-ID",
                   {
                     style: "currency",
@@ -775,7 +777,7 @@ export default function ShopeeSalesPage() {
             <TableRow>
               <TableCell>Total Penghasilan</TableCell>
               <TableCell align="right">
-                {allSummary.total_penghasilan.toLocaleString("id-ID", {
+                {Number(allSummary.total_penghasilan).toLocaleString("id-ID", {
                   style: "currency",
                   currency: "IDR",
                 })}
@@ -163,9 +163,9 @@ export function useDropshipPurchaseStats(
 ) {
   return {
     totalCount: purchases?.length ?? 0,
-    totalValue: purchases?.reduce((sum, p) => sum + (p.total_transaksi || 0), 0) ?? 0,
+    totalValue: purchases?.reduce((sum, p) => sum + Number(p.total_transaksi || 0), 0) ?? 0,
     averageValue: purchases?.length 
-      ? (purchases.reduce((sum, p) => sum + (p.total_transaksi || 0), 0) / purchases.length)
+      ? (purchases.reduce((sum, p) => sum + Number(p.total_transaksi || 0), 0) / purchases.length)
       : 0,
     uniqueStores: new Set(purchases?.map(p => p.nama_toko) ?? []).size,
   };
@@ -1,9 +1,14 @@
-export function formatCurrency(value: number | null | undefined): string {
-  if (value == null) return "";
+// Amounts arrive from the API as decimal strings such as "15000.50".
+export function formatCurrency(
+  value: number | string | null | undefined,
+): string {
+  if (value == null || value === "") return "";
+  const n = typeof value === "string" ? Number(value) : value;
+  if (Number.isNaN(n)) return "";
   return new Intl.NumberFormat("id-ID", {
     style: "currency",
     currency: "IDR",
-  }).format(value);
+  }).format(n);
 }
 
 export function formatDate(value: string | number | Date): string {